@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/affiliate-backend/internal/config"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/repository"
+	"github.com/affiliate-backend/internal/service"
+)
+
+// maxPrintedErrors limits how many row errors are printed per file
+const maxPrintedErrors = 20
+
+func main() {
+	kind := flag.String("kind", domain.AnalyticsImportPublishers, "Record kind: publishers or advertisers")
+	batchSize := flag.Int("batch-size", domain.DefaultAnalyticsImportBatchSize, "Records per COPY batch")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if !domain.IsValidAnalyticsImportKind(*kind) || flag.NArg() == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	fmt.Println("Analytics Import Tool")
+	fmt.Println("---------------------")
+
+	// Load Configuration
+	config.LoadConfig()
+	appConf := config.AppConfig
+
+	if appConf.DatabaseURL == "" {
+		log.Fatalf("DATABASE_URL is not set. Please set it and try again.")
+	}
+
+	db, err := repository.InitDBConnection(appConf.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	importService := service.NewAnalyticsImportService(repository.NewPgxAnalyticsImportRepository(db))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := false
+	for _, path := range flag.Args() {
+		analyticsImport, err := importFile(ctx, importService, *kind, path, *batchSize)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		printImport(ctx, importService, path, analyticsImport)
+		if analyticsImport.Status != domain.AnalyticsImportStatusCompleted || analyticsImport.FailedCount > 0 {
+			failed = true
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// importFile imports one NDJSON file; "-" reads standard input
+func importFile(ctx context.Context, importService service.AnalyticsImportService, kind, path string, batchSize int) (*domain.AnalyticsImport, error) {
+	var input io.Reader = os.Stdin
+	source := "stdin"
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+		source = filepath.Base(path)
+	}
+
+	return importService.Import(ctx, kind, source, "", input, batchSize)
+}
+
+// printImport prints the counts of an import and its first row errors
+func printImport(ctx context.Context, importService service.AnalyticsImportService, path string, analyticsImport *domain.AnalyticsImport) {
+	fmt.Printf("%s: import %d %s\n", path, analyticsImport.ImportID, analyticsImport.Status)
+	fmt.Printf("  inserted: %d\n", analyticsImport.InsertedCount)
+	fmt.Printf("  updated:  %d\n", analyticsImport.UpdatedCount)
+	fmt.Printf("  skipped:  %d\n", analyticsImport.SkippedCount)
+	fmt.Printf("  failed:   %d\n", analyticsImport.FailedCount)
+	if analyticsImport.ErrorMessage != nil {
+		fmt.Printf("  error:    %s\n", *analyticsImport.ErrorMessage)
+	}
+	if analyticsImport.FailedCount == 0 {
+		return
+	}
+
+	details, err := importService.GetImport(context.WithoutCancel(ctx), analyticsImport.ImportID)
+	if err != nil {
+		fmt.Printf("  failed to load row errors: %v\n", err)
+		return
+	}
+	for i, rowError := range details.Errors {
+		if i == maxPrintedErrors {
+			fmt.Printf("  ... %d more\n", len(details.Errors)-maxPrintedErrors)
+			break
+		}
+		if rowError.Domain != "" {
+			fmt.Printf("  line %d (%s): %s\n", rowError.Line, rowError.Domain, rowError.Message)
+		} else {
+			fmt.Printf("  line %d: %s\n", rowError.Line, rowError.Message)
+		}
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: analytics-import [options] file.ndjson [file.ndjson ...]")
+	fmt.Println("Imports newline-delimited JSON analytics records, upserting them by domain.")
+	fmt.Println("Each line is {\"publisher\": {...}} or {\"advertiser\": {...}}; use - to read standard input.")
+	fmt.Println("Options:")
+	fmt.Println("  -kind publishers|advertisers  Record kind (default: publishers)")
+	fmt.Printf("  -batch-size n                 Records per COPY batch (default: %d, max: %d)\n",
+		domain.DefaultAnalyticsImportBatchSize, domain.MaxAnalyticsImportBatchSize)
+}
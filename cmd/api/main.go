@@ -22,7 +22,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -36,11 +38,16 @@ import (
 	_ "github.com/affiliate-backend/docs" // Import for swagger docs
 	"github.com/affiliate-backend/internal/api"
 	"github.com/affiliate-backend/internal/api/handlers"
+	"github.com/affiliate-backend/internal/api/middleware"
 	"github.com/affiliate-backend/internal/config"
+	"github.com/affiliate-backend/internal/domain"
 	"github.com/affiliate-backend/internal/platform/crypto"
+	"github.com/affiliate-backend/internal/platform/email"
 	"github.com/affiliate-backend/internal/platform/everflow"
+	"github.com/affiliate-backend/internal/platform/geoip"
 	"github.com/affiliate-backend/internal/platform/logger"
 	"github.com/affiliate-backend/internal/platform/provider"
+	"github.com/affiliate-backend/internal/platform/storage"
 	"github.com/affiliate-backend/internal/platform/stripe"
 	"github.com/affiliate-backend/internal/repository"
 	"github.com/affiliate-backend/internal/service"
@@ -252,8 +259,26 @@ func main() {
 	trackingLinkRepo := repository.NewTrackingLinkRepository(repository.DB)
 	trackingLinkProviderMappingRepo := repository.NewTrackingLinkProviderMappingRepository(repository.DB)
 	analyticsRepo := repository.NewAnalyticsRepository(repository.DB)
+	analyticsImportRepo := repository.NewPgxAnalyticsImportRepository(repository.DB)
 	favoritePublisherListRepo := repository.NewFavoritePublisherListRepository(repository.DB)
 	publisherMessagingRepo := repository.NewPublisherMessagingRepository(repository.DB)
+	publisherPipelineRepo := repository.NewPgxPublisherPipelineRepository(repository.DB)
+	publisherConversionRepo := repository.NewPgxPublisherConversionRepository(repository.DB)
+	outreachSequenceRepo := repository.NewPgxOutreachSequenceRepository(repository.DB)
+	publisherMessageTemplateRepo := repository.NewPgxPublisherMessageTemplateRepository(repository.DB)
+	notificationRepo := repository.NewPgxNotificationRepository(repository.DB)
+	organizationLogoRepo := repository.NewPgxOrganizationLogoRepository(repository.DB)
+	trackingDomainRepo := repository.NewPgxTrackingDomainRepository(repository.DB)
+	smartLinkRepo := repository.NewPgxSmartLinkRepository(repository.DB)
+	campaignTargetingRepo := repository.NewPgxCampaignTargetingRepository(repository.DB)
+	campaignURLSettingsRepo := repository.NewPgxCampaignURLSettingsRepository(repository.DB)
+	trackingLinkSigningRepo := repository.NewPgxTrackingLinkSigningRepository(repository.DB)
+	linkHealthRepo := repository.NewPgxLinkHealthRepository(repository.DB)
+	creativeRepo := repository.NewPgxCreativeRepository(repository.DB)
+	bulkTrackingLinkJobRepo := repository.NewPgxBulkTrackingLinkJobRepository(repository.DB)
+	providerStatsRepo := repository.NewPgxProviderStatsRepository(repository.DB)
+	reportRepo := repository.NewPgxReportRepository(repository.DB)
+	scheduledReportRepo := repository.NewPgxScheduledReportRepository(repository.DB)
 
 	// Initialize Billing Repositories
 	billingAccountRepo := repository.NewPgxBillingAccountRepository(repository.DB)
@@ -265,6 +290,29 @@ func main() {
 	// Initialize Platform Services
 	cryptoService := crypto.NewServiceFromConfig()
 
+	// Proxies whose forwarding and CDN country headers are believed
+	var trustedProxies []*net.IPNet
+	if appConf.TrustedProxies != "" {
+		networks, err := middleware.ParseTrustedProxies(strings.Split(appConf.TrustedProxies, ","))
+		if err != nil {
+			logger.Error("Invalid TRUSTED_PROXIES, no proxy is trusted", "error", err)
+		} else {
+			trustedProxies = networks
+		}
+	}
+
+	// GeoIP database for campaign targeting; visitors are located by CDN headers only without it
+	var geoLocator service.GeoLocator
+	if appConf.GeoIPDatabasePath != "" {
+		geoReader, err := geoip.Open(appConf.GeoIPDatabasePath)
+		if err != nil {
+			logger.Error("Failed to open GeoIP database, campaign targeting uses CDN country headers only", "path", appConf.GeoIPDatabasePath, "error", err)
+		} else {
+			geoLocator = geoReader
+			logger.Info("GeoIP database loaded", "path", appConf.GeoIPDatabasePath, "database_type", geoReader.Metadata().DatabaseType)
+		}
+	}
+
 	// Initialize Stripe service
 	stripeConfig := stripe.Config{
 		SecretKey:      os.Getenv("STRIPE_SECRET_KEY"),
@@ -288,11 +336,57 @@ func main() {
 
 	stripeService := stripe.NewService(stripeConfig)
 
+	// Initialize email sender; without an SMTP host emails are only logged
+	var emailSender email.Sender
+	if appConf.SMTPHost != "" {
+		emailSender = email.NewSMTPSender(email.Config{
+			Host:     appConf.SMTPHost,
+			Port:     appConf.SMTPPort,
+			Username: appConf.SMTPUsername,
+			Password: appConf.SMTPPassword,
+			From:     appConf.SMTPFrom,
+		})
+	} else {
+		logger.Warn("SMTP_HOST not set, outgoing emails will only be logged")
+		emailSender = email.NewLoggingSender()
+	}
+
+	// Initialize the Reply-To codec that routes publisher replies back to their conversation
+	var publisherReplyAddresses *email.ReplyAddressCodec
+	if appConf.PublisherReplyDomain != "" {
+		replySecret := appConf.PublisherReplySecret
+		if replySecret == "" {
+			replySecret = appConf.EncryptionKey
+		}
+		publisherReplyAddresses = email.NewReplyAddressCodec("reply", appConf.PublisherReplyDomain, replySecret)
+	} else {
+		logger.Warn("PUBLISHER_REPLY_DOMAIN not set, publisher emails will have no Reply-To address")
+	}
+
+	// Initialize report file storage; without a bucket files are kept on local disk
+	var reportStore storage.ObjectStore
+	if appConf.ReportStorageBucket != "" {
+		reportStore = storage.NewS3Store(storage.S3Config{
+			Endpoint:        appConf.ReportStorageEndpoint,
+			Region:          appConf.ReportStorageRegion,
+			Bucket:          appConf.ReportStorageBucket,
+			AccessKeyID:     appConf.ReportStorageAccessKey,
+			SecretAccessKey: appConf.ReportStorageSecretKey,
+		})
+	} else {
+		logger.Warn("REPORT_STORAGE_BUCKET not set, storing report files locally", "dir", appConf.ReportStorageDir)
+		reportStore = storage.NewLocalStore(appConf.ReportStorageDir)
+	}
+
 	// Initialize integration service based on configuration
 	var integrationService provider.IntegrationService
+	var reportingService provider.ReportingService
+	var providerCreativeService provider.CreativeService
 	if appConf.IsMockMode() {
 		logger.Info("Starting in MOCK MODE - using LoggingMockIntegrationService")
 		integrationService = provider.NewLoggingMockIntegrationService()
+		reportingService = provider.NewLoggingMockReportingService()
+		providerCreativeService = provider.NewLoggingMockCreativeService()
 	} else {
 		logger.Info("Starting in PRODUCTION MODE - using real Everflow integration")
 		// Initialize integration service with Everflow configuration
@@ -309,26 +403,54 @@ func main() {
 			affiliateProviderMappingRepo,
 			campaignProviderMappingRepo,
 		)
+		reportingService = everflow.NewReportingService(everflow.ReportingConfig{
+			BaseURL:    everflowConfig.BaseURL,
+			APIKey:     everflowConfig.APIKey,
+			TimezoneID: everflow.TimezoneIDUTC,
+		})
+		providerCreativeService = everflow.NewCreativeService(everflow.CreativeConfig{
+			BaseURL: everflowConfig.BaseURL,
+			APIKey:  everflowConfig.APIKey,
+		})
 	}
 
 	// Initialize Domain Services
+	notificationService := service.NewNotificationService(notificationRepo)
 	profileService := service.NewProfileService(profileRepo)
 	organizationService := service.NewOrganizationService(organizationRepo, advertiserRepo, affiliateRepo)
-	organizationAssociationService := service.NewOrganizationAssociationService(organizationAssociationRepo, organizationRepo, profileRepo, affiliateRepo, campaignRepo)
+	organizationAssociationService := service.NewOrganizationAssociationService(organizationAssociationRepo, organizationRepo, profileRepo, affiliateRepo, campaignRepo, notificationService)
 	advertiserAssociationInvitationService := service.NewAdvertiserAssociationInvitationService(advertiserAssociationInvitationRepo, organizationAssociationRepo, organizationRepo, profileRepo, organizationAssociationService)
-	agencyDelegationService := service.NewAgencyDelegationService(agencyDelegationRepo, organizationRepo, profileRepo)
+	agencyDelegationService := service.NewAgencyDelegationService(agencyDelegationRepo, organizationRepo, profileRepo, notificationService)
 	advertiserService := service.NewAdvertiserService(advertiserRepo, advertiserProviderMappingRepo, organizationRepo, cryptoService, integrationService)
 	affiliateService := service.NewAffiliateService(affiliateRepo, affiliateProviderMappingRepo, organizationRepo, integrationService)
-	campaignService := service.NewCampaignService(campaignRepo, campaignProviderMappingRepo, integrationService)
-	trackingLinkService := service.NewTrackingLinkService(trackingLinkRepo, trackingLinkProviderMappingRepo, campaignRepo, affiliateRepo, campaignProviderMappingRepo, affiliateProviderMappingRepo, integrationService, organizationAssociationService)
+	campaignService := service.NewCampaignService(campaignRepo, campaignProviderMappingRepo, integrationService, campaignTargetingRepo)
+	campaignTargetingService := service.NewCampaignTargetingService(campaignTargetingRepo, campaignRepo, integrationService, geoLocator)
+	trackingLinkSigningService := service.NewTrackingLinkSigningService(trackingLinkSigningRepo, trackingLinkRepo, cryptoService)
+	campaignURLService := service.NewCampaignURLService(campaignURLSettingsRepo, campaignRepo, trackingLinkRepo)
+	linkHealthService := service.NewLinkHealthService(linkHealthRepo, campaignRepo, trackingLinkRepo, campaignService, campaignURLService, notificationService, nil)
+	trackingLinkQRService := service.NewTrackingLinkQRService(trackingLinkRepo, organizationLogoRepo, reportStore)
+	trackingDomainService := service.NewTrackingDomainService(trackingDomainRepo, campaignRepo, trackingLinkRepo, trackingLinkProviderMappingRepo, net.DefaultResolver, appConf.TrackingBaseURL, appConf.APIBaseURL, campaignTargetingService, campaignURLService, trackingLinkSigningService)
+	trackingLinkService := service.NewTrackingLinkService(trackingLinkRepo, trackingLinkProviderMappingRepo, campaignRepo, affiliateRepo, campaignProviderMappingRepo, affiliateProviderMappingRepo, integrationService, organizationAssociationService, trackingLinkQRService, trackingDomainService)
+	bulkTrackingLinkService := service.NewBulkTrackingLinkService(bulkTrackingLinkJobRepo, campaignRepo, affiliateRepo, trackingLinkService, organizationAssociationService)
+	creativeService := service.NewCreativeService(creativeRepo, campaignRepo, trackingLinkRepo, campaignProviderMappingRepo, affiliateProviderMappingRepo, integrationService, providerCreativeService, reportStore, appConf.APIBaseURL)
+	smartLinkService := service.NewSmartLinkService(smartLinkRepo, trackingLinkRepo, affiliateRepo)
 	analyticsService := service.NewAnalyticsService(analyticsRepo)
-	favoritePublisherListService := service.NewFavoritePublisherListService(favoritePublisherListRepo, analyticsRepo)
-	publisherMessagingService := service.NewPublisherMessagingService(publisherMessagingRepo, analyticsRepo, favoritePublisherListRepo)
+	analyticsImportService := service.NewAnalyticsImportService(analyticsImportRepo)
+	favoritePublisherListService := service.NewFavoritePublisherListService(favoritePublisherListRepo, analyticsRepo, publisherPipelineRepo)
+	publisherMessageTemplateService := service.NewPublisherMessageTemplateService(publisherMessageTemplateRepo, publisherMessagingRepo, favoritePublisherListRepo, analyticsRepo, organizationRepo)
+	publisherMessagingService := service.NewPublisherMessagingService(publisherMessagingRepo, analyticsRepo, favoritePublisherListRepo, organizationRepo, publisherMessageTemplateService, reportStore, emailSender, publisherReplyAddresses)
+	publisherInboundEmailService := service.NewPublisherInboundEmailService(publisherMessagingRepo, favoritePublisherListRepo, publisherPipelineRepo, publisherReplyAddresses, reportStore, notificationService)
+	publisherPipelineService := service.NewPublisherPipelineService(publisherPipelineRepo, favoritePublisherListRepo, publisherMessagingRepo, profileRepo, emailSender)
+	publisherConversionService := service.NewPublisherConversionService(publisherConversionRepo, favoritePublisherListRepo, analyticsRepo, affiliateRepo, organizationAssociationRepo, organizationService, affiliateService, advertiserAssociationInvitationService)
+	outreachSequenceService := service.NewOutreachSequenceService(outreachSequenceRepo, favoritePublisherListRepo, publisherPipelineRepo, publisherMessagingRepo, analyticsRepo, organizationRepo, publisherMessagingService)
+	providerStatsService := service.NewProviderStatsService(providerStatsRepo, reportingService)
+	reportService := service.NewReportService(reportRepo, organizationRepo)
+	scheduledReportService := service.NewScheduledReportService(scheduledReportRepo, reportService, cryptoService, emailSender, reportStore, appConf.APIBaseURL)
 
 	// Initialize Billing Services
-	billingService := service.NewBillingService(billingAccountRepo, paymentMethodRepo, transactionRepo, organizationRepo, stripeService)
+	billingService := service.NewBillingService(billingAccountRepo, paymentMethodRepo, transactionRepo, organizationRepo, stripeService, notificationService)
 	usageCalculationService := service.NewUsageCalculationService(usageRecordRepo, billingAccountRepo, transactionRepo, campaignRepo, affiliateRepo, billingService)
-	cronService := service.NewCronService(usageCalculationService)
+	cronService := service.NewCronService(usageCalculationService, providerStatsService, scheduledReportService, publisherPipelineService, outreachSequenceService, trackingDomainService, linkHealthService, trackingLinkService, publisherConversionService, bulkTrackingLinkService)
 
 	// Initialize Handlers
 	profileHandler := handlers.NewProfileHandler(profileService)
@@ -340,9 +462,28 @@ func main() {
 	affiliateHandler := handlers.NewAffiliateHandler(affiliateService, profileService, analyticsService)
 	campaignHandler := handlers.NewCampaignHandler(campaignService)
 	trackingLinkHandler := handlers.NewTrackingLinkHandler(trackingLinkService)
+	trackingLinkQRHandler := handlers.NewTrackingLinkQRHandler(trackingLinkQRService)
+	trackingDomainHandler := handlers.NewTrackingDomainHandler(trackingDomainService)
+	trackingLinkSigningHandler := handlers.NewTrackingLinkSigningHandler(trackingLinkSigningService)
+	smartLinkHandler := handlers.NewSmartLinkHandler(smartLinkService, geoLocator)
+	campaignTargetingHandler := handlers.NewCampaignTargetingHandler(campaignTargetingService)
+	campaignURLHandler := handlers.NewCampaignURLHandler(campaignURLService)
+	linkHealthHandler := handlers.NewLinkHealthHandler(linkHealthService)
+	creativeHandler := handlers.NewCreativeHandler(creativeService)
+	bulkTrackingLinkHandler := handlers.NewBulkTrackingLinkHandler(bulkTrackingLinkService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	analyticsImportHandler := handlers.NewAnalyticsImportHandler(analyticsImportService)
 	favoritePublisherListHandler := handlers.NewFavoritePublisherListHandler(favoritePublisherListService)
+	publisherPipelineHandler := handlers.NewPublisherPipelineHandler(publisherPipelineService)
+	publisherConversionHandler := handlers.NewPublisherConversionHandler(publisherConversionService)
+	outreachSequenceHandler := handlers.NewOutreachSequenceHandler(outreachSequenceService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	publisherMessagingHandler := handlers.NewPublisherMessagingHandler(publisherMessagingService)
+	publisherMessageTemplateHandler := handlers.NewPublisherMessageTemplateHandler(publisherMessageTemplateService)
+	inboundEmailHandler := handlers.NewInboundEmailHandler(publisherInboundEmailService, appConf.InboundEmailSecret)
+	providerStatsHandler := handlers.NewProviderStatsHandler(providerStatsService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	scheduledReportHandler := handlers.NewScheduledReportHandler(scheduledReportService)
 
 	// Initialize Billing Handlers
 	billingHandler := handlers.NewBillingHandler(billingService, profileService)
@@ -350,6 +491,7 @@ func main() {
 
 	// Setup Router
 	router := api.SetupRouter(api.RouterOptions{
+		TrustedProxies:                         trustedProxies,
 		ProfileHandler:                         profileHandler,
 		ProfileService:                         profileService,
 		OrganizationHandler:                    organizationHandler,
@@ -360,11 +502,30 @@ func main() {
 		AffiliateHandler:                       affiliateHandler,
 		CampaignHandler:                        campaignHandler,
 		TrackingLinkHandler:                    trackingLinkHandler,
+		TrackingLinkQRHandler:                  trackingLinkQRHandler,
+		TrackingDomainHandler:                  trackingDomainHandler,
+		TrackingLinkSigningHandler:             trackingLinkSigningHandler,
+		SmartLinkHandler:                       smartLinkHandler,
+		CampaignTargetingHandler:               campaignTargetingHandler,
+		CampaignURLHandler:                     campaignURLHandler,
+		LinkHealthHandler:                      linkHealthHandler,
+		CreativeHandler:                        creativeHandler,
+		BulkTrackingLinkHandler:                bulkTrackingLinkHandler,
 		AnalyticsHandler:                       analyticsHandler,
+		AnalyticsImportHandler:                 analyticsImportHandler,
 		FavoritePublisherListHandler:           favoritePublisherListHandler,
+		PublisherPipelineHandler:               publisherPipelineHandler,
+		PublisherConversionHandler:             publisherConversionHandler,
+		OutreachSequenceHandler:                outreachSequenceHandler,
+		NotificationHandler:                    notificationHandler,
 		PublisherMessagingHandler:              publisherMessagingHandler,
+		PublisherMessageTemplateHandler:        publisherMessageTemplateHandler,
 		BillingHandler:                         billingHandler,
 		WebhookHandler:                         webhookHandler,
+		ProviderStatsHandler:                   providerStatsHandler,
+		ReportHandler:                          reportHandler,
+		ScheduledReportHandler:                 scheduledReportHandler,
+		InboundEmailHandler:                    inboundEmailHandler,
 	})
 
 	// Start Server
@@ -377,6 +538,39 @@ func main() {
 	cronService.Start()
 	defer cronService.Stop()
 
+	// Resume bulk tracking link jobs interrupted by a restart; the cron job takes over the jobs of
+	// instances that stop afterwards
+	if err := bulkTrackingLinkService.ResumeJobs(context.Background()); err != nil {
+		logger.Warn("Failed to resume bulk tracking link jobs", "error", err)
+	}
+
+	// Push notifications created by any replica to the streams open on this one. Stopping the
+	// listener closes the streams, so shutdown does not wait for them.
+	notificationCtx, stopNotifications := context.WithCancel(context.Background())
+	defer stopNotifications()
+	srv.RegisterOnShutdown(stopNotifications)
+	go notificationService.Run(notificationCtx)
+
+	// Start the inbound SMTP listener for publisher replies, if configured
+	if appConf.InboundSMTPAddr != "" {
+		inboundSMTP := email.NewInboundServer(appConf.InboundSMTPAddr, appConf.PublisherReplyDomain, 0,
+			func(ctx context.Context, from string, recipients []string, raw []byte) error {
+				_, err := publisherInboundEmailService.ReceiveEmail(ctx, raw, recipients)
+				if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrInvalidInput) {
+					// Accept mail that cannot be threaded so the sender does not retry it
+					logger.Info("Ignoring inbound email", "from", from, "reason", err)
+					return nil
+				}
+				return err
+			})
+		go func() {
+			if err := inboundSMTP.ListenAndServe(); err != nil {
+				logger.Error("Inbound SMTP server stopped", "error", err)
+			}
+		}()
+		defer inboundSMTP.Close()
+	}
+
 	// Start the server in a goroutine
 	go func() {
 		logger.Info("Server starting", "port", appConf.Port)
@@ -397,5 +591,8 @@ func main() {
 		logger.Fatal("Server forced to shutdown", "error", err)
 	}
 
+	// Finish the links being generated and hand the rest of the bulk jobs to another instance
+	bulkTrackingLinkService.Stop()
+
 	logger.Info("Server exiting")
 }
@@ -1,10 +1,10 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/affiliate-backend/internal/domain"
 	"github.com/affiliate-backend/internal/service"
@@ -211,6 +211,191 @@ func (h *AnalyticsHandler) GetPublisherByDomain(c *gin.Context) {
 	})
 }
 
+// DiscoverPublishers runs a ranked, faceted publisher discovery search
+// @Summary Discover publishers
+// @Description Searches publishers with free text over domain, description and keywords, filtered by promotype, known flag,
+// @Description minimum traffic score, affiliate networks, country and verticals. Results are sorted by text relevance
+// @Description (default), traffic score or partner count. Facets count the top verticals, countries and networks among all matches.
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Param request body domain.PublisherSearchRequest true "Search parameters"
+// @Success 200 {object} SuccessResponse{data=domain.PublisherSearchResponse} "Search results with facets"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid search parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /analytics/affiliates/discover [post]
+func (h *AnalyticsHandler) DiscoverPublishers(c *gin.Context) {
+	var req domain.PublisherSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.analyticsService.DiscoverPublishers(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid search parameters",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to search publishers",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publishers retrieved successfully",
+		"data":    result,
+	})
+}
+
+// GetSimilarPublishers recommends lookalike publishers for a publisher
+// @Summary Get similar publishers
+// @Description Recommends publishers resembling the given publisher, scored by weighted overlap of related publishers,
+// @Description shared partners, verticals and countries. Each result explains its match.
+// @Tags Analytics
+// @Produce json
+// @Param domain path string true "Publisher domain"
+// @Param limit query int false "Maximum number of recommendations (default 20, max 100)"
+// @Success 200 {object} SuccessResponse{data=domain.SimilarPublishersResponse} "Similar publishers, best match first"
+// @Failure 400 {object} ErrorResponse "Bad request - missing domain"
+// @Failure 404 {object} ErrorResponse "Publisher not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /analytics/affiliates/domain/{domain}/similar [get]
+func (h *AnalyticsHandler) GetSimilarPublishers(c *gin.Context) {
+	domainParam := c.Param("domain")
+	if domainParam == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidDomain,
+			Details: DetailDomainRequired,
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	result, err := h.analyticsService.GetSimilarPublishers(c.Request.Context(), domainParam, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   ErrPublisherNotFound,
+				Details: "No publisher found with the specified domain",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to retrieve similar publishers",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Similar publishers retrieved successfully",
+		"data":    result,
+	})
+}
+
+// GetPublisherHistory returns the snapshot history of a publisher
+// @Summary Get publisher history
+// @Description Returns the snapshots of a publisher's key metrics (traffic score, relevance, partners, affiliate networks,
+// @Description verticals and countries) oldest first, what changed between consecutive snapshots and how fresh the data is.
+// @Tags Analytics
+// @Produce json
+// @Param domain path string true "Publisher domain"
+// @Param from query string false "Only snapshots captured at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only snapshots captured at or before this time (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Param limit query int false "Maximum number of most recent snapshots (default 90, max 500)"
+// @Success 200 {object} SuccessResponse{data=domain.PublisherHistoryResponse} "Publisher history"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid time range"
+// @Failure 404 {object} ErrorResponse "Publisher not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /analytics/affiliates/domain/{domain}/history [get]
+func (h *AnalyticsHandler) GetPublisherHistory(c *gin.Context) {
+	domainParam := c.Param("domain")
+	if domainParam == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidDomain,
+			Details: DetailDomainRequired,
+		})
+		return
+	}
+
+	query := &domain.PublisherHistoryQuery{}
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	var ok bool
+	if query.From, ok = parseHistoryTimeParam(c, "from", false); !ok {
+		return
+	}
+	if query.To, ok = parseHistoryTimeParam(c, "to", true); !ok {
+		return
+	}
+
+	result, err := h.analyticsService.GetPublisherHistory(c.Request.Context(), domainParam, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   ErrPublisherNotFound,
+				Details: "No publisher found with the specified domain",
+			})
+		case errors.Is(err, domain.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid history query",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Failed to retrieve publisher history",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publisher history retrieved successfully",
+		"data":    result,
+	})
+}
+
+// parseHistoryTimeParam parses an optional RFC 3339 time or date query parameter. A date used as
+// the end of a range covers the whole day. It writes the error response and returns false when
+// the value is invalid.
+func parseHistoryTimeParam(c *gin.Context, name string, endOfDay bool) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, true
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name,
+			Details: name + " must be an RFC 3339 time or a YYYY-MM-DD date",
+		})
+		return nil, false
+	}
+	if endOfDay {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &parsed, true
+}
+
 // Additional CRUD endpoints for managing analytics data (optional, for data management)
 
 // CreateAdvertiserRequest represents the request body for creating an advertiser
@@ -241,26 +426,8 @@ func (h *AnalyticsHandler) CreateAdvertiser(c *gin.Context) {
 		return
 	}
 
-	// Create advertiser object and extract fields from data
-	advertiser := &domain.AnalyticsAdvertiser{
-		Domain: req.Domain,
-	}
-
-	// Extract and store individual fields as JSON strings
-	if err := h.extractAdvertiserFields(advertiser, req.Data); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Failed to process advertiser data",
-			Details: err.Error(),
-		})
-		return
-	}
-
-	// Store remaining data in AdditionalData
-	if remainingData, err := h.extractRemainingAdvertiserData(req.Data); err == nil && len(remainingData) > 0 {
-		dataJSON, _ := json.Marshal(remainingData)
-		dataStr := string(dataJSON)
-		advertiser.AdditionalData = &dataStr
-	}
+	// Map the response-shaped data onto the advertiser columns
+	advertiser := domain.NewAnalyticsAdvertiserFromData(req.Domain, req.Data)
 
 	// Create via service
 	if err := h.analyticsService.CreateAdvertiser(c.Request.Context(), advertiser); err != nil {
@@ -305,26 +472,8 @@ func (h *AnalyticsHandler) CreatePublisher(c *gin.Context) {
 		return
 	}
 
-	// Create publisher object and extract fields from data
-	publisher := &domain.AnalyticsPublisher{
-		Domain: req.Domain,
-	}
-
-	// Extract and store individual fields as JSON strings
-	if err := h.extractPublisherFields(publisher, req.Data); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Failed to process publisher data",
-			Details: err.Error(),
-		})
-		return
-	}
-
-	// Store remaining data in AdditionalData
-	if remainingData, err := h.extractRemainingPublisherData(req.Data); err == nil && len(remainingData) > 0 {
-		dataJSON, _ := json.Marshal(remainingData)
-		dataStr := string(dataJSON)
-		publisher.AdditionalData = &dataStr
-	}
+	// Map the response-shaped data onto the publisher columns
+	publisher := domain.NewAnalyticsPublisherFromData(req.Domain, req.Data)
 
 	// Create via service
 	if err := h.analyticsService.CreatePublisher(c.Request.Context(), publisher); err != nil {
@@ -340,167 +489,3 @@ func (h *AnalyticsHandler) CreatePublisher(c *gin.Context) {
 		"data":    publisher,
 	})
 }
-
-// Helper functions to extract and process analytics data fields
-
-// extractAdvertiserFields extracts known fields from the data map and stores them in the advertiser object
-func (h *AnalyticsHandler) extractAdvertiserFields(advertiser *domain.AnalyticsAdvertiser, data map[string]interface{}) error {
-	// Extract metadata fields
-	if metaData, ok := data["metaData"].(map[string]interface{}); ok {
-		if desc, ok := metaData["description"].(string); ok {
-			advertiser.Description = &desc
-		}
-		if favicon, ok := metaData["faviconImageUrl"].(string); ok {
-			advertiser.FaviconImageURL = &favicon
-		}
-		if screenshot, ok := metaData["screenshotImageUrl"].(string); ok {
-			advertiser.ScreenshotImageURL = &screenshot
-		}
-	}
-
-	// Extract and store JSON fields
-	jsonFields := map[string]**string{
-		"affiliateNetworks":  &advertiser.AffiliateNetworks,
-		"contactEmails":      &advertiser.ContactEmails,
-		"keywords":           &advertiser.Keywords,
-		"verticals":          &advertiser.Verticals,
-		"socialMedia":        &advertiser.SocialMedia,
-		"partnerInformation": &advertiser.PartnerInformation,
-		"relatedAdvertisers": &advertiser.RelatedAdvertisers,
-		"backlinks":          &advertiser.Backlinks,
-	}
-
-	for fieldName, fieldPtr := range jsonFields {
-		if fieldData, exists := data[fieldName]; exists {
-			if jsonBytes, err := json.Marshal(fieldData); err == nil {
-				jsonStr := string(jsonBytes)
-				*fieldPtr = &jsonStr
-			}
-		}
-	}
-
-	return nil
-}
-
-// extractPublisherFields extracts known fields from the data map and stores them in the publisher object
-func (h *AnalyticsHandler) extractPublisherFields(publisher *domain.AnalyticsPublisher, data map[string]interface{}) error {
-	// Extract metadata fields
-	if metaData, ok := data["metaData"].(map[string]interface{}); ok {
-		if desc, ok := metaData["description"].(string); ok {
-			publisher.Description = &desc
-		}
-		if favicon, ok := metaData["faviconImageUrl"].(string); ok {
-			publisher.FaviconImageURL = &favicon
-		}
-		if screenshot, ok := metaData["screenshotImageUrl"].(string); ok {
-			publisher.ScreenshotImageURL = &screenshot
-		}
-	}
-
-	// Extract simple fields
-	if known, ok := data["known"].(map[string]interface{}); ok {
-		if value, ok := known["value"].(bool); ok {
-			publisher.Known = value
-		}
-	}
-
-	if relevance, ok := data["relevance"].(float64); ok {
-		publisher.Relevance = relevance
-	}
-
-	if trafficScore, ok := data["trafficScore"].(float64); ok {
-		publisher.TrafficScore = trafficScore
-	}
-
-	if promotype, ok := data["promotype"].(map[string]interface{}); ok {
-		if value, ok := promotype["value"].(string); ok {
-			publisher.Promotype = &value
-		} else if promotype["value"] == nil {
-			// Handle null promotype
-			publisher.Promotype = nil
-		}
-	}
-
-	// Extract and store JSON fields
-	jsonFields := map[string]**string{
-		"affiliateNetworks":  &publisher.AffiliateNetworks,
-		"countryRankings":    &publisher.CountryRankings,
-		"keywords":           &publisher.Keywords,
-		"verticals":          &publisher.Verticals,
-		"verticalsV2":        &publisher.VerticalsV2,
-		"socialMedia":        &publisher.SocialMedia,
-		"partnerInformation": &publisher.PartnerInformation,
-		"partners":           &publisher.Partners,
-		"relatedPublishers":  &publisher.RelatedPublishers,
-		"liveUrls":           &publisher.LiveURLs,
-	}
-
-	for fieldName, fieldPtr := range jsonFields {
-		if fieldData, exists := data[fieldName]; exists {
-			if jsonBytes, err := json.Marshal(fieldData); err == nil {
-				jsonStr := string(jsonBytes)
-				*fieldPtr = &jsonStr
-			}
-		}
-	}
-
-	return nil
-}
-
-// extractRemainingAdvertiserData extracts any remaining data that wasn't processed by extractAdvertiserFields
-func (h *AnalyticsHandler) extractRemainingAdvertiserData(data map[string]interface{}) (map[string]interface{}, error) {
-	// List of fields that are already processed
-	processedFields := map[string]bool{
-		"domain":             true,
-		"metaData":           true,
-		"affiliateNetworks":  true,
-		"contactEmails":      true,
-		"keywords":           true,
-		"verticals":          true,
-		"socialMedia":        true,
-		"partnerInformation": true,
-		"relatedAdvertisers": true,
-		"backlinks":          true,
-	}
-
-	remaining := make(map[string]interface{})
-	for key, value := range data {
-		if !processedFields[key] {
-			remaining[key] = value
-		}
-	}
-
-	return remaining, nil
-}
-
-// extractRemainingPublisherData extracts any remaining data that wasn't processed by extractPublisherFields
-func (h *AnalyticsHandler) extractRemainingPublisherData(data map[string]interface{}) (map[string]interface{}, error) {
-	// List of fields that are already processed
-	processedFields := map[string]bool{
-		"domain":             true,
-		"metaData":           true,
-		"known":              true,
-		"relevance":          true,
-		"trafficScore":       true,
-		"promotype":          true,
-		"affiliateNetworks":  true,
-		"countryRankings":    true,
-		"keywords":           true,
-		"verticals":          true,
-		"verticalsV2":        true,
-		"socialMedia":        true,
-		"partnerInformation": true,
-		"partners":           true,
-		"relatedPublishers":  true,
-		"liveUrls":           true,
-	}
-
-	remaining := make(map[string]interface{})
-	for key, value := range data {
-		if !processedFields[key] {
-			remaining[key] = value
-		}
-	}
-
-	return remaining, nil
-}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsImportHandler handles HTTP requests for bulk analytics imports
+type AnalyticsImportHandler struct {
+	analyticsImportService service.AnalyticsImportService
+}
+
+// NewAnalyticsImportHandler creates a new analytics import handler
+func NewAnalyticsImportHandler(analyticsImportService service.AnalyticsImportService) *AnalyticsImportHandler {
+	return &AnalyticsImportHandler{
+		analyticsImportService: analyticsImportService,
+	}
+}
+
+// CreateImport imports newline-delimited JSON publishers or advertisers
+// @Summary Bulk import analytics data
+// @Description Upserts newline-delimited JSON records by domain. Each line is a record in the AnalyticsPublisherResponse ({"publisher": {...}}) or AnalyticsAdvertiserResponse ({"advertiser": {...}}) shape. Send the records as the request body or as a multipart "file" field. Unchanged records are skipped and failing rows are recorded on the import.
+// @Tags Analytics
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce json
+// @Param kind query string true "Record kind" Enums(publishers, advertisers)
+// @Param batch_size query int false "Records per COPY batch (default 500, max 5000)"
+// @Param file formData file false "NDJSON file"
+// @Success 201 {object} SuccessResponse{data=domain.AnalyticsImport} "Import finished; check status and failed_count"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid kind or missing data"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /analytics/imports [post]
+func (h *AnalyticsImportHandler) CreateImport(c *gin.Context) {
+	kind := strings.ToLower(c.Query("kind"))
+	if !domain.IsValidAnalyticsImportKind(kind) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid kind",
+			Details: "kind must be publishers or advertisers",
+		})
+		return
+	}
+
+	var batchSize int
+	if batchSizeStr := c.Query("batch_size"); batchSizeStr != "" {
+		size, err := strconv.Atoi(batchSizeStr)
+		if err != nil || size < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid batch_size",
+				Details: "batch_size must be a positive integer",
+			})
+			return
+		}
+		batchSize = size
+	}
+
+	var body io.Reader = c.Request.Body
+	source := "upload"
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Missing file",
+				Details: err.Error(),
+			})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Failed to read file",
+				Details: err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+		body = file
+		source = fileHeader.Filename
+	}
+
+	var userID string
+	if value, exists := c.Get("userID"); exists {
+		userID, _ = value.(string)
+	}
+
+	analyticsImport, err := h.analyticsImportService.Import(c.Request.Context(), kind, source, userID, body, batchSize)
+	if err != nil {
+		h.respondError(c, "Failed to import analytics data", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Analytics import finished",
+		"data":    analyticsImport,
+	})
+}
+
+// ListImports lists bulk analytics imports
+// @Summary List analytics imports
+// @Description List bulk analytics imports, most recent first
+// @Tags Analytics
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} SuccessResponse{data=domain.AnalyticsImportListResponse} "Imports retrieved successfully"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /analytics/imports [get]
+func (h *AnalyticsImportHandler) ListImports(c *gin.Context) {
+	page, pageSize := getPaginationParams(c)
+
+	resp, err := h.analyticsImportService.ListImports(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.respondError(c, "Failed to list analytics imports", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Analytics imports retrieved successfully",
+		"data":    resp,
+	})
+}
+
+// GetImport returns a bulk analytics import with its row errors
+// @Summary Get analytics import
+// @Description Get a bulk analytics import with its counts and recorded row errors
+// @Tags Analytics
+// @Produce json
+// @Param import_id path int true "Import ID"
+// @Success 200 {object} SuccessResponse{data=domain.AnalyticsImport} "Import retrieved successfully"
+// @Failure 400 {object} ErrorResponse "Invalid import ID"
+// @Failure 404 {object} ErrorResponse "Import not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /analytics/imports/{import_id} [get]
+func (h *AnalyticsImportHandler) GetImport(c *gin.Context) {
+	importID, err := strconv.ParseInt(c.Param("import_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid import_id",
+			Details: "import_id must be a valid integer",
+		})
+		return
+	}
+
+	analyticsImport, err := h.analyticsImportService.GetImport(c.Request.Context(), importID)
+	if err != nil {
+		h.respondError(c, "Failed to get analytics import", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Analytics import retrieved successfully",
+		"data":    analyticsImport,
+	})
+}
+
+// respondError maps service errors to HTTP responses
+func (h *AnalyticsImportHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid analytics import",
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Analytics import not found",
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
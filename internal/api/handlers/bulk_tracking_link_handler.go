@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/export"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// BulkTrackingLinkHandler handles HTTP requests for bulk tracking link generation jobs
+type BulkTrackingLinkHandler struct {
+	bulkTrackingLinkService service.BulkTrackingLinkService
+}
+
+// NewBulkTrackingLinkHandler creates a new bulk tracking link handler
+func NewBulkTrackingLinkHandler(bulkTrackingLinkService service.BulkTrackingLinkService) *BulkTrackingLinkHandler {
+	return &BulkTrackingLinkHandler{
+		bulkTrackingLinkService: bulkTrackingLinkService,
+	}
+}
+
+// authorizeBulkLinkOrganization parses the :id organization and checks that the user belongs to
+// it (administrators may access any organization)
+func (h *BulkTrackingLinkHandler) authorizeBulkLinkOrganization(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, false
+	}
+	return orgID, true
+}
+
+// authorizeBulkLinkJob authorizes the organization and parses the :job_id job
+func (h *BulkTrackingLinkHandler) authorizeBulkLinkJob(c *gin.Context) (int64, int64, bool) {
+	orgID, ok := h.authorizeBulkLinkOrganization(c)
+	if !ok {
+		return 0, 0, false
+	}
+	jobID, err := strconv.ParseInt(c.Param("job_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid job ID",
+			Details: "Job ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+	return orgID, jobID, true
+}
+
+// respondBulkLinkError maps service errors to HTTP responses
+func (h *BulkTrackingLinkHandler) respondBulkLinkError(c *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: notFound,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateJob starts a bulk tracking link generation job
+// @Summary Start bulk tracking link generation
+// @Description Generates or upserts the tracking links of a campaign for a list of affiliates, or for all affiliates
+// @Description visible to the campaign through active associations. Names and parameters may use the placeholders
+// @Description {campaign_id}, {campaign_name}, {affiliate_id}, {affiliate_name}, {affiliate_org_id} and {date}.
+// @Description The job runs in the background; poll it for progress.
+// @Tags tracking-link-jobs
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body domain.CreateBulkTrackingLinkJobRequest true "Job"
+// @Success 202 {object} map[string]interface{} "message: string, data: domain.BulkTrackingLinkJobWithProgress"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-jobs [post]
+func (h *BulkTrackingLinkHandler) CreateJob(c *gin.Context) {
+	orgID, ok := h.authorizeBulkLinkOrganization(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateBulkTrackingLinkJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var userID string
+	if value, exists := c.Get("userID"); exists {
+		userID, _ = value.(string)
+	}
+
+	job, err := h.bulkTrackingLinkService.CreateJob(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		h.respondBulkLinkError(c, "Failed to start tracking link job", "No campaign found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Tracking link job started",
+		"data":    job,
+	})
+}
+
+// ListJobs lists the bulk tracking link jobs of an organization
+// @Summary List bulk tracking link jobs
+// @Tags tracking-link-jobs
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} map[string]interface{} "data: domain.BulkTrackingLinkJobListResponse"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-jobs [get]
+func (h *BulkTrackingLinkHandler) ListJobs(c *gin.Context) {
+	orgID, ok := h.authorizeBulkLinkOrganization(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPaginationParams(c)
+	jobs, err := h.bulkTrackingLinkService.ListJobs(c.Request.Context(), orgID, page, pageSize)
+	if err != nil {
+		h.respondBulkLinkError(c, "Failed to list tracking link jobs", "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tracking link jobs retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+// GetJob returns a bulk tracking link job with its progress
+// @Summary Get bulk tracking link job
+// @Tags tracking-link-jobs
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param job_id path int true "Job ID"
+// @Success 200 {object} map[string]interface{} "data: domain.BulkTrackingLinkJobWithProgress"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-jobs/{job_id} [get]
+func (h *BulkTrackingLinkHandler) GetJob(c *gin.Context) {
+	orgID, jobID, ok := h.authorizeBulkLinkJob(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.bulkTrackingLinkService.GetJob(c.Request.Context(), orgID, jobID)
+	if err != nil {
+		h.respondBulkLinkError(c, "Failed to get tracking link job", "No job found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tracking link job retrieved successfully",
+		"data":    job,
+	})
+}
+
+// ListJobItems lists the per-affiliate results of a bulk tracking link job
+// @Summary List bulk tracking link job items
+// @Tags tracking-link-jobs
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param job_id path int true "Job ID"
+// @Param status query string false "Filter by status (pending, processing, succeeded, failed)"
+// @Success 200 {object} map[string]interface{} "data: []domain.BulkTrackingLinkJobItem"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-jobs/{job_id}/items [get]
+func (h *BulkTrackingLinkHandler) ListJobItems(c *gin.Context) {
+	orgID, jobID, ok := h.authorizeBulkLinkJob(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.bulkTrackingLinkService.ListJobItems(c.Request.Context(), orgID, jobID, c.Query("status"))
+	if err != nil {
+		h.respondBulkLinkError(c, "Failed to list tracking link job items", "No job found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tracking link job items retrieved successfully",
+		"data":    items,
+	})
+}
+
+// ExportJob downloads the generated links and errors of a bulk tracking link job
+// @Summary Export bulk tracking link job
+// @Description Downloads one row per affiliate with its tracking link, parameters or error
+// @Tags tracking-link-jobs
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param id path int true "Organization ID"
+// @Param job_id path int true "Job ID"
+// @Param format query string false "File format (default csv)" Enums(csv, xlsx, jsonl)
+// @Success 200 {file} file "Job export"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-jobs/{job_id}/export [get]
+func (h *BulkTrackingLinkHandler) ExportJob(c *gin.Context) {
+	orgID, jobID, ok := h.authorizeBulkLinkJob(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	file, err := h.bulkTrackingLinkService.ExportJob(c.Request.Context(), orgID, jobID, format)
+	if err != nil {
+		h.respondBulkLinkError(c, "Failed to export tracking link job", "No job found with the specified ID", err)
+		return
+	}
+
+	fileName := fmt.Sprintf("tracking-link-job-%d.%s", jobID, file.Extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
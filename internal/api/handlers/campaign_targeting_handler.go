@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignTargetingHandler handles HTTP requests for campaign targeting rulesets
+type CampaignTargetingHandler struct {
+	campaignTargetingService service.CampaignTargetingService
+}
+
+// NewCampaignTargetingHandler creates a new campaign targeting handler
+func NewCampaignTargetingHandler(campaignTargetingService service.CampaignTargetingService) *CampaignTargetingHandler {
+	return &CampaignTargetingHandler{
+		campaignTargetingService: campaignTargetingService,
+	}
+}
+
+// authorizeCampaign parses the :id organization and :campaign_id campaign and checks that the
+// user belongs to the organization (administrators may access any organization)
+func (h *CampaignTargetingHandler) authorizeCampaign(c *gin.Context) (int64, int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+	campaignID, err := strconv.ParseInt(c.Param("campaign_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid campaign ID",
+			Details: "Campaign ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, campaignID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, 0, false
+	}
+	return orgID, campaignID, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *CampaignTargetingHandler) respondError(c *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: notFound,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// GetCampaignTargeting returns the targeting ruleset of a campaign
+// @Summary Get campaign targeting
+// @Description Returns the traffic targeting ruleset of the campaign
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} map[string]interface{} "data: domain.CampaignTargeting"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/targeting [get]
+func (h *CampaignTargetingHandler) GetCampaignTargeting(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCampaign(c)
+	if !ok {
+		return
+	}
+
+	targeting, err := h.campaignTargetingService.GetTargeting(c.Request.Context(), orgID, campaignID)
+	if err != nil {
+		h.respondError(c, "Failed to get campaign targeting", "The campaign has no targeting ruleset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": targeting})
+}
+
+// SetCampaignTargeting replaces the targeting ruleset of a campaign
+// @Summary Set campaign targeting
+// @Description Replaces the traffic targeting ruleset of the campaign and syncs it to the provider. Native tracking
+// @Description link clicks that do not match are sent to fallback_url, or refused when it is not set.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param request body domain.SetCampaignTargetingRequest true "Targeting ruleset"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.CampaignTargeting"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/targeting [put]
+func (h *CampaignTargetingHandler) SetCampaignTargeting(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCampaign(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetCampaignTargetingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	targeting, err := h.campaignTargetingService.SetTargeting(c.Request.Context(), orgID, campaignID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to set campaign targeting", "No campaign found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Campaign targeting saved successfully",
+		"data":    targeting,
+	})
+}
+
+// DeleteCampaignTargeting removes the targeting ruleset of a campaign
+// @Summary Delete campaign targeting
+// @Description Removes the traffic targeting ruleset of the campaign, here and on the provider
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} map[string]interface{} "message: string"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/targeting [delete]
+func (h *CampaignTargetingHandler) DeleteCampaignTargeting(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCampaign(c)
+	if !ok {
+		return
+	}
+
+	if err := h.campaignTargetingService.DeleteTargeting(c.Request.Context(), orgID, campaignID); err != nil {
+		h.respondError(c, "Failed to delete campaign targeting", "The campaign has no targeting ruleset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign targeting removed successfully"})
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignURLHandler handles HTTP requests for destination URL macros and passthrough parameters
+type CampaignURLHandler struct {
+	campaignURLService service.CampaignURLService
+}
+
+// NewCampaignURLHandler creates a new campaign URL handler
+func NewCampaignURLHandler(campaignURLService service.CampaignURLService) *CampaignURLHandler {
+	return &CampaignURLHandler{
+		campaignURLService: campaignURLService,
+	}
+}
+
+// authorizeURLCampaign parses the :id organization and :campaign_id campaign and checks that
+// the user belongs to the organization (administrators may access any organization)
+func (h *CampaignURLHandler) authorizeURLCampaign(c *gin.Context) (int64, int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+	campaignID, err := strconv.ParseInt(c.Param("campaign_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid campaign ID",
+			Details: "Campaign ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, campaignID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, 0, false
+	}
+	return orgID, campaignID, true
+}
+
+// respondURLError maps service errors to HTTP responses
+func (h *CampaignURLHandler) respondURLError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No campaign found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// GetCampaignURLSettings returns the passthrough parameter whitelist of a campaign
+// @Summary Get campaign URL settings
+// @Description Returns the click parameters passed through to the campaign destination URL on native redirects
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} map[string]interface{} "data: domain.CampaignURLSettings"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/url-settings [get]
+func (h *CampaignURLHandler) GetCampaignURLSettings(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeURLCampaign(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.campaignURLService.GetURLSettings(c.Request.Context(), orgID, campaignID)
+	if err != nil {
+		h.respondURLError(c, "Failed to get campaign URL settings", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// SetCampaignURLSettings replaces the passthrough parameter whitelist of a campaign
+// @Summary Set campaign URL settings
+// @Description Replaces the whitelist of click parameters passed through to the campaign destination URL. A whitelisted
+// @Description parameter is substituted for its {name} macro, or appended to the query when the URL does not reference it;
+// @Description other click parameters are dropped. Encodings: auto (default), query, path or raw.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param request body domain.SetCampaignURLSettingsRequest true "URL settings"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.CampaignURLSettings"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/url-settings [put]
+func (h *CampaignURLHandler) SetCampaignURLSettings(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeURLCampaign(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetCampaignURLSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	settings, err := h.campaignURLService.SetURLSettings(c.Request.Context(), orgID, campaignID, &req)
+	if err != nil {
+		h.respondURLError(c, "Failed to set campaign URL settings", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Campaign URL settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// PreviewCampaignURL validates the destination URL of a campaign and previews it for a sample click
+// @Summary Preview campaign destination URL
+// @Description Validates the campaign destination URL, or the given draft, and expands its macros for a sample click.
+// @Description Built-in macros: {click_id}, {transaction_id}, {tracking_link_id}, {campaign_id}, {affiliate_id},
+// @Description {source_id}, {sub1}-{sub5}, {country}, {ip} and {timestamp}; whitelisted passthrough parameters are also
+// @Description available as macros. Problems are reported in the response with valid set to false.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param request body domain.URLPreviewRequest true "Sample click"
+// @Success 200 {object} map[string]interface{} "data: domain.URLPreviewResponse"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/url-preview [post]
+func (h *CampaignURLHandler) PreviewCampaignURL(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeURLCampaign(c)
+	if !ok {
+		return
+	}
+
+	var req domain.URLPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	preview, err := h.campaignURLService.PreviewURL(c.Request.Context(), orgID, campaignID, &req)
+	if err != nil {
+		h.respondURLError(c, "Failed to preview campaign URL", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preview})
+}
@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CreativeHandler handles HTTP requests for campaign creatives
+type CreativeHandler struct {
+	creativeService service.CreativeService
+}
+
+// NewCreativeHandler creates a new creative handler
+func NewCreativeHandler(creativeService service.CreativeService) *CreativeHandler {
+	return &CreativeHandler{
+		creativeService: creativeService,
+	}
+}
+
+// authorizeCreativeOrganization parses the :id organization and checks that the user belongs to
+// it (administrators may access any organization)
+func (h *CreativeHandler) authorizeCreativeOrganization(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, false
+	}
+	return orgID, true
+}
+
+// authorizeCreativeCampaign authorizes the organization and parses the :campaign_id campaign
+func (h *CreativeHandler) authorizeCreativeCampaign(c *gin.Context) (int64, int64, bool) {
+	orgID, ok := h.authorizeCreativeOrganization(c)
+	if !ok {
+		return 0, 0, false
+	}
+	campaignID, ok := h.parseCreativeID(c, "campaign_id", "campaign")
+	if !ok {
+		return 0, 0, false
+	}
+	return orgID, campaignID, true
+}
+
+// parseCreativeID parses an integer path parameter, responding with a bad request on failure
+func (h *CreativeHandler) parseCreativeID(c *gin.Context, param, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name + " ID",
+			Details: strings.ToUpper(name[:1]) + name[1:] + " ID must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondCreativeError maps service errors to HTTP responses
+func (h *CreativeHandler) respondCreativeError(c *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: notFound,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateCreative uploads a creative for a campaign
+// @Summary Create campaign creative
+// @Description Creates a banner, HTML or email creative. Image creatives are uploaded as multipart form data with a
+// @Description file; HTML and email creatives may be sent as JSON and must contain the {tracking_link} macro.
+// @Description Creatives start pending review and are synced to the provider.
+// @Tags creatives
+// @Accept json,mpfd
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param request body domain.CreateCreativeRequest false "Creative (JSON)"
+// @Param file formData file false "Creative image"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.Creative"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/creatives [post]
+func (h *CreativeHandler) CreateCreative(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCreativeCampaign(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateCreativeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		if fileHeader, err := c.FormFile("file"); err == nil {
+			file, err := fileHeader.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "Failed to read file",
+					Details: err.Error(),
+				})
+				return
+			}
+			defer file.Close()
+
+			// Read one byte past the limit so that validation can reject oversized files
+			data, err := io.ReadAll(io.LimitReader(file, domain.MaxCreativeFileSize+1))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "Failed to read file",
+					Details: err.Error(),
+				})
+				return
+			}
+			req.File = &domain.CreativeFile{
+				FileName:    fileHeader.Filename,
+				ContentType: fileHeader.Header.Get("Content-Type"),
+				Data:        data,
+			}
+		}
+	}
+
+	creative, err := h.creativeService.CreateCreative(c.Request.Context(), orgID, campaignID, &req)
+	if err != nil {
+		h.respondCreativeError(c, "Failed to create creative", "No campaign found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Creative created successfully",
+		"data":    creative,
+	})
+}
+
+// ListCreatives lists the creatives of a campaign
+// @Summary List campaign creatives
+// @Tags creatives
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Success 200 {object} map[string]interface{} "data: []domain.Creative"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/creatives [get]
+func (h *CreativeHandler) ListCreatives(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCreativeCampaign(c)
+	if !ok {
+		return
+	}
+
+	creatives, err := h.creativeService.ListCreatives(c.Request.Context(), orgID, campaignID, c.Query("status"))
+	if err != nil {
+		h.respondCreativeError(c, "Failed to list creatives", "No campaign found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": creatives})
+}
+
+// GetCreative returns a creative of a campaign
+// @Summary Get campaign creative
+// @Tags creatives
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param creative_id path int true "Creative ID"
+// @Success 200 {object} map[string]interface{} "data: domain.Creative"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/creatives/{creative_id} [get]
+func (h *CreativeHandler) GetCreative(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCreativeCampaign(c)
+	if !ok {
+		return
+	}
+	creativeID, ok := h.parseCreativeID(c, "creative_id", "creative")
+	if !ok {
+		return
+	}
+
+	creative, err := h.creativeService.GetCreative(c.Request.Context(), orgID, campaignID, creativeID)
+	if err != nil {
+		h.respondCreativeError(c, "Failed to get creative", "No creative found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": creative})
+}
+
+// UpdateCreative updates a creative of a campaign
+// @Summary Update campaign creative
+// @Description Updates a creative. Changes to the content of a reviewed creative send it back to pending review.
+// @Tags creatives
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param creative_id path int true "Creative ID"
+// @Param request body domain.UpdateCreativeRequest true "Creative changes"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.Creative"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/creatives/{creative_id} [put]
+func (h *CreativeHandler) UpdateCreative(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCreativeCampaign(c)
+	if !ok {
+		return
+	}
+	creativeID, ok := h.parseCreativeID(c, "creative_id", "creative")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateCreativeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	creative, err := h.creativeService.UpdateCreative(c.Request.Context(), orgID, campaignID, creativeID, &req)
+	if err != nil {
+		h.respondCreativeError(c, "Failed to update creative", "No creative found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Creative updated successfully",
+		"data":    creative,
+	})
+}
+
+// ReviewCreative approves or rejects a creative of a campaign
+// @Summary Review campaign creative
+// @Description Approves or rejects a creative. Only approved creatives are offered to affiliates and active on the provider.
+// @Tags creatives
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param creative_id path int true "Creative ID"
+// @Param request body domain.ReviewCreativeRequest true "Review"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.Creative"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/creatives/{creative_id}/review [post]
+func (h *CreativeHandler) ReviewCreative(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCreativeCampaign(c)
+	if !ok {
+		return
+	}
+	creativeID, ok := h.parseCreativeID(c, "creative_id", "creative")
+	if !ok {
+		return
+	}
+
+	var req domain.ReviewCreativeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	creative, err := h.creativeService.ReviewCreative(c.Request.Context(), orgID, campaignID, creativeID, &req)
+	if err != nil {
+		h.respondCreativeError(c, "Failed to review creative", "No creative found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Creative reviewed successfully",
+		"data":    creative,
+	})
+}
+
+// DeleteCreative deletes a creative of a campaign
+// @Summary Delete campaign creative
+// @Tags creatives
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param creative_id path int true "Creative ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/creatives/{creative_id} [delete]
+func (h *CreativeHandler) DeleteCreative(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeCreativeCampaign(c)
+	if !ok {
+		return
+	}
+	creativeID, ok := h.parseCreativeID(c, "creative_id", "creative")
+	if !ok {
+		return
+	}
+
+	if err := h.creativeService.DeleteCreative(c.Request.Context(), orgID, campaignID, creativeID); err != nil {
+		h.respondCreativeError(c, "Failed to delete creative", "No creative found with the specified ID", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListTrackingLinkCreatives returns the approved creatives of a tracking link's campaign wrapped
+// with the tracking link
+// @Summary List tracking link creatives
+// @Description Returns the approved creatives of the tracking link's campaign with the markup to publish, linked to
+// @Description the tracking link (per creative when the creative is synced to the provider).
+// @Tags creatives
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param link_id path int true "Tracking link ID"
+// @Success 200 {object} map[string]interface{} "data: []domain.WrappedCreative"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-links/{link_id}/creatives [get]
+func (h *CreativeHandler) ListTrackingLinkCreatives(c *gin.Context) {
+	orgID, ok := h.authorizeCreativeOrganization(c)
+	if !ok {
+		return
+	}
+	linkID, ok := h.parseCreativeID(c, "link_id", "tracking link")
+	if !ok {
+		return
+	}
+
+	creatives, err := h.creativeService.ListWrappedCreatives(c.Request.Context(), orgID, linkID)
+	if err != nil {
+		h.respondCreativeError(c, "Failed to list tracking link creatives", "No tracking link found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": creatives})
+}
+
+// GetCreativeFile serves the image of an approved creative
+// @Summary Get creative image
+// @Description Serves the image of an approved image creative; used by published banners, so no authentication is required
+// @Tags creatives
+// @Produce png,jpeg,gif
+// @Param creative_id path int true "Creative ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /public/creatives/{creative_id}/file [get]
+func (h *CreativeHandler) GetCreativeFile(c *gin.Context) {
+	creativeID, ok := h.parseCreativeID(c, "creative_id", "creative")
+	if !ok {
+		return
+	}
+
+	creative, data, err := h.creativeService.GetCreativeFile(c.Request.Context(), creativeID)
+	if err != nil {
+		h.respondCreativeError(c, "Failed to get creative image", "No approved creative image found with the specified ID", err)
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, *creative.ContentType, data)
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/export"
+	"github.com/gin-gonic/gin"
+)
+
+// maxFavoriteListImportBytes limits the size of a list import upload
+const maxFavoriteListImportBytes = 10 << 20
+
+// respondServiceError maps service errors to not found, bad request or internal error responses
+func (h *FavoritePublisherListHandler) respondServiceError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		h.respondNotFound(c, ErrListNotFound, "No favorite publisher list found with the specified ID")
+	case errors.Is(err, domain.ErrInvalidInput):
+		h.respondBadRequest(c, message, err.Error())
+	default:
+		h.respondInternalError(c, message, err.Error())
+	}
+}
+
+func (h *FavoritePublisherListHandler) getUserID(c *gin.Context) *string {
+	value, exists := c.Get("userID")
+	if !exists {
+		return nil
+	}
+	if userID, ok := value.(string); ok && userID != "" {
+		return &userID
+	}
+	return nil
+}
+
+// ImportPublishers imports publishers into a favorite list from CSV or JSON
+// @Summary Import publishers into a favorite list
+// @Description Adds publishers from CSV (header row with publisher_domain or domain, and optional notes, status and stage columns)
+// @Description or JSON (an array of {publisher_domain, notes, status, stage} objects, or {"publishers": [...]}). Send the data as the
+// @Description request body or as a multipart "file" field. Rows are validated one by one: invalid rows, unknown stages and domains
+// @Description missing from the analytics publishers are reported with their row number while the other rows are imported.
+// @Tags favorite-publisher-lists
+// @Accept text/csv
+// @Accept json
+// @Accept multipart/form-data
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param format query string false "Data format; defaults to json for JSON bodies and .json files, csv otherwise" Enums(csv, json)
+// @Param on_duplicate query string false "What to do with publishers already in the list (default skip); update replaces their notes and moves them to the row's status or stage" Enums(skip, update)
+// @Param allow_unknown query bool false "Import domains that are not in the analytics publishers"
+// @Param file formData file false "CSV or JSON file"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.FavoriteListImportResult"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/publishers/import [post]
+func (h *FavoritePublisherListHandler) ImportPublishers(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	listID, ok := h.parseListID(c)
+	if !ok {
+		return
+	}
+
+	opts := domain.FavoriteListImportOptions{OnDuplicate: c.Query("on_duplicate")}
+	if allowUnknown := c.Query("allow_unknown"); allowUnknown != "" {
+		value, err := strconv.ParseBool(allowUnknown)
+		if err != nil {
+			h.respondBadRequest(c, "Invalid allow_unknown", "allow_unknown must be true or false")
+			return
+		}
+		opts.AllowUnknown = value
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFavoriteListImportBytes)
+	var body io.Reader = c.Request.Body
+	isJSON := strings.Contains(c.ContentType(), "json")
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			h.respondBadRequest(c, "Missing file", err.Error())
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.respondBadRequest(c, "Failed to read file", err.Error())
+			return
+		}
+		defer file.Close()
+		body = file
+		isJSON = strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".json")
+	}
+	switch c.Query("format") {
+	case "":
+	case "csv":
+		isJSON = false
+	case "json":
+		isJSON = true
+	default:
+		h.respondBadRequest(c, "Invalid format", "format must be csv or json")
+		return
+	}
+
+	var rows []*domain.FavoriteListImportRow
+	var err error
+	if isJSON {
+		var data []byte
+		if data, err = io.ReadAll(body); err == nil {
+			rows, err = domain.ParseFavoriteListImportJSON(data)
+		}
+	} else {
+		rows, err = domain.ParseFavoriteListImportCSV(body)
+	}
+	if err != nil {
+		h.respondBadRequest(c, "Invalid import file", err.Error())
+		return
+	}
+
+	result, err := h.favoriteListService.ImportPublishers(c.Request.Context(), orgID, listID, h.getUserID(c), rows, opts)
+	if err != nil {
+		h.respondServiceError(c, "Failed to import publishers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publishers imported",
+		"data":    result,
+	})
+}
+
+// BulkUpdatePublishers changes the status or stage of, or removes, several publishers of a list
+// @Summary Bulk update publishers in a favorite list
+// @Description Applies one action to several publishers of a list: "status" moves them to the pipeline stage matching the
+// @Description status, "stage" moves them to a pipeline stage and "remove" removes them. Moves are recorded in the stage
+// @Description history. Publishers that are not in the list are returned in not_found.
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param request body domain.BulkFavoriteListItemsRequest true "Bulk action"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.FavoriteListBulkResult"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/publishers/bulk [post]
+func (h *FavoritePublisherListHandler) BulkUpdatePublishers(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	listID, ok := h.parseListID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.BulkFavoriteListItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondBadRequest(c, ErrInvalidRequestBody, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.favoriteListService.BulkUpdatePublishers(c.Request.Context(), orgID, listID, h.getUserID(c), &req)
+	if err != nil {
+		h.respondServiceError(c, "Failed to update publishers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publishers updated",
+		"data":    result,
+	})
+}
+
+// CopyPublishers copies publishers of a list into another list
+// @Summary Copy publishers to another favorite list
+// @Description Copies publishers, with their notes, pipeline stage and follow-up, into another list of the organization or
+// @Description into a new list. Without publisher_domains every publisher of the list is copied.
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param list_id path int true "Source list ID"
+// @Param request body domain.CopyFavoriteListItemsRequest true "Copy request"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.FavoriteListCopyResult"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/copy [post]
+func (h *FavoritePublisherListHandler) CopyPublishers(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	listID, ok := h.parseListID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CopyFavoriteListItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondBadRequest(c, ErrInvalidRequestBody, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.favoriteListService.CopyPublishers(c.Request.Context(), orgID, listID, h.getUserID(c), &req)
+	if err != nil {
+		h.respondServiceError(c, "Failed to copy publishers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publishers copied",
+		"data":    result,
+	})
+}
+
+// MergeList merges a list into another list
+// @Summary Merge a favorite list into another list
+// @Description Moves every publisher of the list, with its notes, pipeline stage and follow-up, into the target list and deletes the list
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param list_id path int true "List ID to merge and delete"
+// @Param request body domain.MergeFavoriteListRequest true "Merge request"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.FavoriteListCopyResult"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/merge [post]
+func (h *FavoritePublisherListHandler) MergeList(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	listID, ok := h.parseListID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.MergeFavoriteListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondBadRequest(c, ErrInvalidRequestBody, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.favoriteListService.MergeList(c.Request.Context(), orgID, listID, h.getUserID(c), &req)
+	if err != nil {
+		h.respondServiceError(c, "Failed to merge list", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "List merged",
+		"data":    result,
+	})
+}
+
+// ExportList downloads the publishers of a list with their metrics
+// @Summary Export a favorite list
+// @Description Downloads the publishers of a list with their status, pipeline stage, follow-up and latest publisher metrics
+// @Tags favorite-publisher-lists
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param list_id path int true "List ID"
+// @Param format query string false "File format (default csv)" Enums(csv, xlsx, jsonl)
+// @Success 200 {file} file "List export"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/export [get]
+func (h *FavoritePublisherListHandler) ExportList(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	listID, ok := h.parseListID(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FormatCSV)
+	file, err := h.favoriteListService.ExportList(c.Request.Context(), orgID, listID, format)
+	if err != nil {
+		h.respondServiceError(c, "Failed to export list", err)
+		return
+	}
+
+	fileName := fmt.Sprintf("favorite-publisher-list-%d.%s", listID, file.Extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
@@ -522,7 +522,7 @@ func (h *FavoritePublisherListHandler) RemovePublisherFromList(c *gin.Context) {
 // @Tags favorite-publisher-lists
 // @Produce json
 // @Param list_id path int true "List ID"
-// @Param include_details query bool false "Include publisher details from analytics"
+// @Param include_details query bool false "Include publisher details from analytics and what changed between the two latest publisher snapshots"
 // @Success 200 {object} map[string]interface{} "message: string, data: []domain.FavoritePublisherListItem"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -727,7 +727,7 @@ func (h *FavoritePublisherListHandler) GetListsContainingPublisher(c *gin.Contex
 
 // UpdatePublisherStatus updates the status of a publisher in a favorite list
 // @Summary Update publisher status in favorite list
-// @Description Updates the status of a publisher in a favorite list (added -> contacted -> accepted)
+// @Description Updates the status of a publisher in a favorite list (added -> contacted -> accepted) and moves it to the matching pipeline stage
 // @Tags favorite-publisher-lists
 // @Accept json
 // @Produce json
@@ -777,3 +777,72 @@ func (h *FavoritePublisherListHandler) UpdatePublisherStatus(c *gin.Context) {
 		"message": MsgPublisherStatusUpdated,
 	})
 }
+
+// GetListRecommendations recommends publishers similar to those in a favorite list
+// @Summary Get lookalike publisher recommendations for a list
+// @Description Recommends publishers resembling the list's publishers as a whole, scored by weighted overlap of related publishers,
+// @Description shared partners, verticals and countries. Publishers already in the list, affiliates of the organization
+// @Description (or of associated affiliate organizations) and publishers with an existing conversation are excluded.
+// @Tags favorite-publisher-lists
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param limit query int false "Maximum number of recommendations (default 20, max 100)"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.SimilarPublishersResponse"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/recommendations [get]
+func (h *FavoritePublisherListHandler) GetListRecommendations(c *gin.Context) {
+	// Get organization ID from context (set by RBAC middleware)
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Details: "Organization ID not found in context",
+		})
+		return
+	}
+
+	orgID, ok := organizationID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal server error",
+			Details: "Invalid organization ID type",
+		})
+		return
+	}
+
+	listID, err := strconv.ParseInt(c.Param("list_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid list ID",
+			Details: "List ID must be a valid integer",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	recommendations, err := h.favoriteListService.GetListRecommendations(c.Request.Context(), orgID, listID, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "List not found",
+				Details: "No favorite publisher list found with the specified ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to retrieve recommendations",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recommendations retrieved successfully",
+		"data":    recommendations,
+	})
+}
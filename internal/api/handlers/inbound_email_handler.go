@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboundEmailBytes limits the size of inbound email webhook requests
+const maxInboundEmailBytes = 30 * 1024 * 1024
+
+// InboundEmailHandler receives publisher email replies and serves their attachments
+type InboundEmailHandler struct {
+	inboundService service.PublisherInboundEmailService
+	webhookSecret  string
+}
+
+// NewInboundEmailHandler creates a new inbound email handler. Webhook requests must carry
+// webhookSecret; the webhook is disabled when it is empty.
+func NewInboundEmailHandler(inboundService service.PublisherInboundEmailService, webhookSecret string) *InboundEmailHandler {
+	return &InboundEmailHandler{
+		inboundService: inboundService,
+		webhookSecret:  webhookSecret,
+	}
+}
+
+// HandleInboundEmail receives a raw publisher email from a mail provider webhook
+// @Summary Receive inbound publisher email
+// @Description Accepts a raw MIME email, either as the request body (message/rfc822) or as the "email" (SendGrid Inbound Parse, raw mode)
+// @Description or "body-mime" (Mailgun) multipart form field. The reply is matched to its conversation by the conversation Reply-To address
+// @Description or its In-Reply-To/References headers, stripped of quoted history and appended as a publisher message.
+// @Description Requests are authenticated with the shared secret in the "token" query parameter or the X-Inbound-Email-Token header.
+// @Tags Publisher Messaging
+// @Accept mpfd
+// @Produce json
+// @Param token query string false "Inbound email webhook secret"
+// @Success 200 {object} map[string]string "Email did not match a conversation and was ignored"
+// @Success 201 {object} domain.PublisherMessage "Reply added to the conversation"
+// @Failure 400 {object} ErrorResponse "Missing or invalid email"
+// @Failure 401 {object} ErrorResponse "Invalid webhook token"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Inbound email is not configured"
+// @Router /public/webhooks/inbound-email [post]
+func (h *InboundEmailHandler) HandleInboundEmail(c *gin.Context) {
+	if h.webhookSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Inbound email is not configured"})
+		return
+	}
+
+	token := c.Query("token")
+	if token == "" {
+		token = c.GetHeader("X-Inbound-Email-Token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.webhookSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid webhook token"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundEmailBytes)
+	raw, recipients, err := readInboundEmail(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid inbound email",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	message, err := h.inboundService.ReceiveEmail(c.Request.Context(), raw, recipients)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			// Acknowledge unmatched mail so the provider does not keep retrying it
+			logger.Info("Ignoring inbound email without matching conversation")
+			c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		case errors.Is(err, domain.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid inbound email",
+				Details: err.Error(),
+			})
+		default:
+			logger.Error("Failed to process inbound email", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Failed to process inbound email",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// readInboundEmail extracts the raw email and any envelope recipients from the request
+func readInboundEmail(c *gin.Context) ([]byte, []string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" && mediaType != "application/x-www-form-urlencoded" {
+		raw, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		if len(raw) == 0 {
+			return nil, nil, fmt.Errorf("request body is empty")
+		}
+		return raw, nil, nil
+	}
+
+	if mediaType == "multipart/form-data" {
+		if err := c.Request.ParseMultipartForm(maxInboundEmailBytes); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse form: %w", err)
+		}
+	}
+
+	raw := c.PostForm("email") // SendGrid Inbound Parse with "POST the raw, full MIME message"
+	if raw == "" {
+		raw = c.PostForm("body-mime") // Mailgun routes forwarding to a .../mime URL
+	}
+	if raw == "" {
+		return nil, nil, fmt.Errorf("form has no email or body-mime field")
+	}
+
+	var recipients []string
+	if envelope := c.PostForm("envelope"); envelope != "" {
+		var parsed struct {
+			To []string `json:"to"`
+		}
+		if err := json.Unmarshal([]byte(envelope), &parsed); err == nil {
+			recipients = append(recipients, parsed.To...)
+		}
+	}
+	if recipient := c.PostForm("recipient"); recipient != "" {
+		for _, address := range strings.Split(recipient, ",") {
+			recipients = append(recipients, strings.TrimSpace(address))
+		}
+	}
+
+	return []byte(raw), recipients, nil
+}
+
+// DownloadMessageAttachment downloads an attachment of a conversation message
+// @Summary Download a message attachment
+// @Description Downloads a file attached to a message, either a publisher's email reply or a message sent by the organization. Attachments are listed in the message metadata under "attachments";
+// @Description index is the position in that list.
+// @Tags Publisher Messaging
+// @Produce octet-stream
+// @Param conversation_id path int true "Conversation ID"
+// @Param message_id path int true "Message ID"
+// @Param index path int true "Attachment index"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse "Invalid path parameter"
+// @Failure 401 {object} ErrorResponse "Organization ID not found in context"
+// @Failure 404 {object} ErrorResponse "Conversation, message or attachment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /publisher-messaging/conversations/{conversation_id}/messages/{message_id}/attachments/{index} [get]
+func (h *InboundEmailHandler) DownloadMessageAttachment(c *gin.Context) {
+	userOrgID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Organization ID not found in context",
+			Details: "Please ensure you are properly authenticated",
+		})
+		return
+	}
+	organizationID := userOrgID.(int64)
+
+	conversationID, err := strconv.ParseInt(c.Param("conversation_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid conversation ID", Details: "Conversation ID must be a valid integer"})
+		return
+	}
+	messageID, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid message ID", Details: "Message ID must be a valid integer"})
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid attachment index", Details: "Attachment index must be a valid integer"})
+		return
+	}
+
+	attachment, data, err := h.inboundService.GetMessageAttachment(c.Request.Context(), organizationID, conversationID, messageID, index)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Attachment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Failed to download attachment",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.Filename))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, contentType, data)
+}
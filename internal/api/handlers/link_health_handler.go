@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// LinkHealthHandler handles HTTP requests for campaign link health monitoring
+type LinkHealthHandler struct {
+	linkHealthService service.LinkHealthService
+}
+
+// NewLinkHealthHandler creates a new link health handler
+func NewLinkHealthHandler(linkHealthService service.LinkHealthService) *LinkHealthHandler {
+	return &LinkHealthHandler{
+		linkHealthService: linkHealthService,
+	}
+}
+
+// authorizeLinkHealthCampaign parses the :id organization and :campaign_id campaign and checks
+// that the user belongs to the organization (administrators may access any organization)
+func (h *LinkHealthHandler) authorizeLinkHealthCampaign(c *gin.Context) (int64, int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+	campaignID, err := strconv.ParseInt(c.Param("campaign_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid campaign ID",
+			Details: "Campaign ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, campaignID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, 0, false
+	}
+	return orgID, campaignID, true
+}
+
+// respondLinkHealthError maps service errors to HTTP responses
+func (h *LinkHealthHandler) respondLinkHealthError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No campaign found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// GetCampaignLinkHealth returns the link health of a campaign
+// @Summary Get campaign link health
+// @Description Returns the monitoring settings and failure streak of a campaign with the checks of its latest run. The
+// @Description destination, preview and tracking URLs of active campaigns are checked every hour, following redirects.
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} map[string]interface{} "data: domain.CampaignLinkHealthResponse"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/link-health [get]
+func (h *LinkHealthHandler) GetCampaignLinkHealth(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeLinkHealthCampaign(c)
+	if !ok {
+		return
+	}
+
+	health, err := h.linkHealthService.GetHealth(c.Request.Context(), orgID, campaignID)
+	if err != nil {
+		h.respondLinkHealthError(c, "Failed to get campaign link health", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": health})
+}
+
+// SetCampaignLinkHealth changes the link health monitoring settings of a campaign
+// @Summary Set campaign link health settings
+// @Description Enables or disables link checks of a campaign. With auto_pause_after_failures, the campaign is paused
+// @Description once that many runs in a row had a failing URL; the organization is notified when a failure streak
+// @Description starts and when the campaign is paused.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param request body domain.SetCampaignLinkHealthRequest true "Monitoring settings"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.CampaignLinkHealth"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/link-health [put]
+func (h *LinkHealthHandler) SetCampaignLinkHealth(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeLinkHealthCampaign(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetCampaignLinkHealthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	health, err := h.linkHealthService.SetSettings(c.Request.Context(), orgID, campaignID, &req)
+	if err != nil {
+		h.respondLinkHealthError(c, "Failed to set campaign link health settings", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Campaign link health settings updated successfully",
+		"data":    health,
+	})
+}
+
+// ListCampaignLinkHealthChecks lists the link checks of a campaign
+// @Summary List campaign link health checks
+// @Description Lists the checks of the campaign URLs, newest first, with their status code, latency, redirect chain and
+// @Description TLS certificate expiry. Checks are kept for 30 days.
+// @Tags campaigns
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} map[string]interface{} "data: domain.LinkHealthCheckListResponse"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/link-health/checks [get]
+func (h *LinkHealthHandler) ListCampaignLinkHealthChecks(c *gin.Context) {
+	orgID, campaignID, ok := h.authorizeLinkHealthCampaign(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPaginationParams(c)
+	checks, err := h.linkHealthService.ListChecks(c.Request.Context(), orgID, campaignID, page, pageSize)
+	if err != nil {
+		h.respondLinkHealthError(c, "Failed to list campaign link health checks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": checks})
+}
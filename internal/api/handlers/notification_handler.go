@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// notificationStreamHeartbeat is how often an idle stream sends a comment so that proxies keep it open
+const notificationStreamHeartbeat = 25 * time.Second
+
+// NotificationHandler handles HTTP requests for user notifications
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandler) getUserID(c *gin.Context) (string, bool) {
+	var userID string
+	if value, exists := c.Get("userID"); exists {
+		userID, _ = value.(string)
+	}
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: "User ID not found in context",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *NotificationHandler) parseNotificationID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("notification_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid notification_id",
+			Details: "notification_id must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *NotificationHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No notification found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// ListNotifications lists the notifications of the current user
+// @Summary List notifications
+// @Description Lists the notifications of the current user, newest first, with the number of unread notifications.
+// @Description Types are publisher_reply, association_request, delegation_invite, billing_alert and link_health_alert.
+// @Tags Notifications
+// @Produce json
+// @Param unread_only query bool false "Only unread notifications"
+// @Param type query string false "Notification type"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size (max 100)" default(20)
+// @Success 200 {object} domain.NotificationListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	filter := &domain.NotificationFilter{
+		UnreadOnly: c.Query("unread_only") == "true",
+	}
+	if notificationType := c.Query("type"); notificationType != "" {
+		filter.Type = &notificationType
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	response, err := h.notificationService.ListNotifications(c.Request.Context(), userID, filter, page, pageSize)
+	if err != nil {
+		h.respondError(c, "Failed to list notifications", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUnreadCount returns the number of unread notifications of the current user
+// @Summary Count unread notifications
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]interface{} "unread_count: int"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.notificationService.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, "Failed to count unread notifications", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+// StreamNotifications pushes the new notifications of the current user as Server-Sent Events
+// @Summary Stream notifications
+// @Description Opens a Server-Sent Events stream. An "unread_count" event is sent first, then a "notification" event with a
+// @Description domain.Notification for each new notification of a type with realtime delivery enabled. Idle streams send a
+// @Description comment line every 25 seconds. The Authorization header is required, so browsers need a fetch-based event source.
+// @Tags Notifications
+// @Produce text/event-stream
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/stream [get]
+func (h *NotificationHandler) StreamNotifications(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	// Subscribe before counting so that nothing created in between is missed
+	events, unsubscribe := h.notificationService.Subscribe(userID)
+	defer unsubscribe()
+
+	count, err := h.notificationService.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, "Failed to count unread notifications", err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+	c.SSEvent("unread_count", gin.H{"unread_count": count})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(notificationStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case notification, ok := <-events:
+			if !ok {
+				return false // The server is shutting down
+			}
+			c.SSEvent("notification", notification)
+			return true
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// MarkRead marks a notification as read
+// @Summary Mark a notification as read
+// @Tags Notifications
+// @Param notification_id path int true "Notification ID"
+// @Success 204 "Marked as read"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/{notification_id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	h.setReadState(c, true)
+}
+
+// MarkUnread marks a notification as unread
+// @Summary Mark a notification as unread
+// @Tags Notifications
+// @Param notification_id path int true "Notification ID"
+// @Success 204 "Marked as unread"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/{notification_id}/read [delete]
+func (h *NotificationHandler) MarkUnread(c *gin.Context) {
+	h.setReadState(c, false)
+}
+
+func (h *NotificationHandler) setReadState(c *gin.Context, read bool) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	notificationID, ok := h.parseNotificationID(c)
+	if !ok {
+		return
+	}
+
+	var err error
+	if read {
+		err = h.notificationService.MarkRead(c.Request.Context(), userID, notificationID)
+	} else {
+		err = h.notificationService.MarkUnread(c.Request.Context(), userID, notificationID)
+	}
+	if err != nil {
+		h.respondError(c, "Failed to update notification", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead marks every unread notification of the current user as read
+// @Summary Mark all notifications as read
+// @Tags Notifications
+// @Produce json
+// @Param type query string false "Only notifications of this type"
+// @Success 200 {object} map[string]interface{} "message: string, data: {marked: int}"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var notificationType *string
+	if t := c.Query("type"); t != "" {
+		notificationType = &t
+	}
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), userID, notificationType)
+	if err != nil {
+		h.respondError(c, "Failed to mark notifications as read", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read",
+		"data":    gin.H{"marked": marked},
+	})
+}
+
+// DeleteNotification deletes a notification
+// @Summary Delete a notification
+// @Tags Notifications
+// @Param notification_id path int true "Notification ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/{notification_id} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	notificationID, ok := h.parseNotificationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.DeleteNotification(c.Request.Context(), userID, notificationID); err != nil {
+		h.respondError(c, "Failed to delete notification", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPreferences returns the notification preferences of the current user
+// @Summary Get notification preferences
+// @Description Returns the settings of every notification type. Disabled types are not stored; types without realtime
+// @Description delivery are listed but not pushed to the stream.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]interface{} "data: []domain.NotificationPreference"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, "Failed to get notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
+}
+
+// UpdatePreferences changes the notification preferences of the current user
+// @Summary Update notification preferences
+// @Description Changes the settings of the listed types; omitted fields and types keep their settings
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body domain.UpdateNotificationPreferencesRequest true "Preferences"
+// @Success 200 {object} map[string]interface{} "message: string, data: []domain.NotificationPreference"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to update notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification preferences updated successfully",
+		"data":    preferences,
+	})
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OutreachSequenceHandler handles HTTP requests for automated publisher outreach sequences
+type OutreachSequenceHandler struct {
+	outreachService service.OutreachSequenceService
+}
+
+// NewOutreachSequenceHandler creates a new outreach sequence handler
+func NewOutreachSequenceHandler(outreachService service.OutreachSequenceService) *OutreachSequenceHandler {
+	return &OutreachSequenceHandler{
+		outreachService: outreachService,
+	}
+}
+
+func (h *OutreachSequenceHandler) getOrganizationID(c *gin.Context) (int64, bool) {
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+
+	orgID, ok := organizationID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: DetailInvalidOrgIDType,
+		})
+		return 0, false
+	}
+
+	return orgID, true
+}
+
+func (h *OutreachSequenceHandler) parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name,
+			Details: name + " must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *OutreachSequenceHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No sequence or list found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateSequence creates an outreach sequence
+// @Summary Create an outreach sequence
+// @Description Creates a sequence of messages sent to the publishers it is launched on. The subject and step bodies are
+// @Description templates with the merge fields {{.Domain}}, {{.Description}}, {{.ContactEmail}}, {{.TopCountry}},
+// @Description {{.Vertical}}, {{.TrafficScore}}, {{.Promotype}}, {{.OrganizationName}}, {{.ListName}} and {{.Notes}}.
+// @Description The first step is sent at launch; each follow-up is sent its delay in days after the previous step, unless
+// @Description the publisher replied.
+// @Tags outreach-sequences
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateOutreachSequenceRequest true "Sequence"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.OutreachSequence"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences [post]
+func (h *OutreachSequenceHandler) CreateSequence(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateOutreachSequenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var userID *string
+	if value, exists := c.Get("userID"); exists {
+		if id, ok := value.(string); ok && id != "" {
+			userID = &id
+		}
+	}
+
+	sequence, err := h.outreachService.CreateSequence(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to create outreach sequence", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Outreach sequence created successfully",
+		"data":    sequence,
+	})
+}
+
+// ListSequences lists the outreach sequences of the organization
+// @Summary List outreach sequences
+// @Description Lists the outreach sequences of the organization with their steps
+// @Tags outreach-sequences
+// @Produce json
+// @Success 200 {object} map[string]interface{} "message: string, data: []domain.OutreachSequence"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences [get]
+func (h *OutreachSequenceHandler) ListSequences(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	sequences, err := h.outreachService.ListSequences(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondError(c, "Failed to list outreach sequences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Outreach sequences retrieved successfully",
+		"data":    sequences,
+	})
+}
+
+// GetSequence returns an outreach sequence with its statistics
+// @Summary Get an outreach sequence
+// @Description Returns an outreach sequence with its steps, how many publishers are in each enrollment state, and the reply
+// @Description rate of the sequence and of each step
+// @Tags outreach-sequences
+// @Produce json
+// @Param sequence_id path int true "Sequence ID"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.OutreachSequence"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences/{sequence_id} [get]
+func (h *OutreachSequenceHandler) GetSequence(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	sequenceID, ok := h.parseIDParam(c, "sequence_id")
+	if !ok {
+		return
+	}
+
+	sequence, err := h.outreachService.GetSequence(c.Request.Context(), orgID, sequenceID)
+	if err != nil {
+		h.respondError(c, "Failed to get outreach sequence", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Outreach sequence retrieved successfully",
+		"data":    sequence,
+	})
+}
+
+// UpdateSequence updates an outreach sequence
+// @Summary Update an outreach sequence
+// @Description Renames, pauses or resumes a sequence, or replaces its templates. Steps given replace all steps; enrolled
+// @Description publishers continue with the steps they have not been sent yet.
+// @Tags outreach-sequences
+// @Accept json
+// @Produce json
+// @Param sequence_id path int true "Sequence ID"
+// @Param request body domain.UpdateOutreachSequenceRequest true "Changes"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.OutreachSequence"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences/{sequence_id} [put]
+func (h *OutreachSequenceHandler) UpdateSequence(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	sequenceID, ok := h.parseIDParam(c, "sequence_id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateOutreachSequenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	sequence, err := h.outreachService.UpdateSequence(c.Request.Context(), orgID, sequenceID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to update outreach sequence", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Outreach sequence updated successfully",
+		"data":    sequence,
+	})
+}
+
+// DeleteSequence deletes an outreach sequence
+// @Summary Delete an outreach sequence
+// @Description Deletes a sequence and its enrollments. Conversations and messages already sent are kept.
+// @Tags outreach-sequences
+// @Produce json
+// @Param sequence_id path int true "Sequence ID"
+// @Success 200 {object} map[string]interface{} "message: string"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences/{sequence_id} [delete]
+func (h *OutreachSequenceHandler) DeleteSequence(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	sequenceID, ok := h.parseIDParam(c, "sequence_id")
+	if !ok {
+		return
+	}
+
+	if err := h.outreachService.DeleteSequence(c.Request.Context(), orgID, sequenceID); err != nil {
+		h.respondError(c, "Failed to delete outreach sequence", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Outreach sequence deleted successfully",
+	})
+}
+
+// LaunchSequence enrolls the publishers of a favorite list in a sequence
+// @Summary Launch an outreach sequence on a favorite list
+// @Description Creates a conversation with each publisher of the list, or the given publishers of it, and schedules the first
+// @Description step at start_at or immediately. Publishers already enrolled, accepted or in a won or lost pipeline stage, in
+// @Description an active conversation or without a contact email are skipped with the reason.
+// @Tags outreach-sequences
+// @Accept json
+// @Produce json
+// @Param sequence_id path int true "Sequence ID"
+// @Param request body domain.LaunchOutreachSequenceRequest true "List and publishers"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.LaunchOutreachSequenceResult"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences/{sequence_id}/launch [post]
+func (h *OutreachSequenceHandler) LaunchSequence(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	sequenceID, ok := h.parseIDParam(c, "sequence_id")
+	if !ok {
+		return
+	}
+
+	var req domain.LaunchOutreachSequenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.outreachService.LaunchSequence(c.Request.Context(), orgID, sequenceID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to launch outreach sequence", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Outreach sequence launched successfully",
+		"data":    result,
+	})
+}
+
+// ListEnrollments lists the publishers enrolled in a sequence
+// @Summary List outreach sequence enrollments
+// @Description Lists the publishers enrolled in a sequence with the steps sent, the next send time and whether they replied
+// @Tags outreach-sequences
+// @Produce json
+// @Param sequence_id path int true "Sequence ID"
+// @Param status query string false "Enrollment status" Enums(scheduled, active, completed, replied, stopped, failed)
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.OutreachEnrollmentList"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /outreach-sequences/{sequence_id}/enrollments [get]
+func (h *OutreachSequenceHandler) ListEnrollments(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	sequenceID, ok := h.parseIDParam(c, "sequence_id")
+	if !ok {
+		return
+	}
+
+	filter := &domain.OutreachEnrollmentFilter{}
+	if status := c.Query("status"); status != "" {
+		filter.Status = &status
+	}
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid limit", Details: "limit must be a valid integer"})
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := c.Query("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid offset", Details: "offset must be a valid integer"})
+			return
+		}
+		filter.Offset = value
+	}
+
+	enrollments, err := h.outreachService.ListEnrollments(c.Request.Context(), orgID, sequenceID, filter)
+	if err != nil {
+		h.respondError(c, "Failed to list outreach sequence enrollments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Outreach sequence enrollments retrieved successfully",
+		"data":    enrollments,
+	})
+}
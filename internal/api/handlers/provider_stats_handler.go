@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderStatsHandler handles HTTP requests for the provider stats warehouse
+type ProviderStatsHandler struct {
+	providerStatsService service.ProviderStatsService
+}
+
+// NewProviderStatsHandler creates a new provider stats handler
+func NewProviderStatsHandler(providerStatsService service.ProviderStatsService) *ProviderStatsHandler {
+	return &ProviderStatsHandler{
+		providerStatsService: providerStatsService,
+	}
+}
+
+// Backfill re-imports provider stats for a date range
+// @Summary Backfill provider stats
+// @Description Imports provider reporting data for every day in the given range, replacing any previously imported data for those days
+// @Tags Provider Stats
+// @Accept json
+// @Produce json
+// @Param request body domain.ProviderStatsBackfillRequest true "Backfill date range (YYYY-MM-DD, inclusive)"
+// @Success 200 {object} domain.ProviderStatsBackfillResponse "Backfill results per day"
+// @Failure 400 {object} ErrorResponse "Invalid date range"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /provider-stats/imports [post]
+func (h *ProviderStatsHandler) Backfill(c *gin.Context) {
+	var req domain.ProviderStatsBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response, err := h.providerStatsService.Backfill(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid date range",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to backfill provider stats",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListImports lists provider stats import runs
+// @Summary List provider stats imports
+// @Description Lists scheduled and backfill import runs, most recent day first
+// @Tags Provider Stats
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} domain.ProviderStatsImportListResponse "Import runs"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /provider-stats/imports [get]
+func (h *ProviderStatsHandler) ListImports(c *gin.Context) {
+	page, pageSize := getPaginationParams(c)
+
+	imports, total, err := h.providerStatsService.ListImports(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to list provider stats imports",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.ProviderStatsImportListResponse{
+		Imports:  imports,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
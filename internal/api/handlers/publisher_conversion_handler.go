@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PublisherConversionHandler handles HTTP requests for onboarding discovered publishers as affiliates
+type PublisherConversionHandler struct {
+	conversionService service.PublisherConversionService
+}
+
+// NewPublisherConversionHandler creates a new publisher conversion handler
+func NewPublisherConversionHandler(conversionService service.PublisherConversionService) *PublisherConversionHandler {
+	return &PublisherConversionHandler{
+		conversionService: conversionService,
+	}
+}
+
+func (h *PublisherConversionHandler) getOrganizationID(c *gin.Context) (int64, bool) {
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+
+	orgID, ok := organizationID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: DetailInvalidOrgIDType,
+		})
+		return 0, false
+	}
+
+	return orgID, true
+}
+
+func (h *PublisherConversionHandler) parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name,
+			Details: name + " must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *PublisherConversionHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No list, publisher or conversion found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// ConvertPublisher onboards an accepted publisher of a favorite list as an affiliate
+// @Summary Convert a publisher into an affiliate
+// @Description Onboards an accepted publisher of a favorite list: links the affiliate organization given, or whose website is on
+// @Description the publisher domain, or creates one with the contact email from the publisher analytics, creates its first
+// @Description affiliate and issues a single-use association invitation with the default visibility. The conversion is then
+// @Description tracked from the conversation with the publisher to the first tracking link.
+// @Tags publisher-conversions
+// @Accept json
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param domain path string true "Publisher domain"
+// @Param request body domain.ConvertPublisherRequest false "Conversion options"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.PublisherConversion"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/publishers/{domain}/convert [post]
+func (h *PublisherConversionHandler) ConvertPublisher(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	listID, ok := h.parseIDParam(c, "list_id")
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	userIDStr, _ := userID.(string)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: "User ID not found in context",
+		})
+		return
+	}
+
+	var req domain.ConvertPublisherRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   ErrInvalidRequestBody,
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	conversion, err := h.conversionService.ConvertPublisher(c.Request.Context(), orgID, listID, c.Param("domain"), userIDStr, &req)
+	if err != nil {
+		h.respondError(c, "Failed to convert publisher", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Publisher converted successfully",
+		"data":    conversion,
+	})
+}
+
+// ListConversions lists the publisher conversions of the organization
+// @Summary List publisher conversions
+// @Description Lists the publishers the organization converted into affiliates, most recently invited first
+// @Tags publisher-conversions
+// @Produce json
+// @Param status query string false "Conversion status" Enums(invited, associated, linked)
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PublisherConversionList"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-conversions [get]
+func (h *PublisherConversionHandler) ListConversions(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	filter := &domain.PublisherConversionFilter{}
+	if status := c.Query("status"); status != "" {
+		filter.Status = &status
+	}
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid limit", Details: "limit must be a valid integer"})
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := c.Query("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid offset", Details: "offset must be a valid integer"})
+			return
+		}
+		filter.Offset = value
+	}
+
+	conversions, err := h.conversionService.ListConversions(c.Request.Context(), orgID, filter)
+	if err != nil {
+		h.respondError(c, "Failed to list publisher conversions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publisher conversions retrieved successfully",
+		"data":    conversions,
+	})
+}
+
+// GetFunnel returns the publisher conversion funnel of the organization
+// @Summary Get the publisher conversion funnel
+// @Description Counts the publisher conversations started and the publishers invited during a period, how many of the invited
+// @Description publishers associated and created a first tracking link, the rates between these stages and the average days
+// @Description each stage took. Defaults to the last 90 days.
+// @Tags publisher-conversions
+// @Produce json
+// @Param from query string false "Start of the period (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "End of the period (RFC 3339 or YYYY-MM-DD, inclusive)"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PublisherConversionFunnel"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-conversions/funnel [get]
+func (h *PublisherConversionHandler) GetFunnel(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	from, ok := parseHistoryTimeParam(c, "from", false)
+	if !ok {
+		return
+	}
+	to, ok := parseHistoryTimeParam(c, "to", true)
+	if !ok {
+		return
+	}
+
+	funnel, err := h.conversionService.GetFunnel(c.Request.Context(), orgID, from, to)
+	if err != nil {
+		h.respondError(c, "Failed to get publisher conversion funnel", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publisher conversion funnel retrieved successfully",
+		"data":    funnel,
+	})
+}
+
+// GetConversion returns a publisher conversion
+// @Summary Get a publisher conversion
+// @Description Returns a publisher conversion with its affiliate organization and affiliate
+// @Tags publisher-conversions
+// @Produce json
+// @Param conversion_id path int true "Conversion ID"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PublisherConversion"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-conversions/{conversion_id} [get]
+func (h *PublisherConversionHandler) GetConversion(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	conversionID, ok := h.parseIDParam(c, "conversion_id")
+	if !ok {
+		return
+	}
+
+	conversion, err := h.conversionService.GetConversion(c.Request.Context(), orgID, conversionID)
+	if err != nil {
+		h.respondError(c, "Failed to get publisher conversion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publisher conversion retrieved successfully",
+		"data":    conversion,
+	})
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PublisherMessageTemplateHandler handles HTTP requests for reusable publisher message templates
+type PublisherMessageTemplateHandler struct {
+	templateService service.PublisherMessageTemplateService
+}
+
+// NewPublisherMessageTemplateHandler creates a new message template handler
+func NewPublisherMessageTemplateHandler(templateService service.PublisherMessageTemplateService) *PublisherMessageTemplateHandler {
+	return &PublisherMessageTemplateHandler{
+		templateService: templateService,
+	}
+}
+
+func (h *PublisherMessageTemplateHandler) getOrganizationID(c *gin.Context) (int64, bool) {
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+
+	orgID, ok := organizationID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: DetailInvalidOrgIDType,
+		})
+		return 0, false
+	}
+
+	return orgID, true
+}
+
+func (h *PublisherMessageTemplateHandler) parseTemplateID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("template_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid template_id",
+			Details: "template_id must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *PublisherMessageTemplateHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No template, conversation or list found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateTemplate creates a message template
+// @Summary Create a message template
+// @Description Creates a reusable message. The subject and body are templates with the merge fields {{.Domain}}, {{.Description}},
+// @Description {{.ContactEmail}}, {{.TopCountry}}, {{.Vertical}}, {{.TrafficScore}}, {{.Promotype}}, {{.OrganizationName}},
+// @Description {{.ListName}} and {{.Notes}}. The subject is only used when the template starts a conversation.
+// @Tags Publisher Messaging
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateMessageTemplateRequest true "Template"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.PublisherMessageTemplate"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-messaging/templates [post]
+func (h *PublisherMessageTemplateHandler) CreateTemplate(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var userID *string
+	if value, exists := c.Get("userID"); exists {
+		if id, ok := value.(string); ok && id != "" {
+			userID = &id
+		}
+	}
+
+	template, err := h.templateService.CreateTemplate(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to create message template", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Message template created successfully",
+		"data":    template,
+	})
+}
+
+// ListTemplates lists the message templates of the organization
+// @Summary List message templates
+// @Description Lists the message templates of the organization by name
+// @Tags Publisher Messaging
+// @Produce json
+// @Success 200 {object} map[string]interface{} "message: string, data: []domain.PublisherMessageTemplate"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-messaging/templates [get]
+func (h *PublisherMessageTemplateHandler) ListTemplates(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	templates, err := h.templateService.ListTemplates(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondError(c, "Failed to list message templates", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message templates retrieved successfully",
+		"data":    templates,
+	})
+}
+
+// GetTemplate returns a message template
+// @Summary Get a message template
+// @Tags Publisher Messaging
+// @Produce json
+// @Param template_id path int true "Template ID"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PublisherMessageTemplate"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-messaging/templates/{template_id} [get]
+func (h *PublisherMessageTemplateHandler) GetTemplate(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	templateID, ok := h.parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(c.Request.Context(), orgID, templateID)
+	if err != nil {
+		h.respondError(c, "Failed to get message template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message template retrieved successfully",
+		"data":    template,
+	})
+}
+
+// UpdateTemplate updates a message template
+// @Summary Update a message template
+// @Description Renames a template or replaces its subject or body; an empty subject removes it
+// @Tags Publisher Messaging
+// @Accept json
+// @Produce json
+// @Param template_id path int true "Template ID"
+// @Param request body domain.UpdateMessageTemplateRequest true "Changes"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PublisherMessageTemplate"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-messaging/templates/{template_id} [put]
+func (h *PublisherMessageTemplateHandler) UpdateTemplate(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	templateID, ok := h.parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(c.Request.Context(), orgID, templateID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to update message template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message template updated successfully",
+		"data":    template,
+	})
+}
+
+// DeleteTemplate deletes a message template
+// @Summary Delete a message template
+// @Description Deletes a template; messages already sent with it are kept
+// @Tags Publisher Messaging
+// @Produce json
+// @Param template_id path int true "Template ID"
+// @Success 200 {object} map[string]interface{} "message: string"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-messaging/templates/{template_id} [delete]
+func (h *PublisherMessageTemplateHandler) DeleteTemplate(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	templateID, ok := h.parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(c.Request.Context(), orgID, templateID); err != nil {
+		h.respondError(c, "Failed to delete message template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message template deleted successfully",
+	})
+}
+
+// RenderTemplate previews a message template
+// @Summary Preview a message template
+// @Description Renders a template for the publisher of a conversation, for a publisher domain (with the notes from a favorite list
+// @Description when list_id is given), or with sample data when neither is given
+// @Tags Publisher Messaging
+// @Accept json
+// @Produce json
+// @Param template_id path int true "Template ID"
+// @Param request body domain.RenderMessageTemplateRequest false "Publisher to render for"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.RenderedMessageTemplate"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /publisher-messaging/templates/{template_id}/render [post]
+func (h *PublisherMessageTemplateHandler) RenderTemplate(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	templateID, ok := h.parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.RenderMessageTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   ErrInvalidRequestBody,
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	rendered, err := h.templateService.RenderTemplate(c.Request.Context(), orgID, templateID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to render message template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message template rendered successfully",
+		"data":    rendered,
+	})
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/affiliate-backend/internal/domain"
 	"github.com/affiliate-backend/internal/service"
@@ -21,9 +25,32 @@ func NewPublisherMessagingHandler(messagingService service.PublisherMessagingSer
 	}
 }
 
+func (h *PublisherMessagingHandler) getUserID(c *gin.Context) string {
+	var userID string
+	if value, exists := c.Get("userID"); exists {
+		userID, _ = value.(string)
+	}
+	return userID
+}
+
+// requireUserID returns the authenticated user, whose read state is tracked
+func (h *PublisherMessagingHandler) requireUserID(c *gin.Context) (string, bool) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: "User ID not found in context",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
 // CreateConversation creates a new conversation with a publisher
 // @Summary Create a new conversation with a publisher
-// @Description Initiates a new conversation with a publisher from a favorite list
+// @Description Initiates a new conversation with a publisher from a favorite list. The initial message is emailed to the publisher's contact address;
+// @Description the delivery status is recorded in the message metadata under email_delivery. With a template_id, an empty subject or
+// @Description initial message is taken from the template rendered for the publisher.
 // @Tags Publisher Messaging
 // @Accept json
 // @Produce json
@@ -58,6 +85,20 @@ func (h *PublisherMessagingHandler) CreateConversation(c *gin.Context) {
 
 	conversation, err := h.messagingService.CreateConversation(c.Request.Context(), organizationID, &req)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Template or list not found",
+				Details: "The specified template or favorite list does not exist or you don't have access to it",
+			})
+			return
+		}
 		switch err.Error() {
 		case "publisher not found":
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -83,10 +124,12 @@ func (h *PublisherMessagingHandler) CreateConversation(c *gin.Context) {
 
 // GetConversations retrieves conversations for the organization
 // @Summary Get conversations for organization
-// @Description Retrieves a paginated list of conversations for the organization
+// @Description Retrieves a paginated list of conversations for the organization with the number of publisher messages the current user
+// @Description has not read, and the unread total across all conversations
 // @Tags Publisher Messaging
 // @Produce json
 // @Param status query string false "Filter by conversation status (active, closed)"
+// @Param unread_only query bool false "Only conversations with unread publisher messages"
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 20)"
 // @Success 200 {object} domain.ConversationListResponse "Conversations retrieved successfully"
@@ -105,11 +148,15 @@ func (h *PublisherMessagingHandler) GetConversations(c *gin.Context) {
 	}
 	organizationID := userOrgID.(int64)
 
-	status := c.Query("status")
+	filter := &domain.ConversationListFilter{
+		Status:     c.Query("status"),
+		UnreadOnly: c.Query("unread_only") == "true",
+		UserID:     h.getUserID(c),
+	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
-	response, err := h.messagingService.GetConversations(c.Request.Context(), organizationID, status, page, pageSize)
+	response, err := h.messagingService.GetConversations(c.Request.Context(), organizationID, filter, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Failed to retrieve conversations",
@@ -123,7 +170,8 @@ func (h *PublisherMessagingHandler) GetConversations(c *gin.Context) {
 
 // GetConversation retrieves a specific conversation with messages
 // @Summary Get conversation with messages
-// @Description Retrieves a specific conversation along with its messages
+// @Description Retrieves a specific conversation along with its messages. Publisher messages carry the current user's read state;
+// @Description viewing the conversation does not mark them as read.
 // @Tags Publisher Messaging
 // @Produce json
 // @Param conversation_id path int true "Conversation ID"
@@ -155,7 +203,7 @@ func (h *PublisherMessagingHandler) GetConversation(c *gin.Context) {
 		return
 	}
 
-	response, err := h.messagingService.GetConversationMessages(c.Request.Context(), organizationID, conversationID, 1, 100)
+	response, err := h.messagingService.GetConversationMessages(c.Request.Context(), organizationID, conversationID, h.getUserID(c), 1, 100)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -176,9 +224,13 @@ func (h *PublisherMessagingHandler) GetConversation(c *gin.Context) {
 
 // AddMessage adds a message to a conversation
 // @Summary Add message to conversation
-// @Description Adds a new message to an existing conversation
+// @Description Adds a new message to an existing conversation. Text messages are emailed to the publisher with a Reply-To address
+// @Description that routes replies back to the conversation; the delivery status is recorded in the message metadata under email_delivery.
+// @Description With a template_id and no content, the template body rendered for the publisher is sent. Files can be attached by
+// @Description sending multipart/form-data with content, message_type and template_id fields and up to 10 "attachments" files of
+// @Description at most 10 MB each; they are listed in the message metadata under "attachments".
 // @Tags Publisher Messaging
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Param conversation_id path int true "Conversation ID"
 // @Param request body domain.SendMessageRequest true "Message request"
@@ -211,7 +263,12 @@ func (h *PublisherMessagingHandler) AddMessage(c *gin.Context) {
 	}
 
 	var req domain.SendMessageRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		err = bindMultipartMessage(c, &req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid request body",
 			Details: err.Error(),
@@ -221,7 +278,12 @@ func (h *PublisherMessagingHandler) AddMessage(c *gin.Context) {
 
 	message, err := h.messagingService.SendMessage(c.Request.Context(), organizationID, conversationID, &req)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Details: err.Error(),
+			})
+		} else if err == domain.ErrNotFound {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Error:   "Conversation not found",
 				Details: "The specified conversation does not exist or you don't have access to it",
@@ -238,6 +300,266 @@ func (h *PublisherMessagingHandler) AddMessage(c *gin.Context) {
 	c.JSON(http.StatusCreated, message)
 }
 
+// bindMultipartMessage reads a message and its attached files from a multipart form
+func bindMultipartMessage(c *gin.Context, req *domain.SendMessageRequest) error {
+	maxBody := int64(domain.MaxMessageAttachments*domain.MaxMessageAttachmentBytes + 1024*1024)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return fmt.Errorf("invalid multipart form: %w", err)
+	}
+
+	req.Content = c.PostForm("content")
+	if messageType := c.PostForm("message_type"); messageType != "" {
+		req.MessageType = &messageType
+	}
+	if templateID := c.PostForm("template_id"); templateID != "" {
+		id, err := strconv.ParseInt(templateID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("template_id must be a valid integer")
+		}
+		req.TemplateID = &id
+	}
+
+	files := form.File["attachments"]
+	if len(files) > domain.MaxMessageAttachments {
+		return fmt.Errorf("at most %d attachments are allowed", domain.MaxMessageAttachments)
+	}
+	for _, header := range files {
+		if header.Size > domain.MaxMessageAttachmentBytes {
+			return fmt.Errorf("attachment %s is larger than %d MB", header.Filename, domain.MaxMessageAttachmentBytes/(1024*1024))
+		}
+		file, err := header.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read attachment %s: %w", header.Filename, err)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read attachment %s: %w", header.Filename, err)
+		}
+
+		contentType := header.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		req.Attachments = append(req.Attachments, domain.MessageAttachmentUpload{
+			Filename:    header.Filename,
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+	return nil
+}
+
+// MarkConversationRead marks the publisher messages of a conversation as read
+// @Summary Mark conversation as read
+// @Description Marks the publisher messages of a conversation as read by the current user, all of them or up to a message
+// @Tags Publisher Messaging
+// @Accept json
+// @Produce json
+// @Param conversation_id path int true "Conversation ID"
+// @Param request body domain.MarkConversationReadRequest false "Last message to mark"
+// @Success 200 {object} map[string]interface{} "message: string, data: {marked: int}"
+// @Failure 400 {object} ErrorResponse "Invalid request body or conversation ID"
+// @Failure 401 {object} ErrorResponse "Organization or user ID not found in context"
+// @Failure 404 {object} ErrorResponse "Conversation not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /publisher-messaging/conversations/{conversation_id}/read [post]
+func (h *PublisherMessagingHandler) MarkConversationRead(c *gin.Context) {
+	organizationID, conversationID, ok := h.conversationParams(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.requireUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.MarkConversationReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request body",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	marked, err := h.messagingService.MarkConversationRead(c.Request.Context(), organizationID, conversationID, userID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Conversation marked as read",
+		"data":    gin.H{"marked": marked},
+	})
+}
+
+// MarkMessageRead marks a publisher message as read
+// @Summary Mark message as read
+// @Description Marks a publisher message as read by the current user. Organization messages have no read state.
+// @Tags Publisher Messaging
+// @Produce json
+// @Param conversation_id path int true "Conversation ID"
+// @Param message_id path int true "Message ID"
+// @Success 204 "Message marked as read"
+// @Failure 400 {object} ErrorResponse "Invalid path parameter"
+// @Failure 401 {object} ErrorResponse "Organization or user ID not found in context"
+// @Failure 404 {object} ErrorResponse "Conversation or message not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /publisher-messaging/conversations/{conversation_id}/messages/{message_id}/read [post]
+func (h *PublisherMessagingHandler) MarkMessageRead(c *gin.Context) {
+	h.setMessageReadState(c, true)
+}
+
+// MarkMessageUnread marks a publisher message as unread
+// @Summary Mark message as unread
+// @Description Marks a publisher message as unread for the current user
+// @Tags Publisher Messaging
+// @Produce json
+// @Param conversation_id path int true "Conversation ID"
+// @Param message_id path int true "Message ID"
+// @Success 204 "Message marked as unread"
+// @Failure 400 {object} ErrorResponse "Invalid path parameter"
+// @Failure 401 {object} ErrorResponse "Organization or user ID not found in context"
+// @Failure 404 {object} ErrorResponse "Conversation or message not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /publisher-messaging/conversations/{conversation_id}/messages/{message_id}/read [delete]
+func (h *PublisherMessagingHandler) MarkMessageUnread(c *gin.Context) {
+	h.setMessageReadState(c, false)
+}
+
+func (h *PublisherMessagingHandler) setMessageReadState(c *gin.Context, read bool) {
+	organizationID, conversationID, ok := h.conversationParams(c)
+	if !ok {
+		return
+	}
+	messageID, err := strconv.ParseInt(c.Param("message_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid message ID", Details: "Message ID must be a valid integer"})
+		return
+	}
+	userID, ok := h.requireUserID(c)
+	if !ok {
+		return
+	}
+
+	if read {
+		err = h.messagingService.MarkMessageRead(c.Request.Context(), organizationID, conversationID, messageID, userID)
+	} else {
+		err = h.messagingService.MarkMessageUnread(c.Request.Context(), organizationID, conversationID, messageID, userID)
+	}
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SearchMessages full-text searches the organization's conversation messages
+// @Summary Search messages
+// @Description Full-text searches the messages of the organization's conversations, best match first. The query supports quoted
+// @Description phrases, "or" and a leading "-" to exclude a word. Each result includes its conversation and an excerpt with the
+// @Description matching words wrapped in <b></b>.
+// @Tags Publisher Messaging
+// @Produce json
+// @Param q query string true "Search query"
+// @Param conversation_id query int false "Only search this conversation"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max 100)"
+// @Success 200 {object} domain.MessageSearchResponse "Matching messages"
+// @Failure 400 {object} ErrorResponse "Invalid query"
+// @Failure 401 {object} ErrorResponse "Organization ID not found in context"
+// @Failure 404 {object} ErrorResponse "Conversation not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /publisher-messaging/messages/search [get]
+func (h *PublisherMessagingHandler) SearchMessages(c *gin.Context) {
+	userOrgID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Organization ID not found in context",
+			Details: "Please ensure you are properly authenticated",
+		})
+		return
+	}
+	organizationID := userOrgID.(int64)
+
+	var conversationID *int64
+	if value := c.Query("conversation_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid conversation ID", Details: "Conversation ID must be a valid integer"})
+			return
+		}
+		conversationID = &id
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	response, err := h.messagingService.SearchMessages(c.Request.Context(), organizationID, c.Query("q"), conversationID, page, pageSize)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// conversationParams returns the organization and the conversation ID path parameter
+func (h *PublisherMessagingHandler) conversationParams(c *gin.Context) (int64, int64, bool) {
+	userOrgID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Organization ID not found in context",
+			Details: "Please ensure you are properly authenticated",
+		})
+		return 0, 0, false
+	}
+	organizationID := userOrgID.(int64)
+
+	conversationID, err := strconv.ParseInt(c.Param("conversation_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid conversation ID",
+			Details: "Conversation ID must be a valid integer",
+		})
+		return 0, 0, false
+	}
+
+	return organizationID, conversationID, true
+}
+
+// respondError maps read state and search errors to HTTP responses
+func (h *PublisherMessagingHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Conversation or message not found",
+			Details: "The specified conversation or message does not exist or you don't have access to it",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to process request",
+			Details: err.Error(),
+		})
+	}
+}
+
 // UpdateConversationStatus updates the status of a conversation
 // @Summary Update conversation status
 // @Description Updates the status of a conversation (e.g., close conversation)
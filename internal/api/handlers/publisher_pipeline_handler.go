@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PublisherPipelineHandler handles HTTP requests for the outreach pipeline of favorite publisher lists
+type PublisherPipelineHandler struct {
+	pipelineService service.PublisherPipelineService
+}
+
+// NewPublisherPipelineHandler creates a new publisher pipeline handler
+func NewPublisherPipelineHandler(pipelineService service.PublisherPipelineService) *PublisherPipelineHandler {
+	return &PublisherPipelineHandler{
+		pipelineService: pipelineService,
+	}
+}
+
+func (h *PublisherPipelineHandler) getOrganizationID(c *gin.Context) (int64, bool) {
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+
+	orgID, ok := organizationID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: DetailInvalidOrgIDType,
+		})
+		return 0, false
+	}
+
+	return orgID, true
+}
+
+func (h *PublisherPipelineHandler) getUserID(c *gin.Context) string {
+	var userID string
+	if value, exists := c.Get("userID"); exists {
+		userID, _ = value.(string)
+	}
+	return userID
+}
+
+func (h *PublisherPipelineHandler) parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name,
+			Details: name + " must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *PublisherPipelineHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No list, publisher or pipeline stage found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// ListStages lists the pipeline stages of the organization
+// @Summary List pipeline stages
+// @Description Lists the outreach pipeline stages of the organization in board order. Organizations start with
+// @Description researching, contacted, negotiating (open), signed (won) and rejected (lost).
+// @Tags favorite-publisher-lists
+// @Produce json
+// @Success 200 {object} map[string]interface{} "message: string, data: []domain.PipelineStage"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/pipeline-stages [get]
+func (h *PublisherPipelineHandler) ListStages(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	stages, err := h.pipelineService.ListStages(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondError(c, "Failed to list pipeline stages", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline stages retrieved successfully",
+		"data":    stages,
+	})
+}
+
+// CreateStage adds a pipeline stage
+// @Summary Create a pipeline stage
+// @Description Adds a stage to the organization's outreach pipeline, at the end of the board unless a position is given
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param request body domain.CreatePipelineStageRequest true "Stage"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.PipelineStage"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/pipeline-stages [post]
+func (h *PublisherPipelineHandler) CreateStage(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreatePipelineStageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	stage, err := h.pipelineService.CreateStage(c.Request.Context(), orgID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to create pipeline stage", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Pipeline stage created successfully",
+		"data":    stage,
+	})
+}
+
+// UpdateStage updates a pipeline stage
+// @Summary Update a pipeline stage
+// @Description Renames, recolors or changes the outcome of a pipeline stage. The pipeline must keep at least one open stage.
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param stage_id path int true "Stage ID"
+// @Param request body domain.UpdatePipelineStageRequest true "Stage changes"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PipelineStage"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/pipeline-stages/{stage_id} [put]
+func (h *PublisherPipelineHandler) UpdateStage(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	stageID, ok := h.parseIDParam(c, "stage_id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdatePipelineStageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	stage, err := h.pipelineService.UpdateStage(c.Request.Context(), orgID, stageID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to update pipeline stage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline stage updated successfully",
+		"data":    stage,
+	})
+}
+
+// DeleteStage deletes an empty pipeline stage
+// @Summary Delete a pipeline stage
+// @Description Deletes a pipeline stage. Publishers in the stage must be moved to another stage first.
+// @Tags favorite-publisher-lists
+// @Produce json
+// @Param stage_id path int true "Stage ID"
+// @Success 200 {object} map[string]interface{} "message: string"
+// @Failure 400 {object} ErrorResponse "Stage still holds publishers or is the last open stage"
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/pipeline-stages/{stage_id} [delete]
+func (h *PublisherPipelineHandler) DeleteStage(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	stageID, ok := h.parseIDParam(c, "stage_id")
+	if !ok {
+		return
+	}
+
+	if err := h.pipelineService.DeleteStage(c.Request.Context(), orgID, stageID); err != nil {
+		h.respondError(c, "Failed to delete pipeline stage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline stage deleted successfully",
+	})
+}
+
+// ReorderStages changes the board order of the pipeline stages
+// @Summary Reorder pipeline stages
+// @Description Sets the board order of the pipeline stages; stage_ids must list every stage of the organization once
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param request body domain.ReorderPipelineStagesRequest true "Stage IDs in board order"
+// @Success 200 {object} map[string]interface{} "message: string, data: []domain.PipelineStage"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/pipeline-stages/order [put]
+func (h *PublisherPipelineHandler) ReorderStages(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ReorderPipelineStagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	stages, err := h.pipelineService.ReorderStages(c.Request.Context(), orgID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to reorder pipeline stages", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline stages reordered successfully",
+		"data":    stages,
+	})
+}
+
+// GetBoard returns the kanban board of a favorite list
+// @Summary Get the pipeline board of a list
+// @Description Returns the publishers of a favorite list grouped by pipeline stage, with publisher details and the
+// @Description active conversation with each publisher, if any
+// @Tags favorite-publisher-lists
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param owner_user_id query string false "Only publishers owned by this user; use me for the current user"
+// @Param due_before query string false "Only publishers with a next action due before this time (RFC 3339 or YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.PipelineBoard"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/board [get]
+func (h *PublisherPipelineHandler) GetBoard(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	listID, ok := h.parseIDParam(c, "list_id")
+	if !ok {
+		return
+	}
+
+	query := &domain.PipelineBoardQuery{}
+	if owner := c.Query("owner_user_id"); owner != "" {
+		if owner == "me" {
+			owner = h.getUserID(c)
+		}
+		query.OwnerUserID = &owner
+	}
+	dueBefore, ok := parseHistoryTimeParam(c, "due_before", false)
+	if !ok {
+		return
+	}
+	query.DueBefore = dueBefore
+
+	board, err := h.pipelineService.GetBoard(c.Request.Context(), orgID, listID, query)
+	if err != nil {
+		h.respondError(c, "Failed to retrieve pipeline board", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pipeline board retrieved successfully",
+		"data":    board,
+	})
+}
+
+// MoveItem moves a publisher to another pipeline stage
+// @Summary Move a publisher to a pipeline stage
+// @Description Moves a publisher of a favorite list to another stage, records the change in its stage history and
+// @Description keeps the legacy status in sync. The change is noted in the active conversation with the publisher.
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param domain path string true "Publisher domain"
+// @Param request body domain.MovePublisherStageRequest true "Target stage"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.FavoritePublisherListItem"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/publishers/{domain}/stage [patch]
+func (h *PublisherPipelineHandler) MoveItem(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	listID, ok := h.parseIDParam(c, "list_id")
+	if !ok {
+		return
+	}
+
+	var req domain.MovePublisherStageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	item, err := h.pipelineService.MoveItem(c.Request.Context(), orgID, listID, c.Param("domain"), h.getUserID(c), &req)
+	if err != nil {
+		h.respondError(c, "Failed to move publisher", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Publisher moved successfully",
+		"data":    item,
+	})
+}
+
+// UpdateFollowUp sets the owner and next action of a publisher in a list
+// @Summary Set the owner and next action of a publisher
+// @Description Sets who owns the outreach to a publisher and when to follow up. The owner is emailed a reminder once
+// @Description the next action is due. Omitted fields are cleared.
+// @Tags favorite-publisher-lists
+// @Accept json
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param domain path string true "Publisher domain"
+// @Param request body domain.UpdatePublisherFollowUpRequest true "Owner and next action"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.FavoritePublisherListItem"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/publishers/{domain}/follow-up [put]
+func (h *PublisherPipelineHandler) UpdateFollowUp(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	listID, ok := h.parseIDParam(c, "list_id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdatePublisherFollowUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	item, err := h.pipelineService.UpdateFollowUp(c.Request.Context(), orgID, listID, c.Param("domain"), &req)
+	if err != nil {
+		h.respondError(c, "Failed to update follow-up", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Follow-up updated successfully",
+		"data":    item,
+	})
+}
+
+// GetItemHistory returns the stage history of a publisher in a list
+// @Summary Get the stage history of a publisher
+// @Description Lists the pipeline stage changes of a publisher in a favorite list, most recent first
+// @Tags favorite-publisher-lists
+// @Produce json
+// @Param list_id path int true "List ID"
+// @Param domain path string true "Publisher domain"
+// @Param limit query int false "Maximum number of changes (default 50, max 500)"
+// @Success 200 {object} map[string]interface{} "message: string, data: []domain.PipelineStageChange"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /favorite-publisher-lists/{list_id}/publishers/{domain}/history [get]
+func (h *PublisherPipelineHandler) GetItemHistory(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	listID, ok := h.parseIDParam(c, "list_id")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	changes, err := h.pipelineService.GetItemHistory(c.Request.Context(), orgID, listID, c.Param("domain"), limit)
+	if err != nil {
+		h.respondError(c, "Failed to retrieve stage history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stage history retrieved successfully",
+		"data":    changes,
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles HTTP requests for performance reports
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// RunReport runs a performance report
+// @Summary Run a performance report
+// @Description Aggregates clicks, conversions, revenue and payout grouped by date, hour, campaign, affiliate, sub1-sub5, country and device.
+// @Description Results include CR, EPC, CPA and margin. Data is limited to the caller's organization and its active associations;
+// @Description affiliates never see revenue and advertisers never see payout or margin.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param request body domain.ReportRequest true "Report query"
+// @Success 200 {object} domain.ReportResponse "Report rows and totals"
+// @Failure 400 {object} ErrorResponse "Invalid report query"
+// @Failure 401 {object} ErrorResponse "Organization ID not found in context"
+// @Failure 403 {object} ErrorResponse "Organization cannot access reports"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /reports/query [post]
+func (h *ReportHandler) RunReport(c *gin.Context) {
+	userOrgID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return
+	}
+	organizationID, ok := userOrgID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: DetailInvalidOrgIDType,
+		})
+		return
+	}
+
+	var req domain.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	report, err := h.reportService.RunReport(c.Request.Context(), organizationID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid report query",
+				Details: err.Error(),
+			})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Details: "Your organization cannot access performance reports",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Failed to run report",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduledReportHandler handles HTTP requests for scheduled report exports
+type ScheduledReportHandler struct {
+	scheduledReportService service.ScheduledReportService
+}
+
+// NewScheduledReportHandler creates a new scheduled report handler
+func NewScheduledReportHandler(scheduledReportService service.ScheduledReportService) *ScheduledReportHandler {
+	return &ScheduledReportHandler{
+		scheduledReportService: scheduledReportService,
+	}
+}
+
+func (h *ScheduledReportHandler) getOrganizationID(c *gin.Context) (int64, bool) {
+	organizationID, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+
+	orgID, ok := organizationID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: DetailInvalidOrgIDType,
+		})
+		return 0, false
+	}
+
+	return orgID, true
+}
+
+func (h *ScheduledReportHandler) parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name,
+			Details: name + " must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *ScheduledReportHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid scheduled report",
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Scheduled report not found",
+			Details: "No scheduled report or run found with the specified ID",
+		})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "Your organization cannot access performance reports",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateScheduledReport creates a new scheduled report
+// @Summary Create a scheduled report
+// @Description Saves a report query that is rendered as CSV, XLSX or JSON Lines on a cron schedule and delivered by email, SFTP or to an S3-compatible bucket.
+// @Description The schedule is a standard 5-field cron expression evaluated in the report timezone. Delivery secrets are write-only.
+// @Tags Scheduled Reports
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateScheduledReportRequest true "Scheduled report definition"
+// @Success 201 {object} domain.ScheduledReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports [post]
+func (h *ScheduledReportHandler) CreateScheduledReport(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateScheduledReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	var userID string
+	if value, exists := c.Get("userID"); exists {
+		userID, _ = value.(string)
+	}
+
+	report, err := h.scheduledReportService.CreateScheduledReport(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to create scheduled report", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// ListScheduledReports lists the organization's scheduled reports
+// @Summary List scheduled reports
+// @Description Lists the scheduled reports of the caller's organization
+// @Tags Scheduled Reports
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} domain.ScheduledReportListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports [get]
+func (h *ScheduledReportHandler) ListScheduledReports(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPaginationParams(c)
+
+	resp, err := h.scheduledReportService.ListScheduledReports(c.Request.Context(), orgID, page, pageSize)
+	if err != nil {
+		h.respondError(c, "Failed to list scheduled reports", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetScheduledReport retrieves a scheduled report
+// @Summary Get a scheduled report
+// @Description Retrieves a scheduled report of the caller's organization
+// @Tags Scheduled Reports
+// @Produce json
+// @Param id path int true "Scheduled report ID"
+// @Success 200 {object} domain.ScheduledReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports/{id} [get]
+func (h *ScheduledReportHandler) GetScheduledReport(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.scheduledReportService.GetScheduledReport(c.Request.Context(), orgID, id)
+	if err != nil {
+		h.respondError(c, "Failed to get scheduled report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// UpdateScheduledReport updates a scheduled report
+// @Summary Update a scheduled report
+// @Description Updates a scheduled report. Omitted fields and delivery secrets keep their current values; the next run time is recomputed.
+// @Tags Scheduled Reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled report ID"
+// @Param request body domain.UpdateScheduledReportRequest true "Fields to update"
+// @Success 200 {object} domain.ScheduledReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports/{id} [put]
+func (h *ScheduledReportHandler) UpdateScheduledReport(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateScheduledReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	report, err := h.scheduledReportService.UpdateScheduledReport(c.Request.Context(), orgID, id, &req)
+	if err != nil {
+		h.respondError(c, "Failed to update scheduled report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DeleteScheduledReport deletes a scheduled report
+// @Summary Delete a scheduled report
+// @Description Deletes a scheduled report and its run history
+// @Tags Scheduled Reports
+// @Param id path int true "Scheduled report ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports/{id} [delete]
+func (h *ScheduledReportHandler) DeleteScheduledReport(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.scheduledReportService.DeleteScheduledReport(c.Request.Context(), orgID, id); err != nil {
+		h.respondError(c, "Failed to delete scheduled report", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunScheduledReport runs a scheduled report immediately
+// @Summary Run a scheduled report now
+// @Description Renders and delivers the report immediately without changing its schedule. Delivery failures are reported in the run status.
+// @Tags Scheduled Reports
+// @Produce json
+// @Param id path int true "Scheduled report ID"
+// @Success 200 {object} domain.ScheduledReportRun
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports/{id}/run [post]
+func (h *ScheduledReportHandler) RunScheduledReport(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := h.scheduledReportService.RunNow(c.Request.Context(), orgID, id)
+	if err != nil {
+		h.respondError(c, "Failed to run scheduled report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ListRuns lists the run history of a scheduled report
+// @Summary List scheduled report runs
+// @Description Lists past runs of a scheduled report, most recent first, with download links for archived files
+// @Tags Scheduled Reports
+// @Produce json
+// @Param id path int true "Scheduled report ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} domain.ScheduledReportRunListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports/{id}/runs [get]
+func (h *ScheduledReportHandler) ListRuns(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPaginationParams(c)
+
+	resp, err := h.scheduledReportService.ListRuns(c.Request.Context(), orgID, id, page, pageSize)
+	if err != nil {
+		h.respondError(c, "Failed to list scheduled report runs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DownloadRun downloads the file of a past run
+// @Summary Download a scheduled report file
+// @Description Downloads the archived file of a past scheduled report run
+// @Tags Scheduled Reports
+// @Produce octet-stream
+// @Param id path int true "Scheduled report ID"
+// @Param run_id path int true "Run ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /scheduled-reports/{id}/runs/{run_id}/download [get]
+func (h *ScheduledReportHandler) DownloadRun(c *gin.Context) {
+	orgID, ok := h.getOrganizationID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	runID, ok := h.parseIDParam(c, "run_id")
+	if !ok {
+		return
+	}
+
+	run, data, err := h.scheduledReportService.DownloadRun(c.Request.Context(), orgID, id, runID)
+	if err != nil {
+		h.respondError(c, "Failed to download scheduled report file", err)
+		return
+	}
+
+	contentType := "application/octet-stream"
+	if run.ContentType != nil {
+		contentType = *run.ContentType
+	}
+	fileName := "report"
+	if run.FileName != nil {
+		fileName = *run.FileName
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, contentType, data)
+}
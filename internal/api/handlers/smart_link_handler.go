@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// countryHeaders carry the visitor's country when the API runs behind a CDN or load balancer
+var countryHeaders = []string{"CF-IPCountry", "CloudFront-Viewer-Country", "X-Country-Code"}
+
+// requestCountry returns the visitor's country from the first country header set, or "". The
+// headers are only believed on requests forwarded by a trusted proxy; anyone can set them otherwise.
+func requestCountry(c *gin.Context) string {
+	if !c.GetBool(middleware.TrustedProxyKey) {
+		return ""
+	}
+	for _, header := range countryHeaders {
+		if country := strings.ToUpper(strings.TrimSpace(c.GetHeader(header))); len(country) == 2 {
+			return country
+		}
+	}
+	return ""
+}
+
+// SmartLinkHandler handles HTTP requests for smart links
+type SmartLinkHandler struct {
+	smartLinkService service.SmartLinkService
+	geoLocator       service.GeoLocator
+}
+
+// NewSmartLinkHandler creates a new smart link handler. Without a geoLocator, visitors are only
+// located by the country headers of trusted proxies.
+func NewSmartLinkHandler(smartLinkService service.SmartLinkService, geoLocator service.GeoLocator) *SmartLinkHandler {
+	return &SmartLinkHandler{
+		smartLinkService: smartLinkService,
+		geoLocator:       geoLocator,
+	}
+}
+
+// authorizeOrganization parses the :id organization and checks that the user belongs to it
+// (administrators may access any organization)
+func (h *SmartLinkHandler) authorizeOrganization(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, false
+	}
+	return orgID, true
+}
+
+// parseSmartLinkID parses the :smart_link_id path parameter
+func (h *SmartLinkHandler) parseSmartLinkID(c *gin.Context) (int64, bool) {
+	smartLinkID, err := strconv.ParseInt(c.Param("smart_link_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid smart link ID",
+			Details: "Smart link ID must be a valid integer",
+		})
+		return 0, false
+	}
+	return smartLinkID, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *SmartLinkHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "No smart link found with the specified ID",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateSmartLink creates a smart link
+// @Summary Create smart link
+// @Description Creates a single link that routes each click to one of the affiliate's tracking links. Destinations
+// @Description whose rules (countries, devices, day parts), daily cap, tracking link and campaign status accept the
+// @Description click are picked in proportion to their weight; other clicks go to the fallback URL.
+// @Tags smart-links
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body domain.CreateSmartLinkRequest true "Smart link"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.SmartLink"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links [post]
+func (h *SmartLinkHandler) CreateSmartLink(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateSmartLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	smartLink, err := h.smartLinkService.CreateSmartLink(c.Request.Context(), orgID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to create smart link", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Smart link created successfully",
+		"data":    smartLink,
+	})
+}
+
+// ListSmartLinks lists the organization's smart links
+// @Summary List smart links
+// @Tags smart-links
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} map[string]interface{} "data: []domain.SmartLink"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links [get]
+func (h *SmartLinkHandler) ListSmartLinks(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	smartLinks, err := h.smartLinkService.ListSmartLinks(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondError(c, "Failed to list smart links", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": smartLinks})
+}
+
+// GetSmartLink returns a smart link
+// @Summary Get smart link
+// @Tags smart-links
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param smart_link_id path int true "Smart Link ID"
+// @Success 200 {object} map[string]interface{} "data: domain.SmartLink"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links/{smart_link_id} [get]
+func (h *SmartLinkHandler) GetSmartLink(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	smartLinkID, ok := h.parseSmartLinkID(c)
+	if !ok {
+		return
+	}
+
+	smartLink, err := h.smartLinkService.GetSmartLink(c.Request.Context(), orgID, smartLinkID)
+	if err != nil {
+		h.respondError(c, "Failed to get smart link", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": smartLink})
+}
+
+// UpdateSmartLink updates a smart link
+// @Summary Update smart link
+// @Description Updates the name, status and fallback URL. Destinations, when given, replace every destination;
+// @Description click stats are kept per tracking link.
+// @Tags smart-links
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param smart_link_id path int true "Smart Link ID"
+// @Param request body domain.UpdateSmartLinkRequest true "Changes"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.SmartLink"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links/{smart_link_id} [put]
+func (h *SmartLinkHandler) UpdateSmartLink(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	smartLinkID, ok := h.parseSmartLinkID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateSmartLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	smartLink, err := h.smartLinkService.UpdateSmartLink(c.Request.Context(), orgID, smartLinkID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to update smart link", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Smart link updated successfully",
+		"data":    smartLink,
+	})
+}
+
+// DeleteSmartLink deletes a smart link
+// @Summary Delete smart link
+// @Tags smart-links
+// @Param id path int true "Organization ID"
+// @Param smart_link_id path int true "Smart Link ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links/{smart_link_id} [delete]
+func (h *SmartLinkHandler) DeleteSmartLink(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	smartLinkID, ok := h.parseSmartLinkID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.smartLinkService.DeleteSmartLink(c.Request.Context(), orgID, smartLinkID); err != nil {
+		h.respondError(c, "Failed to delete smart link", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SimulateSmartLink evaluates a click against a smart link
+// @Summary Simulate smart link click
+// @Description Shows which destinations accept a described click, with the reason the others are skipped and the
+// @Description probability of each, without redirecting or counting the click. With clicks set, that many clicks
+// @Description are drawn and counted per tracking link (0 for the fallback URL).
+// @Tags smart-links
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param smart_link_id path int true "Smart Link ID"
+// @Param request body domain.SimulateSmartLinkRequest true "Click"
+// @Success 200 {object} map[string]interface{} "data: domain.SmartLinkSimulation"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links/{smart_link_id}/simulate [post]
+func (h *SmartLinkHandler) SimulateSmartLink(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	smartLinkID, ok := h.parseSmartLinkID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SimulateSmartLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	simulation, err := h.smartLinkService.SimulateSmartLink(c.Request.Context(), orgID, smartLinkID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to simulate smart link", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": simulation})
+}
+
+// GetSmartLinkStats returns the clicks per destination of a smart link
+// @Summary Get smart link stats
+// @Tags smart-links
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param smart_link_id path int true "Smart Link ID"
+// @Param from query string false "First UTC day (YYYY-MM-DD), 29 days before to by default"
+// @Param to query string false "Last UTC day (YYYY-MM-DD), today by default"
+// @Success 200 {object} map[string]interface{} "data: domain.SmartLinkStats"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/smart-links/{smart_link_id}/stats [get]
+func (h *SmartLinkHandler) GetSmartLinkStats(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	smartLinkID, ok := h.parseSmartLinkID(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid to",
+				Details: "to must be a YYYY-MM-DD date",
+			})
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -29)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid from",
+				Details: "from must be a YYYY-MM-DD date",
+			})
+			return
+		}
+		from = parsed
+	}
+
+	stats, err := h.smartLinkService.GetSmartLinkStats(c.Request.Context(), orgID, smartLinkID, from, to)
+	if err != nil {
+		h.respondError(c, "Failed to get smart link stats", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// RedirectSmartLink routes a click on a smart link
+// @Summary Follow smart link
+// @Description Public endpoint behind smart links. The country is read from the CDN country header
+// @Description (CF-IPCountry, CloudFront-Viewer-Country or X-Country-Code) of trusted proxies, or located
+// @Description through GeoIP, and the device from the User-Agent.
+// @Tags smart-links
+// @Param smart_link_id path int true "Smart Link ID"
+// @Success 302 "Redirect to the selected tracking link or the fallback URL"
+// @Failure 404 {object} ErrorResponse
+// @Router /s/{smart_link_id} [get]
+func (h *SmartLinkHandler) RedirectSmartLink(c *gin.Context) {
+	smartLinkID, err := strconv.ParseInt(c.Param("smart_link_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "Unknown smart link",
+		})
+		return
+	}
+
+	click := domain.SmartLinkClick{
+		Country: requestCountry(c),
+		Device:  domain.ClassifyDevice(c.Request.UserAgent()),
+		Time:    time.Now().UTC(),
+	}
+	if click.Country == "" && h.geoLocator != nil {
+		if ip := net.ParseIP(c.ClientIP()); ip != nil {
+			if location, err := h.geoLocator.Lookup(ip); err == nil {
+				click.Country = location.Country
+			}
+		}
+	}
+
+	location, err := h.smartLinkService.RouteClick(c.Request.Context(), smartLinkID, click)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Not found",
+				Details: "Unknown smart link",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: "Failed to route smart link",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, location)
+}
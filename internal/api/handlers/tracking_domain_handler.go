@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TrackingDomainHandler handles HTTP requests for custom tracking domains and native tracking links
+type TrackingDomainHandler struct {
+	trackingDomainService service.TrackingDomainService
+}
+
+// NewTrackingDomainHandler creates a new tracking domain handler
+func NewTrackingDomainHandler(trackingDomainService service.TrackingDomainService) *TrackingDomainHandler {
+	return &TrackingDomainHandler{
+		trackingDomainService: trackingDomainService,
+	}
+}
+
+// authorizeOrganization parses the :id organization and checks that the user belongs to it
+// (administrators may access any organization)
+func (h *TrackingDomainHandler) authorizeOrganization(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, false
+	}
+	return orgID, true
+}
+
+// parseIDParam parses an integer path parameter
+func (h *TrackingDomainHandler) parseIDParam(c *gin.Context, param, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + name + " ID",
+			Details: name + " ID must be a valid integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *TrackingDomainHandler) respondError(c *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: notFound,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// CreateTrackingDomain registers a custom tracking domain
+// @Summary Register tracking domain
+// @Description Registers a hostname such as go.brand.com for the organization's tracking links. Point the hostname
+// @Description at the API and create the returned TXT record, then call the verify endpoint.
+// @Tags tracking-domains
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body domain.CreateTrackingDomainRequest true "Tracking domain"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains [post]
+func (h *TrackingDomainHandler) CreateTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateTrackingDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	trackingDomain, err := h.trackingDomainService.CreateDomain(c.Request.Context(), orgID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to register tracking domain", "Organization not found", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tracking domain registered successfully",
+		"data":    trackingDomain,
+	})
+}
+
+// ListTrackingDomains lists the organization's tracking domains
+// @Summary List tracking domains
+// @Tags tracking-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} map[string]interface{} "data: []domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains [get]
+func (h *TrackingDomainHandler) ListTrackingDomains(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	domains, err := h.trackingDomainService.ListDomains(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondError(c, "Failed to list tracking domains", "Organization not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": domains})
+}
+
+// GetTrackingDomain returns a tracking domain
+// @Summary Get tracking domain
+// @Tags tracking-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Tracking Domain ID"
+// @Success 200 {object} map[string]interface{} "data: domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains/{domain_id} [get]
+func (h *TrackingDomainHandler) GetTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	trackingDomainID, ok := h.parseIDParam(c, "domain_id", "Tracking domain")
+	if !ok {
+		return
+	}
+
+	trackingDomain, err := h.trackingDomainService.GetDomain(c.Request.Context(), orgID, trackingDomainID)
+	if err != nil {
+		h.respondError(c, "Failed to get tracking domain", "No tracking domain found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": trackingDomain})
+}
+
+// DeleteTrackingDomain removes a tracking domain
+// @Summary Delete tracking domain
+// @Description Links generated on the domain stop redirecting; regenerate them to move them to another domain.
+// @Tags tracking-domains
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Tracking Domain ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains/{domain_id} [delete]
+func (h *TrackingDomainHandler) DeleteTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	trackingDomainID, ok := h.parseIDParam(c, "domain_id", "Tracking domain")
+	if !ok {
+		return
+	}
+
+	if err := h.trackingDomainService.DeleteDomain(c.Request.Context(), orgID, trackingDomainID); err != nil {
+		h.respondError(c, "Failed to delete tracking domain", "No tracking domain found with the specified ID", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyTrackingDomain checks the verification TXT record of a tracking domain
+// @Summary Verify tracking domain
+// @Description Looks up the TXT record and marks the domain verified or failed. A failed lookup (e.g. a DNS
+// @Description timeout) is reported in last_error without changing the status.
+// @Tags tracking-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Tracking Domain ID"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains/{domain_id}/verify [post]
+func (h *TrackingDomainHandler) VerifyTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	trackingDomainID, ok := h.parseIDParam(c, "domain_id", "Tracking domain")
+	if !ok {
+		return
+	}
+
+	trackingDomain, err := h.trackingDomainService.VerifyDomain(c.Request.Context(), orgID, trackingDomainID)
+	if err != nil {
+		h.respondError(c, "Failed to verify tracking domain", "No tracking domain found with the specified ID", err)
+		return
+	}
+
+	message := "Tracking domain verified successfully"
+	if !trackingDomain.IsVerified() {
+		message = "Tracking domain could not be verified"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    trackingDomain,
+	})
+}
+
+// SetDefaultTrackingDomain makes a verified domain the default of the organization's links
+// @Summary Set default tracking domain
+// @Description New and regenerated links of campaigns without an override use the default domain.
+// @Tags tracking-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param domain_id path int true "Tracking Domain ID"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains/{domain_id}/default [put]
+func (h *TrackingDomainHandler) SetDefaultTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	trackingDomainID, ok := h.parseIDParam(c, "domain_id", "Tracking domain")
+	if !ok {
+		return
+	}
+
+	trackingDomain, err := h.trackingDomainService.SetDefaultDomain(c.Request.Context(), orgID, trackingDomainID)
+	if err != nil {
+		h.respondError(c, "Failed to set default tracking domain", "No tracking domain found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Default tracking domain set successfully",
+		"data":    trackingDomain,
+	})
+}
+
+// ClearDefaultTrackingDomain makes the organization's links use the platform tracking URL
+// @Summary Clear default tracking domain
+// @Tags tracking-domains
+// @Param id path int true "Organization ID"
+// @Success 204 "Cleared"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-domains/default [delete]
+func (h *TrackingDomainHandler) ClearDefaultTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	if err := h.trackingDomainService.ClearDefaultDomain(c.Request.Context(), orgID); err != nil {
+		h.respondError(c, "Failed to clear default tracking domain", "Organization not found", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCampaignTrackingDomain returns the tracking domain override of a campaign
+// @Summary Get campaign tracking domain
+// @Tags tracking-domains
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Success 200 {object} map[string]interface{} "data: domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/tracking-domain [get]
+func (h *TrackingDomainHandler) GetCampaignTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	campaignID, ok := h.parseIDParam(c, "campaign_id", "Campaign")
+	if !ok {
+		return
+	}
+
+	trackingDomain, err := h.trackingDomainService.GetCampaignDomain(c.Request.Context(), orgID, campaignID)
+	if err != nil {
+		h.respondError(c, "Failed to get campaign tracking domain", "The campaign has no tracking domain override", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": trackingDomain})
+}
+
+// SetCampaignTrackingDomain sets or removes the tracking domain override of a campaign
+// @Summary Set campaign tracking domain
+// @Description Links of the campaign use the given verified domain instead of the organization's default.
+// @Description A null tracking_domain_id removes the override.
+// @Tags tracking-domains
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param campaign_id path int true "Campaign ID"
+// @Param request body domain.SetCampaignTrackingDomainRequest true "Tracking domain"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.TrackingDomain"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/campaigns/{campaign_id}/tracking-domain [put]
+func (h *TrackingDomainHandler) SetCampaignTrackingDomain(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	campaignID, ok := h.parseIDParam(c, "campaign_id", "Campaign")
+	if !ok {
+		return
+	}
+
+	var req domain.SetCampaignTrackingDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	trackingDomain, err := h.trackingDomainService.SetCampaignDomain(c.Request.Context(), orgID, campaignID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to set campaign tracking domain", "No campaign found with the specified ID", err)
+		return
+	}
+
+	message := "Campaign tracking domain set successfully"
+	if trackingDomain == nil {
+		message = "Campaign tracking domain removed successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    trackingDomain,
+	})
+}
+
+// RedirectTrackingLink redirects a click on a native tracking link
+// @Summary Follow tracking link
+// @Description Public endpoint behind native tracking links. The request must arrive on the platform tracking host
+// @Description or on a verified tracking domain of the link's organization. Visitors the campaign targeting ruleset
+// @Description rejects are sent to its fallback URL, or refused when it has none. Macros in the campaign destination
+// @Description URL are expanded for the click; sub1-sub5 and source_id query parameters override the link's values and
+// @Description whitelisted passthrough parameters are forwarded, also onto provider tracking URLs. Clicks on links of organizations that sign links must
+// @Description carry a valid signature; tampered clicks are refused or flagged depending on the enforcement.
+// @Tags tracking-links
+// @Param link_id path int true "Tracking Link ID"
+// @Success 302 "Redirect to the tracking URL"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /c/{link_id} [get]
+func (h *TrackingDomainHandler) RedirectTrackingLink(c *gin.Context) {
+	trackingLinkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: "Unknown tracking link",
+		})
+		return
+	}
+
+	visitor := domain.TargetingVisitor{
+		IP:        net.ParseIP(c.ClientIP()),
+		UserAgent: c.Request.UserAgent(),
+		Location:  domain.GeoLocation{Country: requestCountry(c)},
+		Time:      time.Now().UTC(),
+	}
+	location, err := h.trackingDomainService.ResolveRedirect(c.Request.Context(), c.Request.Host, trackingLinkID, visitor, c.Request.URL.Query())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Not found",
+				Details: "Unknown tracking link",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrLinkTampered) {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Forbidden",
+				Details: "The tracking link parameters are invalid",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   ErrInternalServer,
+			Details: "Failed to resolve tracking link",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, location)
+}
@@ -1,13 +1,14 @@
 package handlers
 
 import (
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/affiliate-backend/internal/api/models"
+	"github.com/affiliate-backend/internal/domain"
 	"github.com/affiliate-backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -24,50 +25,6 @@ func NewTrackingLinkHandler(trackingLinkService service.TrackingLinkService) *Tr
 	}
 }
 
-// GetTrackingLinkQR generates a QR code for a tracking link
-// @Summary Get QR code for tracking link
-// @Description Generate a QR code for the specified tracking link
-// @Tags tracking-links
-// @Produce text/plain
-// @Param organization_id path int true "Organization ID"
-// @Param link_id path int true "Tracking Link ID"
-// @Success 200 {string} string "Base64 encoded QR code"
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Security BearerAuth
-// @Router /organizations/{organization_id}/tracking-links/{link_id}/qr [get]
-func (h *TrackingLinkHandler) GetTrackingLinkQR(c *gin.Context) {
-	trackingLinkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid tracking link ID",
-			Details: "Tracking link ID must be a valid integer",
-		})
-		return
-	}
-
-	// Get tracking link to verify it exists
-	trackingLink, err := h.trackingLinkService.GetTrackingLinkByID(c.Request.Context(), trackingLinkID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error:   "Tracking link not found",
-			Details: err.Error(),
-		})
-		return
-	}
-
-	// For now, return a simple mock QR code
-	// In a real implementation, you would generate a QR code from the tracking URL
-	qrData := []byte(trackingLink.Name)
-
-	// Convert qrData from bytes slice to base64 encoded string
-	base64QR := base64.StdEncoding.EncodeToString(qrData)
-
-	// Return base64 encoded string directly
-	c.String(http.StatusOK, base64QR)
-}
-
 // CreateTrackingLinkClean creates a new tracking link with uniqueness guarantee
 // @Summary Create a new tracking link
 // @Description Create a new tracking link with uniqueness guarantee for campaign_id + affiliate_id combination
@@ -109,6 +66,8 @@ func (h *TrackingLinkHandler) CreateTrackingLinkClean(c *gin.Context) {
 		IsRedirectLink:      req.IsRedirectLink,
 		InternalNotes:       req.InternalNotes,
 		Tags:                req.Tags,
+		ActiveFrom:          req.ActiveFrom,
+		ActiveUntil:         req.ActiveUntil,
 	}
 
 	// Convert to domain model
@@ -220,6 +179,13 @@ func (h *TrackingLinkHandler) UpdateTrackingLinkClean(c *gin.Context) {
 
 	// Update tracking link
 	err = h.trackingLinkService.UpdateTrackingLink(c.Request.Context(), existingTrackingLink)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Failed to update tracking link",
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TrackingLinkQRHandler handles HTTP requests for tracking link QR codes and organization logos
+type TrackingLinkQRHandler struct {
+	qrService service.TrackingLinkQRService
+}
+
+// NewTrackingLinkQRHandler creates a new tracking link QR code handler
+func NewTrackingLinkQRHandler(qrService service.TrackingLinkQRService) *TrackingLinkQRHandler {
+	return &TrackingLinkQRHandler{
+		qrService: qrService,
+	}
+}
+
+// authorizeOrganization parses the :id organization and checks that the user belongs to it
+// (administrators may access any organization)
+func (h *TrackingLinkQRHandler) authorizeOrganization(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, false
+	}
+	return orgID, true
+}
+
+// respondError maps service errors to HTTP responses
+func (h *TrackingLinkQRHandler) respondError(c *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: notFound,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// GetTrackingLinkQR renders the QR code of a tracking link
+// @Summary Get QR code for tracking link
+// @Description Renders the tracking URL of the link as a QR code image. Images are cached per link and options and
+// @Description rebuilt when the tracking URL or the organization logo changes. With logo=true the organization logo
+// @Description is drawn at the centre, which requires error correction level Q or H (H by default).
+// @Tags tracking-links
+// @Produce image/png
+// @Produce image/svg+xml
+// @Param id path int true "Organization ID"
+// @Param link_id path int true "Tracking Link ID"
+// @Param format query string false "png or svg" default(png)
+// @Param size query int false "Width and height in pixels (64-2048)" default(512)
+// @Param ec_level query string false "Error correction level: L, M, Q or H" default(M)
+// @Param margin query int false "Quiet zone in modules (0-20)" default(4)
+// @Param foreground query string false "Module color, e.g. #000000"
+// @Param background query string false "Background color, e.g. #ffffff, or transparent"
+// @Param logo query bool false "Draw the organization logo at the centre"
+// @Success 200 {file} file "QR code image"
+// @Success 304 "Not modified"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-links/{link_id}/qr [get]
+func (h *TrackingLinkQRHandler) GetTrackingLinkQR(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+	trackingLinkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid tracking link ID",
+			Details: "Tracking link ID must be a valid integer",
+		})
+		return
+	}
+
+	var options domain.QRCodeOptions
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	qr, err := h.qrService.GetTrackingLinkQR(c.Request.Context(), orgID, trackingLinkID, &options)
+	if err != nil {
+		h.respondError(c, "Failed to generate QR code", "No tracking link found with the specified ID", err)
+		return
+	}
+
+	// Clients revalidate so that a changed tracking URL is picked up
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", qr.ETag)
+	if c.GetHeader("If-None-Match") == qr.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, qr.ContentType, qr.Data)
+}
+
+// GetOrganizationLogo returns the logo drawn on the organization's QR codes
+// @Summary Get organization logo
+// @Tags organizations
+// @Produce image/png
+// @Produce image/jpeg
+// @Produce image/gif
+// @Param id path int true "Organization ID"
+// @Success 200 {file} file "Logo image"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/logo [get]
+func (h *TrackingLinkQRHandler) GetOrganizationLogo(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	logo, data, err := h.qrService.GetOrganizationLogo(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondError(c, "Failed to get organization logo", "The organization has no logo", err)
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, logo.ContentType, data)
+}
+
+// UploadOrganizationLogo sets the logo drawn on the organization's QR codes
+// @Summary Upload organization logo
+// @Description Replaces the organization logo with a PNG, JPEG or GIF image of at most 1 MB and 4096x4096 pixels.
+// @Description Send the image as the request body or as the "file" field of a multipart form.
+// @Tags organizations
+// @Accept image/png
+// @Accept image/jpeg
+// @Accept image/gif
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param file formData file false "Logo image"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.OrganizationLogo"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/logo [put]
+func (h *TrackingLinkQRHandler) UploadOrganizationLogo(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Missing file",
+				Details: err.Error(),
+			})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Failed to read file",
+				Details: err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	// Read one byte past the limit so that the service can reject oversized logos
+	data, err := io.ReadAll(io.LimitReader(body, domain.MaxOrganizationLogoSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to read file",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	logo, err := h.qrService.UploadOrganizationLogo(c.Request.Context(), orgID, data)
+	if err != nil {
+		h.respondError(c, "Failed to upload organization logo", "Organization not found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Organization logo uploaded successfully",
+		"data":    logo,
+	})
+}
+
+// DeleteOrganizationLogo removes the logo drawn on the organization's QR codes
+// @Summary Delete organization logo
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Success 204 "Deleted"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/logo [delete]
+func (h *TrackingLinkQRHandler) DeleteOrganizationLogo(c *gin.Context) {
+	orgID, ok := h.authorizeOrganization(c)
+	if !ok {
+		return
+	}
+
+	if err := h.qrService.DeleteOrganizationLogo(c.Request.Context(), orgID); err != nil {
+		h.respondError(c, "Failed to delete organization logo", "The organization has no logo", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/api/middleware"
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TrackingLinkSigningHandler handles HTTP requests for tracking link signing
+type TrackingLinkSigningHandler struct {
+	signingService service.TrackingLinkSigningService
+}
+
+// NewTrackingLinkSigningHandler creates a new tracking link signing handler
+func NewTrackingLinkSigningHandler(signingService service.TrackingLinkSigningService) *TrackingLinkSigningHandler {
+	return &TrackingLinkSigningHandler{
+		signingService: signingService,
+	}
+}
+
+// authorizeSigningOrganization parses the :id organization and checks that the user belongs to
+// it (administrators may access any organization)
+func (h *TrackingLinkSigningHandler) authorizeSigningOrganization(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid organization ID",
+			Details: "Organization ID must be a valid integer",
+		})
+		return 0, false
+	}
+
+	if role, _ := c.Get(middleware.UserRoleKey); role == "Admin" {
+		return orgID, true
+	}
+	value, exists := c.Get("organizationID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   ErrUnauthorized,
+			Details: DetailOrgIDNotFound,
+		})
+		return 0, false
+	}
+	if userOrgID, ok := value.(int64); !ok || userOrgID != orgID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Forbidden",
+			Details: "You don't have permission to access this organization",
+		})
+		return 0, false
+	}
+	return orgID, true
+}
+
+// respondSigningError maps service errors to HTTP responses
+func (h *TrackingLinkSigningHandler) respondSigningError(c *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidInput,
+			Details: err.Error(),
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Not found",
+			Details: notFound,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   message,
+			Details: err.Error(),
+		})
+	}
+}
+
+// GetTrackingLinkSigning returns the signing settings and keys of an organization
+// @Summary Get tracking link signing
+// @Description Returns whether the organization signs its native tracking links, the enforcement and the signing keys
+// @Tags tracking-link-signing
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} map[string]interface{} "data: domain.TrackingLinkSigning"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-signing [get]
+func (h *TrackingLinkSigningHandler) GetTrackingLinkSigning(c *gin.Context) {
+	orgID, ok := h.authorizeSigningOrganization(c)
+	if !ok {
+		return
+	}
+
+	signing, err := h.signingService.GetSigning(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondSigningError(c, "Failed to get tracking link signing", "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": signing})
+}
+
+// SetTrackingLinkSigning enables signing of the native tracking links of an organization
+// @Summary Enable tracking link signing
+// @Description Signs the tracking parameters (affiliate, source and sub IDs) of native tracking links with HMAC, or
+// @Description encrypts them for links with is_encrypt_parameters, creating the first signing key. The stored URLs of
+// @Description existing native links are signed right away; copies of their unsigned URLs fail verification. Clicks that fail verification are refused (reject) or redirected with
+// @Description the link's stored parameters and recorded (flag).
+// @Tags tracking-link-signing
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body domain.SetTrackingLinkSigningRequest true "Signing settings"
+// @Success 200 {object} map[string]interface{} "message: string, data: domain.TrackingLinkSigning"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-signing [put]
+func (h *TrackingLinkSigningHandler) SetTrackingLinkSigning(c *gin.Context) {
+	orgID, ok := h.authorizeSigningOrganization(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetTrackingLinkSigningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	signing, err := h.signingService.SetSigning(c.Request.Context(), orgID, &req)
+	if err != nil {
+		h.respondSigningError(c, "Failed to enable tracking link signing", "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tracking link signing enabled successfully",
+		"data":    signing,
+	})
+}
+
+// DisableTrackingLinkSigning stops signing the native tracking links of an organization
+// @Summary Disable tracking link signing
+// @Description Stops signing new links and verifying clicks. Keys are kept so that signing can be enabled again.
+// @Tags tracking-link-signing
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} map[string]interface{} "message: string"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-signing [delete]
+func (h *TrackingLinkSigningHandler) DisableTrackingLinkSigning(c *gin.Context) {
+	orgID, ok := h.authorizeSigningOrganization(c)
+	if !ok {
+		return
+	}
+
+	if err := h.signingService.DisableSigning(c.Request.Context(), orgID); err != nil {
+		h.respondSigningError(c, "Failed to disable tracking link signing", "Tracking link signing is not enabled", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tracking link signing disabled successfully"})
+}
+
+// RotateTrackingLinkSigningKey makes a new signing key active
+// @Summary Rotate tracking link signing key
+// @Description Makes a new key active for signing new links. The previous key is retired: links it signed keep
+// @Description verifying until it is revoked.
+// @Tags tracking-link-signing
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 201 {object} map[string]interface{} "message: string, data: domain.TrackingLinkSigningKey"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-signing/keys [post]
+func (h *TrackingLinkSigningHandler) RotateTrackingLinkSigningKey(c *gin.Context) {
+	orgID, ok := h.authorizeSigningOrganization(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.signingService.RotateKey(c.Request.Context(), orgID)
+	if err != nil {
+		h.respondSigningError(c, "Failed to rotate tracking link signing key", "", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tracking link signing key rotated successfully",
+		"data":    key,
+	})
+}
+
+// RevokeTrackingLinkSigningKey revokes a retired signing key
+// @Summary Revoke tracking link signing key
+// @Description Revokes a retired key. The stored URLs of links it signed are signed with the active key first;
+// @Description clicks on copies of the old URLs fail verification from then on.
+// @Tags tracking-link-signing
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param key_id path int true "Key ID"
+// @Success 200 {object} map[string]interface{} "message: string"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-signing/keys/{key_id} [delete]
+func (h *TrackingLinkSigningHandler) RevokeTrackingLinkSigningKey(c *gin.Context) {
+	orgID, ok := h.authorizeSigningOrganization(c)
+	if !ok {
+		return
+	}
+	keyID, err := strconv.ParseInt(c.Param("key_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid key ID",
+			Details: "Key ID must be a valid integer",
+		})
+		return
+	}
+
+	if err := h.signingService.RevokeKey(c.Request.Context(), orgID, keyID); err != nil {
+		h.respondSigningError(c, "Failed to revoke tracking link signing key", "No key found with the specified ID", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tracking link signing key revoked successfully"})
+}
+
+// ListTrackingLinkTamperEvents lists the clicks that failed verification
+// @Summary List tracking link tamper events
+// @Tags tracking-link-signing
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} map[string]interface{} "data: domain.TrackingLinkTamperEventListResponse"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/tracking-link-signing/events [get]
+func (h *TrackingLinkSigningHandler) ListTrackingLinkTamperEvents(c *gin.Context) {
+	orgID, ok := h.authorizeSigningOrganization(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := getPaginationParams(c)
+	events, err := h.signingService.ListTamperEvents(c.Request.Context(), orgID, page, pageSize)
+	if err != nil {
+		h.respondSigningError(c, "Failed to list tracking link tamper events", "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
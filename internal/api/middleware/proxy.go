@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedProxyKey is set to true on requests received directly from a trusted proxy, whose
+// forwarding and CDN headers (X-Forwarded-For, CF-IPCountry, ...) may be believed
+const TrustedProxyKey = "trustedProxy"
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges of trusted proxies
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// TrustedProxyMiddleware marks requests whose direct peer is one of the trusted proxy networks
+func TrustedProxyMiddleware(networks []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ip := net.ParseIP(c.RemoteIP()); ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					c.Set(TrustedProxyKey, true)
+					break
+				}
+			}
+		}
+		c.Next()
+	}
+}
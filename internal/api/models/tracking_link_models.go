@@ -9,60 +9,67 @@ import (
 
 // TrackingLinkRequest represents the request to create a tracking link
 type TrackingLinkRequest struct {
-	Name                string  `json:"name" binding:"required" example:"Facebook Campaign Link"`
-	Description         *string `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
-	Status              string  `json:"status" binding:"required" example:"active"`
-	CampaignID          int64   `json:"campaign_id" binding:"required" example:"1"`
-	AffiliateID         int64   `json:"affiliate_id" binding:"required" example:"1"`
-	SourceID            *string `json:"source_id,omitempty" example:"facebook"`
-	Sub1                *string `json:"sub1,omitempty" example:"campaign_123"`
-	Sub2                *string `json:"sub2,omitempty" example:"adset_456"`
-	Sub3                *string `json:"sub3,omitempty" example:"ad_789"`
-	Sub4                *string `json:"sub4,omitempty" example:"placement_mobile"`
-	Sub5                *string `json:"sub5,omitempty" example:"audience_lookalike"`
-	IsEncryptParameters *bool   `json:"is_encrypt_parameters,omitempty" example:"false"`
-	IsRedirectLink      *bool   `json:"is_redirect_link,omitempty" example:"true"`
-	InternalNotes       *string `json:"internal_notes,omitempty" example:"High-performing traffic source"`
-	Tags                *string `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	Name                string     `json:"name" binding:"required" example:"Facebook Campaign Link"`
+	Description         *string    `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
+	Status              string     `json:"status" binding:"required" example:"active"`
+	CampaignID          int64      `json:"campaign_id" binding:"required" example:"1"`
+	AffiliateID         int64      `json:"affiliate_id" binding:"required" example:"1"`
+	SourceID            *string    `json:"source_id,omitempty" example:"facebook"`
+	Sub1                *string    `json:"sub1,omitempty" example:"campaign_123"`
+	Sub2                *string    `json:"sub2,omitempty" example:"adset_456"`
+	Sub3                *string    `json:"sub3,omitempty" example:"ad_789"`
+	Sub4                *string    `json:"sub4,omitempty" example:"placement_mobile"`
+	Sub5                *string    `json:"sub5,omitempty" example:"audience_lookalike"`
+	IsEncryptParameters *bool      `json:"is_encrypt_parameters,omitempty" example:"false"`
+	IsRedirectLink      *bool      `json:"is_redirect_link,omitempty" example:"true"`
+	InternalNotes       *string    `json:"internal_notes,omitempty" example:"High-performing traffic source"`
+	Tags                *string    `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	ActiveFrom          *time.Time `json:"active_from,omitempty" example:"2024-01-01T00:00:00Z"`  // Link is scheduled until then
+	ActiveUntil         *time.Time `json:"active_until,omitempty" example:"2024-12-31T23:59:59Z"` // Link is archived from then
 }
 
 // TrackingLinkUpdateRequest represents the request to update a tracking link
 type TrackingLinkUpdateRequest struct {
-	Name                *string `json:"name,omitempty" example:"Facebook Campaign Link"`
-	Description         *string `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
-	Status              *string `json:"status,omitempty" example:"active"`
-	SourceID            *string `json:"source_id,omitempty" example:"facebook"`
-	Sub1                *string `json:"sub1,omitempty" example:"campaign_123"`
-	Sub2                *string `json:"sub2,omitempty" example:"adset_456"`
-	Sub3                *string `json:"sub3,omitempty" example:"ad_789"`
-	Sub4                *string `json:"sub4,omitempty" example:"placement_mobile"`
-	Sub5                *string `json:"sub5,omitempty" example:"audience_lookalike"`
-	IsEncryptParameters *bool   `json:"is_encrypt_parameters,omitempty" example:"false"`
-	IsRedirectLink      *bool   `json:"is_redirect_link,omitempty" example:"true"`
-	InternalNotes       *string `json:"internal_notes,omitempty" example:"High-performing traffic source"`
-	Tags                *string `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	Name                *string    `json:"name,omitempty" example:"Facebook Campaign Link"`
+	Description         *string    `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
+	Status              *string    `json:"status,omitempty" example:"active"`
+	SourceID            *string    `json:"source_id,omitempty" example:"facebook"`
+	Sub1                *string    `json:"sub1,omitempty" example:"campaign_123"`
+	Sub2                *string    `json:"sub2,omitempty" example:"adset_456"`
+	Sub3                *string    `json:"sub3,omitempty" example:"ad_789"`
+	Sub4                *string    `json:"sub4,omitempty" example:"placement_mobile"`
+	Sub5                *string    `json:"sub5,omitempty" example:"audience_lookalike"`
+	IsEncryptParameters *bool      `json:"is_encrypt_parameters,omitempty" example:"false"`
+	IsRedirectLink      *bool      `json:"is_redirect_link,omitempty" example:"true"`
+	InternalNotes       *string    `json:"internal_notes,omitempty" example:"High-performing traffic source"`
+	Tags                *string    `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	ActiveFrom          *time.Time `json:"active_from,omitempty" example:"2024-01-01T00:00:00Z"`  // Link is scheduled until then
+	ActiveUntil         *time.Time `json:"active_until,omitempty" example:"2024-12-31T23:59:59Z"` // Link is archived from then
+	ClearActiveWindow   bool       `json:"clear_active_window,omitempty" example:"false"`         // Removes active_from and active_until before applying the ones given
 }
 
 // TrackingLinkGenerationRequest represents the request to generate a tracking link
 type TrackingLinkGenerationRequest struct {
-	Name                    string  `json:"name" binding:"required" example:"Facebook Campaign Link"`
-	Description             *string `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
-	CampaignID              int64   `json:"campaign_id" binding:"required" example:"1"`
-	AffiliateID             int64   `json:"affiliate_id" binding:"required" example:"1"`
-	SourceID                *string `json:"source_id,omitempty" example:"facebook"`
-	Sub1                    *string `json:"sub1,omitempty" example:"campaign_123"`
-	Sub2                    *string `json:"sub2,omitempty" example:"adset_456"`
-	Sub3                    *string `json:"sub3,omitempty" example:"ad_789"`
-	Sub4                    *string `json:"sub4,omitempty" example:"placement_mobile"`
-	Sub5                    *string `json:"sub5,omitempty" example:"audience_lookalike"`
-	IsEncryptParameters     *bool   `json:"is_encrypt_parameters,omitempty" example:"false"`
-	IsRedirectLink          *bool   `json:"is_redirect_link,omitempty" example:"true"`
-	NetworkTrackingDomainID *int32  `json:"network_tracking_domain_id,omitempty" example:"1"`
-	NetworkOfferURLID       *int32  `json:"network_offer_url_id,omitempty" example:"1"`
-	CreativeID              *int32  `json:"creative_id,omitempty" example:"1"`
-	NetworkTrafficSourceID  *int32  `json:"network_traffic_source_id,omitempty" example:"1"`
-	InternalNotes           *string `json:"internal_notes,omitempty" example:"High-performing traffic source"`
-	Tags                    *string `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	Name                    string     `json:"name" binding:"required" example:"Facebook Campaign Link"`
+	Description             *string    `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
+	CampaignID              int64      `json:"campaign_id" binding:"required" example:"1"`
+	AffiliateID             int64      `json:"affiliate_id" binding:"required" example:"1"`
+	SourceID                *string    `json:"source_id,omitempty" example:"facebook"`
+	Sub1                    *string    `json:"sub1,omitempty" example:"campaign_123"`
+	Sub2                    *string    `json:"sub2,omitempty" example:"adset_456"`
+	Sub3                    *string    `json:"sub3,omitempty" example:"ad_789"`
+	Sub4                    *string    `json:"sub4,omitempty" example:"placement_mobile"`
+	Sub5                    *string    `json:"sub5,omitempty" example:"audience_lookalike"`
+	IsEncryptParameters     *bool      `json:"is_encrypt_parameters,omitempty" example:"false"`
+	IsRedirectLink          *bool      `json:"is_redirect_link,omitempty" example:"true"`
+	NetworkTrackingDomainID *int32     `json:"network_tracking_domain_id,omitempty" example:"1"`
+	NetworkOfferURLID       *int32     `json:"network_offer_url_id,omitempty" example:"1"`
+	CreativeID              *int32     `json:"creative_id,omitempty" example:"1"`
+	NetworkTrafficSourceID  *int32     `json:"network_traffic_source_id,omitempty" example:"1"`
+	InternalNotes           *string    `json:"internal_notes,omitempty" example:"High-performing traffic source"`
+	Tags                    *string    `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	ActiveFrom              *time.Time `json:"active_from,omitempty" example:"2024-01-01T00:00:00Z"`  // Link is scheduled until then
+	ActiveUntil             *time.Time `json:"active_until,omitempty" example:"2024-12-31T23:59:59Z"` // Link is archived from then
 }
 
 // TrackingLinkResponse represents the response for tracking link operations
@@ -84,10 +91,13 @@ type TrackingLinkResponse struct {
 	IsEncryptParameters *bool   `json:"is_encrypt_parameters,omitempty" example:"false"`
 	IsRedirectLink      *bool   `json:"is_redirect_link,omitempty" example:"true"`
 
-	InternalNotes *string   `json:"internal_notes,omitempty" example:"High-performing traffic source"`
-	Tags          *string   `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
-	CreatedAt     time.Time `json:"created_at" example:"2023-12-01T10:00:00Z"`
-	UpdatedAt     time.Time `json:"updated_at" example:"2023-12-01T10:30:00Z"`
+	InternalNotes *string    `json:"internal_notes,omitempty" example:"High-performing traffic source"`
+	Tags          *string    `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	ActiveFrom    *time.Time `json:"active_from,omitempty" example:"2024-01-01T00:00:00Z"`
+	ActiveUntil   *time.Time `json:"active_until,omitempty" example:"2024-12-31T23:59:59Z"`
+	StatusReason  *string    `json:"status_reason,omitempty" example:"association_suspended"` // Set when the status was changed automatically
+	CreatedAt     time.Time  `json:"created_at" example:"2023-12-01T10:00:00Z"`
+	UpdatedAt     time.Time  `json:"updated_at" example:"2023-12-01T10:30:00Z"`
 }
 
 // TrackingLinkGenerationResponse represents the response for tracking link generation
@@ -99,24 +109,26 @@ type TrackingLinkGenerationResponse struct {
 
 // TrackingLinkUpsertRequest represents the request to upsert a tracking link by campaign and affiliate
 type TrackingLinkUpsertRequest struct {
-	Name                    string  `json:"name" binding:"required" example:"Facebook Campaign Link"`
-	Description             *string `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
-	CampaignID              int64   `json:"campaign_id" binding:"required" example:"1"`
-	AffiliateID             int64   `json:"affiliate_id" binding:"required" example:"1"`
-	SourceID                *string `json:"source_id,omitempty" example:"facebook"`
-	Sub1                    *string `json:"sub1,omitempty" example:"campaign_123"`
-	Sub2                    *string `json:"sub2,omitempty" example:"adset_456"`
-	Sub3                    *string `json:"sub3,omitempty" example:"ad_789"`
-	Sub4                    *string `json:"sub4,omitempty" example:"placement_mobile"`
-	Sub5                    *string `json:"sub5,omitempty" example:"audience_lookalike"`
-	IsEncryptParameters     *bool   `json:"is_encrypt_parameters,omitempty" example:"false"`
-	IsRedirectLink          *bool   `json:"is_redirect_link,omitempty" example:"true"`
-	NetworkTrackingDomainID *int32  `json:"network_tracking_domain_id,omitempty" example:"1"`
-	NetworkOfferURLID       *int32  `json:"network_offer_url_id,omitempty" example:"1"`
-	CreativeID              *int32  `json:"creative_id,omitempty" example:"1"`
-	NetworkTrafficSourceID  *int32  `json:"network_traffic_source_id,omitempty" example:"1"`
-	InternalNotes           *string `json:"internal_notes,omitempty" example:"High-performing traffic source"`
-	Tags                    *string `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	Name                    string     `json:"name" binding:"required" example:"Facebook Campaign Link"`
+	Description             *string    `json:"description,omitempty" example:"Tracking link for Facebook traffic"`
+	CampaignID              int64      `json:"campaign_id" binding:"required" example:"1"`
+	AffiliateID             int64      `json:"affiliate_id" binding:"required" example:"1"`
+	SourceID                *string    `json:"source_id,omitempty" example:"facebook"`
+	Sub1                    *string    `json:"sub1,omitempty" example:"campaign_123"`
+	Sub2                    *string    `json:"sub2,omitempty" example:"adset_456"`
+	Sub3                    *string    `json:"sub3,omitempty" example:"ad_789"`
+	Sub4                    *string    `json:"sub4,omitempty" example:"placement_mobile"`
+	Sub5                    *string    `json:"sub5,omitempty" example:"audience_lookalike"`
+	IsEncryptParameters     *bool      `json:"is_encrypt_parameters,omitempty" example:"false"`
+	IsRedirectLink          *bool      `json:"is_redirect_link,omitempty" example:"true"`
+	NetworkTrackingDomainID *int32     `json:"network_tracking_domain_id,omitempty" example:"1"`
+	NetworkOfferURLID       *int32     `json:"network_offer_url_id,omitempty" example:"1"`
+	CreativeID              *int32     `json:"creative_id,omitempty" example:"1"`
+	NetworkTrafficSourceID  *int32     `json:"network_traffic_source_id,omitempty" example:"1"`
+	InternalNotes           *string    `json:"internal_notes,omitempty" example:"High-performing traffic source"`
+	Tags                    *string    `json:"tags,omitempty" example:"facebook,mobile,lookalike"`
+	ActiveFrom              *time.Time `json:"active_from,omitempty" example:"2024-01-01T00:00:00Z"`  // Link is scheduled until then
+	ActiveUntil             *time.Time `json:"active_until,omitempty" example:"2024-12-31T23:59:59Z"` // Link is archived from then
 }
 
 // TrackingLinkUpsertResponse represents the response for upserting a tracking link
@@ -171,6 +183,8 @@ func (req *TrackingLinkRequest) ToTrackingLinkDomain(organizationID int64) *doma
 		IsRedirectLink:      req.IsRedirectLink,
 		InternalNotes:       req.InternalNotes,
 		Tags:                req.Tags,
+		ActiveFrom:          req.ActiveFrom,
+		ActiveUntil:         req.ActiveUntil,
 	}
 }
 
@@ -215,6 +229,16 @@ func (req *TrackingLinkUpdateRequest) UpdateTrackingLinkDomain(trackingLink *dom
 	if req.Tags != nil {
 		trackingLink.Tags = req.Tags
 	}
+	if req.ClearActiveWindow {
+		trackingLink.ActiveFrom = nil
+		trackingLink.ActiveUntil = nil
+	}
+	if req.ActiveFrom != nil {
+		trackingLink.ActiveFrom = req.ActiveFrom
+	}
+	if req.ActiveUntil != nil {
+		trackingLink.ActiveUntil = req.ActiveUntil
+	}
 }
 
 // ToTrackingLinkGenerationDomain converts API generation request to domain model
@@ -236,6 +260,8 @@ func (req *TrackingLinkGenerationRequest) ToTrackingLinkGenerationDomain() *doma
 		NetworkOfferURLID:       req.NetworkOfferURLID,
 		CreativeID:              req.CreativeID,
 		NetworkTrafficSourceID:  req.NetworkTrafficSourceID,
+		ActiveFrom:              req.ActiveFrom,
+		ActiveUntil:             req.ActiveUntil,
 	}
 }
 
@@ -261,6 +287,9 @@ func FromTrackingLinkDomain(trackingLink *domain.TrackingLink) *TrackingLinkResp
 
 		InternalNotes: trackingLink.InternalNotes,
 		Tags:          trackingLink.Tags,
+		ActiveFrom:    trackingLink.ActiveFrom,
+		ActiveUntil:   trackingLink.ActiveUntil,
+		StatusReason:  trackingLink.StatusReason,
 		CreatedAt:     trackingLink.CreatedAt,
 		UpdatedAt:     trackingLink.UpdatedAt,
 	}
@@ -306,6 +335,8 @@ func (req *TrackingLinkUpsertRequest) ToTrackingLinkUpsertDomain() *domain.Track
 		NetworkTrafficSourceID:  req.NetworkTrafficSourceID,
 		InternalNotes:           req.InternalNotes,
 		Tags:                    req.Tags,
+		ActiveFrom:              req.ActiveFrom,
+		ActiveUntil:             req.ActiveUntil,
 	}
 }
 
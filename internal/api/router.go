@@ -1,6 +1,8 @@
 package api
 
 import (
+	"net"
+
 	"github.com/affiliate-backend/internal/api/handlers"
 	"github.com/affiliate-backend/internal/api/middleware"
 	"github.com/affiliate-backend/internal/service"
@@ -11,6 +13,8 @@ import (
 
 // RouterOptions contains dependencies for the router
 type RouterOptions struct {
+	// TrustedProxies are the networks of the proxies in front of the API; none are trusted when empty
+	TrustedProxies                         []*net.IPNet
 	ProfileHandler                         *handlers.ProfileHandler
 	ProfileService                         service.ProfileService
 	OrganizationHandler                    *handlers.OrganizationHandler
@@ -21,23 +25,56 @@ type RouterOptions struct {
 	AffiliateHandler                       *handlers.AffiliateHandler
 	CampaignHandler                        *handlers.CampaignHandler
 	TrackingLinkHandler                    *handlers.TrackingLinkHandler
+	TrackingLinkQRHandler                  *handlers.TrackingLinkQRHandler
+	TrackingDomainHandler                  *handlers.TrackingDomainHandler
+	TrackingLinkSigningHandler             *handlers.TrackingLinkSigningHandler
+	SmartLinkHandler                       *handlers.SmartLinkHandler
+	CampaignTargetingHandler               *handlers.CampaignTargetingHandler
+	CampaignURLHandler                     *handlers.CampaignURLHandler
+	LinkHealthHandler                      *handlers.LinkHealthHandler
+	CreativeHandler                        *handlers.CreativeHandler
+	BulkTrackingLinkHandler                *handlers.BulkTrackingLinkHandler
 	AnalyticsHandler                       *handlers.AnalyticsHandler
+	AnalyticsImportHandler                 *handlers.AnalyticsImportHandler
 	FavoritePublisherListHandler           *handlers.FavoritePublisherListHandler
+	PublisherPipelineHandler               *handlers.PublisherPipelineHandler
+	PublisherConversionHandler             *handlers.PublisherConversionHandler
 	PublisherMessagingHandler              *handlers.PublisherMessagingHandler
+	PublisherMessageTemplateHandler        *handlers.PublisherMessageTemplateHandler
+	OutreachSequenceHandler                *handlers.OutreachSequenceHandler
+	NotificationHandler                    *handlers.NotificationHandler
 	BillingHandler                         *handlers.BillingHandler
 	WebhookHandler                         *handlers.WebhookHandler
+	ProviderStatsHandler                   *handlers.ProviderStatsHandler
+	ReportHandler                          *handlers.ReportHandler
+	ScheduledReportHandler                 *handlers.ScheduledReportHandler
+	InboundEmailHandler                    *handlers.InboundEmailHandler
 }
 
 // SetupRouter sets up the API router
 func SetupRouter(opts RouterOptions) *gin.Engine {
 	r := gin.Default() // Starts with Logger and Recovery middleware
 
+	// ClientIP only follows X-Forwarded-For from trusted proxies, which also vouch for CDN country headers
+	proxies := make([]string, 0, len(opts.TrustedProxies))
+	for _, network := range opts.TrustedProxies {
+		proxies = append(proxies, network.String())
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		panic(err) // the networks were parsed already
+	}
+	r.Use(middleware.TrustedProxyMiddleware(opts.TrustedProxies))
+
 	// Apply CORS middleware (will only allow CORS in development)
 	r.Use(middleware.CORSMiddleware())
 
 	// Health Check
 	r.GET("/health", handlers.HealthCheck)
 
+	// Native tracking links, served on the platform tracking host and on verified tracking domains
+	r.GET("/c/:link_id", opts.TrackingDomainHandler.RedirectTrackingLink)
+	r.GET("/s/:smart_link_id", opts.SmartLinkHandler.RedirectSmartLink)
+
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -46,10 +83,14 @@ func SetupRouter(opts RouterOptions) *gin.Engine {
 	{
 		public.POST("/webhooks/supabase/new-user", opts.ProfileHandler.HandleSupabaseNewUserWebhook)
 		public.POST("/webhooks/stripe", opts.WebhookHandler.HandleStripeWebhook)
+		// Inbound publisher email replies (authenticated by a shared webhook token)
+		public.POST("/webhooks/inbound-email", opts.InboundEmailHandler.HandleInboundEmail)
 		// Organization creation endpoint (no authentication required)
 		public.POST("/organizations", opts.OrganizationHandler.CreateOrganizationPublic)
 		// Public invitation endpoint (no authentication required for viewing invitations)
 		public.GET("/invitations/:token", opts.AdvertiserAssociationInvitationHandler.GetInvitationByToken)
+		// Images of approved creatives, embedded in published banners
+		public.GET("/creatives/:creative_id/file", opts.CreativeHandler.GetCreativeFile)
 	}
 
 	// Authenticated routes
@@ -184,7 +225,86 @@ func SetupRouter(opts RouterOptions) *gin.Engine {
 
 	// --- Legacy Tracking Link Routes (QR code only) ---
 	// Keep only the QR code endpoint for backward compatibility
-	organizations.GET("/:id/tracking-links/:link_id/qr", profileMW(), rbacMW("Admin", "AdvertiserManager", "AffiliateManager"), opts.TrackingLinkHandler.GetTrackingLinkQR)
+	organizations.GET("/:id/tracking-links/:link_id/qr", profileMW(), rbacMW("Admin", "AdvertiserManager", "AffiliateManager"), opts.TrackingLinkQRHandler.GetTrackingLinkQR)
+
+	// Organization logo drawn on the QR codes
+	organizations.GET("/:id/logo", profileMW(), rbacMW("Admin", "AdvertiserManager", "AffiliateManager"), opts.TrackingLinkQRHandler.GetOrganizationLogo)
+	organizations.PUT("/:id/logo", profileMW(), rbacMW("Admin", "AdvertiserManager", "AffiliateManager"), opts.TrackingLinkQRHandler.UploadOrganizationLogo)
+	organizations.DELETE("/:id/logo", profileMW(), rbacMW("Admin", "AdvertiserManager", "AffiliateManager"), opts.TrackingLinkQRHandler.DeleteOrganizationLogo)
+
+	// Custom tracking domains and per-campaign overrides
+	trackingDomains := organizations.Group("/:id/tracking-domains")
+	trackingDomains.Use(profileMW())
+	trackingDomains.Use(rbacMW("Admin", "AdvertiserManager"))
+	{
+		trackingDomains.POST("", opts.TrackingDomainHandler.CreateTrackingDomain)
+		trackingDomains.GET("", opts.TrackingDomainHandler.ListTrackingDomains)
+		trackingDomains.DELETE("/default", opts.TrackingDomainHandler.ClearDefaultTrackingDomain)
+		trackingDomains.GET("/:domain_id", opts.TrackingDomainHandler.GetTrackingDomain)
+		trackingDomains.DELETE("/:domain_id", opts.TrackingDomainHandler.DeleteTrackingDomain)
+		trackingDomains.POST("/:domain_id/verify", opts.TrackingDomainHandler.VerifyTrackingDomain)
+		trackingDomains.PUT("/:domain_id/default", opts.TrackingDomainHandler.SetDefaultTrackingDomain)
+	}
+
+	// Signing of native tracking link parameters, with key rotation and tamper events
+	linkSigning := organizations.Group("/:id/tracking-link-signing")
+	linkSigning.Use(profileMW())
+	linkSigning.Use(rbacMW("Admin", "AdvertiserManager"))
+	{
+		linkSigning.GET("", opts.TrackingLinkSigningHandler.GetTrackingLinkSigning)
+		linkSigning.PUT("", opts.TrackingLinkSigningHandler.SetTrackingLinkSigning)
+		linkSigning.DELETE("", opts.TrackingLinkSigningHandler.DisableTrackingLinkSigning)
+		linkSigning.POST("/keys", opts.TrackingLinkSigningHandler.RotateTrackingLinkSigningKey)
+		linkSigning.DELETE("/keys/:key_id", opts.TrackingLinkSigningHandler.RevokeTrackingLinkSigningKey)
+		linkSigning.GET("/events", opts.TrackingLinkSigningHandler.ListTrackingLinkTamperEvents)
+	}
+	organizations.GET("/:id/campaigns/:campaign_id/tracking-domain", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.TrackingDomainHandler.GetCampaignTrackingDomain)
+	organizations.PUT("/:id/campaigns/:campaign_id/tracking-domain", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.TrackingDomainHandler.SetCampaignTrackingDomain)
+
+	// Campaign traffic targeting rulesets
+	organizations.GET("/:id/campaigns/:campaign_id/targeting", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CampaignTargetingHandler.GetCampaignTargeting)
+	organizations.PUT("/:id/campaigns/:campaign_id/targeting", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CampaignTargetingHandler.SetCampaignTargeting)
+	organizations.DELETE("/:id/campaigns/:campaign_id/targeting", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CampaignTargetingHandler.DeleteCampaignTargeting)
+
+	// Destination URL macros and passthrough parameters
+	organizations.GET("/:id/campaigns/:campaign_id/url-settings", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CampaignURLHandler.GetCampaignURLSettings)
+	organizations.PUT("/:id/campaigns/:campaign_id/url-settings", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CampaignURLHandler.SetCampaignURLSettings)
+	organizations.POST("/:id/campaigns/:campaign_id/url-preview", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CampaignURLHandler.PreviewCampaignURL)
+
+	// Campaign link health monitoring
+	organizations.GET("/:id/campaigns/:campaign_id/link-health", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.LinkHealthHandler.GetCampaignLinkHealth)
+	organizations.PUT("/:id/campaigns/:campaign_id/link-health", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.LinkHealthHandler.SetCampaignLinkHealth)
+	organizations.GET("/:id/campaigns/:campaign_id/link-health/checks", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.LinkHealthHandler.ListCampaignLinkHealthChecks)
+
+	// Campaign creatives, and creatives wrapped with an affiliate's tracking link
+	organizations.GET("/:id/campaigns/:campaign_id/creatives", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CreativeHandler.ListCreatives)
+	organizations.POST("/:id/campaigns/:campaign_id/creatives", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CreativeHandler.CreateCreative)
+	organizations.GET("/:id/campaigns/:campaign_id/creatives/:creative_id", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CreativeHandler.GetCreative)
+	organizations.PUT("/:id/campaigns/:campaign_id/creatives/:creative_id", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CreativeHandler.UpdateCreative)
+	organizations.DELETE("/:id/campaigns/:campaign_id/creatives/:creative_id", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CreativeHandler.DeleteCreative)
+	organizations.POST("/:id/campaigns/:campaign_id/creatives/:creative_id/review", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.CreativeHandler.ReviewCreative)
+	organizations.GET("/:id/tracking-links/:link_id/creatives", profileMW(), rbacMW("Admin", "AdvertiserManager", "AffiliateManager"), opts.CreativeHandler.ListTrackingLinkCreatives)
+
+	// Bulk tracking link generation jobs
+	organizations.POST("/:id/tracking-link-jobs", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.BulkTrackingLinkHandler.CreateJob)
+	organizations.GET("/:id/tracking-link-jobs", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.BulkTrackingLinkHandler.ListJobs)
+	organizations.GET("/:id/tracking-link-jobs/:job_id", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.BulkTrackingLinkHandler.GetJob)
+	organizations.GET("/:id/tracking-link-jobs/:job_id/items", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.BulkTrackingLinkHandler.ListJobItems)
+	organizations.GET("/:id/tracking-link-jobs/:job_id/export", profileMW(), rbacMW("Admin", "AdvertiserManager"), opts.BulkTrackingLinkHandler.ExportJob)
+
+	// Smart links routing clicks across an affiliate's tracking links
+	smartLinks := organizations.Group("/:id/smart-links")
+	smartLinks.Use(profileMW())
+	smartLinks.Use(rbacMW("Admin", "AffiliateManager"))
+	{
+		smartLinks.POST("", opts.SmartLinkHandler.CreateSmartLink)
+		smartLinks.GET("", opts.SmartLinkHandler.ListSmartLinks)
+		smartLinks.GET("/:smart_link_id", opts.SmartLinkHandler.GetSmartLink)
+		smartLinks.PUT("/:smart_link_id", opts.SmartLinkHandler.UpdateSmartLink)
+		smartLinks.DELETE("/:smart_link_id", opts.SmartLinkHandler.DeleteSmartLink)
+		smartLinks.POST("/:smart_link_id/simulate", opts.SmartLinkHandler.SimulateSmartLink)
+		smartLinks.GET("/:smart_link_id/stats", opts.SmartLinkHandler.GetSmartLinkStats)
+	}
 
 	// --- Analytics Routes ---
 	analytics := v1.Group("/analytics")
@@ -201,7 +321,15 @@ func SetupRouter(opts RouterOptions) *gin.Engine {
 		// Publisher/Affiliate analytics endpoints
 		analytics.GET("/affiliates/:id", opts.AnalyticsHandler.GetPublisherByID)
 		analytics.GET("/affiliates/domain/:domain", opts.AnalyticsHandler.GetPublisherByDomain)
+		analytics.GET("/affiliates/domain/:domain/similar", opts.AnalyticsHandler.GetSimilarPublishers)
+		analytics.GET("/affiliates/domain/:domain/history", opts.AnalyticsHandler.GetPublisherHistory)
+		analytics.POST("/affiliates/discover", opts.AnalyticsHandler.DiscoverPublishers)
 		analytics.POST("/affiliates", opts.AnalyticsHandler.CreatePublisher) // For future data management
+
+		// Bulk import endpoints (admin only)
+		analytics.POST("/imports", rbacMW("Admin"), opts.AnalyticsImportHandler.CreateImport)
+		analytics.GET("/imports", rbacMW("Admin"), opts.AnalyticsImportHandler.ListImports)
+		analytics.GET("/imports/:import_id", rbacMW("Admin"), opts.AnalyticsImportHandler.GetImport)
 	}
 
 	// --- Favorite Publisher Lists Routes ---
@@ -222,11 +350,43 @@ func SetupRouter(opts RouterOptions) *gin.Engine {
 		favoritePublisherLists.PUT("/:list_id/publishers/:domain", opts.FavoritePublisherListHandler.UpdatePublisherInList)
 		favoritePublisherLists.PATCH("/:list_id/publishers/:domain/status", opts.FavoritePublisherListHandler.UpdatePublisherStatus)
 		favoritePublisherLists.DELETE("/:list_id/publishers/:domain", opts.FavoritePublisherListHandler.RemovePublisherFromList)
+		favoritePublisherLists.GET("/:list_id/recommendations", opts.FavoritePublisherListHandler.GetListRecommendations)
+
+		// Bulk operations
+		favoritePublisherLists.POST("/:list_id/publishers/import", opts.FavoritePublisherListHandler.ImportPublishers)
+		favoritePublisherLists.POST("/:list_id/publishers/bulk", opts.FavoritePublisherListHandler.BulkUpdatePublishers)
+		favoritePublisherLists.POST("/:list_id/copy", opts.FavoritePublisherListHandler.CopyPublishers)
+		favoritePublisherLists.POST("/:list_id/merge", opts.FavoritePublisherListHandler.MergeList)
+		favoritePublisherLists.GET("/:list_id/export", opts.FavoritePublisherListHandler.ExportList)
+
+		// Outreach pipeline
+		favoritePublisherLists.GET("/pipeline-stages", opts.PublisherPipelineHandler.ListStages)
+		favoritePublisherLists.POST("/pipeline-stages", opts.PublisherPipelineHandler.CreateStage)
+		favoritePublisherLists.PUT("/pipeline-stages/order", opts.PublisherPipelineHandler.ReorderStages)
+		favoritePublisherLists.PUT("/pipeline-stages/:stage_id", opts.PublisherPipelineHandler.UpdateStage)
+		favoritePublisherLists.DELETE("/pipeline-stages/:stage_id", opts.PublisherPipelineHandler.DeleteStage)
+		favoritePublisherLists.GET("/:list_id/board", opts.PublisherPipelineHandler.GetBoard)
+		favoritePublisherLists.PATCH("/:list_id/publishers/:domain/stage", opts.PublisherPipelineHandler.MoveItem)
+		favoritePublisherLists.PUT("/:list_id/publishers/:domain/follow-up", opts.PublisherPipelineHandler.UpdateFollowUp)
+		favoritePublisherLists.GET("/:list_id/publishers/:domain/history", opts.PublisherPipelineHandler.GetItemHistory)
+
+		// Affiliate onboarding
+		favoritePublisherLists.POST("/:list_id/publishers/:domain/convert", opts.PublisherConversionHandler.ConvertPublisher)
 
 		// Utility endpoints
 		favoritePublisherLists.GET("/search", opts.FavoritePublisherListHandler.GetListsContainingPublisher)
 	}
 
+	// --- Publisher Conversion Routes ---
+	publisherConversions := v1.Group("/publisher-conversions")
+	publisherConversions.Use(profileMW())                                              // Load profile first to get user role
+	publisherConversions.Use(rbacMW("AdvertiserManager", "AffiliateManager", "Admin")) // Allow all managers and admins
+	{
+		publisherConversions.GET("", opts.PublisherConversionHandler.ListConversions)
+		publisherConversions.GET("/funnel", opts.PublisherConversionHandler.GetFunnel)
+		publisherConversions.GET("/:conversion_id", opts.PublisherConversionHandler.GetConversion)
+	}
+
 	// --- Publisher Messaging Routes ---
 	publisherMessaging := v1.Group("/publisher-messaging")
 	publisherMessaging.Use(profileMW())                                              // Load profile first to get user role
@@ -238,14 +398,58 @@ func SetupRouter(opts RouterOptions) *gin.Engine {
 		publisherMessaging.GET("/conversations/:conversation_id", opts.PublisherMessagingHandler.GetConversation)
 		publisherMessaging.PUT("/conversations/:conversation_id/status", opts.PublisherMessagingHandler.UpdateConversationStatus)
 		publisherMessaging.DELETE("/conversations/:conversation_id", opts.PublisherMessagingHandler.DeleteConversation)
+		publisherMessaging.POST("/conversations/:conversation_id/read", opts.PublisherMessagingHandler.MarkConversationRead)
 
 		// Message management
 		publisherMessaging.POST("/conversations/:conversation_id/messages", opts.PublisherMessagingHandler.AddMessage)
+		publisherMessaging.GET("/conversations/:conversation_id/messages/:message_id/attachments/:index", opts.InboundEmailHandler.DownloadMessageAttachment)
+		publisherMessaging.POST("/conversations/:conversation_id/messages/:message_id/read", opts.PublisherMessagingHandler.MarkMessageRead)
+		publisherMessaging.DELETE("/conversations/:conversation_id/messages/:message_id/read", opts.PublisherMessagingHandler.MarkMessageUnread)
+		publisherMessaging.GET("/messages/search", opts.PublisherMessagingHandler.SearchMessages)
+
+		// Message templates
+		publisherMessaging.POST("/templates", opts.PublisherMessageTemplateHandler.CreateTemplate)
+		publisherMessaging.GET("/templates", opts.PublisherMessageTemplateHandler.ListTemplates)
+		publisherMessaging.GET("/templates/:template_id", opts.PublisherMessageTemplateHandler.GetTemplate)
+		publisherMessaging.PUT("/templates/:template_id", opts.PublisherMessageTemplateHandler.UpdateTemplate)
+		publisherMessaging.DELETE("/templates/:template_id", opts.PublisherMessageTemplateHandler.DeleteTemplate)
+		publisherMessaging.POST("/templates/:template_id/render", opts.PublisherMessageTemplateHandler.RenderTemplate)
 
 		// External service integration (no RBAC required for external services)
 		publisherMessaging.POST("/conversations/:conversation_id/external-messages", opts.PublisherMessagingHandler.AddExternalMessage)
 	}
 
+	// --- Outreach Sequence Routes ---
+	outreachSequences := v1.Group("/outreach-sequences")
+	outreachSequences.Use(profileMW())                                              // Load profile first to get user role
+	outreachSequences.Use(rbacMW("AdvertiserManager", "AffiliateManager", "Admin")) // Allow all managers and admins
+	{
+		outreachSequences.POST("", opts.OutreachSequenceHandler.CreateSequence)
+		outreachSequences.GET("", opts.OutreachSequenceHandler.ListSequences)
+		outreachSequences.GET("/:sequence_id", opts.OutreachSequenceHandler.GetSequence)
+		outreachSequences.PUT("/:sequence_id", opts.OutreachSequenceHandler.UpdateSequence)
+		outreachSequences.DELETE("/:sequence_id", opts.OutreachSequenceHandler.DeleteSequence)
+
+		// Enrolling the publishers of a favorite list
+		outreachSequences.POST("/:sequence_id/launch", opts.OutreachSequenceHandler.LaunchSequence)
+		outreachSequences.GET("/:sequence_id/enrollments", opts.OutreachSequenceHandler.ListEnrollments)
+	}
+
+	// --- Notification Routes ---
+	notifications := v1.Group("/notifications")
+	notifications.Use(profileMW()) // Notifications belong to the current user, whatever their role
+	{
+		notifications.GET("", opts.NotificationHandler.ListNotifications)
+		notifications.GET("/unread-count", opts.NotificationHandler.GetUnreadCount)
+		notifications.GET("/stream", opts.NotificationHandler.StreamNotifications)
+		notifications.POST("/read-all", opts.NotificationHandler.MarkAllRead)
+		notifications.GET("/preferences", opts.NotificationHandler.GetPreferences)
+		notifications.PUT("/preferences", opts.NotificationHandler.UpdatePreferences)
+		notifications.POST("/:notification_id/read", opts.NotificationHandler.MarkRead)
+		notifications.DELETE("/:notification_id/read", opts.NotificationHandler.MarkUnread)
+		notifications.DELETE("/:notification_id", opts.NotificationHandler.DeleteNotification)
+	}
+
 	// --- Billing Routes ---
 	billing := v1.Group("/billing")
 	billing.Use(profileMW()) // Load profile for access control validation in handlers
@@ -263,6 +467,35 @@ func SetupRouter(opts RouterOptions) *gin.Engine {
 		billing.GET("/transactions", opts.BillingHandler.GetTransactionHistory)
 	}
 
+	// --- Report Routes ---
+	reports := v1.Group("/reports")
+	reports.Use(profileMW()) // Load profile; report data is scoped to the caller's organization
+	{
+		reports.POST("/query", opts.ReportHandler.RunReport)
+	}
+
+	// --- Scheduled Report Routes ---
+	scheduledReports := v1.Group("/scheduled-reports")
+	scheduledReports.Use(profileMW()) // Load profile; reports are owned by the caller's organization
+	{
+		scheduledReports.POST("", opts.ScheduledReportHandler.CreateScheduledReport)
+		scheduledReports.GET("", opts.ScheduledReportHandler.ListScheduledReports)
+		scheduledReports.GET("/:id", opts.ScheduledReportHandler.GetScheduledReport)
+		scheduledReports.PUT("/:id", opts.ScheduledReportHandler.UpdateScheduledReport)
+		scheduledReports.DELETE("/:id", opts.ScheduledReportHandler.DeleteScheduledReport)
+		scheduledReports.POST("/:id/run", opts.ScheduledReportHandler.RunScheduledReport)
+		scheduledReports.GET("/:id/runs", opts.ScheduledReportHandler.ListRuns)
+		scheduledReports.GET("/:id/runs/:run_id/download", opts.ScheduledReportHandler.DownloadRun)
+	}
+
+	// --- Provider Stats Routes ---
+	providerStats := v1.Group("/provider-stats")
+	providerStats.Use(profileMW(), rbacMW("Admin"))
+	{
+		providerStats.POST("/imports", opts.ProviderStatsHandler.Backfill)
+		providerStats.GET("/imports", opts.ProviderStatsHandler.ListImports)
+	}
+
 	// --- Organization Association Routes ---
 	orgAssociations := v1.Group("/organization-associations")
 	orgAssociations.Use(profileMW()) // Load profile first to get user role
@@ -32,6 +32,46 @@ type Config struct {
 	
 	// Everflow API configuration
 	EverflowAPIKey string `mapstructure:"EVERFLOW_API_KEY"` // Everflow API key for authentication
+
+	// Public base URL of this API, used for links in outgoing emails
+	APIBaseURL string `mapstructure:"API_BASE_URL"`
+
+	// Base URL of native tracking links (e.g. https://trk.example.com) for organizations without a
+	// verified tracking domain; provider URLs are handed out when it is empty
+	TrackingBaseURL string `mapstructure:"TRACKING_BASE_URL"`
+
+	// MaxMind DB file (GeoIP2/GeoLite2 City, Country, ISP or ASN) used to locate visitors for
+	// campaign targeting on native redirects; CDN country headers are used when it is empty
+	GeoIPDatabasePath string `mapstructure:"GEOIP_DATABASE_PATH"`
+
+	// Comma-separated IPs and CIDR ranges of the CDN or load balancers in front of the API. Only
+	// requests from them have X-Forwarded-For and CDN country headers honoured; none are trusted when empty.
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+
+	// SMTP configuration for outgoing email; emails are only logged when SMTP_HOST is empty
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom     string `mapstructure:"SMTP_FROM"`
+
+	// Publisher message replies are addressed to reply+<conversation>-<signature>@PUBLISHER_REPLY_DOMAIN;
+	// no Reply-To is set when the domain is empty. The secret defaults to ENCRYPTION_KEY.
+	PublisherReplyDomain string `mapstructure:"PUBLISHER_REPLY_DOMAIN"`
+	PublisherReplySecret string `mapstructure:"PUBLISHER_REPLY_SECRET"`
+
+	// Inbound publisher replies arrive through the inbound email webhook, authenticated with
+	// INBOUND_EMAIL_SECRET, or through the SMTP listener on INBOUND_SMTP_ADDR when it is set
+	InboundEmailSecret string `mapstructure:"INBOUND_EMAIL_SECRET"`
+	InboundSMTPAddr    string `mapstructure:"INBOUND_SMTP_ADDR"`
+
+	// Report file storage (S3-compatible); files are kept in REPORT_STORAGE_DIR when no bucket is set
+	ReportStorageEndpoint  string `mapstructure:"REPORT_STORAGE_ENDPOINT"`
+	ReportStorageRegion    string `mapstructure:"REPORT_STORAGE_REGION"`
+	ReportStorageBucket    string `mapstructure:"REPORT_STORAGE_BUCKET"`
+	ReportStorageAccessKey string `mapstructure:"REPORT_STORAGE_ACCESS_KEY"`
+	ReportStorageSecretKey string `mapstructure:"REPORT_STORAGE_SECRET_KEY"`
+	ReportStorageDir       string `mapstructure:"REPORT_STORAGE_DIR"`
 }
 
 var AppConfig Config
@@ -59,6 +99,27 @@ func LoadConfig() {
 	viper.SetDefault("EVERFLOW_API_KEY", "")    // Default password, should be overridden
 	viper.SetDefault("MockMode", false)
 
+	// Email and report storage defaults
+	viper.SetDefault("API_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("TRACKING_BASE_URL", "")
+	viper.SetDefault("GEOIP_DATABASE_PATH", "")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("SMTP_FROM", "noreply@localhost")
+	viper.SetDefault("PUBLISHER_REPLY_DOMAIN", "")
+	viper.SetDefault("PUBLISHER_REPLY_SECRET", "")
+	viper.SetDefault("INBOUND_EMAIL_SECRET", "")
+	viper.SetDefault("INBOUND_SMTP_ADDR", "")
+	viper.SetDefault("REPORT_STORAGE_ENDPOINT", "")
+	viper.SetDefault("REPORT_STORAGE_REGION", "us-east-1")
+	viper.SetDefault("REPORT_STORAGE_BUCKET", "")
+	viper.SetDefault("REPORT_STORAGE_ACCESS_KEY", "")
+	viper.SetDefault("REPORT_STORAGE_SECRET_KEY", "")
+	viper.SetDefault("REPORT_STORAGE_DIR", "./data/reports")
+
 	// Logging defaults
 	viper.SetDefault("LOG_LEVEL", "INFO")
 	viper.SetDefault("LOG_FORMAT", "text")
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"encoding/json"
+	"net/mail"
+	"strings"
 	"time"
 )
 
@@ -282,3 +284,48 @@ func (p *AnalyticsPublisher) GetSocialMedia() (*SocialMediaData, error) {
 	err := json.Unmarshal([]byte(*p.SocialMedia), &data)
 	return &data, err
 }
+
+// GetContactEmails parses and returns the contact emails stored in the publisher's additional data
+func (p *AnalyticsPublisher) GetContactEmails() (*ContactEmailData, error) {
+	if p.AdditionalData == nil {
+		return nil, nil
+	}
+	var additional struct {
+		ContactEmails *ContactEmailData `json:"contactEmails"`
+	}
+	if err := json.Unmarshal([]byte(*p.AdditionalData), &additional); err != nil {
+		return nil, err
+	}
+	return additional.ContactEmails, nil
+}
+
+// PrimaryContactEmail returns the address best suited for partnership outreach, preferring
+// partnership, affiliate and marketing departments. It returns "" when no address is known.
+func (p *AnalyticsPublisher) PrimaryContactEmail() (string, error) {
+	emails, err := p.GetContactEmails()
+	if err != nil || emails == nil {
+		return "", err
+	}
+
+	best, bestRank := "", -1
+	for _, email := range emails.Value {
+		address := strings.TrimSpace(email.Value)
+		if _, err := mail.ParseAddress(address); err != nil {
+			continue
+		}
+		rank := 0
+		if email.Department != nil {
+			department := strings.ToLower(*email.Department)
+			switch {
+			case strings.Contains(department, "partner"), strings.Contains(department, "affiliate"):
+				rank = 2
+			case strings.Contains(department, "marketing"), strings.Contains(department, "advertis"):
+				rank = 1
+			}
+		}
+		if rank > bestRank {
+			best, bestRank = address, rank
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,424 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Analytics import kinds
+const (
+	AnalyticsImportPublishers  = "publishers"
+	AnalyticsImportAdvertisers = "advertisers"
+)
+
+// Analytics import statuses
+const (
+	AnalyticsImportStatusRunning   = "running"
+	AnalyticsImportStatusCompleted = "completed"
+	AnalyticsImportStatusFailed    = "failed"
+)
+
+const (
+	// DefaultAnalyticsImportBatchSize is the number of records upserted per COPY batch by default
+	DefaultAnalyticsImportBatchSize = 500
+	// MaxAnalyticsImportBatchSize is the maximum number of records upserted per COPY batch
+	MaxAnalyticsImportBatchSize = 5000
+	// MaxAnalyticsImportErrors limits how many per-row errors are recorded for one import;
+	// further failures are still counted
+	MaxAnalyticsImportErrors = 1000
+	// MaxAnalyticsImportLineBytes limits the size of a single NDJSON record
+	MaxAnalyticsImportLineBytes = 64 << 20
+)
+
+// Column limits of analytics_publishers and analytics_advertisers
+const (
+	maxAnalyticsDomainLength = 255
+	maxPromotypeLength       = 50
+	maxRelevance             = 999.99      // DECIMAL(5,2)
+	maxTrafficScore          = 99999999.99 // DECIMAL(10,2)
+)
+
+// AnalyticsImport records one bulk load of analytics publishers or advertisers
+type AnalyticsImport struct {
+	ImportID        int64                  `json:"import_id" db:"import_id"`
+	Kind            string                 `json:"kind" db:"kind"`
+	Source          string                 `json:"source" db:"source"` // File name or upload name
+	Status          string                 `json:"status" db:"status"`
+	InsertedCount   int                    `json:"inserted_count" db:"inserted_count"`
+	UpdatedCount    int                    `json:"updated_count" db:"updated_count"`
+	SkippedCount    int                    `json:"skipped_count" db:"skipped_count"` // Unchanged or superseded records
+	FailedCount     int                    `json:"failed_count" db:"failed_count"`
+	ErrorMessage    *string                `json:"error_message,omitempty" db:"error_message"`
+	CreatedByUserID *string                `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	StartedAt       time.Time              `json:"started_at" db:"started_at"`
+	CompletedAt     *time.Time             `json:"completed_at,omitempty" db:"completed_at"`
+	Errors          []AnalyticsImportError `json:"errors,omitempty"`
+}
+
+// AnalyticsImportError is a record that could not be imported
+type AnalyticsImportError struct {
+	Line    int    `json:"line" db:"line_number"`
+	Domain  string `json:"domain,omitempty" db:"domain"`
+	Message string `json:"message" db:"message"`
+}
+
+// IsValidAnalyticsImportKind reports whether kind is a supported import kind
+func IsValidAnalyticsImportKind(kind string) bool {
+	return kind == AnalyticsImportPublishers || kind == AnalyticsImportAdvertisers
+}
+
+// AnalyticsPublisherImportRecord is a parsed publisher line of an import file
+type AnalyticsPublisherImportRecord struct {
+	Line        int
+	Publisher   *AnalyticsPublisher
+	Snapshot    *AnalyticsPublisherSnapshot
+	ContentHash string // Hash of the source record, used to skip unchanged publishers
+}
+
+// AnalyticsAdvertiserImportRecord is a parsed advertiser line of an import file
+type AnalyticsAdvertiserImportRecord struct {
+	Line        int
+	Advertiser  *AnalyticsAdvertiser
+	Snapshot    *AnalyticsAdvertiserSnapshot
+	ContentHash string // Hash of the source record, used to skip unchanged advertisers
+}
+
+// AnalyticsPublisherSnapshot holds the key metrics of a publisher at the time of an import
+type AnalyticsPublisherSnapshot struct {
+	SnapshotID        int64     `json:"snapshot_id" db:"snapshot_id"`
+	PublisherID       int64     `json:"publisher_id" db:"publisher_id"`
+	Domain            string    `json:"domain" db:"domain"`
+	ImportID          *int64    `json:"import_id,omitempty" db:"import_id"`
+	ContentHash       string    `json:"-" db:"content_hash"`
+	TrafficScore      float64   `json:"traffic_score" db:"traffic_score"`
+	Relevance         float64   `json:"relevance" db:"relevance"`
+	Known             bool      `json:"known" db:"known"`
+	Promotype         *string   `json:"promotype,omitempty" db:"promotype"`
+	PartnerCount      int       `json:"partner_count" db:"partner_count"`
+	AffiliateNetworks []string  `json:"affiliate_networks" db:"affiliate_networks"`
+	Verticals         []string  `json:"verticals" db:"verticals"`
+	Countries         []string  `json:"countries" db:"countries"` // Upper case country codes
+	CapturedAt        time.Time `json:"captured_at" db:"captured_at"`
+}
+
+// AnalyticsAdvertiserSnapshot holds the key metrics of an advertiser at the time of an import
+type AnalyticsAdvertiserSnapshot struct {
+	SnapshotID             int64     `json:"snapshot_id" db:"snapshot_id"`
+	AdvertiserID           int64     `json:"advertiser_id" db:"advertiser_id"`
+	Domain                 string    `json:"domain" db:"domain"`
+	ImportID               *int64    `json:"import_id,omitempty" db:"import_id"`
+	ContentHash            string    `json:"-" db:"content_hash"`
+	ContactEmailCount      int       `json:"contact_email_count" db:"contact_email_count"`
+	RelatedAdvertiserCount int       `json:"related_advertiser_count" db:"related_advertiser_count"`
+	AffiliateNetworks      []string  `json:"affiliate_networks" db:"affiliate_networks"`
+	Verticals              []string  `json:"verticals" db:"verticals"`
+	CapturedAt             time.Time `json:"captured_at" db:"captured_at"`
+}
+
+// Fields of the publisher and advertiser API shapes that map to their own columns;
+// everything else is kept in additional_data
+var (
+	publisherJSONFields = []string{
+		"affiliateNetworks", "countryRankings", "keywords", "verticals", "verticalsV2",
+		"socialMedia", "partnerInformation", "partners", "relatedPublishers", "liveUrls",
+	}
+	publisherScalarFields = []string{"domain", "metaData", "known", "relevance", "trafficScore", "promotype"}
+
+	advertiserJSONFields = []string{
+		"affiliateNetworks", "contactEmails", "keywords", "verticals",
+		"socialMedia", "partnerInformation", "relatedAdvertisers", "backlinks",
+	}
+	advertiserScalarFields = []string{"domain", "metaData"}
+)
+
+// NewAnalyticsPublisherFromData maps publisher data in the AnalyticsPublisherResponse shape onto
+// the analytics_publishers columns. Fields without a column are kept in AdditionalData.
+func NewAnalyticsPublisherFromData(domainName string, data map[string]interface{}) *AnalyticsPublisher {
+	publisher := &AnalyticsPublisher{Domain: domainName}
+	publisher.Description, publisher.FaviconImageURL, publisher.ScreenshotImageURL = extractMetaData(data)
+
+	if known, ok := data["known"].(map[string]interface{}); ok {
+		if value, ok := known["value"].(bool); ok {
+			publisher.Known = value
+		}
+	}
+	if relevance, ok := data["relevance"].(float64); ok {
+		publisher.Relevance = relevance
+	}
+	if trafficScore, ok := data["trafficScore"].(float64); ok {
+		publisher.TrafficScore = trafficScore
+	}
+	if promotype, ok := data["promotype"].(map[string]interface{}); ok {
+		if value, ok := promotype["value"].(string); ok {
+			publisher.Promotype = &value
+		}
+	}
+
+	columns := []**string{
+		&publisher.AffiliateNetworks, &publisher.CountryRankings, &publisher.Keywords, &publisher.Verticals, &publisher.VerticalsV2,
+		&publisher.SocialMedia, &publisher.PartnerInformation, &publisher.Partners, &publisher.RelatedPublishers, &publisher.LiveURLs,
+	}
+	extractJSONFields(data, publisherJSONFields, columns)
+	publisher.AdditionalData = remainingData(data, publisherScalarFields, publisherJSONFields)
+
+	return publisher
+}
+
+// NewAnalyticsAdvertiserFromData maps advertiser data in the AnalyticsAdvertiserResponse shape onto
+// the analytics_advertisers columns. Fields without a column are kept in AdditionalData.
+func NewAnalyticsAdvertiserFromData(domainName string, data map[string]interface{}) *AnalyticsAdvertiser {
+	advertiser := &AnalyticsAdvertiser{Domain: domainName}
+	advertiser.Description, advertiser.FaviconImageURL, advertiser.ScreenshotImageURL = extractMetaData(data)
+
+	columns := []**string{
+		&advertiser.AffiliateNetworks, &advertiser.ContactEmails, &advertiser.Keywords, &advertiser.Verticals,
+		&advertiser.SocialMedia, &advertiser.PartnerInformation, &advertiser.RelatedAdvertisers, &advertiser.Backlinks,
+	}
+	extractJSONFields(data, advertiserJSONFields, columns)
+	advertiser.AdditionalData = remainingData(data, advertiserScalarFields, advertiserJSONFields)
+
+	return advertiser
+}
+
+// ParseAnalyticsPublisherRecord parses one NDJSON line of the form {"publisher": {...}}
+func ParseAnalyticsPublisherRecord(line []byte) (*AnalyticsPublisherImportRecord, error) {
+	data, domainName, hash, err := parseAnalyticsImportLine(line, "publisher")
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := NewAnalyticsPublisherFromData(domainName, data)
+	if publisher.Promotype != nil && len(*publisher.Promotype) > maxPromotypeLength {
+		return nil, fmt.Errorf("promotype must be at most %d characters", maxPromotypeLength)
+	}
+	if publisher.Relevance < 0 || publisher.Relevance > maxRelevance {
+		return nil, fmt.Errorf("relevance must be between 0 and %v", maxRelevance)
+	}
+	if publisher.TrafficScore < 0 || publisher.TrafficScore > maxTrafficScore {
+		return nil, fmt.Errorf("trafficScore must be between 0 and %v", maxTrafficScore)
+	}
+
+	return &AnalyticsPublisherImportRecord{
+		Publisher:   publisher,
+		Snapshot:    NewAnalyticsPublisherSnapshot(publisher),
+		ContentHash: hash,
+	}, nil
+}
+
+// ParseAnalyticsAdvertiserRecord parses one NDJSON line of the form {"advertiser": {...}}
+func ParseAnalyticsAdvertiserRecord(line []byte) (*AnalyticsAdvertiserImportRecord, error) {
+	data, domainName, hash, err := parseAnalyticsImportLine(line, "advertiser")
+	if err != nil {
+		return nil, err
+	}
+
+	advertiser := NewAnalyticsAdvertiserFromData(domainName, data)
+	return &AnalyticsAdvertiserImportRecord{
+		Advertiser:  advertiser,
+		Snapshot:    NewAnalyticsAdvertiserSnapshot(advertiser),
+		ContentHash: hash,
+	}, nil
+}
+
+// NewAnalyticsPublisherSnapshot captures the key metrics of a publisher. Unparseable JSON
+// columns are treated as empty.
+func NewAnalyticsPublisherSnapshot(p *AnalyticsPublisher) *AnalyticsPublisherSnapshot {
+	snapshot := &AnalyticsPublisherSnapshot{
+		Domain:            p.Domain,
+		TrafficScore:      p.TrafficScore,
+		Relevance:         p.Relevance,
+		Known:             p.Known,
+		Promotype:         p.Promotype,
+		AffiliateNetworks: make([]string, 0),
+		Verticals:         make([]string, 0),
+		Countries:         make([]string, 0),
+	}
+
+	snapshot.PartnerCount = countedListSize(p.Partners)
+	if networks, err := p.GetAffiliateNetworks(); err == nil && networks != nil {
+		snapshot.AffiliateNetworks = sortedUnique(networks.Value, false)
+	}
+	if verticals, err := p.GetVerticalsV2(); err == nil && verticals != nil {
+		names := make([]string, 0, len(verticals.Value))
+		for _, vertical := range verticals.Value {
+			names = append(names, vertical.Name)
+		}
+		snapshot.Verticals = sortedUnique(names, false)
+	}
+	if rankings, err := p.GetCountryRankings(); err == nil && rankings != nil {
+		codes := make([]string, 0, len(rankings.Value))
+		for _, country := range rankings.Value {
+			codes = append(codes, country.CountryCode)
+		}
+		snapshot.Countries = sortedUnique(codes, true)
+	}
+
+	return snapshot
+}
+
+// NewAnalyticsAdvertiserSnapshot captures the key metrics of an advertiser. Unparseable JSON
+// columns are treated as empty.
+func NewAnalyticsAdvertiserSnapshot(a *AnalyticsAdvertiser) *AnalyticsAdvertiserSnapshot {
+	snapshot := &AnalyticsAdvertiserSnapshot{
+		Domain:            a.Domain,
+		AffiliateNetworks: make([]string, 0),
+		Verticals:         make([]string, 0),
+	}
+
+	snapshot.ContactEmailCount = countedListSize(a.ContactEmails)
+	snapshot.RelatedAdvertiserCount = countedListSize(a.RelatedAdvertisers)
+	if networks, err := a.GetAffiliateNetworks(); err == nil && networks != nil {
+		snapshot.AffiliateNetworks = sortedUnique(networks.Value, false)
+	}
+	if verticals, err := a.GetVerticals(); err == nil && verticals != nil {
+		names := make([]string, 0, len(verticals.Value))
+		for _, vertical := range verticals.Value {
+			names = append(names, vertical.Name)
+		}
+		snapshot.Verticals = sortedUnique(names, false)
+	}
+
+	return snapshot
+}
+
+// parseAnalyticsImportLine decodes a {"<key>": {...}} record and returns its data, the normalized
+// domain and a hash of the record. The hash is computed over the re-encoded data, whose object
+// keys are sorted, so formatting differences do not count as changes.
+func parseAnalyticsImportLine(line []byte, key string) (map[string]interface{}, string, string, error) {
+	var record map[string]map[string]interface{}
+	if err := json.Unmarshal(line, &record); err != nil {
+		return nil, "", "", fmt.Errorf("invalid JSON: %v", err)
+	}
+	data, ok := record[key]
+	if !ok || data == nil {
+		return nil, "", "", fmt.Errorf("missing %q object", key)
+	}
+
+	domainName, _ := data["domain"].(string)
+	domainName = strings.ToLower(strings.TrimSpace(domainName))
+	if domainName == "" {
+		return nil, "", "", fmt.Errorf("%s.domain is required", key)
+	}
+	if len(domainName) > maxAnalyticsDomainLength {
+		return nil, "", "", fmt.Errorf("%s.domain must be at most %d characters", key, maxAnalyticsDomainLength)
+	}
+	data["domain"] = domainName
+
+	canonical, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to encode %s: %v", key, err)
+	}
+	sum := sha256.Sum256(canonical)
+
+	return data, domainName, hex.EncodeToString(sum[:]), nil
+}
+
+// extractMetaData returns the description, favicon and screenshot URLs from metaData
+func extractMetaData(data map[string]interface{}) (description, favicon, screenshot *string) {
+	metaData, ok := data["metaData"].(map[string]interface{})
+	if !ok {
+		return nil, nil, nil
+	}
+	if value, ok := metaData["description"].(string); ok {
+		description = &value
+	}
+	if value, ok := metaData["faviconImageUrl"].(string); ok {
+		favicon = &value
+	}
+	if value, ok := metaData["screenshotImageUrl"].(string); ok {
+		screenshot = &value
+	}
+	return description, favicon, screenshot
+}
+
+// extractJSONFields stores each present field as a JSON string in the matching column
+func extractJSONFields(data map[string]interface{}, fields []string, columns []**string) {
+	for i, field := range fields {
+		value, exists := data[field]
+		if !exists {
+			continue
+		}
+		if jsonBytes, err := json.Marshal(value); err == nil {
+			jsonStr := string(jsonBytes)
+			*columns[i] = &jsonStr
+		}
+	}
+}
+
+// remainingData returns the fields not mapped to a column as a JSON string, or nil if there are none
+func remainingData(data map[string]interface{}, processed ...[]string) *string {
+	skip := make(map[string]bool)
+	for _, fields := range processed {
+		for _, field := range fields {
+			skip[field] = true
+		}
+	}
+
+	remaining := make(map[string]interface{})
+	for key, value := range data {
+		if !skip[key] {
+			remaining[key] = value
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	jsonBytes, err := json.Marshal(remaining)
+	if err != nil {
+		return nil
+	}
+	jsonStr := string(jsonBytes)
+	return &jsonStr
+}
+
+// countedListSize returns the count of a {"count": n, "value": [...]} column, falling back to
+// the length of value when count is missing
+func countedListSize(column *string) int {
+	if column == nil {
+		return 0
+	}
+	var list struct {
+		Count *int              `json:"count"`
+		Value []json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(*column), &list); err != nil {
+		return 0
+	}
+	if list.Count != nil {
+		return *list.Count
+	}
+	return len(list.Value)
+}
+
+// sortedUnique returns the non-empty values sorted and without duplicates
+func sortedUnique(values []string, upper bool) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if upper {
+			value = strings.ToUpper(value)
+		}
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AnalyticsImportListResponse represents a page of analytics imports
+type AnalyticsImportListResponse struct {
+	Imports  []*AnalyticsImport `json:"imports"`
+	Total    int                `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
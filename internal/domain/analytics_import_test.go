@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAnalyticsPublisherRecord(t *testing.T) {
+	line := `{"publisher": {"domain": " Example.COM ", "known": {"value": true}, "relevance": 12.5, "trafficScore": 40,
+		"promotype": {"value": "coupon"}, "metaData": {"description": "Deals"},
+		"partners": {"count": 1200, "value": ["amazon.com"]},
+		"affiliateNetworks": {"count": 2, "value": ["Impact", "CJ", "Impact"]},
+		"verticalsV2": {"value": [{"name": "Shopping", "rank": 1, "score": 90}]},
+		"countryRankings": {"value": [{"countryCode": "us", "score": 0.8}, {"countryCode": "gb", "score": 0.2}]},
+		"traffic": {"visits": 100}}}`
+
+	record, err := ParseAnalyticsPublisherRecord([]byte(line))
+	if err != nil {
+		t.Fatalf("ParseAnalyticsPublisherRecord() error = %v", err)
+	}
+
+	publisher := record.Publisher
+	if publisher.Domain != "example.com" {
+		t.Errorf("Domain = %q, want example.com", publisher.Domain)
+	}
+	if !publisher.Known || publisher.Relevance != 12.5 || publisher.TrafficScore != 40 {
+		t.Errorf("scalars = known %v, relevance %v, traffic %v", publisher.Known, publisher.Relevance, publisher.TrafficScore)
+	}
+	if publisher.Promotype == nil || *publisher.Promotype != "coupon" {
+		t.Errorf("Promotype = %v, want coupon", publisher.Promotype)
+	}
+	if publisher.Description == nil || *publisher.Description != "Deals" {
+		t.Errorf("Description = %v, want Deals", publisher.Description)
+	}
+	if publisher.AdditionalData == nil || *publisher.AdditionalData != `{"traffic":{"visits":100}}` {
+		t.Errorf("AdditionalData = %v", publisher.AdditionalData)
+	}
+
+	snapshot := record.Snapshot
+	if snapshot.PartnerCount != 1200 {
+		t.Errorf("PartnerCount = %d, want 1200", snapshot.PartnerCount)
+	}
+	if !reflect.DeepEqual(snapshot.AffiliateNetworks, []string{"CJ", "Impact"}) {
+		t.Errorf("AffiliateNetworks = %v, want [CJ Impact]", snapshot.AffiliateNetworks)
+	}
+	if !reflect.DeepEqual(snapshot.Verticals, []string{"Shopping"}) {
+		t.Errorf("Verticals = %v, want [Shopping]", snapshot.Verticals)
+	}
+	if !reflect.DeepEqual(snapshot.Countries, []string{"GB", "US"}) {
+		t.Errorf("Countries = %v, want [GB US]", snapshot.Countries)
+	}
+
+	// Formatting and key order do not change the content hash, values do
+	reordered, err := ParseAnalyticsPublisherRecord([]byte(strings.Join(strings.Fields(line), " ")))
+	if err != nil {
+		t.Fatalf("ParseAnalyticsPublisherRecord() error = %v", err)
+	}
+	if reordered.ContentHash != record.ContentHash {
+		t.Errorf("ContentHash changed with formatting")
+	}
+	changed, err := ParseAnalyticsPublisherRecord([]byte(strings.Replace(line, `"trafficScore": 40`, `"trafficScore": 41`, 1)))
+	if err != nil {
+		t.Fatalf("ParseAnalyticsPublisherRecord() error = %v", err)
+	}
+	if changed.ContentHash == record.ContentHash {
+		t.Errorf("ContentHash did not change with trafficScore")
+	}
+}
+
+func TestParseAnalyticsPublisherRecord_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "invalid JSON", line: `{"publisher": `},
+		{name: "advertiser record", line: `{"advertiser": {"domain": "example.com"}}`},
+		{name: "missing domain", line: `{"publisher": {"trafficScore": 1}}`},
+		{name: "domain too long", line: `{"publisher": {"domain": "` + strings.Repeat("a", 256) + `"}}`},
+		{name: "relevance out of range", line: `{"publisher": {"domain": "example.com", "relevance": 1000}}`},
+		{name: "negative traffic score", line: `{"publisher": {"domain": "example.com", "trafficScore": -1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAnalyticsPublisherRecord([]byte(tt.line)); err == nil {
+				t.Errorf("ParseAnalyticsPublisherRecord(%s) expected error", tt.line)
+			}
+		})
+	}
+}
+
+func TestParseAnalyticsAdvertiserRecord(t *testing.T) {
+	line := `{"advertiser": {"domain": "shop.example", "contactEmails": {"count": 2, "value": [{"department": null, "value": "a@shop.example"}, {"department": "sales", "value": "b@shop.example"}]},
+		"relatedAdvertisers": {"value": ["other.example"]}, "verticals": {"value": [{"name": "Fashion", "rank": 1, "score": 100}]},
+		"backlinks": {"count": 5}}}`
+
+	record, err := ParseAnalyticsAdvertiserRecord([]byte(line))
+	if err != nil {
+		t.Fatalf("ParseAnalyticsAdvertiserRecord() error = %v", err)
+	}
+	if record.Advertiser.Backlinks == nil || *record.Advertiser.Backlinks != `{"count":5}` {
+		t.Errorf("Backlinks = %v", record.Advertiser.Backlinks)
+	}
+	if record.Advertiser.AdditionalData != nil {
+		t.Errorf("AdditionalData = %v, want nil", *record.Advertiser.AdditionalData)
+	}
+	if record.Snapshot.ContactEmailCount != 2 || record.Snapshot.RelatedAdvertiserCount != 1 {
+		t.Errorf("snapshot counts = %+v", record.Snapshot)
+	}
+	if !reflect.DeepEqual(record.Snapshot.Verticals, []string{"Fashion"}) {
+		t.Errorf("Verticals = %v, want [Fashion]", record.Snapshot.Verticals)
+	}
+}
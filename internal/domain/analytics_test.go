@@ -0,0 +1,48 @@
+package domain
+
+import "testing"
+
+func TestAnalyticsPublisher_PrimaryContactEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		additionalData *string
+		want           string
+	}{
+		{
+			name: "no additional data",
+			want: "",
+		},
+		{
+			name:           "no contact emails",
+			additionalData: strPtr(`{"traffic":{"visits":100}}`),
+			want:           "",
+		},
+		{
+			name:           "prefers partnerships department",
+			additionalData: strPtr(`{"contactEmails":{"count":3,"value":[{"department":null,"value":"info@example.com"},{"department":"Marketing","value":"marketing@example.com"},{"department":"Affiliate Partnerships","value":"partners@example.com"}]}}`),
+			want:           "partners@example.com",
+		},
+		{
+			name:           "skips invalid addresses",
+			additionalData: strPtr(`{"contactEmails":{"count":2,"value":[{"department":"partnerships","value":"not-an-email"},{"department":null,"value":"hello@example.com"}]}}`),
+			want:           "hello@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &AnalyticsPublisher{AdditionalData: tt.additionalData}
+			got, err := publisher.PrimaryContactEmail()
+			if err != nil {
+				t.Fatalf("PrimaryContactEmail() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PrimaryContactEmail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -45,6 +45,9 @@ type Campaign struct {
 	FixedConversionAmount      *float64 `json:"fixed_conversion_amount,omitempty" db:"fixed_conversion_amount"`         // Fixed amount paid to affiliates per conversion
 	PercentageConversionAmount *float64 `json:"percentage_conversion_amount,omitempty" db:"percentage_conversion_amount"` // Percentage of revenue paid to affiliates per conversion (0-100)
 
+	// Targeting is loaded from the campaign targeting ruleset before the campaign is synced to the provider
+	Targeting *CampaignTargetingRules `json:"-" db:"-"`
+
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,325 @@
+package domain
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Rules of a campaign targeting ruleset, as reported when a click does not match
+const (
+	TargetingRuleCountry = "country"
+	TargetingRuleRegion  = "region"
+	TargetingRuleDevice  = "device"
+	TargetingRuleBrowser = "browser"
+	TargetingRuleOS      = "os"
+	TargetingRuleISP     = "isp"
+	TargetingRuleIP      = "ip"
+	TargetingRuleProxy   = "proxy"
+	TargetingRuleDayPart = "day_part"
+)
+
+// maxTargetingRuleItems bounds each list of a targeting ruleset
+const maxTargetingRuleItems = 500
+
+// Browsers recognized in user agents
+const (
+	BrowserChrome  = "chrome"
+	BrowserSafari  = "safari"
+	BrowserFirefox = "firefox"
+	BrowserEdge    = "edge"
+	BrowserOpera   = "opera"
+	BrowserSamsung = "samsung"
+	BrowserIE      = "ie"
+	BrowserOther   = "other"
+)
+
+// Operating systems recognized in user agents
+const (
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSIOS      = "ios"
+	OSAndroid  = "android"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+	OSOther    = "other"
+)
+
+var targetingBrowsers = []string{BrowserChrome, BrowserSafari, BrowserFirefox, BrowserEdge, BrowserOpera, BrowserSamsung, BrowserIE, BrowserOther}
+
+var targetingOperatingSystems = []string{OSWindows, OSMacOS, OSIOS, OSAndroid, OSLinux, OSChromeOS, OSOther}
+
+// CampaignTargeting is the traffic targeting ruleset of a campaign. It is synced to the
+// provider with the campaign and evaluated locally on native tracking link redirects, where
+// traffic that does not match goes to the fallback URL.
+type CampaignTargeting struct {
+	CampaignID  int64                  `json:"campaign_id" db:"campaign_id"`
+	Rules       CampaignTargetingRules `json:"rules" db:"rules"`
+	FallbackURL *string                `json:"fallback_url,omitempty" db:"fallback_url"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// CampaignTargetingRules restrict the traffic of a campaign. Empty lists do not restrict.
+type CampaignTargetingRules struct {
+	Countries         []string `json:"countries,omitempty"`          // ISO 3166-1 alpha-2 codes
+	ExcludedCountries []string `json:"excluded_countries,omitempty"` // ISO 3166-1 alpha-2 codes
+	Regions           []string `json:"regions,omitempty"`            // ISO 3166-2 codes such as US-CA
+	DeviceTypes       []string `json:"device_types,omitempty"`       // desktop, mobile, tablet
+	Browsers          []string `json:"browsers,omitempty"`           // chrome, safari, firefox, edge, opera, samsung, ie, other
+	OperatingSystems  []string `json:"operating_systems,omitempty"`  // windows, macos, ios, android, linux, chromeos, other
+	ISPs              []string `json:"isps,omitempty"`               // ISP or network operator names, matched case-insensitively
+	AllowedIPs        []string `json:"allowed_ips,omitempty"`        // IP addresses or CIDR ranges; when set, only they match
+	BlockedIPs        []string `json:"blocked_ips,omitempty"`        // IP addresses or CIDR ranges
+	BlockProxy        bool     `json:"block_proxy"`
+	// DayParts are the hours traffic is accepted in, in Timezone (UTC when empty)
+	DayParts []SmartLinkDayPart `json:"day_parts,omitempty"`
+	Timezone string             `json:"timezone,omitempty"`
+}
+
+// IsEmpty reports whether the rules accept all traffic
+func (r *CampaignTargetingRules) IsEmpty() bool {
+	return len(r.Countries) == 0 && len(r.ExcludedCountries) == 0 && len(r.Regions) == 0 &&
+		len(r.DeviceTypes) == 0 && len(r.Browsers) == 0 && len(r.OperatingSystems) == 0 &&
+		len(r.ISPs) == 0 && len(r.AllowedIPs) == 0 && len(r.BlockedIPs) == 0 && !r.BlockProxy &&
+		len(r.DayParts) == 0
+}
+
+// Normalize validates the rules and normalizes codes, names and IP ranges
+func (r *CampaignTargetingRules) Normalize() error {
+	for name, values := range map[string][]string{
+		"countries": r.Countries, "excluded_countries": r.ExcludedCountries, "regions": r.Regions,
+		"browsers": r.Browsers, "operating_systems": r.OperatingSystems, "isps": r.ISPs,
+		"allowed_ips": r.AllowedIPs, "blocked_ips": r.BlockedIPs,
+	} {
+		if len(values) > maxTargetingRuleItems {
+			return fmt.Errorf("%s cannot have more than %d items", name, maxTargetingRuleItems)
+		}
+	}
+
+	// Countries, devices and day parts follow the smart link rules
+	shared := SmartLinkRules{
+		Countries:         r.Countries,
+		ExcludedCountries: r.ExcludedCountries,
+		Devices:           r.DeviceTypes,
+		DayParts:          r.DayParts,
+		Timezone:          r.Timezone,
+	}
+	if err := shared.Normalize(); err != nil {
+		return err
+	}
+
+	for i, region := range r.Regions {
+		region = strings.ToUpper(strings.TrimSpace(region))
+		country, subdivision, ok := strings.Cut(region, "-")
+		if !ok || len(country) != 2 || len(subdivision) < 1 || len(subdivision) > 3 {
+			return fmt.Errorf("region %q must be an ISO 3166-2 code such as US-CA", region)
+		}
+		r.Regions[i] = region
+	}
+	if err := normalizeTargetingNames(r.Browsers, targetingBrowsers, "browser"); err != nil {
+		return err
+	}
+	if err := normalizeTargetingNames(r.OperatingSystems, targetingOperatingSystems, "operating system"); err != nil {
+		return err
+	}
+	for i, isp := range r.ISPs {
+		if r.ISPs[i] = strings.TrimSpace(isp); r.ISPs[i] == "" {
+			return fmt.Errorf("isps cannot contain empty names")
+		}
+	}
+	if err := normalizeIPRanges(r.AllowedIPs); err != nil {
+		return err
+	}
+	return normalizeIPRanges(r.BlockedIPs)
+}
+
+func normalizeTargetingNames(values, allowed []string, kind string) error {
+	for i, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !containsString(allowed, value) {
+			return fmt.Errorf("%s %q must be one of %s", kind, value, strings.Join(allowed, ", "))
+		}
+		values[i] = value
+	}
+	return nil
+}
+
+// normalizeIPRanges turns single addresses into host ranges so that evaluation only parses CIDRs
+func normalizeIPRanges(ranges []string) error {
+	for i, value := range ranges {
+		value = strings.TrimSpace(value)
+		if ip := net.ParseIP(value); ip != nil {
+			if ip4 := ip.To4(); ip4 != nil {
+				ranges[i] = ip4.String() + "/32"
+			} else {
+				ranges[i] = ip.String() + "/128"
+			}
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return fmt.Errorf("%q is neither an IP address nor a CIDR range", value)
+		}
+		ranges[i] = network.String()
+	}
+	return nil
+}
+
+// SetCampaignTargetingRequest replaces the targeting ruleset of a campaign
+type SetCampaignTargetingRequest struct {
+	Rules       CampaignTargetingRules `json:"rules"`
+	FallbackURL *string                `json:"fallback_url"` // Where non-matching traffic goes; unmatched clicks are refused without it
+}
+
+// Validate validates and normalizes the request
+func (r *SetCampaignTargetingRequest) Validate() error {
+	if err := validateSmartLinkFallbackURL(r.FallbackURL); err != nil {
+		return err
+	}
+	return r.Rules.Normalize()
+}
+
+// GeoLocation is what a GeoIP database knows about an IP address
+type GeoLocation struct {
+	Country string // ISO 3166-1 alpha-2 code
+	Region  string // ISO 3166-2 code such as US-CA
+	ISP     string
+	IsProxy bool
+}
+
+// TargetingVisitor describes a click for targeting evaluation. Unknown attributes are left empty;
+// a rule that lists values does not match a visitor whose attribute is unknown.
+type TargetingVisitor struct {
+	IP        net.IP
+	UserAgent string
+	Location  GeoLocation
+	Time      time.Time
+}
+
+// UserAgentInfo is the device, browser and operating system parsed from a user agent
+type UserAgentInfo struct {
+	Device  string
+	Browser string
+	OS      string
+}
+
+// ParseUserAgent classifies a user agent; all fields are empty for an empty user agent
+func ParseUserAgent(userAgent string) UserAgentInfo {
+	if userAgent == "" {
+		return UserAgentInfo{}
+	}
+	ua := strings.ToLower(userAgent)
+	info := UserAgentInfo{Device: ClassifyDevice(userAgent), Browser: BrowserOther, OS: OSOther}
+
+	// Order matters: most browsers also announce Chrome and Safari, and iOS announces Mac OS X
+	switch {
+	case strings.Contains(ua, "edg/") || strings.Contains(ua, "edge/") || strings.Contains(ua, "edga/") || strings.Contains(ua, "edgios/"):
+		info.Browser = BrowserEdge
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		info.Browser = BrowserOpera
+	case strings.Contains(ua, "samsungbrowser/"):
+		info.Browser = BrowserSamsung
+	case strings.Contains(ua, "firefox/") || strings.Contains(ua, "fxios/"):
+		info.Browser = BrowserFirefox
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		info.Browser = BrowserChrome
+	case strings.Contains(ua, "msie ") || strings.Contains(ua, "trident/"):
+		info.Browser = BrowserIE
+	case strings.Contains(ua, "safari/") || (strings.Contains(ua, "applewebkit/") && strings.Contains(ua, "mobile/")):
+		info.Browser = BrowserSafari
+	}
+
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ipod"):
+		info.OS = OSIOS
+	case strings.Contains(ua, "android"):
+		info.OS = OSAndroid
+	case strings.Contains(ua, "cros "):
+		info.OS = OSChromeOS
+	case strings.Contains(ua, "windows"):
+		info.OS = OSWindows
+	case strings.Contains(ua, "macintosh") || strings.Contains(ua, "mac os x"):
+		info.OS = OSMacOS
+	case strings.Contains(ua, "linux"):
+		info.OS = OSLinux
+	}
+	return info
+}
+
+// Evaluate returns the first rule the visitor does not match, or "" when the visitor matches
+func (r *CampaignTargetingRules) Evaluate(visitor TargetingVisitor) string {
+	location := visitor.Location
+	country := strings.ToUpper(location.Country)
+	if len(r.Countries) > 0 && !containsString(r.Countries, country) {
+		return TargetingRuleCountry
+	}
+	if country != "" && containsString(r.ExcludedCountries, country) {
+		return TargetingRuleCountry
+	}
+	if len(r.Regions) > 0 && !containsString(r.Regions, strings.ToUpper(location.Region)) {
+		return TargetingRuleRegion
+	}
+
+	agent := ParseUserAgent(visitor.UserAgent)
+	if len(r.DeviceTypes) > 0 && !containsString(r.DeviceTypes, agent.Device) {
+		return TargetingRuleDevice
+	}
+	if len(r.Browsers) > 0 && !containsString(r.Browsers, agent.Browser) {
+		return TargetingRuleBrowser
+	}
+	if len(r.OperatingSystems) > 0 && !containsString(r.OperatingSystems, agent.OS) {
+		return TargetingRuleOS
+	}
+
+	if len(r.ISPs) > 0 && !r.matchesISP(location.ISP) {
+		return TargetingRuleISP
+	}
+	if len(r.AllowedIPs) > 0 && !ipInRanges(visitor.IP, r.AllowedIPs) {
+		return TargetingRuleIP
+	}
+	if ipInRanges(visitor.IP, r.BlockedIPs) {
+		return TargetingRuleIP
+	}
+	if r.BlockProxy && location.IsProxy {
+		return TargetingRuleProxy
+	}
+
+	dayParts := SmartLinkRules{DayParts: r.DayParts, Timezone: r.Timezone}
+	if !dayParts.matchesTime(visitor.Time) {
+		return TargetingRuleDayPart
+	}
+	return ""
+}
+
+func (r *CampaignTargetingRules) matchesISP(isp string) bool {
+	if isp == "" {
+		return false
+	}
+	for _, name := range r.ISPs {
+		if strings.EqualFold(name, isp) {
+			return true
+		}
+	}
+	return false
+}
+
+func ipInRanges(ip net.IP, ranges []string) bool {
+	if ip == nil {
+		return false
+	}
+	for _, value := range ranges {
+		if _, network, err := net.ParseCIDR(value); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetingDecision is the outcome of evaluating a click against a campaign ruleset
+type TargetingDecision struct {
+	Matched     bool    `json:"matched"`
+	FailedRule  string  `json:"failed_rule,omitempty"`
+	FallbackURL *string `json:"fallback_url,omitempty"`
+}
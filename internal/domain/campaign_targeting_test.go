@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+const (
+	testChromeWindowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	testSafariIPhoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Version/17.0 Mobile/15E148 Safari/604.1"
+)
+
+func TestCampaignTargetingRules_Evaluate(t *testing.T) {
+	// Monday noon UTC
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	visitor := TargetingVisitor{
+		IP:        net.ParseIP("203.0.113.10"),
+		UserAgent: testChromeWindowsUA,
+		Location:  GeoLocation{Country: "US", Region: "US-CA", ISP: "Comcast Cable"},
+		Time:      now,
+	}
+	tests := []struct {
+		name     string
+		rules    CampaignTargetingRules
+		mutate   func(v *TargetingVisitor)
+		wantRule string
+	}{
+		{name: "no rules", rules: CampaignTargetingRules{}},
+		{name: "all rules match", rules: CampaignTargetingRules{
+			Countries: []string{"US"}, Regions: []string{"US-CA"}, DeviceTypes: []string{DeviceTypeDesktop},
+			Browsers: []string{BrowserChrome}, OperatingSystems: []string{OSWindows}, ISPs: []string{"comcast cable"},
+			AllowedIPs: []string{"203.0.113.0/24"}, BlockProxy: true,
+			DayParts: []SmartLinkDayPart{{Days: []string{"mon"}, StartHour: 9, EndHour: 17}},
+		}},
+		{name: "country", rules: CampaignTargetingRules{Countries: []string{"CA"}}, wantRule: TargetingRuleCountry},
+		{name: "excluded country", rules: CampaignTargetingRules{ExcludedCountries: []string{"US"}}, wantRule: TargetingRuleCountry},
+		{name: "unknown country", rules: CampaignTargetingRules{Countries: []string{"US"}},
+			mutate: func(v *TargetingVisitor) { v.Location.Country = "" }, wantRule: TargetingRuleCountry},
+		{name: "unknown country not excluded", rules: CampaignTargetingRules{ExcludedCountries: []string{"US"}},
+			mutate: func(v *TargetingVisitor) { v.Location.Country = "" }},
+		{name: "region", rules: CampaignTargetingRules{Regions: []string{"US-NY"}}, wantRule: TargetingRuleRegion},
+		{name: "device", rules: CampaignTargetingRules{DeviceTypes: []string{DeviceTypeMobile}}, wantRule: TargetingRuleDevice},
+		{name: "browser", rules: CampaignTargetingRules{Browsers: []string{BrowserSafari}}, wantRule: TargetingRuleBrowser},
+		{name: "mobile safari", rules: CampaignTargetingRules{Browsers: []string{BrowserSafari}, OperatingSystems: []string{OSIOS}},
+			mutate: func(v *TargetingVisitor) { v.UserAgent = testSafariIPhoneUA }},
+		{name: "os", rules: CampaignTargetingRules{OperatingSystems: []string{OSMacOS}}, wantRule: TargetingRuleOS},
+		{name: "isp", rules: CampaignTargetingRules{ISPs: []string{"Verizon"}}, wantRule: TargetingRuleISP},
+		{name: "allowed ips", rules: CampaignTargetingRules{AllowedIPs: []string{"198.51.100.0/24"}}, wantRule: TargetingRuleIP},
+		{name: "blocked ip", rules: CampaignTargetingRules{BlockedIPs: []string{"203.0.113.10/32"}}, wantRule: TargetingRuleIP},
+		{name: "proxy", rules: CampaignTargetingRules{BlockProxy: true},
+			mutate: func(v *TargetingVisitor) { v.Location.IsProxy = true }, wantRule: TargetingRuleProxy},
+		{name: "day part", rules: CampaignTargetingRules{DayParts: []SmartLinkDayPart{{Days: []string{"sat", "sun"}, StartHour: 0, EndHour: 24}}},
+			wantRule: TargetingRuleDayPart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := visitor
+			if tt.mutate != nil {
+				tt.mutate(&v)
+			}
+			if got := tt.rules.Evaluate(v); got != tt.wantRule {
+				t.Errorf("Evaluate() = %q, want %q", got, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestSetCampaignTargetingRequest_Validate(t *testing.T) {
+	fallback := "https://example.com/other-offers"
+	tests := []struct {
+		name    string
+		rules   CampaignTargetingRules
+		wantErr bool
+	}{
+		{name: "valid", rules: CampaignTargetingRules{
+			Countries: []string{" us"}, Regions: []string{"us-ca"}, DeviceTypes: []string{"Mobile"},
+			Browsers: []string{"Chrome"}, OperatingSystems: []string{"iOS"}, AllowedIPs: []string{"10.0.0.1", "10.1.0.0/16"},
+		}},
+		{name: "bad country", rules: CampaignTargetingRules{Countries: []string{"USA"}}, wantErr: true},
+		{name: "bad region", rules: CampaignTargetingRules{Regions: []string{"California"}}, wantErr: true},
+		{name: "bad browser", rules: CampaignTargetingRules{Browsers: []string{"netscape"}}, wantErr: true},
+		{name: "bad os", rules: CampaignTargetingRules{OperatingSystems: []string{"beos"}}, wantErr: true},
+		{name: "bad ip", rules: CampaignTargetingRules{BlockedIPs: []string{"10.0.0.300"}}, wantErr: true},
+		{name: "empty isp", rules: CampaignTargetingRules{ISPs: []string{" "}}, wantErr: true},
+		{name: "bad timezone", rules: CampaignTargetingRules{Timezone: "Mars/Olympus"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := SetCampaignTargetingRequest{Rules: tt.rules, FallbackURL: &fallback}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := SetCampaignTargetingRequest{Rules: tests[0].rules}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	r := req.Rules
+	if r.Countries[0] != "US" || r.Regions[0] != "US-CA" || r.Browsers[0] != BrowserChrome || r.OperatingSystems[0] != OSIOS {
+		t.Errorf("Validate() did not normalize the rules: %+v", r)
+	}
+	if r.AllowedIPs[0] != "10.0.0.1/32" || r.AllowedIPs[1] != "10.1.0.0/16" {
+		t.Errorf("AllowedIPs = %v, want [10.0.0.1/32 10.1.0.0/16]", r.AllowedIPs)
+	}
+
+	relative := "other-offers"
+	if err := (&SetCampaignTargetingRequest{FallbackURL: &relative}).Validate(); err == nil {
+		t.Errorf("Validate() with a relative fallback URL succeeded")
+	}
+}
+
+func TestParseUserAgent(t *testing.T) {
+	tests := map[string]UserAgentInfo{
+		"":                  {},
+		testChromeWindowsUA: {Device: DeviceTypeDesktop, Browser: BrowserChrome, OS: OSWindows},
+		testSafariIPhoneUA:  {Device: DeviceTypeMobile, Browser: BrowserSafari, OS: OSIOS},
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36 Edg/120.0": {
+			Device: DeviceTypeDesktop, Browser: BrowserEdge, OS: OSWindows},
+		"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 SamsungBrowser/23.0 Chrome/115.0 Mobile Safari/537.36": {
+			Device: DeviceTypeMobile, Browser: BrowserSamsung, OS: OSAndroid},
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.0; rv:121.0) Gecko/20100101 Firefox/121.0": {
+			Device: DeviceTypeDesktop, Browser: BrowserFirefox, OS: OSMacOS},
+		"Mozilla/5.0 (X11; CrOS x86_64 15633.69.0) AppleWebKit/537.36 Chrome/119.0 Safari/537.36": {
+			Device: DeviceTypeDesktop, Browser: BrowserChrome, OS: OSChromeOS},
+		"curl/8.4.0": {Device: DeviceTypeDesktop, Browser: BrowserOther, OS: OSOther},
+	}
+	for ua, want := range tests {
+		if got := ParseUserAgent(ua); got != want {
+			t.Errorf("ParseUserAgent(%q) = %+v, want %+v", ua, got, want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Creative types
+const (
+	CreativeTypeImage = "image" // Banner image served from object storage
+	CreativeTypeHTML  = "html"  // HTML banner with the tracking link macro
+	CreativeTypeEmail = "email" // Email body with the tracking link macro, sender and subject
+)
+
+// Creative approval statuses. Only approved creatives are handed to affiliates.
+const (
+	CreativeStatusPending  = "pending"
+	CreativeStatusApproved = "approved"
+	CreativeStatusRejected = "rejected"
+)
+
+const (
+	// CreativeTrackingLinkMacro is replaced by the affiliate's tracking URL in HTML and email creatives
+	CreativeTrackingLinkMacro = "{tracking_link}"
+	// MaxCreativeFileSize is the largest creative image in bytes
+	MaxCreativeFileSize = 5 << 20
+	// MaxCreativeHTMLSize is the largest HTML or email creative body in bytes
+	MaxCreativeHTMLSize = 256 << 10
+	// MaxCreativeDimension is the largest creative width or height in pixels
+	MaxCreativeDimension = 4096
+)
+
+// Creative is a banner, HTML or email creative of a campaign. Image content is kept in object
+// storage; HTML and email creatives hold their markup.
+type Creative struct {
+	CreativeID      int64      `json:"creative_id" db:"creative_id"`
+	OrganizationID  int64      `json:"organization_id" db:"organization_id"`
+	CampaignID      int64      `json:"campaign_id" db:"campaign_id"`
+	Name            string     `json:"name" db:"name"`
+	CreativeType    string     `json:"creative_type" db:"creative_type"`
+	Status          string     `json:"status" db:"status"`
+	RejectionReason *string    `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	IsPrivate       bool       `json:"is_private" db:"is_private"`
+	Width           *int       `json:"width,omitempty" db:"width"`
+	Height          *int       `json:"height,omitempty" db:"height"`
+
+	HTMLCode     *string `json:"html_code,omitempty" db:"html_code"`
+	EmailFrom    *string `json:"email_from,omitempty" db:"email_from"`
+	EmailSubject *string `json:"email_subject,omitempty" db:"email_subject"`
+
+	FileName    *string `json:"file_name,omitempty" db:"file_name"`
+	ContentType *string `json:"content_type,omitempty" db:"content_type"`
+	FileSize    *int    `json:"file_size,omitempty" db:"file_size"`
+	StorageKey  *string `json:"-" db:"storage_key"`
+	FileURL     *string `json:"file_url,omitempty" db:"-"` // Public URL of the image, filled in by the service
+
+	// Provider sync state
+	ProviderCreativeID *string    `json:"provider_creative_id,omitempty" db:"provider_creative_id"`
+	ProviderSyncedAt   *time.Time `json:"provider_synced_at,omitempty" db:"provider_synced_at"`
+	ProviderSyncError  *string    `json:"provider_sync_error,omitempty" db:"provider_sync_error"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsApproved reports whether the creative can be handed to affiliates
+func (c *Creative) IsApproved() bool {
+	return c.Status == CreativeStatusApproved
+}
+
+// Wrap returns the creative markup ready to publish: images are linked to the tracking URL and
+// the tracking link macro of HTML and email creatives is replaced by it
+func (c *Creative) Wrap(trackingURL, fileURL string) string {
+	escapedURL := html.EscapeString(trackingURL)
+	if c.CreativeType != CreativeTypeImage {
+		if c.HTMLCode == nil {
+			return ""
+		}
+		return strings.ReplaceAll(*c.HTMLCode, CreativeTrackingLinkMacro, escapedURL)
+	}
+
+	var size string
+	if c.Width != nil && c.Height != nil {
+		size = fmt.Sprintf(` width="%d" height="%d"`, *c.Width, *c.Height)
+	}
+	return fmt.Sprintf(`<a href="%s" target="_blank" rel="noopener"><img src="%s"%s alt="%s" border="0"></a>`,
+		escapedURL, html.EscapeString(fileURL), size, html.EscapeString(c.Name))
+}
+
+// CreativeFile is an image uploaded with a creative
+type CreativeFile struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// CreateCreativeRequest creates a creative. Image creatives come with a file, whose dimensions
+// are read from the image; HTML creatives need their width and height.
+type CreateCreativeRequest struct {
+	Name         string        `json:"name" form:"name"`
+	CreativeType string        `json:"creative_type" form:"creative_type"`
+	HTMLCode     *string       `json:"html_code,omitempty" form:"html_code"`
+	EmailFrom    *string       `json:"email_from,omitempty" form:"email_from"`
+	EmailSubject *string       `json:"email_subject,omitempty" form:"email_subject"`
+	Width        *int          `json:"width,omitempty" form:"width"`
+	Height       *int          `json:"height,omitempty" form:"height"`
+	IsPrivate    bool          `json:"is_private" form:"is_private"`
+	File         *CreativeFile `json:"-" form:"-"`
+}
+
+// Validate validates and normalizes the request
+func (r *CreateCreativeRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.CreativeType = strings.ToLower(strings.TrimSpace(r.CreativeType))
+	if r.Name == "" || len(r.Name) > 255 {
+		return fmt.Errorf("name is required and must be at most 255 characters")
+	}
+
+	switch r.CreativeType {
+	case CreativeTypeImage:
+		if r.File == nil || len(r.File.Data) == 0 {
+			return fmt.Errorf("image creatives need a file")
+		}
+		if len(r.File.Data) > MaxCreativeFileSize {
+			return fmt.Errorf("the file must be at most %d MB", MaxCreativeFileSize>>20)
+		}
+		if r.HTMLCode != nil {
+			return fmt.Errorf("image creatives cannot have html_code")
+		}
+	case CreativeTypeHTML, CreativeTypeEmail:
+		if r.File != nil {
+			return fmt.Errorf("%s creatives cannot have a file", r.CreativeType)
+		}
+		if err := validateCreativeHTML(r.HTMLCode); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("creative_type must be one of %s, %s, %s", CreativeTypeImage, CreativeTypeHTML, CreativeTypeEmail)
+	}
+
+	if r.CreativeType == CreativeTypeHTML && (r.Width == nil || r.Height == nil) {
+		return fmt.Errorf("html creatives need a width and a height")
+	}
+	if err := validateCreativeDimensions(r.Width, r.Height); err != nil {
+		return err
+	}
+	if r.CreativeType == CreativeTypeEmail {
+		return validateCreativeEmail(r.EmailFrom, r.EmailSubject, true)
+	}
+	return nil
+}
+
+// UpdateCreativeRequest updates a creative; omitted fields are left unchanged. Content changes
+// send an approved creative back to review.
+type UpdateCreativeRequest struct {
+	Name         *string `json:"name,omitempty"`
+	HTMLCode     *string `json:"html_code,omitempty"`
+	EmailFrom    *string `json:"email_from,omitempty"`
+	EmailSubject *string `json:"email_subject,omitempty"`
+	Width        *int    `json:"width,omitempty"`
+	Height       *int    `json:"height,omitempty"`
+	IsPrivate    *bool   `json:"is_private,omitempty"`
+}
+
+// Apply validates the request against the creative and applies it. It reports whether the
+// content affiliates publish changed.
+func (r *UpdateCreativeRequest) Apply(c *Creative) (bool, error) {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if name == "" || len(name) > 255 {
+			return false, fmt.Errorf("name is required and must be at most 255 characters")
+		}
+		c.Name = name
+	}
+	if r.IsPrivate != nil {
+		c.IsPrivate = *r.IsPrivate
+	}
+
+	contentChanged := false
+	if r.HTMLCode != nil {
+		if c.CreativeType == CreativeTypeImage {
+			return false, fmt.Errorf("image creatives cannot have html_code")
+		}
+		if err := validateCreativeHTML(r.HTMLCode); err != nil {
+			return false, err
+		}
+		c.HTMLCode = r.HTMLCode
+		contentChanged = true
+	}
+	if r.Width != nil || r.Height != nil {
+		if c.CreativeType == CreativeTypeImage {
+			return false, fmt.Errorf("the dimensions of image creatives are read from the file")
+		}
+		if r.Width != nil {
+			c.Width = r.Width
+		}
+		if r.Height != nil {
+			c.Height = r.Height
+		}
+		if err := validateCreativeDimensions(c.Width, c.Height); err != nil {
+			return false, err
+		}
+		contentChanged = true
+	}
+	if r.EmailFrom != nil || r.EmailSubject != nil {
+		if c.CreativeType != CreativeTypeEmail {
+			return false, fmt.Errorf("only email creatives have a sender and subject")
+		}
+		if err := validateCreativeEmail(r.EmailFrom, r.EmailSubject, false); err != nil {
+			return false, err
+		}
+		if r.EmailFrom != nil {
+			c.EmailFrom = r.EmailFrom
+		}
+		if r.EmailSubject != nil {
+			c.EmailSubject = r.EmailSubject
+		}
+		contentChanged = true
+	}
+	return contentChanged, nil
+}
+
+// ReviewCreativeRequest approves or rejects a creative
+type ReviewCreativeRequest struct {
+	Status string  `json:"status" binding:"required"` // approved or rejected
+	Reason *string `json:"reason,omitempty"`          // Shown to the uploader when rejected
+}
+
+// Validate validates the request
+func (r *ReviewCreativeRequest) Validate() error {
+	if r.Status != CreativeStatusApproved && r.Status != CreativeStatusRejected {
+		return fmt.Errorf("status must be %s or %s", CreativeStatusApproved, CreativeStatusRejected)
+	}
+	if r.Status == CreativeStatusApproved {
+		r.Reason = nil
+	}
+	return nil
+}
+
+// WrappedCreative is an approved creative wrapped with an affiliate's tracking link
+type WrappedCreative struct {
+	CreativeID     int64   `json:"creative_id"`
+	TrackingLinkID int64   `json:"tracking_link_id"`
+	Name           string  `json:"name"`
+	CreativeType   string  `json:"creative_type"`
+	Width          *int    `json:"width,omitempty"`
+	Height         *int    `json:"height,omitempty"`
+	EmailFrom      *string `json:"email_from,omitempty"`
+	EmailSubject   *string `json:"email_subject,omitempty"`
+	FileURL        *string `json:"file_url,omitempty"`
+	TrackingURL    string  `json:"tracking_url"`
+	Code           string  `json:"code"` // Markup to publish
+}
+
+func validateCreativeHTML(code *string) error {
+	if code == nil || strings.TrimSpace(*code) == "" {
+		return fmt.Errorf("html_code is required")
+	}
+	if len(*code) > MaxCreativeHTMLSize {
+		return fmt.Errorf("html_code must be at most %d KB", MaxCreativeHTMLSize>>10)
+	}
+	if !strings.Contains(*code, CreativeTrackingLinkMacro) {
+		return fmt.Errorf("html_code must contain the %s macro", CreativeTrackingLinkMacro)
+	}
+	return nil
+}
+
+func validateCreativeDimensions(width, height *int) error {
+	for _, v := range []*int{width, height} {
+		if v != nil && (*v < 1 || *v > MaxCreativeDimension) {
+			return fmt.Errorf("width and height must be between 1 and %d", MaxCreativeDimension)
+		}
+	}
+	return nil
+}
+
+func validateCreativeEmail(from, subject *string, required bool) error {
+	if from != nil || required {
+		if from == nil {
+			return fmt.Errorf("email creatives need email_from")
+		}
+		if _, err := mail.ParseAddress(*from); err != nil {
+			return fmt.Errorf("email_from must be a valid address")
+		}
+	}
+	if subject != nil || required {
+		if subject == nil || strings.TrimSpace(*subject) == "" || len(*subject) > 255 {
+			return fmt.Errorf("email creatives need an email_subject of at most 255 characters")
+		}
+	}
+	return nil
+}
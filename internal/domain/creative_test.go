@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateCreativeRequest_Validate(t *testing.T) {
+	html := `<a href="{tracking_link}">Shop now</a>`
+	noMacro := `<a href="https://example.com">Shop now</a>`
+	from := "Deals <deals@example.com>"
+	subject := "Summer sale"
+	badFrom := "not an address"
+	width, height, tooWide := 300, 250, MaxCreativeDimension+1
+	image := &CreativeFile{FileName: "banner.png", ContentType: "image/png", Data: []byte{1, 2, 3}}
+
+	tests := []struct {
+		name    string
+		req     CreateCreativeRequest
+		wantErr bool
+	}{
+		{name: "image", req: CreateCreativeRequest{Name: "Banner", CreativeType: "Image", File: image}},
+		{name: "image without file", req: CreateCreativeRequest{Name: "Banner", CreativeType: CreativeTypeImage}, wantErr: true},
+		{name: "image with html", req: CreateCreativeRequest{Name: "Banner", CreativeType: CreativeTypeImage, File: image, HTMLCode: &html}, wantErr: true},
+		{name: "image too large", req: CreateCreativeRequest{Name: "Banner", CreativeType: CreativeTypeImage,
+			File: &CreativeFile{Data: make([]byte, MaxCreativeFileSize+1)}}, wantErr: true},
+		{name: "html", req: CreateCreativeRequest{Name: "HTML", CreativeType: CreativeTypeHTML, HTMLCode: &html, Width: &width, Height: &height}},
+		{name: "html without size", req: CreateCreativeRequest{Name: "HTML", CreativeType: CreativeTypeHTML, HTMLCode: &html}, wantErr: true},
+		{name: "html too wide", req: CreateCreativeRequest{Name: "HTML", CreativeType: CreativeTypeHTML, HTMLCode: &html, Width: &tooWide, Height: &height}, wantErr: true},
+		{name: "html without macro", req: CreateCreativeRequest{Name: "HTML", CreativeType: CreativeTypeHTML, HTMLCode: &noMacro, Width: &width, Height: &height}, wantErr: true},
+		{name: "html with file", req: CreateCreativeRequest{Name: "HTML", CreativeType: CreativeTypeHTML, HTMLCode: &html, Width: &width, Height: &height, File: image}, wantErr: true},
+		{name: "email", req: CreateCreativeRequest{Name: "Email", CreativeType: CreativeTypeEmail, HTMLCode: &html, EmailFrom: &from, EmailSubject: &subject}},
+		{name: "email without subject", req: CreateCreativeRequest{Name: "Email", CreativeType: CreativeTypeEmail, HTMLCode: &html, EmailFrom: &from}, wantErr: true},
+		{name: "email bad sender", req: CreateCreativeRequest{Name: "Email", CreativeType: CreativeTypeEmail, HTMLCode: &html, EmailFrom: &badFrom, EmailSubject: &subject}, wantErr: true},
+		{name: "missing name", req: CreateCreativeRequest{Name: "  ", CreativeType: CreativeTypeImage, File: image}, wantErr: true},
+		{name: "unknown type", req: CreateCreativeRequest{Name: "Video", CreativeType: "video"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateCreativeRequest_Apply(t *testing.T) {
+	html := `<a href="{tracking_link}">Old</a>`
+	newHTML := `<a href="{tracking_link}">New</a>`
+	name := "Renamed"
+	width := 728
+	subject := "New subject"
+
+	creative := &Creative{Name: "HTML", CreativeType: CreativeTypeHTML, HTMLCode: &html}
+	changed, err := (&UpdateCreativeRequest{Name: &name}).Apply(creative)
+	if err != nil || changed || creative.Name != name {
+		t.Errorf("rename: changed = %v, err = %v, name = %q", changed, err, creative.Name)
+	}
+	changed, err = (&UpdateCreativeRequest{HTMLCode: &newHTML, Width: &width}).Apply(creative)
+	if err != nil || !changed || *creative.HTMLCode != newHTML || *creative.Width != width {
+		t.Errorf("content: changed = %v, err = %v", changed, err)
+	}
+	if _, err := (&UpdateCreativeRequest{EmailSubject: &subject}).Apply(creative); err == nil {
+		t.Error("expected an error setting an email subject on an html creative")
+	}
+
+	image := &Creative{Name: "Banner", CreativeType: CreativeTypeImage}
+	if _, err := (&UpdateCreativeRequest{Width: &width}).Apply(image); err == nil {
+		t.Error("expected an error resizing an image creative")
+	}
+}
+
+func TestReviewCreativeRequest_Validate(t *testing.T) {
+	reason := "Misleading claims"
+	approve := ReviewCreativeRequest{Status: CreativeStatusApproved, Reason: &reason}
+	if err := approve.Validate(); err != nil || approve.Reason != nil {
+		t.Errorf("approve: err = %v, reason = %v", err, approve.Reason)
+	}
+	reject := ReviewCreativeRequest{Status: CreativeStatusRejected, Reason: &reason}
+	if err := reject.Validate(); err != nil || reject.Reason == nil {
+		t.Errorf("reject: err = %v", err)
+	}
+	pending := ReviewCreativeRequest{Status: CreativeStatusPending}
+	if err := pending.Validate(); err == nil {
+		t.Error("expected an error reviewing back to pending")
+	}
+}
+
+func TestCreative_Wrap(t *testing.T) {
+	trackingURL := "https://track.example.com/c/42?aff=1&sub1=x"
+	width, height := 300, 250
+
+	image := &Creative{Name: `Summer "Sale"`, CreativeType: CreativeTypeImage, Width: &width, Height: &height}
+	got := image.Wrap(trackingURL, "https://api.example.com/creatives/7/file")
+	want := `<a href="https://track.example.com/c/42?aff=1&amp;sub1=x" target="_blank" rel="noopener">` +
+		`<img src="https://api.example.com/creatives/7/file" width="300" height="250" alt="Summer &#34;Sale&#34;" border="0"></a>`
+	if got != want {
+		t.Errorf("Wrap(image) = %s, want %s", got, want)
+	}
+
+	code := `<a href="{tracking_link}">Shop</a> <a href="{tracking_link}">Now</a>`
+	email := &Creative{CreativeType: CreativeTypeEmail, HTMLCode: &code}
+	got = email.Wrap(trackingURL, "")
+	if strings.Contains(got, CreativeTrackingLinkMacro) || strings.Count(got, "sub1=x") != 2 {
+		t.Errorf("Wrap(email) = %s", got)
+	}
+}
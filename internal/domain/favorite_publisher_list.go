@@ -37,11 +37,23 @@ type FavoritePublisherListItem struct {
 	ListID          int64     `json:"list_id" db:"list_id"`
 	PublisherDomain string    `json:"publisher_domain" db:"publisher_domain"`
 	Notes           *string   `json:"notes,omitempty" db:"notes"`
-	Status          string    `json:"status" db:"status"`
+	Status          string    `json:"status" db:"status"` // Legacy status, kept in sync with the pipeline stage
 	AddedAt         time.Time `json:"added_at" db:"added_at"`
 
+	// Pipeline fields
+	StageID        *int64     `json:"stage_id,omitempty" db:"stage_id"`
+	StageChangedAt *time.Time `json:"stage_changed_at,omitempty" db:"stage_changed_at"`
+	OwnerUserID    *string    `json:"owner_user_id,omitempty" db:"owner_user_id"`
+	NextActionAt   *time.Time `json:"next_action_at,omitempty" db:"next_action_at"`
+	NextActionNote *string    `json:"next_action_note,omitempty" db:"next_action_note"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at,omitempty" db:"reminder_sent_at"`
+
+	// Optional: Active conversation with the publisher started from this list, set on pipeline boards
+	ConversationID *int64 `json:"conversation_id,omitempty" db:"-"`
 	// Optional: Include publisher details when fetching with details
 	Publisher *AnalyticsPublisher `json:"publisher,omitempty" db:"-"`
+	// Optional: What changed between the two latest snapshots of the publisher
+	RecentChange *PublisherSnapshotDiff `json:"recent_change,omitempty" db:"-"`
 }
 
 // CreateFavoritePublisherListRequest represents the request to create a new favorite list
@@ -61,6 +73,7 @@ type AddPublisherToListRequest struct {
 	PublisherDomain string  `json:"publisher_domain" binding:"required,min=1,max=255"`
 	Notes           *string `json:"notes,omitempty" binding:"omitempty,max=1000"`
 	Status          *string `json:"status,omitempty" binding:"omitempty,oneof=added contacted accepted"`
+	StageID         *int64  `json:"stage_id,omitempty"` // Pipeline stage; takes precedence over status
 }
 
 // UpdatePublisherInListRequest represents the request to update a publisher's notes in a list
@@ -122,6 +135,20 @@ func ValidateStatusTransition(currentStatus, newStatus string) error {
 	return ErrInvalidInput
 }
 
+// StatusAfterPublisherReply returns the status a list item moves to when the publisher replies
+// to outreach: an added publisher has now been contacted, and a contacted publisher that
+// answers is treated as accepted. It returns false when the status does not change.
+func StatusAfterPublisherReply(currentStatus string) (string, bool) {
+	switch currentStatus {
+	case PublisherStatusAdded:
+		return PublisherStatusContacted, true
+	case PublisherStatusContacted:
+		return PublisherStatusAccepted, true
+	default:
+		return currentStatus, false
+	}
+}
+
 // Validation methods
 
 // Validate validates the CreateFavoritePublisherListRequest
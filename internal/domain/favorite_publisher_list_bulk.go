@@ -0,0 +1,368 @@
+package domain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// MaxFavoriteListImportRows is the maximum number of rows in one list import
+	MaxFavoriteListImportRows = 10000
+	// MaxFavoriteListImportErrors limits how many row errors an import reports
+	MaxFavoriteListImportErrors = 1000
+	// MaxFavoriteListBulkDomains is the maximum number of publishers in one bulk operation
+	MaxFavoriteListBulkDomains = 1000
+)
+
+// How list imports and copies treat publishers that are already in the target list
+const (
+	FavoriteListDuplicateSkip   = "skip"   // Keep the existing item unchanged
+	FavoriteListDuplicateUpdate = "update" // Replace the notes and move the existing item to the row's status or stage
+)
+
+// Bulk list item actions
+const (
+	FavoriteListBulkActionStatus = "status"
+	FavoriteListBulkActionStage  = "stage"
+	FavoriteListBulkActionRemove = "remove"
+)
+
+// FavoriteListImportRow is one publisher of a CSV or JSON list import
+type FavoriteListImportRow struct {
+	Row             int     `json:"-"` // 1-based data row; the CSV header is not counted
+	PublisherDomain string  `json:"publisher_domain"`
+	Notes           *string `json:"notes,omitempty"`
+	Status          *string `json:"status,omitempty"`
+	Stage           *string `json:"stage,omitempty"` // Pipeline stage key or name
+}
+
+// FavoriteListImportOptions controls a list import
+type FavoriteListImportOptions struct {
+	OnDuplicate  string // skip or update
+	AllowUnknown bool   // Accept domains that are not in analytics_publishers
+}
+
+// FavoriteListImportError describes a rejected import row
+type FavoriteListImportError struct {
+	Row             int    `json:"row"`
+	PublisherDomain string `json:"publisher_domain,omitempty"`
+	Message         string `json:"message"`
+}
+
+// FavoriteListImportResult summarizes a list import
+type FavoriteListImportResult struct {
+	Total   int                        `json:"total"`
+	Added   int                        `json:"added"`
+	Updated int                        `json:"updated"`
+	Skipped int                        `json:"skipped"` // Already in the list, or repeated in the file
+	Failed  int                        `json:"failed"`
+	Errors  []*FavoriteListImportError `json:"errors"` // At most MaxFavoriteListImportErrors
+}
+
+// AddError records a rejected row
+func (r *FavoriteListImportResult) AddError(row int, publisherDomain, message string) {
+	r.Failed++
+	if len(r.Errors) < MaxFavoriteListImportErrors {
+		r.Errors = append(r.Errors, &FavoriteListImportError{Row: row, PublisherDomain: publisherDomain, Message: message})
+	}
+}
+
+// BulkFavoriteListItemsRequest applies one action to several publishers of a list
+type BulkFavoriteListItemsRequest struct {
+	Action           string   `json:"action" binding:"required,oneof=status stage remove"`
+	PublisherDomains []string `json:"publisher_domains" binding:"required,min=1"`
+	Status           *string  `json:"status,omitempty" binding:"omitempty,oneof=added contacted accepted"` // For the status action
+	StageID          *int64   `json:"stage_id,omitempty"`                                                  // For the stage action
+	Note             *string  `json:"note,omitempty" binding:"omitempty,max=1000"`                         // Recorded in the stage history
+}
+
+// Validate validates the BulkFavoriteListItemsRequest
+func (r *BulkFavoriteListItemsRequest) Validate() error {
+	if len(r.PublisherDomains) == 0 {
+		return fmt.Errorf("publisher_domains is required")
+	}
+	if len(r.PublisherDomains) > MaxFavoriteListBulkDomains {
+		return fmt.Errorf("at most %d publisher_domains are allowed", MaxFavoriteListBulkDomains)
+	}
+	switch r.Action {
+	case FavoriteListBulkActionStatus:
+		if r.Status == nil {
+			return fmt.Errorf("status is required for the status action")
+		}
+		if err := validateStatus(*r.Status); err != nil {
+			return fmt.Errorf("invalid status: %s", *r.Status)
+		}
+	case FavoriteListBulkActionStage:
+		if r.StageID == nil {
+			return fmt.Errorf("stage_id is required for the stage action")
+		}
+	case FavoriteListBulkActionRemove:
+	default:
+		return fmt.Errorf("invalid action: %s (must be status, stage or remove)", r.Action)
+	}
+	return validateOptionalStringLength(r.Note, 1000)
+}
+
+// FavoriteListBulkResult summarizes a bulk operation
+type FavoriteListBulkResult struct {
+	Matched  int      `json:"matched"`   // Requested publishers that are in the list
+	Changed  int      `json:"changed"`   // Publishers actually moved or removed
+	NotFound []string `json:"not_found"` // Requested publishers that are not in the list
+}
+
+// CopyFavoriteListItemsRequest copies publishers into another list, or into a new list
+type CopyFavoriteListItemsRequest struct {
+	TargetListID     *int64   `json:"target_list_id,omitempty"`
+	NewListName      *string  `json:"new_list_name,omitempty" binding:"omitempty,min=1,max=255"`
+	PublisherDomains []string `json:"publisher_domains,omitempty"` // Defaults to every publisher of the list
+	OnDuplicate      string   `json:"on_duplicate,omitempty" binding:"omitempty,oneof=skip update"`
+}
+
+// Validate validates the CopyFavoriteListItemsRequest
+func (r *CopyFavoriteListItemsRequest) Validate() error {
+	if (r.TargetListID == nil) == (r.NewListName == nil) {
+		return fmt.Errorf("exactly one of target_list_id and new_list_name is required")
+	}
+	if r.NewListName != nil {
+		if err := validateStringLength(strings.TrimSpace(*r.NewListName), 1, 255); err != nil {
+			return fmt.Errorf("new_list_name must be 1-255 characters")
+		}
+	}
+	if len(r.PublisherDomains) > MaxFavoriteListBulkDomains {
+		return fmt.Errorf("at most %d publisher_domains are allowed", MaxFavoriteListBulkDomains)
+	}
+	return validateDuplicateMode(r.OnDuplicate)
+}
+
+// MergeFavoriteListRequest moves every publisher of a list into another list and deletes it
+type MergeFavoriteListRequest struct {
+	TargetListID int64  `json:"target_list_id" binding:"required"`
+	OnDuplicate  string `json:"on_duplicate,omitempty" binding:"omitempty,oneof=skip update"`
+}
+
+// Validate validates the MergeFavoriteListRequest
+func (r *MergeFavoriteListRequest) Validate() error {
+	if r.TargetListID <= 0 {
+		return fmt.Errorf("target_list_id is required")
+	}
+	return validateDuplicateMode(r.OnDuplicate)
+}
+
+// FavoriteListCopyResult summarizes a copy or merge
+type FavoriteListCopyResult struct {
+	TargetList *FavoritePublisherList `json:"target_list"`
+	Added      int                    `json:"added"`
+	Updated    int                    `json:"updated"`
+	Skipped    int                    `json:"skipped"`
+}
+
+func validateDuplicateMode(mode string) error {
+	switch mode {
+	case "", FavoriteListDuplicateSkip, FavoriteListDuplicateUpdate:
+		return nil
+	default:
+		return fmt.Errorf("invalid on_duplicate: %s (must be skip or update)", mode)
+	}
+}
+
+// NormalizePublisherDomain reduces user input like "https://www.Example.com/blog" to "example.com"
+func NormalizePublisherDomain(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.Index(value, "://"); i >= 0 {
+		value = value[i+3:]
+	}
+	if i := strings.IndexAny(value, "/?#:"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimPrefix(value, "www.")
+	return strings.TrimSuffix(value, ".")
+}
+
+// Normalize cleans up the row and validates it
+func (r *FavoriteListImportRow) Normalize() error {
+	r.PublisherDomain = NormalizePublisherDomain(r.PublisherDomain)
+	if r.PublisherDomain == "" {
+		return fmt.Errorf("publisher_domain is required")
+	}
+	if len(r.PublisherDomain) > 255 || !strings.Contains(r.PublisherDomain, ".") || strings.ContainsAny(r.PublisherDomain, " \t") {
+		return fmt.Errorf("invalid publisher_domain")
+	}
+
+	r.Notes = trimmedOrNil(r.Notes)
+	if err := validateOptionalStringLength(r.Notes, 1000); err != nil {
+		return fmt.Errorf("notes must be at most 1000 characters")
+	}
+	if r.Status = trimmedOrNil(r.Status); r.Status != nil {
+		status := strings.ToLower(*r.Status)
+		if err := validateStatus(status); err != nil {
+			return fmt.Errorf("invalid status: %s (must be added, contacted or accepted)", *r.Status)
+		}
+		r.Status = &status
+	}
+	r.Stage = trimmedOrNil(r.Stage)
+	return nil
+}
+
+// FavoriteListImportMove moves publishers already in a list into the stage their import rows set
+type FavoriteListImportMove struct {
+	Stage            *PipelineStage
+	PublisherDomains []string
+}
+
+// PlanFavoriteListImportMoves groups the rows that set a status or stage for publishers already in
+// the list by target stage, in row order. Rows whose status matches no stage of the pipeline are
+// returned as unmoved. Rows must be normalized and their stages known.
+func PlanFavoriteListImportMoves(stages []*PipelineStage, rows []*FavoriteListImportRow, existing map[string]bool) ([]*FavoriteListImportMove, []*FavoriteListImportRow) {
+	moves := make([]*FavoriteListImportMove, 0)
+	byStage := make(map[int64]*FavoriteListImportMove)
+	var unmoved []*FavoriteListImportRow
+	for _, row := range rows {
+		if !existing[row.PublisherDomain] || (row.Stage == nil && row.Status == nil) {
+			continue
+		}
+
+		var stage *PipelineStage
+		if row.Stage != nil {
+			stage = FindPipelineStageByName(stages, *row.Stage)
+		} else {
+			stage = StageForLegacyStatus(stages, *row.Status)
+		}
+		if stage == nil {
+			unmoved = append(unmoved, row)
+			continue
+		}
+
+		move, ok := byStage[stage.StageID]
+		if !ok {
+			move = &FavoriteListImportMove{Stage: stage}
+			byStage[stage.StageID] = move
+			moves = append(moves, move)
+		}
+		move.PublisherDomains = append(move.PublisherDomains, row.PublisherDomain)
+	}
+	return moves, unmoved
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// favoriteListImportColumns maps accepted CSV header names to row fields
+var favoriteListImportColumns = map[string]string{
+	"publisher_domain": "publisher_domain",
+	"domain":           "publisher_domain",
+	"notes":            "notes",
+	"note":             "notes",
+	"status":           "status",
+	"stage":            "stage",
+}
+
+// ParseFavoriteListImportCSV reads list import rows from CSV with a header row. The
+// publisher_domain (or domain) column is required; notes, status and stage are optional.
+// Rows are not validated; call Normalize on each.
+func ParseFavoriteListImportCSV(r io.Reader) ([]*FavoriteListImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("the file is empty")
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := favoriteListImportColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["publisher_domain"]; !ok {
+		return nil, fmt.Errorf("the CSV header must contain a publisher_domain or domain column")
+	}
+
+	cell := func(record []string, field string) *string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return nil
+		}
+		value := record[i]
+		return &value
+	}
+
+	rows := make([]*FavoriteListImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if len(rows) == MaxFavoriteListImportRows {
+			return nil, fmt.Errorf("at most %d rows can be imported at once", MaxFavoriteListImportRows)
+		}
+
+		row := &FavoriteListImportRow{
+			Row:    len(rows) + 1,
+			Notes:  cell(record, "notes"),
+			Status: cell(record, "status"),
+			Stage:  cell(record, "stage"),
+		}
+		if domain := cell(record, "publisher_domain"); domain != nil {
+			row.PublisherDomain = *domain
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ParseFavoriteListImportJSON reads list import rows from a JSON array of objects with
+// publisher_domain, notes, status and stage fields, or from an object holding that array
+// under "publishers". Rows are not validated; call Normalize on each.
+func ParseFavoriteListImportJSON(data []byte) ([]*FavoriteListImportRow, error) {
+	var rows []*FavoriteListImportRow
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		var wrapper struct {
+			Publishers []*FavoriteListImportRow `json:"publishers"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		rows = wrapper.Publishers
+	} else if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no publishers to import")
+	}
+	if len(rows) > MaxFavoriteListImportRows {
+		return nil, fmt.Errorf("at most %d rows can be imported at once", MaxFavoriteListImportRows)
+	}
+	for i, row := range rows {
+		if row == nil {
+			return nil, fmt.Errorf("row %d is null", i+1)
+		}
+		row.Row = i + 1
+	}
+	return rows, nil
+}
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizePublisherDomain(t *testing.T) {
+	tests := map[string]string{
+		"example.com":                      "example.com",
+		"  Example.COM ":                   "example.com",
+		"https://www.example.com/blog?x=1": "example.com",
+		"http://shop.example.com:8080":     "shop.example.com",
+		"www.example.com.":                 "example.com",
+		"":                                 "",
+	}
+	for input, want := range tests {
+		if got := NormalizePublisherDomain(input); got != want {
+			t.Errorf("NormalizePublisherDomain(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseFavoriteListImportCSV(t *testing.T) {
+	input := "\ufeffDomain,Notes,Status,Stage\n" +
+		"https://www.example.com,Great fit,contacted,\n" +
+		"other.com,,,Negotiating\n" +
+		"short.com\n"
+
+	rows, err := ParseFavoriteListImportCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseFavoriteListImportCSV() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("ParseFavoriteListImportCSV() returned %d rows, want 3", len(rows))
+	}
+
+	for _, row := range rows {
+		if err := row.Normalize(); err != nil {
+			t.Errorf("row %d: Normalize() error = %v", row.Row, err)
+		}
+	}
+	if rows[0].PublisherDomain != "example.com" || rows[0].Notes == nil || *rows[0].Notes != "Great fit" ||
+		rows[0].Status == nil || *rows[0].Status != PublisherStatusContacted || rows[0].Stage != nil {
+		t.Errorf("row 1 = %+v", rows[0])
+	}
+	if rows[1].Notes != nil || rows[1].Status != nil || rows[1].Stage == nil || *rows[1].Stage != "Negotiating" {
+		t.Errorf("row 2 = %+v", rows[1])
+	}
+	if rows[2].Row != 3 || rows[2].PublisherDomain != "short.com" {
+		t.Errorf("row 3 = %+v", rows[2])
+	}
+
+	if _, err := ParseFavoriteListImportCSV(strings.NewReader("name,notes\nexample.com,x\n")); err == nil {
+		t.Error("ParseFavoriteListImportCSV() without a domain column should fail")
+	}
+	if _, err := ParseFavoriteListImportCSV(strings.NewReader("")); err == nil {
+		t.Error("ParseFavoriteListImportCSV() of an empty file should fail")
+	}
+}
+
+func TestParseFavoriteListImportJSON(t *testing.T) {
+	for _, input := range []string{
+		`[{"publisher_domain": "example.com", "status": "accepted"}, {"publisher_domain": "other.com"}]`,
+		`{"publishers": [{"publisher_domain": "example.com", "status": "accepted"}, {"publisher_domain": "other.com"}]}`,
+	} {
+		rows, err := ParseFavoriteListImportJSON([]byte(input))
+		if err != nil {
+			t.Fatalf("ParseFavoriteListImportJSON(%s) error = %v", input, err)
+		}
+		if len(rows) != 2 || rows[1].Row != 2 || rows[0].Status == nil || *rows[0].Status != PublisherStatusAccepted {
+			t.Errorf("ParseFavoriteListImportJSON(%s) = %+v", input, rows)
+		}
+	}
+
+	for _, input := range []string{`[]`, `{"publishers": []}`, `[null]`, `not json`} {
+		if _, err := ParseFavoriteListImportJSON([]byte(input)); err == nil {
+			t.Errorf("ParseFavoriteListImportJSON(%s) should fail", input)
+		}
+	}
+}
+
+func TestFavoriteListImportRow_Normalize(t *testing.T) {
+	invalidStatus, upperStatus, blank := "signed", "Accepted", "  "
+
+	tests := []struct {
+		name    string
+		row     FavoriteListImportRow
+		wantErr bool
+	}{
+		{name: "valid", row: FavoriteListImportRow{PublisherDomain: "example.com"}},
+		{name: "status is case insensitive", row: FavoriteListImportRow{PublisherDomain: "example.com", Status: &upperStatus}},
+		{name: "blank status is ignored", row: FavoriteListImportRow{PublisherDomain: "example.com", Status: &blank}},
+		{name: "missing domain", row: FavoriteListImportRow{PublisherDomain: " "}, wantErr: true},
+		{name: "domain without dot", row: FavoriteListImportRow{PublisherDomain: "localhost"}, wantErr: true},
+		{name: "invalid status", row: FavoriteListImportRow{PublisherDomain: "example.com", Status: &invalidStatus}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.row.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanFavoriteListImportMoves(t *testing.T) {
+	stages := []*PipelineStage{
+		{StageID: 1, Key: "new", Name: "New", Position: 0, Outcome: PipelineStageOutcomeOpen},
+		{StageID: 2, Key: "negotiating", Name: "Negotiating", Position: 1, Outcome: PipelineStageOutcomeOpen},
+		{StageID: 3, Key: "lost", Name: "Lost", Position: 2, Outcome: PipelineStageOutcomeLost},
+	}
+	contacted, accepted, negotiating, lost := PublisherStatusContacted, PublisherStatusAccepted, "Negotiating", "lost"
+	rows := []*FavoriteListImportRow{
+		{Row: 1, PublisherDomain: "updated.com", Status: &contacted},
+		{Row: 2, PublisherDomain: "staged.com", Stage: &negotiating},
+		{Row: 3, PublisherDomain: "notes-only.com"},
+		{Row: 4, PublisherDomain: "new.com", Stage: &lost},
+		{Row: 5, PublisherDomain: "won.com", Status: &accepted},
+	}
+	existing := map[string]bool{"updated.com": true, "staged.com": true, "notes-only.com": true, "won.com": true}
+
+	moves, unmoved := PlanFavoriteListImportMoves(stages, rows, existing)
+	if len(moves) != 1 || moves[0].Stage.StageID != 2 ||
+		strings.Join(moves[0].PublisherDomains, ",") != "updated.com,staged.com" {
+		t.Errorf("PlanFavoriteListImportMoves() moves = %+v", moves)
+	}
+	// The pipeline has no won stage for the accepted status
+	if len(unmoved) != 1 || unmoved[0].PublisherDomain != "won.com" {
+		t.Errorf("PlanFavoriteListImportMoves() unmoved = %+v", unmoved)
+	}
+}
+
+func TestBulkFavoriteListItemsRequest_Validate(t *testing.T) {
+	status := PublisherStatusContacted
+	stageID := int64(3)
+	domains := []string{"example.com"}
+
+	tests := []struct {
+		name    string
+		req     BulkFavoriteListItemsRequest
+		wantErr bool
+	}{
+		{name: "status", req: BulkFavoriteListItemsRequest{Action: FavoriteListBulkActionStatus, PublisherDomains: domains, Status: &status}},
+		{name: "stage", req: BulkFavoriteListItemsRequest{Action: FavoriteListBulkActionStage, PublisherDomains: domains, StageID: &stageID}},
+		{name: "remove", req: BulkFavoriteListItemsRequest{Action: FavoriteListBulkActionRemove, PublisherDomains: domains}},
+		{name: "status without status", req: BulkFavoriteListItemsRequest{Action: FavoriteListBulkActionStatus, PublisherDomains: domains}, wantErr: true},
+		{name: "stage without stage", req: BulkFavoriteListItemsRequest{Action: FavoriteListBulkActionStage, PublisherDomains: domains}, wantErr: true},
+		{name: "unknown action", req: BulkFavoriteListItemsRequest{Action: "archive", PublisherDomains: domains}, wantErr: true},
+		{name: "no domains", req: BulkFavoriteListItemsRequest{Action: FavoriteListBulkActionRemove}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCopyFavoriteListItemsRequest_Validate(t *testing.T) {
+	targetID := int64(2)
+	name, blank := "Copy", " "
+
+	tests := []struct {
+		name    string
+		req     CopyFavoriteListItemsRequest
+		wantErr bool
+	}{
+		{name: "existing list", req: CopyFavoriteListItemsRequest{TargetListID: &targetID}},
+		{name: "new list", req: CopyFavoriteListItemsRequest{NewListName: &name, OnDuplicate: FavoriteListDuplicateUpdate}},
+		{name: "no target", req: CopyFavoriteListItemsRequest{}, wantErr: true},
+		{name: "both targets", req: CopyFavoriteListItemsRequest{TargetListID: &targetID, NewListName: &name}, wantErr: true},
+		{name: "blank name", req: CopyFavoriteListItemsRequest{NewListName: &blank}, wantErr: true},
+		{name: "invalid duplicate mode", req: CopyFavoriteListItemsRequest{TargetListID: &targetID, OnDuplicate: "replace"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
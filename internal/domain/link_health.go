@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of URLs checked by link health monitoring
+const (
+	LinkURLKindDestination = "destination" // Campaign destination URL, with its macros expanded
+	LinkURLKindPreview     = "preview"     // Campaign preview URL
+	LinkURLKindTracking    = "tracking"    // Provider tracking URL of a tracking link
+)
+
+const (
+	// LinkHealthCheckInterval is how often the URLs of active campaigns are checked
+	LinkHealthCheckInterval = time.Hour
+	// LinkHealthCheckRetention is how long check results are kept
+	LinkHealthCheckRetention = 30 * 24 * time.Hour
+	// LinkHealthClaimTimeout is how long a campaign claimed for a check is left to the instance
+	// checking it before another instance may check it
+	LinkHealthClaimTimeout = 30 * time.Minute
+	// MaxLinkHealthRedirects is the number of redirects followed before a check fails
+	MaxLinkHealthRedirects = 10
+	// MaxAutoPauseAfterFailures bounds the failure streak a campaign can be paused after
+	MaxAutoPauseAfterFailures = 100
+)
+
+// LinkRedirectHop is a response in the redirect chain of a checked URL
+type LinkRedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// LinkHealthCheck is the result of checking one URL of a campaign
+type LinkHealthCheck struct {
+	CheckID        int64             `json:"check_id" db:"check_id"`
+	OrganizationID int64             `json:"organization_id" db:"organization_id"`
+	CampaignID     int64             `json:"campaign_id" db:"campaign_id"`
+	TrackingLinkID *int64            `json:"tracking_link_id,omitempty" db:"tracking_link_id"`
+	URLKind        string            `json:"url_kind" db:"url_kind"`
+	URL            string            `json:"url" db:"url"`
+	StatusCode     *int              `json:"status_code,omitempty" db:"status_code"` // Status of the last response
+	LatencyMs      int               `json:"latency_ms" db:"latency_ms"`             // Time until the last response, redirects included
+	RedirectChain  []LinkRedirectHop `json:"redirect_chain" db:"redirect_chain"`     // Every response, the last one included
+	TLSExpiresAt   *time.Time        `json:"tls_expires_at,omitempty" db:"tls_expires_at"`
+	Error          *string           `json:"error,omitempty" db:"error"`
+	Healthy        bool              `json:"healthy" db:"healthy"`
+	CheckedAt      time.Time         `json:"checked_at" db:"checked_at"`
+}
+
+// CampaignLinkHealth holds the monitoring settings and failure streak of a campaign. Campaigns
+// without a stored record are monitored without auto-pause.
+type CampaignLinkHealth struct {
+	CampaignID             int64      `json:"campaign_id" db:"campaign_id"`
+	Enabled                bool       `json:"enabled" db:"enabled"`
+	AutoPauseAfterFailures *int       `json:"auto_pause_after_failures,omitempty" db:"auto_pause_after_failures"`
+	ConsecutiveFailures    int        `json:"consecutive_failures" db:"consecutive_failures"` // Failed runs in a row
+	LastCheckedAt          *time.Time `json:"last_checked_at,omitempty" db:"last_checked_at"`
+	LastHealthy            *bool      `json:"last_healthy,omitempty" db:"last_healthy"`
+	AutoPausedAt           *time.Time `json:"auto_paused_at,omitempty" db:"auto_paused_at"`
+	CreatedAt              time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ShouldAutoPause reports whether the failure streak reached the auto-pause threshold
+func (h *CampaignLinkHealth) ShouldAutoPause() bool {
+	return h.AutoPauseAfterFailures != nil && h.ConsecutiveFailures >= *h.AutoPauseAfterFailures
+}
+
+// LinkHealthTarget is an active campaign due for a check, with its monitoring record
+type LinkHealthTarget struct {
+	Campaign *Campaign
+	Health   *CampaignLinkHealth
+}
+
+// SetCampaignLinkHealthRequest changes the monitoring settings of a campaign
+type SetCampaignLinkHealthRequest struct {
+	Enabled                bool `json:"enabled"`
+	AutoPauseAfterFailures *int `json:"auto_pause_after_failures,omitempty"` // Omit to never pause the campaign
+}
+
+// Validate validates the auto-pause threshold
+func (r *SetCampaignLinkHealthRequest) Validate() error {
+	if r.AutoPauseAfterFailures != nil && (*r.AutoPauseAfterFailures < 1 || *r.AutoPauseAfterFailures > MaxAutoPauseAfterFailures) {
+		return fmt.Errorf("auto_pause_after_failures must be between 1 and %d", MaxAutoPauseAfterFailures)
+	}
+	return nil
+}
+
+// CampaignLinkHealthResponse is the monitoring record of a campaign with its latest checks
+type CampaignLinkHealthResponse struct {
+	Health       *CampaignLinkHealth `json:"health"`
+	LatestChecks []*LinkHealthCheck  `json:"latest_checks"`
+}
+
+// LinkHealthCheckListResponse is a page of link health checks
+type LinkHealthCheckListResponse struct {
+	Checks   []*LinkHealthCheck `json:"checks"`
+	Total    int                `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
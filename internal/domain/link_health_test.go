@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestSetCampaignLinkHealthRequestValidate(t *testing.T) {
+	threshold := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		req     SetCampaignLinkHealthRequest
+		wantErr bool
+	}{
+		{"no auto-pause", SetCampaignLinkHealthRequest{Enabled: true}, false},
+		{"auto-pause", SetCampaignLinkHealthRequest{Enabled: true, AutoPauseAfterFailures: threshold(3)}, false},
+		{"maximum", SetCampaignLinkHealthRequest{AutoPauseAfterFailures: threshold(MaxAutoPauseAfterFailures)}, false},
+		{"zero", SetCampaignLinkHealthRequest{AutoPauseAfterFailures: threshold(0)}, true},
+		{"too many", SetCampaignLinkHealthRequest{AutoPauseAfterFailures: threshold(MaxAutoPauseAfterFailures + 1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCampaignLinkHealthShouldAutoPause(t *testing.T) {
+	threshold := 3
+	health := &CampaignLinkHealth{ConsecutiveFailures: 5}
+	if health.ShouldAutoPause() {
+		t.Error("ShouldAutoPause() = true without a threshold")
+	}
+
+	health.AutoPauseAfterFailures = &threshold
+	health.ConsecutiveFailures = 2
+	if health.ShouldAutoPause() {
+		t.Error("ShouldAutoPause() = true below the threshold")
+	}
+	health.ConsecutiveFailures = 3
+	if !health.ShouldAutoPause() {
+		t.Error("ShouldAutoPause() = false at the threshold")
+	}
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Notification types
+const (
+	NotificationTypePublisherReply     = "publisher_reply"     // A publisher replied to a conversation
+	NotificationTypeAssociationRequest = "association_request" // An advertiser invited, or an affiliate asked to join, the organization
+	NotificationTypeDelegationInvite   = "delegation_invite"   // An advertiser asked the agency to manage its account
+	NotificationTypeBillingAlert       = "billing_alert"       // Low balance or a failed recharge
+	NotificationTypeLinkHealthAlert    = "link_health_alert"   // A campaign URL failed its health check or the campaign was auto-paused
+)
+
+// NotificationTypes lists every notification type
+var NotificationTypes = []string{
+	NotificationTypePublisherReply,
+	NotificationTypeAssociationRequest,
+	NotificationTypeDelegationInvite,
+	NotificationTypeBillingAlert,
+	NotificationTypeLinkHealthAlert,
+}
+
+const (
+	// NotificationChannel is the Postgres channel new notifications are announced on
+	NotificationChannel = "notifications"
+	// DefaultNotificationLimit is the default page size when listing notifications
+	DefaultNotificationLimit = 20
+	// MaxNotificationLimit is the maximum page size when listing notifications
+	MaxNotificationLimit = 100
+)
+
+// IsValidNotificationType checks if a notification type is valid
+func IsValidNotificationType(notificationType string) bool {
+	for _, t := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is an in-app notification of an organization user
+type Notification struct {
+	NotificationID int64                  `json:"notification_id" db:"notification_id"`
+	OrganizationID int64                  `json:"organization_id" db:"organization_id"`
+	UserID         string                 `json:"user_id" db:"user_id"`
+	Type           string                 `json:"type" db:"type"`
+	Title          string                 `json:"title" db:"title"`
+	Body           *string                `json:"body,omitempty" db:"body"`
+	Data           map[string]interface{} `json:"data" db:"data"`
+	ReadAt         *time.Time             `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+}
+
+// NewNotification describes a notification to send to the users of an organization
+type NewNotification struct {
+	OrganizationID int64
+	// UserIDs restricts the recipients; when empty every user of the organization is notified
+	UserIDs []string
+	Type    string
+	Title   string
+	Body    string
+	Data    map[string]interface{}
+}
+
+// Validate checks the type and title of a notification
+func (n *NewNotification) Validate() error {
+	if n.OrganizationID <= 0 {
+		return fmt.Errorf("organization_id is required")
+	}
+	if !IsValidNotificationType(n.Type) {
+		return fmt.Errorf("invalid notification type: %s", n.Type)
+	}
+	if n.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if len(n.Title) > 255 {
+		return fmt.Errorf("title must be at most 255 characters")
+	}
+	return nil
+}
+
+// NotificationEvent is the payload announced on NotificationChannel when a notification is created
+type NotificationEvent struct {
+	NotificationID int64  `json:"notification_id"`
+	UserID         string `json:"user_id"`
+}
+
+// NotificationFilter selects the notifications of a user
+type NotificationFilter struct {
+	UnreadOnly bool
+	Type       *string
+	Limit      int
+	Offset     int
+}
+
+// Normalize applies the default and maximum limit and checks the type
+func (f *NotificationFilter) Normalize() error {
+	if f.Type != nil && !IsValidNotificationType(*f.Type) {
+		return fmt.Errorf("invalid notification type: %s", *f.Type)
+	}
+	if f.Limit < 1 {
+		f.Limit = DefaultNotificationLimit
+	}
+	if f.Limit > MaxNotificationLimit {
+		f.Limit = MaxNotificationLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return nil
+}
+
+// NotificationListResponse is a page of notifications
+type NotificationListResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	Total         int             `json:"total"`
+	UnreadCount   int             `json:"unread_count"`
+	Page          int             `json:"page"`
+	PageSize      int             `json:"page_size"`
+}
+
+// NotificationPreference is the setting of a user for a notification type
+type NotificationPreference struct {
+	Type string `json:"type" db:"type"`
+	// Enabled notifications are stored and listed; disabled ones are dropped
+	Enabled bool `json:"enabled" db:"enabled"`
+	// Realtime notifications are pushed to connected clients as they are created
+	Realtime bool `json:"realtime" db:"realtime"`
+}
+
+// DefaultNotificationPreferences returns the settings of a user who changed nothing
+func DefaultNotificationPreferences() []NotificationPreference {
+	preferences := make([]NotificationPreference, 0, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		preferences = append(preferences, NotificationPreference{Type: t, Enabled: true, Realtime: true})
+	}
+	return preferences
+}
+
+// NotificationPreferenceUpdate changes the settings of one notification type
+type NotificationPreferenceUpdate struct {
+	Type     string `json:"type" binding:"required"`
+	Enabled  *bool  `json:"enabled,omitempty"`
+	Realtime *bool  `json:"realtime,omitempty"`
+}
+
+// UpdateNotificationPreferencesRequest is the request body for changing notification preferences
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceUpdate `json:"preferences" binding:"required"`
+}
+
+// Validate checks that every type is valid and appears once
+func (r *UpdateNotificationPreferencesRequest) Validate() error {
+	if len(r.Preferences) == 0 {
+		return fmt.Errorf("at least one preference is required")
+	}
+	seen := make(map[string]bool, len(r.Preferences))
+	for _, p := range r.Preferences {
+		if !IsValidNotificationType(p.Type) {
+			return fmt.Errorf("invalid notification type: %s", p.Type)
+		}
+		if seen[p.Type] {
+			return fmt.Errorf("notification type %s is listed more than once", p.Type)
+		}
+		seen[p.Type] = true
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewNotification_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification NewNotification
+		wantErr      bool
+	}{
+		{name: "valid", notification: NewNotification{OrganizationID: 1, Type: NotificationTypePublisherReply, Title: "example.com replied"}},
+		{name: "missing organization", notification: NewNotification{Type: NotificationTypeBillingAlert, Title: "Balance is low"}, wantErr: true},
+		{name: "unknown type", notification: NewNotification{OrganizationID: 1, Type: "marketing", Title: "News"}, wantErr: true},
+		{name: "blank title", notification: NewNotification{OrganizationID: 1, Type: NotificationTypeBillingAlert}, wantErr: true},
+		{name: "title too long", notification: NewNotification{OrganizationID: 1, Type: NotificationTypeBillingAlert, Title: strings.Repeat("a", 256)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notification.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotificationFilter_Normalize(t *testing.T) {
+	filter := NotificationFilter{Limit: 1000, Offset: -5}
+	if err := filter.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if filter.Limit != MaxNotificationLimit || filter.Offset != 0 {
+		t.Errorf("Normalize() = %+v", filter)
+	}
+
+	filter = NotificationFilter{}
+	if err := filter.Normalize(); err != nil || filter.Limit != DefaultNotificationLimit {
+		t.Errorf("Normalize() = %+v, error = %v, want default limit", filter, err)
+	}
+
+	invalid := "marketing"
+	filter = NotificationFilter{Type: &invalid}
+	if err := filter.Normalize(); err == nil {
+		t.Error("Normalize() with an invalid type should fail")
+	}
+}
+
+func TestDefaultNotificationPreferences(t *testing.T) {
+	preferences := DefaultNotificationPreferences()
+	if len(preferences) != len(NotificationTypes) {
+		t.Fatalf("got %d preferences, want %d", len(preferences), len(NotificationTypes))
+	}
+	for _, p := range preferences {
+		if !p.Enabled || !p.Realtime {
+			t.Errorf("preference %s = %+v, want enabled and realtime", p.Type, p)
+		}
+	}
+}
+
+func TestUpdateNotificationPreferencesRequest_Validate(t *testing.T) {
+	off := false
+
+	tests := []struct {
+		name    string
+		req     UpdateNotificationPreferencesRequest
+		wantErr bool
+	}{
+		{name: "valid", req: UpdateNotificationPreferencesRequest{Preferences: []NotificationPreferenceUpdate{
+			{Type: NotificationTypeBillingAlert, Realtime: &off},
+			{Type: NotificationTypePublisherReply, Enabled: &off},
+		}}},
+		{name: "empty", req: UpdateNotificationPreferencesRequest{}, wantErr: true},
+		{name: "unknown type", req: UpdateNotificationPreferencesRequest{Preferences: []NotificationPreferenceUpdate{{Type: "marketing", Enabled: &off}}}, wantErr: true},
+		{name: "duplicate type", req: UpdateNotificationPreferencesRequest{Preferences: []NotificationPreferenceUpdate{
+			{Type: NotificationTypeBillingAlert, Enabled: &off},
+			{Type: NotificationTypeBillingAlert, Realtime: &off},
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
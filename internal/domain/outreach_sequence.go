@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Outreach sequence statuses. Paused sequences keep their enrollments but send nothing.
+const (
+	OutreachSequenceStatusActive = "active"
+	OutreachSequenceStatusPaused = "paused"
+)
+
+// Outreach enrollment statuses
+const (
+	OutreachEnrollmentStatusScheduled = "scheduled" // First touch not sent yet
+	OutreachEnrollmentStatusActive    = "active"    // Follow-ups remain
+	OutreachEnrollmentStatusCompleted = "completed" // Every step sent without a reply
+	OutreachEnrollmentStatusReplied   = "replied"   // The publisher replied; no further steps are sent
+	OutreachEnrollmentStatusStopped   = "stopped"   // The conversation was closed or removed
+	OutreachEnrollmentStatusFailed    = "failed"    // A step could not be sent
+)
+
+const (
+	// MaxOutreachSequenceSteps is the maximum number of steps, including the first touch
+	MaxOutreachSequenceSteps = 10
+	// MaxOutreachFollowUpDelayDays is the maximum wait before a follow-up
+	MaxOutreachFollowUpDelayDays = 90
+	// OutreachSendBatchSize is the number of due steps sent per scheduler tick
+	OutreachSendBatchSize = 100
+	// DefaultOutreachEnrollmentLimit is the default page size when listing enrollments
+	DefaultOutreachEnrollmentLimit = 50
+	// MaxOutreachEnrollmentLimit is the maximum page size when listing enrollments
+	MaxOutreachEnrollmentLimit = 500
+)
+
+// MessageMetadataOutreach is the metadata key holding the sequence, enrollment and step of a
+// message sent by an outreach sequence
+const MessageMetadataOutreach = "outreach"
+
+// IsValidOutreachEnrollmentStatus checks if an enrollment status is valid
+func IsValidOutreachEnrollmentStatus(status string) bool {
+	switch status {
+	case OutreachEnrollmentStatusScheduled, OutreachEnrollmentStatusActive, OutreachEnrollmentStatusCompleted,
+		OutreachEnrollmentStatusReplied, OutreachEnrollmentStatusStopped, OutreachEnrollmentStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// OutreachSequence is a first-touch message and timed follow-ups sent to publishers until they reply
+type OutreachSequence struct {
+	SequenceID      int64     `json:"sequence_id" db:"sequence_id"`
+	OrganizationID  int64     `json:"organization_id" db:"organization_id"`
+	Name            string    `json:"name" db:"name"`
+	SubjectTemplate string    `json:"subject_template" db:"subject_template"`
+	Status          string    `json:"status" db:"status"`
+	CreatedByUserID *string   `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	Steps []*OutreachSequenceStep `json:"steps,omitempty" db:"-"`
+	// Optional: Set when fetching a single sequence
+	Stats *OutreachSequenceStats `json:"stats,omitempty" db:"-"`
+}
+
+// OutreachSequenceStep is a message of a sequence. Step 1 is the first touch, sent at launch;
+// later steps are sent DelayDays after the previous one.
+type OutreachSequenceStep struct {
+	StepID       int64     `json:"step_id" db:"step_id"`
+	SequenceID   int64     `json:"sequence_id" db:"sequence_id"`
+	StepNumber   int       `json:"step_number" db:"step_number"`
+	DelayDays    int       `json:"delay_days" db:"delay_days"`
+	BodyTemplate string    `json:"body_template" db:"body_template"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// NextStep returns the step to send after stepsSent steps, or nil when every step was sent
+func (s *OutreachSequence) NextStep(stepsSent int) *OutreachSequenceStep {
+	if stepsSent < 0 || stepsSent >= len(s.Steps) {
+		return nil
+	}
+	return s.Steps[stepsSent]
+}
+
+// OutreachSequenceStepInput is a step of a create or update request
+type OutreachSequenceStepInput struct {
+	DelayDays    int    `json:"delay_days"`
+	BodyTemplate string `json:"body_template" binding:"required"`
+}
+
+// CreateOutreachSequenceRequest creates a sequence. Templates use text/template syntax with the
+// MessageMergeFields, e.g. "Hi {{.Domain}} team".
+type CreateOutreachSequenceRequest struct {
+	Name            string                      `json:"name" binding:"required,max=255"`
+	SubjectTemplate string                      `json:"subject_template" binding:"required,max=500"`
+	Steps           []OutreachSequenceStepInput `json:"steps" binding:"required"`
+}
+
+// Validate validates the CreateOutreachSequenceRequest
+func (r *CreateOutreachSequenceRequest) Validate() error {
+	if err := validateOutreachName(r.Name); err != nil {
+		return err
+	}
+	if err := validateOutreachSubject(r.SubjectTemplate); err != nil {
+		return err
+	}
+	return validateOutreachSteps(r.Steps)
+}
+
+// UpdateOutreachSequenceRequest updates a sequence. Steps, when given, replace every step;
+// enrollments continue with the new steps after the ones they were sent.
+type UpdateOutreachSequenceRequest struct {
+	Name            *string                     `json:"name,omitempty" binding:"omitempty,max=255"`
+	SubjectTemplate *string                     `json:"subject_template,omitempty" binding:"omitempty,max=500"`
+	Status          *string                     `json:"status,omitempty" binding:"omitempty,oneof=active paused"`
+	Steps           []OutreachSequenceStepInput `json:"steps,omitempty"`
+}
+
+// Validate validates the UpdateOutreachSequenceRequest
+func (r *UpdateOutreachSequenceRequest) Validate() error {
+	if r.Name != nil {
+		if err := validateOutreachName(*r.Name); err != nil {
+			return err
+		}
+	}
+	if r.SubjectTemplate != nil {
+		if err := validateOutreachSubject(*r.SubjectTemplate); err != nil {
+			return err
+		}
+	}
+	if r.Status != nil && *r.Status != OutreachSequenceStatusActive && *r.Status != OutreachSequenceStatusPaused {
+		return fmt.Errorf("status must be active or paused")
+	}
+	if r.Steps != nil {
+		return validateOutreachSteps(r.Steps)
+	}
+	return nil
+}
+
+func validateOutreachName(name string) error {
+	if err := validateStringLength(strings.TrimSpace(name), 1, 255); err != nil {
+		return fmt.Errorf("name must be 1-255 characters")
+	}
+	return nil
+}
+
+func validateOutreachSubject(subject string) error {
+	if err := validateStringLength(strings.TrimSpace(subject), 1, 500); err != nil {
+		return fmt.Errorf("subject_template must be 1-500 characters")
+	}
+	return nil
+}
+
+func validateOutreachSteps(steps []OutreachSequenceStepInput) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	if len(steps) > MaxOutreachSequenceSteps {
+		return fmt.Errorf("at most %d steps are allowed", MaxOutreachSequenceSteps)
+	}
+	for i, step := range steps {
+		if err := validateStringLength(strings.TrimSpace(step.BodyTemplate), 1, 5000); err != nil {
+			return fmt.Errorf("step %d: body_template must be 1-5000 characters", i+1)
+		}
+		if i == 0 {
+			if step.DelayDays != 0 {
+				return fmt.Errorf("step 1 is sent at launch and cannot have a delay")
+			}
+			continue
+		}
+		if step.DelayDays < 1 || step.DelayDays > MaxOutreachFollowUpDelayDays {
+			return fmt.Errorf("step %d: delay_days must be between 1 and %d", i+1, MaxOutreachFollowUpDelayDays)
+		}
+	}
+	return nil
+}
+
+// LaunchOutreachSequenceRequest enrolls the publishers of a favorite list in a sequence
+type LaunchOutreachSequenceRequest struct {
+	ListID           int64      `json:"list_id" binding:"required"`
+	PublisherDomains []string   `json:"publisher_domains,omitempty"` // Defaults to every publisher of the list
+	StartAt          *time.Time `json:"start_at,omitempty"`          // When the first touch is sent; defaults to now
+}
+
+// Validate validates the LaunchOutreachSequenceRequest
+func (r *LaunchOutreachSequenceRequest) Validate() error {
+	if r.ListID <= 0 {
+		return fmt.Errorf("list_id is required")
+	}
+	if r.StartAt != nil && r.StartAt.After(time.Now().AddDate(1, 0, 0)) {
+		return fmt.Errorf("start_at must be within a year")
+	}
+	return nil
+}
+
+// OutreachEnrollment is a publisher a sequence was launched against
+type OutreachEnrollment struct {
+	EnrollmentID    int64      `json:"enrollment_id" db:"enrollment_id"`
+	SequenceID      int64      `json:"sequence_id" db:"sequence_id"`
+	OrganizationID  int64      `json:"organization_id" db:"organization_id"`
+	ListID          *int64     `json:"list_id,omitempty" db:"list_id"`
+	PublisherDomain string     `json:"publisher_domain" db:"publisher_domain"`
+	ConversationID  *int64     `json:"conversation_id,omitempty" db:"conversation_id"`
+	Status          string     `json:"status" db:"status"`
+	StepsSent       int        `json:"steps_sent" db:"steps_sent"`
+	NextSendAt      *time.Time `json:"next_send_at,omitempty" db:"next_send_at"`
+	LastSentAt      *time.Time `json:"last_sent_at,omitempty" db:"last_sent_at"`
+	RepliedAt       *time.Time `json:"replied_at,omitempty" db:"replied_at"`
+	LastError       *string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Reasons publishers are skipped when a sequence is launched
+const (
+	OutreachSkipAlreadyEnrolled    = "already_enrolled"
+	OutreachSkipActiveConversation = "active_conversation"
+	OutreachSkipNoContactEmail     = "no_contact_email"
+	OutreachSkipClosedStatus       = "closed_status" // Accepted, or in a won or lost pipeline stage
+	OutreachSkipNotInList          = "not_in_list"
+)
+
+// OutreachLaunchSkip is a publisher that was not enrolled and why
+type OutreachLaunchSkip struct {
+	PublisherDomain string `json:"publisher_domain"`
+	Reason          string `json:"reason"`
+}
+
+// LaunchOutreachSequenceResult reports the enrollments created by a launch
+type LaunchOutreachSequenceResult struct {
+	Enrolled    int                   `json:"enrolled"`
+	Enrollments []*OutreachEnrollment `json:"enrollments"`
+	Skipped     []OutreachLaunchSkip  `json:"skipped"`
+}
+
+// OutreachEnrollmentFilter selects the enrollments of a sequence
+type OutreachEnrollmentFilter struct {
+	Status *string
+	Limit  int
+	Offset int
+}
+
+// Normalize applies the default and maximum limit and checks the status
+func (f *OutreachEnrollmentFilter) Normalize() error {
+	if f.Status != nil && !IsValidOutreachEnrollmentStatus(*f.Status) {
+		return fmt.Errorf("invalid status: %s", *f.Status)
+	}
+	if f.Limit < 1 {
+		f.Limit = DefaultOutreachEnrollmentLimit
+	}
+	if f.Limit > MaxOutreachEnrollmentLimit {
+		f.Limit = MaxOutreachEnrollmentLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return nil
+}
+
+// OutreachEnrollmentList is a page of enrollments
+type OutreachEnrollmentList struct {
+	Enrollments []*OutreachEnrollment `json:"enrollments"`
+	Total       int                   `json:"total"`
+}
+
+// OutreachSequenceStats summarizes the enrollments of a sequence
+type OutreachSequenceStats struct {
+	Enrolled  int `json:"enrolled"`
+	Scheduled int `json:"scheduled"`
+	Active    int `json:"active"`
+	Completed int `json:"completed"`
+	Replied   int `json:"replied"`
+	Stopped   int `json:"stopped"`
+	Failed    int `json:"failed"`
+
+	Contacted    int      `json:"contacted"`            // Enrollments sent at least the first touch
+	MessagesSent int      `json:"messages_sent"`        // Steps sent across enrollments
+	ReplyRate    *float64 `json:"reply_rate,omitempty"` // Replied / contacted; nil before any first touch
+
+	Steps []OutreachStepStats `json:"steps"`
+}
+
+// OutreachStepStats counts the enrollments sent a step and the replies that followed it
+type OutreachStepStats struct {
+	StepNumber int      `json:"step_number"`
+	Sent       int      `json:"sent"`
+	Replies    int      `json:"replies"` // Replies received after this step and before the next one
+	ReplyRate  *float64 `json:"reply_rate,omitempty"`
+}
+
+// ComputeRates sets the sequence and step reply rates from the counts
+func (s *OutreachSequenceStats) ComputeRates() {
+	s.ReplyRate = conversionRate(s.Replied, s.Contacted)
+	for i := range s.Steps {
+		s.Steps[i].ReplyRate = conversionRate(s.Steps[i].Replies, s.Steps[i].Sent)
+	}
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateOutreachSequenceRequest_Validate(t *testing.T) {
+	first := OutreachSequenceStepInput{BodyTemplate: "Hi {{.Domain}} team"}
+	followUp := OutreachSequenceStepInput{DelayDays: 3, BodyTemplate: "Just checking in"}
+
+	tests := []struct {
+		name    string
+		req     CreateOutreachSequenceRequest
+		wantErr bool
+	}{
+		{name: "first touch only", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{first}}},
+		{name: "with follow-up", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{first, followUp}}},
+		{name: "blank name", req: CreateOutreachSequenceRequest{Name: " ", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{first}}, wantErr: true},
+		{name: "blank subject", req: CreateOutreachSequenceRequest{Name: "Q3", Steps: []OutreachSequenceStepInput{first}}, wantErr: true},
+		{name: "no steps", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering"}, wantErr: true},
+		{name: "delayed first touch", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{followUp}}, wantErr: true},
+		{name: "follow-up without delay", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{first, first}}, wantErr: true},
+		{name: "follow-up delay too long", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{first, {DelayDays: MaxOutreachFollowUpDelayDays + 1, BodyTemplate: "Hi"}}}, wantErr: true},
+		{name: "body too long", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: []OutreachSequenceStepInput{{BodyTemplate: strings.Repeat("a", 5001)}}}, wantErr: true},
+		{name: "too many steps", req: CreateOutreachSequenceRequest{Name: "Q3", SubjectTemplate: "Partnering", Steps: append([]OutreachSequenceStepInput{first}, make([]OutreachSequenceStepInput, MaxOutreachSequenceSteps)...)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateOutreachSequenceRequest_Validate(t *testing.T) {
+	paused, archived := OutreachSequenceStatusPaused, "archived"
+
+	if err := (&UpdateOutreachSequenceRequest{Status: &paused}).Validate(); err != nil {
+		t.Errorf("Validate() with paused status error = %v", err)
+	}
+	if err := (&UpdateOutreachSequenceRequest{Status: &archived}).Validate(); err == nil {
+		t.Error("Validate() with an invalid status should fail")
+	}
+	if err := (&UpdateOutreachSequenceRequest{Steps: []OutreachSequenceStepInput{}}).Validate(); err == nil {
+		t.Error("Validate() with an empty step list should fail")
+	}
+}
+
+func TestLaunchOutreachSequenceRequest_Validate(t *testing.T) {
+	soon, tooLate := time.Now().Add(time.Hour), time.Now().AddDate(2, 0, 0)
+
+	tests := []struct {
+		name    string
+		req     LaunchOutreachSequenceRequest
+		wantErr bool
+	}{
+		{name: "whole list", req: LaunchOutreachSequenceRequest{ListID: 1}},
+		{name: "scheduled", req: LaunchOutreachSequenceRequest{ListID: 1, StartAt: &soon}},
+		{name: "missing list", req: LaunchOutreachSequenceRequest{}, wantErr: true},
+		{name: "start too late", req: LaunchOutreachSequenceRequest{ListID: 1, StartAt: &tooLate}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutreachSequence_NextStep(t *testing.T) {
+	sequence := OutreachSequence{Steps: []*OutreachSequenceStep{{StepNumber: 1}, {StepNumber: 2}}}
+
+	if step := sequence.NextStep(0); step == nil || step.StepNumber != 1 {
+		t.Errorf("NextStep(0) = %v, want step 1", step)
+	}
+	if step := sequence.NextStep(1); step == nil || step.StepNumber != 2 {
+		t.Errorf("NextStep(1) = %v, want step 2", step)
+	}
+	if step := sequence.NextStep(2); step != nil {
+		t.Errorf("NextStep(2) = %v, want nil after the last step", step)
+	}
+}
+
+func TestOutreachEnrollmentFilter_Normalize(t *testing.T) {
+	filter := OutreachEnrollmentFilter{Limit: 1000, Offset: -1}
+	if err := filter.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if filter.Limit != MaxOutreachEnrollmentLimit || filter.Offset != 0 {
+		t.Errorf("Normalize() = %+v", filter)
+	}
+
+	status := "bounced"
+	filter = OutreachEnrollmentFilter{Status: &status}
+	if err := filter.Normalize(); err == nil {
+		t.Error("Normalize() with an invalid status should fail")
+	}
+}
+
+func TestOutreachSequenceStats_ComputeRates(t *testing.T) {
+	stats := OutreachSequenceStats{
+		Contacted: 4,
+		Replied:   1,
+		Steps:     []OutreachStepStats{{StepNumber: 1, Sent: 4, Replies: 1}, {StepNumber: 2}},
+	}
+	stats.ComputeRates()
+
+	if stats.ReplyRate == nil || *stats.ReplyRate != 0.25 {
+		t.Errorf("ReplyRate = %v, want 0.25", stats.ReplyRate)
+	}
+	if stats.Steps[0].ReplyRate == nil || *stats.Steps[0].ReplyRate != 0.25 {
+		t.Errorf("step 1 ReplyRate = %v, want 0.25", stats.Steps[0].ReplyRate)
+	}
+	if stats.Steps[1].ReplyRate != nil {
+		t.Errorf("step 2 ReplyRate = %v, want nil before it is sent", *stats.Steps[1].ReplyRate)
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Provider stats import status constants
+const (
+	ProviderStatsImportStatusRunning   = "running"
+	ProviderStatsImportStatusCompleted = "completed"
+	ProviderStatsImportStatusFailed    = "failed"
+)
+
+// Provider stats import trigger constants
+const (
+	ProviderStatsImportTriggerScheduled = "scheduled"
+	ProviderStatsImportTriggerBackfill  = "backfill"
+)
+
+// MaxProviderStatsBackfillDays limits how many days a single backfill request may cover
+const MaxProviderStatsBackfillDays = 93
+
+// ProviderStatsScheduledImportDays is how many past days the scheduled import re-imports each
+// night, so that clicks and conversions the provider settles late are picked up
+const ProviderStatsScheduledImportDays = 3
+
+// ProviderDailyStat represents one hour of provider-reported performance for an offer, affiliate, sub ID,
+// country and device combination. Facts are stored per UTC day and hour.
+type ProviderDailyStat struct {
+	StatID       int64     `json:"stat_id" db:"stat_id"`
+	ProviderType string    `json:"provider_type" db:"provider_type"`
+	StatDate     time.Time `json:"stat_date" db:"stat_date"`
+	StatHour     int16     `json:"stat_hour" db:"stat_hour"`
+
+	// Provider-side dimensions
+	ProviderOfferID     string `json:"provider_offer_id" db:"provider_offer_id"`
+	ProviderAffiliateID string `json:"provider_affiliate_id" db:"provider_affiliate_id"`
+	Sub1                string `json:"sub1" db:"sub1"`
+	Sub2                string `json:"sub2" db:"sub2"`
+	Sub3                string `json:"sub3" db:"sub3"`
+	Sub4                string `json:"sub4" db:"sub4"`
+	Sub5                string `json:"sub5" db:"sub5"`
+	CountryCode         string `json:"country_code" db:"country_code"`
+	DeviceType          string `json:"device_type" db:"device_type"`
+
+	// Local entities resolved through provider mappings
+	CampaignID  *int64 `json:"campaign_id,omitempty" db:"campaign_id"`
+	AffiliateID *int64 `json:"affiliate_id,omitempty" db:"affiliate_id"`
+
+	// Metrics
+	Impressions  int64           `json:"impressions" db:"impressions"`
+	Clicks       int64           `json:"clicks" db:"clicks"`
+	UniqueClicks int64           `json:"unique_clicks" db:"unique_clicks"`
+	Conversions  int64           `json:"conversions" db:"conversions"`
+	Revenue      decimal.Decimal `json:"revenue" db:"revenue"`
+	Payout       decimal.Decimal `json:"payout" db:"payout"`
+	Currency     string          `json:"currency" db:"currency"`
+
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+}
+
+// ProviderStatsImport represents a single run of the provider stats importer for one day
+type ProviderStatsImport struct {
+	ImportID     int64      `json:"import_id" db:"import_id"`
+	ProviderType string     `json:"provider_type" db:"provider_type"`
+	StatDate     time.Time  `json:"stat_date" db:"stat_date"`
+	Status       string     `json:"status" db:"status"`
+	TriggeredBy  string     `json:"triggered_by" db:"triggered_by"`
+	RowCount     int        `json:"row_count" db:"row_count"`
+	ErrorMessage *string    `json:"error_message,omitempty" db:"error_message"`
+	StartedAt    time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ProviderStatsBackfillRequest represents a request to (re)import provider stats for a date range
+type ProviderStatsBackfillRequest struct {
+	From string `json:"from" binding:"required"` // YYYY-MM-DD, inclusive
+	To   string `json:"to" binding:"required"`   // YYYY-MM-DD, inclusive
+}
+
+// ProviderStatsBackfillResponse represents the result of a backfill request
+type ProviderStatsBackfillResponse struct {
+	Imports   []*ProviderStatsImport `json:"imports"`
+	Completed int                    `json:"completed"`
+	Failed    int                    `json:"failed"`
+}
+
+// ParseRange validates the request and returns the inclusive date range it covers
+func (r *ProviderStatsBackfillRequest) ParseRange(now time.Time) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01-02", r.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", r.From)
+	}
+	to, err := time.Parse("2006-01-02", r.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", r.To)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to date must not be before from date")
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if to.After(today) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to date must not be in the future")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > MaxProviderStatsBackfillDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("backfill range of %d days exceeds the maximum of %d days", days, MaxProviderStatsBackfillDays)
+	}
+	return from, to, nil
+}
+
+// ProviderStatsImportListResponse represents the response for listing import runs
+type ProviderStatsImportListResponse struct {
+	Imports  []*ProviderStatsImport `json:"imports"`
+	Total    int                    `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
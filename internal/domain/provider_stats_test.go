@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProviderStatsBackfillRequest_ParseRange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		req     ProviderStatsBackfillRequest
+		wantErr bool
+	}{
+		{
+			name: "single day",
+			req:  ProviderStatsBackfillRequest{From: "2024-03-14", To: "2024-03-14"},
+		},
+		{
+			name: "range ending today",
+			req:  ProviderStatsBackfillRequest{From: "2024-03-01", To: "2024-03-15"},
+		},
+		{
+			name:    "invalid from format",
+			req:     ProviderStatsBackfillRequest{From: "03/01/2024", To: "2024-03-02"},
+			wantErr: true,
+		},
+		{
+			name:    "to before from",
+			req:     ProviderStatsBackfillRequest{From: "2024-03-10", To: "2024-03-09"},
+			wantErr: true,
+		},
+		{
+			name:    "to in the future",
+			req:     ProviderStatsBackfillRequest{From: "2024-03-10", To: "2024-03-16"},
+			wantErr: true,
+		},
+		{
+			name:    "range too long",
+			req:     ProviderStatsBackfillRequest{From: "2023-12-01", To: "2024-03-15"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := tt.req.ParseRange(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (from.Format("2006-01-02") != tt.req.From || to.Format("2006-01-02") != tt.req.To) {
+				t.Errorf("ParseRange() = %v, %v, want %s, %s", from, to, tt.req.From, tt.req.To)
+			}
+		})
+	}
+}
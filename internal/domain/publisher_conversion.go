@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Publisher conversion statuses, in funnel order. Pending conversions are claims held while the
+// affiliate is being set up; they are not listed or counted.
+const (
+	PublisherConversionStatusPending    = "pending"    // Claimed, affiliate and invitation being set up
+	PublisherConversionStatusInvited    = "invited"    // Invitation issued, association not active yet
+	PublisherConversionStatusAssociated = "associated" // Association active, no tracking link yet
+	PublisherConversionStatusLinked     = "linked"     // First tracking link created
+)
+
+const (
+	// DefaultPublisherConversionLimit is the default page size when listing conversions
+	DefaultPublisherConversionLimit = 50
+	// MaxPublisherConversionLimit is the maximum page size when listing conversions
+	MaxPublisherConversionLimit = 200
+	// DefaultPublisherConversionFunnelDays is the funnel period when no range is given
+	DefaultPublisherConversionFunnelDays = 90
+	// PublisherConversionClaimTimeout is how long a pending claim blocks other conversions of the
+	// publisher; a claim left behind by a crashed request can be resumed afterwards
+	PublisherConversionClaimTimeout = 10 * time.Minute
+)
+
+// IsValidPublisherConversionStatus checks if a conversion status is valid
+func IsValidPublisherConversionStatus(status string) bool {
+	switch status {
+	case PublisherConversionStatusInvited, PublisherConversionStatusAssociated, PublisherConversionStatusLinked:
+		return true
+	default:
+		return false
+	}
+}
+
+// PublisherConversion tracks the onboarding of a discovered publisher as an affiliate, from the
+// conversation with the publisher to the first tracking link
+type PublisherConversion struct {
+	ConversionID    int64  `json:"conversion_id" db:"conversion_id"`
+	OrganizationID  int64  `json:"organization_id" db:"organization_id"` // Advertiser organization
+	PublisherDomain string `json:"publisher_domain" db:"publisher_domain"`
+	ListID          *int64 `json:"list_id,omitempty" db:"list_id"`
+	ItemID          *int64 `json:"item_id,omitempty" db:"item_id"`
+	ConversationID  *int64 `json:"conversation_id,omitempty" db:"conversation_id"`
+
+	AffiliateOrgID int64   `json:"affiliate_org_id" db:"affiliate_org_id"`
+	AffiliateID    *int64  `json:"affiliate_id,omitempty" db:"affiliate_id"`
+	ContactEmail   *string `json:"contact_email,omitempty" db:"contact_email"`
+
+	InvitationID        *int64 `json:"invitation_id,omitempty" db:"invitation_id"` // Nil when an association already existed
+	AssociationID       *int64 `json:"association_id,omitempty" db:"association_id"`
+	FirstTrackingLinkID *int64 `json:"first_tracking_link_id,omitempty" db:"first_tracking_link_id"`
+
+	Status          string  `json:"status" db:"status"`
+	CreatedByUserID *string `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+
+	ConversationStartedAt *time.Time `json:"conversation_started_at,omitempty" db:"conversation_started_at"`
+	InvitedAt             time.Time  `json:"invited_at" db:"invited_at"`
+	AssociatedAt          *time.Time `json:"associated_at,omitempty" db:"associated_at"`
+	FirstLinkAt           *time.Time `json:"first_link_at,omitempty" db:"first_link_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Optional: Set when the conversion is created
+	Invitation *AdvertiserAssociationInvitation `json:"invitation,omitempty" db:"-"`
+	// Optional: Set when the conversion is created or fetched by ID
+	AffiliateOrganization *Organization `json:"affiliate_organization,omitempty" db:"-"`
+	Affiliate             *Affiliate    `json:"affiliate,omitempty" db:"-"`
+}
+
+// ConvertPublisherRequest onboards an accepted list item as an affiliate. Without an affiliate
+// organization ID the organization whose website matches the publisher domain is linked, or a
+// new affiliate organization is created.
+type ConvertPublisherRequest struct {
+	AffiliateOrgID    *int64     `json:"affiliate_org_id,omitempty"`
+	OrganizationName  *string    `json:"organization_name,omitempty" binding:"omitempty,max=255"` // Defaults to the publisher domain
+	ContactEmail      *string    `json:"contact_email,omitempty" binding:"omitempty,max=255"`     // Defaults to the publisher's analytics contact email
+	InvitationName    *string    `json:"invitation_name,omitempty" binding:"omitempty,max=255"`
+	InvitationMessage *string    `json:"invitation_message,omitempty" binding:"omitempty,max=2000"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"` // Invitation expiry; never expires when omitted
+}
+
+// Validate validates the ConvertPublisherRequest
+func (r *ConvertPublisherRequest) Validate() error {
+	if r.AffiliateOrgID != nil && *r.AffiliateOrgID <= 0 {
+		return fmt.Errorf("affiliate_org_id must be positive")
+	}
+	if r.AffiliateOrgID != nil && r.OrganizationName != nil {
+		return fmt.Errorf("organization_name cannot be used with affiliate_org_id")
+	}
+	if r.OrganizationName != nil {
+		if err := validateStringLength(strings.TrimSpace(*r.OrganizationName), 1, 255); err != nil {
+			return fmt.Errorf("organization_name must be 1-255 characters")
+		}
+	}
+	if r.ContactEmail != nil {
+		if _, err := mail.ParseAddress(strings.TrimSpace(*r.ContactEmail)); err != nil {
+			return fmt.Errorf("invalid contact_email: %s", *r.ContactEmail)
+		}
+	}
+	if r.InvitationName != nil {
+		if err := validateStringLength(strings.TrimSpace(*r.InvitationName), 1, 255); err != nil {
+			return fmt.Errorf("invitation_name must be 1-255 characters")
+		}
+	}
+	if err := validateOptionalStringLength(r.InvitationMessage, 2000); err != nil {
+		return fmt.Errorf("invitation_message must be at most 2000 characters")
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// PublisherConversionFilter selects the conversions of an organization
+type PublisherConversionFilter struct {
+	Status *string
+	Limit  int
+	Offset int
+}
+
+// Normalize applies the default and maximum limit and checks the status
+func (f *PublisherConversionFilter) Normalize() error {
+	if f.Status != nil && !IsValidPublisherConversionStatus(*f.Status) {
+		return fmt.Errorf("invalid status: %s (must be invited, associated or linked)", *f.Status)
+	}
+	if f.Limit < 1 {
+		f.Limit = DefaultPublisherConversionLimit
+	}
+	if f.Limit > MaxPublisherConversionLimit {
+		f.Limit = MaxPublisherConversionLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return nil
+}
+
+// PublisherConversionList is a page of conversions
+type PublisherConversionList struct {
+	Conversions []*PublisherConversion `json:"conversions"`
+	Total       int                    `json:"total"`
+}
+
+// PublisherConversionFunnel counts how far the publishers an organization started talking to or
+// invited during a period got. Invited, associated and linked count the conversions invited in
+// the period, wherever they are now.
+type PublisherConversionFunnel struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	Conversations    int `json:"conversations"`     // Publisher conversations started in the period
+	Invited          int `json:"invited"`           // Publishers converted and invited in the period
+	FromConversation int `json:"from_conversation"` // Invited publishers that had a conversation
+	Associated       int `json:"associated"`
+	Linked           int `json:"linked"`
+
+	// Stage conversion rates between 0 and 1; nil when the previous stage is empty
+	InviteRate      *float64 `json:"invite_rate,omitempty"`      // Invited publishers with a conversation / conversations
+	AssociationRate *float64 `json:"association_rate,omitempty"` // Associated / invited
+	LinkRate        *float64 `json:"link_rate,omitempty"`        // Linked / associated
+
+	AvgDaysToAssociation *float64 `json:"avg_days_to_association,omitempty"` // From invitation to active association
+	AvgDaysToFirstLink   *float64 `json:"avg_days_to_first_link,omitempty"`  // From active association (or invitation) to first link
+}
+
+// ComputeRates sets the stage conversion rates from the counts
+func (f *PublisherConversionFunnel) ComputeRates() {
+	f.InviteRate = conversionRate(f.FromConversation, f.Conversations)
+	f.AssociationRate = conversionRate(f.Associated, f.Invited)
+	f.LinkRate = conversionRate(f.Linked, f.Associated)
+}
+
+func conversionRate(count, base int) *float64 {
+	if base <= 0 {
+		return nil
+	}
+	rate := float64(count) / float64(base)
+	if rate > 1 {
+		rate = 1
+	}
+	return &rate
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertPublisherRequest_Validate(t *testing.T) {
+	orgID, invalidOrgID := int64(7), int64(0)
+	name, blank := "Example Media", " "
+	email, invalidEmail := "partners@example.com", "not-an-email"
+	future, past := time.Now().Add(24*time.Hour), time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		req     ConvertPublisherRequest
+		wantErr bool
+	}{
+		{name: "defaults", req: ConvertPublisherRequest{}},
+		{name: "existing organization", req: ConvertPublisherRequest{AffiliateOrgID: &orgID, ContactEmail: &email}},
+		{name: "new organization", req: ConvertPublisherRequest{OrganizationName: &name, ExpiresAt: &future}},
+		{name: "invalid organization ID", req: ConvertPublisherRequest{AffiliateOrgID: &invalidOrgID}, wantErr: true},
+		{name: "organization ID and name", req: ConvertPublisherRequest{AffiliateOrgID: &orgID, OrganizationName: &name}, wantErr: true},
+		{name: "blank organization name", req: ConvertPublisherRequest{OrganizationName: &blank}, wantErr: true},
+		{name: "invalid contact email", req: ConvertPublisherRequest{ContactEmail: &invalidEmail}, wantErr: true},
+		{name: "blank invitation name", req: ConvertPublisherRequest{InvitationName: &blank}, wantErr: true},
+		{name: "expiry in the past", req: ConvertPublisherRequest{ExpiresAt: &past}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublisherConversionFilter_Normalize(t *testing.T) {
+	filter := PublisherConversionFilter{Limit: 1000, Offset: -5}
+	if err := filter.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if filter.Limit != MaxPublisherConversionLimit || filter.Offset != 0 {
+		t.Errorf("Normalize() = %+v", filter)
+	}
+
+	filter = PublisherConversionFilter{}
+	if err := filter.Normalize(); err != nil || filter.Limit != DefaultPublisherConversionLimit {
+		t.Errorf("Normalize() = %+v, error = %v", filter, err)
+	}
+
+	status := "signed"
+	filter = PublisherConversionFilter{Status: &status}
+	if err := filter.Normalize(); err == nil {
+		t.Error("Normalize() with an invalid status should fail")
+	}
+}
+
+func TestPublisherConversionFunnel_ComputeRates(t *testing.T) {
+	funnel := PublisherConversionFunnel{Conversations: 10, Invited: 4, FromConversation: 2, Associated: 0, Linked: 1}
+	funnel.ComputeRates()
+
+	if funnel.InviteRate == nil || *funnel.InviteRate != 0.2 {
+		t.Errorf("InviteRate = %v, want 0.2", funnel.InviteRate)
+	}
+	if funnel.AssociationRate == nil || *funnel.AssociationRate != 0 {
+		t.Errorf("AssociationRate = %v, want 0", funnel.AssociationRate)
+	}
+	if funnel.LinkRate != nil {
+		t.Errorf("LinkRate = %v, want nil without associated publishers", *funnel.LinkRate)
+	}
+
+	funnel = PublisherConversionFunnel{Conversations: 1, FromConversation: 3}
+	funnel.ComputeRates()
+	if funnel.InviteRate == nil || *funnel.InviteRate != 1 {
+		t.Errorf("InviteRate = %v, want capped at 1", funnel.InviteRate)
+	}
+}
@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultPublisherHistoryLimit is the number of snapshots returned by default
+	DefaultPublisherHistoryLimit = 90
+	// MaxPublisherHistoryLimit is the maximum number of snapshots per request
+	MaxPublisherHistoryLimit = 500
+	// RisingGrowthPercent is the traffic score or partner growth at which a publisher counts as rising
+	RisingGrowthPercent = 10
+)
+
+// PublisherFreshness tells how current the stored data of a publisher is
+type PublisherFreshness struct {
+	LastImportedAt *time.Time `json:"last_imported_at,omitempty"` // Last bulk import that included the publisher, changed or not
+	LastChangedAt  *time.Time `json:"last_changed_at,omitempty"`  // Most recent snapshot
+}
+
+// PublisherHistoryQuery selects the snapshots of a publisher
+type PublisherHistoryQuery struct {
+	From  *time.Time
+	To    *time.Time
+	Limit int
+}
+
+// Normalize applies the default and maximum limit and checks the time range
+func (q *PublisherHistoryQuery) Normalize() error {
+	if q.From != nil && q.To != nil && q.To.Before(*q.From) {
+		return fmt.Errorf("to must not be before from")
+	}
+	if q.Limit < 1 {
+		q.Limit = DefaultPublisherHistoryLimit
+	}
+	if q.Limit > MaxPublisherHistoryLimit {
+		q.Limit = MaxPublisherHistoryLimit
+	}
+	return nil
+}
+
+// PublisherHistoryResponse is the snapshot time series of a publisher
+type PublisherHistoryResponse struct {
+	Domain    string                        `json:"domain"`
+	Freshness PublisherFreshness            `json:"freshness"`
+	Snapshots []*AnalyticsPublisherSnapshot `json:"snapshots"` // Oldest first
+	Changes   []*PublisherSnapshotDiff      `json:"changes"`   // Between consecutive snapshots, oldest first
+}
+
+// MetricChange is the change of a numeric metric between two snapshots
+type MetricChange struct {
+	Previous float64  `json:"previous"`
+	Current  float64  `json:"current"`
+	Delta    float64  `json:"delta"`
+	Percent  *float64 `json:"percent,omitempty"` // Relative change; omitted when the previous value is 0
+}
+
+// PublisherSnapshotDiff describes what changed between two snapshots of a publisher.
+// Unchanged metrics are omitted.
+type PublisherSnapshotDiff struct {
+	From             time.Time        `json:"from"`
+	To               time.Time        `json:"to"`
+	TrafficScore     *MetricChange    `json:"traffic_score,omitempty"`
+	Relevance        *MetricChange    `json:"relevance,omitempty"`
+	PartnerCount     *MetricChange    `json:"partner_count,omitempty"`
+	Known            *bool            `json:"known,omitempty"` // New value when it changed
+	Promotype        *PromotypeChange `json:"promotype,omitempty"`
+	NetworksAdded    []string         `json:"networks_added"`
+	NetworksRemoved  []string         `json:"networks_removed"`
+	VerticalsAdded   []string         `json:"verticals_added"`
+	VerticalsRemoved []string         `json:"verticals_removed"`
+	CountriesAdded   []string         `json:"countries_added"`
+	CountriesRemoved []string         `json:"countries_removed"`
+	Summary          []string         `json:"summary"` // Human readable changes, e.g. "traffic_score +18%"
+	Rising           bool             `json:"rising"`  // Traffic or partners grew by RisingGrowthPercent, or more networks joined than left
+}
+
+// PromotypeChange is a change of the publisher promotype
+type PromotypeChange struct {
+	Previous *string `json:"previous"`
+	Current  *string `json:"current"`
+}
+
+// HasChanges reports whether any tracked metric changed
+func (d *PublisherSnapshotDiff) HasChanges() bool {
+	return len(d.Summary) > 0
+}
+
+// DiffPublisherSnapshots compares a snapshot with an earlier one of the same publisher
+func DiffPublisherSnapshots(previous, current *AnalyticsPublisherSnapshot) *PublisherSnapshotDiff {
+	diff := &PublisherSnapshotDiff{
+		From:    previous.CapturedAt,
+		To:      current.CapturedAt,
+		Summary: make([]string, 0),
+	}
+
+	diff.TrafficScore = newMetricChange(previous.TrafficScore, current.TrafficScore)
+	diff.Relevance = newMetricChange(previous.Relevance, current.Relevance)
+	diff.PartnerCount = newMetricChange(float64(previous.PartnerCount), float64(current.PartnerCount))
+	if previous.Known != current.Known {
+		known := current.Known
+		diff.Known = &known
+	}
+	if stringValue(previous.Promotype) != stringValue(current.Promotype) {
+		diff.Promotype = &PromotypeChange{Previous: previous.Promotype, Current: current.Promotype}
+	}
+	diff.NetworksAdded, diff.NetworksRemoved = setDifference(previous.AffiliateNetworks, current.AffiliateNetworks)
+	diff.VerticalsAdded, diff.VerticalsRemoved = setDifference(previous.Verticals, current.Verticals)
+	diff.CountriesAdded, diff.CountriesRemoved = setDifference(previous.Countries, current.Countries)
+
+	diff.summarize()
+	diff.Rising = growth(diff.TrafficScore) >= RisingGrowthPercent ||
+		growth(diff.PartnerCount) >= RisingGrowthPercent ||
+		len(diff.NetworksAdded) > len(diff.NetworksRemoved)
+
+	return diff
+}
+
+// summarize fills Summary in a fixed order
+func (d *PublisherSnapshotDiff) summarize() {
+	if d.TrafficScore != nil {
+		d.Summary = append(d.Summary, "traffic_score "+formatMetricChange(d.TrafficScore))
+	}
+	if d.Relevance != nil {
+		d.Summary = append(d.Summary, "relevance "+formatMetricChange(d.Relevance))
+	}
+	if d.PartnerCount != nil {
+		if d.PartnerCount.Delta > 0 {
+			d.Summary = append(d.Summary, countPhrase("gained %d new partner%s", int(d.PartnerCount.Delta)))
+		} else {
+			d.Summary = append(d.Summary, countPhrase("lost %d partner%s", int(-d.PartnerCount.Delta)))
+		}
+	}
+	if len(d.NetworksAdded) > 0 {
+		d.Summary = append(d.Summary, countPhrase("joined %d new affiliate network%s", len(d.NetworksAdded)))
+	}
+	if len(d.NetworksRemoved) > 0 {
+		d.Summary = append(d.Summary, countPhrase("left %d affiliate network%s", len(d.NetworksRemoved)))
+	}
+	if len(d.VerticalsAdded) > 0 {
+		d.Summary = append(d.Summary, countPhrase("added %d vertical%s", len(d.VerticalsAdded)))
+	}
+	if len(d.VerticalsRemoved) > 0 {
+		d.Summary = append(d.Summary, countPhrase("dropped %d vertical%s", len(d.VerticalsRemoved)))
+	}
+	if len(d.CountriesAdded) > 0 {
+		d.Summary = append(d.Summary, countPhraseY("ranks in %d new countr%s", len(d.CountriesAdded)))
+	}
+	if len(d.CountriesRemoved) > 0 {
+		d.Summary = append(d.Summary, countPhraseY("no longer ranks in %d countr%s", len(d.CountriesRemoved)))
+	}
+	if d.Known != nil {
+		if *d.Known {
+			d.Summary = append(d.Summary, "became known")
+		} else {
+			d.Summary = append(d.Summary, "no longer known")
+		}
+	}
+	if d.Promotype != nil {
+		d.Summary = append(d.Summary, fmt.Sprintf("promotype changed from %s to %s",
+			promotypeLabel(d.Promotype.Previous), promotypeLabel(d.Promotype.Current)))
+	}
+}
+
+// MetricsHash identifies the tracked metrics of a snapshot; snapshots with equal hashes have no diff
+func (s *AnalyticsPublisherSnapshot) MetricsHash() string {
+	metrics, _ := json.Marshal([]interface{}{
+		s.TrafficScore, s.Relevance, s.Known, stringValue(s.Promotype), s.PartnerCount,
+		s.AffiliateNetworks, s.Verticals, s.Countries,
+	})
+	sum := sha256.Sum256(metrics)
+	return hex.EncodeToString(sum[:])
+}
+
+// newMetricChange returns the change between two values, or nil if they are equal
+// at the precision the columns are stored with
+func newMetricChange(previous, current float64) *MetricChange {
+	previous, current = math.Round(previous*100)/100, math.Round(current*100)/100
+	if previous == current {
+		return nil
+	}
+	change := &MetricChange{
+		Previous: previous,
+		Current:  current,
+		Delta:    math.Round((current-previous)*100) / 100,
+	}
+	if previous != 0 {
+		percent := math.Round((current-previous)/math.Abs(previous)*1000) / 10
+		change.Percent = &percent
+	}
+	return change
+}
+
+// growth returns the relative change in percent, treating growth from 0 as unbounded
+func growth(change *MetricChange) float64 {
+	switch {
+	case change == nil:
+		return 0
+	case change.Percent != nil:
+		return *change.Percent
+	case change.Delta > 0:
+		return math.Inf(1)
+	default:
+		return math.Inf(-1)
+	}
+}
+
+// formatMetricChange renders a change as "+18%", or as the signed delta when there is no percentage
+func formatMetricChange(change *MetricChange) string {
+	if change.Percent == nil {
+		return signed(change.Delta)
+	}
+	if math.Abs(*change.Percent) < 1 {
+		return fmt.Sprintf("%+.1f%%", *change.Percent)
+	}
+	return fmt.Sprintf("%+.0f%%", *change.Percent)
+}
+
+func signed(value float64) string {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if value > 0 {
+		return "+" + formatted
+	}
+	return formatted
+}
+
+// countPhrase formats a count with an "s" plural suffix
+func countPhrase(format string, n int) string {
+	suffix := "s"
+	if n == 1 {
+		suffix = ""
+	}
+	return fmt.Sprintf(format, n, suffix)
+}
+
+// countPhraseY formats a count for words ending in "y"/"ies"
+func countPhraseY(format string, n int) string {
+	suffix := "ies"
+	if n == 1 {
+		suffix = "y"
+	}
+	return fmt.Sprintf(format, n, suffix)
+}
+
+func promotypeLabel(promotype *string) string {
+	if promotype == nil || *promotype == "" {
+		return "none"
+	}
+	return *promotype
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// setDifference returns the values only in current and the values only in previous
+func setDifference(previous, current []string) (added, removed []string) {
+	added, removed = make([]string, 0), make([]string, 0)
+	inPrevious := make(map[string]bool, len(previous))
+	for _, value := range previous {
+		inPrevious[value] = true
+	}
+	inCurrent := make(map[string]bool, len(current))
+	for _, value := range current {
+		inCurrent[value] = true
+		if !inPrevious[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range previous {
+		if !inCurrent[value] {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffPublisherSnapshots(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	blog, coupon := "blog", "coupon"
+	previous := &AnalyticsPublisherSnapshot{
+		TrafficScore:      50,
+		Relevance:         10,
+		Promotype:         &blog,
+		PartnerCount:      100,
+		AffiliateNetworks: []string{"Awin", "CJ"},
+		Verticals:         []string{"Shopping"},
+		Countries:         []string{"GB", "US"},
+		CapturedAt:        day,
+	}
+	current := &AnalyticsPublisherSnapshot{
+		TrafficScore:      59,
+		Relevance:         10,
+		Known:             true,
+		Promotype:         &coupon,
+		PartnerCount:      99,
+		AffiliateNetworks: []string{"Awin", "Impact", "Rakuten", "ShareASale"},
+		Verticals:         []string{"Shopping"},
+		Countries:         []string{"US"},
+		CapturedAt:        day.AddDate(0, 0, 7),
+	}
+
+	diff := DiffPublisherSnapshots(previous, current)
+
+	if diff.TrafficScore == nil || diff.TrafficScore.Delta != 9 || diff.TrafficScore.Percent == nil || *diff.TrafficScore.Percent != 18 {
+		t.Errorf("TrafficScore = %+v, want +9 (18%%)", diff.TrafficScore)
+	}
+	if diff.Relevance != nil {
+		t.Errorf("Relevance = %+v, want nil", diff.Relevance)
+	}
+	if !reflect.DeepEqual(diff.NetworksAdded, []string{"Impact", "Rakuten", "ShareASale"}) || !reflect.DeepEqual(diff.NetworksRemoved, []string{"CJ"}) {
+		t.Errorf("networks added %v removed %v", diff.NetworksAdded, diff.NetworksRemoved)
+	}
+	if len(diff.VerticalsAdded) != 0 || len(diff.VerticalsRemoved) != 0 {
+		t.Errorf("verticals added %v removed %v, want none", diff.VerticalsAdded, diff.VerticalsRemoved)
+	}
+
+	wantSummary := []string{
+		"traffic_score +18%",
+		"lost 1 partner",
+		"joined 3 new affiliate networks",
+		"left 1 affiliate network",
+		"no longer ranks in 1 country",
+		"became known",
+		"promotype changed from blog to coupon",
+	}
+	if !reflect.DeepEqual(diff.Summary, wantSummary) {
+		t.Errorf("Summary = %q, want %q", diff.Summary, wantSummary)
+	}
+	if !diff.Rising {
+		t.Errorf("Rising = false, want true")
+	}
+	if !diff.From.Equal(day) || !diff.To.Equal(current.CapturedAt) {
+		t.Errorf("From/To = %v/%v", diff.From, diff.To)
+	}
+}
+
+func TestDiffPublisherSnapshots_NoChange(t *testing.T) {
+	snapshot := &AnalyticsPublisherSnapshot{TrafficScore: 12.344, PartnerCount: 3, AffiliateNetworks: []string{"CJ"}}
+	same := &AnalyticsPublisherSnapshot{TrafficScore: 12.341, PartnerCount: 3, AffiliateNetworks: []string{"CJ"}}
+
+	diff := DiffPublisherSnapshots(snapshot, same)
+	if diff.HasChanges() || diff.Rising {
+		t.Errorf("diff = %+v, want no changes", diff)
+	}
+	if snapshot.MetricsHash() == (&AnalyticsPublisherSnapshot{TrafficScore: 13}).MetricsHash() {
+		t.Errorf("MetricsHash() equal for different metrics")
+	}
+}
+
+func TestDiffPublisherSnapshots_GrowthFromZero(t *testing.T) {
+	diff := DiffPublisherSnapshots(&AnalyticsPublisherSnapshot{}, &AnalyticsPublisherSnapshot{TrafficScore: 2.5, Relevance: 0.5})
+
+	if diff.TrafficScore == nil || diff.TrafficScore.Percent != nil {
+		t.Errorf("TrafficScore = %+v, want change without percent", diff.TrafficScore)
+	}
+	wantSummary := []string{"traffic_score +2.5", "relevance +0.5"}
+	if !reflect.DeepEqual(diff.Summary, wantSummary) {
+		t.Errorf("Summary = %q, want %q", diff.Summary, wantSummary)
+	}
+	if !diff.Rising {
+		t.Errorf("Rising = false, want true")
+	}
+}
+
+func TestPublisherHistoryQuery_Normalize(t *testing.T) {
+	query := &PublisherHistoryQuery{Limit: 1000}
+	if err := query.Normalize(); err != nil || query.Limit != MaxPublisherHistoryLimit {
+		t.Errorf("Normalize() = %v, limit %d", err, query.Limit)
+	}
+
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, -1)
+	query = &PublisherHistoryQuery{From: &from, To: &to}
+	if err := query.Normalize(); err == nil {
+		t.Errorf("Normalize() with to before from expected error")
+	}
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Weights of the lookalike similarity components; they add up to 1
+const (
+	LookalikeWeightRelated   = 0.30 // One publisher lists the other as related
+	LookalikeWeightPartners  = 0.30 // Shared partner advertisers
+	LookalikeWeightVerticals = 0.25 // Overlap of scored verticals
+	LookalikeWeightCountries = 0.15 // Overlap of country audience shares
+)
+
+const (
+	// DefaultLookalikeLimit is the number of recommendations returned by default
+	DefaultLookalikeLimit = 20
+	// MaxLookalikeLimit is the maximum number of recommendations per request
+	MaxLookalikeLimit = 100
+)
+
+// LookalikeProfile holds the publisher features compared for lookalike recommendations.
+// A profile can describe a single publisher or be merged from several, e.g. a favorite list.
+type LookalikeProfile struct {
+	Domains   map[string]bool    // Publishers the profile was built from
+	Related   map[string]bool    // Related publisher domains
+	Partners  map[string]bool    // Partner advertiser domains
+	Verticals map[string]float64 // Vertical name -> weight in [0, 1]
+	Countries map[string]float64 // Lower case country code -> weight in [0, 1]
+}
+
+// NewLookalikeProfile extracts the comparable features of a publisher. Unparseable JSON
+// columns are treated as empty.
+func NewLookalikeProfile(p *AnalyticsPublisher) *LookalikeProfile {
+	profile := &LookalikeProfile{
+		Domains:   map[string]bool{strings.ToLower(p.Domain): true},
+		Related:   make(map[string]bool),
+		Partners:  make(map[string]bool),
+		Verticals: make(map[string]float64),
+		Countries: make(map[string]float64),
+	}
+
+	var domainList struct {
+		Value []string `json:"value"`
+	}
+	if p.RelatedPublishers != nil && json.Unmarshal([]byte(*p.RelatedPublishers), &domainList) == nil {
+		for _, related := range domainList.Value {
+			profile.Related[strings.ToLower(related)] = true
+		}
+	}
+	domainList.Value = nil
+	if p.Partners != nil && json.Unmarshal([]byte(*p.Partners), &domainList) == nil {
+		for _, partner := range domainList.Value {
+			profile.Partners[strings.ToLower(partner)] = true
+		}
+	}
+
+	if verticals, err := p.GetVerticalsV2(); err == nil && verticals != nil {
+		for _, vertical := range verticals.Value {
+			weight := float64(vertical.Score) / 100
+			if weight <= 0 || weight > 1 {
+				weight = 1
+			}
+			profile.Verticals[vertical.Name] = weight
+		}
+	}
+
+	if rankings, err := p.GetCountryRankings(); err == nil && rankings != nil {
+		for _, country := range rankings.Value {
+			if country.Score > 0 {
+				profile.Countries[strings.ToLower(country.CountryCode)] = math.Min(country.Score, 1)
+			}
+		}
+	}
+
+	return profile
+}
+
+// MergeLookalikeProfiles combines several publishers into one profile. Sets are united and
+// weights are averaged over all profiles, so features shared by many publishers weigh more.
+func MergeLookalikeProfiles(profiles []*LookalikeProfile) *LookalikeProfile {
+	merged := &LookalikeProfile{
+		Domains:   make(map[string]bool),
+		Related:   make(map[string]bool),
+		Partners:  make(map[string]bool),
+		Verticals: make(map[string]float64),
+		Countries: make(map[string]float64),
+	}
+	if len(profiles) == 0 {
+		return merged
+	}
+
+	n := float64(len(profiles))
+	for _, profile := range profiles {
+		for domain := range profile.Domains {
+			merged.Domains[domain] = true
+		}
+		for related := range profile.Related {
+			merged.Related[related] = true
+		}
+		for partner := range profile.Partners {
+			merged.Partners[partner] = true
+		}
+		for vertical, weight := range profile.Verticals {
+			merged.Verticals[vertical] += weight / n
+		}
+		for country, weight := range profile.Countries {
+			merged.Countries[country] += weight / n
+		}
+	}
+	return merged
+}
+
+// LookalikeMatch explains why a candidate is similar to the seed
+type LookalikeMatch struct {
+	Score           float64  `json:"score"` // 0 to 1
+	Related         bool     `json:"related"`
+	SharedPartners  int      `json:"shared_partners"`
+	SharedVerticals []string `json:"shared_verticals"`
+	SharedCountries []string `json:"shared_countries"`
+}
+
+// Similarity scores how closely a candidate publisher resembles the seed profile
+func (seed *LookalikeProfile) Similarity(candidate *LookalikeProfile) LookalikeMatch {
+	match := LookalikeMatch{
+		SharedVerticals: make([]string, 0),
+		SharedCountries: make([]string, 0),
+	}
+
+	for domain := range candidate.Domains {
+		if seed.Related[domain] {
+			match.Related = true
+		}
+	}
+	for domain := range seed.Domains {
+		if candidate.Related[domain] {
+			match.Related = true
+		}
+	}
+
+	// Cosine overlap so that publishers with thousands of partners do not dominate
+	for partner := range candidate.Partners {
+		if seed.Partners[partner] {
+			match.SharedPartners++
+		}
+	}
+	var partnerScore float64
+	if len(seed.Partners) > 0 && len(candidate.Partners) > 0 {
+		partnerScore = float64(match.SharedPartners) / math.Sqrt(float64(len(seed.Partners))*float64(len(candidate.Partners)))
+	}
+
+	verticalScore, sharedVerticals := weightedJaccard(seed.Verticals, candidate.Verticals)
+	countryScore, sharedCountries := weightedJaccard(seed.Countries, candidate.Countries)
+	match.SharedVerticals = append(match.SharedVerticals, sharedVerticals...)
+	for _, country := range sharedCountries {
+		match.SharedCountries = append(match.SharedCountries, strings.ToUpper(country))
+	}
+
+	if match.Related {
+		match.Score += LookalikeWeightRelated
+	}
+	match.Score += LookalikeWeightPartners*partnerScore +
+		LookalikeWeightVerticals*verticalScore +
+		LookalikeWeightCountries*countryScore
+	match.Score = math.Round(match.Score*10000) / 10000
+
+	return match
+}
+
+// weightedJaccard returns sum(min)/sum(max) over the keys of both maps and the shared keys, sorted
+func weightedJaccard(a, b map[string]float64) (float64, []string) {
+	var minSum, maxSum float64
+	var shared []string
+	for key, weightA := range a {
+		weightB := b[key]
+		minSum += math.Min(weightA, weightB)
+		maxSum += math.Max(weightA, weightB)
+		if weightB > 0 {
+			shared = append(shared, key)
+		}
+	}
+	for key, weightB := range b {
+		if _, ok := a[key]; !ok {
+			maxSum += weightB
+		}
+	}
+	sort.Strings(shared)
+
+	if maxSum == 0 {
+		return 0, shared
+	}
+	return minSum / maxSum, shared
+}
+
+// SimilarPublisher is a lookalike recommendation
+type SimilarPublisher struct {
+	Publisher *AnalyticsPublisherResponse `json:"publisher"`
+	Match     LookalikeMatch              `json:"match"`
+}
+
+// SimilarPublishersResponse lists lookalike recommendations, best match first
+type SimilarPublishersResponse struct {
+	Seeds []string            `json:"seeds"` // Publisher domains the recommendations are based on
+	Data  []*SimilarPublisher `json:"data"`
+}
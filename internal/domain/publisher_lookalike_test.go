@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func lookalikePublisher(domain, related, partners, verticals, countries string) *AnalyticsPublisher {
+	return &AnalyticsPublisher{
+		Domain:            domain,
+		RelatedPublishers: strPtr(related),
+		Partners:          strPtr(partners),
+		VerticalsV2:       strPtr(verticals),
+		CountryRankings:   strPtr(countries),
+	}
+}
+
+func TestLookalikeProfile_Similarity(t *testing.T) {
+	seed := NewLookalikeProfile(lookalikePublisher("techradar.com",
+		`{"value": ["tomsguide.com"]}`,
+		`{"value": ["amazon.com", "bestbuy.com", "dell.com", "hp.com"]}`,
+		`{"value": [{"name": "Technology / Computers", "score": 100}, {"name": "Technology / Software", "score": 50}]}`,
+		`{"value": [{"countryCode": "us", "score": 0.6}, {"countryCode": "gb", "score": 0.4}]}`,
+	))
+
+	identical := NewLookalikeProfile(lookalikePublisher("tomsguide.com",
+		`{"value": []}`,
+		`{"value": ["amazon.com", "bestbuy.com", "dell.com", "hp.com"]}`,
+		`{"value": [{"name": "Technology / Computers", "score": 100}, {"name": "Technology / Software", "score": 50}]}`,
+		`{"value": [{"countryCode": "us", "score": 0.6}, {"countryCode": "gb", "score": 0.4}]}`,
+	))
+	match := seed.Similarity(identical)
+	if match.Score != 1 {
+		t.Errorf("identical related publisher score = %v, want 1", match.Score)
+	}
+	if !match.Related || match.SharedPartners != 4 {
+		t.Errorf("match = %+v, want related with 4 shared partners", match)
+	}
+	if !reflect.DeepEqual(match.SharedCountries, []string{"GB", "US"}) {
+		t.Errorf("SharedCountries = %v, want [GB US]", match.SharedCountries)
+	}
+
+	partial := NewLookalikeProfile(lookalikePublisher("recipes.example",
+		`{"value": []}`,
+		`{"value": ["amazon.com", "walmart.com", "target.com", "kroger.com"]}`,
+		`{"value": [{"name": "Food & Drink", "score": 100}]}`,
+		`{"value": [{"countryCode": "us", "score": 0.9}]}`,
+	))
+	partialMatch := seed.Similarity(partial)
+	if partialMatch.Related || partialMatch.SharedPartners != 1 || len(partialMatch.SharedVerticals) != 0 {
+		t.Errorf("partial match = %+v", partialMatch)
+	}
+	if partialMatch.Score <= 0 || partialMatch.Score >= match.Score {
+		t.Errorf("partial score = %v, want between 0 and %v", partialMatch.Score, match.Score)
+	}
+
+	unrelated := NewLookalikeProfile(&AnalyticsPublisher{Domain: "empty.example"})
+	if score := seed.Similarity(unrelated).Score; score != 0 {
+		t.Errorf("publisher without data score = %v, want 0", score)
+	}
+}
+
+func TestMergeLookalikeProfiles(t *testing.T) {
+	a := NewLookalikeProfile(lookalikePublisher("a.com", `{"value": ["x.com"]}`, `{"value": ["shop.com"]}`,
+		`{"value": [{"name": "Fashion", "score": 100}]}`, `{"value": []}`))
+	b := NewLookalikeProfile(lookalikePublisher("b.com", `{"value": ["y.com"]}`, `{"value": []}`,
+		`{"value": [{"name": "Fashion", "score": 100}, {"name": "Beauty", "score": 100}]}`, `{"value": []}`))
+
+	merged := MergeLookalikeProfiles([]*LookalikeProfile{a, b})
+	if !merged.Domains["a.com"] || !merged.Domains["b.com"] || !merged.Related["x.com"] || !merged.Related["y.com"] {
+		t.Errorf("merged sets = %+v", merged)
+	}
+	if merged.Verticals["Fashion"] != 1 || merged.Verticals["Beauty"] != 0.5 {
+		t.Errorf("merged verticals = %v, want Fashion 1 and Beauty 0.5", merged.Verticals)
+	}
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxMessageTemplatesPerOrganization is the maximum number of message templates per organization
+	MaxMessageTemplatesPerOrganization = 200
+	// MaxMessageTemplateBodyLength is the maximum length of a template body, matching messages
+	MaxMessageTemplateBodyLength = 5000
+)
+
+// PublisherMessageTemplate is a reusable message of an organization. The subject and body use
+// text/template syntax with the MessageMergeFields, e.g. "Hi {{.Domain}} team".
+type PublisherMessageTemplate struct {
+	TemplateID      int64     `json:"template_id" db:"template_id"`
+	OrganizationID  int64     `json:"organization_id" db:"organization_id"`
+	Name            string    `json:"name" db:"name"`
+	Subject         *string   `json:"subject,omitempty" db:"subject"` // Used when the template starts a conversation
+	Body            string    `json:"body" db:"body"`
+	CreatedByUserID *string   `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateMessageTemplateRequest represents the request to create a message template
+type CreateMessageTemplateRequest struct {
+	Name    string  `json:"name" binding:"required,max=255"`
+	Subject *string `json:"subject,omitempty" binding:"omitempty,max=500"`
+	Body    string  `json:"body" binding:"required,max=5000"`
+}
+
+// Validate validates the CreateMessageTemplateRequest
+func (r *CreateMessageTemplateRequest) Validate() error {
+	if err := validateStringLength(strings.TrimSpace(r.Name), 1, 255); err != nil {
+		return fmt.Errorf("name must be 1-255 characters")
+	}
+	if r.Subject != nil {
+		if err := validateStringLength(strings.TrimSpace(*r.Subject), 1, 500); err != nil {
+			return fmt.Errorf("subject must be 1-500 characters")
+		}
+	}
+	if err := validateStringLength(strings.TrimSpace(r.Body), 1, MaxMessageTemplateBodyLength); err != nil {
+		return fmt.Errorf("body must be 1-%d characters", MaxMessageTemplateBodyLength)
+	}
+	return nil
+}
+
+// UpdateMessageTemplateRequest represents the request to update a message template. An empty
+// subject removes it.
+type UpdateMessageTemplateRequest struct {
+	Name    *string `json:"name,omitempty" binding:"omitempty,max=255"`
+	Subject *string `json:"subject,omitempty" binding:"omitempty,max=500"`
+	Body    *string `json:"body,omitempty" binding:"omitempty,max=5000"`
+}
+
+// Validate validates the UpdateMessageTemplateRequest
+func (r *UpdateMessageTemplateRequest) Validate() error {
+	if r.Name != nil {
+		if err := validateStringLength(strings.TrimSpace(*r.Name), 1, 255); err != nil {
+			return fmt.Errorf("name must be 1-255 characters")
+		}
+	}
+	if r.Subject != nil && len(strings.TrimSpace(*r.Subject)) > 500 {
+		return fmt.Errorf("subject must be at most 500 characters")
+	}
+	if r.Body != nil {
+		if err := validateStringLength(strings.TrimSpace(*r.Body), 1, MaxMessageTemplateBodyLength); err != nil {
+			return fmt.Errorf("body must be 1-%d characters", MaxMessageTemplateBodyLength)
+		}
+	}
+	return nil
+}
+
+// RenderMessageTemplateRequest selects the publisher a template is previewed for. Without a
+// conversation or publisher domain the template is rendered with sample data.
+type RenderMessageTemplateRequest struct {
+	ConversationID  *int64  `json:"conversation_id,omitempty"`
+	PublisherDomain *string `json:"publisher_domain,omitempty"`
+	ListID          *int64  `json:"list_id,omitempty"` // Fills ListName and Notes for a publisher domain
+}
+
+// RenderedMessageTemplate is a template with its merge fields substituted
+type RenderedMessageTemplate struct {
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// MessageMergeFields are the fields available to message templates and outreach sequences
+type MessageMergeFields struct {
+	Domain           string
+	Description      string
+	ContactEmail     string
+	TopCountry       string // Country code with the most traffic
+	Vertical         string // Highest ranked vertical
+	TrafficScore     float64
+	Promotype        string
+	OrganizationName string // Sending organization
+	ListName         string
+	Notes            string // Notes of the publisher in the list
+}
+
+// NewMessageMergeFields fills the publisher fields from analytics data; publisher may be nil
+func NewMessageMergeFields(publisherDomain string, publisher *AnalyticsPublisher) MessageMergeFields {
+	fields := MessageMergeFields{Domain: publisherDomain}
+	if publisher == nil {
+		return fields
+	}
+
+	if publisher.Description != nil {
+		fields.Description = *publisher.Description
+	}
+	if publisher.Promotype != nil {
+		fields.Promotype = *publisher.Promotype
+	}
+	fields.TrafficScore = publisher.TrafficScore
+	if email, err := publisher.PrimaryContactEmail(); err == nil {
+		fields.ContactEmail = email
+	}
+	if rankings, err := publisher.GetCountryRankings(); err == nil && rankings != nil && rankings.HighestValue != nil {
+		fields.TopCountry = rankings.HighestValue.CountryCode
+	}
+	if verticals, err := publisher.GetVerticalsV2(); err == nil && verticals != nil {
+		best := -1
+		for _, vertical := range verticals.Value {
+			if best == -1 || vertical.Rank < best {
+				fields.Vertical, best = vertical.Name, vertical.Rank
+			}
+		}
+	}
+	return fields
+}
+
+// SampleMessageMergeFields returns merge fields used to check templates before they are saved
+func SampleMessageMergeFields() MessageMergeFields {
+	return MessageMergeFields{
+		Domain:           "example.com",
+		Description:      "An example publisher",
+		ContactEmail:     "partners@example.com",
+		TopCountry:       "US",
+		Vertical:         "Shopping",
+		TrafficScore:     50,
+		Promotype:        "content",
+		OrganizationName: "Example Advertiser",
+		ListName:         "Prospects",
+		Notes:            "Great fit",
+	}
+}
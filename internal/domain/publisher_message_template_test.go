@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateMessageTemplateRequest_Validate(t *testing.T) {
+	subject, blank := "Partnering with {{.OrganizationName}}", " "
+
+	tests := []struct {
+		name    string
+		req     CreateMessageTemplateRequest
+		wantErr bool
+	}{
+		{name: "body only", req: CreateMessageTemplateRequest{Name: "Intro", Body: "Hi {{.Domain}} team"}},
+		{name: "with subject", req: CreateMessageTemplateRequest{Name: "Intro", Subject: &subject, Body: "Hi"}},
+		{name: "blank name", req: CreateMessageTemplateRequest{Name: blank, Body: "Hi"}, wantErr: true},
+		{name: "blank subject", req: CreateMessageTemplateRequest{Name: "Intro", Subject: &blank, Body: "Hi"}, wantErr: true},
+		{name: "blank body", req: CreateMessageTemplateRequest{Name: "Intro", Body: blank}, wantErr: true},
+		{name: "body too long", req: CreateMessageTemplateRequest{Name: "Intro", Body: strings.Repeat("a", MaxMessageTemplateBodyLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateMessageTemplateRequest_Validate(t *testing.T) {
+	empty, blank := "", " "
+
+	if err := (&UpdateMessageTemplateRequest{Subject: &empty}).Validate(); err != nil {
+		t.Errorf("Validate() removing the subject error = %v", err)
+	}
+	if err := (&UpdateMessageTemplateRequest{Body: &blank}).Validate(); err == nil {
+		t.Error("Validate() with a blank body should fail")
+	}
+	if err := (&UpdateMessageTemplateRequest{Name: &empty}).Validate(); err == nil {
+		t.Error("Validate() with an empty name should fail")
+	}
+}
+
+func TestNewMessageMergeFields(t *testing.T) {
+	fields := NewMessageMergeFields("example.com", nil)
+	if fields.Domain != "example.com" || fields.ContactEmail != "" {
+		t.Errorf("NewMessageMergeFields() without analytics = %+v", fields)
+	}
+
+	description := "Deals and coupons"
+	fields = NewMessageMergeFields("example.com", &AnalyticsPublisher{Description: &description, TrafficScore: 42})
+	if fields.Description != description || fields.TrafficScore != 42 {
+		t.Errorf("NewMessageMergeFields() = %+v", fields)
+	}
+}
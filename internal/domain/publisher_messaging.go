@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -26,6 +27,46 @@ const (
 	MessageTypeNotification = "notification"
 )
 
+// Email delivery status constants, stored under MessageMetadataEmailDelivery in message metadata
+const (
+	EmailDeliveryStatusSent    = "sent"
+	EmailDeliveryStatusFailed  = "failed"
+	EmailDeliveryStatusSkipped = "skipped" // No contact email is known for the publisher
+)
+
+// MessageMetadataEmailDelivery is the metadata key holding the email delivery details of a message
+const MessageMetadataEmailDelivery = "email_delivery"
+
+// Metadata keys of messages received by email
+const (
+	MessageMetadataEmail       = "email"       // Sender, subject and threading headers of the inbound email
+	MessageMetadataAttachments = "attachments" // []MessageAttachment stored in object storage
+)
+
+const (
+	// MaxMessageAttachments is the maximum number of files attached to an organization message
+	MaxMessageAttachments = 10
+	// MaxMessageAttachmentBytes is the maximum size of a single attached file
+	MaxMessageAttachmentBytes = 10 * 1024 * 1024
+	// MaxMessageSearchQueryLength is the maximum length of a message search query
+	MaxMessageSearchQueryLength = 200
+)
+
+// MessageAttachment describes a file attached to a message; the content is kept in object storage
+type MessageAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	StorageKey  string `json:"storage_key"`
+}
+
+// MessageAttachmentUpload is a file uploaded with an organization message
+type MessageAttachmentUpload struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
 // PublisherConversation represents a conversation session between an organization and a publisher
 type PublisherConversation struct {
 	ConversationID  int64      `json:"conversation_id" db:"conversation_id"`
@@ -49,6 +90,7 @@ type PublisherConversation struct {
 	
 	// Computed fields
 	MessageCount int `json:"message_count,omitempty" db:"-"`
+	UnreadCount  int `json:"unread_count" db:"-"` // Publisher messages the requesting user has not read
 }
 
 // PublisherMessage represents an individual message within a conversation
@@ -62,23 +104,53 @@ type PublisherMessage struct {
 	ExternalMessageID *string                `json:"external_message_id,omitempty" db:"external_message_id"`
 	Metadata          map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
 	SentAt            time.Time              `json:"sent_at" db:"sent_at"`
+
+	// Read state for the requesting user. Only publisher messages can be unread.
+	Unread bool       `json:"unread" db:"-"`
+	ReadAt *time.Time `json:"read_at,omitempty" db:"-"`
+}
+
+// GetAttachments returns the attachments recorded in the message metadata
+func (m *PublisherMessage) GetAttachments() []MessageAttachment {
+	raw, ok := m.Metadata[MessageMetadataAttachments]
+	if !ok {
+		return nil
+	}
+
+	// Metadata loaded from the database holds generic JSON values
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var attachments []MessageAttachment
+	if err := json.Unmarshal(data, &attachments); err != nil {
+		return nil
+	}
+	return attachments
 }
 
 // Request/Response models
 
-// CreateConversationRequest represents the request to start a new conversation with a publisher
+// CreateConversationRequest represents the request to start a new conversation with a publisher.
+// With a template, the subject and initial message default to the rendered template.
 type CreateConversationRequest struct {
 	PublisherDomain string  `json:"publisher_domain" binding:"required,min=1,max=255"`
 	ListID          *int64  `json:"list_id,omitempty"`
-	Subject         string  `json:"subject" binding:"required,min=1,max=500"`
-	InitialMessage  string  `json:"initial_message" binding:"required,min=1,max=5000"`
+	Subject         string  `json:"subject" binding:"max=500"`
+	InitialMessage  string  `json:"initial_message" binding:"max=5000"`
+	TemplateID      *int64  `json:"template_id,omitempty"`
 }
 
-// SendMessageRequest represents the request to send a message in a conversation
+// SendMessageRequest represents the request to send a message in a conversation. With a template,
+// the content defaults to the rendered template body.
 type SendMessageRequest struct {
-	Content     string                 `json:"content" binding:"required,min=1,max=5000"`
+	Content     string                 `json:"content" binding:"max=5000"`
 	MessageType *string                `json:"message_type,omitempty" binding:"omitempty,oneof=text system notification"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	TemplateID  *int64                 `json:"template_id,omitempty"`
+
+	// Files uploaded with a multipart request
+	Attachments []MessageAttachmentUpload `json:"-"`
 }
 
 // AddExternalMessageRequest represents the request to add a message from an external service
@@ -104,6 +176,37 @@ type ConversationListResponse struct {
 	Total         int                     `json:"total"`
 	Page          int                     `json:"page"`
 	PageSize      int                     `json:"page_size"`
+	UnreadTotal   int                     `json:"unread_total"` // Unread publisher messages across the organization's conversations
+}
+
+// ConversationListFilter selects the conversations listed for an organization user
+type ConversationListFilter struct {
+	Status     string
+	UnreadOnly bool
+	UserID     string // Read state is tracked per user
+}
+
+// MarkConversationReadRequest marks the publisher messages of a conversation as read, up to
+// a message or all of them
+type MarkConversationReadRequest struct {
+	UpToMessageID *int64 `json:"up_to_message_id,omitempty"`
+}
+
+// MessageSearchResult is a message matching a search, with its conversation
+type MessageSearchResult struct {
+	Message         PublisherMessage `json:"message"`
+	Subject         string           `json:"subject"`
+	PublisherDomain string           `json:"publisher_domain"`
+	Headline        string           `json:"headline"` // Excerpt with the matching terms wrapped in <b></b>
+	Rank            float64          `json:"rank"`
+}
+
+// MessageSearchResponse represents the response for a message search
+type MessageSearchResponse struct {
+	Results  []MessageSearchResult `json:"results"`
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
 }
 
 // ConversationWithMessagesResponse represents a conversation with its messages
@@ -133,6 +236,14 @@ func (r *SendMessageRequest) Validate() error {
 	if err := validateStringLength(r.Content, 1, 5000); err != nil {
 		return err
 	}
+	if len(r.Attachments) > MaxMessageAttachments {
+		return fmt.Errorf("%w: at most %d attachments are allowed", ErrInvalidInput, MaxMessageAttachments)
+	}
+	for _, attachment := range r.Attachments {
+		if len(attachment.Data) == 0 || len(attachment.Data) > MaxMessageAttachmentBytes {
+			return fmt.Errorf("%w: attachment %s must be 1 byte to %d MB", ErrInvalidInput, attachment.Filename, MaxMessageAttachmentBytes/(1024*1024))
+		}
+	}
 	if r.MessageType != nil {
 		return validateMessageType(*r.MessageType)
 	}
@@ -0,0 +1,50 @@
+package domain
+
+import "testing"
+
+func TestPublisherMessage_GetAttachments(t *testing.T) {
+	// Metadata as loaded from the JSONB column
+	message := &PublisherMessage{Metadata: map[string]interface{}{
+		MessageMetadataAttachments: []interface{}{
+			map[string]interface{}{"filename": "media-kit.pdf", "content_type": "application/pdf", "size": float64(1024), "storage_key": "publisher-messages/1/ab/media-kit.pdf"},
+		},
+	}}
+
+	attachments := message.GetAttachments()
+	if len(attachments) != 1 {
+		t.Fatalf("GetAttachments() returned %d attachments, want 1", len(attachments))
+	}
+	if attachments[0].Filename != "media-kit.pdf" || attachments[0].Size != 1024 || attachments[0].StorageKey != "publisher-messages/1/ab/media-kit.pdf" {
+		t.Errorf("GetAttachments() = %+v", attachments[0])
+	}
+
+	if got := (&PublisherMessage{}).GetAttachments(); got != nil {
+		t.Errorf("GetAttachments() without metadata = %+v, want nil", got)
+	}
+}
+
+func TestStatusAfterPublisherReply(t *testing.T) {
+	tests := []struct {
+		current     string
+		want        string
+		wantChanged bool
+	}{
+		{PublisherStatusAdded, PublisherStatusContacted, true},
+		{PublisherStatusContacted, PublisherStatusAccepted, true},
+		{PublisherStatusAccepted, PublisherStatusAccepted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			got, changed := StatusAfterPublisherReply(tt.current)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf("StatusAfterPublisherReply(%q) = %q, %v, want %q, %v", tt.current, got, changed, tt.want, tt.wantChanged)
+			}
+			if changed {
+				if err := ValidateStatusTransition(tt.current, got); err != nil {
+					t.Errorf("transition %s -> %s is not allowed: %v", tt.current, got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pipeline stage outcome constants. Open stages are still in progress; won and lost stages end
+// the outreach to a publisher.
+const (
+	PipelineStageOutcomeOpen = "open"
+	PipelineStageOutcomeWon  = "won"
+	PipelineStageOutcomeLost = "lost"
+)
+
+const (
+	// MaxPipelineStages is the maximum number of pipeline stages per organization
+	MaxPipelineStages = 20
+	// PipelineReminderBatchSize is the number of due reminders sent per scheduler tick
+	PipelineReminderBatchSize = 100
+	// DefaultPipelineHistoryLimit is the number of stage changes returned by default
+	DefaultPipelineHistoryLimit = 50
+	// MaxPipelineHistoryLimit is the maximum number of stage changes per request
+	MaxPipelineHistoryLimit = 500
+)
+
+var (
+	pipelineStageKeyPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{0,49}$`)
+	pipelineStageColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// PipelineStage is a configurable outreach stage of an organization's publisher pipeline
+type PipelineStage struct {
+	StageID        int64     `json:"stage_id" db:"stage_id"`
+	OrganizationID int64     `json:"organization_id" db:"organization_id"`
+	Key            string    `json:"key" db:"key"` // Stable identifier, e.g. "negotiating"
+	Name           string    `json:"name" db:"name"`
+	Position       int       `json:"position" db:"position"` // Board column order, starting at 0
+	Outcome        string    `json:"outcome" db:"outcome"`   // open, won or lost
+	Color          *string   `json:"color,omitempty" db:"color"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultPipelineStages returns the stages an organization starts with
+func DefaultPipelineStages(organizationID int64) []*PipelineStage {
+	defaults := []struct{ key, name, outcome string }{
+		{"researching", "Researching", PipelineStageOutcomeOpen},
+		{"contacted", "Contacted", PipelineStageOutcomeOpen},
+		{"negotiating", "Negotiating", PipelineStageOutcomeOpen},
+		{"signed", "Signed", PipelineStageOutcomeWon},
+		{"rejected", "Rejected", PipelineStageOutcomeLost},
+	}
+
+	stages := make([]*PipelineStage, len(defaults))
+	for i, d := range defaults {
+		stages[i] = &PipelineStage{
+			OrganizationID: organizationID,
+			Key:            d.key,
+			Name:           d.name,
+			Position:       i,
+			Outcome:        d.outcome,
+		}
+	}
+	return stages
+}
+
+// Validate validates a pipeline stage
+func (s *PipelineStage) Validate() error {
+	if !pipelineStageKeyPattern.MatchString(s.Key) {
+		return fmt.Errorf("key must be 1-50 lower case letters, digits or underscores")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(s.Name) > 100 {
+		return fmt.Errorf("name must be at most 100 characters")
+	}
+	if !IsValidPipelineStageOutcome(s.Outcome) {
+		return fmt.Errorf("invalid outcome: %s (must be open, won or lost)", s.Outcome)
+	}
+	if s.Color != nil && !pipelineStageColorPattern.MatchString(*s.Color) {
+		return fmt.Errorf("color must be a hex color like #1a2b3c")
+	}
+	return nil
+}
+
+// IsValidPipelineStageOutcome reports whether outcome is a known stage outcome
+func IsValidPipelineStageOutcome(outcome string) bool {
+	switch outcome {
+	case PipelineStageOutcomeOpen, PipelineStageOutcomeWon, PipelineStageOutcomeLost:
+		return true
+	default:
+		return false
+	}
+}
+
+// CreatePipelineStageRequest represents the request to add a pipeline stage
+type CreatePipelineStageRequest struct {
+	Key      string  `json:"key" binding:"required,max=50"`
+	Name     string  `json:"name" binding:"required,max=100"`
+	Outcome  string  `json:"outcome" binding:"required,oneof=open won lost"`
+	Color    *string `json:"color,omitempty"`
+	Position *int    `json:"position,omitempty"` // Defaults to the end of the pipeline
+}
+
+// UpdatePipelineStageRequest represents the request to rename or recolor a pipeline stage
+type UpdatePipelineStageRequest struct {
+	Name    *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	Outcome *string `json:"outcome,omitempty" binding:"omitempty,oneof=open won lost"`
+	Color   *string `json:"color,omitempty"`
+}
+
+// ReorderPipelineStagesRequest lists every stage ID of the organization in the new board order
+type ReorderPipelineStagesRequest struct {
+	StageIDs []int64 `json:"stage_ids" binding:"required,min=1"`
+}
+
+// MovePublisherStageRequest represents the request to move a list item to another stage
+type MovePublisherStageRequest struct {
+	StageID int64   `json:"stage_id" binding:"required"`
+	Note    *string `json:"note,omitempty" binding:"omitempty,max=1000"`
+}
+
+// UpdatePublisherFollowUpRequest sets the owner and next action of a list item; omitted fields are cleared
+type UpdatePublisherFollowUpRequest struct {
+	OwnerUserID    *string    `json:"owner_user_id,omitempty"`
+	NextActionAt   *time.Time `json:"next_action_at,omitempty"`
+	NextActionNote *string    `json:"next_action_note,omitempty" binding:"omitempty,max=1000"`
+}
+
+// Validate validates the MovePublisherStageRequest
+func (r *MovePublisherStageRequest) Validate() error {
+	if r.StageID <= 0 {
+		return fmt.Errorf("stage_id is required")
+	}
+	return validateOptionalStringLength(r.Note, 1000)
+}
+
+// Validate validates the UpdatePublisherFollowUpRequest
+func (r *UpdatePublisherFollowUpRequest) Validate() error {
+	if r.NextActionNote != nil && r.NextActionAt == nil {
+		return fmt.Errorf("next_action_note requires next_action_at")
+	}
+	return validateOptionalStringLength(r.NextActionNote, 1000)
+}
+
+// PipelineStageChange is an entry of a list item's stage history
+type PipelineStageChange struct {
+	ChangeID        int64     `json:"change_id" db:"change_id"`
+	ItemID          int64     `json:"item_id" db:"item_id"`
+	FromStageID     *int64    `json:"from_stage_id,omitempty" db:"from_stage_id"`
+	FromStageName   *string   `json:"from_stage_name,omitempty" db:"from_stage_name"`
+	ToStageID       *int64    `json:"to_stage_id,omitempty" db:"to_stage_id"` // Nil once the stage is deleted
+	ToStageName     string    `json:"to_stage_name" db:"to_stage_name"`
+	ChangedByUserID *string   `json:"changed_by_user_id,omitempty" db:"changed_by_user_id"` // Nil for automatic changes
+	Reason          string    `json:"reason" db:"reason"`
+	Note            *string   `json:"note,omitempty" db:"note"`
+	ChangedAt       time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// Stage change reason constants
+const (
+	PipelineChangeReasonCreated        = "created"         // Item added to a list
+	PipelineChangeReasonMoved          = "moved"           // Moved on the board
+	PipelineChangeReasonStatus         = "status"          // Set through the legacy status endpoint
+	PipelineChangeReasonPublisherReply = "publisher_reply" // The publisher answered outreach
+)
+
+// PipelineBoardQuery filters the items of a pipeline board
+type PipelineBoardQuery struct {
+	OwnerUserID *string
+	DueBefore   *time.Time // Only items with a next action due before this time
+}
+
+// PipelineBoardColumn is one stage of a pipeline board with its items
+type PipelineBoardColumn struct {
+	Stage *PipelineStage               `json:"stage"`
+	Items []*FavoritePublisherListItem `json:"items"`
+	Count int                          `json:"count"`
+}
+
+// PipelineBoard is a kanban view of a favorite list
+type PipelineBoard struct {
+	List    *FavoritePublisherList `json:"list"`
+	Columns []*PipelineBoardColumn `json:"columns"`
+}
+
+// PipelineReminder is a due next action to remind the item owner about
+type PipelineReminder struct {
+	ItemID          int64     `json:"item_id"`
+	ListID          int64     `json:"list_id"`
+	ListName        string    `json:"list_name"`
+	PublisherDomain string    `json:"publisher_domain"`
+	StageName       *string   `json:"stage_name,omitempty"`
+	OwnerEmail      string    `json:"owner_email"`
+	NextActionAt    time.Time `json:"next_action_at"`
+	NextActionNote  *string   `json:"next_action_note,omitempty"`
+}
+
+// SortPipelineStages orders stages by position, then by ID
+func SortPipelineStages(stages []*PipelineStage) {
+	sort.SliceStable(stages, func(i, j int) bool {
+		if stages[i].Position != stages[j].Position {
+			return stages[i].Position < stages[j].Position
+		}
+		return stages[i].StageID < stages[j].StageID
+	})
+}
+
+// FindPipelineStage returns the stage with the given ID, or nil
+func FindPipelineStage(stages []*PipelineStage, stageID int64) *PipelineStage {
+	for _, stage := range stages {
+		if stage.StageID == stageID {
+			return stage
+		}
+	}
+	return nil
+}
+
+// FindPipelineStageByName returns the stage whose key or name matches value, ignoring case
+func FindPipelineStageByName(stages []*PipelineStage, value string) *PipelineStage {
+	value = strings.TrimSpace(value)
+	for _, stage := range stages {
+		if strings.EqualFold(stage.Key, value) || strings.EqualFold(stage.Name, value) {
+			return stage
+		}
+	}
+	return nil
+}
+
+// LegacyStatusForStage maps a stage to the added/contacted/accepted item status kept for older
+// clients: won stages are accepted, the entry stage (the first open stage) is added and every
+// other stage counts as contacted. stages must be sorted.
+func LegacyStatusForStage(stages []*PipelineStage, stage *PipelineStage) string {
+	if stage.Outcome == PipelineStageOutcomeWon {
+		return PublisherStatusAccepted
+	}
+	if entry := EntryPipelineStage(stages); entry != nil && entry.StageID == stage.StageID {
+		return PublisherStatusAdded
+	}
+	return PublisherStatusContacted
+}
+
+// StageForLegacyStatus returns the stage an item set to a legacy status moves to: the entry stage
+// for added, the first open stage after it for contacted and the first won stage for accepted.
+// It returns nil when the pipeline has no such stage. stages must be sorted.
+func StageForLegacyStatus(stages []*PipelineStage, status string) *PipelineStage {
+	entry := EntryPipelineStage(stages)
+	switch status {
+	case PublisherStatusAdded:
+		return entry
+	case PublisherStatusContacted:
+		for _, stage := range stages {
+			if stage.Outcome == PipelineStageOutcomeOpen && stage != entry {
+				return stage
+			}
+		}
+	case PublisherStatusAccepted:
+		for _, stage := range stages {
+			if stage.Outcome == PipelineStageOutcomeWon {
+				return stage
+			}
+		}
+	}
+	return nil
+}
+
+// StageAfterPublisherReply returns the stage an item moves to when the publisher replies to
+// outreach: the next open stage on the board. Items in a won or lost stage, or in the last
+// open stage, stay where they are. stages must be sorted.
+func StageAfterPublisherReply(stages []*PipelineStage, current *PipelineStage) (*PipelineStage, bool) {
+	if current == nil || current.Outcome != PipelineStageOutcomeOpen {
+		return current, false
+	}
+	for i, stage := range stages {
+		if stage.StageID != current.StageID {
+			continue
+		}
+		if i+1 < len(stages) && stages[i+1].Outcome == PipelineStageOutcomeOpen {
+			return stages[i+1], true
+		}
+		break
+	}
+	return current, false
+}
+
+// EntryPipelineStage returns the first open stage, where new list items start. stages must be sorted.
+func EntryPipelineStage(stages []*PipelineStage) *PipelineStage {
+	for _, stage := range stages {
+		if stage.Outcome == PipelineStageOutcomeOpen {
+			return stage
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package domain
+
+import "testing"
+
+func testPipelineStages() []*PipelineStage {
+	stages := DefaultPipelineStages(1)
+	for i, stage := range stages {
+		stage.StageID = int64(i + 1)
+	}
+	return stages
+}
+
+func TestLegacyStatusForStage(t *testing.T) {
+	stages := testPipelineStages()
+
+	want := map[string]string{
+		"researching": PublisherStatusAdded,
+		"contacted":   PublisherStatusContacted,
+		"negotiating": PublisherStatusContacted,
+		"signed":      PublisherStatusAccepted,
+		"rejected":    PublisherStatusContacted,
+	}
+	for _, stage := range stages {
+		if got := LegacyStatusForStage(stages, stage); got != want[stage.Key] {
+			t.Errorf("LegacyStatusForStage(%s) = %q, want %q", stage.Key, got, want[stage.Key])
+		}
+	}
+}
+
+func TestStageForLegacyStatus(t *testing.T) {
+	stages := testPipelineStages()
+
+	tests := []struct {
+		status  string
+		wantKey string
+	}{
+		{status: PublisherStatusAdded, wantKey: "researching"},
+		{status: PublisherStatusContacted, wantKey: "contacted"},
+		{status: PublisherStatusAccepted, wantKey: "signed"},
+	}
+	for _, tt := range tests {
+		stage := StageForLegacyStatus(stages, tt.status)
+		if stage == nil || stage.Key != tt.wantKey {
+			t.Errorf("StageForLegacyStatus(%s) = %+v, want %s", tt.status, stage, tt.wantKey)
+		}
+	}
+
+	// Without a won stage there is nothing to accept into
+	openOnly := stages[:3]
+	if stage := StageForLegacyStatus(openOnly, PublisherStatusAccepted); stage != nil {
+		t.Errorf("StageForLegacyStatus(accepted) = %+v, want nil", stage)
+	}
+}
+
+func TestStageAfterPublisherReply(t *testing.T) {
+	stages := testPipelineStages()
+
+	tests := []struct {
+		current     string
+		wantKey     string
+		wantChanged bool
+	}{
+		{current: "researching", wantKey: "contacted", wantChanged: true},
+		{current: "contacted", wantKey: "negotiating", wantChanged: true},
+		{current: "negotiating", wantKey: "negotiating", wantChanged: false}, // Next stage is won
+		{current: "signed", wantKey: "signed", wantChanged: false},
+		{current: "rejected", wantKey: "rejected", wantChanged: false},
+	}
+	for _, tt := range tests {
+		var current *PipelineStage
+		for _, stage := range stages {
+			if stage.Key == tt.current {
+				current = stage
+			}
+		}
+		got, changed := StageAfterPublisherReply(stages, current)
+		if got.Key != tt.wantKey || changed != tt.wantChanged {
+			t.Errorf("StageAfterPublisherReply(%s) = %s, %v, want %s, %v", tt.current, got.Key, changed, tt.wantKey, tt.wantChanged)
+		}
+	}
+}
+
+func TestPipelineStage_Validate(t *testing.T) {
+	red, invalidColor := "#ff0000", "red"
+
+	tests := []struct {
+		name    string
+		stage   PipelineStage
+		wantErr bool
+	}{
+		{name: "valid", stage: PipelineStage{Key: "on_hold", Name: "On hold", Outcome: PipelineStageOutcomeOpen, Color: &red}},
+		{name: "invalid key", stage: PipelineStage{Key: "On Hold", Name: "On hold", Outcome: PipelineStageOutcomeOpen}, wantErr: true},
+		{name: "missing name", stage: PipelineStage{Key: "on_hold", Name: " ", Outcome: PipelineStageOutcomeOpen}, wantErr: true},
+		{name: "invalid outcome", stage: PipelineStage{Key: "on_hold", Name: "On hold", Outcome: "paused"}, wantErr: true},
+		{name: "invalid color", stage: PipelineStage{Key: "on_hold", Name: "On hold", Outcome: PipelineStageOutcomeLost, Color: &invalidColor}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.stage.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindPipelineStageByName(t *testing.T) {
+	stages := testPipelineStages()
+
+	for _, value := range []string{"negotiating", "Negotiating", " NEGOTIATING "} {
+		if stage := FindPipelineStageByName(stages, value); stage == nil || stage.Key != "negotiating" {
+			t.Errorf("FindPipelineStageByName(%q) = %+v, want negotiating", value, stage)
+		}
+	}
+	if stage := FindPipelineStageByName(stages, "on hold"); stage != nil {
+		t.Errorf("FindPipelineStageByName(on hold) = %+v, want nil", stage)
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Publisher discovery sort orders
+const (
+	PublisherSortRelevance = "relevance" // Text match rank when there is a query, then the stored relevance score
+	PublisherSortTraffic   = "traffic"   // Traffic score
+	PublisherSortPartners  = "partners"  // Number of partner advertisers
+)
+
+const (
+	// MaxPublisherSearchQueryLength limits the free-text query
+	MaxPublisherSearchQueryLength = 200
+	// DefaultPublisherFacetLimit is the number of values returned per facet by default
+	DefaultPublisherFacetLimit = 10
+	// MaxPublisherFacetLimit is the maximum number of values returned per facet
+	MaxPublisherFacetLimit = 50
+)
+
+// PublisherSearchRequest represents a publisher discovery search
+type PublisherSearchRequest struct {
+	// Free text matched against domain, description and keywords
+	Query string `json:"query,omitempty" example:"running shoes"`
+	// Sort order: relevance (default), traffic or partners
+	Sort string `json:"sort,omitempty" example:"relevance"`
+	// Only publishers with one of these promotypes
+	Promotypes []string `json:"promotypes,omitempty" example:"content,coupon"`
+	// Only known (true) or unknown (false) publishers
+	Known *bool `json:"known,omitempty"`
+	// Minimum traffic score
+	MinTrafficScore *float64 `json:"min_traffic_score,omitempty" example:"50"`
+	// Only publishers working with one of these affiliate networks
+	Networks []string `json:"networks,omitempty" example:"Impact,CJ"`
+	// Only publishers ranking in this country
+	Country string `json:"country,omitempty" example:"US"`
+	// Only publishers in one of these verticals (verticalsV2 names)
+	Verticals []string `json:"verticals,omitempty" example:"E-commerce"`
+	// Number of values per facet (default 10, max 50)
+	FacetLimit int `json:"facet_limit,omitempty" example:"10"`
+	Page       int `json:"page,omitempty" example:"1"`
+	PageSize   int `json:"page_size,omitempty" example:"20"`
+}
+
+// PublisherSearchQuery is a validated publisher discovery search
+type PublisherSearchQuery struct {
+	Text            string
+	Sort            string
+	Promotypes      []string // Lower case
+	Known           *bool
+	MinTrafficScore *float64
+	Networks        []string
+	Country         string // Lower case, as stored in country_rankings
+	Verticals       []string
+	FacetLimit      int
+	Limit           int
+	Offset          int
+}
+
+// ToQuery validates the request and applies defaults
+func (r *PublisherSearchRequest) ToQuery() (*PublisherSearchQuery, error) {
+	text := strings.TrimSpace(r.Query)
+	if len(text) > MaxPublisherSearchQueryLength {
+		return nil, fmt.Errorf("query must be at most %d characters", MaxPublisherSearchQueryLength)
+	}
+
+	sort := strings.ToLower(r.Sort)
+	switch sort {
+	case "":
+		sort = PublisherSortRelevance
+	case PublisherSortRelevance, PublisherSortTraffic, PublisherSortPartners:
+	default:
+		return nil, fmt.Errorf("sort must be one of relevance, traffic or partners")
+	}
+
+	if r.MinTrafficScore != nil && *r.MinTrafficScore < 0 {
+		return nil, fmt.Errorf("min_traffic_score must not be negative")
+	}
+
+	facetLimit := r.FacetLimit
+	if facetLimit < 1 {
+		facetLimit = DefaultPublisherFacetLimit
+	}
+	if facetLimit > MaxPublisherFacetLimit {
+		facetLimit = MaxPublisherFacetLimit
+	}
+
+	page := r.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := r.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	promotypes := make([]string, 0, len(r.Promotypes))
+	for _, promotype := range r.Promotypes {
+		if promotype = strings.ToLower(strings.TrimSpace(promotype)); promotype != "" {
+			promotypes = append(promotypes, promotype)
+		}
+	}
+
+	return &PublisherSearchQuery{
+		Text:            text,
+		Sort:            sort,
+		Promotypes:      promotypes,
+		Known:           r.Known,
+		MinTrafficScore: r.MinTrafficScore,
+		Networks:        nonEmptyStrings(r.Networks),
+		Country:         strings.ToLower(strings.TrimSpace(r.Country)),
+		Verticals:       nonEmptyStrings(r.Verticals),
+		FacetLimit:      facetLimit,
+		Limit:           pageSize,
+		Offset:          (page - 1) * pageSize,
+	}, nil
+}
+
+// nonEmptyStrings returns the trimmed, non-empty values
+func nonEmptyStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// FacetCount is the number of matching publishers with a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// PublisherSearchFacets holds the most common values among all publishers matching a search
+type PublisherSearchFacets struct {
+	Verticals []FacetCount `json:"verticals"`
+	Countries []FacetCount `json:"countries"`
+	Networks  []FacetCount `json:"networks"`
+}
+
+// PublisherSearchResponse represents a page of publisher discovery results
+type PublisherSearchResponse struct {
+	Data     []*AnalyticsPublisherResponse `json:"data"`
+	Total    int64                         `json:"total"`
+	Page     int                           `json:"page"`
+	PageSize int                           `json:"page_size"`
+	Facets   PublisherSearchFacets         `json:"facets"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPublisherSearchRequest_ToQuery(t *testing.T) {
+	query, err := (&PublisherSearchRequest{}).ToQuery()
+	if err != nil {
+		t.Fatalf("ToQuery() error = %v", err)
+	}
+	if query.Sort != PublisherSortRelevance || query.Limit != 20 || query.Offset != 0 || query.FacetLimit != DefaultPublisherFacetLimit {
+		t.Errorf("ToQuery() defaults = %+v", query)
+	}
+
+	query, err = (&PublisherSearchRequest{
+		Query:      "  running shoes ",
+		Sort:       "Traffic",
+		Promotypes: []string{"Content", " "},
+		Networks:   []string{"Impact", ""},
+		Country:    "US",
+		FacetLimit: 500,
+		Page:       3,
+		PageSize:   50,
+	}).ToQuery()
+	if err != nil {
+		t.Fatalf("ToQuery() error = %v", err)
+	}
+	if query.Text != "running shoes" {
+		t.Errorf("Text = %q, want %q", query.Text, "running shoes")
+	}
+	if query.Sort != PublisherSortTraffic {
+		t.Errorf("Sort = %q, want %q", query.Sort, PublisherSortTraffic)
+	}
+	if len(query.Promotypes) != 1 || query.Promotypes[0] != "content" {
+		t.Errorf("Promotypes = %v, want [content]", query.Promotypes)
+	}
+	if len(query.Networks) != 1 || query.Networks[0] != "Impact" {
+		t.Errorf("Networks = %v, want [Impact]", query.Networks)
+	}
+	if query.Country != "us" {
+		t.Errorf("Country = %q, want %q", query.Country, "us")
+	}
+	if query.FacetLimit != MaxPublisherFacetLimit {
+		t.Errorf("FacetLimit = %d, want %d", query.FacetLimit, MaxPublisherFacetLimit)
+	}
+	if query.Limit != 50 || query.Offset != 100 {
+		t.Errorf("Limit/Offset = %d/%d, want 50/100", query.Limit, query.Offset)
+	}
+
+	negative := -1.0
+	invalid := []PublisherSearchRequest{
+		{Sort: "newest"},
+		{Query: strings.Repeat("a", MaxPublisherSearchQueryLength+1)},
+		{MinTrafficScore: &negative},
+	}
+	for _, req := range invalid {
+		if _, err := req.ToQuery(); err == nil {
+			t.Errorf("ToQuery(%+v) expected error", req)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReportDimension represents a dimension a performance report can be grouped by
+type ReportDimension string
+
+const (
+	ReportDimensionDate      ReportDimension = "date"
+	ReportDimensionHour      ReportDimension = "hour"
+	ReportDimensionCampaign  ReportDimension = "campaign"
+	ReportDimensionAffiliate ReportDimension = "affiliate"
+	ReportDimensionSub1      ReportDimension = "sub1"
+	ReportDimensionSub2      ReportDimension = "sub2"
+	ReportDimensionSub3      ReportDimension = "sub3"
+	ReportDimensionSub4      ReportDimension = "sub4"
+	ReportDimensionSub5      ReportDimension = "sub5"
+	ReportDimensionCountry   ReportDimension = "country"
+	ReportDimensionDevice    ReportDimension = "device"
+)
+
+// IsValid checks if the report dimension is valid
+func (d ReportDimension) IsValid() bool {
+	switch d {
+	case ReportDimensionDate, ReportDimensionHour, ReportDimensionCampaign, ReportDimensionAffiliate,
+		ReportDimensionSub1, ReportDimensionSub2, ReportDimensionSub3, ReportDimensionSub4, ReportDimensionSub5,
+		ReportDimensionCountry, ReportDimensionDevice:
+		return true
+	default:
+		return false
+	}
+}
+
+// Report sort fields that are metrics rather than dimensions
+const (
+	ReportSortImpressions  = "impressions"
+	ReportSortClicks       = "clicks"
+	ReportSortUniqueClicks = "unique_clicks"
+	ReportSortConversions  = "conversions"
+	ReportSortRevenue      = "revenue"
+	ReportSortPayout       = "payout"
+)
+
+// MaxReportRangeDays limits how many days a single report request may cover
+const MaxReportRangeDays = 366
+
+// ReportFilters restricts which facts are included in a report
+type ReportFilters struct {
+	CampaignIDs  []int64  `json:"campaign_ids,omitempty"`
+	AffiliateIDs []int64  `json:"affiliate_ids,omitempty"`
+	Countries    []string `json:"countries,omitempty"`    // ISO 3166-1 alpha-2 codes
+	DeviceTypes  []string `json:"device_types,omitempty"` // e.g. mobile, desktop, tablet
+	Sub1         []string `json:"sub1,omitempty"`
+	Sub2         []string `json:"sub2,omitempty"`
+	Sub3         []string `json:"sub3,omitempty"`
+	Sub4         []string `json:"sub4,omitempty"`
+	Sub5         []string `json:"sub5,omitempty"`
+}
+
+// ReportRequest represents a performance report query
+type ReportRequest struct {
+	From      string            `json:"from" binding:"required"` // YYYY-MM-DD, inclusive, in the report timezone
+	To        string            `json:"to" binding:"required"`   // YYYY-MM-DD, inclusive, in the report timezone
+	Timezone  string            `json:"timezone,omitempty"`      // IANA timezone name, defaults to UTC
+	GroupBy   []ReportDimension `json:"group_by,omitempty"`
+	Filters   ReportFilters     `json:"filters"`
+	SortBy    string            `json:"sort_by,omitempty"`    // A metric or one of the group_by dimensions
+	SortOrder string            `json:"sort_order,omitempty"` // asc or desc
+	Page      int               `json:"page,omitempty"`
+	PageSize  int               `json:"page_size,omitempty"`
+}
+
+// ReportScope identifies whose data a report may include
+type ReportScope struct {
+	OrganizationID   int64
+	OrganizationType OrganizationType
+}
+
+// ReportQuery is a validated report request resolved for a specific organization
+type ReportQuery struct {
+	From      time.Time // First local day, inclusive
+	To        time.Time // Last local day, inclusive
+	Timezone  string
+	GroupBy   []ReportDimension
+	Filters   ReportFilters
+	SortBy    string
+	SortOrder string
+	Scope     ReportScope
+	Limit     int
+	Offset    int
+}
+
+// ToQuery validates the request and resolves it into a query for the given scope
+func (r *ReportRequest) ToQuery(scope ReportScope, now time.Time) (*ReportQuery, error) {
+	timezone := r.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", r.Timezone)
+	}
+
+	from, err := time.Parse("2006-01-02", r.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", r.From)
+	}
+	to, err := time.Parse("2006-01-02", r.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", r.To)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("to date must not be before from date")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > MaxReportRangeDays {
+		return nil, fmt.Errorf("report range of %d days exceeds the maximum of %d days", days, MaxReportRangeDays)
+	}
+	localNow := now.In(loc)
+	if from.After(time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)) {
+		return nil, fmt.Errorf("from date must not be in the future")
+	}
+
+	seen := make(map[ReportDimension]bool)
+	for _, dimension := range r.GroupBy {
+		if !dimension.IsValid() {
+			return nil, fmt.Errorf("invalid group_by dimension: %s", dimension)
+		}
+		if seen[dimension] {
+			return nil, fmt.Errorf("duplicate group_by dimension: %s", dimension)
+		}
+		seen[dimension] = true
+	}
+
+	sortBy := r.SortBy
+	switch sortBy {
+	case "":
+	case ReportSortImpressions, ReportSortClicks, ReportSortUniqueClicks, ReportSortConversions:
+	case ReportSortRevenue:
+		if !CanSeeReportRevenue(scope.OrganizationType) {
+			return nil, fmt.Errorf("cannot sort by revenue")
+		}
+	case ReportSortPayout:
+		if !CanSeeReportPayout(scope.OrganizationType) {
+			return nil, fmt.Errorf("cannot sort by payout")
+		}
+	default:
+		if !seen[ReportDimension(sortBy)] {
+			return nil, fmt.Errorf("sort_by must be a metric or one of the group_by dimensions")
+		}
+	}
+
+	sortOrder := strings.ToLower(r.SortOrder)
+	if sortOrder != "" && sortOrder != "asc" && sortOrder != "desc" {
+		return nil, fmt.Errorf("sort_order must be asc or desc")
+	}
+
+	page := r.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := r.PageSize
+	if pageSize < 1 || pageSize > 1000 {
+		pageSize = 100
+	}
+
+	filters := r.Filters
+	for i, country := range filters.Countries {
+		filters.Countries[i] = strings.ToUpper(country)
+	}
+	for i, deviceType := range filters.DeviceTypes {
+		filters.DeviceTypes[i] = strings.ToLower(deviceType)
+	}
+
+	return &ReportQuery{
+		From:      from,
+		To:        to,
+		Timezone:  loc.String(),
+		GroupBy:   r.GroupBy,
+		Filters:   filters,
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		Scope:     scope,
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	}, nil
+}
+
+// CanSeeReportRevenue reports whether an organization type may see advertiser revenue.
+// Revenue is what the advertiser pays, so affiliates never see it.
+func CanSeeReportRevenue(orgType OrganizationType) bool {
+	return orgType != OrganizationTypeAffiliate
+}
+
+// CanSeeReportPayout reports whether an organization type may see affiliate payouts.
+// Payout is what the affiliate earns, so advertisers and their agencies never see it.
+func CanSeeReportPayout(orgType OrganizationType) bool {
+	return orgType == OrganizationTypePlatformOwner || orgType == OrganizationTypeAffiliate
+}
+
+// ReportDimensionValues holds the dimension values of a report row; only grouped dimensions are set
+type ReportDimensionValues struct {
+	Date          *string `json:"date,omitempty"`
+	Hour          *int    `json:"hour,omitempty"`
+	CampaignID    *int64  `json:"campaign_id,omitempty"`
+	CampaignName  *string `json:"campaign_name,omitempty"`
+	AffiliateID   *int64  `json:"affiliate_id,omitempty"`
+	AffiliateName *string `json:"affiliate_name,omitempty"`
+	Sub1          *string `json:"sub1,omitempty"`
+	Sub2          *string `json:"sub2,omitempty"`
+	Sub3          *string `json:"sub3,omitempty"`
+	Sub4          *string `json:"sub4,omitempty"`
+	Sub5          *string `json:"sub5,omitempty"`
+	Country       *string `json:"country,omitempty"`
+	Device        *string `json:"device,omitempty"`
+}
+
+// ReportTotals holds the summed raw facts for a report row
+type ReportTotals struct {
+	Impressions  int64
+	Clicks       int64
+	UniqueClicks int64
+	Conversions  int64
+	Revenue      decimal.Decimal
+	Payout       decimal.Decimal
+}
+
+// ReportAggregate is a grouped row of raw facts as returned by the repository
+type ReportAggregate struct {
+	ReportDimensionValues
+	ReportTotals
+}
+
+// ReportMetrics holds the metrics of a report row as visible to the requesting organization
+type ReportMetrics struct {
+	Impressions    int64            `json:"impressions"`
+	Clicks         int64            `json:"clicks"`
+	UniqueClicks   int64            `json:"unique_clicks"`
+	Conversions    int64            `json:"conversions"`
+	Revenue        *decimal.Decimal `json:"revenue,omitempty"`
+	Payout         *decimal.Decimal `json:"payout,omitempty"`
+	ConversionRate decimal.Decimal  `json:"cr"`               // Conversions per click, in percent
+	EPC            decimal.Decimal  `json:"epc"`              // Earnings per click
+	CPA            decimal.Decimal  `json:"cpa"`              // Cost per acquisition
+	Margin         *decimal.Decimal `json:"margin,omitempty"` // Revenue minus payout
+	MarginPercent  *decimal.Decimal `json:"margin_percent,omitempty"`
+}
+
+// NewReportMetrics computes the derived metrics for a set of totals and hides the
+// financial fields the organization type is not allowed to see.
+//
+// EPC and CPA are computed from the money the viewer sees: revenue for advertisers
+// (cost per click and per conversion), payout for affiliates (earnings), and for the
+// platform owner EPC uses revenue while CPA uses payout.
+func NewReportMetrics(totals ReportTotals, orgType OrganizationType) ReportMetrics {
+	metrics := ReportMetrics{
+		Impressions:    totals.Impressions,
+		Clicks:         totals.Clicks,
+		UniqueClicks:   totals.UniqueClicks,
+		Conversions:    totals.Conversions,
+		ConversionRate: decimal.Zero,
+		EPC:            decimal.Zero,
+		CPA:            decimal.Zero,
+	}
+
+	clicks := decimal.NewFromInt(totals.Clicks)
+	conversions := decimal.NewFromInt(totals.Conversions)
+	if totals.Clicks > 0 {
+		metrics.ConversionRate = conversions.Div(clicks).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+
+	epcBase, cpaBase := totals.Revenue, totals.Revenue
+	switch orgType {
+	case OrganizationTypeAffiliate:
+		epcBase, cpaBase = totals.Payout, totals.Payout
+	case OrganizationTypePlatformOwner:
+		cpaBase = totals.Payout
+	}
+	if totals.Clicks > 0 {
+		metrics.EPC = epcBase.Div(clicks).Round(4)
+	}
+	if totals.Conversions > 0 {
+		metrics.CPA = cpaBase.Div(conversions).Round(4)
+	}
+
+	if CanSeeReportRevenue(orgType) {
+		revenue := totals.Revenue
+		metrics.Revenue = &revenue
+	}
+	if CanSeeReportPayout(orgType) {
+		payout := totals.Payout
+		metrics.Payout = &payout
+	}
+	if CanSeeReportRevenue(orgType) && CanSeeReportPayout(orgType) {
+		margin := totals.Revenue.Sub(totals.Payout)
+		metrics.Margin = &margin
+		marginPercent := decimal.Zero
+		if totals.Revenue.IsPositive() {
+			marginPercent = margin.Div(totals.Revenue).Mul(decimal.NewFromInt(100)).Round(2)
+		}
+		metrics.MarginPercent = &marginPercent
+	}
+
+	return metrics
+}
+
+// ReportRow represents a single grouped row of a performance report
+type ReportRow struct {
+	ReportDimensionValues
+	Metrics ReportMetrics `json:"metrics"`
+}
+
+// ReportResponse represents the response of a performance report query
+type ReportResponse struct {
+	Rows     []ReportRow       `json:"rows"`
+	Totals   ReportMetrics     `json:"totals"`
+	GroupBy  []ReportDimension `json:"group_by"`
+	Timezone string            `json:"timezone"`
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewReportMetrics(t *testing.T) {
+	totals := ReportTotals{
+		Clicks:      200,
+		Conversions: 10,
+		Revenue:     decimal.NewFromInt(100),
+		Payout:      decimal.NewFromInt(60),
+	}
+
+	tests := []struct {
+		name        string
+		orgType     OrganizationType
+		wantEPC     string
+		wantCPA     string
+		wantRevenue bool
+		wantPayout  bool
+		wantMargin  string
+	}{
+		{
+			name:        "platform owner sees everything",
+			orgType:     OrganizationTypePlatformOwner,
+			wantEPC:     "0.5",
+			wantCPA:     "6",
+			wantRevenue: true,
+			wantPayout:  true,
+			wantMargin:  "40",
+		},
+		{
+			name:        "advertiser sees revenue only",
+			orgType:     OrganizationTypeAdvertiser,
+			wantEPC:     "0.5",
+			wantCPA:     "10",
+			wantRevenue: true,
+		},
+		{
+			name:       "affiliate sees payout only",
+			orgType:    OrganizationTypeAffiliate,
+			wantEPC:    "0.3",
+			wantCPA:    "6",
+			wantPayout: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewReportMetrics(totals, tt.orgType)
+
+			if got := metrics.ConversionRate.String(); got != "5" {
+				t.Errorf("ConversionRate = %s, want 5", got)
+			}
+			if got := metrics.EPC.String(); got != tt.wantEPC {
+				t.Errorf("EPC = %s, want %s", got, tt.wantEPC)
+			}
+			if got := metrics.CPA.String(); got != tt.wantCPA {
+				t.Errorf("CPA = %s, want %s", got, tt.wantCPA)
+			}
+			if (metrics.Revenue != nil) != tt.wantRevenue {
+				t.Errorf("Revenue visible = %v, want %v", metrics.Revenue != nil, tt.wantRevenue)
+			}
+			if (metrics.Payout != nil) != tt.wantPayout {
+				t.Errorf("Payout visible = %v, want %v", metrics.Payout != nil, tt.wantPayout)
+			}
+			if tt.wantMargin == "" && metrics.Margin != nil {
+				t.Errorf("Margin should be hidden, got %s", metrics.Margin)
+			}
+			if tt.wantMargin != "" && (metrics.Margin == nil || metrics.Margin.String() != tt.wantMargin) {
+				t.Errorf("Margin = %v, want %s", metrics.Margin, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestReportRequest_ToQuery(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	affiliateScope := ReportScope{OrganizationID: 1, OrganizationType: OrganizationTypeAffiliate}
+
+	tests := []struct {
+		name    string
+		req     ReportRequest
+		wantErr bool
+	}{
+		{
+			name: "valid grouped request",
+			req:  ReportRequest{From: "2024-03-01", To: "2024-03-14", Timezone: "America/New_York", GroupBy: []ReportDimension{ReportDimensionDate, ReportDimensionCampaign}},
+		},
+		{
+			name: "sort by grouped dimension",
+			req:  ReportRequest{From: "2024-03-01", To: "2024-03-14", GroupBy: []ReportDimension{ReportDimensionCountry}, SortBy: "country"},
+		},
+		{
+			name:    "invalid timezone",
+			req:     ReportRequest{From: "2024-03-01", To: "2024-03-14", Timezone: "Mars/Base"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid dimension",
+			req:     ReportRequest{From: "2024-03-01", To: "2024-03-14", GroupBy: []ReportDimension{"browser"}},
+			wantErr: true,
+		},
+		{
+			name:    "sort by ungrouped dimension",
+			req:     ReportRequest{From: "2024-03-01", To: "2024-03-14", SortBy: "country"},
+			wantErr: true,
+		},
+		{
+			name:    "affiliate cannot sort by revenue",
+			req:     ReportRequest{From: "2024-03-01", To: "2024-03-14", SortBy: ReportSortRevenue},
+			wantErr: true,
+		},
+		{
+			name:    "from in the future",
+			req:     ReportRequest{From: "2024-03-16", To: "2024-03-17"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.req.ToQuery(affiliateScope, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
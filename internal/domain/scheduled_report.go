@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Scheduled report file formats
+const (
+	ScheduledReportFormatCSV   = "csv"
+	ScheduledReportFormatXLSX  = "xlsx"
+	ScheduledReportFormatJSONL = "jsonl"
+)
+
+// Scheduled report delivery types
+const (
+	ScheduledReportDeliveryEmail = "email"
+	ScheduledReportDeliverySFTP  = "sftp"
+	ScheduledReportDeliveryS3    = "s3"
+)
+
+// Scheduled report date ranges, resolved relative to the time of the run in the report timezone
+const (
+	ScheduledReportRangeYesterday     = "yesterday"
+	ScheduledReportRangeLast7Days     = "last_7_days"
+	ScheduledReportRangeLast30Days    = "last_30_days"
+	ScheduledReportRangeMonthToDate   = "month_to_date"
+	ScheduledReportRangePreviousMonth = "previous_month"
+)
+
+// Scheduled report run status constants
+const (
+	ScheduledReportRunStatusRunning   = "running"
+	ScheduledReportRunStatusCompleted = "completed"
+	ScheduledReportRunStatusFailed    = "failed"
+)
+
+// Scheduled report run trigger constants
+const (
+	ScheduledReportRunTriggerScheduled = "scheduled"
+	ScheduledReportRunTriggerManual    = "manual"
+)
+
+// MaxScheduledReportRows limits how many rows a single scheduled report file may contain
+const MaxScheduledReportRows = 100000
+
+// ScheduledReportQuery is the saved part of a report query; the date range is resolved per run
+type ScheduledReportQuery struct {
+	GroupBy   []ReportDimension `json:"group_by,omitempty"`
+	Filters   ReportFilters     `json:"filters"`
+	SortBy    string            `json:"sort_by,omitempty"`
+	SortOrder string            `json:"sort_order,omitempty"`
+}
+
+// ScheduledReportSFTPDestination holds the SFTP destination of a scheduled report
+type ScheduledReportSFTPDestination struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port,omitempty"`
+	Username           string `json:"username"`
+	Password           string `json:"password,omitempty"`    // Write-only; stored encrypted
+	PrivateKey         string `json:"private_key,omitempty"` // Write-only; stored encrypted
+	Directory          string `json:"directory,omitempty"`
+	HostKeyFingerprint string `json:"host_key_fingerprint,omitempty"`
+}
+
+// ScheduledReportS3Destination holds the S3-compatible destination of a scheduled report
+type ScheduledReportS3Destination struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix,omitempty"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"` // Write-only; stored encrypted
+}
+
+// ScheduledReportDelivery holds where a scheduled report is delivered; only the part matching the delivery type is used
+type ScheduledReportDelivery struct {
+	Recipients []string                        `json:"recipients,omitempty"`
+	SFTP       *ScheduledReportSFTPDestination `json:"sftp,omitempty"`
+	S3         *ScheduledReportS3Destination   `json:"s3,omitempty"`
+}
+
+// ScheduledReport represents a saved report definition that is rendered and delivered on a schedule
+type ScheduledReport struct {
+	ScheduledReportID int64                   `json:"scheduled_report_id" db:"scheduled_report_id"`
+	OrganizationID    int64                   `json:"organization_id" db:"organization_id"`
+	Name              string                  `json:"name" db:"name"`
+	Query             ScheduledReportQuery    `json:"query" db:"query"`
+	DateRange         string                  `json:"date_range" db:"date_range"`
+	Timezone          string                  `json:"timezone" db:"timezone"`
+	Format            string                  `json:"format" db:"format"`
+	Schedule          string                  `json:"schedule" db:"schedule"` // Standard 5-field cron expression, evaluated in Timezone
+	DeliveryType      string                  `json:"delivery_type" db:"delivery_type"`
+	Delivery          ScheduledReportDelivery `json:"delivery" db:"delivery_config"`
+	IsActive          bool                    `json:"is_active" db:"is_active"`
+	NextRunAt         *time.Time              `json:"next_run_at,omitempty" db:"next_run_at"`
+	LastRunAt         *time.Time              `json:"last_run_at,omitempty" db:"last_run_at"`
+	CreatedByUserID   *string                 `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	CreatedAt         time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at" db:"updated_at"`
+}
+
+// Redact clears the delivery secrets so the report can be returned by the API
+func (r *ScheduledReport) Redact() {
+	if r.Delivery.SFTP != nil {
+		sftp := *r.Delivery.SFTP
+		sftp.Password = ""
+		sftp.PrivateKey = ""
+		r.Delivery.SFTP = &sftp
+	}
+	if r.Delivery.S3 != nil {
+		s3 := *r.Delivery.S3
+		s3.SecretAccessKey = ""
+		r.Delivery.S3 = &s3
+	}
+}
+
+// CreateScheduledReportRequest represents the request to create a scheduled report
+type CreateScheduledReportRequest struct {
+	Name         string                  `json:"name" binding:"required"`
+	Query        ScheduledReportQuery    `json:"query"`
+	DateRange    string                  `json:"date_range" binding:"required"`
+	Timezone     string                  `json:"timezone,omitempty"`
+	Format       string                  `json:"format" binding:"required"`
+	Schedule     string                  `json:"schedule" binding:"required"`
+	DeliveryType string                  `json:"delivery_type" binding:"required"`
+	Delivery     ScheduledReportDelivery `json:"delivery"`
+	IsActive     *bool                   `json:"is_active,omitempty"`
+}
+
+// UpdateScheduledReportRequest represents the request to update a scheduled report.
+// Omitted delivery secrets keep their stored values.
+type UpdateScheduledReportRequest struct {
+	Name         *string                  `json:"name,omitempty"`
+	Query        *ScheduledReportQuery    `json:"query,omitempty"`
+	DateRange    *string                  `json:"date_range,omitempty"`
+	Timezone     *string                  `json:"timezone,omitempty"`
+	Format       *string                  `json:"format,omitempty"`
+	Schedule     *string                  `json:"schedule,omitempty"`
+	DeliveryType *string                  `json:"delivery_type,omitempty"`
+	Delivery     *ScheduledReportDelivery `json:"delivery,omitempty"`
+	IsActive     *bool                    `json:"is_active,omitempty"`
+}
+
+// Validate validates the scheduled report definition, except for the cron schedule syntax
+// and the presence of secrets, which are checked by the service
+func (r *ScheduledReport) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+
+	switch r.Format {
+	case ScheduledReportFormatCSV, ScheduledReportFormatXLSX, ScheduledReportFormatJSONL:
+	default:
+		return fmt.Errorf("invalid format: %s (must be csv, xlsx or jsonl)", r.Format)
+	}
+
+	switch r.DateRange {
+	case ScheduledReportRangeYesterday, ScheduledReportRangeLast7Days, ScheduledReportRangeLast30Days,
+		ScheduledReportRangeMonthToDate, ScheduledReportRangePreviousMonth:
+	default:
+		return fmt.Errorf("invalid date_range: %s", r.DateRange)
+	}
+
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", r.Timezone)
+	}
+
+	if strings.TrimSpace(r.Schedule) == "" {
+		return fmt.Errorf("schedule is required")
+	}
+
+	for _, dimension := range r.Query.GroupBy {
+		if !dimension.IsValid() {
+			return fmt.Errorf("invalid group_by dimension: %s", dimension)
+		}
+	}
+
+	switch r.DeliveryType {
+	case ScheduledReportDeliveryEmail:
+		if len(r.Delivery.Recipients) == 0 {
+			return fmt.Errorf("at least one email recipient is required")
+		}
+		if len(r.Delivery.Recipients) > 50 {
+			return fmt.Errorf("at most 50 email recipients are allowed")
+		}
+		for _, recipient := range r.Delivery.Recipients {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return fmt.Errorf("invalid email recipient: %s", recipient)
+			}
+		}
+	case ScheduledReportDeliverySFTP:
+		sftp := r.Delivery.SFTP
+		if sftp == nil || sftp.Host == "" || sftp.Username == "" {
+			return fmt.Errorf("sftp delivery requires host and username")
+		}
+		if sftp.Port < 0 || sftp.Port > 65535 {
+			return fmt.Errorf("invalid sftp port: %d", sftp.Port)
+		}
+		if !strings.HasPrefix(sftp.HostKeyFingerprint, "SHA256:") {
+			return fmt.Errorf("sftp delivery requires host_key_fingerprint (SHA256:...)")
+		}
+	case ScheduledReportDeliveryS3:
+		s3 := r.Delivery.S3
+		if s3 == nil || s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKeyID == "" {
+			return fmt.Errorf("s3 delivery requires endpoint, bucket and access_key_id")
+		}
+		if endpoint, err := url.Parse(s3.Endpoint); err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+			return fmt.Errorf("s3 endpoint must be an https URL")
+		}
+	default:
+		return fmt.Errorf("invalid delivery_type: %s (must be email, sftp or s3)", r.DeliveryType)
+	}
+
+	return nil
+}
+
+// DestinationHost returns the host an SFTP or S3 report is delivered to, empty for email
+func (r *ScheduledReport) DestinationHost() string {
+	switch {
+	case r.DeliveryType == ScheduledReportDeliverySFTP && r.Delivery.SFTP != nil:
+		return r.Delivery.SFTP.Host
+	case r.DeliveryType == ScheduledReportDeliveryS3 && r.Delivery.S3 != nil:
+		if endpoint, err := url.Parse(r.Delivery.S3.Endpoint); err == nil {
+			return endpoint.Hostname()
+		}
+	}
+	return ""
+}
+
+// ResolveDateRange returns the inclusive local date range a run at the given time covers
+func (r *ScheduledReport) ResolveDateRange(now time.Time) (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid timezone %q", r.Timezone)
+	}
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	switch r.DateRange {
+	case ScheduledReportRangeYesterday:
+		return yesterday, yesterday, nil
+	case ScheduledReportRangeLast7Days:
+		return today.AddDate(0, 0, -7), yesterday, nil
+	case ScheduledReportRangeLast30Days:
+		return today.AddDate(0, 0, -30), yesterday, nil
+	case ScheduledReportRangeMonthToDate:
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC), today, nil
+	case ScheduledReportRangePreviousMonth:
+		firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date_range: %s", r.DateRange)
+	}
+}
+
+// ScheduledReportRun represents one execution of a scheduled report
+type ScheduledReportRun struct {
+	RunID             int64      `json:"run_id" db:"run_id"`
+	ScheduledReportID int64      `json:"scheduled_report_id" db:"scheduled_report_id"`
+	Status            string     `json:"status" db:"status"`
+	TriggeredBy       string     `json:"triggered_by" db:"triggered_by"`
+	RangeFrom         time.Time  `json:"range_from" db:"range_from"`
+	RangeTo           time.Time  `json:"range_to" db:"range_to"`
+	RowCount          int        `json:"row_count" db:"row_count"`
+	FileName          *string    `json:"file_name,omitempty" db:"file_name"`
+	ContentType       *string    `json:"content_type,omitempty" db:"content_type"`
+	FileSize          *int64     `json:"file_size,omitempty" db:"file_size"`
+	StorageKey        *string    `json:"-" db:"storage_key"`
+	ErrorMessage      *string    `json:"error_message,omitempty" db:"error_message"`
+	StartedAt         time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+
+	// DownloadURL is the API path of the archived file, set when the file was archived
+	DownloadURL *string `json:"download_url,omitempty" db:"-"`
+}
+
+// ScheduledReportListResponse represents the response for listing scheduled reports
+type ScheduledReportListResponse struct {
+	ScheduledReports []*ScheduledReport `json:"scheduled_reports"`
+	Total            int                `json:"total"`
+	Page             int                `json:"page"`
+	PageSize         int                `json:"page_size"`
+}
+
+// ScheduledReportRunListResponse represents the response for listing scheduled report runs
+type ScheduledReportRunListResponse struct {
+	Runs     []*ScheduledReportRun `json:"runs"`
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
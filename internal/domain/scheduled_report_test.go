@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduledReport_ResolveDateRange(t *testing.T) {
+	// 2024-03-15 02:30 UTC is still 2024-03-14 in New York
+	now := time.Date(2024, 3, 15, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		dateRange string
+		timezone  string
+		wantFrom  string
+		wantTo    string
+	}{
+		{ScheduledReportRangeYesterday, "UTC", "2024-03-14", "2024-03-14"},
+		{ScheduledReportRangeYesterday, "America/New_York", "2024-03-13", "2024-03-13"},
+		{ScheduledReportRangeLast7Days, "UTC", "2024-03-08", "2024-03-14"},
+		{ScheduledReportRangeLast30Days, "UTC", "2024-02-14", "2024-03-14"},
+		{ScheduledReportRangeMonthToDate, "UTC", "2024-03-01", "2024-03-15"},
+		{ScheduledReportRangePreviousMonth, "UTC", "2024-02-01", "2024-02-29"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dateRange+"/"+tt.timezone, func(t *testing.T) {
+			report := &ScheduledReport{DateRange: tt.dateRange, Timezone: tt.timezone}
+			from, to, err := report.ResolveDateRange(now)
+			if err != nil {
+				t.Fatalf("ResolveDateRange() error = %v", err)
+			}
+			if got := from.Format("2006-01-02"); got != tt.wantFrom {
+				t.Errorf("from = %s, want %s", got, tt.wantFrom)
+			}
+			if got := to.Format("2006-01-02"); got != tt.wantTo {
+				t.Errorf("to = %s, want %s", got, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestScheduledReport_Validate(t *testing.T) {
+	valid := func() *ScheduledReport {
+		return &ScheduledReport{
+			Name:         "Daily revenue",
+			DateRange:    ScheduledReportRangeYesterday,
+			Timezone:     "UTC",
+			Format:       ScheduledReportFormatCSV,
+			Schedule:     "0 6 * * *",
+			DeliveryType: ScheduledReportDeliveryEmail,
+			Delivery:     ScheduledReportDelivery{Recipients: []string{"finance@example.com"}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r *ScheduledReport)
+		wantErr bool
+	}{
+		{"valid email report", func(r *ScheduledReport) {}, false},
+		{"missing name", func(r *ScheduledReport) { r.Name = " " }, true},
+		{"unsupported format", func(r *ScheduledReport) { r.Format = "pdf" }, true},
+		{"unknown date range", func(r *ScheduledReport) { r.DateRange = "last_year" }, true},
+		{"unknown timezone", func(r *ScheduledReport) { r.Timezone = "Mars/Olympus" }, true},
+		{"invalid group by", func(r *ScheduledReport) { r.Query.GroupBy = []ReportDimension{"browser"} }, true},
+		{"no recipients", func(r *ScheduledReport) { r.Delivery.Recipients = nil }, true},
+		{"invalid recipient", func(r *ScheduledReport) { r.Delivery.Recipients = []string{"not-an-email"} }, true},
+		{"sftp without host", func(r *ScheduledReport) {
+			r.DeliveryType = ScheduledReportDeliverySFTP
+			r.Delivery = ScheduledReportDelivery{SFTP: &ScheduledReportSFTPDestination{Username: "reports"}}
+		}, true},
+		{"sftp without host key fingerprint", func(r *ScheduledReport) {
+			r.DeliveryType = ScheduledReportDeliverySFTP
+			r.Delivery = ScheduledReportDelivery{SFTP: &ScheduledReportSFTPDestination{Host: "sftp.example.com", Username: "reports"}}
+		}, true},
+		{"valid sftp report", func(r *ScheduledReport) {
+			r.DeliveryType = ScheduledReportDeliverySFTP
+			r.Delivery = ScheduledReportDelivery{SFTP: &ScheduledReportSFTPDestination{
+				Host: "sftp.example.com", Username: "reports", HostKeyFingerprint: "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+			}}
+		}, false},
+		{"valid s3 report", func(r *ScheduledReport) {
+			r.DeliveryType = ScheduledReportDeliveryS3
+			r.Delivery = ScheduledReportDelivery{S3: &ScheduledReportS3Destination{Endpoint: "https://s3.example.com", Bucket: "reports", AccessKeyID: "minio"}}
+		}, false},
+		{"s3 over http", func(r *ScheduledReport) {
+			r.DeliveryType = ScheduledReportDeliveryS3
+			r.Delivery = ScheduledReportDelivery{S3: &ScheduledReportS3Destination{Endpoint: "http://s3.example.com", Bucket: "reports", AccessKeyID: "minio"}}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := valid()
+			tt.modify(report)
+			err := report.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduledReport_DestinationHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   ScheduledReport
+		wantHost string
+	}{
+		{"email", ScheduledReport{DeliveryType: ScheduledReportDeliveryEmail}, ""},
+		{"sftp", ScheduledReport{
+			DeliveryType: ScheduledReportDeliverySFTP,
+			Delivery:     ScheduledReportDelivery{SFTP: &ScheduledReportSFTPDestination{Host: "10.0.0.5"}},
+		}, "10.0.0.5"},
+		{"s3", ScheduledReport{
+			DeliveryType: ScheduledReportDeliveryS3,
+			Delivery:     ScheduledReportDelivery{S3: &ScheduledReportS3Destination{Endpoint: "https://[::1]:9000/"}},
+		}, "::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.report.DestinationHost(); got != tt.wantHost {
+				t.Errorf("DestinationHost() = %q, want %q", got, tt.wantHost)
+			}
+		})
+	}
+}
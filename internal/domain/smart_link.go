@@ -0,0 +1,498 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Smart link statuses. Paused smart links send every click to the fallback URL.
+const (
+	SmartLinkStatusActive = "active"
+	SmartLinkStatusPaused = "paused"
+)
+
+// Device types matched by smart link rules
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+)
+
+// Reasons a smart link destination is skipped for a click
+const (
+	SmartLinkSkipInactive          = "inactive"
+	SmartLinkSkipLinkNotActive     = "tracking_link_not_active"
+	SmartLinkSkipCampaignNotActive = "campaign_not_active"
+	SmartLinkSkipNoTrackingURL     = "no_tracking_url"
+	SmartLinkSkipCountry           = "country"
+	SmartLinkSkipDevice            = "device"
+	SmartLinkSkipDayPart           = "day_part"
+	SmartLinkSkipCapReached        = "cap_reached"
+)
+
+const (
+	// SmartLinkRedirectPath is the path of smart links, followed by the smart link ID
+	SmartLinkRedirectPath = "/s/"
+	// MaxSmartLinkDestinations is the maximum number of destinations of a smart link
+	MaxSmartLinkDestinations = 50
+	// MaxSmartLinkWeight is the maximum weight of a destination
+	MaxSmartLinkWeight = 1000
+	// MaxSmartLinkSimulatedClicks is the maximum number of clicks a simulation draws
+	MaxSmartLinkSimulatedClicks = 100000
+	// SmartLinkFallbackDestinationID is the tracking link ID under which fallback clicks are counted
+	SmartLinkFallbackDestinationID = 0
+)
+
+var smartLinkWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// SmartLink is a single affiliate link that routes each click to one of several tracking links
+// of the affiliate, by rules and weights, and to a fallback URL when no destination applies
+type SmartLink struct {
+	SmartLinkID    int64     `json:"smart_link_id" db:"smart_link_id"`
+	OrganizationID int64     `json:"organization_id" db:"organization_id"`
+	AffiliateID    int64     `json:"affiliate_id" db:"affiliate_id"`
+	Name           string    `json:"name" db:"name"`
+	Status         string    `json:"status" db:"status"`
+	FallbackURL    *string   `json:"fallback_url,omitempty" db:"fallback_url"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+
+	Destinations []*SmartLinkDestination `json:"destinations" db:"-"`
+}
+
+// SmartLinkDestination is a tracking link a smart link routes to. Among the destinations whose
+// rules match a click, one is picked at random in proportion to its weight.
+type SmartLinkDestination struct {
+	DestinationID  int64          `json:"destination_id" db:"destination_id"`
+	SmartLinkID    int64          `json:"smart_link_id" db:"smart_link_id"`
+	TrackingLinkID int64          `json:"tracking_link_id" db:"tracking_link_id"`
+	CampaignID     int64          `json:"campaign_id" db:"campaign_id"`
+	Weight         int            `json:"weight" db:"weight"`
+	Rules          SmartLinkRules `json:"rules" db:"rules"`
+	DailyClickCap  *int           `json:"daily_click_cap,omitempty" db:"daily_click_cap"` // Smart link clicks per UTC day
+	IsActive       bool           `json:"is_active" db:"is_active"`
+	Position       int            `json:"position" db:"position"`
+}
+
+// SmartLinkRules restrict the clicks a destination receives; empty rules match every click
+type SmartLinkRules struct {
+	Countries         []string           `json:"countries,omitempty"`          // ISO 3166-1 alpha-2 codes
+	ExcludedCountries []string           `json:"excluded_countries,omitempty"` // ISO 3166-1 alpha-2 codes
+	Devices           []string           `json:"devices,omitempty"`            // desktop, mobile, tablet
+	DayParts          []SmartLinkDayPart `json:"day_parts,omitempty"`
+	Timezone          string             `json:"timezone,omitempty"` // IANA name for day parts; UTC by default
+}
+
+// SmartLinkDayPart is a time window on some weekdays, from StartHour up to EndHour
+type SmartLinkDayPart struct {
+	Days      []string `json:"days,omitempty"` // sun, mon, ... sat; every day when empty
+	StartHour int      `json:"start_hour"`
+	EndHour   int      `json:"end_hour"`
+}
+
+// SmartLinkDestinationInput is a destination of a create or update request
+type SmartLinkDestinationInput struct {
+	TrackingLinkID int64          `json:"tracking_link_id" binding:"required"`
+	Weight         int            `json:"weight"`
+	Rules          SmartLinkRules `json:"rules"`
+	DailyClickCap  *int           `json:"daily_click_cap,omitempty"`
+	IsActive       *bool          `json:"is_active,omitempty"`
+}
+
+// CreateSmartLinkRequest creates a smart link
+type CreateSmartLinkRequest struct {
+	AffiliateID  int64                       `json:"affiliate_id" binding:"required"`
+	Name         string                      `json:"name" binding:"required,max=255"`
+	FallbackURL  *string                     `json:"fallback_url,omitempty"`
+	Destinations []SmartLinkDestinationInput `json:"destinations"`
+}
+
+// Validate validates and normalizes the CreateSmartLinkRequest
+func (r *CreateSmartLinkRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := validateSmartLinkFallbackURL(r.FallbackURL); err != nil {
+		return err
+	}
+	return validateSmartLinkDestinations(r.Destinations)
+}
+
+// UpdateSmartLinkRequest updates a smart link; destinations, when given, replace every destination
+type UpdateSmartLinkRequest struct {
+	Name         *string                     `json:"name,omitempty" binding:"omitempty,max=255"`
+	Status       *string                     `json:"status,omitempty" binding:"omitempty,oneof=active paused"`
+	FallbackURL  *string                     `json:"fallback_url,omitempty"` // "" removes the fallback URL
+	Destinations []SmartLinkDestinationInput `json:"destinations,omitempty"`
+}
+
+// Validate validates and normalizes the UpdateSmartLinkRequest
+func (r *UpdateSmartLinkRequest) Validate() error {
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	if r.Status != nil && *r.Status != SmartLinkStatusActive && *r.Status != SmartLinkStatusPaused {
+		return fmt.Errorf("status must be active or paused")
+	}
+	if r.FallbackURL != nil && *r.FallbackURL != "" {
+		if err := validateSmartLinkFallbackURL(r.FallbackURL); err != nil {
+			return err
+		}
+	}
+	if r.Destinations != nil {
+		return validateSmartLinkDestinations(r.Destinations)
+	}
+	return nil
+}
+
+func validateSmartLinkFallbackURL(fallbackURL *string) error {
+	if fallbackURL == nil {
+		return nil
+	}
+	parsed, err := url.Parse(*fallbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("fallback_url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func validateSmartLinkDestinations(destinations []SmartLinkDestinationInput) error {
+	if len(destinations) > MaxSmartLinkDestinations {
+		return fmt.Errorf("a smart link can have at most %d destinations", MaxSmartLinkDestinations)
+	}
+	seen := make(map[int64]bool)
+	for i := range destinations {
+		d := &destinations[i]
+		if seen[d.TrackingLinkID] {
+			return fmt.Errorf("tracking link %d is used by more than one destination", d.TrackingLinkID)
+		}
+		seen[d.TrackingLinkID] = true
+
+		if d.Weight == 0 {
+			d.Weight = 1
+		}
+		if d.Weight < 1 || d.Weight > MaxSmartLinkWeight {
+			return fmt.Errorf("destination weight must be between 1 and %d", MaxSmartLinkWeight)
+		}
+		if d.DailyClickCap != nil && *d.DailyClickCap < 1 {
+			return fmt.Errorf("daily_click_cap must be positive")
+		}
+		if err := d.Rules.Normalize(); err != nil {
+			return fmt.Errorf("destination %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Normalize validates the rules and normalizes country codes, devices and days
+func (r *SmartLinkRules) Normalize() error {
+	var err error
+	if r.Countries, err = normalizeCountryCodes(r.Countries); err != nil {
+		return err
+	}
+	if r.ExcludedCountries, err = normalizeCountryCodes(r.ExcludedCountries); err != nil {
+		return err
+	}
+	for i, device := range r.Devices {
+		device = strings.ToLower(strings.TrimSpace(device))
+		if device != DeviceTypeDesktop && device != DeviceTypeMobile && device != DeviceTypeTablet {
+			return fmt.Errorf("device %q must be desktop, mobile or tablet", device)
+		}
+		r.Devices[i] = device
+	}
+	if r.Timezone != "" {
+		if _, err := time.LoadLocation(r.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", r.Timezone)
+		}
+	}
+	for i := range r.DayParts {
+		part := &r.DayParts[i]
+		if part.StartHour < 0 || part.StartHour > 23 || part.EndHour < 1 || part.EndHour > 24 || part.StartHour == part.EndHour {
+			return fmt.Errorf("day parts need a start_hour of 0-23 and a different end_hour of 1-24")
+		}
+		for j, day := range part.Days {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if _, ok := smartLinkWeekdays[day]; !ok {
+				return fmt.Errorf("day %q must be one of sun, mon, tue, wed, thu, fri, sat", day)
+			}
+			part.Days[j] = day
+		}
+	}
+	return nil
+}
+
+func normalizeCountryCodes(codes []string) ([]string, error) {
+	for i, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("country %q must be an ISO 3166-1 alpha-2 code", code)
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// SmartLinkClick describes a click for rule evaluation
+type SmartLinkClick struct {
+	Country string    `json:"country,omitempty"` // ISO 3166-1 alpha-2 code; unknown when empty
+	Device  string    `json:"device,omitempty"`  // desktop, mobile or tablet; unknown when empty
+	Time    time.Time `json:"time"`
+}
+
+// SmartLinkDestinationState is what the rules of a destination are evaluated against besides the click
+type SmartLinkDestinationState struct {
+	TrackingLinkStatus string
+	CampaignStatus     string
+	TrackingURL        string
+	ClicksToday        int64
+
+	// The active window of the tracking link and the dates of its campaign, which the lifecycle
+	// job may not have applied to the status yet
+	ActiveFrom        *time.Time
+	ActiveUntil       *time.Time
+	CampaignStartDate *time.Time
+	CampaignEndDate   *time.Time
+}
+
+// linkIsLive reports whether the tracking link accepts clicks at the given time
+func (s SmartLinkDestinationState) linkIsLive(at time.Time) bool {
+	link := TrackingLink{Status: s.TrackingLinkStatus, ActiveFrom: s.ActiveFrom, ActiveUntil: s.ActiveUntil}
+	return link.IsLive(&Campaign{StartDate: s.CampaignStartDate, EndDate: s.CampaignEndDate}, at)
+}
+
+// SmartLinkDestinationEvaluation is the outcome of a destination for a click
+type SmartLinkDestinationEvaluation struct {
+	TrackingLinkID int64   `json:"tracking_link_id"`
+	CampaignID     int64   `json:"campaign_id"`
+	Weight         int     `json:"weight"`
+	Eligible       bool    `json:"eligible"`
+	SkipReason     string  `json:"skip_reason,omitempty"`
+	Probability    float64 `json:"probability"`
+	TrackingURL    string  `json:"-"`
+}
+
+// SmartLinkEvaluation is the outcome of every destination of a smart link for a click
+type SmartLinkEvaluation struct {
+	Destinations []SmartLinkDestinationEvaluation `json:"destinations"`
+	TotalWeight  int                              `json:"total_weight"`
+}
+
+// EvaluateSmartLink matches a click against every destination. Destinations without a state are
+// treated as unavailable.
+func EvaluateSmartLink(link *SmartLink, states map[int64]SmartLinkDestinationState, click SmartLinkClick) *SmartLinkEvaluation {
+	evaluation := &SmartLinkEvaluation{Destinations: make([]SmartLinkDestinationEvaluation, 0, len(link.Destinations))}
+	for _, destination := range link.Destinations {
+		state := states[destination.TrackingLinkID]
+		result := SmartLinkDestinationEvaluation{
+			TrackingLinkID: destination.TrackingLinkID,
+			CampaignID:     destination.CampaignID,
+			Weight:         destination.Weight,
+			TrackingURL:    state.TrackingURL,
+			SkipReason:     destination.skipReason(state, click),
+		}
+		if result.SkipReason == "" {
+			result.Eligible = true
+			evaluation.TotalWeight += destination.Weight
+		}
+		evaluation.Destinations = append(evaluation.Destinations, result)
+	}
+	if evaluation.TotalWeight > 0 {
+		for i := range evaluation.Destinations {
+			if evaluation.Destinations[i].Eligible {
+				evaluation.Destinations[i].Probability = float64(evaluation.Destinations[i].Weight) / float64(evaluation.TotalWeight)
+			}
+		}
+	}
+	return evaluation
+}
+
+// Pick returns the eligible destination selected by r in [0, 1), or nil when none is eligible
+func (e *SmartLinkEvaluation) Pick(r float64) *SmartLinkDestinationEvaluation {
+	if e.TotalWeight == 0 {
+		return nil
+	}
+	target := int(r * float64(e.TotalWeight))
+	for i := range e.Destinations {
+		d := &e.Destinations[i]
+		if !d.Eligible {
+			continue
+		}
+		if target < d.Weight {
+			return d
+		}
+		target -= d.Weight
+	}
+	return nil
+}
+
+func (d *SmartLinkDestination) skipReason(state SmartLinkDestinationState, click SmartLinkClick) string {
+	switch {
+	case !d.IsActive:
+		return SmartLinkSkipInactive
+	case !state.linkIsLive(click.Time):
+		return SmartLinkSkipLinkNotActive
+	case state.CampaignStatus != "active":
+		return SmartLinkSkipCampaignNotActive
+	case state.TrackingURL == "":
+		return SmartLinkSkipNoTrackingURL
+	case !d.Rules.matchesCountry(click.Country):
+		return SmartLinkSkipCountry
+	case len(d.Rules.Devices) > 0 && !containsString(d.Rules.Devices, click.Device):
+		return SmartLinkSkipDevice
+	case !d.Rules.matchesTime(click.Time):
+		return SmartLinkSkipDayPart
+	case d.DailyClickCap != nil && state.ClicksToday >= int64(*d.DailyClickCap):
+		return SmartLinkSkipCapReached
+	}
+	return ""
+}
+
+func (r *SmartLinkRules) matchesCountry(country string) bool {
+	country = strings.ToUpper(country)
+	if len(r.Countries) > 0 && !containsString(r.Countries, country) {
+		return false
+	}
+	return !containsString(r.ExcludedCountries, country)
+}
+
+func (r *SmartLinkRules) matchesTime(t time.Time) bool {
+	if len(r.DayParts) == 0 {
+		return true
+	}
+	location := time.UTC
+	if r.Timezone != "" {
+		if loaded, err := time.LoadLocation(r.Timezone); err == nil {
+			location = loaded
+		}
+	}
+	local := t.In(location)
+	hour := local.Hour()
+	for _, part := range r.DayParts {
+		// Windows ending before they start wrap past midnight and belong to their start day
+		day := local.Weekday()
+		inWindow := false
+		if part.StartHour < part.EndHour {
+			inWindow = hour >= part.StartHour && hour < part.EndHour
+		} else if hour >= part.StartHour {
+			inWindow = true
+		} else if hour < part.EndHour {
+			inWindow = true
+			day = (day + 6) % 7
+		}
+		if inWindow && part.matchesDay(day) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *SmartLinkDayPart) matchesDay(day time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, name := range p.Days {
+		if smartLinkWeekdays[name] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyDevice returns the device type of a User-Agent header, or "" when it is empty
+func ClassifyDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return ""
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") || strings.Contains(ua, "kindle") ||
+		strings.Contains(ua, "silk/") || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return DeviceTypeTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "ipod") ||
+		strings.Contains(ua, "windows phone") || strings.Contains(ua, "blackberry") || strings.Contains(ua, "opera mini"):
+		return DeviceTypeMobile
+	default:
+		return DeviceTypeDesktop
+	}
+}
+
+// SimulateSmartLinkRequest describes a click to evaluate against a smart link without redirecting.
+// Device is taken from UserAgent when not given; the current time is used when Time is not set.
+type SimulateSmartLinkRequest struct {
+	Country   string     `json:"country,omitempty" example:"US"`
+	Device    string     `json:"device,omitempty" example:"mobile"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	Time      *time.Time `json:"time,omitempty"`
+	Clicks    int        `json:"clicks,omitempty"` // When positive, draws this many clicks to show the distribution
+}
+
+// Validate validates the SimulateSmartLinkRequest
+func (r *SimulateSmartLinkRequest) Validate() error {
+	if r.Country != "" {
+		countries, err := normalizeCountryCodes([]string{r.Country})
+		if err != nil {
+			return err
+		}
+		r.Country = countries[0]
+	}
+	r.Device = strings.ToLower(strings.TrimSpace(r.Device))
+	if r.Device != "" && r.Device != DeviceTypeDesktop && r.Device != DeviceTypeMobile && r.Device != DeviceTypeTablet {
+		return fmt.Errorf("device must be desktop, mobile or tablet")
+	}
+	if r.Clicks < 0 || r.Clicks > MaxSmartLinkSimulatedClicks {
+		return fmt.Errorf("clicks must be between 0 and %d", MaxSmartLinkSimulatedClicks)
+	}
+	return nil
+}
+
+// SmartLinkSimulation is the outcome of a simulated click
+type SmartLinkSimulation struct {
+	Click        SmartLinkClick                   `json:"click"`
+	Destinations []SmartLinkDestinationEvaluation `json:"destinations"`
+	// Selected is the tracking link a click would be sent to, nil when it goes to the fallback URL
+	Selected     *int64  `json:"selected_tracking_link_id,omitempty"`
+	RedirectURL  *string `json:"redirect_url,omitempty"`
+	UsesFallback bool    `json:"uses_fallback"`
+	// Distribution counts the drawn clicks per tracking link ID, 0 standing for the fallback
+	Distribution map[int64]int `json:"distribution,omitempty"`
+}
+
+// SmartLinkDestinationStats counts the clicks a smart link sent to a destination
+type SmartLinkDestinationStats struct {
+	TrackingLinkID int64   `json:"tracking_link_id"` // 0 for the fallback URL
+	CampaignID     *int64  `json:"campaign_id,omitempty"`
+	Clicks         int64   `json:"clicks"`
+	Share          float64 `json:"share"`
+}
+
+// SmartLinkDailyStats counts a smart link's clicks on a day
+type SmartLinkDailyStats struct {
+	Date           string `json:"date"`
+	TrackingLinkID int64  `json:"tracking_link_id"`
+	CampaignID     *int64 `json:"campaign_id,omitempty"`
+	Clicks         int64  `json:"clicks"`
+}
+
+// SmartLinkStats summarizes the clicks of a smart link over a date range
+type SmartLinkStats struct {
+	SmartLinkID    int64                       `json:"smart_link_id"`
+	From           string                      `json:"from"`
+	To             string                      `json:"to"`
+	TotalClicks    int64                       `json:"total_clicks"`
+	FallbackClicks int64                       `json:"fallback_clicks"`
+	Destinations   []SmartLinkDestinationStats `json:"destinations"`
+	Daily          []SmartLinkDailyStats       `json:"daily"`
+}
@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func smartLinkTestLink() *SmartLink {
+	dailyCap := 10
+	return &SmartLink{
+		Destinations: []*SmartLinkDestination{
+			{TrackingLinkID: 1, CampaignID: 11, Weight: 3, IsActive: true, Rules: SmartLinkRules{Countries: []string{"US", "CA"}}},
+			{TrackingLinkID: 2, CampaignID: 12, Weight: 1, IsActive: true, Rules: SmartLinkRules{Devices: []string{DeviceTypeMobile}}},
+			{TrackingLinkID: 3, CampaignID: 13, Weight: 1, IsActive: true, DailyClickCap: &dailyCap},
+		},
+	}
+}
+
+func smartLinkTestStates() map[int64]SmartLinkDestinationState {
+	return map[int64]SmartLinkDestinationState{
+		1: {TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/1"},
+		2: {TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/2"},
+		3: {TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/3"},
+	}
+}
+
+func TestEvaluateSmartLink(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		click       SmartLinkClick
+		mutate      func(link *SmartLink, states map[int64]SmartLinkDestinationState)
+		wantReasons map[int64]string
+		wantTotal   int
+	}{
+		{
+			name:        "all match",
+			click:       SmartLinkClick{Country: "US", Device: DeviceTypeMobile, Time: now},
+			wantReasons: map[int64]string{1: "", 2: "", 3: ""},
+			wantTotal:   5,
+		},
+		{
+			name:        "country and device",
+			click:       SmartLinkClick{Country: "FR", Device: DeviceTypeDesktop, Time: now},
+			wantReasons: map[int64]string{1: SmartLinkSkipCountry, 2: SmartLinkSkipDevice, 3: ""},
+			wantTotal:   1,
+		},
+		{
+			name:  "campaign paused and cap reached",
+			click: SmartLinkClick{Country: "US", Device: DeviceTypeMobile, Time: now},
+			mutate: func(link *SmartLink, states map[int64]SmartLinkDestinationState) {
+				states[1] = SmartLinkDestinationState{TrackingLinkStatus: "active", CampaignStatus: "paused", TrackingURL: "https://t/1"}
+				states[3] = SmartLinkDestinationState{TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/3", ClicksToday: 10}
+			},
+			wantReasons: map[int64]string{1: SmartLinkSkipCampaignNotActive, 2: "", 3: SmartLinkSkipCapReached},
+			wantTotal:   1,
+		},
+		{
+			name:  "inactive and missing state",
+			click: SmartLinkClick{Country: "US", Device: DeviceTypeMobile, Time: now},
+			mutate: func(link *SmartLink, states map[int64]SmartLinkDestinationState) {
+				link.Destinations[0].IsActive = false
+				delete(states, 2)
+			},
+			wantReasons: map[int64]string{1: SmartLinkSkipInactive, 2: SmartLinkSkipLinkNotActive, 3: ""},
+			wantTotal:   1,
+		},
+		{
+			name:  "outside link window and campaign dates",
+			click: SmartLinkClick{Country: "US", Device: DeviceTypeMobile, Time: now},
+			mutate: func(link *SmartLink, states map[int64]SmartLinkDestinationState) {
+				until, campaignEnd := now.Add(-time.Minute), now.AddDate(0, 0, -1)
+				from := now.Add(time.Minute)
+				states[1] = SmartLinkDestinationState{TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/1", ActiveUntil: &until}
+				states[2] = SmartLinkDestinationState{TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/2", ActiveFrom: &from}
+				states[3] = SmartLinkDestinationState{TrackingLinkStatus: "active", CampaignStatus: "active", TrackingURL: "https://t/3", CampaignEndDate: &campaignEnd}
+			},
+			wantReasons: map[int64]string{1: SmartLinkSkipLinkNotActive, 2: SmartLinkSkipLinkNotActive, 3: SmartLinkSkipLinkNotActive},
+			wantTotal:   0,
+		},
+		{
+			name:  "day part",
+			click: SmartLinkClick{Country: "US", Device: DeviceTypeMobile, Time: now},
+			mutate: func(link *SmartLink, states map[int64]SmartLinkDestinationState) {
+				link.Destinations[2].Rules.DayParts = []SmartLinkDayPart{{Days: []string{"sat", "sun"}, StartHour: 0, EndHour: 24}}
+			},
+			wantReasons: map[int64]string{1: "", 2: "", 3: SmartLinkSkipDayPart},
+			wantTotal:   4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, states := smartLinkTestLink(), smartLinkTestStates()
+			if tt.mutate != nil {
+				tt.mutate(link, states)
+			}
+			evaluation := EvaluateSmartLink(link, states, tt.click)
+			if evaluation.TotalWeight != tt.wantTotal {
+				t.Errorf("TotalWeight = %d, want %d", evaluation.TotalWeight, tt.wantTotal)
+			}
+			for _, d := range evaluation.Destinations {
+				if d.SkipReason != tt.wantReasons[d.TrackingLinkID] {
+					t.Errorf("destination %d SkipReason = %q, want %q", d.TrackingLinkID, d.SkipReason, tt.wantReasons[d.TrackingLinkID])
+				}
+				if d.Eligible != (d.SkipReason == "") {
+					t.Errorf("destination %d Eligible = %v with reason %q", d.TrackingLinkID, d.Eligible, d.SkipReason)
+				}
+			}
+		})
+	}
+}
+
+func TestSmartLinkEvaluation_Pick(t *testing.T) {
+	evaluation := EvaluateSmartLink(smartLinkTestLink(), smartLinkTestStates(),
+		SmartLinkClick{Country: "US", Device: DeviceTypeMobile, Time: time.Now()})
+
+	// Weights 3, 1 and 1 split [0, 1) into 0.6, 0.2 and 0.2
+	tests := map[float64]int64{0: 1, 0.59: 1, 0.6: 2, 0.79: 2, 0.8: 3, 0.999: 3}
+	for r, want := range tests {
+		if got := evaluation.Pick(r); got == nil || got.TrackingLinkID != want {
+			t.Errorf("Pick(%v) = %v, want tracking link %d", r, got, want)
+		}
+	}
+	if p := evaluation.Destinations[0].Probability; p != 0.6 {
+		t.Errorf("Probability = %v, want 0.6", p)
+	}
+
+	empty := EvaluateSmartLink(&SmartLink{}, nil, SmartLinkClick{})
+	if got := empty.Pick(0.5); got != nil {
+		t.Errorf("Pick() without destinations = %v, want nil", got)
+	}
+}
+
+func TestSmartLinkRules_MatchesTime(t *testing.T) {
+	// Night window from 22:00 to 06:00 starting on Fridays
+	rules := SmartLinkRules{DayParts: []SmartLinkDayPart{{Days: []string{"fri"}, StartHour: 22, EndHour: 6}}}
+	tests := []struct {
+		time time.Time
+		want bool
+	}{
+		{time.Date(2024, 6, 7, 23, 0, 0, 0, time.UTC), true},  // Friday 23:00
+		{time.Date(2024, 6, 8, 5, 0, 0, 0, time.UTC), true},   // Saturday 05:00, started Friday
+		{time.Date(2024, 6, 8, 23, 0, 0, 0, time.UTC), false}, // Saturday 23:00
+		{time.Date(2024, 6, 7, 5, 0, 0, 0, time.UTC), false},  // Friday 05:00, started Thursday
+		{time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC), false}, // Friday noon
+	}
+	for _, tt := range tests {
+		if got := rules.matchesTime(tt.time); got != tt.want {
+			t.Errorf("matchesTime(%v) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+
+	tz := SmartLinkRules{Timezone: "America/New_York", DayParts: []SmartLinkDayPart{{StartHour: 9, EndHour: 17}}}
+	if !tz.matchesTime(time.Date(2024, 6, 7, 14, 0, 0, 0, time.UTC)) { // 10:00 in New York
+		t.Errorf("matchesTime() in New York business hours = false")
+	}
+	if tz.matchesTime(time.Date(2024, 6, 7, 10, 0, 0, 0, time.UTC)) { // 06:00 in New York
+		t.Errorf("matchesTime() before New York business hours = true")
+	}
+}
+
+func TestCreateSmartLinkRequest_Validate(t *testing.T) {
+	fallback := "https://example.com/offers"
+	badFallback := "example.com"
+	tests := []struct {
+		name    string
+		req     CreateSmartLinkRequest
+		wantErr bool
+	}{
+		{name: "valid", req: CreateSmartLinkRequest{Name: "Mix", FallbackURL: &fallback, Destinations: []SmartLinkDestinationInput{
+			{TrackingLinkID: 1, Rules: SmartLinkRules{Countries: []string{"us"}, Devices: []string{"Mobile"}}},
+		}}},
+		{name: "relative fallback", req: CreateSmartLinkRequest{Name: "Mix", FallbackURL: &badFallback}, wantErr: true},
+		{name: "duplicate destination", req: CreateSmartLinkRequest{Name: "Mix", Destinations: []SmartLinkDestinationInput{
+			{TrackingLinkID: 1}, {TrackingLinkID: 1},
+		}}, wantErr: true},
+		{name: "weight too high", req: CreateSmartLinkRequest{Name: "Mix", Destinations: []SmartLinkDestinationInput{
+			{TrackingLinkID: 1, Weight: MaxSmartLinkWeight + 1},
+		}}, wantErr: true},
+		{name: "bad country", req: CreateSmartLinkRequest{Name: "Mix", Destinations: []SmartLinkDestinationInput{
+			{TrackingLinkID: 1, Rules: SmartLinkRules{Countries: []string{"USA"}}},
+		}}, wantErr: true},
+		{name: "bad day part", req: CreateSmartLinkRequest{Name: "Mix", Destinations: []SmartLinkDestinationInput{
+			{TrackingLinkID: 1, Rules: SmartLinkRules{DayParts: []SmartLinkDayPart{{StartHour: 8, EndHour: 8}}}},
+		}}, wantErr: true},
+		{name: "bad timezone", req: CreateSmartLinkRequest{Name: "Mix", Destinations: []SmartLinkDestinationInput{
+			{TrackingLinkID: 1, Rules: SmartLinkRules{Timezone: "Mars/Olympus"}},
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := tests[0].req
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if d := req.Destinations[0]; d.Weight != 1 || d.Rules.Countries[0] != "US" || d.Rules.Devices[0] != DeviceTypeMobile {
+		t.Errorf("Validate() did not normalize the destination: %+v", d)
+	}
+}
+
+func TestClassifyDevice(t *testing.T) {
+	tests := map[string]string{
+		"": "",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148":      DeviceTypeMobile,
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36":  DeviceTypeMobile,
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 Chrome/120.0 Safari/537.36":         DeviceTypeTablet,
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148":               DeviceTypeTablet,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36":        DeviceTypeDesktop,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15": DeviceTypeDesktop,
+	}
+	for ua, want := range tests {
+		if got := ClassifyDevice(ua); got != want {
+			t.Errorf("ClassifyDevice(%q) = %q, want %q", ua, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Tracking domain statuses
+const (
+	TrackingDomainStatusPending  = "pending"  // Registered, the TXT record was not checked yet
+	TrackingDomainStatusVerified = "verified" // The TXT record holds the verification token
+	TrackingDomainStatusFailed   = "failed"   // The TXT record is missing or holds another token
+)
+
+const (
+	// TrackingDomainVerificationLabel is prepended to the hostname to name the TXT record
+	TrackingDomainVerificationLabel = "_affiliate-verification"
+	// TrackingDomainVerificationPrefix precedes the token in the TXT record value
+	TrackingDomainVerificationPrefix = "affiliate-domain-verification="
+	// MaxTrackingDomainsPerOrganization is the maximum number of tracking domains per organization
+	MaxTrackingDomainsPerOrganization = 20
+	// TrackingDomainRecheckInterval is how often verified domains are checked again
+	TrackingDomainRecheckInterval = 24 * time.Hour
+	// TrackingLinkRedirectPath is the path of native tracking links, followed by the tracking link ID
+	TrackingLinkRedirectPath = "/c/"
+)
+
+// TrackingDomain is a custom hostname (e.g. go.brand.com) an organization serves its tracking
+// links on. The hostname must point at the API, and the organization proves control of it with
+// a DNS TXT record.
+type TrackingDomain struct {
+	TrackingDomainID  int64      `json:"tracking_domain_id" db:"tracking_domain_id"`
+	OrganizationID    int64      `json:"organization_id" db:"organization_id"`
+	Hostname          string     `json:"hostname" db:"hostname"`
+	Status            string     `json:"status" db:"status"`
+	VerificationToken string     `json:"-" db:"verification_token"`
+	IsDefault         bool       `json:"is_default" db:"is_default"` // Used for the organization's links without a campaign override
+	VerifiedAt        *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+	LastCheckedAt     *time.Time `json:"last_checked_at,omitempty" db:"last_checked_at"`
+	LastError         *string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Verification is the DNS record to create; set in API responses
+	Verification *TrackingDomainVerificationRecord `json:"verification,omitempty" db:"-"`
+}
+
+// TrackingDomainVerificationRecord is the DNS record that proves control of a tracking domain
+type TrackingDomainVerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// VerificationRecordName returns the name of the TXT record checked for the domain
+func (d *TrackingDomain) VerificationRecordName() string {
+	return TrackingDomainVerificationLabel + "." + d.Hostname
+}
+
+// VerificationRecordValue returns the value the TXT record must hold
+func (d *TrackingDomain) VerificationRecordValue() string {
+	return TrackingDomainVerificationPrefix + d.VerificationToken
+}
+
+// IsVerified reports whether links may be served on the domain
+func (d *TrackingDomain) IsVerified() bool {
+	return d.Status == TrackingDomainStatusVerified
+}
+
+// CreateTrackingDomainRequest represents the request to register a tracking domain
+type CreateTrackingDomainRequest struct {
+	Hostname string `json:"hostname" binding:"required" example:"go.brand.com"`
+}
+
+// SetCampaignTrackingDomainRequest selects the tracking domain of a campaign's links; null
+// removes the override so that the organization's default domain is used
+type SetCampaignTrackingDomainRequest struct {
+	TrackingDomainID *int64 `json:"tracking_domain_id"`
+}
+
+// NormalizeHostname lowercases a hostname and checks that it is a fully qualified domain name
+// without scheme, port or path
+func NormalizeHostname(hostname string) (string, error) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+	if hostname == "" {
+		return "", fmt.Errorf("hostname is required")
+	}
+	if len(hostname) > 253 {
+		return "", fmt.Errorf("hostname must be at most 253 characters")
+	}
+	if strings.ContainsAny(hostname, ":/?#@ ") {
+		return "", fmt.Errorf("hostname must not include a scheme, port or path")
+	}
+	if net.ParseIP(hostname) != nil {
+		return "", fmt.Errorf("hostname must be a domain name, not an IP address")
+	}
+
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("hostname must be a fully qualified domain name such as go.brand.com")
+	}
+	for _, label := range labels {
+		if len(label) < 1 || len(label) > 63 {
+			return "", fmt.Errorf("each hostname label must be 1-63 characters")
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return "", fmt.Errorf("hostname labels must not start or end with a hyphen")
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' {
+				return "", fmt.Errorf("hostname may only contain letters, digits, hyphens and dots; use punycode for internationalized names")
+			}
+		}
+	}
+	return hostname, nil
+}
+
+// RequestHostname returns the hostname of an HTTP Host header, without the port
+func RequestHostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeHostname(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		want     string
+		wantErr  bool
+	}{
+		{name: "valid", hostname: "go.brand.com", want: "go.brand.com"},
+		{name: "uppercase and trailing dot", hostname: " Go.Brand.COM. ", want: "go.brand.com"},
+		{name: "punycode", hostname: "xn--bcher-kva.example", want: "xn--bcher-kva.example"},
+		{name: "empty", hostname: "", wantErr: true},
+		{name: "single label", hostname: "localhost", wantErr: true},
+		{name: "scheme", hostname: "https://go.brand.com", wantErr: true},
+		{name: "port", hostname: "go.brand.com:8080", wantErr: true},
+		{name: "path", hostname: "go.brand.com/c", wantErr: true},
+		{name: "ip address", hostname: "10.0.0.1", wantErr: true},
+		{name: "leading hyphen", hostname: "-go.brand.com", wantErr: true},
+		{name: "empty label", hostname: "go..brand.com", wantErr: true},
+		{name: "underscore", hostname: "go_links.brand.com", wantErr: true},
+		{name: "label too long", hostname: strings.Repeat("a", 64) + ".com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeHostname(tt.hostname)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NormalizeHostname() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeHostname() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestHostname(t *testing.T) {
+	tests := map[string]string{
+		"go.brand.com":     "go.brand.com",
+		"GO.Brand.com:443": "go.brand.com",
+		"go.brand.com.":    "go.brand.com",
+		"[::1]:8080":       "::1",
+		"localhost:8080":   "localhost",
+	}
+	for host, want := range tests {
+		if got := RequestHostname(host); got != want {
+			t.Errorf("RequestHostname(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestTrackingDomain_VerificationRecord(t *testing.T) {
+	d := TrackingDomain{Hostname: "go.brand.com", VerificationToken: "abc123"}
+	if got := d.VerificationRecordName(); got != "_affiliate-verification.go.brand.com" {
+		t.Errorf("VerificationRecordName() = %q", got)
+	}
+	if got := d.VerificationRecordValue(); got != "affiliate-domain-verification=abc123" {
+		t.Errorf("VerificationRecordValue() = %q", got)
+	}
+}
@@ -2,9 +2,26 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
+// Tracking link statuses. Scheduled links wait for their active window, or their campaign, to
+// start; the lifecycle job activates them and archives links whose window or campaign ended.
+const (
+	TrackingLinkStatusScheduled = "scheduled"
+	TrackingLinkStatusActive    = "active"
+	TrackingLinkStatusPaused    = "paused"
+	TrackingLinkStatusArchived  = "archived"
+)
+
+// Reasons recorded for status changes made automatically rather than by a user
+const (
+	TrackingLinkStatusReasonAssociationSuspended = "association_suspended" // Resumed when the association is reactivated
+	TrackingLinkStatusReasonExpired              = "expired"               // The link's active_until passed
+	TrackingLinkStatusReasonCampaignEnded        = "campaign_ended"        // The campaign's end date passed
+)
+
 // TrackingLink represents a clean tracking link entity following clean architecture principles
 type TrackingLink struct {
 	TrackingLinkID int64   `json:"tracking_link_id" db:"tracking_link_id"`
@@ -13,7 +30,7 @@ type TrackingLink struct {
 	AffiliateID    int64   `json:"affiliate_id" db:"affiliate_id"`
 	Name           string  `json:"name" db:"name"`
 	Description    *string `json:"description,omitempty" db:"description"`
-	Status         string  `json:"status" db:"status"` // 'active', 'paused', 'archived'
+	Status         string  `json:"status" db:"status"` // 'scheduled', 'active', 'paused', 'archived'
 
 	// Core tracking link fields (provider-agnostic)
 	TrackingURL *string `json:"tracking_url,omitempty" db:"tracking_url"`
@@ -32,10 +49,112 @@ type TrackingLink struct {
 	InternalNotes *string `json:"internal_notes,omitempty" db:"internal_notes"`
 	Tags          *string `json:"tags,omitempty" db:"tags"` // JSONB stored as string (array of strings)
 
+	// Lifecycle: the link only accepts clicks between ActiveFrom and ActiveUntil, within the
+	// campaign dates. StatusReason explains automatic status changes.
+	ActiveFrom   *time.Time `json:"active_from,omitempty" db:"active_from"`
+	ActiveUntil  *time.Time `json:"active_until,omitempty" db:"active_until"`
+	StatusReason *string    `json:"status_reason,omitempty" db:"status_reason"`
+
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// IsValidTrackingLinkStatus checks if a tracking link status is valid
+func IsValidTrackingLinkStatus(status string) bool {
+	switch status {
+	case TrackingLinkStatusScheduled, TrackingLinkStatusActive, TrackingLinkStatusPaused, TrackingLinkStatusArchived:
+		return true
+	}
+	return false
+}
+
+// ValidateActiveWindow checks that the active window of the link is not empty
+func (l *TrackingLink) ValidateActiveWindow() error {
+	if l.ActiveFrom != nil && l.ActiveUntil != nil && !l.ActiveUntil.After(*l.ActiveFrom) {
+		return fmt.Errorf("active_until must be after active_from")
+	}
+	return nil
+}
+
+// LifecycleStatus returns the status the active window of the link and the dates of its
+// campaign give it at now, with the reason when it is archived. Campaign dates are whole UTC
+// days: a campaign runs until the end of its end date. campaign may be nil to only consider the
+// link's window.
+func (l *TrackingLink) LifecycleStatus(campaign *Campaign, now time.Time) (string, *string) {
+	reason := func(r string) *string { return &r }
+
+	if l.ActiveUntil != nil && !now.Before(*l.ActiveUntil) {
+		return TrackingLinkStatusArchived, reason(TrackingLinkStatusReasonExpired)
+	}
+	if campaign != nil && campaign.EndDate != nil && !now.Before(campaign.EndDate.AddDate(0, 0, 1)) {
+		return TrackingLinkStatusArchived, reason(TrackingLinkStatusReasonCampaignEnded)
+	}
+	if l.ActiveFrom != nil && now.Before(*l.ActiveFrom) {
+		return TrackingLinkStatusScheduled, nil
+	}
+	if campaign != nil && campaign.StartDate != nil && now.Before(*campaign.StartDate) {
+		return TrackingLinkStatusScheduled, nil
+	}
+	return TrackingLinkStatusActive, nil
+}
+
+// ApplyLifecycle moves an active or scheduled link to the status its window gives it at now and
+// reports whether the status changed. Paused and archived links are left as they are.
+func (l *TrackingLink) ApplyLifecycle(campaign *Campaign, now time.Time) bool {
+	if l.Status != TrackingLinkStatusActive && l.Status != TrackingLinkStatusScheduled {
+		return false
+	}
+	status, reason := l.LifecycleStatus(campaign, now)
+	if status == l.Status {
+		return false
+	}
+	l.Status = status
+	l.StatusReason = reason
+	return true
+}
+
+// ApplyLifecycleTransition is ApplyLifecycle for the lifecycle job: an ended window or campaign
+// archives paused links too, so that they are not resumed later
+func (l *TrackingLink) ApplyLifecycleTransition(campaign *Campaign, now time.Time) bool {
+	if l.Status == TrackingLinkStatusPaused {
+		status, reason := l.LifecycleStatus(campaign, now)
+		if status != TrackingLinkStatusArchived {
+			return false
+		}
+		l.Status = status
+		l.StatusReason = reason
+		return true
+	}
+	return l.ApplyLifecycle(campaign, now)
+}
+
+// Resume moves a paused link to the status its window gives it at now
+func (l *TrackingLink) Resume(campaign *Campaign, now time.Time) {
+	l.Status, l.StatusReason = l.LifecycleStatus(campaign, now)
+}
+
+// IsLive reports whether the link accepts clicks at now
+func (l *TrackingLink) IsLive(campaign *Campaign, now time.Time) bool {
+	status, _ := l.LifecycleStatus(campaign, now)
+	return l.Status == TrackingLinkStatusActive && status == TrackingLinkStatusActive
+}
+
+// CheckAcceptsClick returns an ErrNotFound error when the link does not accept a click at now:
+// it or its campaign is not active, or now is outside the window they give it
+func (l *TrackingLink) CheckAcceptsClick(campaign *Campaign, now time.Time) error {
+	if l.Status != TrackingLinkStatusActive {
+		return fmt.Errorf("tracking link is %s: %w", l.Status, ErrNotFound)
+	}
+	if campaign.Status != "active" {
+		return fmt.Errorf("campaign is %s: %w", campaign.Status, ErrNotFound)
+	}
+	// The lifecycle job may not have caught up with a window that just closed
+	if !l.IsLive(campaign, now) {
+		return fmt.Errorf("tracking link is outside its active window: %w", ErrNotFound)
+	}
+	return nil
+}
+
 // TrackingLinkProviderMapping represents a mapping between a tracking link and a provider
 type TrackingLinkProviderMapping struct {
 	MappingID              int64   `json:"mapping_id" db:"mapping_id"`
@@ -108,6 +227,10 @@ type TrackingLinkGenerationRequest struct {
 	IsEncryptParameters *bool `json:"is_encrypt_parameters,omitempty"`
 	IsRedirectLink      *bool `json:"is_redirect_link,omitempty"`
 
+	// Active window of the link
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+
 	// Provider-specific options
 	NetworkTrackingDomainID *int32 `json:"network_tracking_domain_id,omitempty"`
 	NetworkOfferURLID       *int32 `json:"network_offer_url_id,omitempty"`
@@ -141,6 +264,10 @@ type TrackingLinkUpsertRequest struct {
 	IsEncryptParameters *bool `json:"is_encrypt_parameters,omitempty"`
 	IsRedirectLink      *bool `json:"is_redirect_link,omitempty"`
 
+	// Active window of the link
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+
 	// Provider-specific options
 	NetworkTrackingDomainID *int32 `json:"network_tracking_domain_id,omitempty"`
 	NetworkOfferURLID       *int32 `json:"network_offer_url_id,omitempty"`
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bulk tracking link job statuses
+const (
+	BulkLinkJobStatusRunning   = "running"
+	BulkLinkJobStatusCompleted = "completed"
+	BulkLinkJobStatusFailed    = "failed"
+)
+
+// Bulk tracking link job item statuses
+const (
+	BulkLinkItemStatusPending    = "pending"
+	BulkLinkItemStatusProcessing = "processing" // Claimed by the instance generating its link
+	BulkLinkItemStatusSucceeded  = "succeeded"
+	BulkLinkItemStatusFailed     = "failed"
+)
+
+const (
+	// MaxBulkLinkJobAffiliates is the largest number of affiliates in one job
+	MaxBulkLinkJobAffiliates = 2000
+	// DefaultBulkLinkConcurrency is the number of links generated at once when not given
+	DefaultBulkLinkConcurrency = 4
+	// MaxBulkLinkConcurrency bounds the links generated at once, to stay within provider rate limits
+	MaxBulkLinkConcurrency = 10
+	// BulkLinkJobLeaseDuration is how long a running job stays with an instance without a heartbeat;
+	// another instance takes it over afterwards
+	BulkLinkJobLeaseDuration = 2 * time.Minute
+	// DefaultBulkLinkNameTemplate names the links of a job when no name template is given
+	DefaultBulkLinkNameTemplate = "{campaign_name} - {affiliate_name}"
+)
+
+// Placeholders of tracking link templates
+const (
+	LinkTemplateCampaignID    = "campaign_id"
+	LinkTemplateCampaignName  = "campaign_name"
+	LinkTemplateAffiliateID   = "affiliate_id"
+	LinkTemplateAffiliateName = "affiliate_name"
+	LinkTemplateAffiliateOrg  = "affiliate_org_id"
+	LinkTemplateDate          = "date" // Job creation date, YYYY-MM-DD
+)
+
+var linkTemplatePlaceholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// TrackingLinkTemplate holds the name and tracking parameters of the links of a bulk job. Values
+// may contain {placeholder}s filled in per affiliate.
+type TrackingLinkTemplate struct {
+	Name     string  `json:"name,omitempty"`
+	SourceID *string `json:"source_id,omitempty"`
+	Sub1     *string `json:"sub1,omitempty"`
+	Sub2     *string `json:"sub2,omitempty"`
+	Sub3     *string `json:"sub3,omitempty"`
+	Sub4     *string `json:"sub4,omitempty"`
+	Sub5     *string `json:"sub5,omitempty"`
+}
+
+// Validate checks that the template only uses known placeholders
+func (t *TrackingLinkTemplate) Validate() error {
+	fields := map[string]*string{"name": &t.Name, "source_id": t.SourceID, "sub1": t.Sub1, "sub2": t.Sub2, "sub3": t.Sub3, "sub4": t.Sub4, "sub5": t.Sub5}
+	for field, value := range fields {
+		if value == nil {
+			continue
+		}
+		if len(*value) > 255 {
+			return fmt.Errorf("%s must be at most 255 characters", field)
+		}
+		for _, match := range linkTemplatePlaceholder.FindAllStringSubmatch(*value, -1) {
+			switch match[1] {
+			case LinkTemplateCampaignID, LinkTemplateCampaignName, LinkTemplateAffiliateID,
+				LinkTemplateAffiliateName, LinkTemplateAffiliateOrg, LinkTemplateDate:
+			default:
+				return fmt.Errorf("%s uses the unknown placeholder {%s}", field, match[1])
+			}
+		}
+	}
+	return nil
+}
+
+// Render fills in the placeholders for an affiliate. Empty rendered parameters are left unset.
+func (t *TrackingLinkTemplate) Render(campaign *Campaign, affiliate *Affiliate, date time.Time) TrackingLinkTemplate {
+	values := map[string]string{
+		LinkTemplateCampaignID:    strconv.FormatInt(campaign.CampaignID, 10),
+		LinkTemplateCampaignName:  campaign.Name,
+		LinkTemplateAffiliateID:   strconv.FormatInt(affiliate.AffiliateID, 10),
+		LinkTemplateAffiliateName: affiliate.Name,
+		LinkTemplateAffiliateOrg:  strconv.FormatInt(affiliate.OrganizationID, 10),
+		LinkTemplateDate:          date.Format("2006-01-02"),
+	}
+	render := func(value string) string {
+		return linkTemplatePlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+			return values[strings.Trim(placeholder, "{}")]
+		})
+	}
+	renderOptional := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		rendered := strings.TrimSpace(render(*value))
+		if rendered == "" {
+			return nil
+		}
+		return &rendered
+	}
+
+	name := t.Name
+	if strings.TrimSpace(name) == "" {
+		name = DefaultBulkLinkNameTemplate
+	}
+	rendered := TrackingLinkTemplate{
+		Name:     strings.TrimSpace(render(name)),
+		SourceID: renderOptional(t.SourceID),
+		Sub1:     renderOptional(t.Sub1),
+		Sub2:     renderOptional(t.Sub2),
+		Sub3:     renderOptional(t.Sub3),
+		Sub4:     renderOptional(t.Sub4),
+		Sub5:     renderOptional(t.Sub5),
+	}
+	if len(rendered.Name) > 255 {
+		rendered.Name = rendered.Name[:255]
+	}
+	return rendered
+}
+
+// BulkTrackingLinkJob generates or upserts the tracking links of a campaign for many affiliates
+type BulkTrackingLinkJob struct {
+	JobID                int64                `json:"job_id" db:"job_id"`
+	OrganizationID       int64                `json:"organization_id" db:"organization_id"`
+	CampaignID           int64                `json:"campaign_id" db:"campaign_id"`
+	Status               string               `json:"status" db:"status"`
+	AllVisibleAffiliates bool                 `json:"all_visible_affiliates" db:"all_visible_affiliates"`
+	Template             TrackingLinkTemplate `json:"template" db:"template"`
+	IsEncryptParameters  *bool                `json:"is_encrypt_parameters,omitempty" db:"is_encrypt_parameters"`
+	IsRedirectLink       *bool                `json:"is_redirect_link,omitempty" db:"is_redirect_link"`
+	Concurrency          int                  `json:"concurrency" db:"concurrency"`
+	TotalCount           int                  `json:"total_count" db:"total_count"`
+	SucceededCount       int                  `json:"succeeded_count" db:"succeeded_count"`
+	FailedCount          int                  `json:"failed_count" db:"failed_count"`
+	ErrorMessage         *string              `json:"error_message,omitempty" db:"error_message"`
+	CreatedByUserID      *string              `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	CreatedAt            time.Time            `json:"created_at" db:"created_at"`
+	CompletedAt          *time.Time           `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// ProcessedCount returns the number of items done, successfully or not
+func (j *BulkTrackingLinkJob) ProcessedCount() int {
+	return j.SucceededCount + j.FailedCount
+}
+
+// BulkTrackingLinkJobItem is the link of one affiliate in a bulk job
+type BulkTrackingLinkJobItem struct {
+	ItemID         int64                `json:"item_id" db:"item_id"`
+	JobID          int64                `json:"job_id" db:"job_id"`
+	AffiliateID    int64                `json:"affiliate_id" db:"affiliate_id"`
+	AffiliateName  string               `json:"affiliate_name" db:"affiliate_name"`
+	Status         string               `json:"status" db:"status"`
+	Parameters     TrackingLinkTemplate `json:"parameters" db:"parameters"` // Rendered template
+	TrackingLinkID *int64               `json:"tracking_link_id,omitempty" db:"tracking_link_id"`
+	TrackingURL    *string              `json:"tracking_url,omitempty" db:"tracking_url"`
+	IsNew          *bool                `json:"is_new,omitempty" db:"is_new"`
+	ErrorMessage   *string              `json:"error_message,omitempty" db:"error_message"`
+	ProcessedAt    *time.Time           `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+// BulkTrackingLinkJobWithProgress is a job with its completion percentage
+type BulkTrackingLinkJobWithProgress struct {
+	*BulkTrackingLinkJob
+	ProcessedCount int     `json:"processed_count"`
+	Progress       float64 `json:"progress"` // Percentage of items processed
+}
+
+// NewBulkTrackingLinkJobWithProgress computes the progress of a job
+func NewBulkTrackingLinkJobWithProgress(job *BulkTrackingLinkJob) *BulkTrackingLinkJobWithProgress {
+	progress := 100.0
+	if job.TotalCount > 0 {
+		progress = float64(job.ProcessedCount()*1000/job.TotalCount) / 10
+	}
+	return &BulkTrackingLinkJobWithProgress{BulkTrackingLinkJob: job, ProcessedCount: job.ProcessedCount(), Progress: progress}
+}
+
+// BulkTrackingLinkJobListResponse is a page of bulk tracking link jobs
+type BulkTrackingLinkJobListResponse struct {
+	Jobs     []*BulkTrackingLinkJobWithProgress `json:"jobs"`
+	Total    int                                `json:"total"`
+	Page     int                                `json:"page"`
+	PageSize int                                `json:"page_size"`
+}
+
+// CreateBulkTrackingLinkJobRequest starts a bulk job for the given affiliates, or for all
+// affiliates visible to the campaign through active associations
+type CreateBulkTrackingLinkJobRequest struct {
+	CampaignID           int64                `json:"campaign_id" binding:"required"`
+	AffiliateIDs         []int64              `json:"affiliate_ids,omitempty"`
+	AllVisibleAffiliates bool                 `json:"all_visible_affiliates"`
+	Template             TrackingLinkTemplate `json:"template"`
+	IsEncryptParameters  *bool                `json:"is_encrypt_parameters,omitempty"`
+	IsRedirectLink       *bool                `json:"is_redirect_link,omitempty"`
+	Concurrency          int                  `json:"concurrency,omitempty"` // Links generated at once (default 4, max 10)
+}
+
+// Validate validates and normalizes the request, dropping duplicate affiliates
+func (r *CreateBulkTrackingLinkJobRequest) Validate() error {
+	if r.CampaignID <= 0 {
+		return fmt.Errorf("campaign_id is required")
+	}
+	if r.AllVisibleAffiliates == (len(r.AffiliateIDs) > 0) {
+		return fmt.Errorf("either affiliate_ids or all_visible_affiliates is required")
+	}
+	seen := make(map[int64]bool, len(r.AffiliateIDs))
+	affiliateIDs := make([]int64, 0, len(r.AffiliateIDs))
+	for _, id := range r.AffiliateIDs {
+		if id <= 0 {
+			return fmt.Errorf("affiliate IDs must be positive")
+		}
+		if !seen[id] {
+			seen[id] = true
+			affiliateIDs = append(affiliateIDs, id)
+		}
+	}
+	if len(affiliateIDs) > MaxBulkLinkJobAffiliates {
+		return fmt.Errorf("at most %d affiliates can be included in one job", MaxBulkLinkJobAffiliates)
+	}
+	r.AffiliateIDs = affiliateIDs
+
+	if r.Concurrency == 0 {
+		r.Concurrency = DefaultBulkLinkConcurrency
+	}
+	if r.Concurrency < 1 || r.Concurrency > MaxBulkLinkConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", MaxBulkLinkConcurrency)
+	}
+	return r.Template.Validate()
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackingLinkTemplate_Render(t *testing.T) {
+	campaign := &Campaign{CampaignID: 12, Name: "Summer Sale"}
+	affiliate := &Affiliate{AffiliateID: 34, OrganizationID: 5, Name: "Coupon Site"}
+	date := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	sub1 := "aff{affiliate_id}-{date}"
+	sub2 := "{campaign_id}_{affiliate_org_id}"
+	empty := " "
+
+	rendered := (&TrackingLinkTemplate{Sub1: &sub1, Sub2: &sub2, Sub3: &empty}).Render(campaign, affiliate, date)
+	if rendered.Name != "Summer Sale - Coupon Site" {
+		t.Errorf("Name = %q", rendered.Name)
+	}
+	if rendered.Sub1 == nil || *rendered.Sub1 != "aff34-2024-06-03" {
+		t.Errorf("Sub1 = %v", rendered.Sub1)
+	}
+	if rendered.Sub2 == nil || *rendered.Sub2 != "12_5" {
+		t.Errorf("Sub2 = %v", rendered.Sub2)
+	}
+	if rendered.Sub3 != nil || rendered.SourceID != nil {
+		t.Errorf("empty parameters should be unset, got Sub3 = %v, SourceID = %v", rendered.Sub3, rendered.SourceID)
+	}
+
+	named := (&TrackingLinkTemplate{Name: "{affiliate_name} link"}).Render(campaign, affiliate, date)
+	if named.Name != "Coupon Site link" {
+		t.Errorf("Name = %q", named.Name)
+	}
+}
+
+func TestCreateBulkTrackingLinkJobRequest_Validate(t *testing.T) {
+	unknown := "{affiliate_email}"
+	tests := []struct {
+		name    string
+		req     CreateBulkTrackingLinkJobRequest
+		wantErr bool
+	}{
+		{name: "affiliate IDs", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1, AffiliateIDs: []int64{3, 4, 3}}},
+		{name: "all visible", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1, AllVisibleAffiliates: true}},
+		{name: "both", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1, AffiliateIDs: []int64{3}, AllVisibleAffiliates: true}, wantErr: true},
+		{name: "neither", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1}, wantErr: true},
+		{name: "missing campaign", req: CreateBulkTrackingLinkJobRequest{AllVisibleAffiliates: true}, wantErr: true},
+		{name: "bad affiliate ID", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1, AffiliateIDs: []int64{0}}, wantErr: true},
+		{name: "concurrency too high", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1, AllVisibleAffiliates: true, Concurrency: MaxBulkLinkConcurrency + 1}, wantErr: true},
+		{name: "unknown placeholder", req: CreateBulkTrackingLinkJobRequest{CampaignID: 1, AllVisibleAffiliates: true,
+			Template: TrackingLinkTemplate{Sub1: &unknown}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := CreateBulkTrackingLinkJobRequest{CampaignID: 1, AffiliateIDs: []int64{3, 4, 3}}
+	if err := req.Validate(); err != nil || len(req.AffiliateIDs) != 2 || req.Concurrency != DefaultBulkLinkConcurrency {
+		t.Errorf("Validate() = %v, affiliate IDs = %v, concurrency = %d", err, req.AffiliateIDs, req.Concurrency)
+	}
+}
+
+func TestNewBulkTrackingLinkJobWithProgress(t *testing.T) {
+	job := &BulkTrackingLinkJob{TotalCount: 3, SucceededCount: 1, FailedCount: 1}
+	if got := NewBulkTrackingLinkJobWithProgress(job); got.ProcessedCount != 2 || got.Progress != 66.6 {
+		t.Errorf("processed = %d, progress = %v", got.ProcessedCount, got.Progress)
+	}
+	if got := NewBulkTrackingLinkJobWithProgress(&BulkTrackingLinkJob{}); got.Progress != 100 {
+		t.Errorf("empty job progress = %v", got.Progress)
+	}
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// QR code image formats
+const (
+	QRCodeFormatPNG = "png"
+	QRCodeFormatSVG = "svg"
+)
+
+const (
+	// DefaultQRCodeSize is the default width and height of a QR code in pixels
+	DefaultQRCodeSize = 512
+	// MinQRCodeSize and MaxQRCodeSize bound the requested size in pixels
+	MinQRCodeSize = 64
+	MaxQRCodeSize = 2048
+	// DefaultQRCodeMargin is the quiet zone required by the standard, in modules
+	DefaultQRCodeMargin = 4
+	// MaxQRCodeMargin is the widest quiet zone, in modules
+	MaxQRCodeMargin = 20
+	// QRCodeTransparent is the background color value that leaves the background transparent
+	QRCodeTransparent = "transparent"
+
+	// MaxOrganizationLogoSize is the largest logo upload in bytes
+	MaxOrganizationLogoSize = 1 << 20
+	// MaxOrganizationLogoDimension is the largest logo width or height in pixels
+	MaxOrganizationLogoDimension = 4096
+)
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6}|[0-9a-f]{8})$`)
+
+// QRCodeOptions controls how the QR code of a tracking link is rendered
+type QRCodeOptions struct {
+	Format string `form:"format"` // png (default) or svg
+	Size   int    `form:"size"`   // Pixels, 64-2048
+	// ErrorCorrection is L, M, Q or H; defaults to M, or H with a logo
+	ErrorCorrection string `form:"ec_level"`
+	Margin          *int   `form:"margin"`     // Quiet zone in modules, 0-20; defaults to 4
+	Foreground      string `form:"foreground"` // Hex color, defaults to #000000
+	Background      string `form:"background"` // Hex color or "transparent", defaults to #ffffff
+	Logo            bool   `form:"logo"`       // Draw the organization logo at the centre
+}
+
+// Normalize applies the defaults and validates the options
+func (o *QRCodeOptions) Normalize() error {
+	o.Format = strings.ToLower(strings.TrimSpace(o.Format))
+	switch o.Format {
+	case "":
+		o.Format = QRCodeFormatPNG
+	case QRCodeFormatPNG, QRCodeFormatSVG:
+	default:
+		return fmt.Errorf("format must be png or svg")
+	}
+
+	if o.Size == 0 {
+		o.Size = DefaultQRCodeSize
+	}
+	if o.Size < MinQRCodeSize || o.Size > MaxQRCodeSize {
+		return fmt.Errorf("size must be between %d and %d pixels", MinQRCodeSize, MaxQRCodeSize)
+	}
+
+	if o.Margin == nil {
+		margin := DefaultQRCodeMargin
+		o.Margin = &margin
+	}
+	if *o.Margin < 0 || *o.Margin > MaxQRCodeMargin {
+		return fmt.Errorf("margin must be between 0 and %d modules", MaxQRCodeMargin)
+	}
+
+	o.ErrorCorrection = strings.ToUpper(strings.TrimSpace(o.ErrorCorrection))
+	switch o.ErrorCorrection {
+	case "":
+		o.ErrorCorrection = "M"
+		if o.Logo {
+			o.ErrorCorrection = "H"
+		}
+	case "L", "M":
+		// The logo covers modules that only levels Q and H can restore
+		if o.Logo {
+			return fmt.Errorf("ec_level must be Q or H when a logo is drawn")
+		}
+	case "Q", "H":
+	default:
+		return fmt.Errorf("ec_level must be L, M, Q or H")
+	}
+
+	o.Foreground = strings.ToLower(strings.TrimSpace(o.Foreground))
+	if o.Foreground == "" {
+		o.Foreground = "#000000"
+	}
+	if !hexColorPattern.MatchString(o.Foreground) {
+		return fmt.Errorf("foreground must be a hex color such as #000000")
+	}
+	o.Background = strings.ToLower(strings.TrimSpace(o.Background))
+	if o.Background == "" {
+		o.Background = "#ffffff"
+	}
+	if o.Background != QRCodeTransparent && !hexColorPattern.MatchString(o.Background) {
+		return fmt.Errorf("background must be a hex color such as #ffffff or transparent")
+	}
+	return nil
+}
+
+// CacheKey identifies the rendered image of normalized options
+func (o *QRCodeOptions) CacheKey() string {
+	margin := DefaultQRCodeMargin
+	if o.Margin != nil {
+		margin = *o.Margin
+	}
+	return fmt.Sprintf("%s|%d|%s|%d|%s|%s|%t",
+		o.Format, o.Size, o.ErrorCorrection, margin, o.Foreground, o.Background, o.Logo)
+}
+
+// ContentType returns the MIME type of the format
+func (o *QRCodeOptions) ContentType() string {
+	if o.Format == QRCodeFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// QRCodeImage is a rendered QR code
+type QRCodeImage struct {
+	Data        []byte
+	ContentType string
+	// ETag changes whenever the tracking URL, the options or the logo change
+	ETag string
+}
+
+// OrganizationLogo is the logo an organization uploaded for its QR codes. The image itself is
+// kept in object storage.
+type OrganizationLogo struct {
+	OrganizationID int64     `json:"organization_id" db:"organization_id"`
+	StorageKey     string    `json:"-" db:"storage_key"`
+	ContentType    string    `json:"content_type" db:"content_type"`
+	SizeBytes      int       `json:"size_bytes" db:"size_bytes"`
+	Width          int       `json:"width" db:"width"`
+	Height         int       `json:"height" db:"height"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "testing"
+
+func TestQRCodeOptions_Normalize(t *testing.T) {
+	zero := 0
+	tooWide := MaxQRCodeMargin + 1
+
+	tests := []struct {
+		name    string
+		options QRCodeOptions
+		wantErr bool
+	}{
+		{name: "defaults", options: QRCodeOptions{}},
+		{name: "svg with colors", options: QRCodeOptions{Format: "SVG", Foreground: "#1A2B3C", Background: "transparent", Margin: &zero}},
+		{name: "logo with level Q", options: QRCodeOptions{Logo: true, ErrorCorrection: "q"}},
+		{name: "unknown format", options: QRCodeOptions{Format: "gif"}, wantErr: true},
+		{name: "too small", options: QRCodeOptions{Size: 32}, wantErr: true},
+		{name: "too large", options: QRCodeOptions{Size: 4096}, wantErr: true},
+		{name: "margin too wide", options: QRCodeOptions{Margin: &tooWide}, wantErr: true},
+		{name: "unknown level", options: QRCodeOptions{ErrorCorrection: "X"}, wantErr: true},
+		{name: "logo with level L", options: QRCodeOptions{Logo: true, ErrorCorrection: "L"}, wantErr: true},
+		{name: "named color", options: QRCodeOptions{Foreground: "red"}, wantErr: true},
+		{name: "transparent foreground", options: QRCodeOptions{Foreground: "transparent"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQRCodeOptions_NormalizeDefaults(t *testing.T) {
+	options := QRCodeOptions{}
+	if err := options.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if options.Format != QRCodeFormatPNG || options.Size != DefaultQRCodeSize || *options.Margin != DefaultQRCodeMargin ||
+		options.ErrorCorrection != "M" || options.Foreground != "#000000" || options.Background != "#ffffff" {
+		t.Errorf("Normalize() = %+v", options)
+	}
+
+	withLogo := QRCodeOptions{Logo: true}
+	if err := withLogo.Normalize(); err != nil || withLogo.ErrorCorrection != "H" {
+		t.Errorf("Normalize() with a logo = %+v, error = %v, want level H", withLogo, err)
+	}
+}
+
+func TestQRCodeOptions_CacheKey(t *testing.T) {
+	a := QRCodeOptions{Foreground: "#FFF"}
+	b := QRCodeOptions{Foreground: "#fff", Size: DefaultQRCodeSize}
+	if err := a.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if a.CacheKey() != b.CacheKey() {
+		t.Errorf("equivalent options have different keys: %q and %q", a.CacheKey(), b.CacheKey())
+	}
+
+	c := b
+	c.Format = QRCodeFormatSVG
+	if c.CacheKey() == b.CacheKey() {
+		t.Error("options with different formats have the same key")
+	}
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrLinkTampered is returned for clicks whose signed tracking parameters do not verify
+var ErrLinkTampered = errors.New("tracking link parameters were tampered with")
+
+// What the redirect does with clicks on links that fail verification
+const (
+	LinkSigningEnforcementReject = "reject" // Refuse the click
+	LinkSigningEnforcementFlag   = "flag"   // Record the click as tampered and redirect with the link's stored parameters
+)
+
+// Tracking link signing key statuses. The active key signs new links; retired keys still verify
+// the links they signed until they are revoked.
+const (
+	SigningKeyStatusActive  = "active"
+	SigningKeyStatusRetired = "retired"
+	SigningKeyStatusRevoked = "revoked"
+)
+
+// Query parameters of signed tracking links
+const (
+	LinkParamKeyID       = "kid"
+	LinkParamSignature   = "sig"
+	LinkParamData        = "d" // Encrypted parameters of links with IsEncryptParameters
+	LinkParamAffiliateID = "aff"
+)
+
+// Reasons a click fails verification
+const (
+	TamperReasonMissingSignature  = "missing_signature"
+	TamperReasonUnknownKey        = "unknown_key"
+	TamperReasonRevokedKey        = "revoked_key"
+	TamperReasonInvalidSignature  = "invalid_signature"
+	TamperReasonUndecryptable     = "undecryptable"
+	TamperReasonAffiliateMismatch = "affiliate_mismatch"
+)
+
+// Actions taken on tampered clicks
+const (
+	TamperActionRejected = "rejected"
+	TamperActionFlagged  = "flagged"
+)
+
+// SignedLinkParameterNames are the query parameters covered by link signatures
+var SignedLinkParameterNames = []string{
+	LinkParamAffiliateID, URLMacroSourceID, URLMacroSub1, URLMacroSub2, URLMacroSub3, URLMacroSub4, URLMacroSub5,
+}
+
+// TrackingLinkSigningSettings enables signing of the native tracking links of an organization
+type TrackingLinkSigningSettings struct {
+	OrganizationID int64     `json:"organization_id" db:"organization_id"`
+	Enforcement    string    `json:"enforcement" db:"enforcement"` // reject or flag
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TrackingLinkSigningKey is a versioned secret links are signed and encrypted with. Its ID is
+// embedded in the links it signs.
+type TrackingLinkSigningKey struct {
+	KeyID           int64      `json:"key_id" db:"key_id"`
+	OrganizationID  int64      `json:"organization_id" db:"organization_id"`
+	EncryptedSecret string     `json:"-" db:"encrypted_secret"` // Encrypted with the application encryption key
+	Status          string     `json:"status" db:"status"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	RetiredAt       *time.Time `json:"retired_at,omitempty" db:"retired_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// TrackingLinkSigning is the signing configuration of an organization with its keys
+type TrackingLinkSigning struct {
+	Enabled  bool                         `json:"enabled"`
+	Settings *TrackingLinkSigningSettings `json:"settings,omitempty"`
+	Keys     []*TrackingLinkSigningKey    `json:"keys"`
+}
+
+// SetTrackingLinkSigningRequest enables signing or changes its enforcement
+type SetTrackingLinkSigningRequest struct {
+	Enforcement string `json:"enforcement"` // reject (default) or flag
+}
+
+// Validate validates the request and defaults the enforcement to reject
+func (r *SetTrackingLinkSigningRequest) Validate() error {
+	switch r.Enforcement {
+	case "":
+		r.Enforcement = LinkSigningEnforcementReject
+	case LinkSigningEnforcementReject, LinkSigningEnforcementFlag:
+	default:
+		return fmt.Errorf("enforcement must be %s or %s", LinkSigningEnforcementReject, LinkSigningEnforcementFlag)
+	}
+	return nil
+}
+
+// TrackingLinkTamperEvent records a click that failed verification
+type TrackingLinkTamperEvent struct {
+	EventID        int64     `json:"event_id" db:"event_id"`
+	OrganizationID int64     `json:"organization_id" db:"organization_id"`
+	TrackingLinkID int64     `json:"tracking_link_id" db:"tracking_link_id"`
+	KeyID          *int64    `json:"key_id,omitempty" db:"key_id"`
+	Reason         string    `json:"reason" db:"reason"`
+	Action         string    `json:"action" db:"action"`
+	IP             *string   `json:"ip,omitempty" db:"ip"`
+	UserAgent      *string   `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// TrackingLinkTamperEventListResponse is a page of tamper events
+type TrackingLinkTamperEventListResponse struct {
+	Events   []*TrackingLinkTamperEvent `json:"events"`
+	Total    int                        `json:"total"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"page_size"`
+}
+
+// SignedLinkParameters returns the parameters of a link that are signed into its URL
+func SignedLinkParameters(link *TrackingLink) url.Values {
+	params := url.Values{}
+	params.Set(LinkParamAffiliateID, strconv.FormatInt(link.AffiliateID, 10))
+	for name, value := range map[string]*string{
+		URLMacroSourceID: link.SourceID,
+		URLMacroSub1:     link.Sub1,
+		URLMacroSub2:     link.Sub2,
+		URLMacroSub3:     link.Sub3,
+		URLMacroSub4:     link.Sub4,
+		URLMacroSub5:     link.Sub5,
+	} {
+		if value != nil && *value != "" {
+			params.Set(name, *value)
+		}
+	}
+	return params
+}
+
+// ExtractSignedLinkParameters returns the signed parameters present in a click query. A
+// parameter given more than once cannot be signed and is reported as not ok.
+func ExtractSignedLinkParameters(query url.Values) (url.Values, bool) {
+	params := url.Values{}
+	for _, name := range SignedLinkParameterNames {
+		values := query[name]
+		if len(values) > 1 {
+			return nil, false
+		}
+		if len(values) == 1 && values[0] != "" {
+			params.Set(name, values[0])
+		}
+	}
+	return params, true
+}
+
+// LinkSignatureMessage returns the message signed for a link: its ID, the key ID and the signed
+// parameters, in canonical order
+func LinkSignatureMessage(trackingLinkID, keyID int64, params url.Values) string {
+	return fmt.Sprintf("link=%d&%s=%d&%s", trackingLinkID, LinkParamKeyID, keyID, params.Encode())
+}
+
+// LinkEncryptionAdditionalData binds encrypted parameters to a link and key, so that they cannot
+// be moved to another link
+func LinkEncryptionAdditionalData(trackingLinkID, keyID int64) []byte {
+	return []byte(fmt.Sprintf("link=%d&%s=%d", trackingLinkID, LinkParamKeyID, keyID))
+}
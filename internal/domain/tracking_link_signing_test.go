@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSignedLinkParameters(t *testing.T) {
+	sub1 := "a b"
+	empty := ""
+	link := &TrackingLink{TrackingLinkID: 4, AffiliateID: 9, Sub1: &sub1, Sub2: &empty}
+
+	params := SignedLinkParameters(link)
+	if got := params.Encode(); got != "aff=9&sub1=a+b" {
+		t.Errorf("SignedLinkParameters() = %q", got)
+	}
+	if got := LinkSignatureMessage(4, 2, params); got != "link=4&kid=2&aff=9&sub1=a+b" {
+		t.Errorf("LinkSignatureMessage() = %q", got)
+	}
+}
+
+func TestExtractSignedLinkParameters(t *testing.T) {
+	query := url.Values{"aff": {"9"}, "sub1": {"x"}, "sub2": {""}, "gclid": {"g"}, "sig": {"s"}}
+	params, ok := ExtractSignedLinkParameters(query)
+	if !ok || params.Encode() != "aff=9&sub1=x" {
+		t.Errorf("ExtractSignedLinkParameters() = %q, %v", params.Encode(), ok)
+	}
+
+	if _, ok := ExtractSignedLinkParameters(url.Values{"sub1": {"x", "y"}}); ok {
+		t.Error("repeated signed parameter should not be accepted")
+	}
+}
+
+func TestSetTrackingLinkSigningRequest_Validate(t *testing.T) {
+	req := SetTrackingLinkSigningRequest{}
+	if err := req.Validate(); err != nil || req.Enforcement != LinkSigningEnforcementReject {
+		t.Errorf("Validate() = %v, enforcement = %q", err, req.Enforcement)
+	}
+	if err := (&SetTrackingLinkSigningRequest{Enforcement: "log"}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown enforcement")
+	}
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrackingLinkLifecycleStatus(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { v := now.Add(d); return &v }
+	day := func(year int, month time.Month, d int) *time.Time {
+		v := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+
+	tests := []struct {
+		name       string
+		link       TrackingLink
+		campaign   *Campaign
+		wantStatus string
+		wantReason string
+	}{
+		{"no window", TrackingLink{}, nil, TrackingLinkStatusActive, ""},
+		{"inside window", TrackingLink{ActiveFrom: at(-time.Hour), ActiveUntil: at(time.Hour)}, nil, TrackingLinkStatusActive, ""},
+		{"before window", TrackingLink{ActiveFrom: at(time.Hour)}, nil, TrackingLinkStatusScheduled, ""},
+		{"window ended", TrackingLink{ActiveUntil: at(0)}, nil, TrackingLinkStatusArchived, TrackingLinkStatusReasonExpired},
+		{"campaign not started", TrackingLink{}, &Campaign{StartDate: day(2024, 6, 16)}, TrackingLinkStatusScheduled, ""},
+		{"campaign started today", TrackingLink{}, &Campaign{StartDate: day(2024, 6, 15)}, TrackingLinkStatusActive, ""},
+		{"campaign ends today", TrackingLink{}, &Campaign{EndDate: day(2024, 6, 15)}, TrackingLinkStatusActive, ""},
+		{"campaign ended", TrackingLink{}, &Campaign{EndDate: day(2024, 6, 14)}, TrackingLinkStatusArchived, TrackingLinkStatusReasonCampaignEnded},
+		{"expiry wins over campaign end", TrackingLink{ActiveUntil: at(-time.Hour)}, &Campaign{EndDate: day(2024, 6, 1)}, TrackingLinkStatusArchived, TrackingLinkStatusReasonExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := tt.link.LifecycleStatus(tt.campaign, now)
+			gotReason := ""
+			if reason != nil {
+				gotReason = *reason
+			}
+			if status != tt.wantStatus || gotReason != tt.wantReason {
+				t.Errorf("LifecycleStatus() = %s, %q, want %s, %q", status, gotReason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTrackingLinkApplyLifecycle(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	link := &TrackingLink{Status: TrackingLinkStatusActive, ActiveFrom: &future}
+	if !link.ApplyLifecycle(nil, now) || link.Status != TrackingLinkStatusScheduled {
+		t.Errorf("ApplyLifecycle() status = %s, want scheduled", link.Status)
+	}
+	if link.IsLive(nil, now) {
+		t.Error("IsLive() = true for a scheduled link")
+	}
+	if !link.ApplyLifecycle(nil, future) || link.Status != TrackingLinkStatusActive || !link.IsLive(nil, future) {
+		t.Errorf("ApplyLifecycle() at the window start: status = %s", link.Status)
+	}
+
+	paused := &TrackingLink{Status: TrackingLinkStatusPaused, ActiveUntil: &past}
+	if paused.ApplyLifecycle(nil, now) || paused.Status != TrackingLinkStatusPaused {
+		t.Errorf("ApplyLifecycle() changed a paused link to %s", paused.Status)
+	}
+
+	expired := &TrackingLink{Status: TrackingLinkStatusActive, ActiveUntil: &past}
+	if expired.IsLive(nil, now) {
+		t.Error("IsLive() = true after the window ended")
+	}
+	if !expired.ApplyLifecycle(nil, now) || expired.Status != TrackingLinkStatusArchived ||
+		expired.StatusReason == nil || *expired.StatusReason != TrackingLinkStatusReasonExpired {
+		t.Errorf("ApplyLifecycle() = %s, %v, want archived, expired", expired.Status, expired.StatusReason)
+	}
+}
+
+func TestTrackingLinkApplyLifecycleTransition(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	endedYesterday := time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		link        TrackingLink
+		campaign    *Campaign
+		wantChanged bool
+		wantStatus  string
+		wantReason  string
+	}{
+		{"active inside window", TrackingLink{Status: TrackingLinkStatusActive, ActiveUntil: &future}, nil, false, TrackingLinkStatusActive, ""},
+		{"active window ended", TrackingLink{Status: TrackingLinkStatusActive, ActiveUntil: &past}, nil, true, TrackingLinkStatusArchived, TrackingLinkStatusReasonExpired},
+		{"scheduled window opened", TrackingLink{Status: TrackingLinkStatusScheduled, ActiveFrom: &past}, nil, true, TrackingLinkStatusActive, ""},
+		{"active window moved later", TrackingLink{Status: TrackingLinkStatusActive, ActiveFrom: &future}, nil, true, TrackingLinkStatusScheduled, ""},
+		{"paused campaign ended", TrackingLink{Status: TrackingLinkStatusPaused}, &Campaign{EndDate: &endedYesterday}, true, TrackingLinkStatusArchived, TrackingLinkStatusReasonCampaignEnded},
+		{"paused before window", TrackingLink{Status: TrackingLinkStatusPaused, ActiveFrom: &future}, nil, false, TrackingLinkStatusPaused, ""},
+		{"archived", TrackingLink{Status: TrackingLinkStatusArchived, ActiveFrom: &past}, nil, false, TrackingLinkStatusArchived, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := tt.link
+			changed := link.ApplyLifecycleTransition(tt.campaign, now)
+			gotReason := ""
+			if link.StatusReason != nil {
+				gotReason = *link.StatusReason
+			}
+			if changed != tt.wantChanged || link.Status != tt.wantStatus || gotReason != tt.wantReason {
+				t.Errorf("ApplyLifecycleTransition() = %v, %s, %q, want %v, %s, %q",
+					changed, link.Status, gotReason, tt.wantChanged, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTrackingLinkResume(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	reason := TrackingLinkStatusReasonAssociationSuspended
+
+	tests := []struct {
+		name       string
+		link       TrackingLink
+		wantStatus string
+		wantReason string
+	}{
+		{"inside window", TrackingLink{ActiveUntil: &future}, TrackingLinkStatusActive, ""},
+		{"before window", TrackingLink{ActiveFrom: &future}, TrackingLinkStatusScheduled, ""},
+		{"window ended while suspended", TrackingLink{ActiveUntil: &past}, TrackingLinkStatusArchived, TrackingLinkStatusReasonExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := tt.link
+			link.Status, link.StatusReason = TrackingLinkStatusPaused, &reason
+			link.Resume(nil, now)
+			gotReason := ""
+			if link.StatusReason != nil {
+				gotReason = *link.StatusReason
+			}
+			if link.Status != tt.wantStatus || gotReason != tt.wantReason {
+				t.Errorf("Resume() = %s, %q, want %s, %q", link.Status, gotReason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTrackingLinkValidateActiveWindow(t *testing.T) {
+	from := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	until := from.Add(24 * time.Hour)
+
+	if err := (&TrackingLink{ActiveFrom: &from, ActiveUntil: &until}).ValidateActiveWindow(); err != nil {
+		t.Errorf("ValidateActiveWindow() error = %v", err)
+	}
+	if err := (&TrackingLink{ActiveUntil: &until}).ValidateActiveWindow(); err != nil {
+		t.Errorf("ValidateActiveWindow() error = %v for an open start", err)
+	}
+	if err := (&TrackingLink{ActiveFrom: &until, ActiveUntil: &from}).ValidateActiveWindow(); err == nil {
+		t.Error("ValidateActiveWindow() accepted a window ending before it starts")
+	}
+	if err := (&TrackingLink{ActiveFrom: &from, ActiveUntil: &from}).ValidateActiveWindow(); err == nil {
+		t.Error("ValidateActiveWindow() accepted an empty window")
+	}
+}
+
+func TestTrackingLinkCheckAcceptsClick(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		link     TrackingLink
+		campaign Campaign
+		wantErr  bool
+	}{
+		{"active link and campaign", TrackingLink{Status: TrackingLinkStatusActive}, Campaign{Status: "active"}, false},
+		{"paused link", TrackingLink{Status: TrackingLinkStatusPaused}, Campaign{Status: "active"}, true},
+		{"paused campaign", TrackingLink{Status: TrackingLinkStatusActive}, Campaign{Status: "paused"}, true},
+		{"draft campaign", TrackingLink{Status: TrackingLinkStatusActive}, Campaign{Status: "draft"}, true},
+		{"window ended", TrackingLink{Status: TrackingLinkStatusActive, ActiveUntil: &past}, Campaign{Status: "active"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.link.CheckAcceptsClick(&tt.campaign, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAcceptsClick() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				t.Errorf("CheckAcceptsClick() error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Macros substituted in campaign destination URLs when a native tracking link redirects
+const (
+	URLMacroClickID        = "click_id"
+	URLMacroTransactionID  = "transaction_id" // Alias of click_id, named as in Everflow destination URLs
+	URLMacroTrackingLinkID = "tracking_link_id"
+	URLMacroCampaignID     = "campaign_id"
+	URLMacroAffiliateID    = "affiliate_id"
+	URLMacroSourceID       = "source_id"
+	URLMacroSub1           = "sub1"
+	URLMacroSub2           = "sub2"
+	URLMacroSub3           = "sub3"
+	URLMacroSub4           = "sub4"
+	URLMacroSub5           = "sub5"
+	URLMacroCountry        = "country" // ISO 3166-1 alpha-2 code of the visitor
+	URLMacroIP             = "ip"
+	URLMacroTimestamp      = "timestamp" // Unix time of the click
+)
+
+// URLMacros lists the built-in destination URL macros
+var URLMacros = []string{
+	URLMacroClickID, URLMacroTransactionID, URLMacroTrackingLinkID, URLMacroCampaignID, URLMacroAffiliateID,
+	URLMacroSourceID, URLMacroSub1, URLMacroSub2, URLMacroSub3, URLMacroSub4, URLMacroSub5,
+	URLMacroCountry, URLMacroIP, URLMacroTimestamp,
+}
+
+// Encodings of macro values
+const (
+	URLEncodingAuto  = "auto"  // Path escaping in the URL path, query escaping in the query and fragment
+	URLEncodingQuery = "query" // Query escaping wherever the macro is
+	URLEncodingPath  = "path"  // Path escaping wherever the macro is
+	URLEncodingRaw   = "raw"   // Inserted as is, for values the affiliate already encoded; whitespace and control characters are still escaped
+)
+
+const (
+	// MaxPassthroughParameters bounds the whitelist of a campaign
+	MaxPassthroughParameters = 20
+	// maxURLMacroValueLength truncates macro values taken from clicks
+	maxURLMacroValueLength = 512
+)
+
+var urlMacroPattern = regexp.MustCompile(`\{([A-Za-z0-9_.-]+)\}`)
+
+var passthroughParameterName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// PassthroughParameter is a click parameter an advertiser accepts on its destination URL. The
+// value is substituted for the {name} macro, or appended to the query when the destination
+// URL does not reference it.
+type PassthroughParameter struct {
+	Name     string  `json:"name"`
+	Encoding string  `json:"encoding,omitempty"` // auto (default), query, path or raw
+	Default  *string `json:"default,omitempty"`  // Used when the click does not carry the parameter
+}
+
+// CampaignURLSettings holds the passthrough parameter whitelist of a campaign. Click parameters
+// that are not whitelisted are dropped on native redirects.
+type CampaignURLSettings struct {
+	CampaignID            int64                  `json:"campaign_id" db:"campaign_id"`
+	PassthroughParameters []PassthroughParameter `json:"passthrough_parameters" db:"passthrough_parameters"`
+	CreatedAt             time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// SetCampaignURLSettingsRequest replaces the passthrough parameter whitelist of a campaign
+type SetCampaignURLSettingsRequest struct {
+	PassthroughParameters []PassthroughParameter `json:"passthrough_parameters"`
+}
+
+// Validate validates the whitelist and fills in default encodings
+func (r *SetCampaignURLSettingsRequest) Validate() error {
+	if len(r.PassthroughParameters) > MaxPassthroughParameters {
+		return fmt.Errorf("at most %d passthrough parameters are allowed", MaxPassthroughParameters)
+	}
+	seen := make(map[string]bool, len(r.PassthroughParameters))
+	for i := range r.PassthroughParameters {
+		param := &r.PassthroughParameters[i]
+		param.Name = strings.TrimSpace(param.Name)
+		if !passthroughParameterName.MatchString(param.Name) {
+			return fmt.Errorf("passthrough parameter %q must be 1 to 64 letters, digits, '_', '-' or '.'", param.Name)
+		}
+		if isBuiltInURLMacro(param.Name) {
+			return fmt.Errorf("passthrough parameter %q conflicts with a built-in macro", param.Name)
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("passthrough parameter %q is listed twice", param.Name)
+		}
+		seen[param.Name] = true
+
+		switch param.Encoding {
+		case "":
+			param.Encoding = URLEncodingAuto
+		case URLEncodingAuto, URLEncodingQuery, URLEncodingPath, URLEncodingRaw:
+		default:
+			return fmt.Errorf("encoding of %q must be %s, %s, %s or %s", param.Name, URLEncodingAuto, URLEncodingQuery, URLEncodingPath, URLEncodingRaw)
+		}
+		if param.Default != nil && len(*param.Default) > maxURLMacroValueLength {
+			return fmt.Errorf("default of %q must be at most %d characters", param.Name, maxURLMacroValueLength)
+		}
+	}
+	return nil
+}
+
+func isBuiltInURLMacro(name string) bool {
+	for _, macro := range URLMacros {
+		if macro == name {
+			return true
+		}
+	}
+	return false
+}
+
+// passthroughParameter returns the whitelisted parameter with the given name
+func (s *CampaignURLSettings) passthroughParameter(name string) *PassthroughParameter {
+	if s == nil {
+		return nil
+	}
+	for i := range s.PassthroughParameters {
+		if s.PassthroughParameters[i].Name == name {
+			return &s.PassthroughParameters[i]
+		}
+	}
+	return nil
+}
+
+// IsClickParameter reports whether a click query parameter is used on redirects: the
+// source_id and sub1-sub5 overrides, and whitelisted passthrough parameters
+func (s *CampaignURLSettings) IsClickParameter(name string) bool {
+	switch name {
+	case URLMacroSourceID, URLMacroSub1, URLMacroSub2, URLMacroSub3, URLMacroSub4, URLMacroSub5:
+		return true
+	}
+	return s.passthroughParameter(name) != nil
+}
+
+// URLMacroClick holds the values substituted for the macros of a click
+type URLMacroClick struct {
+	ClickID      string
+	TrackingLink *TrackingLink
+	Query        url.Values // Parameters of the click request
+	Country      string
+	IP           string
+	Time         time.Time
+}
+
+// urlTemplateParts locates the end of the scheme and host, and the start of the query, of a
+// destination URL template
+func urlTemplateParts(template string) (hostEnd, queryStart int) {
+	hostEnd = len(template)
+	if schemeEnd := strings.Index(template, "://"); schemeEnd >= 0 {
+		if end := strings.IndexAny(template[schemeEnd+3:], "/?#"); end >= 0 {
+			hostEnd = schemeEnd + 3 + end
+		}
+	}
+	queryStart = len(template)
+	if start := strings.IndexAny(template[hostEnd:], "?#"); start >= 0 {
+		queryStart = hostEnd + start
+	}
+	return hostEnd, queryStart
+}
+
+// ValidateDestinationTemplate checks that template is an absolute http(s) URL whose macros are
+// all known and kept out of the scheme and host. It returns the macros used and the problems
+// found.
+func (s *CampaignURLSettings) ValidateDestinationTemplate(template string) ([]string, []string) {
+	var problems []string
+	hostEnd, _ := urlTemplateParts(template)
+	parsed, err := url.Parse(template[:hostEnd])
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		problems = append(problems, "destination URL must be an absolute http or https URL")
+	}
+
+	seen := make(map[string]bool)
+	var macros []string
+	for _, match := range urlMacroPattern.FindAllStringSubmatchIndex(template, -1) {
+		name := template[match[2]:match[3]]
+		if match[0] < hostEnd {
+			problems = append(problems, fmt.Sprintf("macro {%s} cannot be used in the scheme or host", name))
+			continue
+		}
+		if !isBuiltInURLMacro(name) && s.passthroughParameter(name) == nil {
+			problems = append(problems, fmt.Sprintf("unknown macro {%s}; whitelist it as a passthrough parameter", name))
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			macros = append(macros, name)
+		}
+	}
+	return macros, problems
+}
+
+// BuildDestinationURL substitutes the macros of template for a click and appends the
+// whitelisted parameters the template does not reference. Unknown macros, and macros in the
+// scheme or host, are left as they are.
+func (s *CampaignURLSettings) BuildDestinationURL(template string, click URLMacroClick) string {
+	// Passthrough parameters are appended before the fragment
+	base, fragment, hasFragment := strings.Cut(template, "#")
+	hostEnd, queryStart := urlTemplateParts(base)
+
+	referenced := make(map[string]bool)
+	var out strings.Builder
+	expand := func(part string, inHost func(int) bool, inQuery func(int) bool) {
+		last := 0
+		for _, match := range urlMacroPattern.FindAllStringSubmatchIndex(part, -1) {
+			out.WriteString(part[last:match[0]])
+			last = match[1]
+			name := part[match[2]:match[3]]
+			value, encoding, ok := s.macroValue(name, click)
+			if !ok || inHost(match[0]) {
+				out.WriteString(part[match[0]:match[1]])
+				continue
+			}
+			referenced[name] = true
+			out.WriteString(encodeURLMacroValue(value, encoding, inQuery(match[0])))
+		}
+		out.WriteString(part[last:])
+	}
+	expand(base, func(i int) bool { return i < hostEnd }, func(i int) bool { return i >= queryStart })
+
+	var appended []string
+	if s != nil {
+		for _, param := range s.PassthroughParameters {
+			if referenced[param.Name] {
+				continue
+			}
+			if value := truncateURLMacroValue(click.Query.Get(param.Name)); value != "" {
+				appended = append(appended, url.QueryEscape(param.Name)+"="+encodeURLMacroValue(value, param.Encoding, true))
+			}
+		}
+	}
+	if len(appended) > 0 {
+		expanded := out.String()
+		switch {
+		case queryStart == len(base):
+			out.WriteString("?")
+		case !strings.HasSuffix(expanded, "?") && !strings.HasSuffix(expanded, "&"):
+			out.WriteString("&")
+		}
+		out.WriteString(strings.Join(appended, "&"))
+	}
+
+	if hasFragment {
+		out.WriteString("#")
+		expand(fragment, func(int) bool { return false }, func(int) bool { return true })
+	}
+	return out.String()
+}
+
+// BuildProviderURL forwards the click parameters of a redirect onto the tracking URL a provider
+// generated for the link: source_id and sub1-sub5 overrides replace the values the URL carries,
+// and whitelisted passthrough parameters (or their defaults) are added for the provider to pass
+// on. Macros are expanded by the provider, so the URL is returned as is when it can't be parsed.
+func (s *CampaignURLSettings) BuildProviderURL(providerURL string, click URLMacroClick) string {
+	u, err := url.Parse(providerURL)
+	if err != nil {
+		return providerURL
+	}
+	query := u.Query()
+	changed := false
+	for _, name := range []string{URLMacroSourceID, URLMacroSub1, URLMacroSub2, URLMacroSub3, URLMacroSub4, URLMacroSub5} {
+		if value := truncateURLMacroValue(click.Query.Get(name)); value != "" {
+			query.Set(name, value)
+			changed = true
+		}
+	}
+	if s != nil {
+		for _, param := range s.PassthroughParameters {
+			value := truncateURLMacroValue(click.Query.Get(param.Name))
+			if value == "" && param.Default != nil {
+				value = *param.Default
+			}
+			if value != "" {
+				query.Set(param.Name, value)
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return providerURL
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// macroValue returns the value of a macro for a click and how to encode it
+func (s *CampaignURLSettings) macroValue(name string, click URLMacroClick) (string, string, bool) {
+	link := click.TrackingLink
+	if link == nil {
+		link = &TrackingLink{}
+	}
+	// Clicks may override the source and sub IDs stored on the link
+	linkValue := func(stored *string) string {
+		if value := truncateURLMacroValue(click.Query.Get(name)); value != "" {
+			return value
+		}
+		if stored != nil {
+			return *stored
+		}
+		return ""
+	}
+	formatID := func(id int64) string {
+		if id == 0 {
+			return ""
+		}
+		return strconv.FormatInt(id, 10)
+	}
+
+	switch name {
+	case URLMacroClickID, URLMacroTransactionID:
+		return click.ClickID, URLEncodingAuto, true
+	case URLMacroTrackingLinkID:
+		return formatID(link.TrackingLinkID), URLEncodingAuto, true
+	case URLMacroCampaignID:
+		return formatID(link.CampaignID), URLEncodingAuto, true
+	case URLMacroAffiliateID:
+		return formatID(link.AffiliateID), URLEncodingAuto, true
+	case URLMacroSourceID:
+		return linkValue(link.SourceID), URLEncodingAuto, true
+	case URLMacroSub1:
+		return linkValue(link.Sub1), URLEncodingAuto, true
+	case URLMacroSub2:
+		return linkValue(link.Sub2), URLEncodingAuto, true
+	case URLMacroSub3:
+		return linkValue(link.Sub3), URLEncodingAuto, true
+	case URLMacroSub4:
+		return linkValue(link.Sub4), URLEncodingAuto, true
+	case URLMacroSub5:
+		return linkValue(link.Sub5), URLEncodingAuto, true
+	case URLMacroCountry:
+		return strings.ToUpper(click.Country), URLEncodingAuto, true
+	case URLMacroIP:
+		return click.IP, URLEncodingAuto, true
+	case URLMacroTimestamp:
+		if click.Time.IsZero() {
+			return "", URLEncodingAuto, true
+		}
+		return strconv.FormatInt(click.Time.Unix(), 10), URLEncodingAuto, true
+	}
+
+	param := s.passthroughParameter(name)
+	if param == nil {
+		return "", "", false
+	}
+	value := truncateURLMacroValue(click.Query.Get(name))
+	if value == "" && param.Default != nil {
+		value = *param.Default
+	}
+	return value, param.Encoding, true
+}
+
+func truncateURLMacroValue(value string) string {
+	if len(value) > maxURLMacroValueLength {
+		return value[:maxURLMacroValueLength]
+	}
+	return value
+}
+
+// encodeURLMacroValue escapes a macro value for where it appears in the URL
+func encodeURLMacroValue(value, encoding string, queryContext bool) string {
+	switch encoding {
+	case URLEncodingQuery:
+		return url.QueryEscape(value)
+	case URLEncodingPath:
+		return url.PathEscape(value)
+	case URLEncodingRaw:
+		var b strings.Builder
+		for i := 0; i < len(value); i++ {
+			if c := value[i]; c <= ' ' || c == 0x7f {
+				fmt.Fprintf(&b, "%%%02X", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+		return b.String()
+	}
+	if queryContext {
+		return url.QueryEscape(value)
+	}
+	return url.PathEscape(value)
+}
+
+// URLPreviewRequest previews the destination URL of a campaign for a sample click
+type URLPreviewRequest struct {
+	DestinationURL *string           `json:"destination_url,omitempty"`  // Template to check instead of the campaign's destination URL
+	TrackingLinkID *int64            `json:"tracking_link_id,omitempty"` // Link whose IDs and sub values are used; link macros are empty without one
+	Query          map[string]string `json:"query,omitempty"`            // Parameters of the sample click
+	Country        string            `json:"country,omitempty"`
+	IP             string            `json:"ip,omitempty"`
+}
+
+// URLPreviewResponse is the destination URL a sample click would be redirected to
+type URLPreviewResponse struct {
+	Template          string   `json:"template"`
+	URL               string   `json:"url"`
+	Valid             bool     `json:"valid"`
+	Problems          []string `json:"problems,omitempty"`
+	Macros            []string `json:"macros"`                       // Macros the template uses
+	DroppedParameters []string `json:"dropped_parameters,omitempty"` // Sample parameters that are not forwarded
+}
+
+// DroppedClickParameters lists the query parameters a redirect does not use, sorted
+func (s *CampaignURLSettings) DroppedClickParameters(query url.Values) []string {
+	var dropped []string
+	for name := range query {
+		if !s.IsClickParameter(name) {
+			dropped = append(dropped, name)
+		}
+	}
+	sort.Strings(dropped)
+	return dropped
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCampaignURLSettings_BuildDestinationURL(t *testing.T) {
+	sub1 := "static"
+	sub2 := "stored"
+	gclidDefault := "none"
+	settings := &CampaignURLSettings{PassthroughParameters: []PassthroughParameter{
+		{Name: "gclid", Encoding: URLEncodingAuto, Default: &gclidDefault},
+		{Name: "utm_source", Encoding: URLEncodingAuto},
+		{Name: "deep", Encoding: URLEncodingRaw},
+	}}
+	click := URLMacroClick{
+		ClickID:      "abc123",
+		TrackingLink: &TrackingLink{TrackingLinkID: 7, CampaignID: 3, AffiliateID: 9, Sub1: &sub1, Sub2: &sub2},
+		Query:        url.Values{"sub2": {"from click"}, "utm_source": {"news letter"}, "other": {"x"}},
+		Country:      "de",
+		IP:           "203.0.113.5",
+		Time:         time.Unix(1700000000, 0),
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "query macros",
+			template: "https://shop.example.com/land?cid={click_id}&aff={affiliate_id}&s1={sub1}&s2={sub2}&c={country}&t={timestamp}",
+			want:     "https://shop.example.com/land?cid=abc123&aff=9&s1=static&s2=from+click&c=DE&t=1700000000&utm_source=news+letter",
+		},
+		{
+			name:     "path macro and passthrough default",
+			template: "https://shop.example.com/{sub2}/offer?g={gclid}",
+			want:     "https://shop.example.com/from%20click/offer?g=none&utm_source=news+letter",
+		},
+		{
+			name:     "appended before fragment",
+			template: "https://shop.example.com/land#ref={transaction_id}",
+			want:     "https://shop.example.com/land?utm_source=news+letter#ref=abc123",
+		},
+		{
+			name:     "unknown and host macros are kept",
+			template: "https://{sub1}.example.com/land?x={unknown}&utm_source={utm_source}",
+			want:     "https://{sub1}.example.com/land?x={unknown}&utm_source=news+letter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settings.BuildDestinationURL(tt.template, click); got != tt.want {
+				t.Errorf("BuildDestinationURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	rawClick := click
+	rawClick.Query = url.Values{"deep": {"a%2Fb c"}}
+	if got := settings.BuildDestinationURL("https://shop.example.com/?d={deep}", rawClick); got != "https://shop.example.com/?d=a%2Fb%20c" {
+		t.Errorf("raw encoding = %q", got)
+	}
+
+	var none *CampaignURLSettings
+	if got := none.BuildDestinationURL("https://shop.example.com/?s={sub1}", URLMacroClick{}); got != "https://shop.example.com/?s=" {
+		t.Errorf("without settings = %q", got)
+	}
+}
+
+func TestCampaignURLSettings_BuildProviderURL(t *testing.T) {
+	gclidDefault := "none"
+	settings := &CampaignURLSettings{PassthroughParameters: []PassthroughParameter{
+		{Name: "gclid", Encoding: URLEncodingAuto, Default: &gclidDefault},
+		{Name: "utm_source", Encoding: URLEncodingAuto},
+	}}
+	providerURL := "https://trk.example.com/ABC/DEF/?sub1=static&sub2=stored"
+
+	tests := []struct {
+		name     string
+		settings *CampaignURLSettings
+		query    url.Values
+		want     string
+	}{
+		{
+			name:     "overrides and passthrough",
+			settings: settings,
+			query:    url.Values{"sub2": {"from click"}, "utm_source": {"news letter"}, "other": {"x"}},
+			want:     "https://trk.example.com/ABC/DEF/?gclid=none&sub1=static&sub2=from+click&utm_source=news+letter",
+		},
+		{
+			name:     "passthrough default only",
+			settings: settings,
+			query:    url.Values{},
+			want:     "https://trk.example.com/ABC/DEF/?gclid=none&sub1=static&sub2=stored",
+		},
+		{
+			name:     "nothing to forward",
+			settings: nil,
+			query:    url.Values{"utm_source": {"news"}},
+			want:     providerURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.settings.BuildProviderURL(providerURL, URLMacroClick{Query: tt.query}); got != tt.want {
+				t.Errorf("BuildProviderURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCampaignURLSettings_ValidateDestinationTemplate(t *testing.T) {
+	settings := &CampaignURLSettings{PassthroughParameters: []PassthroughParameter{{Name: "gclid"}}}
+
+	macros, problems := settings.ValidateDestinationTemplate("https://shop.example.com/p?a={click_id}&g={gclid}&b={click_id}")
+	if len(problems) != 0 || strings.Join(macros, ",") != "click_id,gclid" {
+		t.Errorf("macros = %v, problems = %v", macros, problems)
+	}
+
+	for _, template := range []string{
+		"https://shop.example.com/p?x={fbclid}",
+		"https://{sub1}.example.com/",
+		"shop.example.com/p",
+		"ftp://shop.example.com/p",
+	} {
+		if _, problems := settings.ValidateDestinationTemplate(template); len(problems) == 0 {
+			t.Errorf("ValidateDestinationTemplate(%q) found no problems", template)
+		}
+	}
+}
+
+func TestSetCampaignURLSettingsRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []PassthroughParameter
+		wantErr bool
+	}{
+		{name: "valid", params: []PassthroughParameter{{Name: "gclid"}, {Name: "utm_source", Encoding: URLEncodingQuery}}},
+		{name: "built-in macro", params: []PassthroughParameter{{Name: "sub1"}}, wantErr: true},
+		{name: "duplicate", params: []PassthroughParameter{{Name: "gclid"}, {Name: "gclid"}}, wantErr: true},
+		{name: "bad name", params: []PassthroughParameter{{Name: "a b"}}, wantErr: true},
+		{name: "bad encoding", params: []PassthroughParameter{{Name: "gclid", Encoding: "base64"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := SetCampaignURLSettingsRequest{PassthroughParameters: tt.params}
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && req.PassthroughParameters[0].Encoding == "" {
+				t.Errorf("default encoding not set")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key := DeriveKey([]byte("secret"), "sign")
+	signature := Sign(key, "link=1&sub1=a")
+
+	if !Verify(key, "link=1&sub1=a", signature) {
+		t.Error("Verify() rejected a valid signature")
+	}
+	if Verify(key, "link=1&sub1=b", signature) {
+		t.Error("Verify() accepted a signature of another message")
+	}
+	if Verify(DeriveKey([]byte("other"), "sign"), "link=1&sub1=a", signature) {
+		t.Error("Verify() accepted a signature made with another key")
+	}
+	if Verify(key, "link=1&sub1=a", "not base64!") {
+		t.Error("Verify() accepted a malformed signature")
+	}
+}
+
+func TestSealAndOpen(t *testing.T) {
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	secret, _ := base64.StdEncoding.DecodeString(encoded)
+	key := DeriveKey(secret, "encrypt")
+
+	sealed, err := Seal(key, []byte("sub1=a"), []byte("link=1"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	plaintext, err := Open(key, sealed, []byte("link=1"))
+	if err != nil || string(plaintext) != "sub1=a" {
+		t.Errorf("Open() = %q, %v", plaintext, err)
+	}
+	if _, err := Open(key, sealed, []byte("link=2")); err == nil {
+		t.Error("Open() accepted other additional data")
+	}
+	if _, err := Open(key, sealed[:len(sealed)-2]+"AA", []byte("link=1")); err == nil {
+		t.Error("Open() accepted a modified ciphertext")
+	}
+	if _, err := Seal([]byte("short"), nil, nil); err == nil {
+		t.Error("Seal() accepted a short key")
+	}
+}
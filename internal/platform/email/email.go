@@ -0,0 +1,302 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/platform/logger"
+)
+
+// Config holds the SMTP configuration
+type Config struct {
+	Host     string
+	Port     int
+	Username string // Optional; no authentication is attempted when empty
+	Password string
+	From     string // Default sender address
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message represents an outgoing email
+type Message struct {
+	From        string // Defaults to Config.From
+	To          []string
+	ReplyTo     string
+	Subject     string
+	TextBody    string
+	MessageID   string            // Generated when empty
+	Headers     map[string]string // Additional headers (e.g. In-Reply-To)
+	Attachments []Attachment
+}
+
+// Sender sends email messages
+type Sender interface {
+	// Send delivers the message and returns the Message-ID it was sent with
+	Send(ctx context.Context, msg *Message) (string, error)
+}
+
+// SMTPSender sends email through an SMTP server
+type SMTPSender struct {
+	config Config
+}
+
+// Ensure SMTPSender implements Sender
+var _ Sender = (*SMTPSender)(nil)
+
+// NewSMTPSender creates a new SMTP sender
+func NewSMTPSender(config Config) *SMTPSender {
+	return &SMTPSender{config: config}
+}
+
+// Send delivers the message through the configured SMTP server.
+// STARTTLS is used when the server offers it; authentication only when a username is configured.
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) (string, error) {
+	if len(msg.To) == 0 {
+		return "", fmt.Errorf("email has no recipients")
+	}
+	from := msg.From
+	if from == "" {
+		from = s.config.From
+	}
+	if from == "" {
+		return "", fmt.Errorf("email has no sender address")
+	}
+
+	messageID := msg.MessageID
+	if messageID == "" {
+		messageID = generateMessageID(from)
+	}
+
+	body, err := buildMIME(msg, from, messageID, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.config.Host}); err != nil {
+			return "", fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return "", fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(extractAddress(from)); err != nil {
+		return "", fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(extractAddress(to)); err != nil {
+			return "", fmt.Errorf("SMTP RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return "", fmt.Errorf("failed to write email body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish email body: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		logger.Warn("SMTP QUIT failed after message was accepted", "error", err)
+	}
+
+	logger.Info("Email sent", "message_id", messageID, "recipients", len(msg.To))
+	return messageID, nil
+}
+
+// LoggingSender logs emails instead of sending them, for environments without SMTP
+type LoggingSender struct{}
+
+// Ensure LoggingSender implements Sender
+var _ Sender = (*LoggingSender)(nil)
+
+// NewLoggingSender creates a new logging sender
+func NewLoggingSender() *LoggingSender {
+	logger.Info("SMTP not configured - outgoing email will only be logged")
+	return &LoggingSender{}
+}
+
+// Send logs the message and returns a generated Message-ID
+func (l *LoggingSender) Send(ctx context.Context, msg *Message) (string, error) {
+	messageID := msg.MessageID
+	if messageID == "" {
+		messageID = generateMessageID(msg.From)
+	}
+	logger.Info("Email not sent (logging sender)",
+		"message_id", messageID,
+		"to", strings.Join(msg.To, ", "),
+		"subject", msg.Subject,
+		"attachments", len(msg.Attachments))
+	return messageID, nil
+}
+
+// buildMIME renders the message as an RFC 5322 message with optional attachments
+func buildMIME(msg *Message, from, messageID string, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"Message-ID: " + messageID,
+		"MIME-Version: 1.0",
+	}
+	if msg.ReplyTo != "" {
+		headers = append(headers, "Reply-To: "+msg.ReplyTo)
+	}
+	names := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		headers = append(headers, textproto.CanonicalMIMEHeaderKey(name)+": "+msg.Headers[name])
+	}
+
+	if len(msg.Attachments) == 0 {
+		headers = append(headers,
+			"Content-Type: text/plain; charset=utf-8",
+			"Content-Transfer-Encoding: quoted-printable",
+		)
+		buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, msg.TextBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	headers = append(headers, "Content-Type: multipart/mixed; boundary="+mw.Boundary())
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create text part: %w", err)
+	}
+	if err := writeQuotedPrintable(textPart, msg.TextBody); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create attachment part: %w", err)
+		}
+		if err := writeBase64Lines(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// generateMessageID returns a unique Message-ID using the sender's domain
+func generateMessageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(extractAddress(from), "@"); at >= 0 {
+		domain = extractAddress(from)[at+1:]
+	}
+	random := make([]byte, 12)
+	rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// extractAddress returns the bare address from "Name <addr>" or "addr"
+func extractAddress(value string) string {
+	if start := strings.LastIndex(value, "<"); start >= 0 {
+		if end := strings.LastIndex(value, ">"); end > start {
+			return value[start+1 : end]
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// writeQuotedPrintable writes text using quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return fmt.Errorf("failed to write email text: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to write email text: %w", err)
+	}
+	return nil
+}
+
+// writeBase64Lines writes base64 data wrapped at 76 characters per line
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return fmt.Errorf("failed to write attachment: %w", err)
+		}
+		encoded = encoded[76:]
+	}
+	if _, err := w.Write([]byte(encoded + "\r\n")); err != nil {
+		return fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturedMail is a message received by the fake SMTP server
+type capturedMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// startFakeSMTPServer starts a minimal MailHog-style SMTP server that accepts every message
+func startFakeSMTPServer(t *testing.T) (string, int, func() []capturedMail) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	var received []capturedMail
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+				var current capturedMail
+				write("220 localhost fake SMTP")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					command := strings.ToUpper(strings.TrimSpace(line))
+					switch {
+					case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+						write("250 localhost")
+					case strings.HasPrefix(command, "MAIL FROM:"):
+						current = capturedMail{From: strings.Trim(strings.TrimSpace(line)[10:], "<>")}
+						write("250 OK")
+					case strings.HasPrefix(command, "RCPT TO:"):
+						current.To = append(current.To, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+						write("250 OK")
+					case command == "DATA":
+						write("354 End data with <CR><LF>.<CR><LF>")
+						var data strings.Builder
+						for {
+							dataLine, err := reader.ReadString('\n')
+							if err != nil {
+								return
+							}
+							if dataLine == ".\r\n" {
+								break
+							}
+							data.WriteString(dataLine)
+						}
+						current.Data = data.String()
+						mu.Lock()
+						received = append(received, current)
+						mu.Unlock()
+						write("250 OK queued")
+					case command == "QUIT":
+						write("221 Bye")
+						return
+					default:
+						write("250 OK")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, func() []capturedMail {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]capturedMail(nil), received...)
+	}
+}
+
+func TestSMTPSender_SendWithAttachment(t *testing.T) {
+	host, port, received := startFakeSMTPServer(t)
+
+	sender := NewSMTPSender(Config{Host: host, Port: port, From: "Reports <reports@example.com>"})
+
+	messageID, err := sender.Send(context.Background(), &Message{
+		To:       []string{"alice@example.com", "bob@example.com"},
+		ReplyTo:  "reply+token@example.com",
+		Subject:  "Daily performance",
+		TextBody: "Your report is attached.",
+		Headers:  map[string]string{"X-Report-Id": "42"},
+		Attachments: []Attachment{
+			{Filename: "report.csv", ContentType: "text/csv", Data: []byte("date,clicks\n2024-03-15,10\n")},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(messageID, "@example.com>"))
+
+	mails := received()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "reports@example.com", mails[0].From)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, mails[0].To)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(mails[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, "Daily performance", parsed.Header.Get("Subject"))
+	assert.Equal(t, "reply+token@example.com", parsed.Header.Get("Reply-To"))
+	assert.Equal(t, messageID, parsed.Header.Get("Message-Id"))
+	assert.Equal(t, "42", parsed.Header.Get("X-Report-Id"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	textPart, err := reader.NextPart()
+	require.NoError(t, err)
+	text, _ := io.ReadAll(textPart)
+	assert.Equal(t, "Your report is attached.", string(text))
+
+	attachmentPart, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.csv", attachmentPart.FileName())
+	assert.Equal(t, "base64", attachmentPart.Header.Get("Content-Transfer-Encoding"))
+	attachment, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attachmentPart))
+	require.NoError(t, err)
+	assert.Equal(t, "date,clicks\n2024-03-15,10\n", string(attachment))
+}
+
+func TestSMTPSender_RequiresRecipients(t *testing.T) {
+	sender := NewSMTPSender(Config{Host: "127.0.0.1", Port: 1, From: "reports@example.com"})
+
+	_, err := sender.Send(context.Background(), &Message{Subject: "No one"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recipients")
+}
@@ -0,0 +1,304 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxMIMEDepth limits how deeply nested multipart bodies are parsed
+const maxMIMEDepth = 10
+
+// InboundMessage is a parsed incoming email
+type InboundMessage struct {
+	From        string   // Bare sender address
+	FromName    string   // Display name of the sender, if any
+	To          []string // Bare recipient addresses from To, Cc, Delivered-To and X-Original-To
+	Subject     string
+	MessageID   string   // Including angle brackets, e.g. "<abc@example.com>"
+	InReplyTo   []string // Message-IDs from In-Reply-To
+	References  []string // Message-IDs from References
+	Date        time.Time
+	TextBody    string // Plain text body; derived from the HTML body when there is no text part
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// ParseInbound parses a raw RFC 5322 message
+func ParseInbound(raw []byte) (*InboundMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email message: %w", err)
+	}
+
+	decoder := &mime.WordDecoder{CharsetReader: charsetReader}
+	decodeHeader := func(value string) string {
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			return decoded
+		}
+		return value
+	}
+
+	inbound := &InboundMessage{
+		Subject:    strings.TrimSpace(decodeHeader(msg.Header.Get("Subject"))),
+		MessageID:  strings.TrimSpace(msg.Header.Get("Message-Id")),
+		InReplyTo:  parseMessageIDs(msg.Header.Get("In-Reply-To")),
+		References: parseMessageIDs(msg.Header.Get("References")),
+	}
+
+	if from, err := parseAddressList(decoder, msg.Header.Get("From")); err == nil && len(from) > 0 {
+		inbound.From = strings.ToLower(from[0].Address)
+		inbound.FromName = from[0].Name
+	} else {
+		inbound.From = strings.ToLower(extractAddress(msg.Header.Get("From")))
+	}
+
+	seen := make(map[string]bool)
+	for _, header := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		for _, value := range msg.Header[header] {
+			addresses, err := parseAddressList(decoder, value)
+			if err != nil {
+				continue
+			}
+			for _, address := range addresses {
+				addr := strings.ToLower(address.Address)
+				if !seen[addr] {
+					seen[addr] = true
+					inbound.To = append(inbound.To, addr)
+				}
+			}
+		}
+	}
+
+	if date, err := msg.Header.Date(); err == nil {
+		inbound.Date = date
+	}
+
+	header := map[string][]string(msg.Header)
+	if err := inbound.walkPart(header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+
+	if inbound.TextBody == "" && inbound.HTMLBody != "" {
+		inbound.TextBody = htmlToText(inbound.HTMLBody)
+	}
+	inbound.TextBody = strings.ReplaceAll(inbound.TextBody, "\r\n", "\n")
+
+	return inbound, nil
+}
+
+// walkPart collects the text bodies and attachments of a (possibly multipart) part
+func (m *InboundMessage) walkPart(header map[string][]string, body io.Reader, depth int) error {
+	if depth > maxMIMEDepth {
+		return fmt.Errorf("email is nested too deeply")
+	}
+
+	get := func(name string) string {
+		if values := header[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if err := m.walkPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransferEncoding(get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("failed to decode email part: %w", err)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	isAttachment := disposition == "attachment" || filename != "" || mediaType == "message/rfc822"
+	switch {
+	case !isAttachment && mediaType == "text/plain" && m.TextBody == "":
+		m.TextBody = toUTF8(data, params["charset"])
+	case !isAttachment && mediaType == "text/html" && m.HTMLBody == "":
+		m.HTMLBody = toUTF8(data, params["charset"])
+	case isAttachment || !strings.HasPrefix(mediaType, "text/"):
+		if filename == "" {
+			filename = "attachment"
+			if mediaType == "message/rfc822" {
+				filename = "message.eml"
+			}
+		}
+		decoder := &mime.WordDecoder{CharsetReader: charsetReader}
+		if decoded, err := decoder.DecodeHeader(filename); err == nil {
+			filename = decoded
+		}
+		m.Attachments = append(m.Attachments, Attachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Data:        data,
+		})
+	}
+
+	return nil
+}
+
+// decodeTransferEncoding wraps body in a decoder for its Content-Transfer-Encoding
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// The base64 decoder skips CR and LF, so line-wrapped data decodes as-is
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// toUTF8 converts text in the given charset to UTF-8. Latin-1 style charsets are converted;
+// other charsets are returned unchanged when they already are valid UTF-8.
+func toUTF8(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		if !utf8.Valid(data) {
+			runes := make([]rune, len(data))
+			for i, b := range data {
+				runes[i] = rune(b)
+			}
+			return string(runes)
+		}
+	}
+	return strings.ToValidUTF8(string(data), "�")
+}
+
+// charsetReader supports Latin-1 encoded words in headers in addition to UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(toUTF8(data, charset)), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset: %s", charset)
+	}
+}
+
+// parseAddressList parses an address header, decoding encoded display names
+func parseAddressList(decoder *mime.WordDecoder, value string) ([]*mail.Address, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	parser := mail.AddressParser{WordDecoder: decoder}
+	return parser.ParseList(value)
+}
+
+// messageIDPattern matches a Message-ID in angle brackets
+var messageIDPattern = regexp.MustCompile(`<[^<>\s]+>`)
+
+// parseMessageIDs extracts the Message-IDs from an In-Reply-To or References header
+func parseMessageIDs(value string) []string {
+	return messageIDPattern.FindAllString(value, -1)
+}
+
+var (
+	htmlQuotePattern     = regexp.MustCompile(`(?is)<div[^>]*class="[^"]*(gmail_quote|moz-cite-prefix|OutlookMessageHeader)[^"]*".*$|<blockquote.*$`)
+	htmlBreakPattern     = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>|</h[1-6]>`)
+	htmlInvisiblePattern = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	htmlTagPattern       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern    = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText converts an HTML body to plain text, dropping quoted replies
+func htmlToText(body string) string {
+	text := htmlQuotePattern.ReplaceAllString(body, "")
+	text = htmlInvisiblePattern.ReplaceAllString(text, "")
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.ReplaceAll(line, "\u00a0", " "))
+	}
+	text = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+var (
+	// "On Mon, 4 Mar 2024 at 10:00, Jane <jane@example.com> wrote:", possibly wrapped over two lines
+	replyHeaderPattern = regexp.MustCompile(`(?i)^(on\s.+|le\s.+|am\s.+|el\s.+)\s(wrote|a écrit|schrieb|escribió)\s*:$`)
+	// "-----Original Message-----" and Outlook's underscore separator
+	originalMessagePattern = regexp.MustCompile(`(?i)^(-{2,}\s*(original message|forwarded message)\s*-{2,}|_{10,})$`)
+	outlookHeaderPattern   = regexp.MustCompile(`(?i)^\*?(from|de|von)\s*:\*?\s`)
+	outlookDetailPattern   = regexp.MustCompile(`(?i)^\*?(sent|date|to|subject|envoyé|gesendet)\s*:`)
+)
+
+// StripQuotedReply removes quoted history from a plain text reply: everything from the
+// "On ... wrote:" line or an Outlook-style header block onwards, and any ">" quoted lines.
+// The full text is returned if nothing would remain.
+func StripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	cut := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if originalMessagePattern.MatchString(trimmed) || replyHeaderPattern.MatchString(trimmed) {
+			cut = i
+			break
+		}
+		// Reply headers wrapped over two lines
+		if i+1 < len(lines) && strings.HasPrefix(strings.ToLower(trimmed), "on ") &&
+			replyHeaderPattern.MatchString(trimmed+" "+strings.TrimSpace(lines[i+1])) {
+			cut = i
+			break
+		}
+		// Outlook header block: "From: ..." followed by "Sent:"/"Date:"/"To:" lines
+		if outlookHeaderPattern.MatchString(trimmed) && i+1 < len(lines) && outlookDetailPattern.MatchString(strings.TrimSpace(lines[i+1])) {
+			cut = i
+			break
+		}
+	}
+
+	kept := make([]string, 0, cut)
+	for _, line := range lines[:cut] {
+		if strings.HasPrefix(strings.TrimLeft(line, " "), ">") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+
+	stripped := strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(kept, "\n"), "\n\n"))
+	if stripped == "" {
+		return strings.TrimSpace(text)
+	}
+	return stripped
+}
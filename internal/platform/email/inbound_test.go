@@ -0,0 +1,185 @@
+package email
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gmailStyleReply = "From: =?utf-8?q?Jos=C3=A9?= <Jose@Publisher.example>\r\n" +
+	"To: \"Acme via Platform\" <reply+42-abcdef0123456789@replies.example.com>\r\n" +
+	"Subject: Re: Partnership\r\n" +
+	"Message-ID: <reply-1@publisher.example>\r\n" +
+	"In-Reply-To: <outbound-1@platform.example>\r\n" +
+	"References: <root@platform.example> <outbound-1@platform.example>\r\n" +
+	"Date: Mon, 4 Mar 2024 10:00:00 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Sounds great, send me the terms =E2=80=93 Jos=C3=A9\r\n" +
+	"\r\n" +
+	"On Sun, 3 Mar 2024 at 09:00, Acme <reply+42-abcdef0123456789@replies.example.com> wrote:\r\n" +
+	"> Would you like to promote our offers?\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Sounds great</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"media-kit.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"media-kit.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--outer--\r\n"
+
+func TestParseInbound(t *testing.T) {
+	msg, err := ParseInbound([]byte(gmailStyleReply))
+	require.NoError(t, err)
+
+	assert.Equal(t, "jose@publisher.example", msg.From)
+	assert.Equal(t, "José", msg.FromName)
+	assert.Equal(t, []string{"reply+42-abcdef0123456789@replies.example.com"}, msg.To)
+	assert.Equal(t, "Re: Partnership", msg.Subject)
+	assert.Equal(t, "<reply-1@publisher.example>", msg.MessageID)
+	assert.Equal(t, []string{"<outbound-1@platform.example>"}, msg.InReplyTo)
+	assert.Equal(t, []string{"<root@platform.example>", "<outbound-1@platform.example>"}, msg.References)
+	assert.Equal(t, time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), msg.Date.UTC())
+	assert.True(t, strings.HasPrefix(msg.TextBody, "Sounds great, send me the terms – José\n"))
+	assert.Contains(t, msg.HTMLBody, "<p>Sounds great</p>")
+
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "media-kit.pdf", msg.Attachments[0].Filename)
+	assert.Equal(t, "application/pdf", msg.Attachments[0].ContentType)
+	assert.Equal(t, "%PDF-1.4\n", string(msg.Attachments[0].Data))
+}
+
+func TestParseInbound_HTMLOnly(t *testing.T) {
+	raw := "From: jose@publisher.example\r\n" +
+		"To: reply@replies.example.com\r\n" +
+		"Subject: Re: Hi\r\n" +
+		"Content-Type: text/html; charset=iso-8859-1\r\n" +
+		"\r\n" +
+		"<html><head><style>p{}</style></head><body><p>Yes, we&#39;re interested.</p><p>Caf\xe9 team</p>" +
+		"<div class=\"gmail_quote\">On Sun wrote:<blockquote>old</blockquote></div></body></html>"
+
+	msg, err := ParseInbound([]byte(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "Yes, we're interested.\nCafé team", msg.TextBody)
+}
+
+func TestStripQuotedReply(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "gmail reply header",
+			text: "Thanks, works for us.\n\nOn Mon, Mar 4, 2024 at 10:00 AM Acme <a@b.com> wrote:\n> Hello\n> there",
+			want: "Thanks, works for us.",
+		},
+		{
+			name: "wrapped reply header",
+			text: "Thanks!\n\nOn Mon, Mar 4, 2024 at 10:00 AM Acme Partnerships <reply+1-abc@replies.example.com>\nwrote:\n> Hello",
+			want: "Thanks!",
+		},
+		{
+			name: "outlook original message",
+			text: "Approved.\r\n\r\n-----Original Message-----\r\nFrom: Acme\r\nSent: Monday\r\n",
+			want: "Approved.",
+		},
+		{
+			name: "outlook header block",
+			text: "Let's talk tomorrow.\n\nFrom: Acme <a@b.com>\nSent: Monday, March 4, 2024 10:00 AM\nTo: Jose\nSubject: Partnership",
+			want: "Let's talk tomorrow.",
+		},
+		{
+			name: "interleaved quotes",
+			text: "> Do you have a media kit?\nYes, attached.\n> What are your rates?\nSee the kit.",
+			want: "Yes, attached.\nSee the kit.",
+		},
+		{
+			name: "only quoted text keeps original",
+			text: "> Hello",
+			want: "> Hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StripQuotedReply(tt.text))
+		})
+	}
+}
+
+func TestInboundServer_ReceivesFromSMTPSender(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var gotFrom string
+	var gotRecipients []string
+	var gotRaw []byte
+	server := NewInboundServer("", "mx.test", 1024*1024, func(ctx context.Context, from string, recipients []string, raw []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		gotFrom, gotRecipients, gotRaw = from, recipients, raw
+		return nil
+	})
+	go server.Serve(listener)
+	defer server.Close()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	sender := NewSMTPSender(Config{Host: "127.0.0.1", Port: addr.Port, From: "jose@publisher.example"})
+	_, err = sender.Send(context.Background(), &Message{
+		To:       []string{"reply+42-abcdef0123456789@replies.example.com"},
+		Subject:  "Re: Partnership",
+		TextBody: "Sounds great\n.\nA line with a single dot",
+	})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "jose@publisher.example", gotFrom)
+	assert.Equal(t, []string{"reply+42-abcdef0123456789@replies.example.com"}, gotRecipients)
+
+	msg, err := ParseInbound(gotRaw)
+	require.NoError(t, err)
+	assert.Equal(t, "Re: Partnership", msg.Subject)
+	assert.Equal(t, "Sounds great\n.\nA line with a single dot", strings.TrimRight(msg.TextBody, "\n"))
+}
+
+func TestInboundServer_RejectsOversizedMessages(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewInboundServer("", "mx.test", 64, func(ctx context.Context, from string, recipients []string, raw []byte) error {
+		t.Error("handler should not be called for oversized messages")
+		return nil
+	})
+	go server.Serve(listener)
+	defer server.Close()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	sender := NewSMTPSender(Config{Host: "127.0.0.1", Port: addr.Port, From: "jose@publisher.example"})
+	_, err = sender.Send(context.Background(), &Message{
+		To:       []string{"reply@replies.example.com"},
+		Subject:  "Too big",
+		TextBody: strings.Repeat("x", 200),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package email
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// replySignatureLength is the number of hex characters of the HMAC kept in a reply address
+const replySignatureLength = 16
+
+// ReplyAddressCodec encodes a thread ID into a signed Reply-To address such as
+// "reply+42-3f1c9a0b5d7e2c48@replies.example.com", so that replies can be routed back
+// to the thread without trusting anything else in the inbound email
+type ReplyAddressCodec struct {
+	prefix string
+	domain string
+	secret []byte
+}
+
+// NewReplyAddressCodec creates a codec for addresses "<prefix>+<id>-<signature>@<domain>"
+func NewReplyAddressCodec(prefix, domain, secret string) *ReplyAddressCodec {
+	if prefix == "" {
+		prefix = "reply"
+	}
+	return &ReplyAddressCodec{
+		prefix: strings.ToLower(prefix),
+		domain: strings.ToLower(domain),
+		secret: []byte(secret),
+	}
+}
+
+// Address returns the reply address for the given thread ID
+func (c *ReplyAddressCodec) Address(threadID int64) string {
+	return fmt.Sprintf("%s+%d-%s@%s", c.prefix, threadID, c.sign(threadID), c.domain)
+}
+
+// Parse extracts the thread ID from a reply address, accepting "Name <addr>" forms.
+// It returns false when the address is not a reply address of this codec or its signature is invalid.
+func (c *ReplyAddressCodec) Parse(address string) (int64, bool) {
+	addr := strings.ToLower(extractAddress(address))
+	at := strings.LastIndex(addr, "@")
+	if at < 0 || addr[at+1:] != c.domain {
+		return 0, false
+	}
+
+	tag, ok := strings.CutPrefix(addr[:at], c.prefix+"+")
+	if !ok {
+		return 0, false
+	}
+	idPart, signature, ok := strings.Cut(tag, "-")
+	if !ok {
+		return 0, false
+	}
+	threadID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || threadID <= 0 {
+		return 0, false
+	}
+	if !hmac.Equal([]byte(signature), []byte(c.sign(threadID))) {
+		return 0, false
+	}
+	return threadID, true
+}
+
+// sign returns the truncated HMAC of the thread ID
+func (c *ReplyAddressCodec) sign(threadID int64) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(strconv.FormatInt(threadID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:replySignatureLength]
+}
@@ -0,0 +1,52 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplyAddressCodec_RoundTrip(t *testing.T) {
+	codec := NewReplyAddressCodec("reply", "replies.example.com", "secret")
+
+	address := codec.Address(42)
+	assert.Regexp(t, `^reply\+42-[0-9a-f]{16}@replies\.example\.com$`, address)
+
+	id, ok := codec.Parse(address)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), id)
+
+	id, ok = codec.Parse(`"Publisher Replies" <` + address + `>`)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestReplyAddressCodec_RejectsForgedAddresses(t *testing.T) {
+	codec := NewReplyAddressCodec("reply", "replies.example.com", "secret")
+	other := NewReplyAddressCodec("reply", "replies.example.com", "other-secret")
+
+	tests := []string{
+		other.Address(42),
+		"reply+43-" + codec.sign(42) + "@replies.example.com",
+		"reply+42@replies.example.com",
+		"support@replies.example.com",
+		"reply+42-" + codec.sign(42) + "@example.com",
+		"not an address",
+	}
+	for _, address := range tests {
+		_, ok := codec.Parse(address)
+		assert.False(t, ok, address)
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl := MustParseTemplate("test", "Re: {{.Subject}}\n", "Hello {{.Name}},\n{{.Body}}\n")
+
+	subject, body, err := tmpl.Render(map[string]string{"Subject": "Partnership", "Name": "Ana", "Body": "Welcome"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Re: Partnership", subject)
+	assert.Equal(t, "Hello Ana,\nWelcome\n", body)
+
+	_, _, err = tmpl.Render(map[string]string{"Subject": "Partnership"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/affiliate-backend/internal/platform/logger"
+)
+
+// InboundHandler processes a message received by the InboundServer
+type InboundHandler func(ctx context.Context, from string, recipients []string, raw []byte) error
+
+// InboundServer is a minimal SMTP receiver for accepting replies directly, e.g. behind an MX
+// record or for local development. It does not relay mail, authenticate clients or offer TLS,
+// so it should only be exposed behind a mail gateway or on a private network.
+type InboundServer struct {
+	addr     string
+	hostname string
+	maxBytes int64
+	handler  InboundHandler
+
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+// NewInboundServer creates an SMTP receiver listening on addr. Messages larger than maxBytes are rejected.
+func NewInboundServer(addr, hostname string, maxBytes int64, handler InboundHandler) *InboundServer {
+	if hostname == "" {
+		hostname = "localhost"
+	}
+	if maxBytes <= 0 {
+		maxBytes = 25 * 1024 * 1024
+	}
+	return &InboundServer{
+		addr:     addr,
+		hostname: hostname,
+		maxBytes: maxBytes,
+		handler:  handler,
+	}
+}
+
+// ListenAndServe accepts connections until Close is called
+func (s *InboundServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for inbound email: %w", err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on the listener until Close is called
+func (s *InboundServer) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return nil
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	logger.Info("Inbound SMTP server listening", "addr", listener.Addr().String())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("failed to accept inbound email connection: %w", err)
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections
+func (s *InboundServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// serveConn runs one SMTP session
+func (s *InboundServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(code int, message string) bool {
+		conn.SetWriteDeadline(time.Now().Add(time.Minute))
+		return text.PrintfLine("%d %s", code, message) == nil
+	}
+
+	if !reply(220, s.hostname+" ESMTP ready") {
+		return
+	}
+
+	var from string
+	var recipients []string
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Minute))
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reply(250, s.hostname)
+		case "EHLO":
+			text.PrintfLine("250-%s", s.hostname)
+			text.PrintfLine("250-SIZE %d", s.maxBytes)
+			reply(250, "8BITMIME")
+		case "MAIL":
+			from = parsePathArg(arg, "FROM:")
+			recipients = nil
+			reply(250, "OK")
+		case "RCPT":
+			if len(recipients) >= 100 {
+				reply(452, "Too many recipients")
+				continue
+			}
+			recipients = append(recipients, parsePathArg(arg, "TO:"))
+			reply(250, "OK")
+		case "DATA":
+			if len(recipients) == 0 {
+				reply(503, "Need RCPT before DATA")
+				continue
+			}
+			if !reply(354, "End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			raw, err := readData(text.DotReader(), s.maxBytes)
+			if err != nil {
+				if errors.Is(err, errMessageTooLarge) {
+					reply(552, "Message exceeds maximum size")
+					continue
+				}
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			err = s.handler(ctx, from, recipients, raw)
+			cancel()
+			if err != nil {
+				logger.Error("Failed to process inbound email", "from", from, "error", err)
+				reply(451, "Temporary failure processing message")
+			} else {
+				reply(250, "OK: message accepted")
+			}
+			from, recipients = "", nil
+		case "RSET":
+			from, recipients = "", nil
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// errMessageTooLarge is returned by readData when the message exceeds the size limit
+var errMessageTooLarge = errors.New("message too large")
+
+// readData reads the dot-encoded message, draining it fully even when it is too large
+func readData(reader io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			return nil, err
+		}
+		return nil, errMessageTooLarge
+	}
+	return data, nil
+}
+
+// parsePathArg extracts the address from "FROM:<addr> SIZE=123" style arguments
+func parsePathArg(arg, prefix string) string {
+	arg = strings.TrimSpace(arg)
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = strings.TrimSpace(arg[len(prefix):])
+	}
+	if start := strings.Index(arg, "<"); start >= 0 {
+		if end := strings.Index(arg[start:], ">"); end >= 0 {
+			return strings.ToLower(arg[start+1 : start+end])
+		}
+	}
+	if fields := strings.Fields(arg); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Template renders the subject and plain text body of an email from data
+type Template struct {
+	subject *template.Template
+	text    *template.Template
+}
+
+// ParseTemplate parses the subject and text body templates (text/template syntax)
+func ParseTemplate(name, subject, text string) (*Template, error) {
+	subjectTmpl, err := template.New(name + ".subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s subject template: %w", name, err)
+	}
+	textTmpl, err := template.New(name + ".text").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s text template: %w", name, err)
+	}
+	return &Template{subject: subjectTmpl, text: textTmpl}, nil
+}
+
+// MustParseTemplate is like ParseTemplate but panics on error; for templates defined in code
+func MustParseTemplate(name, subject, text string) *Template {
+	tmpl, err := ParseTemplate(name, subject, text)
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
+}
+
+// Render executes the templates and returns the subject (on a single line) and the text body
+func (t *Template) Render(data interface{}) (string, string, error) {
+	var subject, text bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return "", "", fmt.Errorf("failed to render email body: %w", err)
+	}
+	return strings.Join(strings.Fields(subject.String()), " "), text.String(), nil
+}
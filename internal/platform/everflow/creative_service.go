@@ -0,0 +1,223 @@
+package everflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/everflow/offer"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/provider"
+)
+
+// CreativeConfig holds the configuration for the Everflow creative service
+type CreativeConfig struct {
+	BaseURL string
+	APIKey  string
+}
+
+// CreativeService syncs campaign creatives to the creatives of the matching Everflow offer
+type CreativeService struct {
+	config     CreativeConfig
+	httpClient *http.Client
+}
+
+// Ensure CreativeService implements provider.CreativeService
+var _ provider.CreativeService = (*CreativeService)(nil)
+
+// NewCreativeService creates a new Everflow creative service
+func NewCreativeService(config CreativeConfig) *CreativeService {
+	return &CreativeService{
+		config:     config,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// creativeRequest is the body of POST /networks/creatives and PUT /networks/creatives/{id}
+type creativeRequest struct {
+	NetworkOfferID int32               `json:"network_offer_id"`
+	Name           string              `json:"name"`
+	CreativeType   string              `json:"creative_type"`
+	IsPrivate      bool                `json:"is_private"`
+	CreativeStatus string              `json:"creative_status"`
+	HtmlCode       *string             `json:"html_code,omitempty"`
+	Width          *int32              `json:"width,omitempty"`
+	Height         *int32              `json:"height,omitempty"`
+	EmailFrom      *string             `json:"email_from,omitempty"`
+	EmailSubject   *string             `json:"email_subject,omitempty"`
+	ResourceFile   *offer.ResourceFile `json:"resource_file,omitempty"`
+}
+
+// creativeResponse is the response of the creative endpoints
+type creativeResponse struct {
+	NetworkOfferCreativeID int64 `json:"network_offer_creative_id"`
+}
+
+// uploadResponse is the response of POST /networks/uploads/temp
+type uploadResponse struct {
+	TempURL string `json:"temp_url"`
+}
+
+// ProviderType returns the provider identifier for Everflow
+func (s *CreativeService) ProviderType() string {
+	return "everflow"
+}
+
+// SyncCreative uploads the image of image creatives, then creates or updates the offer creative.
+// Only approved creatives are active on Everflow; pending and rejected ones are paused.
+func (s *CreativeService) SyncCreative(ctx context.Context, creative *domain.Creative, file []byte, campaignMapping *domain.CampaignProviderMapping) (string, error) {
+	networkOfferID, err := networkOfferIDFromMapping(campaignMapping)
+	if err != nil {
+		return "", err
+	}
+
+	reqBody := creativeRequest{
+		NetworkOfferID: networkOfferID,
+		Name:           creative.Name,
+		CreativeType:   creative.CreativeType,
+		IsPrivate:      creative.IsPrivate,
+		CreativeStatus: "paused",
+		HtmlCode:       creative.HTMLCode,
+		EmailFrom:      creative.EmailFrom,
+		EmailSubject:   creative.EmailSubject,
+	}
+	if creative.IsApproved() {
+		reqBody.CreativeStatus = "active"
+	}
+	if creative.Width != nil && creative.Height != nil {
+		width, height := int32(*creative.Width), int32(*creative.Height)
+		reqBody.Width, reqBody.Height = &width, &height
+	}
+	if creative.CreativeType == domain.CreativeTypeImage && len(file) > 0 {
+		fileName := fmt.Sprintf("creative-%d", creative.CreativeID)
+		if creative.FileName != nil {
+			fileName = *creative.FileName
+		}
+		tempURL, err := s.uploadFile(ctx, fileName, file)
+		if err != nil {
+			return "", err
+		}
+		reqBody.ResourceFile = &offer.ResourceFile{TempUrl: tempURL, OriginalFileName: fileName}
+	}
+
+	method, path := http.MethodPost, "/networks/creatives"
+	if creative.ProviderCreativeID != nil {
+		method, path = http.MethodPut, "/networks/creatives/"+*creative.ProviderCreativeID
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal creative request: %w", err)
+	}
+
+	logger.Debug("Syncing creative to Everflow", "creative_id", creative.CreativeID, "network_offer_id", networkOfferID, "method", method)
+
+	body, err := s.do(ctx, method, path, "application/json", payload)
+	if err != nil {
+		return "", err
+	}
+	var resp creativeResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to decode Everflow creative response: %w", err)
+	}
+	if resp.NetworkOfferCreativeID == 0 {
+		if creative.ProviderCreativeID != nil {
+			return *creative.ProviderCreativeID, nil
+		}
+		return "", fmt.Errorf("Everflow creative response has no network_offer_creative_id")
+	}
+
+	logger.Info("Synced creative to Everflow", "creative_id", creative.CreativeID, "network_offer_creative_id", resp.NetworkOfferCreativeID)
+	return strconv.FormatInt(resp.NetworkOfferCreativeID, 10), nil
+}
+
+// uploadFile uploads a file to Everflow temporary storage and returns its temporary URL,
+// which creatives reference as their resource file
+func (s *CreativeService) uploadFile(ctx context.Context, fileName string, data []byte) (string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to build creative upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to build creative upload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build creative upload: %w", err)
+	}
+
+	body, err := s.do(ctx, http.MethodPost, "/networks/uploads/temp", writer.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	var resp uploadResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to decode Everflow upload response: %w", err)
+	}
+	if resp.TempURL == "" {
+		return "", fmt.Errorf("Everflow upload response has no temp_url")
+	}
+	return resp.TempURL, nil
+}
+
+// do sends a request to the Everflow API and returns the body of a successful response
+func (s *CreativeService) do(ctx context.Context, method, path, contentType string, payload []byte) ([]byte, error) {
+	url := strings.TrimSuffix(s.config.BaseURL, "/") + path
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Everflow request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("X-Eflow-API-Key", s.config.APIKey)
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Everflow API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Everflow response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Everflow API %s %s returned status %d: %s", method, path, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// networkOfferIDFromMapping reads the Everflow offer ID of a campaign from its provider mapping,
+// accepting the stored create payload as well as plain provider data
+func networkOfferIDFromMapping(mapping *domain.CampaignProviderMapping) (int32, error) {
+	if mapping == nil {
+		return 0, fmt.Errorf("campaign has no provider mapping")
+	}
+	if mapping.ProviderOfferID != nil {
+		if id, err := strconv.ParseInt(*mapping.ProviderOfferID, 10, 32); err == nil && id > 0 {
+			return int32(id), nil
+		}
+	}
+	if mapping.ProviderData != nil {
+		var payload struct {
+			Response struct {
+				NetworkOfferID int32 `json:"network_offer_id"`
+			} `json:"response"`
+		}
+		if err := json.Unmarshal([]byte(*mapping.ProviderData), &payload); err == nil && payload.Response.NetworkOfferID > 0 {
+			return payload.Response.NetworkOfferID, nil
+		}
+		var providerData domain.EverflowCampaignProviderData
+		if err := providerData.FromJSON(*mapping.ProviderData); err == nil && providerData.NetworkCampaignID != nil {
+			return *providerData.NetworkCampaignID, nil
+		}
+	}
+	return 0, fmt.Errorf("missing network_offer_id in campaign provider mapping")
+}
@@ -0,0 +1,82 @@
+package everflow
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreativeService_SyncCreative(t *testing.T) {
+	var created creativeRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-Eflow-API-Key"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/networks/uploads/temp":
+			file, header, err := r.FormFile("file")
+			require.NoError(t, err)
+			data, _ := io.ReadAll(file)
+			assert.Equal(t, "banner.png", header.Filename)
+			assert.Equal(t, []byte("png-bytes"), data)
+			w.Write([]byte(`{"temp_url": "https://uploads.example.com/tmp/abc"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/networks/creatives":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"network_offer_creative_id": 77}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/networks/creatives/77":
+			var updated creativeRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+			assert.Equal(t, "active", updated.CreativeStatus)
+			assert.Nil(t, updated.ResourceFile)
+			w.Write([]byte(`{"network_offer_creative_id": 77}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	service := NewCreativeService(CreativeConfig{BaseURL: mockServer.URL + "/v1", APIKey: "test-key"})
+	offerID := "20"
+	mapping := &domain.CampaignProviderMapping{ProviderOfferID: &offerID}
+	fileName := "banner.png"
+	width, height := 300, 250
+	creative := &domain.Creative{
+		CreativeID: 5, Name: "Banner", CreativeType: domain.CreativeTypeImage, Status: domain.CreativeStatusPending,
+		FileName: &fileName, Width: &width, Height: &height,
+	}
+
+	id, err := service.SyncCreative(context.Background(), creative, []byte("png-bytes"), mapping)
+	require.NoError(t, err)
+	assert.Equal(t, "77", id)
+	assert.Equal(t, int32(20), created.NetworkOfferID)
+	assert.Equal(t, "paused", created.CreativeStatus)
+	assert.Equal(t, int32(300), *created.Width)
+	require.NotNil(t, created.ResourceFile)
+	assert.Equal(t, "https://uploads.example.com/tmp/abc", created.ResourceFile.TempUrl)
+
+	creative.ProviderCreativeID = &id
+	creative.Status = domain.CreativeStatusApproved
+	id, err = service.SyncCreative(context.Background(), creative, nil, mapping)
+	require.NoError(t, err)
+	assert.Equal(t, "77", id)
+}
+
+func TestNetworkOfferIDFromMapping(t *testing.T) {
+	payload := `{"request": {}, "response": {"network_offer_id": 42}}`
+	id, err := networkOfferIDFromMapping(&domain.CampaignProviderMapping{ProviderData: &payload})
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), id)
+
+	_, err = networkOfferIDFromMapping(&domain.CampaignProviderMapping{})
+	assert.Error(t, err)
+	_, err = networkOfferIDFromMapping(nil)
+	assert.Error(t, err)
+}
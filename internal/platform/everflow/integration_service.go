@@ -15,6 +15,7 @@ import (
 	"github.com/affiliate-backend/internal/platform/everflow/tracking"
 	"github.com/affiliate-backend/internal/platform/logger"
 	"github.com/affiliate-backend/internal/platform/provider"
+	"github.com/affiliate-backend/internal/platform/qrcode"
 	"github.com/google/uuid"
 )
 
@@ -1135,10 +1136,8 @@ func (s *IntegrationService) mapCampaignToEverflowRequest(camp *domain.Campaign,
 	// Set default category ID
 	req.SetNetworkCategoryId(1)
 
-	// Create empty ruleset with default timezone
-	ruleset := offer.NewRuleset()
-	ruleset.SetDayPartingTimezoneId(58) // Default timezone from example
-	req.SetRuleset(*ruleset)
+	// Map the campaign targeting ruleset, empty when the campaign has none
+	req.SetRuleset(*mapTargetingToEverflowRuleset(camp.Targeting))
 
 	// Set attribution methods
 	req.SetEmailAttributionMethod("first_affiliate_attribution")
@@ -1438,6 +1437,9 @@ func (s *IntegrationService) mapCampaignToEverflowUpdateRequest(camp *domain.Cam
 	updateRequest.SetSessionDefinition("ip_user_agent")
 	updateRequest.SetSessionDuration(24)
 
+	// Replace the offer ruleset, clearing it when the campaign has no targeting
+	updateRequest.SetRuleset(*mapTargetingToEverflowRuleset(camp.Targeting))
+
 	// Set hardcoded required fields
 	updateRequest.SetNetworkTrackingDomainId(12977)  // Added: hardcoded as 12977
 	updateRequest.SetNetworkCategoryId(1)            // Added: hardcoded as 1
@@ -1730,13 +1732,18 @@ func (s *IntegrationService) CreateTrackingLink(ctx context.Context, trackingLin
 	return mapping, nil
 }
 
-// GenerateTrackingLinkQR generates a QR code for a tracking link via Everflow API
+// GenerateTrackingLinkQR generates a tracking link via Everflow and renders it as a PNG QR code
 func (s *IntegrationService) GenerateTrackingLinkQR(ctx context.Context, req *domain.TrackingLinkGenerationRequest, campaignMapping *domain.CampaignProviderMapping, affiliateMapping *domain.AffiliateProviderMapping) ([]byte, error) {
-	// For now, simulate the Everflow QR API call since we don't have the tracking API client generated
-	// In a real implementation, this would make an HTTP POST to /v1/networks/tracking/offers/clicks/qr
+	generated, err := s.GenerateTrackingLink(ctx, req, campaignMapping, affiliateMapping)
+	if err != nil {
+		return nil, err
+	}
 
-	// Return a mock QR code (in real implementation, this would be a PNG image from Everflow)
-	return []byte("everflow-qr-code-png-data"), nil
+	code, err := qrcode.Encode(generated.GeneratedURL, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tracking link QR code: %w", err)
+	}
+	return code.RenderPNG(qrcode.RenderOptions{Size: domain.DefaultQRCodeSize, Margin: qrcode.DefaultMargin})
 }
 
 
@@ -0,0 +1,207 @@
+package everflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/everflow/advertiser"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/provider"
+	"github.com/shopspring/decimal"
+)
+
+// entityReportColumns are the dimensions requested from the Everflow entity report
+var entityReportColumns = []string{"hour", "offer", "affiliate", "sub1", "sub2", "sub3", "sub4", "sub5", "country", "device_type"}
+
+// TimezoneIDUTC is the Everflow timezone ID of UTC (see GET /meta/timezones)
+const TimezoneIDUTC = 67
+
+// ReportingConfig holds the configuration for the Everflow reporting service
+type ReportingConfig struct {
+	BaseURL    string
+	APIKey     string
+	TimezoneID int    // Everflow timezone ID; defaults to UTC since stored hours are shifted into report timezones
+	CurrencyID string // Reporting currency; defaults to USD
+}
+
+// ReportingService pulls entity-level reports from the Everflow reporting endpoints
+type ReportingService struct {
+	config     ReportingConfig
+	httpClient *http.Client
+}
+
+// Ensure ReportingService implements provider.ReportingService
+var _ provider.ReportingService = (*ReportingService)(nil)
+
+// NewReportingService creates a new Everflow reporting service
+func NewReportingService(config ReportingConfig) *ReportingService {
+	if config.CurrencyID == "" {
+		config.CurrencyID = "USD"
+	}
+	if config.TimezoneID == 0 {
+		config.TimezoneID = TimezoneIDUTC
+	}
+	return &ReportingService{
+		config:     config,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// entityReportColumn is a single requested report dimension
+type entityReportColumn struct {
+	Column string `json:"column"`
+}
+
+// entityReportRequest is the body of POST /networks/reporting/entity
+type entityReportRequest struct {
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	TimezoneID int                  `json:"timezone_id,omitempty"`
+	CurrencyID string               `json:"currency_id"`
+	Columns    []entityReportColumn `json:"columns"`
+	Query      struct {
+		Filters []interface{} `json:"filters"`
+	} `json:"query"`
+}
+
+// entityReportRowColumn is a dimension value in a report row
+type entityReportRowColumn struct {
+	ColumnType string `json:"column_type"`
+	ID         string `json:"id"`
+	Label      string `json:"label"`
+}
+
+// entityReportRow is a single row of the entity report table
+type entityReportRow struct {
+	Columns   []entityReportRowColumn  `json:"columns"`
+	Reporting advertiser.ReportingData `json:"reporting"`
+}
+
+// entityReportResponse is the response of POST /networks/reporting/entity
+type entityReportResponse struct {
+	Table []entityReportRow `json:"table"`
+}
+
+// ProviderType returns the provider identifier for Everflow
+func (s *ReportingService) ProviderType() string {
+	return "everflow"
+}
+
+// FetchDailyStats fetches the entity report for a single day grouped by hour, offer, affiliate, sub IDs, country and device
+func (s *ReportingService) FetchDailyStats(ctx context.Context, date time.Time) ([]domain.ProviderDailyStat, error) {
+	day := date.Format("2006-01-02")
+
+	reqBody := entityReportRequest{
+		From:       day,
+		To:         day,
+		TimezoneID: s.config.TimezoneID,
+		CurrencyID: s.config.CurrencyID,
+	}
+	for _, column := range entityReportColumns {
+		reqBody.Columns = append(reqBody.Columns, entityReportColumn{Column: column})
+	}
+	reqBody.Query.Filters = []interface{}{}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entity report request: %w", err)
+	}
+
+	url := strings.TrimSuffix(s.config.BaseURL, "/") + "/networks/reporting/entity"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build entity report request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Eflow-API-Key", s.config.APIKey)
+
+	logger.Debug("Fetching Everflow entity report", "date", day)
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Everflow reporting API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Everflow reporting response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Everflow reporting API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var report entityReportResponse
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode Everflow reporting response: %w", err)
+	}
+
+	statDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	stats := make([]domain.ProviderDailyStat, 0, len(report.Table))
+	for _, row := range report.Table {
+		stat := mapEntityReportRow(row)
+		if stat.ProviderOfferID == "" || stat.ProviderAffiliateID == "" {
+			continue // Rows without offer/affiliate cannot be attributed
+		}
+		stat.ProviderType = s.ProviderType()
+		stat.StatDate = statDate
+		stat.Currency = s.config.CurrencyID
+		stats = append(stats, stat)
+	}
+
+	logger.Info("Fetched Everflow entity report", "date", day, "rows", len(stats))
+	return stats, nil
+}
+
+// mapEntityReportRow maps an Everflow report row to a provider daily stat
+func mapEntityReportRow(row entityReportRow) domain.ProviderDailyStat {
+	var stat domain.ProviderDailyStat
+	for _, column := range row.Columns {
+		switch column.ColumnType {
+		case "offer":
+			stat.ProviderOfferID = column.ID
+		case "affiliate":
+			stat.ProviderAffiliateID = column.ID
+		case "sub1":
+			stat.Sub1 = column.ID
+		case "sub2":
+			stat.Sub2 = column.ID
+		case "sub3":
+			stat.Sub3 = column.ID
+		case "sub4":
+			stat.Sub4 = column.ID
+		case "sub5":
+			stat.Sub5 = column.ID
+		case "hour":
+			if hour, err := strconv.Atoi(column.ID); err == nil && hour >= 0 && hour < 24 {
+				stat.StatHour = int16(hour)
+			}
+		case "country":
+			// Everflow reports countries by numeric ID with the ISO code as label
+			if len(column.Label) == 2 {
+				stat.CountryCode = strings.ToUpper(column.Label)
+			} else if len(column.ID) == 2 {
+				stat.CountryCode = strings.ToUpper(column.ID)
+			}
+		case "device_type":
+			stat.DeviceType = strings.ToLower(column.Label)
+		}
+	}
+
+	reporting := row.Reporting
+	stat.Impressions = int64(reporting.GetImp())
+	stat.Clicks = int64(reporting.GetTotalClick())
+	stat.UniqueClicks = int64(reporting.GetUniqueClick())
+	stat.Conversions = int64(reporting.GetCv())
+	stat.Revenue = decimal.NewFromFloat32(reporting.GetRevenue()).Round(4)
+	stat.Payout = decimal.NewFromFloat32(reporting.GetPayout()).Round(4)
+	return stat
+}
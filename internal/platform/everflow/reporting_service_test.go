@@ -0,0 +1,96 @@
+package everflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportingService_FetchDailyStats(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/v1/networks/reporting/entity", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("X-Eflow-API-Key"))
+
+		var body entityReportRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "2024-03-15", body.From)
+		assert.Equal(t, "2024-03-15", body.To)
+		assert.Equal(t, "USD", body.CurrencyID)
+		assert.Equal(t, TimezoneIDUTC, body.TimezoneID)
+		assert.Len(t, body.Columns, len(entityReportColumns))
+
+		response := `{
+			"table": [
+				{
+					"columns": [
+						{"column_type": "offer", "id": "20", "label": "Offer A"},
+						{"column_type": "affiliate", "id": "8", "label": "Affiliate B"},
+						{"column_type": "sub1", "id": "facebook", "label": "facebook"},
+						{"column_type": "hour", "id": "14", "label": "14"},
+						{"column_type": "country", "id": "227", "label": "us"},
+						{"column_type": "device_type", "id": "2", "label": "Mobile"}
+					],
+					"reporting": {"imp": 500, "total_click": 120, "unique_click": 100, "cv": 6, "revenue": 60.5, "payout": 30.25}
+				},
+				{
+					"columns": [
+						{"column_type": "sub1", "id": "orphan", "label": "orphan"}
+					],
+					"reporting": {"total_click": 3}
+				}
+			]
+		}`
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(response))
+	}))
+	defer mockServer.Close()
+
+	service := NewReportingService(ReportingConfig{
+		BaseURL: mockServer.URL + "/v1",
+		APIKey:  "test-key",
+	})
+
+	stats, err := service.FetchDailyStats(context.Background(), time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, stats, 1, "rows without offer and affiliate should be skipped")
+
+	stat := stats[0]
+	assert.Equal(t, "everflow", stat.ProviderType)
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), stat.StatDate)
+	assert.Equal(t, "20", stat.ProviderOfferID)
+	assert.Equal(t, "8", stat.ProviderAffiliateID)
+	assert.Equal(t, "facebook", stat.Sub1)
+	assert.Equal(t, "", stat.Sub2)
+	assert.Equal(t, int16(14), stat.StatHour)
+	assert.Equal(t, "US", stat.CountryCode)
+	assert.Equal(t, "mobile", stat.DeviceType)
+	assert.Equal(t, int64(500), stat.Impressions)
+	assert.Equal(t, int64(120), stat.Clicks)
+	assert.Equal(t, int64(100), stat.UniqueClicks)
+	assert.Equal(t, int64(6), stat.Conversions)
+	assert.Equal(t, "60.5", stat.Revenue.String())
+	assert.Equal(t, "30.25", stat.Payout.String())
+	assert.Equal(t, "USD", stat.Currency)
+}
+
+func TestReportingService_FetchDailyStatsError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid api key"}`))
+	}))
+	defer mockServer.Close()
+
+	service := NewReportingService(ReportingConfig{BaseURL: mockServer.URL + "/v1"})
+
+	_, err := service.FetchDailyStats(context.Background(), time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+}
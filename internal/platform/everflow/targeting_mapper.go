@@ -0,0 +1,146 @@
+package everflow
+
+import (
+	"net"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/everflow/offer"
+)
+
+// defaultDayPartingTimezoneID is the Everflow timezone offers are created with
+const defaultDayPartingTimezoneID = 58
+
+// Everflow targeting types of ruleset items
+const (
+	targetingInclude = "include"
+	targetingExclude = "exclude"
+)
+
+var everflowWeekdays = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// mapTargetingToEverflowRuleset maps a campaign targeting ruleset to an offer ruleset. A nil
+// ruleset maps to an empty one so that removed targeting is also removed on Everflow.
+//
+// Everflow identifies timezones by network IDs that have no IANA equivalent here, so day parts
+// are sent against the default offer timezone; the local redirect evaluates them in the ruleset
+// timezone.
+func mapTargetingToEverflowRuleset(rules *domain.CampaignTargetingRules) *offer.Ruleset {
+	ruleset := offer.NewRuleset()
+	ruleset.SetDayPartingTimezoneId(defaultDayPartingTimezoneID)
+	if rules == nil {
+		return ruleset
+	}
+
+	var countries []map[string]interface{}
+	for _, code := range rules.Countries {
+		countries = append(countries, map[string]interface{}{"country_code": code, "targeting_type": targetingInclude})
+	}
+	for _, code := range rules.ExcludedCountries {
+		countries = append(countries, map[string]interface{}{"country_code": code, "targeting_type": targetingExclude})
+	}
+	if len(countries) > 0 {
+		ruleset.SetCountries(countries)
+	}
+	if items := includeItems("region_code", rules.Regions); items != nil {
+		ruleset.SetRegions(items)
+	}
+	if items := includeItems("device_type", rules.DeviceTypes); items != nil {
+		ruleset.SetDeviceTypes(items)
+	}
+	if items := includeItems("browser", rules.Browsers); items != nil {
+		ruleset.SetBrowsers(items)
+	}
+	if items := includeItems("platform", rules.OperatingSystems); items != nil {
+		ruleset.SetPlatforms(items)
+	}
+	if items := includeItems("isp", rules.ISPs); items != nil {
+		ruleset.SetIsps(items)
+	}
+
+	var ips []map[string]interface{}
+	for _, cidr := range rules.AllowedIPs {
+		if item := ipRangeItem(cidr, targetingInclude); item != nil {
+			ips = append(ips, item)
+		}
+	}
+	for _, cidr := range rules.BlockedIPs {
+		if item := ipRangeItem(cidr, targetingExclude); item != nil {
+			ips = append(ips, item)
+		}
+	}
+	if len(ips) > 0 {
+		ruleset.SetIps(ips)
+	}
+
+	ruleset.SetIsBlockProxy(rules.BlockProxy)
+
+	if len(rules.DayParts) > 0 {
+		ruleset.SetIsUseDayParting(true)
+		ruleset.SetDayPartingApplyTo("selected_timezone")
+		ruleset.SetDaysParting(dayPartingItems(rules.DayParts))
+	}
+	return ruleset
+}
+
+func includeItems(key string, values []string) []map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	items := make([]map[string]interface{}, 0, len(values))
+	for _, value := range values {
+		items = append(items, map[string]interface{}{key: value, "targeting_type": targetingInclude})
+	}
+	return items
+}
+
+// ipRangeItem maps a CIDR range to the first and last address Everflow expects
+func ipRangeItem(cidr, targetingType string) map[string]interface{} {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil
+	}
+	last := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		last[i] = network.IP[i] | ^network.Mask[i]
+	}
+	return map[string]interface{}{
+		"ip_from":        network.IP.String(),
+		"ip_to":          last.String(),
+		"targeting_type": targetingType,
+	}
+}
+
+// dayPartingItems expands day parts to one item per weekday and splits windows that wrap past
+// midnight into an evening item on the start day and a morning item on the next day
+func dayPartingItems(dayParts []domain.SmartLinkDayPart) []map[string]interface{} {
+	var items []map[string]interface{}
+	add := func(day, startHour, endHour int) {
+		items = append(items, map[string]interface{}{
+			"day_of_week":  day,
+			"start_hour":   startHour,
+			"start_minute": 0,
+			"end_hour":     endHour,
+			"end_minute":   0,
+		})
+	}
+	for _, part := range dayParts {
+		days := make([]int, 0, 7)
+		for _, name := range part.Days {
+			days = append(days, everflowWeekdays[name])
+		}
+		if len(days) == 0 {
+			days = []int{0, 1, 2, 3, 4, 5, 6}
+		}
+		for _, day := range days {
+			if part.StartHour < part.EndHour {
+				add(day, part.StartHour, part.EndHour)
+				continue
+			}
+			add(day, part.StartHour, 24)
+			if part.EndHour > 0 {
+				add((day+1)%7, 0, part.EndHour)
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package everflow
+
+import (
+	"testing"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapTargetingToEverflowRuleset(t *testing.T) {
+	t.Run("nil targeting maps to an empty ruleset", func(t *testing.T) {
+		ruleset := mapTargetingToEverflowRuleset(nil)
+		assert.Equal(t, int32(defaultDayPartingTimezoneID), ruleset.GetDayPartingTimezoneId())
+		assert.Empty(t, ruleset.Countries)
+		assert.False(t, ruleset.GetIsUseDayParting())
+	})
+
+	t.Run("maps rules", func(t *testing.T) {
+		ruleset := mapTargetingToEverflowRuleset(&domain.CampaignTargetingRules{
+			Countries:         []string{"US"},
+			ExcludedCountries: []string{"FR"},
+			DeviceTypes:       []string{domain.DeviceTypeMobile},
+			AllowedIPs:        []string{"10.1.0.0/16"},
+			BlockedIPs:        []string{"10.1.2.3/32"},
+			BlockProxy:        true,
+			DayParts:          []domain.SmartLinkDayPart{{Days: []string{"sat"}, StartHour: 22, EndHour: 2}},
+		})
+
+		assert.Equal(t, []map[string]interface{}{
+			{"country_code": "US", "targeting_type": "include"},
+			{"country_code": "FR", "targeting_type": "exclude"},
+		}, ruleset.Countries)
+		assert.Equal(t, []map[string]interface{}{{"device_type": "mobile", "targeting_type": "include"}}, ruleset.DeviceTypes)
+		assert.Equal(t, []map[string]interface{}{
+			{"ip_from": "10.1.0.0", "ip_to": "10.1.255.255", "targeting_type": "include"},
+			{"ip_from": "10.1.2.3", "ip_to": "10.1.2.3", "targeting_type": "exclude"},
+		}, ruleset.Ips)
+		assert.True(t, ruleset.GetIsBlockProxy())
+		assert.True(t, ruleset.GetIsUseDayParting())
+		assert.Equal(t, "selected_timezone", ruleset.GetDayPartingApplyTo())
+
+		// Saturday 22:00-02:00 wraps into Sunday
+		assert.Equal(t, []map[string]interface{}{
+			{"day_of_week": 6, "start_hour": 22, "start_minute": 0, "end_hour": 24, "end_minute": 0},
+			{"day_of_week": 0, "start_hour": 0, "start_minute": 0, "end_hour": 2, "end_minute": 0},
+		}, ruleset.DaysParting)
+	})
+}
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Supported export formats
+const (
+	FormatCSV   = "csv"
+	FormatXLSX  = "xlsx"
+	FormatJSONL = "jsonl"
+)
+
+// Table is a rectangular data set with a header row. Cell values may be strings,
+// integers, floats, decimals or nil.
+type Table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// File is a rendered export
+type File struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// Render renders the table in the given format
+func Render(format string, table *Table) (*File, error) {
+	switch format {
+	case FormatCSV:
+		data, err := renderCSV(table)
+		return &File{Data: data, ContentType: "text/csv", Extension: "csv"}, err
+	case FormatJSONL:
+		data, err := renderJSONL(table)
+		return &File{Data: data, ContentType: "application/x-ndjson", Extension: "jsonl"}, err
+	case FormatXLSX:
+		data, err := renderXLSX(table)
+		return &File{Data: data, ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx"}, err
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func renderCSV(table *Table) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(table.Columns); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	record := make([]string, len(table.Columns))
+	for _, row := range table.Rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = formatCell(row[i])
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func renderJSONL(table *Table) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, row := range table.Rows {
+		object := make(map[string]interface{}, len(table.Columns))
+		for i, column := range table.Columns {
+			var value interface{}
+			if i < len(row) {
+				value = row[i]
+			}
+			if d, ok := value.(decimal.Decimal); ok {
+				// Emit decimals as JSON numbers rather than strings
+				value = json.Number(d.String())
+			}
+			object[column] = value
+		}
+		if err := enc.Encode(object); err != nil {
+			return nil, fmt.Errorf("failed to write JSON line: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// formatCell renders a cell value as text. Text that a spreadsheet would evaluate as a formula is
+// prefixed with a quote so that user-entered values stay inert.
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case decimal.Decimal:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return escapeFormula(fmt.Sprint(v))
+	}
+}
+
+// escapeFormula prefixes text starting with a formula trigger character with a single quote
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// renderXLSX writes a minimal single-sheet Office Open XML workbook with inline strings
+func renderXLSX(table *Table) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Report" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/worksheets/sheet1.xml", buildSheetXML(table)},
+	}
+
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create XLSX part %s: %w", part.name, err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write XLSX part %s: %w", part.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish XLSX file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// buildSheetXML renders the worksheet; numbers become numeric cells, everything else inline strings
+func buildSheetXML(table *Table) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	writeRow := func(rowNum int, values []interface{}) {
+		fmt.Fprintf(&buf, `<row r="%d">`, rowNum)
+		for i, value := range values {
+			ref := columnName(i) + strconv.Itoa(rowNum)
+			switch v := value.(type) {
+			case nil:
+				continue
+			case int, int64, float64, decimal.Decimal:
+				fmt.Fprintf(&buf, `<c r="%s"><v>%s</v></c>`, ref, formatCell(v))
+			default:
+				fmt.Fprintf(&buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				xml.EscapeText(&buf, []byte(formatCell(v)))
+				buf.WriteString(`</t></is></c>`)
+			}
+		}
+		buf.WriteString(`</row>`)
+	}
+
+	header := make([]interface{}, len(table.Columns))
+	for i, column := range table.Columns {
+		header[i] = column
+	}
+	writeRow(1, header)
+	for i, row := range table.Rows {
+		writeRow(i+2, row)
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.String()
+}
+
+// columnName converts a zero-based column index to a spreadsheet column name (A, B, ..., Z, AA, ...)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
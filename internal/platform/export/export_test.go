@@ -0,0 +1,99 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTable() *Table {
+	return &Table{
+		Columns: []string{"date", "campaign", "clicks", "revenue"},
+		Rows: [][]interface{}{
+			{"2024-03-15", "Spring <Sale>", int64(120), decimal.RequireFromString("60.50")},
+			{"2024-03-16", nil, int64(0), decimal.Zero},
+		},
+	}
+}
+
+func TestRender_CSV(t *testing.T) {
+	file, err := Render(FormatCSV, testTable())
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", file.ContentType)
+	assert.Equal(t, "date,campaign,clicks,revenue\n2024-03-15,Spring <Sale>,120,60.5\n2024-03-16,,0,0\n", string(file.Data))
+}
+
+func TestRender_EscapesFormulas(t *testing.T) {
+	table := &Table{
+		Columns: []string{"notes", "sub1", "clicks", "payout"},
+		Rows: [][]interface{}{
+			{"=HYPERLINK(\"http://evil\")", "@SUM(A1)", int64(-3), decimal.RequireFromString("-1.5")},
+			{"+1", "-cmd", int64(4), decimal.Zero},
+		},
+	}
+
+	file, err := Render(FormatCSV, table)
+	require.NoError(t, err)
+	assert.Equal(t, "notes,sub1,clicks,payout\n\"'=HYPERLINK(\"\"http://evil\"\")\",'@SUM(A1),-3,-1.5\n'+1,'-cmd,4,0\n", string(file.Data))
+
+	sheet := buildSheetXML(table)
+	assert.Contains(t, sheet, `<t xml:space="preserve">&#39;=HYPERLINK(&#34;http://evil&#34;)</t>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">&#39;-cmd</t>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>-3</v></c>`)
+}
+
+func TestRender_JSONL(t *testing.T) {
+	file, err := Render(FormatJSONL, testTable())
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(file.Data)), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"date":"2024-03-15","campaign":"Spring <Sale>","clicks":120,"revenue":60.5}`, lines[0])
+	assert.JSONEq(t, `{"date":"2024-03-16","campaign":null,"clicks":0,"revenue":0}`, lines[1])
+}
+
+func TestRender_XLSX(t *testing.T) {
+	file, err := Render(FormatXLSX, testTable())
+	require.NoError(t, err)
+	assert.Equal(t, "xlsx", file.Extension)
+
+	zr, err := zip.NewReader(bytes.NewReader(file.Data), int64(len(file.Data)))
+	require.NoError(t, err)
+
+	var sheet string
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(data)
+		}
+	}
+	assert.Contains(t, names, "[Content_Types].xml")
+	assert.Contains(t, names, "xl/workbook.xml")
+	assert.Contains(t, sheet, `<c r="C2"><v>120</v></c>`)
+	assert.Contains(t, sheet, `<c r="D2"><v>60.5</v></c>`)
+	assert.Contains(t, sheet, `Spring &lt;Sale&gt;`)
+}
+
+func TestRender_UnsupportedFormat(t *testing.T) {
+	_, err := Render("pdf", testTable())
+	assert.Error(t, err)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
@@ -0,0 +1,437 @@
+// Package geoip reads MaxMind DB files (GeoIP2 and GeoLite2 City, Country, ISP, ASN and
+// Anonymous IP databases) to locate visitors. Only reading is supported: the file is loaded in
+// memory and looked up without further allocation besides the decoded record.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+
+	"github.com/affiliate-backend/internal/domain"
+)
+
+// metadataMarker precedes the metadata map at the end of the file
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSectionSeparator is the size of the zero bytes between the search tree and the data section
+const dataSectionSeparator = 16
+
+// ErrInvalidDatabase is returned for files that are not valid MaxMind DB files
+var ErrInvalidDatabase = errors.New("geoip: invalid MaxMind DB file")
+
+// Data types of the MaxMind DB format
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBoolean   = 14
+	typeFloat     = 15
+)
+
+// Metadata describes a database
+type Metadata struct {
+	DatabaseType string
+	IPVersion    int
+	RecordSize   int
+	NodeCount    int
+}
+
+// Reader looks up IP addresses in a MaxMind DB file
+type Reader struct {
+	buffer      []byte
+	data        []byte
+	metadata    Metadata
+	ipv4Start   int
+	ipv4Depth   int
+	nodeByteLen int
+}
+
+// Open loads a MaxMind DB file
+func Open(path string) (*Reader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+	return FromBytes(buffer)
+}
+
+// FromBytes reads a MaxMind DB held in memory
+func FromBytes(buffer []byte) (*Reader, error) {
+	markerAt := bytes.LastIndex(buffer, metadataMarker)
+	if markerAt < 0 {
+		return nil, fmt.Errorf("%w: metadata marker not found", ErrInvalidDatabase)
+	}
+	metadataStart := markerAt + len(metadataMarker)
+	decoded, _, err := (&decoder{buffer: buffer[metadataStart:]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	fields, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is not a map", ErrInvalidDatabase)
+	}
+
+	metadata := Metadata{
+		DatabaseType: stringValue(fields["database_type"]),
+		IPVersion:    int(uintValue(fields["ip_version"])),
+		RecordSize:   int(uintValue(fields["record_size"])),
+		NodeCount:    int(uintValue(fields["node_count"])),
+	}
+	if metadata.RecordSize != 24 && metadata.RecordSize != 28 && metadata.RecordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, metadata.RecordSize)
+	}
+	if metadata.IPVersion != 4 && metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", ErrInvalidDatabase, metadata.IPVersion)
+	}
+
+	nodeByteLen := metadata.RecordSize / 4
+	treeSize := metadata.NodeCount * nodeByteLen
+	if metadata.NodeCount <= 0 || treeSize+dataSectionSeparator > markerAt {
+		return nil, fmt.Errorf("%w: search tree exceeds the file", ErrInvalidDatabase)
+	}
+
+	r := &Reader{
+		buffer:      buffer,
+		data:        buffer[treeSize+dataSectionSeparator : markerAt],
+		metadata:    metadata,
+		nodeByteLen: nodeByteLen,
+	}
+
+	// IPv4 addresses live under ::/96 of IPv6 trees
+	if metadata.IPVersion == 6 {
+		node := 0
+		for i := 0; i < 96 && node < metadata.NodeCount; i++ {
+			node = r.record(node, 0)
+		}
+		r.ipv4Start, r.ipv4Depth = node, 96
+	}
+	return r, nil
+}
+
+// Metadata returns the metadata of the database
+func (r *Reader) Metadata() Metadata {
+	return r.metadata
+}
+
+// record returns the left (bit 0) or right (bit 1) record of a node
+func (r *Reader) record(node, bit int) int {
+	b := r.buffer[node*r.nodeByteLen : (node+1)*r.nodeByteLen]
+	switch r.metadata.RecordSize {
+	case 24:
+		if bit == 0 {
+			return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+		return int(b[3])<<16 | int(b[4])<<8 | int(b[5])
+	case 28:
+		if bit == 0 {
+			return int(b[3]&0xf0)<<20 | int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+		return int(b[3]&0x0f)<<24 | int(b[4])<<16 | int(b[5])<<8 | int(b[6])
+	default:
+		if bit == 0 {
+			return int(binary.BigEndian.Uint32(b[0:4]))
+		}
+		return int(binary.BigEndian.Uint32(b[4:8]))
+	}
+}
+
+// LookupRecord returns the decoded record of an IP address, or nil when the database has none
+func (r *Reader) LookupRecord(ip net.IP) (map[string]interface{}, error) {
+	address := ip.To4()
+	node, bitCount := 0, 32
+	if address != nil {
+		if r.metadata.IPVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else {
+		if address = ip.To16(); address == nil {
+			return nil, fmt.Errorf("geoip: invalid IP address %v", ip)
+		}
+		if r.metadata.IPVersion == 4 {
+			return nil, nil
+		}
+		bitCount = 128
+	}
+
+	nodeCount := r.metadata.NodeCount
+	for i := 0; i < bitCount && node < nodeCount; i++ {
+		bit := int(address[i>>3]>>(7-uint(i&7))) & 1
+		node = r.record(node, bit)
+	}
+	if node == nodeCount {
+		return nil, nil
+	}
+	if node < nodeCount {
+		return nil, fmt.Errorf("%w: search tree deeper than the address", ErrInvalidDatabase)
+	}
+
+	offset := node - nodeCount - dataSectionSeparator
+	if offset < 0 || offset >= len(r.data) {
+		return nil, fmt.Errorf("%w: record pointer outside the data section", ErrInvalidDatabase)
+	}
+	value, _, err := (&decoder{buffer: r.data}).decode(offset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	record, _ := value.(map[string]interface{})
+	return record, nil
+}
+
+// Lookup returns what the database knows about an IP address. City and Country databases
+// provide the country and region, ISP and ASN databases the ISP, and Anonymous IP databases
+// as well as the traits of City databases whether the address is a proxy.
+func (r *Reader) Lookup(ip net.IP) (domain.GeoLocation, error) {
+	record, err := r.LookupRecord(ip)
+	if err != nil || record == nil {
+		return domain.GeoLocation{}, err
+	}
+
+	var location domain.GeoLocation
+	location.Country = stringValue(path(record, "country", "iso_code"))
+	if location.Country == "" {
+		location.Country = stringValue(path(record, "registered_country", "iso_code"))
+	}
+	if subdivisions, ok := record["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 && location.Country != "" {
+		if code := stringValue(path(subdivisions[0], "iso_code")); code != "" {
+			location.Region = location.Country + "-" + code
+		}
+	}
+	for _, isp := range []interface{}{record["isp"], path(record, "traits", "isp"), record["autonomous_system_organization"]} {
+		if location.ISP = stringValue(isp); location.ISP != "" {
+			break
+		}
+	}
+	for _, flag := range []interface{}{
+		record["is_anonymous"], record["is_public_proxy"], record["is_anonymous_vpn"],
+		path(record, "traits", "is_anonymous"), path(record, "traits", "is_anonymous_proxy"),
+	} {
+		if proxy, ok := flag.(bool); ok && proxy {
+			location.IsProxy = true
+			break
+		}
+	}
+	return location, nil
+}
+
+// path follows map keys through a decoded record
+func path(value interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func uintValue(value interface{}) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case int32:
+		if v > 0 {
+			return uint64(v)
+		}
+	}
+	return 0
+}
+
+// decoder decodes values of the data section
+type decoder struct {
+	buffer []byte
+	depth  int
+}
+
+// maxDecodeDepth guards against pointer and container cycles in corrupt files
+const maxDecodeDepth = 64
+
+// decode decodes the value at offset and returns it with the offset following it
+func (d *decoder) decode(offset int) (interface{}, int, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDecodeDepth {
+		return nil, 0, errors.New("data nested too deeply")
+	}
+
+	dataType, size, offset, err := d.controlByte(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dataType == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(target)
+		return value, next, err
+	}
+
+	switch dataType {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			key, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			value, next, err := d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[keyString] = value
+			offset = next
+		}
+		return m, offset, nil
+	case typeArray:
+		array := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			value, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			array = append(array, value)
+			offset = next
+		}
+		return array, offset, nil
+	case typeBoolean:
+		return size != 0, offset, nil
+	case typeContainer, typeEndMarker:
+		return nil, 0, fmt.Errorf("unexpected data type %d", dataType)
+	}
+
+	if offset+size > len(d.buffer) {
+		return nil, 0, errors.New("value exceeds the data section")
+	}
+	raw := d.buffer[offset : offset+size]
+	next := offset + size
+	switch dataType {
+	case typeString:
+		return string(raw), next, nil
+	case typeBytes:
+		return append([]byte(nil), raw...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("double must be 8 bytes")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("float must be 4 bytes")
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(raw)), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, errors.New("unsigned integer too long")
+		}
+		var v uint64
+		for _, b := range raw {
+			v = v<<8 | uint64(b)
+		}
+		return v, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errors.New("int32 too long")
+		}
+		var v uint32
+		for _, b := range raw {
+			v = v<<8 | uint32(b)
+		}
+		// Shorter values are padded with zeros, so only 4-byte values can be negative
+		return int32(v), next, nil
+	case typeUint128:
+		return new(big.Int).SetBytes(raw), next, nil
+	}
+	return nil, 0, fmt.Errorf("unknown data type %d", dataType)
+}
+
+// controlByte reads the type and size of the value at offset and returns the offset of its payload
+func (d *decoder) controlByte(offset int) (int, int, int, error) {
+	if offset >= len(d.buffer) {
+		return 0, 0, 0, errors.New("offset outside the data section")
+	}
+	control := d.buffer[offset]
+	offset++
+	dataType := int(control >> 5)
+	if dataType == typeExtended {
+		if offset >= len(d.buffer) {
+			return 0, 0, 0, errors.New("truncated extended type")
+		}
+		dataType = 7 + int(d.buffer[offset])
+		offset++
+	}
+
+	size := int(control & 0x1f)
+	if dataType == typePointer {
+		return dataType, size, offset, nil
+	}
+	if size >= 29 {
+		extra := size - 28
+		if offset+extra > len(d.buffer) {
+			return 0, 0, 0, errors.New("truncated size")
+		}
+		n := 0
+		for _, b := range d.buffer[offset : offset+extra] {
+			n = n<<8 | int(b)
+		}
+		switch size {
+		case 29:
+			size = 29 + n
+		case 30:
+			size = 285 + n
+		default:
+			size = 65821 + n
+		}
+		offset += extra
+	}
+	return dataType, size, offset, nil
+}
+
+// pointer resolves a pointer whose size bits are sizeBits and whose extra bytes start at offset
+func (d *decoder) pointer(sizeBits, offset int) (int, int, error) {
+	length := (sizeBits>>3)&0x3 + 1
+	if offset+length > len(d.buffer) {
+		return 0, 0, errors.New("truncated pointer")
+	}
+	n := 0
+	for _, b := range d.buffer[offset : offset+length] {
+		n = n<<8 | int(b)
+	}
+	value := sizeBits & 0x7
+	switch length {
+	case 1:
+		n = value<<8 | n
+	case 2:
+		n = (value<<16 | n) + 2048
+	case 3:
+		n = (value<<24 | n) + 526336
+	}
+	return n, offset + length, nil
+}
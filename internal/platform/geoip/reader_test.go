@@ -0,0 +1,146 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Minimal MaxMind DB encoder for test databases
+
+// encodeControl encodes the control byte of a value; sizes of 29 and more take one extra byte
+func encodeControl(dataType, size int) []byte {
+	var extra []byte
+	if size >= 29 {
+		extra = []byte{byte(size - 29)}
+		size = 29
+	}
+	out := []byte{byte(dataType<<5 | size)}
+	if dataType > 7 {
+		out = []byte{byte(size), byte(dataType - 7)}
+	}
+	return append(out, extra...)
+}
+
+func encodeString(s string) []byte {
+	return append(encodeControl(typeString, len(s)), s...)
+}
+
+func encodeUint(dataType int, v uint64, length int) []byte {
+	out := encodeControl(dataType, length)
+	for i := length - 1; i >= 0; i-- {
+		out = append(out, byte(v>>(8*uint(i))))
+	}
+	return out
+}
+
+func encodeBool(v bool) []byte {
+	if v {
+		return encodeControl(typeBoolean, 1)
+	}
+	return encodeControl(typeBoolean, 0)
+}
+
+func encodeMap(pairs ...[]byte) []byte {
+	out := encodeControl(typeMap, len(pairs)/2)
+	for _, p := range pairs {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func encodeArray(values ...[]byte) []byte {
+	out := encodeControl(typeArray, len(values))
+	for _, v := range values {
+		out = append(out, v...)
+	}
+	return out
+}
+
+// encodePointer encodes a pointer with one extra byte (offsets below 2048)
+func encodePointer(offset int) []byte {
+	return []byte{byte(typePointer<<5 | (offset>>8)&0x7), byte(offset)}
+}
+
+// buildDatabase builds an IPv4 database with 24-bit records holding record for prefix/prefixLen
+func buildDatabase(t *testing.T, prefix net.IP, prefixLen int, data []byte, recordOffset int) []byte {
+	t.Helper()
+	nodeCount := prefixLen
+	empty := nodeCount
+	dataPointer := nodeCount + dataSectionSeparator + recordOffset
+
+	var tree []byte
+	ip := prefix.To4()
+	for i := 0; i < prefixLen; i++ {
+		bit := int(ip[i>>3]>>(7-uint(i&7))) & 1
+		next := i + 1
+		if i == prefixLen-1 {
+			next = dataPointer
+		}
+		records := [2]int{empty, empty}
+		records[bit] = next
+		for _, record := range records {
+			tree = append(tree, byte(record>>16), byte(record>>8), byte(record))
+		}
+	}
+
+	var db bytes.Buffer
+	db.Write(tree)
+	db.Write(make([]byte, dataSectionSeparator))
+	db.Write(data)
+	db.Write(metadataMarker)
+	db.Write(encodeMap(
+		encodeString("node_count"), encodeUint(typeUint32, uint64(nodeCount), 4),
+		encodeString("record_size"), encodeUint(typeUint16, 24, 2),
+		encodeString("ip_version"), encodeUint(typeUint16, 4, 2),
+		encodeString("database_type"), encodeString("Test-City"),
+	))
+	return db.Bytes()
+}
+
+func TestReader_Lookup(t *testing.T) {
+	// The subdivision code is stored once and referenced through a pointer
+	shared := encodeString("CA")
+	record := encodeMap(
+		encodeString("country"), encodeMap(encodeString("iso_code"), encodeString("US")),
+		encodeString("subdivisions"), encodeArray(encodeMap(encodeString("iso_code"), encodePointer(0))),
+		encodeString("traits"), encodeMap(encodeString("is_anonymous_proxy"), encodeBool(true)),
+		encodeString("autonomous_system_organization"), encodeString("Example Networks"),
+		encodeString("accuracy"), encodeUint(typeUint16, 1000, 2),
+	)
+	data := append(append([]byte{}, shared...), record...)
+
+	reader, err := FromBytes(buildDatabase(t, net.ParseIP("203.0.113.0"), 24, data, len(shared)))
+	require.NoError(t, err)
+	assert.Equal(t, Metadata{DatabaseType: "Test-City", IPVersion: 4, RecordSize: 24, NodeCount: 24}, reader.Metadata())
+
+	location, err := reader.Lookup(net.ParseIP("203.0.113.77"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.GeoLocation{Country: "US", Region: "US-CA", ISP: "Example Networks", IsProxy: true}, location)
+
+	location, err = reader.Lookup(net.ParseIP("198.51.100.1"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.GeoLocation{}, location)
+
+	// IPv6 addresses are unknown to IPv4 databases
+	location, err = reader.Lookup(net.ParseIP("2001:db8::1"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.GeoLocation{}, location)
+}
+
+func TestFromBytes_Invalid(t *testing.T) {
+	_, err := FromBytes([]byte("not a database"))
+	assert.ErrorIs(t, err, ErrInvalidDatabase)
+
+	badRecordSize := append(append([]byte{}, metadataMarker...), encodeMap(
+		encodeString("node_count"), encodeUint(typeUint32, 1, 4),
+		encodeString("record_size"), encodeUint(typeUint16, 20, 2),
+		encodeString("ip_version"), encodeUint(typeUint16, 4, 2),
+	)...)
+	_, err = FromBytes(badRecordSize)
+	assert.ErrorIs(t, err, ErrInvalidDatabase)
+}
@@ -0,0 +1,150 @@
+// Package linkcheck checks that URLs are reachable. Redirects are followed one at a time so that
+// every hop is recorded, along with the time until the last response and the earliest expiry
+// of the TLS certificates served along the way.
+package linkcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/netguard"
+)
+
+const (
+	// DefaultTimeout bounds a whole check, redirects included
+	DefaultTimeout = 15 * time.Second
+	// maxDrainBytes is how much of a response body is read so that the connection can be reused
+	maxDrainBytes = 64 << 10
+	userAgent     = "AffiliateLinkHealth/1.0"
+)
+
+// ErrTooManyRedirects is returned for URLs that redirect more than the checker follows
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// ErrNonPublicAddress is returned for URLs, or redirects, to loopback, private, link-local and
+// other addresses that are not on the public internet
+var ErrNonPublicAddress = netguard.ErrNonPublicAddress
+
+// newRestrictedClient returns a client that only connects to the addresses allowed accepts,
+// so redirects and DNS rebinding are covered as well
+func newRestrictedClient(allowed func(net.IP) bool) *http.Client {
+	return &http.Client{Transport: netguard.NewTransport(allowed)}
+}
+
+// Result is the outcome of checking a URL
+type Result struct {
+	URL        string
+	FinalURL   string
+	StatusCode int // Status of the last response, 0 when no response was received
+	Latency    time.Duration
+	// RedirectChain lists every response received, the last one included
+	RedirectChain []domain.LinkRedirectHop
+	// TLSExpiresAt is the earliest expiry of the leaf certificates served along the chain
+	TLSExpiresAt *time.Time
+	Err          error
+}
+
+// Healthy reports whether the URL answered with a success or redirect status, without error
+func (r *Result) Healthy() bool {
+	return r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 400
+}
+
+// Checker fetches URLs and follows their redirects
+type Checker struct {
+	client       *http.Client
+	timeout      time.Duration
+	maxRedirects int
+}
+
+// NewChecker creates a checker. client may be nil for a default client that refuses to connect
+// to non-public addresses, since the URLs checked are user supplied; a given client is used as
+// is. Its redirect policy is replaced since the checker follows redirects itself.
+func NewChecker(client *http.Client) *Checker {
+	if client == nil {
+		client = newRestrictedClient(netguard.IsPublicIP)
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &Checker{
+		client:       &noRedirects,
+		timeout:      DefaultTimeout,
+		maxRedirects: domain.MaxLinkHealthRedirects,
+	}
+}
+
+// Check fetches rawURL with GET and follows its redirects. Failures are reported in the result.
+func (c *Checker) Check(ctx context.Context, rawURL string) *Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result := &Result{URL: rawURL, RedirectChain: make([]domain.LinkRedirectHop, 0, 1)}
+	start := time.Now()
+	defer func() { result.Latency = time.Since(start) }()
+
+	current := rawURL
+	for redirects := 0; ; redirects++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, current, nil)
+		if err != nil {
+			result.Err = fmt.Errorf("invalid URL: %w", err)
+			return result
+		}
+		req.Header.Set("User-Agent", userAgent)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+
+		result.FinalURL = current
+		result.StatusCode = resp.StatusCode
+		result.RedirectChain = append(result.RedirectChain, domain.LinkRedirectHop{URL: current, StatusCode: resp.StatusCode})
+		result.TLSExpiresAt = earliestExpiry(result.TLSExpiresAt, resp.TLS)
+
+		if !isRedirect(resp.StatusCode) {
+			return result
+		}
+		location, err := resp.Location()
+		if err != nil {
+			result.Err = fmt.Errorf("redirect %d without a valid location: %w", resp.StatusCode, err)
+			return result
+		}
+		if redirects == c.maxRedirects {
+			result.Err = ErrTooManyRedirects
+			return result
+		}
+		current = location.String()
+	}
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// earliestExpiry returns the earlier of current and the expiry of the leaf certificate of state
+func earliestExpiry(current *time.Time, state *tls.ConnectionState) *time.Time {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return current
+	}
+	notAfter := state.PeerCertificates[0].NotAfter
+	if current != nil && current.Before(notAfter) {
+		return current
+	}
+	return &notAfter
+}
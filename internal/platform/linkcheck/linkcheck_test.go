@@ -0,0 +1,152 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckFollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusFound)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/landing", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/landing", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result := NewChecker(server.Client()).Check(context.Background(), server.URL+"/start")
+
+	if !result.Healthy() {
+		t.Fatalf("Check() unhealthy: status %d, err %v", result.StatusCode, result.Err)
+	}
+	if result.FinalURL != server.URL+"/landing" {
+		t.Errorf("FinalURL = %q, want %q", result.FinalURL, server.URL+"/landing")
+	}
+	wantCodes := []int{http.StatusFound, http.StatusMovedPermanently, http.StatusOK}
+	if len(result.RedirectChain) != len(wantCodes) {
+		t.Fatalf("RedirectChain = %+v, want %d hops", result.RedirectChain, len(wantCodes))
+	}
+	for i, code := range wantCodes {
+		if result.RedirectChain[i].StatusCode != code {
+			t.Errorf("hop %d status = %d, want %d", i, result.RedirectChain[i].StatusCode, code)
+		}
+	}
+	if result.TLSExpiresAt != nil {
+		t.Errorf("TLSExpiresAt = %v, want nil for plain HTTP", result.TLSExpiresAt)
+	}
+}
+
+func TestCheckReportsFailures(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/no-location", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	checker := NewChecker(server.Client())
+
+	result := checker.Check(context.Background(), server.URL+"/missing")
+	if result.Healthy() || result.StatusCode != http.StatusNotFound || result.Err != nil {
+		t.Errorf("Check(/missing) = status %d, err %v", result.StatusCode, result.Err)
+	}
+
+	result = checker.Check(context.Background(), server.URL+"/loop")
+	if !errors.Is(result.Err, ErrTooManyRedirects) {
+		t.Errorf("Check(/loop) err = %v, want ErrTooManyRedirects", result.Err)
+	}
+	if len(result.RedirectChain) != checker.maxRedirects+1 {
+		t.Errorf("Check(/loop) recorded %d hops, want %d", len(result.RedirectChain), checker.maxRedirects+1)
+	}
+
+	result = checker.Check(context.Background(), server.URL+"/no-location")
+	if result.Healthy() || result.Err == nil {
+		t.Errorf("Check(/no-location) = status %d, err %v", result.StatusCode, result.Err)
+	}
+
+	server.Close()
+	result = checker.Check(context.Background(), server.URL+"/missing")
+	if result.Healthy() || result.Err == nil || result.StatusCode != 0 {
+		t.Errorf("Check() on a closed server = status %d, err %v", result.StatusCode, result.Err)
+	}
+}
+
+func TestCheckTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	checker := NewChecker(server.Client())
+	checker.timeout = 50 * time.Millisecond
+	result := checker.Check(context.Background(), server.URL)
+	if result.Healthy() || result.Err == nil {
+		t.Errorf("Check() = status %d, err %v, want a timeout", result.StatusCode, result.Err)
+	}
+}
+
+func TestCheckRecordsTLSExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	result := NewChecker(server.Client()).Check(context.Background(), server.URL)
+	if !result.Healthy() {
+		t.Fatalf("Check() unhealthy: status %d, err %v", result.StatusCode, result.Err)
+	}
+	want := server.Certificate().NotAfter
+	if result.TLSExpiresAt == nil || !result.TLSExpiresAt.Equal(want) {
+		t.Errorf("TLSExpiresAt = %v, want %v", result.TLSExpiresAt, want)
+	}
+}
+
+func TestDefaultCheckerRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	result := NewChecker(nil).Check(context.Background(), server.URL)
+	if result.Healthy() || !errors.Is(result.Err, ErrNonPublicAddress) {
+		t.Errorf("Check(%s) = status %d, err %v, want ErrNonPublicAddress", server.URL, result.StatusCode, result.Err)
+	}
+	if len(result.RedirectChain) != 0 {
+		t.Errorf("RedirectChain = %+v, want no response", result.RedirectChain)
+	}
+}
+
+func TestCheckRefusesRedirectsToDisallowedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// Only the test server itself may be reached
+	checker := NewChecker(newRestrictedClient(func(ip net.IP) bool { return ip.Equal(net.IPv4(127, 0, 0, 1)) }))
+	result := checker.Check(context.Background(), server.URL)
+	if result.Healthy() || !errors.Is(result.Err, ErrNonPublicAddress) {
+		t.Errorf("Check() = status %d, err %v, want ErrNonPublicAddress", result.StatusCode, result.Err)
+	}
+	if len(result.RedirectChain) != 1 || result.RedirectChain[0].StatusCode != http.StatusFound {
+		t.Errorf("RedirectChain = %+v, want the redirect only", result.RedirectChain)
+	}
+}
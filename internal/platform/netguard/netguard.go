@@ -0,0 +1,85 @@
+// Package netguard restricts outbound connections made on behalf of users to public internet
+// addresses, so that user supplied URLs and hosts cannot reach internal services.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned for connections to loopback, private, link-local and other
+// addresses that are not on the public internet
+var ErrNonPublicAddress = errors.New("non-public address")
+
+// nonPublicNetworks are the ranges not covered by the net.IP predicates used in IsPublicIP
+var nonPublicNetworks = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"), // Carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // Benchmarking
+	mustParseCIDR("240.0.0.0/4"),   // Reserved
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// IsPublicIP reports whether ip is a public unicast address
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost rejects host names that are known not to be public without resolving them:
+// localhost names and non-public IP literals. Other names are only checked when dialed.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+	}
+	return nil
+}
+
+// NewDialer returns a dialer that only connects to the addresses allowed accepts. The check is
+// a Control hook: it runs after name resolution, on every connection, so DNS rebinding is covered.
+func NewDialer(allowed func(net.IP) bool, timeout time.Duration) *net.Dialer {
+	control := func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+			return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+		}
+		return nil
+	}
+	return &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: control}
+}
+
+// NewTransport returns an HTTP transport that only connects to the addresses allowed accepts,
+// directly rather than through an environment proxy, so redirects are restricted as well
+func NewTransport(allowed func(net.IP) bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = NewDialer(allowed, 10*time.Second).DialContext
+	return transport
+}
@@ -0,0 +1,88 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{"s3.example.com", false},
+		{"93.184.216.34", false},
+		{"localhost", true},
+		{"LOCALHOST.", true},
+		{"minio.localhost", true},
+		{"127.0.0.1", true},
+		{"10.0.0.5", true},
+		{"169.254.169.254", true},
+		{"[::1]", true},
+	}
+	for _, tt := range tests {
+		err := CheckHost(tt.host)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckHost(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("CheckHost(%q) error = %v, want ErrNonPublicAddress", tt.host, err)
+		}
+	}
+}
+
+func TestNewTransportRefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(IsPublicIP), Timeout: 5 * time.Second}
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("Get(%s) error = %v, want ErrNonPublicAddress", server.URL, err)
+	}
+}
+
+func TestNewDialerRefusesNonPublicAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	_, err = NewDialer(IsPublicIP, time.Second).DialContext(context.Background(), "tcp", listener.Addr().String())
+	if !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("DialContext() error = %v, want ErrNonPublicAddress", err)
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+)
+
+// CreativeService defines the provider-agnostic interface for syncing campaign creatives
+type CreativeService interface {
+	// ProviderType returns the provider identifier (e.g. "everflow")
+	ProviderType() string
+
+	// SyncCreative creates the creative on the provider offer of the campaign, or updates it when
+	// it already has a provider creative ID, and returns the provider creative ID. The content of
+	// image creatives is passed in file.
+	SyncCreative(ctx context.Context, creative *domain.Creative, file []byte, campaignMapping *domain.CampaignProviderMapping) (string, error)
+}
+
+// LoggingMockCreativeService is a mock creative service that logs requests and reuses local IDs
+type LoggingMockCreativeService struct{}
+
+// Ensure LoggingMockCreativeService implements CreativeService
+var _ CreativeService = (*LoggingMockCreativeService)(nil)
+
+// NewLoggingMockCreativeService creates a new logging mock creative service
+func NewLoggingMockCreativeService() *LoggingMockCreativeService {
+	logger.Info("Mock Creative Service initialized - creative syncs will be simulated")
+	return &LoggingMockCreativeService{}
+}
+
+// ProviderType returns the simulated provider type
+func (l *LoggingMockCreativeService) ProviderType() string {
+	return "everflow"
+}
+
+// SyncCreative logs the request and returns the existing provider ID or the local creative ID
+func (l *LoggingMockCreativeService) SyncCreative(ctx context.Context, creative *domain.Creative, file []byte, campaignMapping *domain.CampaignProviderMapping) (string, error) {
+	logger.Debug("Mock request", "operation", "SYNC", "entity_type", "CREATIVE", "creative_id", creative.CreativeID, "file_size", len(file))
+	if creative.ProviderCreativeID != nil {
+		return *creative.ProviderCreativeID, nil
+	}
+	return strconv.FormatInt(creative.CreativeID, 10), nil
+}
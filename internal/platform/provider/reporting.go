@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+)
+
+// ReportingService defines the provider-agnostic interface for pulling performance reports
+type ReportingService interface {
+	// ProviderType returns the provider identifier stored alongside imported stats (e.g. "everflow")
+	ProviderType() string
+
+	// FetchDailyStats returns the stats for a single day, broken down by offer, affiliate and sub IDs.
+	// Returned stats carry provider IDs only; resolving them to local entities is up to the caller.
+	FetchDailyStats(ctx context.Context, date time.Time) ([]domain.ProviderDailyStat, error)
+}
+
+// LoggingMockReportingService is a mock reporting service that logs requests and returns no data
+type LoggingMockReportingService struct{}
+
+// Ensure LoggingMockReportingService implements ReportingService
+var _ ReportingService = (*LoggingMockReportingService)(nil)
+
+// NewLoggingMockReportingService creates a new logging mock reporting service
+func NewLoggingMockReportingService() *LoggingMockReportingService {
+	logger.Info("Mock Reporting Service initialized - provider reports will be simulated as empty")
+	return &LoggingMockReportingService{}
+}
+
+// ProviderType returns the simulated provider type
+func (l *LoggingMockReportingService) ProviderType() string {
+	return "everflow"
+}
+
+// FetchDailyStats logs the request and returns an empty report
+func (l *LoggingMockReportingService) FetchDailyStats(ctx context.Context, date time.Time) ([]domain.ProviderDailyStat, error) {
+	logger.Debug("Mock request", "operation", "FETCH", "entity_type", "DAILY_STATS", "date", date.Format("2006-01-02"))
+	return []domain.ProviderDailyStat{}, nil
+}
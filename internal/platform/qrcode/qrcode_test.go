@@ -0,0 +1,241 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormatInformation checks the format bits against the table of the standard
+func TestFormatInformation(t *testing.T) {
+	tests := []struct {
+		level ErrorCorrectionLevel
+		mask  int
+		want  int
+	}{
+		{Low, 0, 0x77C4},
+		{Low, 7, 0x6976},
+		{Medium, 0, 0x5412},
+		{Medium, 5, 0x40CE},
+		{Quartile, 0, 0x355F},
+		{High, 0, 0x1689},
+		{High, 7, 0x083B},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatInformation(tt.level, tt.mask), "level %s mask %d", tt.level, tt.mask)
+	}
+}
+
+// TestVersionInformation checks the version bits against the table of the standard
+func TestVersionInformation(t *testing.T) {
+	assert.Equal(t, 0x07C94, versionInformation(7))
+	assert.Equal(t, 0x0A4D3, versionInformation(10))
+	assert.Equal(t, 0x28C69, versionInformation(40))
+}
+
+// TestReedSolomonRemainder checks the error correction of the 1-M "HELLO WORLD" example
+func TestReedSolomonRemainder(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	assert.Equal(t, want, reedSolomonRemainder(data, reedSolomonDivisor(10)))
+}
+
+func TestAlignmentPatternPositions(t *testing.T) {
+	assert.Empty(t, alignmentPatternPositions(1))
+	assert.Equal(t, []int{6, 18}, alignmentPatternPositions(2))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPatternPositions(7))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPatternPositions(32))
+	assert.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPatternPositions(40))
+}
+
+// TestNumDataCodewords checks a few data capacities from the standard
+func TestNumDataCodewords(t *testing.T) {
+	assert.Equal(t, 19, numDataCodewords(1, Low))
+	assert.Equal(t, 9, numDataCodewords(1, High))
+	assert.Equal(t, 62, numDataCodewords(5, Quartile))
+	assert.Equal(t, 216, numDataCodewords(10, Medium))
+	assert.Equal(t, 2956, numDataCodewords(40, Low))
+	assert.Equal(t, 1276, numDataCodewords(40, High))
+}
+
+func TestEncode_Version(t *testing.T) {
+	// Byte mode holds 17 bytes in 1-L and 7 in 1-H
+	code, err := Encode(strings.Repeat("a", 17), Low)
+	require.NoError(t, err)
+	assert.Equal(t, 1, code.Version)
+	assert.Equal(t, 21, code.Size)
+
+	code, err = Encode(strings.Repeat("a", 8), High)
+	require.NoError(t, err)
+	assert.Equal(t, 2, code.Version)
+
+	_, err = Encode(strings.Repeat("a", 2954), Low)
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+// TestEncode_RoundTrip reads the symbols back and compares the data codewords
+func TestEncode_RoundTrip(t *testing.T) {
+	texts := []string{
+		"https://track.example.com/c?o=42&a=7",
+		"https://track.example.com/c?o=42&a=7&sub1=" + strings.Repeat("x", 300),
+		strings.Repeat("€", 400),
+	}
+	for _, text := range texts {
+		for level := Low; level <= High; level++ {
+			code, err := Encode(text, level)
+			require.NoError(t, err)
+
+			mask, decodedLevel := readFormat(t, code)
+			assert.Equal(t, code.Mask, mask)
+			assert.Equal(t, level, decodedLevel)
+
+			want := encodeData([]byte(text), code.Version, level)
+			assert.Equal(t, want, readData(code), "version %d level %s", code.Version, level)
+		}
+	}
+}
+
+// readFormat decodes the format information next to the top left finder pattern
+func readFormat(t *testing.T, c *Code) (int, ErrorCorrectionLevel) {
+	bits := 0
+	for i := 0; i <= 5; i++ {
+		bits |= b2i(c.Dark(8, i)) << uint(i)
+	}
+	bits |= b2i(c.Dark(8, 7)) << 6
+	bits |= b2i(c.Dark(8, 8)) << 7
+	bits |= b2i(c.Dark(7, 8)) << 8
+	for i := 9; i < 15; i++ {
+		bits |= b2i(c.Dark(14-i, 8)) << uint(i)
+	}
+	for level := Low; level <= High; level++ {
+		for mask := 0; mask < 8; mask++ {
+			if formatInformation(level, mask) == bits {
+				return mask, level
+			}
+		}
+	}
+	t.Fatalf("unknown format bits %015b", bits)
+	return 0, 0
+}
+
+// readData unmasks the symbol, reads the codewords in zigzag order and de-interleaves the data codewords
+func readData(c *Code) []byte {
+	c.applyMask(c.Mask)
+	defer c.applyMask(c.Mask)
+
+	raw := make([]byte, numRawDataModules(c.Version)/8)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.isFunction[y][x] && i < len(raw)*8 {
+					if c.modules[y][x] {
+						raw[i>>3] |= 1 << (7 - uint(i&7))
+					}
+					i++
+				}
+			}
+		}
+	}
+
+	numBlocks := numErrorCorrectionBlocks[c.Level][c.Version]
+	eccLen := eccCodewordsPerBlock[c.Level][c.Version]
+	numShortBlocks := numBlocks - len(raw)%numBlocks
+	shortDataLen := len(raw)/numBlocks - eccLen
+
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for col := 0; col <= shortDataLen; col++ {
+		for b := 0; b < numBlocks; b++ {
+			if col == shortDataLen && b < numShortBlocks {
+				continue
+			}
+			blocks[b] = append(blocks[b], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	for _, block := range blocks {
+		data = append(data, block...)
+	}
+	return data
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestRenderPNG(t *testing.T) {
+	code, err := Encode("https://track.example.com/c?o=42", Quartile)
+	require.NoError(t, err)
+
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	logo := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			logo.Set(x, y, red)
+		}
+	}
+
+	data, err := code.RenderPNG(RenderOptions{Size: 300, Margin: DefaultMargin, Logo: logo})
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 300, 300), img.Bounds())
+
+	l := code.layout(RenderOptions{Size: 300, Margin: DefaultMargin})
+	origin := l.offset + DefaultMargin*l.scale
+	// The top left module belongs to a finder pattern, the corner is quiet zone and the centre is the logo
+	assert.Equal(t, color.NRGBAModel.Convert(color.Black), color.NRGBAModel.Convert(img.At(origin, origin)))
+	assert.Equal(t, color.NRGBAModel.Convert(color.White), color.NRGBAModel.Convert(img.At(0, 0)))
+	assert.Equal(t, color.Color(red), color.NRGBAModel.Convert(img.At(150, 150)))
+}
+
+func TestRenderSVG(t *testing.T) {
+	code, err := Encode("https://track.example.com/c?o=42", Medium)
+	require.NoError(t, err)
+
+	data, err := code.RenderSVG(RenderOptions{
+		Size:       256,
+		Margin:     2,
+		Foreground: color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff},
+		Background: color.NRGBA{},
+	})
+	require.NoError(t, err)
+	svg := string(data)
+	assert.Contains(t, svg, `width="256" height="256"`)
+	assert.Contains(t, svg, `<path fill="#112233" d="M`)
+	assert.NotContains(t, svg, "<rect", "a transparent background is not drawn")
+	assert.NotContains(t, svg, "<image")
+}
+
+func TestParseHexColor(t *testing.T) {
+	c, err := ParseHexColor("#0af")
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0x00, G: 0xaa, B: 0xff, A: 0xff}, c)
+
+	c, err = ParseHexColor("#11223380")
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x80}, c)
+
+	for _, invalid := range []string{"", "112233", "#12", "#gggggg"} {
+		_, err := ParseHexColor(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,240 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultMargin is the quiet zone required by the standard, in modules
+	DefaultMargin = 4
+	// logoShare is the width of the logo relative to the symbol. Together with its pad the logo
+	// covers under 10% of the modules, which levels Q and H restore.
+	logoShare = 0.22
+)
+
+// RenderOptions controls how a Code is drawn
+type RenderOptions struct {
+	// Size is the width and height of the image in pixels. Modules are whole pixels, so the
+	// symbol is centred in the image when Size is not a multiple of the module count; the image
+	// is larger than Size when Size is below one pixel per module.
+	Size int
+	// Margin is the quiet zone around the symbol, in modules
+	Margin     int
+	Foreground color.Color
+	Background color.Color
+	// Logo is drawn at the centre of the symbol on a pad of the background color
+	Logo image.Image
+}
+
+// layout is the position of the symbol in the image
+type layout struct {
+	size   int // Image width and height in pixels
+	scale  int // Pixels per module
+	offset int // Pixels before the first module of the quiet zone
+}
+
+func (c *Code) layout(opts RenderOptions) layout {
+	modules := c.Size + 2*opts.Margin
+	scale := opts.Size / modules
+	if scale < 1 {
+		scale = 1
+	}
+	size := opts.Size
+	if size < modules*scale {
+		size = modules * scale
+	}
+	return layout{size: size, scale: scale, offset: (size - modules*scale) / 2}
+}
+
+// logoBox returns the square, in pixels, the logo and its pad take at the centre of the symbol
+func (c *Code) logoBox(l layout, margin int) (logo, pad image.Rectangle) {
+	symbol := c.Size * l.scale
+	side := int(float64(symbol) * logoShare)
+	origin := l.offset + margin*l.scale + (symbol-side)/2
+	logo = image.Rect(origin, origin, origin+side, origin+side)
+	return logo, logo.Inset(-l.scale)
+}
+
+// RenderPNG draws the symbol as a PNG image
+func (c *Code) RenderPNG(opts RenderOptions) ([]byte, error) {
+	opts = withDefaultColors(opts)
+	l := c.layout(opts)
+
+	img := image.NewNRGBA(image.Rect(0, 0, l.size, l.size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+
+	foreground := image.NewUniform(opts.Foreground)
+	origin := l.offset + opts.Margin*l.scale
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			r := image.Rect(origin+x*l.scale, origin+y*l.scale, origin+(x+1)*l.scale, origin+(y+1)*l.scale)
+			draw.Draw(img, r, foreground, image.Point{}, draw.Src)
+		}
+	}
+
+	if opts.Logo != nil {
+		logoRect, padRect := c.logoBox(l, opts.Margin)
+		draw.Draw(img, padRect, image.NewUniform(opts.Background), image.Point{}, draw.Src)
+		scaled := fitImage(opts.Logo, logoRect.Dx(), logoRect.Dy())
+		at := logoRect.Min.Add(image.Pt(
+			(logoRect.Dx()-scaled.Bounds().Dx())/2,
+			(logoRect.Dy()-scaled.Bounds().Dy())/2,
+		))
+		draw.Draw(img, scaled.Bounds().Add(at), scaled, image.Point{}, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("qrcode: failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderSVG draws the symbol as an SVG document. Modules are drawn as one path in module units,
+// so the image scales without blurring; the logo is embedded as a PNG data URI.
+func (c *Code) RenderSVG(opts RenderOptions) ([]byte, error) {
+	opts = withDefaultColors(opts)
+	l := c.layout(opts)
+	// The view box is in modules; the extra pixels of the PNG layout become extra margin
+	viewBox := float64(l.size) / float64(l.scale)
+	shift := float64(l.offset) / float64(l.scale)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %s %s" shape-rendering="crispEdges">`+"\n",
+		l.size, l.size, formatFloat(viewBox), formatFloat(viewBox))
+	if fill, opacity, visible := svgColor(opts.Background); visible {
+		fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"%s/>`+"\n", fill, opacity)
+	}
+
+	fill, opacity, _ := svgColor(opts.Foreground)
+	fmt.Fprintf(&buf, `<path fill="%s"%s d="`, fill, opacity)
+	origin := shift + float64(opts.Margin)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&buf, "M%s %sh1v1h-1z", formatFloat(origin+float64(x)), formatFloat(origin+float64(y)))
+			}
+		}
+	}
+	buf.WriteString(`"/>` + "\n")
+
+	if opts.Logo != nil {
+		logoRect, padRect := c.logoBox(l, opts.Margin)
+		toUnits := func(px int) string { return formatFloat(float64(px) / float64(l.scale)) }
+
+		// The pad needs a solid color even on a transparent background
+		bgFill, bgOpacity, visible := svgColor(opts.Background)
+		if !visible {
+			bgFill, bgOpacity = "#ffffff", ""
+		}
+		fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"%s/>`+"\n",
+			toUnits(padRect.Min.X), toUnits(padRect.Min.Y), toUnits(padRect.Dx()), toUnits(padRect.Dy()), bgFill, bgOpacity)
+
+		// Embed the logo at twice the pixel size of the PNG layout so that it stays sharp when zoomed
+		scaled := fitImage(opts.Logo, logoRect.Dx()*2, logoRect.Dy()*2)
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, scaled); err != nil {
+			return nil, fmt.Errorf("qrcode: failed to encode logo: %w", err)
+		}
+		fmt.Fprintf(&buf, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`+"\n",
+			toUnits(logoRect.Min.X), toUnits(logoRect.Min.Y), toUnits(logoRect.Dx()), toUnits(logoRect.Dy()),
+			base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
+
+func withDefaultColors(opts RenderOptions) RenderOptions {
+	if opts.Foreground == nil {
+		opts.Foreground = color.Black
+	}
+	if opts.Background == nil {
+		opts.Background = color.White
+	}
+	return opts
+}
+
+// svgColor returns the fill and opacity attributes of a color; visible is false when it is fully transparent
+func svgColor(c color.Color) (fill, opacity string, visible bool) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	fill = fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+	if n.A < 0xff {
+		opacity = fmt.Sprintf(` fill-opacity="%s"`, formatFloat(float64(n.A)/0xff))
+	}
+	return fill, opacity, n.A > 0
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// fitImage scales src to fit in width x height, keeping its aspect ratio. Each destination pixel
+// averages the source pixels it covers, which keeps downscaled logos smooth.
+func fitImage(src image.Image, width, height int) *image.NRGBA {
+	b := src.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 || width <= 0 || height <= 0 {
+		return image.NewNRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if b.Dx()*height > b.Dy()*width {
+		height = maxInt(1, b.Dy()*width/b.Dx())
+	} else {
+		width = maxInt(1, b.Dx()*height/b.Dy())
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := maxInt(y0+1, b.Min.Y+(y+1)*b.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := maxInt(x0+1, b.Min.X+(x+1)*b.Dx()/width)
+
+			// Average in premultiplied alpha so that transparent pixels do not darken the edges
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// ParseHexColor parses #rgb, #rrggbb or #rrggbbaa
+func ParseHexColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 || !strings.HasPrefix(s, "#") {
+		return color.NRGBA{}, fmt.Errorf("qrcode: invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("qrcode: invalid color %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
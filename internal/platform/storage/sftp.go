@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/affiliate-backend/internal/platform/netguard"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTPConfig holds the connection settings for an SFTP destination
+type SFTPConfig struct {
+	Host               string
+	Port               int // Defaults to 22
+	Username           string
+	Password           string // Used when PrivateKey is empty
+	PrivateKey         string // PEM encoded private key
+	Directory          string // Remote base directory for uploaded files
+	HostKeyFingerprint string // SHA256 fingerprint (e.g. "SHA256:...") of the expected host key; required
+	// PublicOnly refuses connections to loopback, private and other non-public addresses, for
+	// hosts supplied by users
+	PublicOnly bool
+}
+
+// SFTPStore is an ObjectStore backed by a directory on an SFTP server
+type SFTPStore struct {
+	config SFTPConfig
+}
+
+// Ensure SFTPStore implements ObjectStore
+var _ ObjectStore = (*SFTPStore)(nil)
+
+// NewSFTPStore creates a new SFTP store
+func NewSFTPStore(config SFTPConfig) *SFTPStore {
+	if config.Port == 0 {
+		config.Port = 22
+	}
+	return &SFTPStore{config: config}
+}
+
+// Put uploads the object under the configured directory, creating subdirectories as needed
+func (s *SFTPStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	client, closeFn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	remotePath := path.Join(s.config.Directory, key)
+	if err := client.MkdirAll(path.Dir(remotePath)); err != nil {
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+
+	file, err := client.Create(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write remote file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close remote file: %w", err)
+	}
+	return nil
+}
+
+// Get downloads the object from the configured directory
+func (s *SFTPStore) Get(ctx context.Context, key string) ([]byte, error) {
+	client, closeFn, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	file, err := client.Open(path.Join(s.config.Directory, key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote file: %w", err)
+	}
+	return data, nil
+}
+
+// connect opens an SSH connection and SFTP session
+func (s *SFTPStore) connect(ctx context.Context) (*sftp.Client, func(), error) {
+	var auth []ssh.AuthMethod
+	if s.config.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(s.config.PrivateKey))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid SFTP private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	} else {
+		auth = append(auth, ssh.Password(s.config.Password))
+	}
+
+	// Credentials are only sent to the server whose host key was configured
+	if s.config.HostKeyFingerprint == "" {
+		return nil, nil, fmt.Errorf("SFTP host key fingerprint is required")
+	}
+	expected := s.config.HostKeyFingerprint
+	hostKeyCallback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if fingerprint := ssh.FingerprintSHA256(key); fingerprint != expected {
+			return fmt.Errorf("SFTP host key mismatch: got %s", fingerprint)
+		}
+		return nil
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if s.config.PublicOnly {
+		dialer = netguard.NewDialer(netguard.IsPublicIP, 30*time.Second)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to SFTP server: %w", err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            s.config.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("SSH handshake failed: %w", err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, nil, fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+
+	return client, func() {
+		client.Close()
+		sshClient.Close()
+	}, nil
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"testing"
+
+	"github.com/affiliate-backend/internal/platform/netguard"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// newFakeSFTPServer starts an SSH server with an in-memory SFTP subsystem and returns its address
+// and host key fingerprint
+func newFakeSFTPServer(t *testing.T) (string, int, string) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "reports" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, assert.AnError
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	// Objects are shared across connections so that a Get sees earlier Puts
+	handlers := sftp.InMemHandler()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeSFTPConn(conn, config, handlers)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ssh.FingerprintSHA256(signer.PublicKey())
+}
+
+func serveFakeSFTPConn(conn net.Conn, config *ssh.ServerConfig, handlers sftp.Handlers) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server := sftp.NewRequestServer(channel, handlers)
+					server.Serve()
+					server.Close()
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestSFTPStore_PutGet(t *testing.T) {
+	host, port, fingerprint := newFakeSFTPServer(t)
+	store := NewSFTPStore(SFTPConfig{
+		Host:               host,
+		Port:               port,
+		Username:           "reports",
+		Password:           "secret",
+		Directory:          "/outbox",
+		HostKeyFingerprint: fingerprint,
+	})
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "daily/2024-03-15.csv", "text/csv", []byte("date,clicks\n2024-03-15,10\n")))
+
+	data, err := store.Get(ctx, "daily/2024-03-15.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "date,clicks\n2024-03-15,10\n", string(data))
+
+	_, err = store.Get(ctx, "daily/missing.csv")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestSFTPStore_HostKeyVerification(t *testing.T) {
+	host, port, _ := newFakeSFTPServer(t)
+	config := SFTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "reports",
+		Password: "secret",
+	}
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		wantErr     string
+	}{
+		{"missing fingerprint", "", "host key fingerprint is required"},
+		{"wrong fingerprint", "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8", "host key mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.HostKeyFingerprint = tt.fingerprint
+			err := NewSFTPStore(config).Put(context.Background(), "report.csv", "text/csv", []byte("x"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSFTPStore_PublicOnlyRefusesLoopback(t *testing.T) {
+	host, port, fingerprint := newFakeSFTPServer(t)
+	store := NewSFTPStore(SFTPConfig{
+		Host:               host,
+		Port:               port,
+		Username:           "reports",
+		Password:           "secret",
+		HostKeyFingerprint: fingerprint,
+		PublicOnly:         true,
+	})
+
+	err := store.Put(context.Background(), "report.csv", "text/csv", []byte("x"))
+	assert.ErrorIs(t, err, netguard.ErrNonPublicAddress)
+}
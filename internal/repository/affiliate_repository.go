@@ -142,6 +142,9 @@ func (r *pgxAffiliateRepository) GetAffiliateByID(ctx context.Context, affiliate
 	)
 
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("affiliate not found: %w", domain.ErrNotFound)
+		}
 		return nil, fmt.Errorf("error getting affiliate by ID: %w", err)
 	}
 
@@ -0,0 +1,463 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AnalyticsUpsertResult counts how a batch of analytics records was applied
+type AnalyticsUpsertResult struct {
+	Inserted int
+	Updated  int
+	Skipped  int // Records whose content hash did not change
+}
+
+// AnalyticsImportRepository defines the interface for bulk analytics import persistence
+type AnalyticsImportRepository interface {
+	CreateImport(ctx context.Context, analyticsImport *domain.AnalyticsImport) error
+	FinishImport(ctx context.Context, analyticsImport *domain.AnalyticsImport) error
+	AddImportErrors(ctx context.Context, importID int64, importErrors []domain.AnalyticsImportError) error
+	GetImport(ctx context.Context, importID int64) (*domain.AnalyticsImport, error)
+	ListImports(ctx context.Context, limit, offset int) ([]*domain.AnalyticsImport, int, error)
+
+	// UpsertPublishers and UpsertAdvertisers upsert a batch by domain and record a snapshot for every
+	// inserted or changed record (publishers only when their tracked metrics changed). Domains must be
+	// unique within a batch.
+	UpsertPublishers(ctx context.Context, importID int64, records []*domain.AnalyticsPublisherImportRecord) (*AnalyticsUpsertResult, error)
+	UpsertAdvertisers(ctx context.Context, importID int64, records []*domain.AnalyticsAdvertiserImportRecord) (*AnalyticsUpsertResult, error)
+}
+
+// pgxAnalyticsImportRepository implements AnalyticsImportRepository
+type pgxAnalyticsImportRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxAnalyticsImportRepository creates a new analytics import repository
+func NewPgxAnalyticsImportRepository(db *pgxpool.Pool) AnalyticsImportRepository {
+	return &pgxAnalyticsImportRepository{db: db}
+}
+
+const analyticsImportColumns = `import_id, kind, source, status, inserted_count, updated_count, skipped_count,
+	failed_count, error_message, created_by_user_id, started_at, completed_at`
+
+func scanAnalyticsImport(row pgx.Row) (*domain.AnalyticsImport, error) {
+	var analyticsImport domain.AnalyticsImport
+	err := row.Scan(
+		&analyticsImport.ImportID, &analyticsImport.Kind, &analyticsImport.Source, &analyticsImport.Status,
+		&analyticsImport.InsertedCount, &analyticsImport.UpdatedCount, &analyticsImport.SkippedCount,
+		&analyticsImport.FailedCount, &analyticsImport.ErrorMessage, &analyticsImport.CreatedByUserID,
+		&analyticsImport.StartedAt, &analyticsImport.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &analyticsImport, nil
+}
+
+// CreateImport records the start of an import
+func (r *pgxAnalyticsImportRepository) CreateImport(ctx context.Context, analyticsImport *domain.AnalyticsImport) error {
+	query := `
+		INSERT INTO analytics_imports (kind, source, status, created_by_user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING import_id, started_at`
+
+	err := r.db.QueryRow(ctx, query,
+		analyticsImport.Kind, analyticsImport.Source, analyticsImport.Status, analyticsImport.CreatedByUserID,
+	).Scan(&analyticsImport.ImportID, &analyticsImport.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create analytics import: %w", err)
+	}
+	return nil
+}
+
+// FinishImport stores the final status and counts of an import
+func (r *pgxAnalyticsImportRepository) FinishImport(ctx context.Context, analyticsImport *domain.AnalyticsImport) error {
+	query := `
+		UPDATE analytics_imports
+		SET status = $2, inserted_count = $3, updated_count = $4, skipped_count = $5,
+		    failed_count = $6, error_message = $7, completed_at = CURRENT_TIMESTAMP
+		WHERE import_id = $1
+		RETURNING completed_at`
+
+	err := r.db.QueryRow(ctx, query,
+		analyticsImport.ImportID, analyticsImport.Status,
+		analyticsImport.InsertedCount, analyticsImport.UpdatedCount, analyticsImport.SkippedCount,
+		analyticsImport.FailedCount, analyticsImport.ErrorMessage,
+	).Scan(&analyticsImport.CompletedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to finish analytics import: %w", err)
+	}
+	return nil
+}
+
+// AddImportErrors stores per-row import errors
+func (r *pgxAnalyticsImportRepository) AddImportErrors(ctx context.Context, importID int64, importErrors []domain.AnalyticsImportError) error {
+	if len(importErrors) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, 0, len(importErrors))
+	for _, importError := range importErrors {
+		var domainName *string
+		if importError.Domain != "" {
+			domainName = &importError.Domain
+		}
+		rows = append(rows, []interface{}{importID, importError.Line, domainName, importError.Message})
+	}
+
+	_, err := r.db.CopyFrom(ctx,
+		pgx.Identifier{"analytics_import_errors"},
+		[]string{"import_id", "line_number", "domain", "message"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy analytics import errors: %w", err)
+	}
+	return nil
+}
+
+// GetImport retrieves an import with its recorded errors
+func (r *pgxAnalyticsImportRepository) GetImport(ctx context.Context, importID int64) (*domain.AnalyticsImport, error) {
+	query := `SELECT ` + analyticsImportColumns + ` FROM analytics_imports WHERE import_id = $1`
+
+	analyticsImport, err := scanAnalyticsImport(r.db.QueryRow(ctx, query, importID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get analytics import: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT line_number, COALESCE(domain, ''), message
+		FROM analytics_import_errors
+		WHERE import_id = $1
+		ORDER BY line_number, import_error_id`, importID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics import errors: %w", err)
+	}
+	defer rows.Close()
+
+	analyticsImport.Errors = make([]domain.AnalyticsImportError, 0)
+	for rows.Next() {
+		var importError domain.AnalyticsImportError
+		if err := rows.Scan(&importError.Line, &importError.Domain, &importError.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics import error: %w", err)
+		}
+		analyticsImport.Errors = append(analyticsImport.Errors, importError)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating analytics import errors: %w", err)
+	}
+
+	return analyticsImport, nil
+}
+
+// ListImports lists imports, most recent first, with the total number of imports
+func (r *pgxAnalyticsImportRepository) ListImports(ctx context.Context, limit, offset int) ([]*domain.AnalyticsImport, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM analytics_imports`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count analytics imports: %w", err)
+	}
+
+	query := `SELECT ` + analyticsImportColumns + `
+		FROM analytics_imports
+		ORDER BY started_at DESC, import_id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list analytics imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports := make([]*domain.AnalyticsImport, 0)
+	for rows.Next() {
+		analyticsImport, err := scanAnalyticsImport(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan analytics import: %w", err)
+		}
+		imports = append(imports, analyticsImport)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating analytics imports: %w", err)
+	}
+
+	return imports, total, nil
+}
+
+// UpsertPublishers copies the batch into a temporary staging table and upserts it into
+// analytics_publishers in one statement. Rows whose content hash is unchanged are only marked
+// as imported.
+func (r *pgxAnalyticsImportRepository) UpsertPublishers(ctx context.Context, importID int64, records []*domain.AnalyticsPublisherImportRecord) (*AnalyticsUpsertResult, error) {
+	result := &AnalyticsUpsertResult{}
+	if len(records) == 0 {
+		return result, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// JSON columns are staged as text and cast on insert
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE analytics_publishers_import (
+			domain TEXT NOT NULL, description TEXT, favicon_image_url TEXT, screenshot_image_url TEXT,
+			affiliate_networks TEXT, country_rankings TEXT, keywords TEXT, verticals TEXT, verticals_v2 TEXT,
+			partner_information TEXT, partners TEXT, related_publishers TEXT, social_media TEXT, live_urls TEXT,
+			known BOOLEAN NOT NULL, relevance DOUBLE PRECISION NOT NULL, traffic_score DOUBLE PRECISION NOT NULL,
+			promotype TEXT, additional_data TEXT, content_hash TEXT NOT NULL, metrics_hash TEXT NOT NULL,
+			partner_count INTEGER NOT NULL, snapshot_networks TEXT NOT NULL,
+			snapshot_verticals TEXT NOT NULL, snapshot_countries TEXT NOT NULL
+		) ON COMMIT DROP`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create publisher staging table: %w", err)
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		p, s := record.Publisher, record.Snapshot
+		networks, verticals, countries, err := marshalSnapshotLists(s.AffiliateNetworks, s.Verticals, s.Countries)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, []interface{}{
+			p.Domain, p.Description, p.FaviconImageURL, p.ScreenshotImageURL,
+			p.AffiliateNetworks, p.CountryRankings, p.Keywords, p.Verticals, p.VerticalsV2,
+			p.PartnerInformation, p.Partners, p.RelatedPublishers, p.SocialMedia, p.LiveURLs,
+			p.Known, p.Relevance, p.TrafficScore,
+			p.Promotype, p.AdditionalData, record.ContentHash, s.MetricsHash(),
+			s.PartnerCount, networks, verticals, countries,
+		})
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"analytics_publishers_import"},
+		[]string{
+			"domain", "description", "favicon_image_url", "screenshot_image_url",
+			"affiliate_networks", "country_rankings", "keywords", "verticals", "verticals_v2",
+			"partner_information", "partners", "related_publishers", "social_media", "live_urls",
+			"known", "relevance", "traffic_score",
+			"promotype", "additional_data", "content_hash", "metrics_hash",
+			"partner_count", "snapshot_networks", "snapshot_verticals", "snapshot_countries",
+		},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy publishers: %w", err)
+	}
+
+	// xmax is 0 only for freshly inserted rows. Snapshots store the metrics hash and are only
+	// added when the metrics changed, so edits to other columns leave the history untouched.
+	query := `
+		WITH upserted AS (
+			INSERT INTO analytics_publishers (
+				domain, description, favicon_image_url, screenshot_image_url,
+				affiliate_networks, country_rankings, keywords, verticals, verticals_v2,
+				partner_information, partners, related_publishers, social_media, live_urls,
+				known, relevance, traffic_score, promotype, additional_data, content_hash
+			)
+			SELECT domain, description, favicon_image_url, screenshot_image_url,
+				affiliate_networks::jsonb, country_rankings::jsonb, keywords::jsonb, verticals::jsonb, verticals_v2::jsonb,
+				partner_information::jsonb, partners::jsonb, related_publishers::jsonb, social_media::jsonb, live_urls::jsonb,
+				known, relevance, traffic_score, promotype, additional_data::jsonb, content_hash
+			FROM analytics_publishers_import
+			ON CONFLICT (domain) DO UPDATE SET
+				description = EXCLUDED.description,
+				favicon_image_url = EXCLUDED.favicon_image_url,
+				screenshot_image_url = EXCLUDED.screenshot_image_url,
+				affiliate_networks = EXCLUDED.affiliate_networks,
+				country_rankings = EXCLUDED.country_rankings,
+				keywords = EXCLUDED.keywords,
+				verticals = EXCLUDED.verticals,
+				verticals_v2 = EXCLUDED.verticals_v2,
+				partner_information = EXCLUDED.partner_information,
+				partners = EXCLUDED.partners,
+				related_publishers = EXCLUDED.related_publishers,
+				social_media = EXCLUDED.social_media,
+				live_urls = EXCLUDED.live_urls,
+				known = EXCLUDED.known,
+				relevance = EXCLUDED.relevance,
+				traffic_score = EXCLUDED.traffic_score,
+				promotype = EXCLUDED.promotype,
+				additional_data = EXCLUDED.additional_data,
+				content_hash = EXCLUDED.content_hash,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE analytics_publishers.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+			RETURNING id, domain, (xmax = 0) AS inserted
+		), snapshots AS (
+			INSERT INTO analytics_publisher_snapshots (
+				publisher_id, domain, import_id, content_hash, traffic_score, relevance, known, promotype,
+				partner_count, affiliate_networks, verticals, countries
+			)
+			SELECT u.id, u.domain, $1, i.metrics_hash, i.traffic_score, i.relevance, i.known, i.promotype,
+				i.partner_count, i.snapshot_networks::jsonb, i.snapshot_verticals::jsonb, i.snapshot_countries::jsonb
+			FROM upserted u
+			JOIN analytics_publishers_import i ON i.domain = u.domain
+			WHERE i.metrics_hash IS DISTINCT FROM (` + latestPublisherSnapshotHash("u.id") + `)
+		)
+		SELECT COUNT(*) FILTER (WHERE inserted), COUNT(*) FILTER (WHERE NOT inserted)
+		FROM upserted`
+
+	if err := tx.QueryRow(ctx, query, importID).Scan(&result.Inserted, &result.Updated); err != nil {
+		return nil, fmt.Errorf("failed to upsert publishers: %w", err)
+	}
+
+	// Mark every publisher in the batch as seen, including the unchanged ones
+	_, err = tx.Exec(ctx, `
+		UPDATE analytics_publishers p
+		SET last_imported_at = CURRENT_TIMESTAMP
+		FROM analytics_publishers_import i
+		WHERE p.domain = i.domain`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark publishers as imported: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit publishers: %w", err)
+	}
+
+	result.Skipped = len(records) - result.Inserted - result.Updated
+	return result, nil
+}
+
+// UpsertAdvertisers copies the batch into a temporary staging table and upserts it into
+// analytics_advertisers in one statement. Rows whose content hash is unchanged are left alone.
+func (r *pgxAnalyticsImportRepository) UpsertAdvertisers(ctx context.Context, importID int64, records []*domain.AnalyticsAdvertiserImportRecord) (*AnalyticsUpsertResult, error) {
+	result := &AnalyticsUpsertResult{}
+	if len(records) == 0 {
+		return result, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// JSON columns are staged as text and cast on insert
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE analytics_advertisers_import (
+			domain TEXT NOT NULL, description TEXT, favicon_image_url TEXT, screenshot_image_url TEXT,
+			affiliate_networks TEXT, contact_emails TEXT, keywords TEXT, verticals TEXT,
+			partner_information TEXT, related_advertisers TEXT, social_media TEXT, backlinks TEXT,
+			additional_data TEXT, content_hash TEXT NOT NULL,
+			contact_email_count INTEGER NOT NULL, related_advertiser_count INTEGER NOT NULL,
+			snapshot_networks TEXT NOT NULL, snapshot_verticals TEXT NOT NULL
+		) ON COMMIT DROP`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create advertiser staging table: %w", err)
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		a, s := record.Advertiser, record.Snapshot
+		networks, verticals, _, err := marshalSnapshotLists(s.AffiliateNetworks, s.Verticals, nil)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, []interface{}{
+			a.Domain, a.Description, a.FaviconImageURL, a.ScreenshotImageURL,
+			a.AffiliateNetworks, a.ContactEmails, a.Keywords, a.Verticals,
+			a.PartnerInformation, a.RelatedAdvertisers, a.SocialMedia, a.Backlinks,
+			a.AdditionalData, record.ContentHash,
+			s.ContactEmailCount, s.RelatedAdvertiserCount, networks, verticals,
+		})
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"analytics_advertisers_import"},
+		[]string{
+			"domain", "description", "favicon_image_url", "screenshot_image_url",
+			"affiliate_networks", "contact_emails", "keywords", "verticals",
+			"partner_information", "related_advertisers", "social_media", "backlinks",
+			"additional_data", "content_hash",
+			"contact_email_count", "related_advertiser_count", "snapshot_networks", "snapshot_verticals",
+		},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy advertisers: %w", err)
+	}
+
+	// xmax is 0 only for freshly inserted rows
+	query := `
+		WITH upserted AS (
+			INSERT INTO analytics_advertisers (
+				domain, description, favicon_image_url, screenshot_image_url,
+				affiliate_networks, contact_emails, keywords, verticals,
+				partner_information, related_advertisers, social_media, backlinks,
+				additional_data, content_hash
+			)
+			SELECT domain, description, favicon_image_url, screenshot_image_url,
+				affiliate_networks::jsonb, contact_emails::jsonb, keywords::jsonb, verticals::jsonb,
+				partner_information::jsonb, related_advertisers::jsonb, social_media::jsonb, backlinks::jsonb,
+				additional_data::jsonb, content_hash
+			FROM analytics_advertisers_import
+			ON CONFLICT (domain) DO UPDATE SET
+				description = EXCLUDED.description,
+				favicon_image_url = EXCLUDED.favicon_image_url,
+				screenshot_image_url = EXCLUDED.screenshot_image_url,
+				affiliate_networks = EXCLUDED.affiliate_networks,
+				contact_emails = EXCLUDED.contact_emails,
+				keywords = EXCLUDED.keywords,
+				verticals = EXCLUDED.verticals,
+				partner_information = EXCLUDED.partner_information,
+				related_advertisers = EXCLUDED.related_advertisers,
+				social_media = EXCLUDED.social_media,
+				backlinks = EXCLUDED.backlinks,
+				additional_data = EXCLUDED.additional_data,
+				content_hash = EXCLUDED.content_hash,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE analytics_advertisers.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+			RETURNING id, domain, (xmax = 0) AS inserted
+		), snapshots AS (
+			INSERT INTO analytics_advertiser_snapshots (
+				advertiser_id, domain, import_id, content_hash,
+				contact_email_count, related_advertiser_count, affiliate_networks, verticals
+			)
+			SELECT u.id, u.domain, $1, i.content_hash,
+				i.contact_email_count, i.related_advertiser_count, i.snapshot_networks::jsonb, i.snapshot_verticals::jsonb
+			FROM upserted u
+			JOIN analytics_advertisers_import i ON i.domain = u.domain
+		)
+		SELECT COUNT(*) FILTER (WHERE inserted), COUNT(*) FILTER (WHERE NOT inserted)
+		FROM upserted`
+
+	if err := tx.QueryRow(ctx, query, importID).Scan(&result.Inserted, &result.Updated); err != nil {
+		return nil, fmt.Errorf("failed to upsert advertisers: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit advertisers: %w", err)
+	}
+
+	result.Skipped = len(records) - result.Inserted - result.Updated
+	return result, nil
+}
+
+// marshalSnapshotLists encodes snapshot name lists as JSON arrays
+func marshalSnapshotLists(networks, verticals, countries []string) (string, string, string, error) {
+	encoded := make([]string, 3)
+	for i, list := range [][]string{networks, verticals, countries} {
+		if list == nil {
+			list = []string{}
+		}
+		data, err := json.Marshal(list)
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to encode snapshot: %w", err)
+		}
+		encoded[i] = string(data)
+	}
+	return encoded[0], encoded[1], encoded[2], nil
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/affiliate-backend/internal/domain"
@@ -29,6 +31,8 @@ type AnalyticsRepository interface {
 	CreatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error
 	GetPublisherByID(ctx context.Context, id int64) (*domain.AnalyticsPublisher, error)
 	GetPublisherByDomain(ctx context.Context, domainName string) (*domain.AnalyticsPublisher, error)
+	// GetPublishersByDomains retrieves the publishers with the given domains; unknown domains are skipped
+	GetPublishersByDomains(ctx context.Context, domainNames []string) ([]*domain.AnalyticsPublisher, error)
 	UpdatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error
 	DeletePublisher(ctx context.Context, id int64) error
 
@@ -37,6 +41,18 @@ type AnalyticsRepository interface {
 	SearchPublishers(ctx context.Context, query string, limit int) ([]domain.AutocompleteResult, error)
 	SearchBoth(ctx context.Context, query string, limit int) ([]domain.AutocompleteResult, error)
 	AffiliatesSearch(ctx context.Context, domainFilter, country string, partnerDomains []string, verticals []string, limit int, offset int) (*AffiliatesSearchResult, error)
+	DiscoverPublishers(ctx context.Context, query *domain.PublisherSearchQuery) (*AffiliatesSearchResult, error)
+	GetPublisherSearchFacets(ctx context.Context, query *domain.PublisherSearchQuery) (*domain.PublisherSearchFacets, error)
+	FindLookalikeCandidates(ctx context.Context, seed *domain.LookalikeProfile, excludeDomains []string, limit int) ([]*domain.AnalyticsPublisher, error)
+
+	// Publisher history methods
+	CreatePublisherSnapshot(ctx context.Context, snapshot *domain.AnalyticsPublisherSnapshot) error
+	GetPublisherFreshness(ctx context.Context, domainName string) (*domain.PublisherFreshness, error)
+	ListPublisherSnapshots(ctx context.Context, domainName string, query *domain.PublisherHistoryQuery) ([]*domain.AnalyticsPublisherSnapshot, error)
+	GetLatestPublisherSnapshots(ctx context.Context, domainNames []string, perDomain int) (map[string][]*domain.AnalyticsPublisherSnapshot, error)
+
+	// GetExistingPublisherDomains returns which of the given lower case domains exist in analytics_publishers
+	GetExistingPublisherDomains(ctx context.Context, domainNames []string) (map[string]bool, error)
 }
 
 // analyticsRepository implements AnalyticsRepository
@@ -156,6 +172,340 @@ func (r *analyticsRepository) AffiliatesSearch(ctx context.Context, domainFilter
 	}, nil
 }
 
+// Full text expressions matching the idx_analytics_publishers_*_text indexes
+const (
+	publisherDomainVector      = `to_tsvector('english', domain)`
+	publisherDescriptionVector = `to_tsvector('english', COALESCE(description, ''))`
+	publisherKeywordsVector    = `jsonb_to_tsvector('english', COALESCE(keywords->'value', '[]'::jsonb), '["string"]')`
+)
+
+// publisherSearchFilter builds the WHERE clause shared by the discovery search and its facets.
+// When the query has text, the returned args start with the tsquery text ($1) and the domain
+// ILIKE pattern ($2) so the ranking expression can reference them.
+func publisherSearchFilter(query *domain.PublisherSearchQuery) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	jsonArg := func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return arg(string(data)) + "::jsonb", nil
+	}
+
+	if query.Text != "" {
+		tsquery := fmt.Sprintf("websearch_to_tsquery('english', %s)", arg(query.Text))
+		pattern := arg("%" + escapeLikePattern(query.Text) + "%")
+		conditions = append(conditions, fmt.Sprintf(`(%s @@ %s OR %s @@ %s OR %s @@ %s OR domain ILIKE %s)`,
+			publisherDomainVector, tsquery,
+			publisherDescriptionVector, tsquery,
+			publisherKeywordsVector, tsquery,
+			pattern))
+	}
+
+	if len(query.Promotypes) > 0 {
+		conditions = append(conditions, fmt.Sprintf("LOWER(promotype) = ANY(%s)", arg(query.Promotypes)))
+	}
+	if query.Known != nil {
+		conditions = append(conditions, fmt.Sprintf("known = %s", arg(*query.Known)))
+	}
+	if query.MinTrafficScore != nil {
+		conditions = append(conditions, fmt.Sprintf("traffic_score >= %s", arg(*query.MinTrafficScore)))
+	}
+
+	// Containment on the whole column can use the GIN indexes on affiliate_networks and verticals_v2
+	if len(query.Networks) > 0 {
+		networkConditions := make([]string, len(query.Networks))
+		for i, network := range query.Networks {
+			value, err := jsonArg(map[string]interface{}{"value": []string{network}})
+			if err != nil {
+				return "", nil, err
+			}
+			networkConditions[i] = "affiliate_networks @> " + value
+		}
+		conditions = append(conditions, "("+strings.Join(networkConditions, " OR ")+")")
+	}
+	if len(query.Verticals) > 0 {
+		verticalConditions := make([]string, len(query.Verticals))
+		for i, vertical := range query.Verticals {
+			value, err := jsonArg(map[string]interface{}{"value": []map[string]string{{"name": vertical}}})
+			if err != nil {
+				return "", nil, err
+			}
+			verticalConditions[i] = "verticals_v2 @> " + value
+		}
+		conditions = append(conditions, "("+strings.Join(verticalConditions, " OR ")+")")
+	}
+	if query.Country != "" {
+		value, err := jsonArg([]map[string]string{{"countryCode": query.Country}})
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, "country_rankings->'value' @> "+value)
+	}
+
+	whereClause := "WHERE 1=1"
+	if len(conditions) > 0 {
+		whereClause += " AND " + strings.Join(conditions, " AND ")
+	}
+	return whereClause, args, nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards in a user supplied value
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// DiscoverPublishers runs a ranked publisher discovery search
+func (r *analyticsRepository) DiscoverPublishers(ctx context.Context, query *domain.PublisherSearchQuery) (*AffiliatesSearchResult, error) {
+	whereClause, args, err := publisherSearchFilter(query)
+	if err != nil {
+		return nil, fmt.Errorf("error building publisher search: %w", err)
+	}
+
+	var total int64
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM analytics_publishers %s`, whereClause)
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("error counting publishers: %w", err)
+	}
+
+	var orderBy string
+	switch query.Sort {
+	case domain.PublisherSortTraffic:
+		orderBy = "traffic_score DESC NULLS LAST, relevance DESC NULLS LAST"
+	case domain.PublisherSortPartners:
+		orderBy = "COALESCE((partners->'count')::int, 0) DESC, traffic_score DESC NULLS LAST"
+	default:
+		orderBy = "relevance DESC NULLS LAST, traffic_score DESC NULLS LAST"
+		if query.Text != "" {
+			// Domain matches weigh most, then keywords, then the description; $1 and $2 are the
+			// tsquery text and domain pattern added first by publisherSearchFilter
+			orderBy = fmt.Sprintf(`(
+				ts_rank(%s, websearch_to_tsquery('english', $1)) * 4
+				+ ts_rank(%s, websearch_to_tsquery('english', $1)) * 2
+				+ ts_rank(%s, websearch_to_tsquery('english', $1))
+				+ CASE WHEN domain ILIKE $2 THEN 1 ELSE 0 END
+			) DESC, `, publisherDomainVector, publisherKeywordsVector, publisherDescriptionVector) + orderBy
+		}
+	}
+
+	dataQuery := fmt.Sprintf(`
+        SELECT 
+            id, domain, description, favicon_image_url, screenshot_image_url,
+            affiliate_networks, country_rankings, keywords, verticals, verticals_v2,
+            partner_information, partners, related_publishers, social_media, live_urls,
+            known, relevance, traffic_score, promotype, additional_data,
+            created_at, updated_at
+        FROM analytics_publishers 
+        %s
+        ORDER BY %s, id
+        LIMIT $%d OFFSET $%d`, whereClause, orderBy, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, dataQuery, append(args, query.Limit, query.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching publishers: %w", err)
+	}
+	defer rows.Close()
+
+	publishers := make([]*domain.AnalyticsPublisher, 0)
+	for rows.Next() {
+		var p domain.AnalyticsPublisher
+		err := rows.Scan(
+			&p.ID, &p.Domain,
+			&p.Description, &p.FaviconImageURL, &p.ScreenshotImageURL,
+			&p.AffiliateNetworks, &p.CountryRankings, &p.Keywords, &p.Verticals, &p.VerticalsV2,
+			&p.PartnerInformation, &p.Partners, &p.RelatedPublishers, &p.SocialMedia, &p.LiveURLs,
+			&p.Known, &p.Relevance, &p.TrafficScore, &p.Promotype, &p.AdditionalData,
+			&p.CreatedAt, &p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning publisher row: %w", err)
+		}
+		publishers = append(publishers, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return &AffiliatesSearchResult{
+		Data:  publishers,
+		Total: total,
+	}, nil
+}
+
+// GetPublisherSearchFacets counts the most common verticals, countries and affiliate networks
+// among all publishers matching the search filters
+func (r *analyticsRepository) GetPublisherSearchFacets(ctx context.Context, query *domain.PublisherSearchQuery) (*domain.PublisherSearchFacets, error) {
+	whereClause, args, err := publisherSearchFilter(query)
+	if err != nil {
+		return nil, fmt.Errorf("error building publisher search: %w", err)
+	}
+
+	// jsonb_array_elements fails on non-arrays, so malformed rows contribute nothing
+	asArray := func(expr string) string {
+		return fmt.Sprintf("CASE WHEN jsonb_typeof(%s) = 'array' THEN %s ELSE '[]'::jsonb END", expr, expr)
+	}
+
+	facetQuery := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT id, verticals_v2, country_rankings, affiliate_networks
+			FROM analytics_publishers
+			%s
+		),
+		facet_values AS (
+			SELECT 'verticals' AS facet, m.id, v->>'name' AS value
+			FROM matched m CROSS JOIN LATERAL jsonb_array_elements(%s) v
+			UNION ALL
+			SELECT 'countries', m.id, UPPER(c->>'countryCode')
+			FROM matched m CROSS JOIN LATERAL jsonb_array_elements(%s) c
+			UNION ALL
+			SELECT 'networks', m.id, n #>> '{}'
+			FROM matched m CROSS JOIN LATERAL jsonb_array_elements(%s) n
+		),
+		counted AS (
+			SELECT facet, value, COUNT(DISTINCT id) AS publisher_count,
+			       ROW_NUMBER() OVER (PARTITION BY facet ORDER BY COUNT(DISTINCT id) DESC, value) AS position
+			FROM facet_values
+			WHERE value IS NOT NULL AND value <> ''
+			GROUP BY facet, value
+		)
+		SELECT facet, value, publisher_count
+		FROM counted
+		WHERE position <= $%d
+		ORDER BY facet, position`,
+		whereClause,
+		asArray("m.verticals_v2->'value'"),
+		asArray("m.country_rankings->'value'"),
+		asArray("m.affiliate_networks->'value'"),
+		len(args)+1)
+
+	rows, err := r.db.Query(ctx, facetQuery, append(args, query.FacetLimit)...)
+	if err != nil {
+		return nil, fmt.Errorf("error counting publisher facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &domain.PublisherSearchFacets{
+		Verticals: make([]domain.FacetCount, 0),
+		Countries: make([]domain.FacetCount, 0),
+		Networks:  make([]domain.FacetCount, 0),
+	}
+	for rows.Next() {
+		var facet string
+		var count domain.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Count); err != nil {
+			return nil, fmt.Errorf("error scanning publisher facet row: %w", err)
+		}
+		switch facet {
+		case "verticals":
+			facets.Verticals = append(facets.Verticals, count)
+		case "countries":
+			facets.Countries = append(facets.Countries, count)
+		case "networks":
+			facets.Networks = append(facets.Networks, count)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return facets, nil
+}
+
+// maxLookalikeSeedVerticals limits how many of the seed's verticals are used to find candidates
+const maxLookalikeSeedVerticals = 10
+
+// FindLookalikeCandidates returns publishers that might resemble the seed profile: its related
+// publishers, publishers listing one of the seed domains as related, and publishers sharing one
+// of its strongest verticals. Related publishers come first, then by traffic score.
+func (r *analyticsRepository) FindLookalikeCandidates(ctx context.Context, seed *domain.LookalikeProfile, excludeDomains []string, limit int) ([]*domain.AnalyticsPublisher, error) {
+	seedDomains := make([]string, 0, len(seed.Domains))
+	for seedDomain := range seed.Domains {
+		seedDomains = append(seedDomains, seedDomain)
+	}
+	related := make([]string, 0, len(seed.Related))
+	for relatedDomain := range seed.Related {
+		related = append(related, relatedDomain)
+	}
+	if excludeDomains == nil {
+		excludeDomains = []string{}
+	}
+
+	args := []interface{}{excludeDomains, related, seedDomains}
+	candidateConditions := []string{
+		"LOWER(domain) = ANY($2)",
+		"related_publishers->'value' ?| $3",
+	}
+
+	verticals := make([]string, 0, len(seed.Verticals))
+	for vertical := range seed.Verticals {
+		verticals = append(verticals, vertical)
+	}
+	sort.Slice(verticals, func(i, j int) bool {
+		if seed.Verticals[verticals[i]] != seed.Verticals[verticals[j]] {
+			return seed.Verticals[verticals[i]] > seed.Verticals[verticals[j]]
+		}
+		return verticals[i] < verticals[j]
+	})
+	if len(verticals) > maxLookalikeSeedVerticals {
+		verticals = verticals[:maxLookalikeSeedVerticals]
+	}
+	for _, vertical := range verticals {
+		value, err := json.Marshal(map[string]interface{}{"value": []map[string]string{{"name": vertical}}})
+		if err != nil {
+			return nil, fmt.Errorf("error building lookalike query: %w", err)
+		}
+		args = append(args, string(value))
+		candidateConditions = append(candidateConditions, fmt.Sprintf("verticals_v2 @> $%d::jsonb", len(args)))
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+        SELECT 
+            id, domain, description, favicon_image_url, screenshot_image_url,
+            affiliate_networks, country_rankings, keywords, verticals, verticals_v2,
+            partner_information, partners, related_publishers, social_media, live_urls,
+            known, relevance, traffic_score, promotype, additional_data,
+            created_at, updated_at
+        FROM analytics_publishers
+        WHERE LOWER(domain) <> ALL($1) AND LOWER(domain) <> ALL($3)
+          AND (%s)
+        ORDER BY (LOWER(domain) = ANY($2)) DESC, traffic_score DESC NULLS LAST, id
+        LIMIT $%d`, strings.Join(candidateConditions, " OR "), len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding lookalike publishers: %w", err)
+	}
+	defer rows.Close()
+
+	publishers := make([]*domain.AnalyticsPublisher, 0)
+	for rows.Next() {
+		var p domain.AnalyticsPublisher
+		err := rows.Scan(
+			&p.ID, &p.Domain,
+			&p.Description, &p.FaviconImageURL, &p.ScreenshotImageURL,
+			&p.AffiliateNetworks, &p.CountryRankings, &p.Keywords, &p.Verticals, &p.VerticalsV2,
+			&p.PartnerInformation, &p.Partners, &p.RelatedPublishers, &p.SocialMedia, &p.LiveURLs,
+			&p.Known, &p.Relevance, &p.TrafficScore, &p.Promotype, &p.AdditionalData,
+			&p.CreatedAt, &p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning publisher row: %w", err)
+		}
+		publishers = append(publishers, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return publishers, nil
+}
+
 // NewAnalyticsRepository creates a new analytics repository
 func NewAnalyticsRepository(db *pgxpool.Pool) AnalyticsRepository {
 	return &analyticsRepository{db: db}
@@ -352,6 +702,49 @@ func (r *analyticsRepository) GetPublisherByDomain(ctx context.Context, domainNa
 	return &publisher, nil
 }
 
+// GetPublishersByDomains retrieves the publishers with the given domains in one query
+func (r *analyticsRepository) GetPublishersByDomains(ctx context.Context, domainNames []string) ([]*domain.AnalyticsPublisher, error) {
+	publishers := make([]*domain.AnalyticsPublisher, 0, len(domainNames))
+	if len(domainNames) == 0 {
+		return publishers, nil
+	}
+
+	query := `
+		SELECT id, domain, description, favicon_image_url, screenshot_image_url,
+			   affiliate_networks, country_rankings, keywords, verticals, verticals_v2,
+			   partner_information, partners, related_publishers, social_media, live_urls,
+			   known, relevance, traffic_score, promotype, additional_data,
+			   created_at, updated_at
+		FROM analytics_publishers
+		WHERE domain = ANY($1)
+		ORDER BY domain`
+
+	rows, err := r.db.Query(ctx, query, domainNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publishers by domains: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publisher domain.AnalyticsPublisher
+		if err := rows.Scan(
+			&publisher.ID, &publisher.Domain, &publisher.Description, &publisher.FaviconImageURL, &publisher.ScreenshotImageURL,
+			&publisher.AffiliateNetworks, &publisher.CountryRankings, &publisher.Keywords, &publisher.Verticals, &publisher.VerticalsV2,
+			&publisher.PartnerInformation, &publisher.Partners, &publisher.RelatedPublishers, &publisher.SocialMedia, &publisher.LiveURLs,
+			&publisher.Known, &publisher.Relevance, &publisher.TrafficScore, &publisher.Promotype, &publisher.AdditionalData,
+			&publisher.CreatedAt, &publisher.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan publisher: %w", err)
+		}
+		publishers = append(publishers, &publisher)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating publishers: %w", err)
+	}
+
+	return publishers, nil
+}
+
 func (r *analyticsRepository) UpdatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error {
 	query := `
 		UPDATE analytics_publishers SET
@@ -494,3 +887,176 @@ func (r *analyticsRepository) SearchBoth(ctx context.Context, query string, limi
 
 	return results, rows.Err()
 }
+
+const publisherSnapshotColumns = `snapshot_id, publisher_id, domain, import_id, content_hash, traffic_score, relevance,
+	known, promotype, partner_count, affiliate_networks, verticals, countries, captured_at`
+
+func scanPublisherSnapshot(row pgx.Row) (*domain.AnalyticsPublisherSnapshot, error) {
+	var snapshot domain.AnalyticsPublisherSnapshot
+	err := row.Scan(
+		&snapshot.SnapshotID, &snapshot.PublisherID, &snapshot.Domain, &snapshot.ImportID, &snapshot.ContentHash,
+		&snapshot.TrafficScore, &snapshot.Relevance, &snapshot.Known, &snapshot.Promotype, &snapshot.PartnerCount,
+		&snapshot.AffiliateNetworks, &snapshot.Verticals, &snapshot.Countries, &snapshot.CapturedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// CreatePublisherSnapshot records the current metrics of a publisher outside of a bulk import.
+// Nothing is recorded when the metrics hash equals the latest snapshot's, leaving SnapshotID zero.
+func (r *analyticsRepository) CreatePublisherSnapshot(ctx context.Context, snapshot *domain.AnalyticsPublisherSnapshot) error {
+	networks, verticals, countries, err := marshalSnapshotLists(snapshot.AffiliateNetworks, snapshot.Verticals, snapshot.Countries)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO analytics_publisher_snapshots (
+			publisher_id, domain, import_id, content_hash, traffic_score, relevance, known, promotype,
+			partner_count, affiliate_networks, verticals, countries
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11::jsonb, $12::jsonb
+		WHERE $4 IS DISTINCT FROM (` + latestPublisherSnapshotHash("$1") + `)
+		RETURNING snapshot_id, captured_at`
+
+	err = r.db.QueryRow(ctx, query,
+		snapshot.PublisherID, snapshot.Domain, snapshot.ImportID, snapshot.ContentHash,
+		snapshot.TrafficScore, snapshot.Relevance, snapshot.Known, snapshot.Promotype,
+		snapshot.PartnerCount, networks, verticals, countries,
+	).Scan(&snapshot.SnapshotID, &snapshot.CapturedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to create publisher snapshot: %w", err)
+	}
+	return nil
+}
+
+// latestPublisherSnapshotHash returns a subquery selecting the metrics hash of the latest
+// snapshot of the publisher identified by the given SQL expression
+func latestPublisherSnapshotHash(publisherID string) string {
+	return `SELECT ls.content_hash FROM analytics_publisher_snapshots ls
+		WHERE ls.publisher_id = ` + publisherID + `
+		ORDER BY ls.captured_at DESC, ls.snapshot_id DESC LIMIT 1`
+}
+
+// GetPublisherFreshness returns when a publisher was last imported and last changed
+func (r *analyticsRepository) GetPublisherFreshness(ctx context.Context, domainName string) (*domain.PublisherFreshness, error) {
+	query := `
+		SELECT p.last_imported_at,
+		       (SELECT MAX(s.captured_at) FROM analytics_publisher_snapshots s WHERE s.publisher_id = p.id)
+		FROM analytics_publishers p
+		WHERE p.domain = $1`
+
+	var freshness domain.PublisherFreshness
+	err := r.db.QueryRow(ctx, query, domainName).Scan(&freshness.LastImportedAt, &freshness.LastChangedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get publisher freshness: %w", err)
+	}
+	return &freshness, nil
+}
+
+// ListPublisherSnapshots returns the most recent snapshots of a publisher within the query range, oldest first
+func (r *analyticsRepository) ListPublisherSnapshots(ctx context.Context, domainName string, query *domain.PublisherHistoryQuery) ([]*domain.AnalyticsPublisherSnapshot, error) {
+	sqlQuery := `
+		SELECT * FROM (
+			SELECT ` + publisherSnapshotColumns + `
+			FROM analytics_publisher_snapshots
+			WHERE domain = $1
+			  AND ($2::timestamptz IS NULL OR captured_at >= $2)
+			  AND ($3::timestamptz IS NULL OR captured_at <= $3)
+			ORDER BY captured_at DESC, snapshot_id DESC
+			LIMIT $4
+		) recent
+		ORDER BY captured_at, snapshot_id`
+
+	rows, err := r.db.Query(ctx, sqlQuery, domainName, query.From, query.To, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publisher snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := make([]*domain.AnalyticsPublisherSnapshot, 0)
+	for rows.Next() {
+		snapshot, err := scanPublisherSnapshot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan publisher snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating publisher snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// GetLatestPublisherSnapshots returns up to perDomain snapshots for each domain, newest first
+func (r *analyticsRepository) GetLatestPublisherSnapshots(ctx context.Context, domainNames []string, perDomain int) (map[string][]*domain.AnalyticsPublisherSnapshot, error) {
+	result := make(map[string][]*domain.AnalyticsPublisherSnapshot)
+	if len(domainNames) == 0 || perDomain < 1 {
+		return result, nil
+	}
+
+	query := `
+		SELECT ` + publisherSnapshotColumns + `
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY domain ORDER BY captured_at DESC, snapshot_id DESC) AS position
+			FROM analytics_publisher_snapshots
+			WHERE domain = ANY($1)
+		) ranked
+		WHERE position <= $2
+		ORDER BY domain, position`
+
+	rows, err := r.db.Query(ctx, query, domainNames, perDomain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest publisher snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		snapshot, err := scanPublisherSnapshot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan publisher snapshot: %w", err)
+		}
+		result[snapshot.Domain] = append(result[snapshot.Domain], snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating publisher snapshots: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetExistingPublisherDomains returns which of the given lower case domains exist in analytics_publishers
+func (r *analyticsRepository) GetExistingPublisherDomains(ctx context.Context, domainNames []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(domainNames) == 0 {
+		return existing, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT LOWER(domain) FROM analytics_publishers WHERE LOWER(domain) = ANY($1)`, domainNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing publisher domains: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var domainName string
+		if err := rows.Scan(&domainName); err != nil {
+			return nil, fmt.Errorf("failed to scan publisher domain: %w", err)
+		}
+		existing[domainName] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating publisher domains: %w", err)
+	}
+
+	return existing, nil
+}
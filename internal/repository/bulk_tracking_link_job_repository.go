@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BulkTrackingLinkJobRepository defines the interface for bulk tracking link job data access
+type BulkTrackingLinkJobRepository interface {
+	// CreateJob creates a running job with its pending items
+	CreateJob(ctx context.Context, job *domain.BulkTrackingLinkJob, items []*domain.BulkTrackingLinkJobItem) error
+	GetJob(ctx context.Context, jobID int64) (*domain.BulkTrackingLinkJob, error)
+	// ListJobs lists the jobs of an organization, most recent first, with the total count
+	ListJobs(ctx context.Context, organizationID int64, limit, offset int) ([]*domain.BulkTrackingLinkJob, int, error)
+	// ListRunningJobs lists the jobs of all organizations that have not finished
+	ListRunningJobs(ctx context.Context) ([]*domain.BulkTrackingLinkJob, error)
+	// ListJobItems lists the items of a job in creation order, optionally with a given status
+	ListJobItems(ctx context.Context, jobID int64, status string) ([]*domain.BulkTrackingLinkJobItem, error)
+	// AcquireJobLease gives a running job to owner until the given time unless another owner holds an
+	// unexpired lease; items left processing by a previous owner are made pending again
+	AcquireJobLease(ctx context.Context, jobID int64, owner string, until time.Time) (bool, error)
+	// RenewJobLease extends the lease of owner; false when the job is no longer leased to it
+	RenewJobLease(ctx context.Context, jobID int64, owner string, until time.Time) (bool, error)
+	// ReleaseJobLease gives up the lease of owner so that another instance can resume the job
+	ReleaseJobLease(ctx context.Context, jobID int64, owner string) error
+	// ClaimNextItem marks the first pending item of a job processing and returns it, or nil when none is left
+	ClaimNextItem(ctx context.Context, jobID int64) (*domain.BulkTrackingLinkJobItem, error)
+	// FinishItem records the outcome of a claimed item and counts it on its job
+	FinishItem(ctx context.Context, item *domain.BulkTrackingLinkJobItem) error
+	// CompleteJob sets the final status of a job and releases its lease
+	CompleteJob(ctx context.Context, job *domain.BulkTrackingLinkJob) error
+}
+
+// pgxBulkTrackingLinkJobRepository implements BulkTrackingLinkJobRepository using pgx
+type pgxBulkTrackingLinkJobRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxBulkTrackingLinkJobRepository creates a new bulk tracking link job repository
+func NewPgxBulkTrackingLinkJobRepository(db *pgxpool.Pool) BulkTrackingLinkJobRepository {
+	return &pgxBulkTrackingLinkJobRepository{db: db}
+}
+
+const bulkTrackingLinkJobColumns = `job_id, organization_id, campaign_id, status, all_visible_affiliates, template,
+	is_encrypt_parameters, is_redirect_link, concurrency, total_count, succeeded_count, failed_count, error_message,
+	created_by_user_id, created_at, completed_at`
+
+func scanBulkTrackingLinkJob(row pgx.Row) (*domain.BulkTrackingLinkJob, error) {
+	job := &domain.BulkTrackingLinkJob{}
+	var template []byte
+	err := row.Scan(
+		&job.JobID, &job.OrganizationID, &job.CampaignID, &job.Status, &job.AllVisibleAffiliates, &template,
+		&job.IsEncryptParameters, &job.IsRedirectLink, &job.Concurrency, &job.TotalCount, &job.SucceededCount,
+		&job.FailedCount, &job.ErrorMessage, &job.CreatedByUserID, &job.CreatedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan bulk tracking link job: %w", err)
+	}
+	if err := json.Unmarshal(template, &job.Template); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bulk tracking link template: %w", err)
+	}
+	return job, nil
+}
+
+// CreateJob creates a running job with its pending items in one transaction
+func (r *pgxBulkTrackingLinkJobRepository) CreateJob(ctx context.Context, job *domain.BulkTrackingLinkJob, items []*domain.BulkTrackingLinkJobItem) error {
+	template, err := json.Marshal(job.Template)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk tracking link template: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	job.TotalCount = len(items)
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bulk_tracking_link_jobs (organization_id, campaign_id, status, all_visible_affiliates, template,
+			is_encrypt_parameters, is_redirect_link, concurrency, total_count, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING job_id, created_at`,
+		job.OrganizationID, job.CampaignID, job.Status, job.AllVisibleAffiliates, string(template),
+		job.IsEncryptParameters, job.IsRedirectLink, job.Concurrency, job.TotalCount, job.CreatedByUserID,
+	).Scan(&job.JobID, &job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bulk tracking link job: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, item := range items {
+		item.JobID = job.JobID
+		parameters, err := json.Marshal(item.Parameters)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bulk tracking link parameters: %w", err)
+		}
+		batch.Queue(`
+			INSERT INTO bulk_tracking_link_job_items (job_id, affiliate_id, affiliate_name, status, parameters, error_message, processed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING item_id`,
+			item.JobID, item.AffiliateID, item.AffiliateName, item.Status, string(parameters), item.ErrorMessage, item.ProcessedAt,
+		).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&item.ItemID)
+		})
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to create bulk tracking link job items: %w", err)
+	}
+
+	// Items that failed up front (such as unknown affiliates) are counted right away
+	job.FailedCount = 0
+	for _, item := range items {
+		if item.Status == domain.BulkLinkItemStatusFailed {
+			job.FailedCount++
+		}
+	}
+	if job.FailedCount > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE bulk_tracking_link_jobs SET failed_count = $2 WHERE job_id = $1`, job.JobID, job.FailedCount); err != nil {
+			return fmt.Errorf("failed to count failed bulk tracking link job items: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetJob retrieves a job by ID
+func (r *pgxBulkTrackingLinkJobRepository) GetJob(ctx context.Context, jobID int64) (*domain.BulkTrackingLinkJob, error) {
+	return scanBulkTrackingLinkJob(r.db.QueryRow(ctx, `SELECT `+bulkTrackingLinkJobColumns+` FROM bulk_tracking_link_jobs WHERE job_id = $1`, jobID))
+}
+
+// ListJobs lists the jobs of an organization, most recent first
+func (r *pgxBulkTrackingLinkJobRepository) ListJobs(ctx context.Context, organizationID int64, limit, offset int) ([]*domain.BulkTrackingLinkJob, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM bulk_tracking_link_jobs WHERE organization_id = $1`, organizationID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bulk tracking link jobs: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+bulkTrackingLinkJobColumns+`
+		FROM bulk_tracking_link_jobs
+		WHERE organization_id = $1
+		ORDER BY created_at DESC, job_id DESC
+		LIMIT $2 OFFSET $3`, organizationID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bulk tracking link jobs: %w", err)
+	}
+	jobs, err := collectBulkTrackingLinkJobs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// ListRunningJobs lists the jobs that have not finished
+func (r *pgxBulkTrackingLinkJobRepository) ListRunningJobs(ctx context.Context) ([]*domain.BulkTrackingLinkJob, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+bulkTrackingLinkJobColumns+` FROM bulk_tracking_link_jobs WHERE status = $1 ORDER BY job_id`,
+		domain.BulkLinkJobStatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running bulk tracking link jobs: %w", err)
+	}
+	return collectBulkTrackingLinkJobs(rows)
+}
+
+func collectBulkTrackingLinkJobs(rows pgx.Rows) ([]*domain.BulkTrackingLinkJob, error) {
+	defer rows.Close()
+	jobs := make([]*domain.BulkTrackingLinkJob, 0)
+	for rows.Next() {
+		job, err := scanBulkTrackingLinkJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bulk tracking link jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ListJobItems lists the items of a job in creation order
+func (r *pgxBulkTrackingLinkJobRepository) ListJobItems(ctx context.Context, jobID int64, status string) ([]*domain.BulkTrackingLinkJobItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+bulkTrackingLinkJobItemColumns+`
+		FROM bulk_tracking_link_job_items
+		WHERE job_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY item_id`, jobID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bulk tracking link job items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*domain.BulkTrackingLinkJobItem, 0)
+	for rows.Next() {
+		item, err := scanBulkTrackingLinkJobItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bulk tracking link job items: %w", err)
+	}
+	return items, nil
+}
+
+const bulkTrackingLinkJobItemColumns = `item_id, job_id, affiliate_id, affiliate_name, status, parameters, tracking_link_id,
+	tracking_url, is_new, error_message, processed_at`
+
+func scanBulkTrackingLinkJobItem(row pgx.Row) (*domain.BulkTrackingLinkJobItem, error) {
+	item := &domain.BulkTrackingLinkJobItem{}
+	var parameters []byte
+	err := row.Scan(
+		&item.ItemID, &item.JobID, &item.AffiliateID, &item.AffiliateName, &item.Status, &parameters,
+		&item.TrackingLinkID, &item.TrackingURL, &item.IsNew, &item.ErrorMessage, &item.ProcessedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan bulk tracking link job item: %w", err)
+	}
+	if err := json.Unmarshal(parameters, &item.Parameters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bulk tracking link parameters: %w", err)
+	}
+	return item, nil
+}
+
+// AcquireJobLease leases a running job whose lease is free, expired or already held by owner. Taking
+// over from another owner makes the items it left processing pending again, in the same transaction.
+func (r *pgxBulkTrackingLinkJobRepository) AcquireJobLease(ctx context.Context, jobID int64, owner string, until time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previousOwner *string
+	err = tx.QueryRow(ctx, `
+		WITH previous AS (
+			SELECT job_id, lease_owner FROM bulk_tracking_link_jobs WHERE job_id = $1 FOR UPDATE
+		)
+		UPDATE bulk_tracking_link_jobs j
+		SET lease_owner = $2, lease_until = $3
+		FROM previous p
+		WHERE j.job_id = p.job_id AND j.status = 'running'
+		  AND (j.lease_owner IS NULL OR j.lease_owner = $2 OR j.lease_until < CURRENT_TIMESTAMP)
+		RETURNING p.lease_owner`, jobID, owner, until,
+	).Scan(&previousOwner)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to lease bulk tracking link job: %w", err)
+	}
+
+	if previousOwner != nil && *previousOwner != owner {
+		_, err := tx.Exec(ctx, `UPDATE bulk_tracking_link_job_items SET status = 'pending' WHERE job_id = $1 AND status = 'processing'`, jobID)
+		if err != nil {
+			return false, fmt.Errorf("failed to requeue bulk tracking link job items: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// RenewJobLease extends a lease still held by owner
+func (r *pgxBulkTrackingLinkJobRepository) RenewJobLease(ctx context.Context, jobID int64, owner string, until time.Time) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE bulk_tracking_link_jobs SET lease_until = $3
+		WHERE job_id = $1 AND lease_owner = $2 AND status = 'running'`, jobID, owner, until)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew bulk tracking link job lease: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// ReleaseJobLease clears a lease held by owner
+func (r *pgxBulkTrackingLinkJobRepository) ReleaseJobLease(ctx context.Context, jobID int64, owner string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE bulk_tracking_link_jobs SET lease_owner = NULL, lease_until = NULL
+		WHERE job_id = $1 AND lease_owner = $2`, jobID, owner)
+	if err != nil {
+		return fmt.Errorf("failed to release bulk tracking link job lease: %w", err)
+	}
+	return nil
+}
+
+// ClaimNextItem moves the first pending item of a job to processing; concurrent claims skip the
+// items locked by each other
+func (r *pgxBulkTrackingLinkJobRepository) ClaimNextItem(ctx context.Context, jobID int64) (*domain.BulkTrackingLinkJobItem, error) {
+	item, err := scanBulkTrackingLinkJobItem(r.db.QueryRow(ctx, `
+		UPDATE bulk_tracking_link_job_items
+		SET status = 'processing'
+		WHERE item_id = (
+			SELECT item_id FROM bulk_tracking_link_job_items
+			WHERE job_id = $1 AND status = 'pending'
+			ORDER BY item_id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+bulkTrackingLinkJobItemColumns, jobID))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return item, err
+}
+
+// FinishItem records the outcome of a processing item and counts it on its job. Items that are no
+// longer processing are left alone, so that an item is counted once.
+func (r *pgxBulkTrackingLinkJobRepository) FinishItem(ctx context.Context, item *domain.BulkTrackingLinkJobItem) error {
+	query := `
+		WITH finished AS (
+			UPDATE bulk_tracking_link_job_items
+			SET status = $2, tracking_link_id = $3, tracking_url = $4, is_new = $5, error_message = $6, processed_at = $7
+			WHERE item_id = $1 AND status = 'processing'
+			RETURNING job_id, status
+		)
+		UPDATE bulk_tracking_link_jobs j
+		SET succeeded_count = j.succeeded_count + CASE WHEN f.status = 'succeeded' THEN 1 ELSE 0 END,
+			failed_count = j.failed_count + CASE WHEN f.status = 'failed' THEN 1 ELSE 0 END
+		FROM finished f
+		WHERE j.job_id = f.job_id`
+	_, err := r.db.Exec(ctx, query, item.ItemID, item.Status, item.TrackingLinkID, item.TrackingURL, item.IsNew,
+		item.ErrorMessage, item.ProcessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish bulk tracking link job item: %w", err)
+	}
+	return nil
+}
+
+// CompleteJob sets the final status of a job, releases its lease and reloads its counts
+func (r *pgxBulkTrackingLinkJobRepository) CompleteJob(ctx context.Context, job *domain.BulkTrackingLinkJob) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE bulk_tracking_link_jobs
+		SET status = $2, error_message = $3, completed_at = CURRENT_TIMESTAMP, lease_owner = NULL, lease_until = NULL
+		WHERE job_id = $1
+		RETURNING succeeded_count, failed_count, completed_at`,
+		job.JobID, job.Status, job.ErrorMessage,
+	).Scan(&job.SucceededCount, &job.FailedCount, &job.CompletedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to complete bulk tracking link job: %w", err)
+	}
+	return nil
+}
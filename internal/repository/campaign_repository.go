@@ -139,7 +139,7 @@ func (r *pgxCampaignRepository) GetCampaignByID(ctx context.Context, id int64) (
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("campaign not found: %w", err)
+			return nil, fmt.Errorf("campaign not found: %w", domain.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get campaign: %w", err)
 	}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CampaignTargetingRepository defines the interface for campaign targeting ruleset data access
+type CampaignTargetingRepository interface {
+	GetTargeting(ctx context.Context, campaignID int64) (*domain.CampaignTargeting, error)
+	// UpsertTargeting creates or replaces the ruleset of a campaign
+	UpsertTargeting(ctx context.Context, targeting *domain.CampaignTargeting) error
+	DeleteTargeting(ctx context.Context, campaignID int64) error
+}
+
+// pgxCampaignTargetingRepository implements CampaignTargetingRepository using pgx
+type pgxCampaignTargetingRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxCampaignTargetingRepository creates a new campaign targeting repository
+func NewPgxCampaignTargetingRepository(db *pgxpool.Pool) CampaignTargetingRepository {
+	return &pgxCampaignTargetingRepository{db: db}
+}
+
+// GetTargeting retrieves the ruleset of a campaign
+func (r *pgxCampaignTargetingRepository) GetTargeting(ctx context.Context, campaignID int64) (*domain.CampaignTargeting, error) {
+	query := `
+		SELECT campaign_id, rules, fallback_url, created_at, updated_at
+		FROM campaign_targeting_rulesets
+		WHERE campaign_id = $1`
+
+	targeting := &domain.CampaignTargeting{}
+	var rules []byte
+	err := r.db.QueryRow(ctx, query, campaignID).Scan(
+		&targeting.CampaignID, &rules, &targeting.FallbackURL, &targeting.CreatedAt, &targeting.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get campaign targeting: %w", err)
+	}
+	if err := json.Unmarshal(rules, &targeting.Rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal campaign targeting rules: %w", err)
+	}
+	return targeting, nil
+}
+
+// UpsertTargeting creates or replaces the ruleset of a campaign
+func (r *pgxCampaignTargetingRepository) UpsertTargeting(ctx context.Context, targeting *domain.CampaignTargeting) error {
+	rules, err := json.Marshal(targeting.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal campaign targeting rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO campaign_targeting_rulesets (campaign_id, rules, fallback_url)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (campaign_id) DO UPDATE SET rules = EXCLUDED.rules, fallback_url = EXCLUDED.fallback_url
+		RETURNING created_at, updated_at`
+	err = r.db.QueryRow(ctx, query, targeting.CampaignID, rules, targeting.FallbackURL).Scan(&targeting.CreatedAt, &targeting.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save campaign targeting: %w", err)
+	}
+	return nil
+}
+
+// DeleteTargeting removes the ruleset of a campaign
+func (r *pgxCampaignTargetingRepository) DeleteTargeting(ctx context.Context, campaignID int64) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM campaign_targeting_rulesets WHERE campaign_id = $1", campaignID)
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign targeting: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CampaignURLSettingsRepository defines the interface for campaign URL settings data access
+type CampaignURLSettingsRepository interface {
+	GetURLSettings(ctx context.Context, campaignID int64) (*domain.CampaignURLSettings, error)
+	// UpsertURLSettings creates or replaces the URL settings of a campaign
+	UpsertURLSettings(ctx context.Context, settings *domain.CampaignURLSettings) error
+}
+
+// pgxCampaignURLSettingsRepository implements CampaignURLSettingsRepository using pgx
+type pgxCampaignURLSettingsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxCampaignURLSettingsRepository creates a new campaign URL settings repository
+func NewPgxCampaignURLSettingsRepository(db *pgxpool.Pool) CampaignURLSettingsRepository {
+	return &pgxCampaignURLSettingsRepository{db: db}
+}
+
+// GetURLSettings retrieves the URL settings of a campaign
+func (r *pgxCampaignURLSettingsRepository) GetURLSettings(ctx context.Context, campaignID int64) (*domain.CampaignURLSettings, error) {
+	query := `
+		SELECT campaign_id, passthrough_parameters, created_at, updated_at
+		FROM campaign_url_settings
+		WHERE campaign_id = $1`
+
+	settings := &domain.CampaignURLSettings{}
+	var params []byte
+	err := r.db.QueryRow(ctx, query, campaignID).Scan(
+		&settings.CampaignID, &params, &settings.CreatedAt, &settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get campaign URL settings: %w", err)
+	}
+	if err := json.Unmarshal(params, &settings.PassthroughParameters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal passthrough parameters: %w", err)
+	}
+	return settings, nil
+}
+
+// UpsertURLSettings creates or replaces the URL settings of a campaign
+func (r *pgxCampaignURLSettingsRepository) UpsertURLSettings(ctx context.Context, settings *domain.CampaignURLSettings) error {
+	if settings.PassthroughParameters == nil {
+		settings.PassthroughParameters = []domain.PassthroughParameter{}
+	}
+	params, err := json.Marshal(settings.PassthroughParameters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal passthrough parameters: %w", err)
+	}
+
+	query := `
+		INSERT INTO campaign_url_settings (campaign_id, passthrough_parameters)
+		VALUES ($1, $2)
+		ON CONFLICT (campaign_id) DO UPDATE SET passthrough_parameters = EXCLUDED.passthrough_parameters
+		RETURNING created_at, updated_at`
+	err = r.db.QueryRow(ctx, query, settings.CampaignID, params).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save campaign URL settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CreativeRepository defines the interface for creative data access
+type CreativeRepository interface {
+	CreateCreative(ctx context.Context, creative *domain.Creative) error
+	GetCreativeByID(ctx context.Context, creativeID int64) (*domain.Creative, error)
+	// ListCreatives lists the creatives of a campaign, optionally with a given status
+	ListCreatives(ctx context.Context, campaignID int64, status string) ([]*domain.Creative, error)
+	// UpdateCreative saves the content and review state of a creative
+	UpdateCreative(ctx context.Context, creative *domain.Creative) error
+	// UpdateProviderSync records the outcome of syncing a creative to the provider
+	UpdateProviderSync(ctx context.Context, creativeID int64, providerCreativeID *string, syncedAt *time.Time, syncError *string) error
+	DeleteCreative(ctx context.Context, creativeID int64) error
+}
+
+// pgxCreativeRepository implements CreativeRepository using pgx
+type pgxCreativeRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxCreativeRepository creates a new creative repository
+func NewPgxCreativeRepository(db *pgxpool.Pool) CreativeRepository {
+	return &pgxCreativeRepository{db: db}
+}
+
+const creativeColumns = `creative_id, organization_id, campaign_id, name, creative_type, status, rejection_reason, reviewed_at,
+	is_private, width, height, html_code, email_from, email_subject, file_name, content_type, file_size, storage_key,
+	provider_creative_id, provider_synced_at, provider_sync_error, created_at, updated_at`
+
+func scanCreative(row pgx.Row) (*domain.Creative, error) {
+	creative := &domain.Creative{}
+	err := row.Scan(
+		&creative.CreativeID, &creative.OrganizationID, &creative.CampaignID, &creative.Name, &creative.CreativeType,
+		&creative.Status, &creative.RejectionReason, &creative.ReviewedAt, &creative.IsPrivate, &creative.Width,
+		&creative.Height, &creative.HTMLCode, &creative.EmailFrom, &creative.EmailSubject, &creative.FileName,
+		&creative.ContentType, &creative.FileSize, &creative.StorageKey, &creative.ProviderCreativeID,
+		&creative.ProviderSyncedAt, &creative.ProviderSyncError, &creative.CreatedAt, &creative.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan creative: %w", err)
+	}
+	return creative, nil
+}
+
+// CreateCreative creates a creative
+func (r *pgxCreativeRepository) CreateCreative(ctx context.Context, creative *domain.Creative) error {
+	query := `
+		INSERT INTO creatives (organization_id, campaign_id, name, creative_type, status, is_private, width, height,
+			html_code, email_from, email_subject, file_name, content_type, file_size, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING creative_id, created_at, updated_at`
+	err := r.db.QueryRow(ctx, query,
+		creative.OrganizationID, creative.CampaignID, creative.Name, creative.CreativeType, creative.Status,
+		creative.IsPrivate, creative.Width, creative.Height, creative.HTMLCode, creative.EmailFrom, creative.EmailSubject,
+		creative.FileName, creative.ContentType, creative.FileSize, creative.StorageKey,
+	).Scan(&creative.CreativeID, &creative.CreatedAt, &creative.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create creative: %w", err)
+	}
+	return nil
+}
+
+// GetCreativeByID retrieves a creative by ID
+func (r *pgxCreativeRepository) GetCreativeByID(ctx context.Context, creativeID int64) (*domain.Creative, error) {
+	return scanCreative(r.db.QueryRow(ctx, `SELECT `+creativeColumns+` FROM creatives WHERE creative_id = $1`, creativeID))
+}
+
+// ListCreatives lists the creatives of a campaign, newest first
+func (r *pgxCreativeRepository) ListCreatives(ctx context.Context, campaignID int64, status string) ([]*domain.Creative, error) {
+	query := `SELECT ` + creativeColumns + ` FROM creatives WHERE campaign_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC, creative_id DESC`
+	rows, err := r.db.Query(ctx, query, campaignID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list creatives: %w", err)
+	}
+	defer rows.Close()
+
+	creatives := make([]*domain.Creative, 0)
+	for rows.Next() {
+		creative, err := scanCreative(rows)
+		if err != nil {
+			return nil, err
+		}
+		creatives = append(creatives, creative)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating creatives: %w", err)
+	}
+	return creatives, nil
+}
+
+// UpdateCreative saves the content and review state of a creative
+func (r *pgxCreativeRepository) UpdateCreative(ctx context.Context, creative *domain.Creative) error {
+	query := `
+		UPDATE creatives
+		SET name = $2, status = $3, rejection_reason = $4, reviewed_at = $5, is_private = $6, width = $7, height = $8,
+			html_code = $9, email_from = $10, email_subject = $11
+		WHERE creative_id = $1
+		RETURNING updated_at`
+	err := r.db.QueryRow(ctx, query,
+		creative.CreativeID, creative.Name, creative.Status, creative.RejectionReason, creative.ReviewedAt,
+		creative.IsPrivate, creative.Width, creative.Height, creative.HTMLCode, creative.EmailFrom, creative.EmailSubject,
+	).Scan(&creative.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update creative: %w", err)
+	}
+	return nil
+}
+
+// UpdateProviderSync records the outcome of syncing a creative to the provider
+func (r *pgxCreativeRepository) UpdateProviderSync(ctx context.Context, creativeID int64, providerCreativeID *string, syncedAt *time.Time, syncError *string) error {
+	query := `
+		UPDATE creatives
+		SET provider_creative_id = COALESCE($2, provider_creative_id), provider_synced_at = COALESCE($3, provider_synced_at),
+			provider_sync_error = $4
+		WHERE creative_id = $1`
+	if _, err := r.db.Exec(ctx, query, creativeID, providerCreativeID, syncedAt, syncError); err != nil {
+		return fmt.Errorf("failed to update creative provider sync: %w", err)
+	}
+	return nil
+}
+
+// DeleteCreative deletes a creative
+func (r *pgxCreativeRepository) DeleteCreative(ctx context.Context, creativeID int64) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM creatives WHERE creative_id = $1", creativeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete creative: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
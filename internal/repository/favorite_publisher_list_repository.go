@@ -28,9 +28,22 @@ type FavoritePublisherListRepository interface {
 	UpdatePublisherStatus(ctx context.Context, listID int64, publisherDomain string, status string) error
 	GetPublisherFromList(ctx context.Context, listID int64, publisherDomain string) (*domain.FavoritePublisherListItem, error)
 
+	// Bulk item management
+	// BulkAddPublishersToList inserts items with distinct domains; existing items are skipped, or get
+	// the new notes when updateExisting is set. New items in a stage get a "created" history entry.
+	BulkAddPublishersToList(ctx context.Context, listID int64, items []*domain.FavoritePublisherListItem, updateExisting bool, changedBy *string) (added, updated int, err error)
+	RemovePublishersFromList(ctx context.Context, listID int64, publisherDomains []string) (int, error)
+	// CopyPublishersToList copies items with their stage, notes and follow-up into another list;
+	// an empty publisherDomains copies every item
+	CopyPublishersToList(ctx context.Context, sourceListID, targetListID int64, publisherDomains []string, updateExisting bool, changedBy *string) (added, updated int, err error)
+	// MergeLists copies every item of the source list into the target list and deletes the source list
+	MergeLists(ctx context.Context, sourceListID, targetListID int64, updateExisting bool, changedBy *string) (added, updated int, err error)
+
 	// Utility methods
+	GetListDomains(ctx context.Context, listID int64) (map[string]bool, error)
 	IsPublisherInList(ctx context.Context, listID int64, publisherDomain string) (bool, error)
 	GetListsContainingPublisher(ctx context.Context, organizationID int64, publisherDomain string) ([]*domain.FavoritePublisherList, error)
+	GetAssociatedPublisherDomains(ctx context.Context, organizationID int64) ([]string, error)
 }
 
 // favoritePublisherListRepository implements FavoritePublisherListRepository
@@ -46,9 +59,18 @@ func NewFavoritePublisherListRepository(db *pgxpool.Pool) FavoritePublisherListR
 // SQL query constants
 const (
 	selectListFields = "list_id, organization_id, name, description, created_at, updated_at"
-	selectItemFields = "item_id, list_id, publisher_domain, notes, status, added_at"
+	selectItemFields = "item_id, list_id, publisher_domain, notes, status, added_at, " +
+		"stage_id, stage_changed_at, owner_user_id, next_action_at, next_action_note, reminder_sent_at"
 )
 
+// listItemScanFields returns the scan destinations matching selectItemFields
+func listItemScanFields(item *domain.FavoritePublisherListItem) []interface{} {
+	return []interface{}{
+		&item.ItemID, &item.ListID, &item.PublisherDomain, &item.Notes, &item.Status, &item.AddedAt,
+		&item.StageID, &item.StageChangedAt, &item.OwnerUserID, &item.NextActionAt, &item.NextActionNote, &item.ReminderSentAt,
+	}
+}
+
 // Helper function to scan a list row
 func (r *favoritePublisherListRepository) scanList(row pgx.Row) (*domain.FavoritePublisherList, error) {
 	list := &domain.FavoritePublisherList{}
@@ -66,7 +88,7 @@ func (r *favoritePublisherListRepository) scanList(row pgx.Row) (*domain.Favorit
 // Helper function to scan a list item row
 func (r *favoritePublisherListRepository) scanListItem(row pgx.Row) (*domain.FavoritePublisherListItem, error) {
 	item := &domain.FavoritePublisherListItem{}
-	err := row.Scan(&item.ItemID, &item.ListID, &item.PublisherDomain, &item.Notes, &item.Status, &item.AddedAt)
+	err := row.Scan(listItemScanFields(item)...)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, domain.ErrNotFound
@@ -174,12 +196,12 @@ func (r *favoritePublisherListRepository) DeleteList(ctx context.Context, listID
 // AddPublisherToList adds a publisher to a favorite list
 func (r *favoritePublisherListRepository) AddPublisherToList(ctx context.Context, item *domain.FavoritePublisherListItem) error {
 	query := `
-		INSERT INTO favorite_publisher_list_items (list_id, publisher_domain, notes, status)
-		VALUES ($1, $2, $3, $4)
-		RETURNING item_id, added_at`
+		INSERT INTO favorite_publisher_list_items (list_id, publisher_domain, notes, status, stage_id, stage_changed_at)
+		VALUES ($1, $2, $3, $4, $5, CASE WHEN $5::BIGINT IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END)
+		RETURNING item_id, added_at, stage_changed_at`
 
-	err := r.db.QueryRow(ctx, query, item.ListID, item.PublisherDomain, item.Notes, item.Status).Scan(
-		&item.ItemID, &item.AddedAt)
+	err := r.db.QueryRow(ctx, query, item.ListID, item.PublisherDomain, item.Notes, item.Status, item.StageID).Scan(
+		&item.ItemID, &item.AddedAt, &item.StageChangedAt)
 	if err != nil {
 		return fmt.Errorf("failed to add publisher to list: %w", err)
 	}
@@ -205,11 +227,11 @@ func (r *favoritePublisherListRepository) RemovePublisherFromList(ctx context.Co
 
 // GetListItems retrieves all items in a favorite list
 func (r *favoritePublisherListRepository) GetListItems(ctx context.Context, listID int64) ([]*domain.FavoritePublisherListItem, error) {
-	query := `
-		SELECT item_id, list_id, publisher_domain, notes, status, added_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM favorite_publisher_list_items
 		WHERE list_id = $1
-		ORDER BY added_at DESC`
+		ORDER BY added_at DESC`, selectItemFields)
 
 	rows, err := r.db.Query(ctx, query, listID)
 	if err != nil {
@@ -220,8 +242,7 @@ func (r *favoritePublisherListRepository) GetListItems(ctx context.Context, list
 	items := make([]*domain.FavoritePublisherListItem, 0)
 	for rows.Next() {
 		item := &domain.FavoritePublisherListItem{}
-		err := rows.Scan(&item.ItemID, &item.ListID, &item.PublisherDomain, &item.Notes, &item.Status, &item.AddedAt)
-		if err != nil {
+		if err := rows.Scan(listItemScanFields(item)...); err != nil {
 			return nil, fmt.Errorf("failed to scan list item: %w", err)
 		}
 		items = append(items, item)
@@ -235,6 +256,7 @@ func (r *favoritePublisherListRepository) GetListItemsWithPublisherDetails(ctx c
 	query := `
 		SELECT 
 			fpli.item_id, fpli.list_id, fpli.publisher_domain, fpli.notes, fpli.status, fpli.added_at,
+			fpli.stage_id, fpli.stage_changed_at, fpli.owner_user_id, fpli.next_action_at, fpli.next_action_note, fpli.reminder_sent_at,
 			ap.id, ap.domain, ap.description, ap.favicon_image_url, ap.screenshot_image_url,
 			ap.known, ap.relevance, ap.traffic_score, ap.promotype, ap.created_at, ap.updated_at
 		FROM favorite_publisher_list_items fpli
@@ -264,11 +286,10 @@ func (r *favoritePublisherListRepository) GetListItemsWithPublisherDetails(ctx c
 		var publisherCreatedAt sql.NullTime
 		var publisherUpdatedAt sql.NullTime
 
-		err := rows.Scan(
-			&item.ItemID, &item.ListID, &item.PublisherDomain, &item.Notes, &item.Status, &item.AddedAt,
+		err := rows.Scan(append(listItemScanFields(item),
 			&publisherID, &publisherDomain, &publisherDescription, &publisherFavicon, &publisherScreenshot,
 			&publisherKnown, &publisherRelevance, &publisherTrafficScore, &publisherPromotype,
-			&publisherCreatedAt, &publisherUpdatedAt)
+			&publisherCreatedAt, &publisherUpdatedAt)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan list item with details: %w", err)
 		}
@@ -390,3 +411,194 @@ func (r *favoritePublisherListRepository) GetPublisherFromList(ctx context.Conte
 	row := r.db.QueryRow(ctx, query, listID, publisherDomain)
 	return r.scanListItem(row)
 }
+
+// GetAssociatedPublisherDomains returns the lower case domains of publishers the organization
+// already works with: the websites of its own affiliates and of affiliates in actively associated
+// organizations, and publishers it has a conversation with
+func (r *favoritePublisherListRepository) GetAssociatedPublisherDomains(ctx context.Context, organizationID int64) ([]string, error) {
+	// Reduce affiliate website URLs like "https://www.example.com/blog" to "example.com"
+	query := `
+		WITH affiliate_websites AS (
+			SELECT aei.website
+			FROM affiliate_extra_info aei
+			WHERE aei.website IS NOT NULL
+			  AND (aei.organization_id = $1 OR aei.organization_id IN (
+				SELECT affiliate_org_id FROM organization_associations
+				WHERE advertiser_org_id = $1 AND status = 'active'
+			  ))
+		)
+		SELECT DISTINCT regexp_replace(regexp_replace(LOWER(TRIM(website)), '^[a-z][a-z0-9+.-]*://', ''), '^www\.|[/:?#].*$', '', 'g')
+		FROM affiliate_websites
+		UNION
+		SELECT DISTINCT LOWER(publisher_domain)
+		FROM publisher_conversations
+		WHERE organization_id = $1`
+
+	rows, err := r.db.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get associated publisher domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make([]string, 0)
+	for rows.Next() {
+		var publisherDomain string
+		if err := rows.Scan(&publisherDomain); err != nil {
+			return nil, fmt.Errorf("failed to scan associated publisher domain: %w", err)
+		}
+		if publisherDomain != "" {
+			domains = append(domains, publisherDomain)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate associated publisher domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// GetListDomains returns the publisher domains in a list
+func (r *favoritePublisherListRepository) GetListDomains(ctx context.Context, listID int64) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `SELECT publisher_domain FROM favorite_publisher_list_items WHERE list_id = $1`, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make(map[string]bool)
+	for rows.Next() {
+		var publisherDomain string
+		if err := rows.Scan(&publisherDomain); err != nil {
+			return nil, fmt.Errorf("failed to scan list domain: %w", err)
+		}
+		domains[publisherDomain] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate list domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// createdItemsHistory records a "created" stage change for every item the upserted CTE inserted
+// into a stage and counts inserted and updated items. $1 is the user making the change.
+const createdItemsHistory = `
+	history AS (
+		INSERT INTO publisher_pipeline_stage_changes (item_id, to_stage_id, to_stage_name, changed_by_user_id, reason)
+		SELECT u.item_id, u.stage_id, s.name, $1::UUID, '` + domain.PipelineChangeReasonCreated + `'
+		FROM upserted u
+		JOIN publisher_pipeline_stages s ON s.stage_id = u.stage_id
+		WHERE u.inserted
+	)
+	SELECT COUNT(*) FILTER (WHERE inserted), COUNT(*) FILTER (WHERE NOT inserted)
+	FROM upserted`
+
+// BulkAddPublishersToList inserts items with distinct domains in one statement
+func (r *favoritePublisherListRepository) BulkAddPublishersToList(ctx context.Context, listID int64, items []*domain.FavoritePublisherListItem, updateExisting bool, changedBy *string) (int, int, error) {
+	if len(items) == 0 {
+		return 0, 0, nil
+	}
+
+	domains := make([]string, len(items))
+	notes := make([]*string, len(items))
+	statuses := make([]string, len(items))
+	stageIDs := make([]*int64, len(items))
+	for i, item := range items {
+		domains[i] = item.PublisherDomain
+		notes[i] = item.Notes
+		statuses[i] = item.Status
+		stageIDs[i] = item.StageID
+	}
+
+	// xmax is 0 only for rows this statement inserted; updates of existing rows set it
+	query := `
+		WITH input AS (
+			SELECT * FROM unnest($3::TEXT[], $4::TEXT[], $5::TEXT[], $6::BIGINT[]) AS t(publisher_domain, notes, status, stage_id)
+		),
+		upserted AS (
+			INSERT INTO favorite_publisher_list_items (list_id, publisher_domain, notes, status, stage_id, stage_changed_at)
+			SELECT $2, publisher_domain, notes, status, stage_id, CASE WHEN stage_id IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END
+			FROM input
+			ON CONFLICT (list_id, publisher_domain) DO UPDATE
+			SET notes = COALESCE(EXCLUDED.notes, favorite_publisher_list_items.notes)
+			WHERE $7
+			RETURNING item_id, stage_id, (xmax = 0) AS inserted
+		),` + createdItemsHistory
+
+	var added, updated int
+	err := r.db.QueryRow(ctx, query, changedBy, listID, domains, notes, statuses, stageIDs, updateExisting).Scan(&added, &updated)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to bulk add publishers to list: %w", err)
+	}
+
+	return added, updated, nil
+}
+
+// RemovePublishersFromList removes publishers from a list and returns how many were removed
+func (r *favoritePublisherListRepository) RemovePublishersFromList(ctx context.Context, listID int64, publisherDomains []string) (int, error) {
+	query := `DELETE FROM favorite_publisher_list_items WHERE list_id = $1 AND publisher_domain = ANY($2)`
+
+	result, err := r.db.Exec(ctx, query, listID, publisherDomains)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove publishers from list: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// CopyPublishersToList copies items into another list
+func (r *favoritePublisherListRepository) CopyPublishersToList(ctx context.Context, sourceListID, targetListID int64, publisherDomains []string, updateExisting bool, changedBy *string) (int, int, error) {
+	return copyListItems(ctx, r.db, sourceListID, targetListID, publisherDomains, updateExisting, changedBy)
+}
+
+// MergeLists copies every item of the source list into the target list and deletes the source list
+func (r *favoritePublisherListRepository) MergeLists(ctx context.Context, sourceListID, targetListID int64, updateExisting bool, changedBy *string) (int, int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	added, updated, err := copyListItems(ctx, tx, sourceListID, targetListID, nil, updateExisting, changedBy)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM favorite_publisher_lists WHERE list_id = $1`, sourceListID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete merged list: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, 0, domain.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return added, updated, nil
+}
+
+func copyListItems(ctx context.Context, db queryRower, sourceListID, targetListID int64, publisherDomains []string, updateExisting bool, changedBy *string) (int, int, error) {
+	query := `
+		WITH upserted AS (
+			INSERT INTO favorite_publisher_list_items (list_id, publisher_domain, notes, status, stage_id, stage_changed_at,
+				owner_user_id, next_action_at, next_action_note)
+			SELECT $3, publisher_domain, notes, status, stage_id, CASE WHEN stage_id IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END,
+				owner_user_id, next_action_at, next_action_note
+			FROM favorite_publisher_list_items
+			WHERE list_id = $2 AND (COALESCE(cardinality($4::TEXT[]), 0) = 0 OR publisher_domain = ANY($4))
+			ON CONFLICT (list_id, publisher_domain) DO UPDATE
+			SET notes = COALESCE(EXCLUDED.notes, favorite_publisher_list_items.notes)
+			WHERE $5
+			RETURNING item_id, stage_id, (xmax = 0) AS inserted
+		),` + createdItemsHistory
+
+	var added, updated int
+	err := db.QueryRow(ctx, query, changedBy, sourceListID, targetListID, publisherDomains, updateExisting).Scan(&added, &updated)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to copy list items: %w", err)
+	}
+
+	return added, updated, nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LinkHealthRepository defines the interface for link health monitoring data access
+type LinkHealthRepository interface {
+	// ClaimTargets claims the active campaigns with monitoring enabled that were never checked or
+	// last checked before checkedBefore, least recently checked first, until claimedUntil. Campaigns
+	// claimed by another caller are skipped; RecordRun releases the claim.
+	ClaimTargets(ctx context.Context, checkedBefore, now, claimedUntil time.Time, limit int) ([]*domain.LinkHealthTarget, error)
+
+	GetHealth(ctx context.Context, campaignID int64) (*domain.CampaignLinkHealth, error)
+	// UpsertSettings creates or replaces the monitoring settings of a campaign, keeping its
+	// failure streak
+	UpsertSettings(ctx context.Context, health *domain.CampaignLinkHealth) error
+	// RecordRun extends or resets the failure streak of a campaign after a run, releases its claim
+	// and returns the updated record
+	RecordRun(ctx context.Context, campaignID int64, healthy bool, checkedAt time.Time) (*domain.CampaignLinkHealth, error)
+	// MarkAutoPaused records that a campaign was paused after its failure streak and resets the
+	// streak, so that a reactivated campaign gets the full threshold again
+	MarkAutoPaused(ctx context.Context, campaignID int64, pausedAt time.Time) error
+
+	CreateCheck(ctx context.Context, check *domain.LinkHealthCheck) error
+	ListChecks(ctx context.Context, campaignID int64, limit, offset int) ([]*domain.LinkHealthCheck, int, error)
+	// ListLatestChecks lists the checks of the latest run of a campaign
+	ListLatestChecks(ctx context.Context, campaignID int64) ([]*domain.LinkHealthCheck, error)
+	// DeleteChecksBefore deletes the checks older than before and returns how many were deleted
+	DeleteChecksBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// pgxLinkHealthRepository implements LinkHealthRepository using pgx
+type pgxLinkHealthRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxLinkHealthRepository creates a new link health repository
+func NewPgxLinkHealthRepository(db *pgxpool.Pool) LinkHealthRepository {
+	return &pgxLinkHealthRepository{db: db}
+}
+
+// ClaimTargets claims the campaigns due for a check. Campaigns without a monitoring record get
+// one with the defaults. The conflict clause makes the claim atomic: a concurrent claim of the
+// same campaign waits for this one and then finds it claimed.
+func (r *pgxLinkHealthRepository) ClaimTargets(ctx context.Context, checkedBefore, now, claimedUntil time.Time, limit int) ([]*domain.LinkHealthTarget, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+			SELECT c.campaign_id
+			FROM public.campaigns c
+			LEFT JOIN public.campaign_link_health h ON h.campaign_id = c.campaign_id
+			WHERE c.status = 'active' AND COALESCE(h.enabled, TRUE)
+			  AND (h.last_checked_at IS NULL OR h.last_checked_at < $1)
+			  AND (h.claimed_until IS NULL OR h.claimed_until < $2)
+			ORDER BY h.last_checked_at NULLS FIRST, c.campaign_id
+			LIMIT $4
+		), claimed AS (
+			INSERT INTO public.campaign_link_health (campaign_id, claimed_until)
+			SELECT campaign_id, $3 FROM due
+			ON CONFLICT (campaign_id) DO UPDATE SET claimed_until = EXCLUDED.claimed_until
+			WHERE campaign_link_health.claimed_until IS NULL OR campaign_link_health.claimed_until < $2
+			RETURNING campaign_id, auto_pause_after_failures, consecutive_failures, last_checked_at, last_healthy, auto_paused_at
+		)
+		SELECT c.campaign_id, c.organization_id, c.advertiser_id, c.name, c.status, c.destination_url, c.preview_url,
+		       h.auto_pause_after_failures, h.consecutive_failures, h.last_checked_at, h.last_healthy, h.auto_paused_at
+		FROM claimed h
+		JOIN public.campaigns c ON c.campaign_id = h.campaign_id
+		ORDER BY h.last_checked_at NULLS FIRST, c.campaign_id`, checkedBefore, now, claimedUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim link health targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := make([]*domain.LinkHealthTarget, 0)
+	for rows.Next() {
+		campaign := &domain.Campaign{}
+		health := &domain.CampaignLinkHealth{Enabled: true}
+		if err := rows.Scan(&campaign.CampaignID, &campaign.OrganizationID, &campaign.AdvertiserID, &campaign.Name,
+			&campaign.Status, &campaign.DestinationURL, &campaign.PreviewURL,
+			&health.AutoPauseAfterFailures, &health.ConsecutiveFailures, &health.LastCheckedAt, &health.LastHealthy,
+			&health.AutoPausedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan link health target: %w", err)
+		}
+		health.CampaignID = campaign.CampaignID
+		targets = append(targets, &domain.LinkHealthTarget{Campaign: campaign, Health: health})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate link health targets: %w", err)
+	}
+	return targets, nil
+}
+
+const campaignLinkHealthColumns = `campaign_id, enabled, auto_pause_after_failures, consecutive_failures, last_checked_at,
+	last_healthy, auto_paused_at, created_at, updated_at`
+
+func scanCampaignLinkHealth(row pgx.Row) (*domain.CampaignLinkHealth, error) {
+	health := &domain.CampaignLinkHealth{}
+	err := row.Scan(&health.CampaignID, &health.Enabled, &health.AutoPauseAfterFailures, &health.ConsecutiveFailures,
+		&health.LastCheckedAt, &health.LastHealthy, &health.AutoPausedAt, &health.CreatedAt, &health.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// GetHealth retrieves the monitoring record of a campaign
+func (r *pgxLinkHealthRepository) GetHealth(ctx context.Context, campaignID int64) (*domain.CampaignLinkHealth, error) {
+	health, err := scanCampaignLinkHealth(r.db.QueryRow(ctx,
+		`SELECT `+campaignLinkHealthColumns+` FROM campaign_link_health WHERE campaign_id = $1`, campaignID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get campaign link health: %w", err)
+	}
+	return health, nil
+}
+
+// UpsertSettings creates or replaces the monitoring settings of a campaign
+func (r *pgxLinkHealthRepository) UpsertSettings(ctx context.Context, health *domain.CampaignLinkHealth) error {
+	saved, err := scanCampaignLinkHealth(r.db.QueryRow(ctx, `
+		INSERT INTO campaign_link_health (campaign_id, enabled, auto_pause_after_failures)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (campaign_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			auto_pause_after_failures = EXCLUDED.auto_pause_after_failures
+		RETURNING `+campaignLinkHealthColumns,
+		health.CampaignID, health.Enabled, health.AutoPauseAfterFailures))
+	if err != nil {
+		return fmt.Errorf("failed to save campaign link health settings: %w", err)
+	}
+	*health = *saved
+	return nil
+}
+
+// RecordRun updates the failure streak of a campaign after a run
+func (r *pgxLinkHealthRepository) RecordRun(ctx context.Context, campaignID int64, healthy bool, checkedAt time.Time) (*domain.CampaignLinkHealth, error) {
+	health, err := scanCampaignLinkHealth(r.db.QueryRow(ctx, `
+		INSERT INTO campaign_link_health (campaign_id, consecutive_failures, last_checked_at, last_healthy)
+		VALUES ($1, CASE WHEN $2 THEN 0 ELSE 1 END, $3, $2)
+		ON CONFLICT (campaign_id) DO UPDATE SET
+			consecutive_failures = CASE WHEN $2 THEN 0 ELSE campaign_link_health.consecutive_failures + 1 END,
+			last_checked_at = EXCLUDED.last_checked_at,
+			last_healthy = EXCLUDED.last_healthy,
+			claimed_until = NULL
+		RETURNING `+campaignLinkHealthColumns,
+		campaignID, healthy, checkedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record link health run: %w", err)
+	}
+	return health, nil
+}
+
+// MarkAutoPaused records the auto-pause of a campaign and resets its failure streak
+func (r *pgxLinkHealthRepository) MarkAutoPaused(ctx context.Context, campaignID int64, pausedAt time.Time) error {
+	result, err := r.db.Exec(ctx, `UPDATE campaign_link_health SET auto_paused_at = $2, consecutive_failures = 0 WHERE campaign_id = $1`, campaignID, pausedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark campaign as auto-paused: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CreateCheck records the result of checking a URL
+func (r *pgxLinkHealthRepository) CreateCheck(ctx context.Context, check *domain.LinkHealthCheck) error {
+	if check.RedirectChain == nil {
+		check.RedirectChain = []domain.LinkRedirectHop{}
+	}
+	chain, err := json.Marshal(check.RedirectChain)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redirect chain: %w", err)
+	}
+
+	query := `
+		INSERT INTO link_health_checks (organization_id, campaign_id, tracking_link_id, url_kind, url, status_code,
+			latency_ms, redirect_chain, tls_expires_at, error, healthy, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING check_id`
+	err = r.db.QueryRow(ctx, query,
+		check.OrganizationID, check.CampaignID, check.TrackingLinkID, check.URLKind, check.URL, check.StatusCode,
+		check.LatencyMs, chain, check.TLSExpiresAt, check.Error, check.Healthy, check.CheckedAt,
+	).Scan(&check.CheckID)
+	if err != nil {
+		return fmt.Errorf("failed to create link health check: %w", err)
+	}
+	return nil
+}
+
+const linkHealthCheckColumns = `check_id, organization_id, campaign_id, tracking_link_id, url_kind, url, status_code,
+	latency_ms, redirect_chain, tls_expires_at, error, healthy, checked_at`
+
+func scanLinkHealthChecks(rows pgx.Rows) ([]*domain.LinkHealthCheck, error) {
+	defer rows.Close()
+
+	checks := make([]*domain.LinkHealthCheck, 0)
+	for rows.Next() {
+		check := &domain.LinkHealthCheck{}
+		var chain []byte
+		if err := rows.Scan(&check.CheckID, &check.OrganizationID, &check.CampaignID, &check.TrackingLinkID,
+			&check.URLKind, &check.URL, &check.StatusCode, &check.LatencyMs, &chain, &check.TLSExpiresAt,
+			&check.Error, &check.Healthy, &check.CheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan link health check: %w", err)
+		}
+		if err := json.Unmarshal(chain, &check.RedirectChain); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal redirect chain: %w", err)
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate link health checks: %w", err)
+	}
+	return checks, nil
+}
+
+// ListChecks lists the checks of a campaign, newest first
+func (r *pgxLinkHealthRepository) ListChecks(ctx context.Context, campaignID int64, limit, offset int) ([]*domain.LinkHealthCheck, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM link_health_checks WHERE campaign_id = $1`, campaignID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count link health checks: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+linkHealthCheckColumns+`
+		FROM link_health_checks
+		WHERE campaign_id = $1
+		ORDER BY checked_at DESC, check_id
+		LIMIT $2 OFFSET $3`, campaignID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list link health checks: %w", err)
+	}
+	checks, err := scanLinkHealthChecks(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return checks, total, nil
+}
+
+// ListLatestChecks lists the checks of the latest run of a campaign; the checks of a run share
+// their checked_at
+func (r *pgxLinkHealthRepository) ListLatestChecks(ctx context.Context, campaignID int64) ([]*domain.LinkHealthCheck, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+linkHealthCheckColumns+`
+		FROM link_health_checks
+		WHERE campaign_id = $1
+		  AND checked_at = (SELECT MAX(checked_at) FROM link_health_checks WHERE campaign_id = $1)
+		ORDER BY check_id`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list latest link health checks: %w", err)
+	}
+	return scanLinkHealthChecks(rows)
+}
+
+// DeleteChecksBefore deletes the checks older than before
+func (r *pgxLinkHealthRepository) DeleteChecksBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM link_health_checks WHERE checked_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete link health checks: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
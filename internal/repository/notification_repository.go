@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotificationRepository defines the interface for notification data access
+type NotificationRepository interface {
+	// CreateNotifications stores a notification for each recipient that has the type enabled
+	CreateNotifications(ctx context.Context, notification *domain.NewNotification) ([]*domain.Notification, error)
+	GetNotificationByID(ctx context.Context, notificationID int64) (*domain.Notification, error)
+	ListNotifications(ctx context.Context, userID string, filter *domain.NotificationFilter) ([]*domain.Notification, int, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID string, notificationID int64) error
+	MarkUnread(ctx context.Context, userID string, notificationID int64) error
+	// MarkAllRead marks the unread notifications of a user as read, optionally of one type only
+	MarkAllRead(ctx context.Context, userID string, notificationType *string) (int, error)
+	DeleteNotification(ctx context.Context, userID string, notificationID int64) error
+
+	// GetPreferences returns the stored preferences of a user; types without a row use the defaults
+	GetPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error)
+	SavePreferences(ctx context.Context, userID string, preferences []domain.NotificationPreference) error
+
+	// Listen receives the events announced on domain.NotificationChannel by any replica until
+	// ctx is cancelled or the connection fails
+	Listen(ctx context.Context, handle func(domain.NotificationEvent)) error
+}
+
+// pgxNotificationRepository implements NotificationRepository using pgx
+type pgxNotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxNotificationRepository creates a new notification repository
+func NewPgxNotificationRepository(db *pgxpool.Pool) NotificationRepository {
+	return &pgxNotificationRepository{db: db}
+}
+
+const notificationColumns = `notification_id, organization_id, user_id::text, type, title, body, data, read_at, created_at`
+
+func scanNotification(row pgx.Row) (*domain.Notification, error) {
+	notification := &domain.Notification{}
+	var data JSONB
+	err := row.Scan(
+		&notification.NotificationID, &notification.OrganizationID, &notification.UserID, &notification.Type,
+		&notification.Title, &notification.Body, &data, &notification.ReadAt, &notification.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan notification: %w", err)
+	}
+	notification.Data = map[string]interface{}(data)
+	if notification.Data == nil {
+		notification.Data = map[string]interface{}{}
+	}
+	return notification, nil
+}
+
+// CreateNotifications stores one notification per user of the organization (or per listed user)
+// unless the user disabled the type
+func (r *pgxNotificationRepository) CreateNotifications(ctx context.Context, notification *domain.NewNotification) ([]*domain.Notification, error) {
+	var body *string
+	if notification.Body != "" {
+		body = &notification.Body
+	}
+	data := JSONB(notification.Data)
+	if data == nil {
+		data = JSONB{}
+	}
+	var userIDs []string
+	if len(notification.UserIDs) > 0 {
+		userIDs = notification.UserIDs
+	}
+
+	query := `
+		INSERT INTO notifications (organization_id, user_id, type, title, body, data)
+		SELECT $1, p.id, $2, $3, $4, $5
+		FROM profiles p
+		LEFT JOIN notification_preferences np ON np.user_id = p.id AND np.type = $2
+		WHERE p.organization_id = $1
+		  AND COALESCE(np.enabled, TRUE)
+		  AND ($6::text[] IS NULL OR p.id::text = ANY($6::text[]))
+		RETURNING ` + notificationColumns
+
+	rows, err := r.db.Query(ctx, query,
+		notification.OrganizationID, notification.Type, notification.Title, body, data, userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notifications: %w", err)
+	}
+	defer rows.Close()
+
+	created := make([]*domain.Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return created, nil
+}
+
+// GetNotificationByID returns a notification
+func (r *pgxNotificationRepository) GetNotificationByID(ctx context.Context, notificationID int64) (*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE notification_id = $1`
+	return scanNotification(r.db.QueryRow(ctx, query, notificationID))
+}
+
+// ListNotifications lists the notifications of a user, newest first
+func (r *pgxNotificationRepository) ListNotifications(ctx context.Context, userID string, filter *domain.NotificationFilter) ([]*domain.Notification, int, error) {
+	conditions := []string{"user_id = $1::uuid"}
+	args := []interface{}{userID}
+	if filter.UnreadOnly {
+		conditions = append(conditions, "read_at IS NULL")
+	}
+	if filter.Type != nil {
+		args = append(args, *filter.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM notifications WHERE %s
+		ORDER BY created_at DESC, notification_id DESC
+		LIMIT $%d OFFSET $%d`, notificationColumns, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*domain.Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+	return notifications, total, nil
+}
+
+// CountUnread counts the unread notifications of a user
+func (r *pgxNotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1::uuid AND read_at IS NULL`
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *pgxNotificationRepository) setReadAt(ctx context.Context, userID string, notificationID int64, readAt string) error {
+	query := `UPDATE notifications SET read_at = ` + readAt + ` WHERE notification_id = $1 AND user_id = $2::uuid`
+	result, err := r.db.Exec(ctx, query, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// MarkRead marks a notification of the user as read; the first read time is kept
+func (r *pgxNotificationRepository) MarkRead(ctx context.Context, userID string, notificationID int64) error {
+	return r.setReadAt(ctx, userID, notificationID, "COALESCE(read_at, CURRENT_TIMESTAMP)")
+}
+
+// MarkUnread marks a notification of the user as unread
+func (r *pgxNotificationRepository) MarkUnread(ctx context.Context, userID string, notificationID int64) error {
+	return r.setReadAt(ctx, userID, notificationID, "NULL")
+}
+
+// MarkAllRead marks the unread notifications of a user as read
+func (r *pgxNotificationRepository) MarkAllRead(ctx context.Context, userID string, notificationType *string) (int, error) {
+	query := `
+		UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1::uuid AND read_at IS NULL AND ($2::text IS NULL OR type = $2)`
+	result, err := r.db.Exec(ctx, query, userID, notificationType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// DeleteNotification deletes a notification of the user
+func (r *pgxNotificationRepository) DeleteNotification(ctx context.Context, userID string, notificationID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM notifications WHERE notification_id = $1 AND user_id = $2::uuid`, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetPreferences returns the stored preferences of a user
+func (r *pgxNotificationRepository) GetPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error) {
+	query := `SELECT type, enabled, realtime FROM notification_preferences WHERE user_id = $1::uuid ORDER BY type`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	preferences := make([]domain.NotificationPreference, 0)
+	for rows.Next() {
+		var p domain.NotificationPreference
+		if err := rows.Scan(&p.Type, &p.Enabled, &p.Realtime); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		preferences = append(preferences, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+// SavePreferences stores the preferences of a user for the given types
+func (r *pgxNotificationRepository) SavePreferences(ctx context.Context, userID string, preferences []domain.NotificationPreference) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO notification_preferences (user_id, type, enabled, realtime)
+		VALUES ($1::uuid, $2, $3, $4)
+		ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled, realtime = EXCLUDED.realtime`
+	for _, p := range preferences {
+		if _, err := tx.Exec(ctx, query, userID, p.Type, p.Enabled, p.Realtime); err != nil {
+			return fmt.Errorf("failed to save notification preference: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Listen holds a pool connection listening on the notifications channel
+func (r *pgxNotificationRepository) Listen(ctx context.Context, handle func(domain.NotificationEvent)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire listener connection: %w", err)
+	}
+	// The connection stays in LISTEN state, so it is taken out of the pool and closed when done
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+domain.NotificationChannel); err != nil {
+		return fmt.Errorf("failed to listen for notifications: %w", err)
+	}
+
+	for {
+		received, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notifications: %w", err)
+		}
+		var event domain.NotificationEvent
+		if err := json.Unmarshal([]byte(received.Payload), &event); err != nil {
+			continue // Not one of ours
+		}
+		handle(event)
+	}
+}
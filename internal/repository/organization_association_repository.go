@@ -20,6 +20,14 @@ type OrganizationAssociationRepository interface {
 	GetAssociationByIDWithDetails(ctx context.Context, id int64) (*domain.OrganizationAssociationWithDetails, error)
 	GetAssociationByOrganizations(ctx context.Context, advertiserOrgID, affiliateOrgID int64) (*domain.OrganizationAssociation, error)
 	UpdateAssociation(ctx context.Context, association *domain.OrganizationAssociation) error
+	// SuspendAssociation suspends an active association and pauses the active and scheduled links
+	// of the advertiser's campaigns for the affiliate organization's affiliates, in one transaction.
+	// It returns the number of links paused.
+	SuspendAssociation(ctx context.Context, association *domain.OrganizationAssociation) (int64, error)
+	// ReactivateAssociation reactivates a suspended association and restores the links paused by
+	// SuspendAssociation to the status their window gives them at now, in one transaction. It
+	// returns the number of links resumed.
+	ReactivateAssociation(ctx context.Context, association *domain.OrganizationAssociation, now time.Time) (int64, error)
 	ListAssociations(ctx context.Context, filter *domain.AssociationListFilter) ([]*domain.OrganizationAssociation, error)
 	ListAssociationsWithDetails(ctx context.Context, filter *domain.AssociationListFilter) ([]*domain.OrganizationAssociationWithDetails, error)
 	DeleteAssociation(ctx context.Context, id int64) error
@@ -227,7 +235,7 @@ func (r *pgxOrganizationAssociationRepository) GetAssociationByOrganizations(ctx
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("organization association not found")
+			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("error getting organization association: %w", err)
 	}
@@ -264,6 +272,89 @@ func (r *pgxOrganizationAssociationRepository) UpdateAssociation(ctx context.Con
 	return nil
 }
 
+// SuspendAssociation suspends an association and pauses its links in one transaction
+func (r *pgxOrganizationAssociationRepository) SuspendAssociation(ctx context.Context, association *domain.OrganizationAssociation) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := changeAssociationStatus(ctx, tx, association, domain.AssociationStatusActive, domain.AssociationStatusSuspended); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(ctx, `
+		UPDATE public.tracking_links tl
+		SET status = 'paused', status_reason = $3
+		FROM public.campaigns c, public.affiliates a
+		WHERE c.campaign_id = tl.campaign_id AND a.affiliate_id = tl.affiliate_id
+		  AND c.organization_id = $1 AND a.organization_id = $2
+		  AND tl.status IN ('scheduled', 'active')`,
+		association.AdvertiserOrgID, association.AffiliateOrgID, domain.TrackingLinkStatusReasonAssociationSuspended)
+	if err != nil {
+		return 0, fmt.Errorf("failed to pause tracking links of association: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// ReactivateAssociation reactivates an association and resumes the links its suspension paused
+// in one transaction. Links paused by a user are left paused.
+func (r *pgxOrganizationAssociationRepository) ReactivateAssociation(ctx context.Context, association *domain.OrganizationAssociation, now time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := changeAssociationStatus(ctx, tx, association, domain.AssociationStatusSuspended, domain.AssociationStatusActive); err != nil {
+		return 0, err
+	}
+	links, err := lockLifecycleLinks(ctx, tx, `
+		c.organization_id = $1
+		AND tl.affiliate_id IN (SELECT affiliate_id FROM public.affiliates WHERE organization_id = $2)
+		AND tl.status = 'paused' AND tl.status_reason = $3`,
+		association.AdvertiserOrgID, association.AffiliateOrgID, domain.TrackingLinkStatusReasonAssociationSuspended)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resume tracking links of association: %w", err)
+	}
+	for _, l := range links {
+		l.link.Resume(&l.campaign, now)
+		if err := saveLifecycleStatus(ctx, tx, &l.link); err != nil {
+			return 0, fmt.Errorf("failed to resume tracking links of association: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int64(len(links)), nil
+}
+
+// changeAssociationStatus moves an association from one status to another within tx, recording
+// association.ApprovedByUserID as the user who changed it. It fails with domain.ErrNotFound when
+// the association is no longer in the from status.
+func changeAssociationStatus(ctx context.Context, tx pgx.Tx, association *domain.OrganizationAssociation, from, to domain.AssociationStatus) error {
+	now := time.Now()
+	result, err := tx.Exec(ctx, `
+		UPDATE public.organization_associations
+		SET status = $3, approved_by_user_id = $4, updated_at = $5
+		WHERE association_id = $1 AND status = $2`,
+		association.AssociationID, from, to, association.ApprovedByUserID, now)
+	if err != nil {
+		return fmt.Errorf("error updating organization association: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("association is no longer %s: %w", from, domain.ErrNotFound)
+	}
+	association.Status = to
+	association.UpdatedAt = now
+	return nil
+}
+
 // ListAssociations retrieves a list of organization associations based on filter
 func (r *pgxOrganizationAssociationRepository) ListAssociations(ctx context.Context, filter *domain.AssociationListFilter) ([]*domain.OrganizationAssociation, error) {
 	query := `SELECT association_id, advertiser_org_id, affiliate_org_id, status, association_type,
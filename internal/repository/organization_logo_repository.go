@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OrganizationLogoRepository defines the interface for organization logo data access
+type OrganizationLogoRepository interface {
+	GetLogo(ctx context.Context, organizationID int64) (*domain.OrganizationLogo, error)
+	// SaveLogo creates or replaces the logo of an organization and sets its UpdatedAt
+	SaveLogo(ctx context.Context, logo *domain.OrganizationLogo) error
+	DeleteLogo(ctx context.Context, organizationID int64) error
+}
+
+// pgxOrganizationLogoRepository implements OrganizationLogoRepository using pgx
+type pgxOrganizationLogoRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxOrganizationLogoRepository creates a new organization logo repository
+func NewPgxOrganizationLogoRepository(db *pgxpool.Pool) OrganizationLogoRepository {
+	return &pgxOrganizationLogoRepository{db: db}
+}
+
+// GetLogo returns the logo of an organization
+func (r *pgxOrganizationLogoRepository) GetLogo(ctx context.Context, organizationID int64) (*domain.OrganizationLogo, error) {
+	query := `
+		SELECT organization_id, storage_key, content_type, size_bytes, width, height, updated_at
+		FROM organization_logos WHERE organization_id = $1`
+
+	logo := &domain.OrganizationLogo{}
+	err := r.db.QueryRow(ctx, query, organizationID).Scan(
+		&logo.OrganizationID, &logo.StorageKey, &logo.ContentType, &logo.SizeBytes,
+		&logo.Width, &logo.Height, &logo.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization logo: %w", err)
+	}
+	return logo, nil
+}
+
+// SaveLogo upserts the logo of an organization
+func (r *pgxOrganizationLogoRepository) SaveLogo(ctx context.Context, logo *domain.OrganizationLogo) error {
+	query := `
+		INSERT INTO organization_logos (organization_id, storage_key, content_type, size_bytes, width, height)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			storage_key = EXCLUDED.storage_key,
+			content_type = EXCLUDED.content_type,
+			size_bytes = EXCLUDED.size_bytes,
+			width = EXCLUDED.width,
+			height = EXCLUDED.height
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		logo.OrganizationID, logo.StorageKey, logo.ContentType, logo.SizeBytes, logo.Width, logo.Height,
+	).Scan(&logo.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save organization logo: %w", err)
+	}
+	return nil
+}
+
+// DeleteLogo removes the logo of an organization
+func (r *pgxOrganizationLogoRepository) DeleteLogo(ctx context.Context, organizationID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM organization_logos WHERE organization_id = $1`, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization logo: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,497 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutreachSequenceRepository defines the interface for outreach sequence data access
+type OutreachSequenceRepository interface {
+	// Sequence operations; sequences are returned with their steps
+	CreateSequence(ctx context.Context, sequence *domain.OutreachSequence) error
+	GetSequenceByID(ctx context.Context, sequenceID int64) (*domain.OutreachSequence, error)
+	ListSequences(ctx context.Context, organizationID int64) ([]*domain.OutreachSequence, error)
+	// UpdateSequence saves the name, subject and status, and replaces the steps when replaceSteps is set
+	UpdateSequence(ctx context.Context, sequence *domain.OutreachSequence, replaceSteps bool) error
+	DeleteSequence(ctx context.Context, sequenceID int64) error
+
+	// Enrollment operations
+	CreateEnrollment(ctx context.Context, enrollment *domain.OutreachEnrollment) error
+	GetEnrolledDomains(ctx context.Context, sequenceID int64) (map[string]bool, error)
+	ListEnrollments(ctx context.Context, sequenceID int64, filter *domain.OutreachEnrollmentFilter) ([]*domain.OutreachEnrollment, int, error)
+	GetSequenceStats(ctx context.Context, sequenceID int64, stepCount int) (*domain.OutreachSequenceStats, error)
+
+	// Scheduler operations
+	// MarkRepliedEnrollments marks the enrollments whose publisher replied since they were
+	// created and returns how many were marked
+	MarkRepliedEnrollments(ctx context.Context) (int, error)
+	// StopClosedEnrollments stops the pending enrollments whose conversation was closed or removed
+	StopClosedEnrollments(ctx context.Context) (int, error)
+	// ListDueEnrollments returns pending enrollments of active sequences whose next step is due
+	ListDueEnrollments(ctx context.Context, now time.Time, limit int) ([]*domain.OutreachEnrollment, error)
+	// ClaimEnrollmentStep records that the step after stepsSent is being sent, only if no other
+	// scheduler sent it first. A nil nextSendAt completes the enrollment.
+	ClaimEnrollmentStep(ctx context.Context, enrollmentID int64, stepsSent int, sentAt time.Time, nextSendAt *time.Time) (bool, error)
+	// FinishEnrollment ends an enrollment that has not been replied to with a status and optional
+	// error, also after its last step was claimed
+	FinishEnrollment(ctx context.Context, enrollmentID int64, status string, lastError *string) error
+}
+
+// pgxOutreachSequenceRepository implements OutreachSequenceRepository using pgx
+type pgxOutreachSequenceRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxOutreachSequenceRepository creates a new outreach sequence repository
+func NewPgxOutreachSequenceRepository(db *pgxpool.Pool) OutreachSequenceRepository {
+	return &pgxOutreachSequenceRepository{db: db}
+}
+
+const outreachEnrollmentColumns = `enrollment_id, sequence_id, organization_id, list_id, publisher_domain, conversation_id,
+	status, steps_sent, next_send_at, last_sent_at, replied_at, last_error, created_at, updated_at`
+
+// pendingOutreachStatuses are the enrollment statuses that still have steps to send
+const pendingOutreachStatuses = `('` + domain.OutreachEnrollmentStatusScheduled + `', '` + domain.OutreachEnrollmentStatusActive + `')`
+
+func scanOutreachEnrollment(row pgx.Row) (*domain.OutreachEnrollment, error) {
+	enrollment := &domain.OutreachEnrollment{}
+	err := row.Scan(
+		&enrollment.EnrollmentID, &enrollment.SequenceID, &enrollment.OrganizationID, &enrollment.ListID,
+		&enrollment.PublisherDomain, &enrollment.ConversationID, &enrollment.Status, &enrollment.StepsSent,
+		&enrollment.NextSendAt, &enrollment.LastSentAt, &enrollment.RepliedAt, &enrollment.LastError,
+		&enrollment.CreatedAt, &enrollment.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan outreach enrollment: %w", err)
+	}
+	return enrollment, nil
+}
+
+// CreateSequence creates a sequence with its steps
+func (r *pgxOutreachSequenceRepository) CreateSequence(ctx context.Context, sequence *domain.OutreachSequence) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO outreach_sequences (organization_id, name, subject_template, status, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING sequence_id, created_at, updated_at`,
+		sequence.OrganizationID, sequence.Name, sequence.SubjectTemplate, sequence.Status, sequence.CreatedByUserID,
+	).Scan(&sequence.SequenceID, &sequence.CreatedAt, &sequence.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create outreach sequence: %w", err)
+	}
+
+	if err := insertOutreachSteps(ctx, tx, sequence); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertOutreachSteps inserts the steps of a sequence, numbered in order
+func insertOutreachSteps(ctx context.Context, db queryRower, sequence *domain.OutreachSequence) error {
+	for i, step := range sequence.Steps {
+		step.SequenceID = sequence.SequenceID
+		step.StepNumber = i + 1
+		err := db.QueryRow(ctx, `
+			INSERT INTO outreach_sequence_steps (sequence_id, step_number, delay_days, body_template)
+			VALUES ($1, $2, $3, $4)
+			RETURNING step_id, created_at`,
+			step.SequenceID, step.StepNumber, step.DelayDays, step.BodyTemplate,
+		).Scan(&step.StepID, &step.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create outreach sequence step: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetSequenceByID retrieves a sequence with its steps
+func (r *pgxOutreachSequenceRepository) GetSequenceByID(ctx context.Context, sequenceID int64) (*domain.OutreachSequence, error) {
+	sequence := &domain.OutreachSequence{}
+	err := r.db.QueryRow(ctx, `
+		SELECT sequence_id, organization_id, name, subject_template, status, created_by_user_id, created_at, updated_at
+		FROM outreach_sequences
+		WHERE sequence_id = $1`, sequenceID,
+	).Scan(&sequence.SequenceID, &sequence.OrganizationID, &sequence.Name, &sequence.SubjectTemplate,
+		&sequence.Status, &sequence.CreatedByUserID, &sequence.CreatedAt, &sequence.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get outreach sequence: %w", err)
+	}
+
+	steps, err := r.getSteps(ctx, []int64{sequenceID})
+	if err != nil {
+		return nil, err
+	}
+	sequence.Steps = steps[sequenceID]
+	return sequence, nil
+}
+
+// ListSequences lists the sequences of an organization, newest first
+func (r *pgxOutreachSequenceRepository) ListSequences(ctx context.Context, organizationID int64) ([]*domain.OutreachSequence, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT sequence_id, organization_id, name, subject_template, status, created_by_user_id, created_at, updated_at
+		FROM outreach_sequences
+		WHERE organization_id = $1
+		ORDER BY created_at DESC, sequence_id DESC`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outreach sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := make([]*domain.OutreachSequence, 0)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		sequence := &domain.OutreachSequence{}
+		if err := rows.Scan(&sequence.SequenceID, &sequence.OrganizationID, &sequence.Name, &sequence.SubjectTemplate,
+			&sequence.Status, &sequence.CreatedByUserID, &sequence.CreatedAt, &sequence.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outreach sequence: %w", err)
+		}
+		sequences = append(sequences, sequence)
+		ids = append(ids, sequence.SequenceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outreach sequences: %w", err)
+	}
+
+	steps, err := r.getSteps(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, sequence := range sequences {
+		sequence.Steps = steps[sequence.SequenceID]
+	}
+	return sequences, nil
+}
+
+// getSteps returns the steps of sequences in order, keyed by sequence ID
+func (r *pgxOutreachSequenceRepository) getSteps(ctx context.Context, sequenceIDs []int64) (map[int64][]*domain.OutreachSequenceStep, error) {
+	steps := make(map[int64][]*domain.OutreachSequenceStep)
+	if len(sequenceIDs) == 0 {
+		return steps, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT step_id, sequence_id, step_number, delay_days, body_template, created_at
+		FROM outreach_sequence_steps
+		WHERE sequence_id = ANY($1)
+		ORDER BY sequence_id, step_number`, sequenceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outreach sequence steps: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		step := &domain.OutreachSequenceStep{}
+		if err := rows.Scan(&step.StepID, &step.SequenceID, &step.StepNumber, &step.DelayDays, &step.BodyTemplate, &step.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outreach sequence step: %w", err)
+		}
+		steps[step.SequenceID] = append(steps[step.SequenceID], step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outreach sequence steps: %w", err)
+	}
+	return steps, nil
+}
+
+// UpdateSequence updates a sequence and optionally replaces its steps
+func (r *pgxOutreachSequenceRepository) UpdateSequence(ctx context.Context, sequence *domain.OutreachSequence, replaceSteps bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE outreach_sequences
+		SET name = $2, subject_template = $3, status = $4
+		WHERE sequence_id = $1
+		RETURNING updated_at`,
+		sequence.SequenceID, sequence.Name, sequence.SubjectTemplate, sequence.Status,
+	).Scan(&sequence.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update outreach sequence: %w", err)
+	}
+
+	if replaceSteps {
+		if _, err := tx.Exec(ctx, `DELETE FROM outreach_sequence_steps WHERE sequence_id = $1`, sequence.SequenceID); err != nil {
+			return fmt.Errorf("failed to delete outreach sequence steps: %w", err)
+		}
+		if err := insertOutreachSteps(ctx, tx, sequence); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteSequence deletes a sequence with its steps and enrollments; conversations are kept
+func (r *pgxOutreachSequenceRepository) DeleteSequence(ctx context.Context, sequenceID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM outreach_sequences WHERE sequence_id = $1`, sequenceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete outreach sequence: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CreateEnrollment enrolls a publisher in a sequence
+func (r *pgxOutreachSequenceRepository) CreateEnrollment(ctx context.Context, enrollment *domain.OutreachEnrollment) error {
+	query := `
+		INSERT INTO outreach_sequence_enrollments (sequence_id, organization_id, list_id, publisher_domain, conversation_id, status, next_send_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + outreachEnrollmentColumns
+
+	created, err := scanOutreachEnrollment(r.db.QueryRow(ctx, query,
+		enrollment.SequenceID, enrollment.OrganizationID, enrollment.ListID, enrollment.PublisherDomain,
+		enrollment.ConversationID, enrollment.Status, enrollment.NextSendAt))
+	if err != nil {
+		return fmt.Errorf("failed to create outreach enrollment: %w", err)
+	}
+
+	*enrollment = *created
+	return nil
+}
+
+// GetEnrolledDomains returns the publisher domains enrolled in a sequence
+func (r *pgxOutreachSequenceRepository) GetEnrolledDomains(ctx context.Context, sequenceID int64) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `SELECT publisher_domain FROM outreach_sequence_enrollments WHERE sequence_id = $1`, sequenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrolled domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make(map[string]bool)
+	for rows.Next() {
+		var publisherDomain string
+		if err := rows.Scan(&publisherDomain); err != nil {
+			return nil, fmt.Errorf("failed to scan enrolled domain: %w", err)
+		}
+		domains[publisherDomain] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating enrolled domains: %w", err)
+	}
+	return domains, nil
+}
+
+// ListEnrollments lists the enrollments of a sequence, most recently updated first
+func (r *pgxOutreachSequenceRepository) ListEnrollments(ctx context.Context, sequenceID int64, filter *domain.OutreachEnrollmentFilter) ([]*domain.OutreachEnrollment, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM outreach_sequence_enrollments
+		WHERE sequence_id = $1 AND ($2::TEXT IS NULL OR status = $2)`,
+		sequenceID, filter.Status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count outreach enrollments: %w", err)
+	}
+
+	query := `
+		SELECT ` + outreachEnrollmentColumns + `
+		FROM outreach_sequence_enrollments
+		WHERE sequence_id = $1 AND ($2::TEXT IS NULL OR status = $2)
+		ORDER BY updated_at DESC, enrollment_id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, sequenceID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list outreach enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	enrollments := make([]*domain.OutreachEnrollment, 0)
+	for rows.Next() {
+		enrollment, err := scanOutreachEnrollment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating outreach enrollments: %w", err)
+	}
+
+	return enrollments, total, nil
+}
+
+// GetSequenceStats counts the enrollments of a sequence by status and the sends and replies of
+// each of its stepCount steps
+func (r *pgxOutreachSequenceRepository) GetSequenceStats(ctx context.Context, sequenceID int64, stepCount int) (*domain.OutreachSequenceStats, error) {
+	stats := &domain.OutreachSequenceStats{Steps: make([]domain.OutreachStepStats, 0, stepCount)}
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'scheduled'),
+			COUNT(*) FILTER (WHERE status = 'active'),
+			COUNT(*) FILTER (WHERE status = 'completed'),
+			COUNT(*) FILTER (WHERE status = 'replied'),
+			COUNT(*) FILTER (WHERE status = 'stopped'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE steps_sent > 0),
+			COALESCE(SUM(steps_sent), 0)
+		FROM outreach_sequence_enrollments
+		WHERE sequence_id = $1`, sequenceID,
+	).Scan(&stats.Enrolled, &stats.Scheduled, &stats.Active, &stats.Completed, &stats.Replied,
+		&stats.Stopped, &stats.Failed, &stats.Contacted, &stats.MessagesSent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outreach sequence stats: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT n,
+			COUNT(e.enrollment_id) FILTER (WHERE e.steps_sent >= n),
+			COUNT(e.enrollment_id) FILTER (WHERE e.status = 'replied' AND e.steps_sent = n)
+		FROM generate_series(1, $2::INTEGER) AS n
+		LEFT JOIN outreach_sequence_enrollments e ON e.sequence_id = $1
+		GROUP BY n
+		ORDER BY n`, sequenceID, stepCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outreach step stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var step domain.OutreachStepStats
+		if err := rows.Scan(&step.StepNumber, &step.Sent, &step.Replies); err != nil {
+			return nil, fmt.Errorf("failed to scan outreach step stats: %w", err)
+		}
+		stats.Steps = append(stats.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outreach step stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// MarkRepliedEnrollments marks enrollments replied at the first publisher message received in
+// their conversation since they were created. Completed enrollments are included so replies to
+// the last step count.
+func (r *pgxOutreachSequenceRepository) MarkRepliedEnrollments(ctx context.Context) (int, error) {
+	result, err := r.db.Exec(ctx, `
+		WITH replies AS (
+			SELECT e.enrollment_id, MIN(m.sent_at) AS replied_at
+			FROM outreach_sequence_enrollments e
+			JOIN publisher_messages m ON m.conversation_id = e.conversation_id
+			WHERE e.status IN ('scheduled', 'active', 'completed')
+			  AND m.sender_type = '`+domain.SenderTypePublisher+`'
+			  AND m.sent_at >= e.created_at
+			GROUP BY e.enrollment_id
+		)
+		UPDATE outreach_sequence_enrollments e
+		SET status = 'replied', replied_at = replies.replied_at, next_send_at = NULL
+		FROM replies
+		WHERE e.enrollment_id = replies.enrollment_id`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark replied outreach enrollments: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// StopClosedEnrollments stops pending enrollments whose conversation is no longer active
+func (r *pgxOutreachSequenceRepository) StopClosedEnrollments(ctx context.Context) (int, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE outreach_sequence_enrollments e
+		SET status = 'stopped', next_send_at = NULL, last_error = 'conversation is no longer active'
+		WHERE e.status IN `+pendingOutreachStatuses+`
+		  AND NOT EXISTS (
+			SELECT 1 FROM publisher_conversations c
+			WHERE c.conversation_id = e.conversation_id AND c.status = '`+domain.ConversationStatusActive+`'
+		  )`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stop closed outreach enrollments: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// ListDueEnrollments returns due enrollments of active sequences without a publisher reply
+func (r *pgxOutreachSequenceRepository) ListDueEnrollments(ctx context.Context, now time.Time, limit int) ([]*domain.OutreachEnrollment, error) {
+	query := `
+		SELECT e.enrollment_id, e.sequence_id, e.organization_id, e.list_id, e.publisher_domain, e.conversation_id,
+			e.status, e.steps_sent, e.next_send_at, e.last_sent_at, e.replied_at, e.last_error, e.created_at, e.updated_at
+		FROM outreach_sequence_enrollments e
+		JOIN outreach_sequences s ON s.sequence_id = e.sequence_id
+		WHERE e.status IN ` + pendingOutreachStatuses + `
+		  AND e.next_send_at <= $1
+		  AND e.conversation_id IS NOT NULL
+		  AND s.status = '` + domain.OutreachSequenceStatusActive + `'
+		  AND NOT EXISTS (
+			SELECT 1 FROM publisher_messages m
+			WHERE m.conversation_id = e.conversation_id
+			  AND m.sender_type = '` + domain.SenderTypePublisher + `'
+			  AND m.sent_at >= e.created_at
+		  )
+		ORDER BY e.next_send_at, e.enrollment_id
+		LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due outreach enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	enrollments := make([]*domain.OutreachEnrollment, 0)
+	for rows.Next() {
+		enrollment, err := scanOutreachEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due outreach enrollments: %w", err)
+	}
+	return enrollments, nil
+}
+
+// ClaimEnrollmentStep advances an enrollment past the step being sent
+func (r *pgxOutreachSequenceRepository) ClaimEnrollmentStep(ctx context.Context, enrollmentID int64, stepsSent int, sentAt time.Time, nextSendAt *time.Time) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE outreach_sequence_enrollments
+		SET steps_sent = steps_sent + 1, last_sent_at = $3, next_send_at = $4, last_error = NULL,
+			status = CASE WHEN $4::TIMESTAMPTZ IS NULL THEN 'completed' ELSE 'active' END
+		WHERE enrollment_id = $1 AND steps_sent = $2 AND status IN `+pendingOutreachStatuses,
+		enrollmentID, stepsSent, sentAt, nextSendAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim outreach enrollment step: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// FinishEnrollment ends an enrollment unless the publisher already replied
+func (r *pgxOutreachSequenceRepository) FinishEnrollment(ctx context.Context, enrollmentID int64, status string, lastError *string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE outreach_sequence_enrollments
+		SET status = $2, last_error = $3, next_send_at = NULL
+		WHERE enrollment_id = $1 AND status <> 'replied'`,
+		enrollmentID, status, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to finish outreach enrollment: %w", err)
+	}
+	return nil
+}
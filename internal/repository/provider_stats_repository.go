@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// ProviderStatsRepository defines the interface for provider stats warehouse operations
+type ProviderStatsRepository interface {
+	// Fact operations
+	ReplaceDailyStats(ctx context.Context, providerType string, statDate time.Time, stats []domain.ProviderDailyStat) (int, error)
+	GetDailyStats(ctx context.Context, providerType string, statDate time.Time, limit, offset int) ([]domain.ProviderDailyStat, error)
+
+	// Import run operations
+	CreateImport(ctx context.Context, imp *domain.ProviderStatsImport) error
+	UpdateImport(ctx context.Context, imp *domain.ProviderStatsImport) error
+	ListImports(ctx context.Context, providerType string, limit, offset int) ([]*domain.ProviderStatsImport, int, error)
+}
+
+// pgxProviderStatsRepository implements ProviderStatsRepository using pgx
+type pgxProviderStatsRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxProviderStatsRepository creates a new provider stats repository
+func NewPgxProviderStatsRepository(db *pgxpool.Pool) ProviderStatsRepository {
+	return &pgxProviderStatsRepository{db: db}
+}
+
+// ReplaceDailyStats atomically replaces all facts for a provider day, which makes re-imports idempotent.
+// Local campaign and affiliate IDs are resolved from the provider mapping tables after loading.
+func (r *pgxProviderStatsRepository) ReplaceDailyStats(ctx context.Context, providerType string, statDate time.Time, stats []domain.ProviderDailyStat) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM provider_daily_stats WHERE provider_type = $1 AND stat_date = $2`, providerType, statDate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear existing daily stats: %w", err)
+	}
+
+	now := time.Now()
+	rows := make([][]interface{}, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, []interface{}{
+			providerType, statDate, s.StatHour,
+			s.ProviderOfferID, s.ProviderAffiliateID,
+			s.Sub1, s.Sub2, s.Sub3, s.Sub4, s.Sub5,
+			s.CountryCode, s.DeviceType,
+			s.Impressions, s.Clicks, s.UniqueClicks, s.Conversions,
+			decimalToNumeric(s.Revenue), decimalToNumeric(s.Payout), s.Currency, now,
+		})
+	}
+
+	copied, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"provider_daily_stats"},
+		[]string{
+			"provider_type", "stat_date", "stat_hour",
+			"provider_offer_id", "provider_affiliate_id",
+			"sub1", "sub2", "sub3", "sub4", "sub5",
+			"country_code", "device_type",
+			"impressions", "clicks", "unique_clicks", "conversions",
+			"revenue", "payout", "currency", "imported_at",
+		},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy daily stats: %w", err)
+	}
+
+	// Resolve local campaigns from provider offer IDs
+	_, err = tx.Exec(ctx, `
+		UPDATE provider_daily_stats s
+		SET campaign_id = m.campaign_id
+		FROM campaign_provider_mappings m
+		WHERE s.provider_type = $1 AND s.stat_date = $2
+		  AND m.provider_type = s.provider_type
+		  AND m.provider_offer_id = s.provider_offer_id`, providerType, statDate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve campaigns for daily stats: %w", err)
+	}
+
+	// Resolve local affiliates from provider affiliate IDs
+	_, err = tx.Exec(ctx, `
+		UPDATE provider_daily_stats s
+		SET affiliate_id = m.affiliate_id
+		FROM affiliate_provider_mappings m
+		WHERE s.provider_type = $1 AND s.stat_date = $2
+		  AND m.provider_type = s.provider_type
+		  AND m.provider_affiliate_id = s.provider_affiliate_id`, providerType, statDate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve affiliates for daily stats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit daily stats: %w", err)
+	}
+
+	return int(copied), nil
+}
+
+// GetDailyStats retrieves the imported facts for a provider day
+func (r *pgxProviderStatsRepository) GetDailyStats(ctx context.Context, providerType string, statDate time.Time, limit, offset int) ([]domain.ProviderDailyStat, error) {
+	query := `
+		SELECT stat_id, provider_type, stat_date, stat_hour, provider_offer_id, provider_affiliate_id,
+		       sub1, sub2, sub3, sub4, sub5, country_code, device_type, campaign_id, affiliate_id,
+		       impressions, clicks, unique_clicks, conversions, revenue, payout, currency, imported_at
+		FROM provider_daily_stats
+		WHERE provider_type = $1 AND stat_date = $2
+		ORDER BY stat_id
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, providerType, statDate, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]domain.ProviderDailyStat, 0)
+	for rows.Next() {
+		var s domain.ProviderDailyStat
+		err := rows.Scan(
+			&s.StatID, &s.ProviderType, &s.StatDate, &s.StatHour, &s.ProviderOfferID, &s.ProviderAffiliateID,
+			&s.Sub1, &s.Sub2, &s.Sub3, &s.Sub4, &s.Sub5, &s.CountryCode, &s.DeviceType, &s.CampaignID, &s.AffiliateID,
+			&s.Impressions, &s.Clicks, &s.UniqueClicks, &s.Conversions, &s.Revenue, &s.Payout, &s.Currency, &s.ImportedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily stat: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CreateImport records the start of an import run
+func (r *pgxProviderStatsRepository) CreateImport(ctx context.Context, imp *domain.ProviderStatsImport) error {
+	query := `
+		INSERT INTO provider_stats_imports (provider_type, stat_date, status, triggered_by, row_count, error_message, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING import_id, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		imp.ProviderType,
+		imp.StatDate,
+		imp.Status,
+		imp.TriggeredBy,
+		imp.RowCount,
+		imp.ErrorMessage,
+		imp.StartedAt,
+	).Scan(&imp.ImportID, &imp.CreatedAt, &imp.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create provider stats import: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateImport records the outcome of an import run
+func (r *pgxProviderStatsRepository) UpdateImport(ctx context.Context, imp *domain.ProviderStatsImport) error {
+	query := `
+		UPDATE provider_stats_imports
+		SET status = $1, row_count = $2, error_message = $3, completed_at = $4
+		WHERE import_id = $5
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		imp.Status,
+		imp.RowCount,
+		imp.ErrorMessage,
+		imp.CompletedAt,
+		imp.ImportID,
+	).Scan(&imp.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update provider stats import: %w", err)
+	}
+
+	return nil
+}
+
+// ListImports lists import runs, most recent day first
+func (r *pgxProviderStatsRepository) ListImports(ctx context.Context, providerType string, limit, offset int) ([]*domain.ProviderStatsImport, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM provider_stats_imports WHERE provider_type = $1`, providerType).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count provider stats imports: %w", err)
+	}
+
+	query := `
+		SELECT import_id, provider_type, stat_date, status, triggered_by, row_count, error_message,
+		       started_at, completed_at, created_at, updated_at
+		FROM provider_stats_imports
+		WHERE provider_type = $1
+		ORDER BY stat_date DESC, import_id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, providerType, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list provider stats imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports := make([]*domain.ProviderStatsImport, 0)
+	for rows.Next() {
+		var imp domain.ProviderStatsImport
+		err := rows.Scan(
+			&imp.ImportID, &imp.ProviderType, &imp.StatDate, &imp.Status, &imp.TriggeredBy, &imp.RowCount, &imp.ErrorMessage,
+			&imp.StartedAt, &imp.CompletedAt, &imp.CreatedAt, &imp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan provider stats import: %w", err)
+		}
+		imports = append(imports, &imp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return imports, total, nil
+}
+
+// decimalToNumeric converts a decimal to a pgtype.Numeric for use with COPY, which encodes in binary format
+func decimalToNumeric(d decimal.Decimal) pgtype.Numeric {
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.Exponent(), Valid: true}
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PublisherConversionRepository defines the interface for publisher conversion data access
+type PublisherConversionRepository interface {
+	// ClaimConversion inserts a pending conversion for the publisher, or resumes a pending one that
+	// was released or whose claim expired, returning what it already set up. It returns false when
+	// the publisher is already converted or being converted by another request.
+	ClaimConversion(ctx context.Context, conversion *domain.PublisherConversion) (bool, error)
+	// RecordClaimProgress stores the affiliate organization, affiliate and invitation set up so far
+	// for a pending conversion
+	RecordClaimProgress(ctx context.Context, conversion *domain.PublisherConversion) error
+	// CompleteConversion fills in a pending conversion and links it to the most recent conversation
+	// with the publisher, preferring an active one
+	CompleteConversion(ctx context.Context, conversion *domain.PublisherConversion) error
+	// ReleaseConversion ends the claim on a pending conversion whose setup failed, so that it can be
+	// resumed right away
+	ReleaseConversion(ctx context.Context, conversionID int64) error
+	GetConversionByID(ctx context.Context, conversionID int64) (*domain.PublisherConversion, error)
+	GetConversionByDomain(ctx context.Context, organizationID int64, publisherDomain string) (*domain.PublisherConversion, error)
+	ListConversions(ctx context.Context, organizationID int64, filter *domain.PublisherConversionFilter) ([]*domain.PublisherConversion, int, error)
+	// RefreshProgress records the association and first tracking link of the conversions that are
+	// not linked yet, of one organization or of all organizations when organizationID is nil
+	RefreshProgress(ctx context.Context, organizationID *int64) error
+	GetFunnel(ctx context.Context, organizationID int64, from, to time.Time) (*domain.PublisherConversionFunnel, error)
+
+	// FindAffiliateOrganizationByDomain returns the affiliate organization whose website is on the
+	// publisher domain, or domain.ErrNotFound
+	FindAffiliateOrganizationByDomain(ctx context.Context, publisherDomain string) (int64, error)
+}
+
+// pgxPublisherConversionRepository implements PublisherConversionRepository using pgx
+type pgxPublisherConversionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxPublisherConversionRepository creates a new publisher conversion repository
+func NewPgxPublisherConversionRepository(db *pgxpool.Pool) PublisherConversionRepository {
+	return &pgxPublisherConversionRepository{db: db}
+}
+
+const publisherConversionColumns = `conversion_id, organization_id, publisher_domain, list_id, item_id, conversation_id,
+	COALESCE(affiliate_org_id, 0), affiliate_id, contact_email, invitation_id, association_id, first_tracking_link_id,
+	status, created_by_user_id, conversation_started_at, invited_at, associated_at, first_link_at,
+	created_at, updated_at`
+
+// prefixedPublisherConversionColumns are the conversion columns qualified with the pc alias, for
+// statements that join other tables
+const prefixedPublisherConversionColumns = `pc.conversion_id, pc.organization_id, pc.publisher_domain, pc.list_id, pc.item_id,
+	pc.conversation_id, COALESCE(pc.affiliate_org_id, 0), pc.affiliate_id, pc.contact_email, pc.invitation_id, pc.association_id,
+	pc.first_tracking_link_id, pc.status, pc.created_by_user_id, pc.conversation_started_at, pc.invited_at, pc.associated_at,
+	pc.first_link_at, pc.created_at, pc.updated_at`
+
+func scanPublisherConversion(row pgx.Row) (*domain.PublisherConversion, error) {
+	conversion := &domain.PublisherConversion{}
+	err := row.Scan(
+		&conversion.ConversionID, &conversion.OrganizationID, &conversion.PublisherDomain, &conversion.ListID,
+		&conversion.ItemID, &conversion.ConversationID, &conversion.AffiliateOrgID, &conversion.AffiliateID,
+		&conversion.ContactEmail, &conversion.InvitationID, &conversion.AssociationID, &conversion.FirstTrackingLinkID,
+		&conversion.Status, &conversion.CreatedByUserID, &conversion.ConversationStartedAt, &conversion.InvitedAt,
+		&conversion.AssociatedAt, &conversion.FirstLinkAt, &conversion.CreatedAt, &conversion.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan publisher conversion: %w", err)
+	}
+	return conversion, nil
+}
+
+// ClaimConversion inserts or resumes a pending conversion as a claim on the publisher domain
+func (r *pgxPublisherConversionRepository) ClaimConversion(ctx context.Context, conversion *domain.PublisherConversion) (bool, error) {
+	query := `
+		INSERT INTO publisher_conversions (organization_id, publisher_domain, list_id, item_id, status, created_by_user_id, claimed_until)
+		VALUES ($1, $2, $3, $4, '` + domain.PublisherConversionStatusPending + `', $5, NOW() + $6::FLOAT8 * INTERVAL '1 second')
+		ON CONFLICT (organization_id, publisher_domain) DO UPDATE
+		SET list_id = EXCLUDED.list_id, item_id = EXCLUDED.item_id, created_by_user_id = EXCLUDED.created_by_user_id,
+			claimed_until = EXCLUDED.claimed_until
+		WHERE publisher_conversions.status = '` + domain.PublisherConversionStatusPending + `'
+		  AND (publisher_conversions.claimed_until IS NULL OR publisher_conversions.claimed_until < NOW())
+		RETURNING ` + publisherConversionColumns
+
+	claimed, err := scanPublisherConversion(r.db.QueryRow(ctx, query,
+		conversion.OrganizationID, conversion.PublisherDomain, conversion.ListID, conversion.ItemID,
+		conversion.CreatedByUserID, domain.PublisherConversionClaimTimeout.Seconds()))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim publisher conversion: %w", err)
+	}
+
+	*conversion = *claimed
+	return true, nil
+}
+
+// RecordClaimProgress stores the setup progress of a pending conversion
+func (r *pgxPublisherConversionRepository) RecordClaimProgress(ctx context.Context, conversion *domain.PublisherConversion) error {
+	var affiliateOrgID *int64
+	if conversion.AffiliateOrgID != 0 {
+		affiliateOrgID = &conversion.AffiliateOrgID
+	}
+	_, err := r.db.Exec(ctx, `
+		UPDATE publisher_conversions
+		SET affiliate_org_id = $2, affiliate_id = $3, invitation_id = $4, contact_email = $5
+		WHERE conversion_id = $1 AND status = $6`,
+		conversion.ConversionID, affiliateOrgID, conversion.AffiliateID, conversion.InvitationID, conversion.ContactEmail,
+		domain.PublisherConversionStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to record publisher conversion progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteConversion fills in a pending conversion
+func (r *pgxPublisherConversionRepository) CompleteConversion(ctx context.Context, conversion *domain.PublisherConversion) error {
+	query := `
+		WITH conversation AS (
+			SELECT conversation_id, created_at
+			FROM publisher_conversations
+			WHERE organization_id = $2 AND publisher_domain = $3
+			ORDER BY (status = '` + domain.ConversationStatusActive + `') DESC, created_at DESC
+			LIMIT 1
+		)
+		UPDATE publisher_conversions pc
+		SET conversation_id = c.conversation_id, conversation_started_at = c.created_at,
+			affiliate_org_id = $4, affiliate_id = $5, contact_email = $6, invitation_id = $7, status = $8,
+			invited_at = CURRENT_TIMESTAMP, created_at = CURRENT_TIMESTAMP, claimed_until = NULL
+		FROM (SELECT 1) AS one
+		LEFT JOIN conversation c ON TRUE
+		WHERE pc.conversion_id = $1 AND pc.status = '` + domain.PublisherConversionStatusPending + `'
+		RETURNING ` + prefixedPublisherConversionColumns
+
+	completed, err := scanPublisherConversion(r.db.QueryRow(ctx, query,
+		conversion.ConversionID, conversion.OrganizationID, conversion.PublisherDomain,
+		conversion.AffiliateOrgID, conversion.AffiliateID, conversion.ContactEmail, conversion.InvitationID,
+		conversion.Status))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return err
+		}
+		return fmt.Errorf("failed to complete publisher conversion: %w", err)
+	}
+
+	*conversion = *completed
+	return nil
+}
+
+// ReleaseConversion ends the claim on a pending conversion
+func (r *pgxPublisherConversionRepository) ReleaseConversion(ctx context.Context, conversionID int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE publisher_conversions SET claimed_until = NULL WHERE conversion_id = $1 AND status = $2`,
+		conversionID, domain.PublisherConversionStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to release publisher conversion: %w", err)
+	}
+	return nil
+}
+
+// GetConversionByID retrieves a conversion by ID
+func (r *pgxPublisherConversionRepository) GetConversionByID(ctx context.Context, conversionID int64) (*domain.PublisherConversion, error) {
+	query := `SELECT ` + publisherConversionColumns + ` FROM publisher_conversions WHERE conversion_id = $1 AND status <> $2`
+	return scanPublisherConversion(r.db.QueryRow(ctx, query, conversionID, domain.PublisherConversionStatusPending))
+}
+
+// GetConversionByDomain retrieves the conversion of a publisher domain for an organization,
+// including a pending one
+func (r *pgxPublisherConversionRepository) GetConversionByDomain(ctx context.Context, organizationID int64, publisherDomain string) (*domain.PublisherConversion, error) {
+	query := `SELECT ` + publisherConversionColumns + ` FROM publisher_conversions WHERE organization_id = $1 AND publisher_domain = $2`
+	return scanPublisherConversion(r.db.QueryRow(ctx, query, organizationID, publisherDomain))
+}
+
+// ListConversions lists the conversions of an organization, most recently invited first
+func (r *pgxPublisherConversionRepository) ListConversions(ctx context.Context, organizationID int64, filter *domain.PublisherConversionFilter) ([]*domain.PublisherConversion, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM publisher_conversions
+		WHERE organization_id = $1 AND ($2::TEXT IS NULL OR status = $2) AND status <> $3`,
+		organizationID, filter.Status, domain.PublisherConversionStatusPending).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count publisher conversions: %w", err)
+	}
+
+	query := `
+		SELECT ` + publisherConversionColumns + `
+		FROM publisher_conversions
+		WHERE organization_id = $1 AND ($2::TEXT IS NULL OR status = $2) AND status <> $5
+		ORDER BY invited_at DESC, conversion_id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, organizationID, filter.Status, filter.Limit, filter.Offset, domain.PublisherConversionStatusPending)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list publisher conversions: %w", err)
+	}
+	defer rows.Close()
+
+	conversions := make([]*domain.PublisherConversion, 0)
+	for rows.Next() {
+		conversion, err := scanPublisherConversion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		conversions = append(conversions, conversion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating publisher conversions: %w", err)
+	}
+
+	return conversions, total, nil
+}
+
+// RefreshProgress records the association and first tracking link of open conversions. The first
+// tracking link is the earliest link of an affiliate of the affiliate organization on a campaign
+// of the advertiser organization.
+func (r *pgxPublisherConversionRepository) RefreshProgress(ctx context.Context, organizationID *int64) error {
+	query := `
+		WITH progress AS (
+			SELECT pc.conversion_id, oa.association_id, oa.status AS association_status,
+				COALESCE(oa.approved_at, oa.updated_at) AS association_active_at,
+				link.tracking_link_id, link.created_at AS link_created_at
+			FROM publisher_conversions pc
+			LEFT JOIN organization_associations oa
+				ON oa.advertiser_org_id = pc.organization_id AND oa.affiliate_org_id = pc.affiliate_org_id
+			LEFT JOIN LATERAL (
+				SELECT tl.tracking_link_id, tl.created_at
+				FROM tracking_links tl
+				JOIN affiliates a ON a.affiliate_id = tl.affiliate_id
+				JOIN campaigns c ON c.campaign_id = tl.campaign_id
+				WHERE a.organization_id = pc.affiliate_org_id AND c.organization_id = pc.organization_id
+				ORDER BY tl.created_at, tl.tracking_link_id
+				LIMIT 1
+			) link ON TRUE
+			WHERE ($1::BIGINT IS NULL OR pc.organization_id = $1)
+			  AND pc.status NOT IN ('` + domain.PublisherConversionStatusPending + `', '` + domain.PublisherConversionStatusLinked + `')
+		)
+		UPDATE publisher_conversions pc
+		SET association_id = COALESCE(p.association_id, pc.association_id),
+			associated_at = CASE
+				WHEN pc.associated_at IS NULL AND p.association_status = 'active' THEN p.association_active_at
+				ELSE pc.associated_at END,
+			first_tracking_link_id = p.tracking_link_id,
+			first_link_at = p.link_created_at,
+			status = CASE
+				WHEN p.tracking_link_id IS NOT NULL THEN '` + domain.PublisherConversionStatusLinked + `'
+				WHEN pc.associated_at IS NOT NULL OR p.association_status = 'active' THEN '` + domain.PublisherConversionStatusAssociated + `'
+				ELSE pc.status END
+		FROM progress p
+		WHERE pc.conversion_id = p.conversion_id
+		  AND (p.association_id IS DISTINCT FROM pc.association_id
+			OR (pc.associated_at IS NULL AND p.association_status = 'active')
+			OR p.tracking_link_id IS NOT NULL)`
+
+	if _, err := r.db.Exec(ctx, query, organizationID); err != nil {
+		return fmt.Errorf("failed to refresh publisher conversion progress: %w", err)
+	}
+	return nil
+}
+
+// GetFunnel counts the conversations started and the conversions invited in [from, to)
+func (r *pgxPublisherConversionRepository) GetFunnel(ctx context.Context, organizationID int64, from, to time.Time) (*domain.PublisherConversionFunnel, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM publisher_conversations
+			 WHERE organization_id = $1 AND created_at >= $2 AND created_at < $3),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE conversation_id IS NOT NULL),
+			COUNT(*) FILTER (WHERE associated_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE first_link_at IS NOT NULL),
+			AVG(EXTRACT(EPOCH FROM associated_at - invited_at)) / 86400,
+			AVG(EXTRACT(EPOCH FROM first_link_at - COALESCE(associated_at, invited_at))) / 86400
+		FROM publisher_conversions
+		WHERE organization_id = $1 AND invited_at >= $2 AND invited_at < $3
+		  AND status <> '` + domain.PublisherConversionStatusPending + `'`
+
+	funnel := &domain.PublisherConversionFunnel{From: from, To: to}
+	err := r.db.QueryRow(ctx, query, organizationID, from, to).Scan(
+		&funnel.Conversations, &funnel.Invited, &funnel.FromConversation, &funnel.Associated, &funnel.Linked,
+		&funnel.AvgDaysToAssociation, &funnel.AvgDaysToFirstLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publisher conversion funnel: %w", err)
+	}
+
+	return funnel, nil
+}
+
+// FindAffiliateOrganizationByDomain matches affiliate organization websites like
+// "https://www.example.com/blog" against the publisher domain
+func (r *pgxPublisherConversionRepository) FindAffiliateOrganizationByDomain(ctx context.Context, publisherDomain string) (int64, error) {
+	query := `
+		SELECT o.organization_id
+		FROM organizations o
+		JOIN affiliate_extra_info aei ON aei.organization_id = o.organization_id
+		WHERE o.type = 'affiliate'
+		  AND regexp_replace(regexp_replace(LOWER(TRIM(aei.website)), '^[a-z][a-z0-9+.-]*://', ''), '^www\.|[/:?#].*$', '', 'g') = LOWER($1)
+		ORDER BY o.organization_id
+		LIMIT 1`
+
+	var organizationID int64
+	err := r.db.QueryRow(ctx, query, publisherDomain).Scan(&organizationID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, domain.ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to find affiliate organization by domain: %w", err)
+	}
+	return organizationID, nil
+}
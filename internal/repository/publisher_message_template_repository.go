@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PublisherMessageTemplateRepository defines the interface for message template data access
+type PublisherMessageTemplateRepository interface {
+	CreateTemplate(ctx context.Context, template *domain.PublisherMessageTemplate) error
+	GetTemplateByID(ctx context.Context, templateID int64) (*domain.PublisherMessageTemplate, error)
+	ListTemplates(ctx context.Context, organizationID int64) ([]*domain.PublisherMessageTemplate, error)
+	UpdateTemplate(ctx context.Context, template *domain.PublisherMessageTemplate) error
+	DeleteTemplate(ctx context.Context, templateID int64) error
+}
+
+// pgxPublisherMessageTemplateRepository implements PublisherMessageTemplateRepository using pgx
+type pgxPublisherMessageTemplateRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxPublisherMessageTemplateRepository creates a new message template repository
+func NewPgxPublisherMessageTemplateRepository(db *pgxpool.Pool) PublisherMessageTemplateRepository {
+	return &pgxPublisherMessageTemplateRepository{db: db}
+}
+
+const publisherMessageTemplateColumns = `template_id, organization_id, name, subject, body, created_by_user_id, created_at, updated_at`
+
+func scanPublisherMessageTemplate(row pgx.Row) (*domain.PublisherMessageTemplate, error) {
+	template := &domain.PublisherMessageTemplate{}
+	err := row.Scan(
+		&template.TemplateID, &template.OrganizationID, &template.Name, &template.Subject, &template.Body,
+		&template.CreatedByUserID, &template.CreatedAt, &template.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan message template: %w", err)
+	}
+	return template, nil
+}
+
+// CreateTemplate creates a message template
+func (r *pgxPublisherMessageTemplateRepository) CreateTemplate(ctx context.Context, template *domain.PublisherMessageTemplate) error {
+	query := `
+		INSERT INTO publisher_message_templates (organization_id, name, subject, body, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING template_id, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		template.OrganizationID, template.Name, template.Subject, template.Body, template.CreatedByUserID,
+	).Scan(&template.TemplateID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create message template: %w", err)
+	}
+	return nil
+}
+
+// GetTemplateByID returns a message template
+func (r *pgxPublisherMessageTemplateRepository) GetTemplateByID(ctx context.Context, templateID int64) (*domain.PublisherMessageTemplate, error) {
+	query := `SELECT ` + publisherMessageTemplateColumns + ` FROM publisher_message_templates WHERE template_id = $1`
+	return scanPublisherMessageTemplate(r.db.QueryRow(ctx, query, templateID))
+}
+
+// ListTemplates lists the message templates of an organization by name
+func (r *pgxPublisherMessageTemplateRepository) ListTemplates(ctx context.Context, organizationID int64) ([]*domain.PublisherMessageTemplate, error) {
+	query := `SELECT ` + publisherMessageTemplateColumns + `
+		FROM publisher_message_templates
+		WHERE organization_id = $1
+		ORDER BY name`
+
+	rows, err := r.db.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]*domain.PublisherMessageTemplate, 0)
+	for rows.Next() {
+		template, err := scanPublisherMessageTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate message templates: %w", err)
+	}
+	return templates, nil
+}
+
+// UpdateTemplate saves the name, subject and body of a template
+func (r *pgxPublisherMessageTemplateRepository) UpdateTemplate(ctx context.Context, template *domain.PublisherMessageTemplate) error {
+	query := `
+		UPDATE publisher_message_templates
+		SET name = $2, subject = $3, body = $4
+		WHERE template_id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(ctx, query, template.TemplateID, template.Name, template.Subject, template.Body).Scan(&template.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update message template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate deletes a template; messages sent with it are kept
+func (r *pgxPublisherMessageTemplateRepository) DeleteTemplate(ctx context.Context, templateID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM publisher_message_templates WHERE template_id = $1`, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to delete message template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
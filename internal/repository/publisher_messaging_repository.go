@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/affiliate-backend/internal/domain"
 	"github.com/jackc/pgx/v5"
@@ -16,7 +17,8 @@ type PublisherMessagingRepository interface {
 	// Conversation operations
 	CreateConversation(ctx context.Context, conversation *domain.PublisherConversation) error
 	GetConversationByID(ctx context.Context, conversationID int64) (*domain.PublisherConversation, error)
-	GetConversationsByOrganization(ctx context.Context, organizationID int64, status string, limit, offset int) ([]domain.PublisherConversation, int, error)
+	// GetConversationsByOrganization lists conversations with the unread count of filter.UserID
+	GetConversationsByOrganization(ctx context.Context, organizationID int64, filter *domain.ConversationListFilter, limit, offset int) ([]domain.PublisherConversation, int, error)
 	GetConversationByPublisher(ctx context.Context, organizationID int64, publisherDomain string, status string) (*domain.PublisherConversation, error)
 	UpdateConversationStatus(ctx context.Context, conversationID int64, status string) error
 	DeleteConversation(ctx context.Context, conversationID int64) error
@@ -24,9 +26,25 @@ type PublisherMessagingRepository interface {
 	// Message operations
 	CreateMessage(ctx context.Context, message *domain.PublisherMessage) error
 	GetMessagesByConversation(ctx context.Context, conversationID int64, limit, offset int) ([]domain.PublisherMessage, int, error)
+	GetMessageByID(ctx context.Context, messageID int64) (*domain.PublisherMessage, error)
 	GetMessageByExternalID(ctx context.Context, externalMessageID string) (*domain.PublisherMessage, error)
+	GetMessageByEmailMessageID(ctx context.Context, emailMessageIDs []string) (*domain.PublisherMessage, error)
+	UpdateMessageMetadata(ctx context.Context, messageID int64, metadata map[string]interface{}) error
 	DeleteMessage(ctx context.Context, messageID int64) error
 
+	// Read state operations. Only publisher messages can be unread.
+	// MarkMessagesRead marks the publisher messages of a conversation, up to a message when given,
+	// as read by the user and returns how many were newly marked
+	MarkMessagesRead(ctx context.Context, conversationID int64, userID string, upToMessageID *int64) (int, error)
+	MarkMessageRead(ctx context.Context, messageID int64, userID string) error
+	MarkMessageUnread(ctx context.Context, messageID int64, userID string) error
+	// GetMessageReads returns when the user read the messages of a conversation, by message ID
+	GetMessageReads(ctx context.Context, conversationID int64, userID string) (map[int64]time.Time, error)
+	CountUnreadMessages(ctx context.Context, organizationID int64, userID string) (int, error)
+
+	// SearchMessages full-text searches the messages of an organization's conversations, best match first
+	SearchMessages(ctx context.Context, organizationID int64, query string, conversationID *int64, limit, offset int) ([]domain.MessageSearchResult, int, error)
+
 	// Combined operations
 	GetConversationWithMessages(ctx context.Context, conversationID int64, messageLimit, messageOffset int) (*domain.PublisherConversation, []domain.PublisherMessage, int, error)
 }
@@ -125,20 +143,34 @@ func (r *publisherMessagingRepository) GetConversationByID(ctx context.Context,
 	return &conversation, nil
 }
 
-func (r *publisherMessagingRepository) GetConversationsByOrganization(ctx context.Context, organizationID int64, status string, limit, offset int) ([]domain.PublisherConversation, int, error) {
+func (r *publisherMessagingRepository) GetConversationsByOrganization(ctx context.Context, organizationID int64, filter *domain.ConversationListFilter, limit, offset int) ([]domain.PublisherConversation, int, error) {
 	// Build query with optional status filter
 	whereClause := "WHERE organization_id = $1"
 	args := []interface{}{organizationID}
 	argIndex := 2
 
-	if status != "" {
+	if filter.Status != "" {
 		whereClause += fmt.Sprintf(" AND status = $%d", argIndex)
-		args = append(args, status)
+		args = append(args, filter.Status)
 		argIndex++
 	}
 
+	// Unread publisher messages of the user; without a user nothing is unread
+	unreadCount := "0"
+	if filter.UserID != "" {
+		unreadCount = fmt.Sprintf(`(SELECT COUNT(*) FROM publisher_messages m
+		        WHERE m.conversation_id = pc.conversation_id AND m.sender_type = 'publisher'
+		          AND NOT EXISTS (SELECT 1 FROM publisher_message_reads r WHERE r.message_id = m.message_id AND r.user_id = $%d::uuid))`, argIndex)
+		args = append(args, filter.UserID)
+		argIndex++
+
+		if filter.UnreadOnly {
+			whereClause += " AND " + unreadCount + " > 0"
+		}
+	}
+
 	// Count query
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM publisher_conversations %s", whereClause)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM publisher_conversations pc %s", whereClause)
 	var total int
 	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
@@ -149,11 +181,12 @@ func (r *publisherMessagingRepository) GetConversationsByOrganization(ctx contex
 	query := fmt.Sprintf(`
 		SELECT conversation_id, organization_id, publisher_domain, list_id, subject, status,
 		       created_at, updated_at, last_message_at,
-		       (SELECT COUNT(*) FROM publisher_messages WHERE conversation_id = pc.conversation_id) as message_count
+		       (SELECT COUNT(*) FROM publisher_messages WHERE conversation_id = pc.conversation_id) as message_count,
+		       %s as unread_count
 		FROM publisher_conversations pc
 		%s
 		ORDER BY last_message_at DESC
-		LIMIT $%d OFFSET $%d`, whereClause, argIndex, argIndex+1)
+		LIMIT $%d OFFSET $%d`, unreadCount, whereClause, argIndex, argIndex+1)
 
 	args = append(args, limit, offset)
 
@@ -177,6 +210,7 @@ func (r *publisherMessagingRepository) GetConversationsByOrganization(ctx contex
 			&conversation.UpdatedAt,
 			&conversation.LastMessageAt,
 			&conversation.MessageCount,
+			&conversation.UnreadCount,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
@@ -343,6 +377,78 @@ func (r *publisherMessagingRepository) GetMessagesByConversation(ctx context.Con
 	return messages, total, nil
 }
 
+func (r *publisherMessagingRepository) GetMessageByID(ctx context.Context, messageID int64) (*domain.PublisherMessage, error) {
+	query := `
+		SELECT message_id, conversation_id, sender_type, sender_id, content, message_type,
+		       external_message_id, metadata, sent_at
+		FROM publisher_messages
+		WHERE message_id = $1`
+
+	message, err := scanPublisherMessage(r.db.QueryRow(ctx, query, messageID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetMessageByEmailMessageID finds the most recent message whose email external ID or sent
+// email Message-ID matches one of the given Message-IDs, e.g. from In-Reply-To/References headers
+func (r *publisherMessagingRepository) GetMessageByEmailMessageID(ctx context.Context, emailMessageIDs []string) (*domain.PublisherMessage, error) {
+	if len(emailMessageIDs) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	query := `
+		SELECT message_id, conversation_id, sender_type, sender_id, content, message_type,
+		       external_message_id, metadata, sent_at
+		FROM publisher_messages
+		WHERE external_message_id = ANY($1)
+		   OR (metadata->'email_delivery'->>'message_id') = ANY($1)
+		ORDER BY sent_at DESC
+		LIMIT 1`
+
+	message, err := scanPublisherMessage(r.db.QueryRow(ctx, query, emailMessageIDs))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get message by email message ID: %w", err)
+	}
+
+	return message, nil
+}
+
+// scanPublisherMessage scans a single publisher message row
+func scanPublisherMessage(row pgx.Row) (*domain.PublisherMessage, error) {
+	var message domain.PublisherMessage
+	var metadataJSON JSONB
+
+	err := row.Scan(
+		&message.MessageID,
+		&message.ConversationID,
+		&message.SenderType,
+		&message.SenderID,
+		&message.Content,
+		&message.MessageType,
+		&message.ExternalMessageID,
+		&metadataJSON,
+		&message.SentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadataJSON != nil {
+		message.Metadata = map[string]interface{}(metadataJSON)
+	}
+
+	return &message, nil
+}
+
 func (r *publisherMessagingRepository) GetMessageByExternalID(ctx context.Context, externalMessageID string) (*domain.PublisherMessage, error) {
 	query := `
 		SELECT message_id, conversation_id, sender_type, sender_id, content, message_type,
@@ -379,6 +485,21 @@ func (r *publisherMessagingRepository) GetMessageByExternalID(ctx context.Contex
 	return &message, nil
 }
 
+func (r *publisherMessagingRepository) UpdateMessageMetadata(ctx context.Context, messageID int64, metadata map[string]interface{}) error {
+	query := `UPDATE publisher_messages SET metadata = $1 WHERE message_id = $2`
+
+	result, err := r.db.Exec(ctx, query, JSONB(metadata), messageID)
+	if err != nil {
+		return fmt.Errorf("failed to update message metadata: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *publisherMessagingRepository) DeleteMessage(ctx context.Context, messageID int64) error {
 	query := `DELETE FROM publisher_messages WHERE message_id = $1`
 
@@ -394,6 +515,161 @@ func (r *publisherMessagingRepository) DeleteMessage(ctx context.Context, messag
 	return nil
 }
 
+// Read state operations
+
+func (r *publisherMessagingRepository) MarkMessagesRead(ctx context.Context, conversationID int64, userID string, upToMessageID *int64) (int, error) {
+	query := `
+		INSERT INTO publisher_message_reads (message_id, user_id)
+		SELECT message_id, $2::uuid
+		FROM publisher_messages
+		WHERE conversation_id = $1 AND sender_type = 'publisher'
+		  AND ($3::bigint IS NULL OR message_id <= $3)
+		ON CONFLICT (message_id, user_id) DO NOTHING`
+
+	result, err := r.db.Exec(ctx, query, conversationID, userID, upToMessageID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages as read: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *publisherMessagingRepository) MarkMessageRead(ctx context.Context, messageID int64, userID string) error {
+	query := `
+		INSERT INTO publisher_message_reads (message_id, user_id)
+		VALUES ($1, $2::uuid)
+		ON CONFLICT (message_id, user_id) DO NOTHING`
+
+	if _, err := r.db.Exec(ctx, query, messageID, userID); err != nil {
+		return fmt.Errorf("failed to mark message as read: %w", err)
+	}
+
+	return nil
+}
+
+func (r *publisherMessagingRepository) MarkMessageUnread(ctx context.Context, messageID int64, userID string) error {
+	query := `DELETE FROM publisher_message_reads WHERE message_id = $1 AND user_id = $2::uuid`
+
+	if _, err := r.db.Exec(ctx, query, messageID, userID); err != nil {
+		return fmt.Errorf("failed to mark message as unread: %w", err)
+	}
+
+	return nil
+}
+
+func (r *publisherMessagingRepository) GetMessageReads(ctx context.Context, conversationID int64, userID string) (map[int64]time.Time, error) {
+	query := `
+		SELECT r.message_id, r.read_at
+		FROM publisher_message_reads r
+		JOIN publisher_messages m ON m.message_id = r.message_id
+		WHERE m.conversation_id = $1 AND r.user_id = $2::uuid`
+
+	rows, err := r.db.Query(ctx, query, conversationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message reads: %w", err)
+	}
+	defer rows.Close()
+
+	reads := make(map[int64]time.Time)
+	for rows.Next() {
+		var messageID int64
+		var readAt time.Time
+		if err := rows.Scan(&messageID, &readAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message read: %w", err)
+		}
+		reads[messageID] = readAt
+	}
+
+	return reads, rows.Err()
+}
+
+func (r *publisherMessagingRepository) CountUnreadMessages(ctx context.Context, organizationID int64, userID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM publisher_messages m
+		JOIN publisher_conversations pc ON pc.conversation_id = m.conversation_id
+		WHERE pc.organization_id = $1 AND m.sender_type = 'publisher'
+		  AND NOT EXISTS (SELECT 1 FROM publisher_message_reads r WHERE r.message_id = m.message_id AND r.user_id = $2::uuid)`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, organizationID, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// Search operations
+
+func (r *publisherMessagingRepository) SearchMessages(ctx context.Context, organizationID int64, query string, conversationID *int64, limit, offset int) ([]domain.MessageSearchResult, int, error) {
+	whereClause := `
+		WHERE pc.organization_id = $1
+		  AND m.search_vector @@ websearch_to_tsquery('english', $2)
+		  AND ($3::bigint IS NULL OR m.conversation_id = $3)`
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM publisher_messages m
+		JOIN publisher_conversations pc ON pc.conversation_id = m.conversation_id` + whereClause
+
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, organizationID, query, conversationID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count message search results: %w", err)
+	}
+
+	searchQuery := `
+		SELECT m.message_id, m.conversation_id, m.sender_type, m.sender_id, m.content, m.message_type,
+		       m.external_message_id, m.metadata, m.sent_at,
+		       pc.subject, pc.publisher_domain,
+		       ts_headline('english', m.content, websearch_to_tsquery('english', $2), 'MaxFragments=2, MaxWords=30, MinWords=10'),
+		       ts_rank(m.search_vector, websearch_to_tsquery('english', $2)) AS rank
+		FROM publisher_messages m
+		JOIN publisher_conversations pc ON pc.conversation_id = m.conversation_id` + whereClause + `
+		ORDER BY rank DESC, m.sent_at DESC
+		LIMIT $4 OFFSET $5`
+
+	rows, err := r.db.Query(ctx, searchQuery, organizationID, query, conversationID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]domain.MessageSearchResult, 0)
+	for rows.Next() {
+		var result domain.MessageSearchResult
+		var metadataJSON JSONB
+		var rank float32
+
+		err := rows.Scan(
+			&result.Message.MessageID,
+			&result.Message.ConversationID,
+			&result.Message.SenderType,
+			&result.Message.SenderID,
+			&result.Message.Content,
+			&result.Message.MessageType,
+			&result.Message.ExternalMessageID,
+			&metadataJSON,
+			&result.Message.SentAt,
+			&result.Subject,
+			&result.PublisherDomain,
+			&result.Headline,
+			&rank,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message search result: %w", err)
+		}
+
+		if metadataJSON != nil {
+			result.Message.Metadata = map[string]interface{}(metadataJSON)
+		}
+		result.Rank = float64(rank)
+
+		results = append(results, result)
+	}
+
+	return results, total, rows.Err()
+}
+
 // Combined operations
 
 func (r *publisherMessagingRepository) GetConversationWithMessages(ctx context.Context, conversationID int64, messageLimit, messageOffset int) (*domain.PublisherConversation, []domain.PublisherMessage, int, error) {
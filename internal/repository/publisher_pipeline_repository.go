@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PublisherPipelineRepository defines the interface for outreach pipeline data access
+type PublisherPipelineRepository interface {
+	// Stage operations
+	ListStages(ctx context.Context, organizationID int64) ([]*domain.PipelineStage, error)
+	// CreateDefaultStages adds the default stages whose keys the organization does not use yet
+	CreateDefaultStages(ctx context.Context, organizationID int64) error
+	CreateStage(ctx context.Context, stage *domain.PipelineStage) error
+	UpdateStage(ctx context.Context, stage *domain.PipelineStage) error
+	DeleteStage(ctx context.Context, stageID int64) error
+	// ReorderStages sets each stage's position to its index in stageIDs
+	ReorderStages(ctx context.Context, organizationID int64, stageIDs []int64) error
+	CountItemsInStage(ctx context.Context, stageID int64) (int, error)
+
+	// Item operations
+	// MoveItem sets the stage and legacy status of an item and records the change in its history
+	MoveItem(ctx context.Context, item *domain.FavoritePublisherListItem, status string, change *domain.PipelineStageChange) error
+	// MoveItems moves the items of a list with the given domains into a stage, recording a change
+	// for each item that was in another stage, and returns how many items moved
+	MoveItems(ctx context.Context, listID int64, publisherDomains []string, stage *domain.PipelineStage, status string, changedBy *string, reason string, note *string) (int, error)
+	CreateStageChange(ctx context.Context, change *domain.PipelineStageChange) error
+	ListStageChanges(ctx context.Context, itemID int64, limit int) ([]*domain.PipelineStageChange, error)
+	// UpdateFollowUp sets the owner and next action of an item; a changed next action is reminded again
+	UpdateFollowUp(ctx context.Context, item *domain.FavoritePublisherListItem) error
+	// GetListConversationIDs returns the active conversation per publisher domain of a list,
+	// preferring conversations started from the list itself
+	GetListConversationIDs(ctx context.Context, organizationID, listID int64) (map[string]int64, error)
+
+	// Reminder operations
+	ListDueReminders(ctx context.Context, now time.Time, limit int) ([]*domain.PipelineReminder, error)
+	// ClaimReminder marks the reminder of an item as sent only if it still has the expected
+	// next action and was not sent yet, so that concurrent schedulers send each reminder once
+	ClaimReminder(ctx context.Context, itemID int64, nextActionAt time.Time) (bool, error)
+}
+
+// pgxPublisherPipelineRepository implements PublisherPipelineRepository using pgx
+type pgxPublisherPipelineRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxPublisherPipelineRepository creates a new publisher pipeline repository
+func NewPgxPublisherPipelineRepository(db *pgxpool.Pool) PublisherPipelineRepository {
+	return &pgxPublisherPipelineRepository{db: db}
+}
+
+const pipelineStageColumns = "stage_id, organization_id, key, name, position, outcome, color, created_at, updated_at"
+
+const pipelineStageChangeColumns = `change_id, item_id, from_stage_id, from_stage_name, to_stage_id, to_stage_name,
+	changed_by_user_id, reason, note, changed_at`
+
+func scanPipelineStage(row pgx.Row) (*domain.PipelineStage, error) {
+	stage := &domain.PipelineStage{}
+	err := row.Scan(&stage.StageID, &stage.OrganizationID, &stage.Key, &stage.Name, &stage.Position,
+		&stage.Outcome, &stage.Color, &stage.CreatedAt, &stage.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return stage, nil
+}
+
+// ListStages returns the stages of an organization in board order
+func (r *pgxPublisherPipelineRepository) ListStages(ctx context.Context, organizationID int64) ([]*domain.PipelineStage, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM publisher_pipeline_stages
+		WHERE organization_id = $1
+		ORDER BY position, stage_id`, pipelineStageColumns)
+
+	rows, err := r.db.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipeline stages: %w", err)
+	}
+	defer rows.Close()
+
+	stages := make([]*domain.PipelineStage, 0)
+	for rows.Next() {
+		stage, err := scanPipelineStage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline stage: %w", err)
+		}
+		stages = append(stages, stage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pipeline stages: %w", err)
+	}
+
+	return stages, nil
+}
+
+// CreateDefaultStages adds the default stages whose keys the organization does not use yet
+func (r *pgxPublisherPipelineRepository) CreateDefaultStages(ctx context.Context, organizationID int64) error {
+	batch := &pgx.Batch{}
+	for _, stage := range domain.DefaultPipelineStages(organizationID) {
+		batch.Queue(`
+			INSERT INTO publisher_pipeline_stages (organization_id, key, name, position, outcome)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (organization_id, key) DO NOTHING`,
+			stage.OrganizationID, stage.Key, stage.Name, stage.Position, stage.Outcome)
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to create default pipeline stages: %w", err)
+	}
+	return nil
+}
+
+// CreateStage creates a pipeline stage
+func (r *pgxPublisherPipelineRepository) CreateStage(ctx context.Context, stage *domain.PipelineStage) error {
+	query := `
+		INSERT INTO publisher_pipeline_stages (organization_id, key, name, position, outcome, color)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING stage_id, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query, stage.OrganizationID, stage.Key, stage.Name, stage.Position,
+		stage.Outcome, stage.Color).Scan(&stage.StageID, &stage.CreatedAt, &stage.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create pipeline stage: %w", err)
+	}
+	return nil
+}
+
+// UpdateStage updates the name, outcome and color of a pipeline stage
+func (r *pgxPublisherPipelineRepository) UpdateStage(ctx context.Context, stage *domain.PipelineStage) error {
+	query := `
+		UPDATE publisher_pipeline_stages
+		SET name = $2, outcome = $3, color = $4
+		WHERE stage_id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(ctx, query, stage.StageID, stage.Name, stage.Outcome, stage.Color).Scan(&stage.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update pipeline stage: %w", err)
+	}
+	return nil
+}
+
+// DeleteStage deletes a pipeline stage; it fails while list items are in the stage
+func (r *pgxPublisherPipelineRepository) DeleteStage(ctx context.Context, stageID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM publisher_pipeline_stages WHERE stage_id = $1`, stageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete pipeline stage: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ReorderStages sets each stage's position to its index in stageIDs
+func (r *pgxPublisherPipelineRepository) ReorderStages(ctx context.Context, organizationID int64, stageIDs []int64) error {
+	query := `
+		UPDATE publisher_pipeline_stages
+		SET position = array_position($2::BIGINT[], stage_id) - 1
+		WHERE organization_id = $1 AND stage_id = ANY($2)`
+
+	if _, err := r.db.Exec(ctx, query, organizationID, stageIDs); err != nil {
+		return fmt.Errorf("failed to reorder pipeline stages: %w", err)
+	}
+	return nil
+}
+
+// CountItemsInStage counts the list items currently in a stage
+func (r *pgxPublisherPipelineRepository) CountItemsInStage(ctx context.Context, stageID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM favorite_publisher_list_items WHERE stage_id = $1`, stageID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count items in pipeline stage: %w", err)
+	}
+	return count, nil
+}
+
+// MoveItem sets the stage and legacy status of an item and records the change in its history
+func (r *pgxPublisherPipelineRepository) MoveItem(ctx context.Context, item *domain.FavoritePublisherListItem, status string, change *domain.PipelineStageChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE favorite_publisher_list_items
+		SET stage_id = $2, status = $3, stage_changed_at = CURRENT_TIMESTAMP
+		WHERE item_id = $1
+		RETURNING stage_changed_at`,
+		item.ItemID, change.ToStageID, status).Scan(&item.StageChangedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to move list item: %w", err)
+	}
+
+	if err := insertStageChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	item.StageID = change.ToStageID
+	item.Status = status
+	return nil
+}
+
+// MoveItems moves several items of a list into a stage in one statement
+func (r *pgxPublisherPipelineRepository) MoveItems(ctx context.Context, listID int64, publisherDomains []string, stage *domain.PipelineStage, status string, changedBy *string, reason string, note *string) (int, error) {
+	query := `
+		WITH moved AS (
+			UPDATE favorite_publisher_list_items i
+			SET stage_id = $3, status = $4, stage_changed_at = CURRENT_TIMESTAMP
+			FROM favorite_publisher_list_items old
+			LEFT JOIN publisher_pipeline_stages s ON s.stage_id = old.stage_id
+			WHERE i.item_id = old.item_id
+			  AND i.list_id = $1 AND i.publisher_domain = ANY($2)
+			  AND i.stage_id IS DISTINCT FROM $3
+			RETURNING i.item_id, old.stage_id AS from_stage_id, s.name AS from_stage_name
+		),
+		history AS (
+			INSERT INTO publisher_pipeline_stage_changes (item_id, from_stage_id, from_stage_name, to_stage_id,
+				to_stage_name, changed_by_user_id, reason, note)
+			SELECT item_id, from_stage_id, from_stage_name, $3, $5, $6, $7, $8
+			FROM moved
+		)
+		SELECT COUNT(*) FROM moved`
+
+	var moved int
+	err := r.db.QueryRow(ctx, query, listID, publisherDomains, stage.StageID, status, stage.Name, changedBy, reason, note).Scan(&moved)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move list items: %w", err)
+	}
+	return moved, nil
+}
+
+// CreateStageChange records a stage change without moving the item
+func (r *pgxPublisherPipelineRepository) CreateStageChange(ctx context.Context, change *domain.PipelineStageChange) error {
+	return insertStageChange(ctx, r.db, change)
+}
+
+// queryRower is implemented by both the pool and transactions
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func insertStageChange(ctx context.Context, db queryRower, change *domain.PipelineStageChange) error {
+	query := `
+		INSERT INTO publisher_pipeline_stage_changes (item_id, from_stage_id, from_stage_name, to_stage_id,
+			to_stage_name, changed_by_user_id, reason, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING change_id, changed_at`
+
+	err := db.QueryRow(ctx, query, change.ItemID, change.FromStageID, change.FromStageName, change.ToStageID,
+		change.ToStageName, change.ChangedByUserID, change.Reason, change.Note).Scan(&change.ChangeID, &change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record pipeline stage change: %w", err)
+	}
+	return nil
+}
+
+// ListStageChanges returns the stage history of an item, most recent first
+func (r *pgxPublisherPipelineRepository) ListStageChanges(ctx context.Context, itemID int64, limit int) ([]*domain.PipelineStageChange, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM publisher_pipeline_stage_changes
+		WHERE item_id = $1
+		ORDER BY changed_at DESC, change_id DESC
+		LIMIT $2`, pipelineStageChangeColumns)
+
+	rows, err := r.db.Query(ctx, query, itemID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipeline stage changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]*domain.PipelineStageChange, 0)
+	for rows.Next() {
+		change := &domain.PipelineStageChange{}
+		err := rows.Scan(&change.ChangeID, &change.ItemID, &change.FromStageID, &change.FromStageName,
+			&change.ToStageID, &change.ToStageName, &change.ChangedByUserID, &change.Reason, &change.Note,
+			&change.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline stage change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pipeline stage changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// UpdateFollowUp sets the owner and next action of an item; a changed next action is reminded again
+func (r *pgxPublisherPipelineRepository) UpdateFollowUp(ctx context.Context, item *domain.FavoritePublisherListItem) error {
+	query := `
+		UPDATE favorite_publisher_list_items
+		SET owner_user_id = $2,
+			next_action_at = $3,
+			next_action_note = $4,
+			reminder_sent_at = CASE WHEN next_action_at IS NOT DISTINCT FROM $3 THEN reminder_sent_at ELSE NULL END
+		WHERE item_id = $1
+		RETURNING reminder_sent_at`
+
+	err := r.db.QueryRow(ctx, query, item.ItemID, item.OwnerUserID, item.NextActionAt, item.NextActionNote).
+		Scan(&item.ReminderSentAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update list item follow-up: %w", err)
+	}
+	return nil
+}
+
+// GetListConversationIDs returns the active conversation per publisher domain of a list
+func (r *pgxPublisherPipelineRepository) GetListConversationIDs(ctx context.Context, organizationID, listID int64) (map[string]int64, error) {
+	query := `
+		SELECT DISTINCT ON (pc.publisher_domain) pc.publisher_domain, pc.conversation_id
+		FROM publisher_conversations pc
+		JOIN favorite_publisher_list_items fpli ON fpli.publisher_domain = pc.publisher_domain AND fpli.list_id = $2
+		WHERE pc.organization_id = $1 AND pc.status = 'active'
+		ORDER BY pc.publisher_domain, (pc.list_id IS NOT DISTINCT FROM $2) DESC, pc.last_message_at DESC`
+
+	rows, err := r.db.Query(ctx, query, organizationID, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := make(map[string]int64)
+	for rows.Next() {
+		var publisherDomain string
+		var conversationID int64
+		if err := rows.Scan(&publisherDomain, &conversationID); err != nil {
+			return nil, fmt.Errorf("failed to scan list conversation: %w", err)
+		}
+		conversations[publisherDomain] = conversationID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate list conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// ListDueReminders returns owned items whose next action has passed and was not reminded yet
+func (r *pgxPublisherPipelineRepository) ListDueReminders(ctx context.Context, now time.Time, limit int) ([]*domain.PipelineReminder, error) {
+	query := `
+		SELECT fpli.item_id, fpli.list_id, fpl.name, fpli.publisher_domain, pps.name, p.email,
+			fpli.next_action_at, fpli.next_action_note
+		FROM favorite_publisher_list_items fpli
+		JOIN favorite_publisher_lists fpl ON fpl.list_id = fpli.list_id
+		JOIN profiles p ON p.id = fpli.owner_user_id
+		LEFT JOIN publisher_pipeline_stages pps ON pps.stage_id = fpli.stage_id
+		WHERE fpli.next_action_at <= $1
+		  AND fpli.reminder_sent_at IS NULL
+		  AND fpli.owner_user_id IS NOT NULL
+		ORDER BY fpli.next_action_at
+		LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due pipeline reminders: %w", err)
+	}
+	defer rows.Close()
+
+	reminders := make([]*domain.PipelineReminder, 0)
+	for rows.Next() {
+		reminder := &domain.PipelineReminder{}
+		err := rows.Scan(&reminder.ItemID, &reminder.ListID, &reminder.ListName, &reminder.PublisherDomain,
+			&reminder.StageName, &reminder.OwnerEmail, &reminder.NextActionAt, &reminder.NextActionNote)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pipeline reminder: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pipeline reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// ClaimReminder marks the reminder of an item as sent if it is still due for nextActionAt
+func (r *pgxPublisherPipelineRepository) ClaimReminder(ctx context.Context, itemID int64, nextActionAt time.Time) (bool, error) {
+	query := `
+		UPDATE favorite_publisher_list_items
+		SET reminder_sent_at = CURRENT_TIMESTAMP
+		WHERE item_id = $1 AND next_action_at = $2 AND reminder_sent_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, itemID, nextActionAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim pipeline reminder: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
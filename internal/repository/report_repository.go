@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReportRepository defines the interface for performance report queries over the provider stats warehouse
+type ReportRepository interface {
+	// QueryReport returns one page of grouped rows, the number of grouped rows and the overall totals
+	QueryReport(ctx context.Context, query *domain.ReportQuery) ([]domain.ReportAggregate, int, *domain.ReportTotals, error)
+}
+
+// pgxReportRepository implements ReportRepository using pgx
+type pgxReportRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxReportRepository creates a new report repository
+func NewPgxReportRepository(db *pgxpool.Pool) ReportRepository {
+	return &pgxReportRepository{db: db}
+}
+
+// reportLocalTime converts the UTC fact hour into a local timestamp in the report timezone ($1)
+const reportLocalTime = `(((s.stat_date + make_interval(hours => s.stat_hour::int)) AT TIME ZONE 'UTC') AT TIME ZONE $1)`
+
+// reportDimensionColumns maps each dimension to the expressions it selects and groups by
+var reportDimensionColumns = map[domain.ReportDimension][]string{
+	domain.ReportDimensionDate:      {`to_char(` + reportLocalTime + `, 'YYYY-MM-DD')`},
+	domain.ReportDimensionHour:      {`EXTRACT(HOUR FROM ` + reportLocalTime + `)::int`},
+	domain.ReportDimensionCampaign:  {`s.campaign_id`, `c.name`},
+	domain.ReportDimensionAffiliate: {`s.affiliate_id`, `a.name`},
+	domain.ReportDimensionSub1:      {`s.sub1`},
+	domain.ReportDimensionSub2:      {`s.sub2`},
+	domain.ReportDimensionSub3:      {`s.sub3`},
+	domain.ReportDimensionSub4:      {`s.sub4`},
+	domain.ReportDimensionSub5:      {`s.sub5`},
+	domain.ReportDimensionCountry:   {`s.country_code`},
+	domain.ReportDimensionDevice:    {`s.device_type`},
+}
+
+// reportMetricColumns maps sortable metrics to their aggregate expressions
+var reportMetricColumns = map[string]string{
+	domain.ReportSortImpressions:  "SUM(s.impressions)",
+	domain.ReportSortClicks:       "SUM(s.clicks)",
+	domain.ReportSortUniqueClicks: "SUM(s.unique_clicks)",
+	domain.ReportSortConversions:  "SUM(s.conversions)",
+	domain.ReportSortRevenue:      "SUM(s.revenue)",
+	domain.ReportSortPayout:       "SUM(s.payout)",
+}
+
+// reportMetricSelect selects the summed metrics; counts are cast back from numeric to bigint
+const reportMetricSelect = `COALESCE(SUM(s.impressions), 0)::bigint, COALESCE(SUM(s.clicks), 0)::bigint, COALESCE(SUM(s.unique_clicks), 0)::bigint,
+	COALESCE(SUM(s.conversions), 0)::bigint, COALESCE(SUM(s.revenue), 0), COALESCE(SUM(s.payout), 0)`
+
+// QueryReport aggregates the warehouse facts for a report query
+func (r *pgxReportRepository) QueryReport(ctx context.Context, query *domain.ReportQuery) ([]domain.ReportAggregate, int, *domain.ReportTotals, error) {
+	whereClause, args, err := buildReportWhere(query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	fromClause := `
+		FROM provider_daily_stats s
+		LEFT JOIN campaigns c ON c.campaign_id = s.campaign_id
+		LEFT JOIN affiliates a ON a.affiliate_id = s.affiliate_id
+		WHERE ` + whereClause
+
+	// Overall totals
+	totals := &domain.ReportTotals{}
+	err = r.db.QueryRow(ctx, "SELECT "+reportMetricSelect+fromClause, args...).Scan(
+		&totals.Impressions, &totals.Clicks, &totals.UniqueClicks,
+		&totals.Conversions, &totals.Revenue, &totals.Payout,
+	)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to query report totals: %w", err)
+	}
+
+	var selectColumns, groupColumns []string
+	for _, dimension := range query.GroupBy {
+		columns := reportDimensionColumns[dimension]
+		selectColumns = append(selectColumns, columns...)
+		groupColumns = append(groupColumns, columns...)
+	}
+
+	groupClause := ""
+	if len(groupColumns) > 0 {
+		groupClause = " GROUP BY " + strings.Join(groupColumns, ", ")
+	}
+
+	args = append(args, query.Limit, query.Offset)
+	sql := "SELECT " + strings.Join(append(selectColumns, reportMetricSelect, "COUNT(*) OVER()"), ", ") +
+		fromClause + groupClause +
+		" ORDER BY " + buildReportOrder(query) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to query report: %w", err)
+	}
+	defer rows.Close()
+
+	aggregates := make([]domain.ReportAggregate, 0)
+	total := 0
+	for rows.Next() {
+		var agg domain.ReportAggregate
+		dest := reportDimensionDest(&agg.ReportDimensionValues, query.GroupBy)
+		dest = append(dest,
+			&agg.Impressions, &agg.Clicks, &agg.UniqueClicks,
+			&agg.Conversions, &agg.Revenue, &agg.Payout,
+			&total,
+		)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to scan report row: %w", err)
+		}
+		aggregates = append(aggregates, agg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return aggregates, total, totals, nil
+}
+
+// buildReportWhere builds the date range, visibility scope and filter conditions.
+// $1 is always the report timezone.
+func buildReportWhere(query *domain.ReportQuery) (string, []interface{}, error) {
+	args := []interface{}{query.Timezone}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// The UTC day range is padded by a day on each side so the stat_date index can be used,
+	// then narrowed to the exact local day range
+	fromArg := addArg(query.From)
+	toArg := addArg(query.To.AddDate(0, 0, 1))
+	conditions := []string{
+		fmt.Sprintf("s.stat_date BETWEEN (%s::date - 1) AND %s::date", fromArg, toArg),
+		fmt.Sprintf("%s >= %s::timestamp AND %s < %s::timestamp", reportLocalTime, fromArg, reportLocalTime, toArg),
+	}
+
+	scope := query.Scope
+	switch scope.OrganizationType {
+	case domain.OrganizationTypePlatformOwner:
+		// Platform owners see everything, including unmapped facts
+	case domain.OrganizationTypeAdvertiser, domain.OrganizationTypeAgency:
+		orgArg := addArg(scope.OrganizationID)
+		if scope.OrganizationType == domain.OrganizationTypeAdvertiser {
+			conditions = append(conditions, "c.organization_id = "+orgArg)
+		} else {
+			conditions = append(conditions, `c.organization_id IN (
+				SELECT d.advertiser_org_id FROM agency_delegations d
+				WHERE d.agency_org_id = `+orgArg+` AND d.status = 'active')`)
+		}
+		// Only affiliates the campaign owner can see through an active association
+		conditions = append(conditions, `(a.organization_id = c.organization_id OR EXISTS (
+			SELECT 1 FROM organization_associations oa
+			WHERE oa.advertiser_org_id = c.organization_id
+			  AND oa.affiliate_org_id = a.organization_id
+			  AND oa.status = 'active'
+			  AND (oa.all_affiliates_visible OR COALESCE(oa.visible_affiliate_ids, '[]'::jsonb) @> to_jsonb(a.affiliate_id))))`)
+	case domain.OrganizationTypeAffiliate:
+		orgArg := addArg(scope.OrganizationID)
+		// Own affiliates only, on campaigns visible through an active association
+		conditions = append(conditions, "a.organization_id = "+orgArg)
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM organization_associations oa
+			WHERE oa.affiliate_org_id = `+orgArg+`
+			  AND oa.advertiser_org_id = c.organization_id
+			  AND oa.status = 'active'
+			  AND (oa.all_campaigns_visible OR COALESCE(oa.visible_campaign_ids, '[]'::jsonb) @> to_jsonb(c.campaign_id)))`)
+	default:
+		return "", nil, domain.ErrForbidden
+	}
+
+	filters := query.Filters
+	if len(filters.CampaignIDs) > 0 {
+		conditions = append(conditions, "s.campaign_id = ANY("+addArg(filters.CampaignIDs)+")")
+	}
+	if len(filters.AffiliateIDs) > 0 {
+		conditions = append(conditions, "s.affiliate_id = ANY("+addArg(filters.AffiliateIDs)+")")
+	}
+	if len(filters.Countries) > 0 {
+		conditions = append(conditions, "s.country_code = ANY("+addArg(filters.Countries)+")")
+	}
+	if len(filters.DeviceTypes) > 0 {
+		conditions = append(conditions, "s.device_type = ANY("+addArg(filters.DeviceTypes)+")")
+	}
+	for i, values := range [][]string{filters.Sub1, filters.Sub2, filters.Sub3, filters.Sub4, filters.Sub5} {
+		if len(values) > 0 {
+			conditions = append(conditions, fmt.Sprintf("s.sub%d = ANY(%s)", i+1, addArg(values)))
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// buildReportOrder builds the ORDER BY clause; without an explicit sort, time dimensions
+// are ordered chronologically and everything else by clicks
+func buildReportOrder(query *domain.ReportQuery) string {
+	direction := "DESC"
+	if query.SortOrder == "asc" {
+		direction = "ASC"
+	}
+
+	if query.SortBy != "" {
+		if expr, ok := reportMetricColumns[query.SortBy]; ok {
+			return expr + " " + direction
+		}
+		if query.SortOrder == "" {
+			direction = "ASC"
+		}
+		return reportDimensionColumns[domain.ReportDimension(query.SortBy)][0] + " " + direction
+	}
+
+	var order []string
+	for _, dimension := range query.GroupBy {
+		if dimension == domain.ReportDimensionDate || dimension == domain.ReportDimensionHour {
+			order = append(order, reportDimensionColumns[dimension][0]+" ASC")
+		}
+	}
+	return strings.Join(append(order, "SUM(s.clicks) DESC"), ", ")
+}
+
+// reportDimensionDest returns scan destinations matching the selected dimension columns
+func reportDimensionDest(values *domain.ReportDimensionValues, groupBy []domain.ReportDimension) []interface{} {
+	var dest []interface{}
+	for _, dimension := range groupBy {
+		switch dimension {
+		case domain.ReportDimensionDate:
+			dest = append(dest, &values.Date)
+		case domain.ReportDimensionHour:
+			dest = append(dest, &values.Hour)
+		case domain.ReportDimensionCampaign:
+			dest = append(dest, &values.CampaignID, &values.CampaignName)
+		case domain.ReportDimensionAffiliate:
+			dest = append(dest, &values.AffiliateID, &values.AffiliateName)
+		case domain.ReportDimensionSub1:
+			dest = append(dest, &values.Sub1)
+		case domain.ReportDimensionSub2:
+			dest = append(dest, &values.Sub2)
+		case domain.ReportDimensionSub3:
+			dest = append(dest, &values.Sub3)
+		case domain.ReportDimensionSub4:
+			dest = append(dest, &values.Sub4)
+		case domain.ReportDimensionSub5:
+			dest = append(dest, &values.Sub5)
+		case domain.ReportDimensionCountry:
+			dest = append(dest, &values.Country)
+		case domain.ReportDimensionDevice:
+			dest = append(dest, &values.Device)
+		}
+	}
+	return dest
+}
@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScheduledReportRepository defines the interface for scheduled report operations
+type ScheduledReportRepository interface {
+	// Definition operations
+	CreateScheduledReport(ctx context.Context, report *domain.ScheduledReport) error
+	GetScheduledReportByID(ctx context.Context, id int64) (*domain.ScheduledReport, error)
+	ListScheduledReports(ctx context.Context, organizationID int64, limit, offset int) ([]*domain.ScheduledReport, int, error)
+	UpdateScheduledReport(ctx context.Context, report *domain.ScheduledReport) error
+	DeleteScheduledReport(ctx context.Context, id int64) error
+
+	// Scheduling operations
+	ListDueScheduledReports(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledReport, error)
+	// ClaimScheduledRun moves next_run_at forward only if it still has the expected value,
+	// so that concurrent schedulers run each occurrence once
+	ClaimScheduledRun(ctx context.Context, id int64, expectedNextRunAt time.Time, nextRunAt *time.Time) (bool, error)
+
+	// Run operations
+	CreateRun(ctx context.Context, run *domain.ScheduledReportRun) error
+	UpdateRun(ctx context.Context, run *domain.ScheduledReportRun) error
+	GetRunByID(ctx context.Context, runID int64) (*domain.ScheduledReportRun, error)
+	ListRuns(ctx context.Context, scheduledReportID int64, limit, offset int) ([]*domain.ScheduledReportRun, int, error)
+}
+
+// pgxScheduledReportRepository implements ScheduledReportRepository using pgx
+type pgxScheduledReportRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxScheduledReportRepository creates a new scheduled report repository
+func NewPgxScheduledReportRepository(db *pgxpool.Pool) ScheduledReportRepository {
+	return &pgxScheduledReportRepository{db: db}
+}
+
+const scheduledReportColumns = `scheduled_report_id, organization_id, name, query, date_range, timezone, format, schedule,
+	delivery_type, delivery_config, is_active, next_run_at, last_run_at, created_by_user_id, created_at, updated_at`
+
+// CreateScheduledReport creates a new scheduled report
+func (r *pgxScheduledReportRepository) CreateScheduledReport(ctx context.Context, report *domain.ScheduledReport) error {
+	queryJSON, deliveryJSON, err := marshalScheduledReportJSON(report)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO scheduled_reports (organization_id, name, query, date_range, timezone, format, schedule,
+			delivery_type, delivery_config, is_active, next_run_at, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING scheduled_report_id, created_at, updated_at`
+
+	err = r.db.QueryRow(ctx, query,
+		report.OrganizationID,
+		report.Name,
+		queryJSON,
+		report.DateRange,
+		report.Timezone,
+		report.Format,
+		report.Schedule,
+		report.DeliveryType,
+		deliveryJSON,
+		report.IsActive,
+		report.NextRunAt,
+		report.CreatedByUserID,
+	).Scan(&report.ScheduledReportID, &report.CreatedAt, &report.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled report: %w", err)
+	}
+
+	return nil
+}
+
+// GetScheduledReportByID retrieves a scheduled report by ID
+func (r *pgxScheduledReportRepository) GetScheduledReportByID(ctx context.Context, id int64) (*domain.ScheduledReport, error) {
+	query := `SELECT ` + scheduledReportColumns + ` FROM scheduled_reports WHERE scheduled_report_id = $1`
+
+	report, err := scanScheduledReport(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scheduled report: %w", err)
+	}
+
+	return report, nil
+}
+
+// ListScheduledReports lists the scheduled reports of an organization
+func (r *pgxScheduledReportRepository) ListScheduledReports(ctx context.Context, organizationID int64, limit, offset int) ([]*domain.ScheduledReport, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM scheduled_reports WHERE organization_id = $1`, organizationID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count scheduled reports: %w", err)
+	}
+
+	query := `SELECT ` + scheduledReportColumns + `
+		FROM scheduled_reports
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, organizationID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list scheduled reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports, err := collectScheduledReports(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+// UpdateScheduledReport updates a scheduled report definition and its next run time
+func (r *pgxScheduledReportRepository) UpdateScheduledReport(ctx context.Context, report *domain.ScheduledReport) error {
+	queryJSON, deliveryJSON, err := marshalScheduledReportJSON(report)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE scheduled_reports
+		SET name = $1, query = $2, date_range = $3, timezone = $4, format = $5, schedule = $6,
+		    delivery_type = $7, delivery_config = $8, is_active = $9, next_run_at = $10
+		WHERE scheduled_report_id = $11
+		RETURNING updated_at`
+
+	err = r.db.QueryRow(ctx, query,
+		report.Name,
+		queryJSON,
+		report.DateRange,
+		report.Timezone,
+		report.Format,
+		report.Schedule,
+		report.DeliveryType,
+		deliveryJSON,
+		report.IsActive,
+		report.NextRunAt,
+		report.ScheduledReportID,
+	).Scan(&report.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update scheduled report: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteScheduledReport deletes a scheduled report and its run history
+func (r *pgxScheduledReportRepository) DeleteScheduledReport(ctx context.Context, id int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM scheduled_reports WHERE scheduled_report_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled report: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// ListDueScheduledReports lists active scheduled reports whose next run is due
+func (r *pgxScheduledReportRepository) ListDueScheduledReports(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledReport, error) {
+	query := `SELECT ` + scheduledReportColumns + `
+		FROM scheduled_reports
+		WHERE is_active = TRUE AND next_run_at IS NOT NULL AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled reports: %w", err)
+	}
+	defer rows.Close()
+
+	return collectScheduledReports(rows)
+}
+
+// ClaimScheduledRun advances next_run_at and records the run time if no other scheduler got there first
+func (r *pgxScheduledReportRepository) ClaimScheduledRun(ctx context.Context, id int64, expectedNextRunAt time.Time, nextRunAt *time.Time) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE scheduled_reports
+		SET next_run_at = $1, last_run_at = CURRENT_TIMESTAMP
+		WHERE scheduled_report_id = $2 AND next_run_at = $3`,
+		nextRunAt, id, expectedNextRunAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim scheduled report run: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// CreateRun records the start of a scheduled report run
+func (r *pgxScheduledReportRepository) CreateRun(ctx context.Context, run *domain.ScheduledReportRun) error {
+	query := `
+		INSERT INTO scheduled_report_runs (scheduled_report_id, status, triggered_by, range_from, range_to, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING run_id, created_at`
+
+	err := r.db.QueryRow(ctx, query,
+		run.ScheduledReportID,
+		run.Status,
+		run.TriggeredBy,
+		run.RangeFrom,
+		run.RangeTo,
+		run.StartedAt,
+	).Scan(&run.RunID, &run.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled report run: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRun records the outcome of a scheduled report run
+func (r *pgxScheduledReportRepository) UpdateRun(ctx context.Context, run *domain.ScheduledReportRun) error {
+	query := `
+		UPDATE scheduled_report_runs
+		SET status = $1, row_count = $2, file_name = $3, content_type = $4, file_size = $5,
+		    storage_key = $6, error_message = $7, completed_at = $8
+		WHERE run_id = $9`
+
+	result, err := r.db.Exec(ctx, query,
+		run.Status,
+		run.RowCount,
+		run.FileName,
+		run.ContentType,
+		run.FileSize,
+		run.StorageKey,
+		run.ErrorMessage,
+		run.CompletedAt,
+		run.RunID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled report run: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+const scheduledReportRunColumns = `run_id, scheduled_report_id, status, triggered_by, range_from, range_to, row_count,
+	file_name, content_type, file_size, storage_key, error_message, started_at, completed_at, created_at`
+
+// GetRunByID retrieves a scheduled report run by ID
+func (r *pgxScheduledReportRepository) GetRunByID(ctx context.Context, runID int64) (*domain.ScheduledReportRun, error) {
+	query := `SELECT ` + scheduledReportRunColumns + ` FROM scheduled_report_runs WHERE run_id = $1`
+
+	var run domain.ScheduledReportRun
+	err := r.db.QueryRow(ctx, query, runID).Scan(scheduledReportRunDest(&run)...)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scheduled report run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// ListRuns lists the runs of a scheduled report, most recent first
+func (r *pgxScheduledReportRepository) ListRuns(ctx context.Context, scheduledReportID int64, limit, offset int) ([]*domain.ScheduledReportRun, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM scheduled_report_runs WHERE scheduled_report_id = $1`, scheduledReportID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count scheduled report runs: %w", err)
+	}
+
+	query := `SELECT ` + scheduledReportRunColumns + `
+		FROM scheduled_report_runs
+		WHERE scheduled_report_id = $1
+		ORDER BY started_at DESC, run_id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, scheduledReportID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list scheduled report runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*domain.ScheduledReportRun, 0)
+	for rows.Next() {
+		var run domain.ScheduledReportRun
+		if err := rows.Scan(scheduledReportRunDest(&run)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan scheduled report run: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return runs, total, nil
+}
+
+// marshalScheduledReportJSON encodes the JSONB columns of a scheduled report
+func marshalScheduledReportJSON(report *domain.ScheduledReport) ([]byte, []byte, error) {
+	queryJSON, err := json.Marshal(report.Query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal report query: %w", err)
+	}
+	deliveryJSON, err := json.Marshal(report.Delivery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal delivery config: %w", err)
+	}
+	return queryJSON, deliveryJSON, nil
+}
+
+// scanScheduledReport scans a scheduled report row, decoding the JSONB columns
+func scanScheduledReport(row pgx.Row) (*domain.ScheduledReport, error) {
+	var report domain.ScheduledReport
+	var queryJSON, deliveryJSON []byte
+
+	err := row.Scan(
+		&report.ScheduledReportID,
+		&report.OrganizationID,
+		&report.Name,
+		&queryJSON,
+		&report.DateRange,
+		&report.Timezone,
+		&report.Format,
+		&report.Schedule,
+		&report.DeliveryType,
+		&deliveryJSON,
+		&report.IsActive,
+		&report.NextRunAt,
+		&report.LastRunAt,
+		&report.CreatedByUserID,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(queryJSON, &report.Query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report query: %w", err)
+	}
+	if err := json.Unmarshal(deliveryJSON, &report.Delivery); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delivery config: %w", err)
+	}
+
+	return &report, nil
+}
+
+// collectScheduledReports scans all rows into scheduled reports
+func collectScheduledReports(rows pgx.Rows) ([]*domain.ScheduledReport, error) {
+	reports := make([]*domain.ScheduledReport, 0)
+	for rows.Next() {
+		report, err := scanScheduledReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return reports, nil
+}
+
+// scheduledReportRunDest returns the scan destinations for scheduledReportRunColumns
+func scheduledReportRunDest(run *domain.ScheduledReportRun) []interface{} {
+	return []interface{}{
+		&run.RunID, &run.ScheduledReportID, &run.Status, &run.TriggeredBy, &run.RangeFrom, &run.RangeTo, &run.RowCount,
+		&run.FileName, &run.ContentType, &run.FileSize, &run.StorageKey, &run.ErrorMessage,
+		&run.StartedAt, &run.CompletedAt, &run.CreatedAt,
+	}
+}
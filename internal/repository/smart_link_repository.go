@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SmartLinkRepository defines the interface for smart link data access
+type SmartLinkRepository interface {
+	// Smart link operations; smart links are returned with their destinations
+	CreateSmartLink(ctx context.Context, smartLink *domain.SmartLink) error
+	GetSmartLinkByID(ctx context.Context, smartLinkID int64) (*domain.SmartLink, error)
+	ListSmartLinks(ctx context.Context, organizationID int64) ([]*domain.SmartLink, error)
+	// UpdateSmartLink saves the name, status and fallback URL, and replaces the destinations when
+	// replaceDestinations is set
+	UpdateSmartLink(ctx context.Context, smartLink *domain.SmartLink, replaceDestinations bool) error
+	DeleteSmartLink(ctx context.Context, smartLinkID int64) error
+
+	// GetDestinationStates returns the tracking link, campaign and clicks on the given day of
+	// every destination, by tracking link ID
+	GetDestinationStates(ctx context.Context, smartLinkID int64, date time.Time) (map[int64]domain.SmartLinkDestinationState, error)
+	// RecordClick counts a click sent to a tracking link, or to the fallback URL for tracking link 0
+	RecordClick(ctx context.Context, smartLinkID int64, date time.Time, trackingLinkID int64, campaignID *int64) error
+	// GetDailyStats returns the clicks per day and destination between two dates, inclusive
+	GetDailyStats(ctx context.Context, smartLinkID int64, from, to time.Time) ([]*domain.SmartLinkDailyStats, error)
+}
+
+// pgxSmartLinkRepository implements SmartLinkRepository using pgx
+type pgxSmartLinkRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxSmartLinkRepository creates a new smart link repository
+func NewPgxSmartLinkRepository(db *pgxpool.Pool) SmartLinkRepository {
+	return &pgxSmartLinkRepository{db: db}
+}
+
+const smartLinkColumns = `smart_link_id, organization_id, affiliate_id, name, status, fallback_url, created_at, updated_at`
+
+func scanSmartLink(row pgx.Row) (*domain.SmartLink, error) {
+	smartLink := &domain.SmartLink{}
+	err := row.Scan(
+		&smartLink.SmartLinkID, &smartLink.OrganizationID, &smartLink.AffiliateID, &smartLink.Name,
+		&smartLink.Status, &smartLink.FallbackURL, &smartLink.CreatedAt, &smartLink.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan smart link: %w", err)
+	}
+	return smartLink, nil
+}
+
+// CreateSmartLink creates a smart link with its destinations
+func (r *pgxSmartLinkRepository) CreateSmartLink(ctx context.Context, smartLink *domain.SmartLink) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO smart_links (organization_id, affiliate_id, name, status, fallback_url)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING smart_link_id, created_at, updated_at`,
+		smartLink.OrganizationID, smartLink.AffiliateID, smartLink.Name, smartLink.Status, smartLink.FallbackURL,
+	).Scan(&smartLink.SmartLinkID, &smartLink.CreatedAt, &smartLink.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create smart link: %w", err)
+	}
+
+	if err := insertSmartLinkDestinations(ctx, tx, smartLink); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertSmartLinkDestinations inserts the destinations of a smart link, positioned in order
+func insertSmartLinkDestinations(ctx context.Context, db queryRower, smartLink *domain.SmartLink) error {
+	for i, destination := range smartLink.Destinations {
+		destination.SmartLinkID = smartLink.SmartLinkID
+		destination.Position = i
+		rules, err := json.Marshal(destination.Rules)
+		if err != nil {
+			return fmt.Errorf("failed to marshal smart link rules: %w", err)
+		}
+		err = db.QueryRow(ctx, `
+			INSERT INTO smart_link_destinations (smart_link_id, tracking_link_id, campaign_id, weight, rules, daily_click_cap, is_active, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING destination_id`,
+			destination.SmartLinkID, destination.TrackingLinkID, destination.CampaignID, destination.Weight,
+			string(rules), destination.DailyClickCap, destination.IsActive, destination.Position,
+		).Scan(&destination.DestinationID)
+		if err != nil {
+			return fmt.Errorf("failed to create smart link destination: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetSmartLinkByID retrieves a smart link with its destinations
+func (r *pgxSmartLinkRepository) GetSmartLinkByID(ctx context.Context, smartLinkID int64) (*domain.SmartLink, error) {
+	smartLink, err := scanSmartLink(r.db.QueryRow(ctx, `SELECT `+smartLinkColumns+` FROM smart_links WHERE smart_link_id = $1`, smartLinkID))
+	if err != nil {
+		return nil, err
+	}
+
+	destinations, err := r.getDestinations(ctx, []int64{smartLinkID})
+	if err != nil {
+		return nil, err
+	}
+	smartLink.Destinations = destinations[smartLinkID]
+	if smartLink.Destinations == nil {
+		smartLink.Destinations = []*domain.SmartLinkDestination{}
+	}
+	return smartLink, nil
+}
+
+// ListSmartLinks lists the smart links of an organization with their destinations
+func (r *pgxSmartLinkRepository) ListSmartLinks(ctx context.Context, organizationID int64) ([]*domain.SmartLink, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+smartLinkColumns+` FROM smart_links WHERE organization_id = $1 ORDER BY name, smart_link_id`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list smart links: %w", err)
+	}
+	defer rows.Close()
+
+	smartLinks := make([]*domain.SmartLink, 0)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		smartLink, err := scanSmartLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		smartLinks = append(smartLinks, smartLink)
+		ids = append(ids, smartLink.SmartLinkID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating smart links: %w", err)
+	}
+
+	destinations, err := r.getDestinations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, smartLink := range smartLinks {
+		smartLink.Destinations = destinations[smartLink.SmartLinkID]
+		if smartLink.Destinations == nil {
+			smartLink.Destinations = []*domain.SmartLinkDestination{}
+		}
+	}
+	return smartLinks, nil
+}
+
+func (r *pgxSmartLinkRepository) getDestinations(ctx context.Context, smartLinkIDs []int64) (map[int64][]*domain.SmartLinkDestination, error) {
+	destinations := make(map[int64][]*domain.SmartLinkDestination)
+	if len(smartLinkIDs) == 0 {
+		return destinations, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT destination_id, smart_link_id, tracking_link_id, campaign_id, weight, rules, daily_click_cap, is_active, position
+		FROM smart_link_destinations
+		WHERE smart_link_id = ANY($1)
+		ORDER BY smart_link_id, position`, smartLinkIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart link destinations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		destination := &domain.SmartLinkDestination{}
+		var rules []byte
+		err := rows.Scan(
+			&destination.DestinationID, &destination.SmartLinkID, &destination.TrackingLinkID, &destination.CampaignID,
+			&destination.Weight, &rules, &destination.DailyClickCap, &destination.IsActive, &destination.Position,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan smart link destination: %w", err)
+		}
+		if err := json.Unmarshal(rules, &destination.Rules); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal smart link rules: %w", err)
+		}
+		destinations[destination.SmartLinkID] = append(destinations[destination.SmartLinkID], destination)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating smart link destinations: %w", err)
+	}
+	return destinations, nil
+}
+
+// UpdateSmartLink updates a smart link and optionally replaces its destinations
+func (r *pgxSmartLinkRepository) UpdateSmartLink(ctx context.Context, smartLink *domain.SmartLink, replaceDestinations bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE smart_links
+		SET name = $2, status = $3, fallback_url = $4
+		WHERE smart_link_id = $1
+		RETURNING updated_at`,
+		smartLink.SmartLinkID, smartLink.Name, smartLink.Status, smartLink.FallbackURL,
+	).Scan(&smartLink.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update smart link: %w", err)
+	}
+
+	if replaceDestinations {
+		if _, err := tx.Exec(ctx, `DELETE FROM smart_link_destinations WHERE smart_link_id = $1`, smartLink.SmartLinkID); err != nil {
+			return fmt.Errorf("failed to delete smart link destinations: %w", err)
+		}
+		if err := insertSmartLinkDestinations(ctx, tx, smartLink); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteSmartLink deletes a smart link with its destinations and stats
+func (r *pgxSmartLinkRepository) DeleteSmartLink(ctx context.Context, smartLinkID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM smart_links WHERE smart_link_id = $1`, smartLinkID)
+	if err != nil {
+		return fmt.Errorf("failed to delete smart link: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetDestinationStates loads what the destination rules are evaluated against in one query.
+// The link windows and campaign dates are returned as stored; the evaluation applies them at the
+// time of the click.
+func (r *pgxSmartLinkRepository) GetDestinationStates(ctx context.Context, smartLinkID int64, date time.Time) (map[int64]domain.SmartLinkDestinationState, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT d.tracking_link_id, tl.status, COALESCE(tl.tracking_url, ''), c.status, COALESCE(s.clicks, 0),
+		       tl.active_from, tl.active_until, c.start_date, c.end_date
+		FROM smart_link_destinations d
+		JOIN tracking_links tl ON tl.tracking_link_id = d.tracking_link_id
+		JOIN campaigns c ON c.campaign_id = d.campaign_id
+		LEFT JOIN smart_link_daily_stats s
+			ON s.smart_link_id = d.smart_link_id AND s.stat_date = $2 AND s.tracking_link_id = d.tracking_link_id
+		WHERE d.smart_link_id = $1`, smartLinkID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart link destination states: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[int64]domain.SmartLinkDestinationState)
+	for rows.Next() {
+		var trackingLinkID int64
+		var state domain.SmartLinkDestinationState
+		if err := rows.Scan(&trackingLinkID, &state.TrackingLinkStatus, &state.TrackingURL, &state.CampaignStatus, &state.ClicksToday,
+			&state.ActiveFrom, &state.ActiveUntil, &state.CampaignStartDate, &state.CampaignEndDate); err != nil {
+			return nil, fmt.Errorf("failed to scan smart link destination state: %w", err)
+		}
+		states[trackingLinkID] = state
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating smart link destination states: %w", err)
+	}
+	return states, nil
+}
+
+// RecordClick increments the click count of a destination for the day
+func (r *pgxSmartLinkRepository) RecordClick(ctx context.Context, smartLinkID int64, date time.Time, trackingLinkID int64, campaignID *int64) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO smart_link_daily_stats (smart_link_id, stat_date, tracking_link_id, campaign_id, clicks)
+		VALUES ($1, $2, $3, $4, 1)
+		ON CONFLICT (smart_link_id, stat_date, tracking_link_id)
+		DO UPDATE SET clicks = smart_link_daily_stats.clicks + 1`,
+		smartLinkID, date, trackingLinkID, campaignID)
+	if err != nil {
+		return fmt.Errorf("failed to record smart link click: %w", err)
+	}
+	return nil
+}
+
+// GetDailyStats returns the daily clicks of a smart link, oldest first
+func (r *pgxSmartLinkRepository) GetDailyStats(ctx context.Context, smartLinkID int64, from, to time.Time) ([]*domain.SmartLinkDailyStats, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT stat_date, tracking_link_id, campaign_id, clicks
+		FROM smart_link_daily_stats
+		WHERE smart_link_id = $1 AND stat_date BETWEEN $2 AND $3
+		ORDER BY stat_date, tracking_link_id`, smartLinkID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart link stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]*domain.SmartLinkDailyStats, 0)
+	for rows.Next() {
+		var date time.Time
+		row := &domain.SmartLinkDailyStats{}
+		if err := rows.Scan(&date, &row.TrackingLinkID, &row.CampaignID, &row.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan smart link stats: %w", err)
+		}
+		row.Date = date.Format("2006-01-02")
+		stats = append(stats, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating smart link stats: %w", err)
+	}
+	return stats, nil
+}
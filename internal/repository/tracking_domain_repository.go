@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TrackingDomainRepository defines the interface for tracking domain data access
+type TrackingDomainRepository interface {
+	CreateDomain(ctx context.Context, trackingDomain *domain.TrackingDomain) error
+	GetDomainByID(ctx context.Context, trackingDomainID int64) (*domain.TrackingDomain, error)
+	// GetDomainByHostname returns the verified domain of a hostname; unverified claims are ignored
+	GetDomainByHostname(ctx context.Context, hostname string) (*domain.TrackingDomain, error)
+	// GetOrganizationDomainByHostname returns the domain an organization registered for a hostname, verified or not
+	GetOrganizationDomainByHostname(ctx context.Context, organizationID int64, hostname string) (*domain.TrackingDomain, error)
+	ListDomainsByOrganization(ctx context.Context, organizationID int64) ([]*domain.TrackingDomain, error)
+	CountDomainsByOrganization(ctx context.Context, organizationID int64) (int, error)
+	// GetDefaultDomain returns the default domain of an organization, verified or not
+	GetDefaultDomain(ctx context.Context, organizationID int64) (*domain.TrackingDomain, error)
+	// SetDefaultDomain makes a domain the default of its organization, replacing the previous one
+	SetDefaultDomain(ctx context.Context, organizationID, trackingDomainID int64) error
+	ClearDefaultDomain(ctx context.Context, organizationID int64) error
+	// UpdateVerification records the result of a DNS check
+	UpdateVerification(ctx context.Context, trackingDomain *domain.TrackingDomain) error
+	// ListDomainsToRecheck returns the verified and failed domains last checked before the given time
+	ListDomainsToRecheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*domain.TrackingDomain, error)
+	DeleteDomain(ctx context.Context, trackingDomainID int64) error
+
+	// GetCampaignDomain returns the domain override of a campaign
+	GetCampaignDomain(ctx context.Context, campaignID int64) (*domain.TrackingDomain, error)
+	SetCampaignDomain(ctx context.Context, campaignID, trackingDomainID int64) error
+	ClearCampaignDomain(ctx context.Context, campaignID int64) error
+}
+
+// pgxTrackingDomainRepository implements TrackingDomainRepository using pgx
+type pgxTrackingDomainRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxTrackingDomainRepository creates a new tracking domain repository
+func NewPgxTrackingDomainRepository(db *pgxpool.Pool) TrackingDomainRepository {
+	return &pgxTrackingDomainRepository{db: db}
+}
+
+const trackingDomainColumns = `td.tracking_domain_id, td.organization_id, td.hostname, td.status, td.verification_token,
+	td.is_default, td.verified_at, td.last_checked_at, td.last_error, td.created_at, td.updated_at`
+
+func scanTrackingDomain(row pgx.Row) (*domain.TrackingDomain, error) {
+	d := &domain.TrackingDomain{}
+	err := row.Scan(
+		&d.TrackingDomainID, &d.OrganizationID, &d.Hostname, &d.Status, &d.VerificationToken,
+		&d.IsDefault, &d.VerifiedAt, &d.LastCheckedAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to scan tracking domain: %w", err)
+	}
+	return d, nil
+}
+
+func (r *pgxTrackingDomainRepository) queryDomains(ctx context.Context, query string, args ...interface{}) ([]*domain.TrackingDomain, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracking domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make([]*domain.TrackingDomain, 0)
+	for rows.Next() {
+		d, err := scanTrackingDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tracking domains: %w", err)
+	}
+	return domains, nil
+}
+
+// CreateDomain stores a new tracking domain
+func (r *pgxTrackingDomainRepository) CreateDomain(ctx context.Context, trackingDomain *domain.TrackingDomain) error {
+	query := `
+		INSERT INTO tracking_domains (organization_id, hostname, status, verification_token)
+		VALUES ($1, $2, $3, $4)
+		RETURNING tracking_domain_id, is_default, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		trackingDomain.OrganizationID, trackingDomain.Hostname, trackingDomain.Status, trackingDomain.VerificationToken,
+	).Scan(&trackingDomain.TrackingDomainID, &trackingDomain.IsDefault, &trackingDomain.CreatedAt, &trackingDomain.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tracking domain: %w", err)
+	}
+	return nil
+}
+
+// GetDomainByID returns a tracking domain
+func (r *pgxTrackingDomainRepository) GetDomainByID(ctx context.Context, trackingDomainID int64) (*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM tracking_domains td WHERE td.tracking_domain_id = $1`
+	return scanTrackingDomain(r.db.QueryRow(ctx, query, trackingDomainID))
+}
+
+// GetDomainByHostname returns the verified tracking domain of a hostname
+func (r *pgxTrackingDomainRepository) GetDomainByHostname(ctx context.Context, hostname string) (*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM tracking_domains td WHERE td.hostname = $1 AND td.status = 'verified'`
+	return scanTrackingDomain(r.db.QueryRow(ctx, query, hostname))
+}
+
+// GetOrganizationDomainByHostname returns the tracking domain an organization registered for a hostname
+func (r *pgxTrackingDomainRepository) GetOrganizationDomainByHostname(ctx context.Context, organizationID int64, hostname string) (*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM tracking_domains td WHERE td.organization_id = $1 AND td.hostname = $2`
+	return scanTrackingDomain(r.db.QueryRow(ctx, query, organizationID, hostname))
+}
+
+// ListDomainsByOrganization lists the tracking domains of an organization, default first
+func (r *pgxTrackingDomainRepository) ListDomainsByOrganization(ctx context.Context, organizationID int64) ([]*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM tracking_domains td
+		WHERE td.organization_id = $1
+		ORDER BY td.is_default DESC, td.hostname`
+	return r.queryDomains(ctx, query, organizationID)
+}
+
+// CountDomainsByOrganization counts the tracking domains of an organization
+func (r *pgxTrackingDomainRepository) CountDomainsByOrganization(ctx context.Context, organizationID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM tracking_domains WHERE organization_id = $1`
+	if err := r.db.QueryRow(ctx, query, organizationID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tracking domains: %w", err)
+	}
+	return count, nil
+}
+
+// GetDefaultDomain returns the default domain of an organization
+func (r *pgxTrackingDomainRepository) GetDefaultDomain(ctx context.Context, organizationID int64) (*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM tracking_domains td WHERE td.organization_id = $1 AND td.is_default`
+	return scanTrackingDomain(r.db.QueryRow(ctx, query, organizationID))
+}
+
+// SetDefaultDomain switches the default domain of an organization in one transaction
+func (r *pgxTrackingDomainRepository) SetDefaultDomain(ctx context.Context, organizationID, trackingDomainID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE tracking_domains SET is_default = FALSE WHERE organization_id = $1 AND is_default`, organizationID); err != nil {
+		return fmt.Errorf("failed to clear default tracking domain: %w", err)
+	}
+	result, err := tx.Exec(ctx, `UPDATE tracking_domains SET is_default = TRUE WHERE tracking_domain_id = $1 AND organization_id = $2`,
+		trackingDomainID, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to set default tracking domain: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ClearDefaultDomain removes the default domain of an organization
+func (r *pgxTrackingDomainRepository) ClearDefaultDomain(ctx context.Context, organizationID int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE tracking_domains SET is_default = FALSE WHERE organization_id = $1 AND is_default`, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to clear default tracking domain: %w", err)
+	}
+	return nil
+}
+
+// UpdateVerification stores the status, verification time and error of a domain
+func (r *pgxTrackingDomainRepository) UpdateVerification(ctx context.Context, trackingDomain *domain.TrackingDomain) error {
+	query := `
+		UPDATE tracking_domains
+		SET status = $2, verified_at = $3, last_checked_at = $4, last_error = $5
+		WHERE tracking_domain_id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		trackingDomain.TrackingDomainID, trackingDomain.Status, trackingDomain.VerifiedAt,
+		trackingDomain.LastCheckedAt, trackingDomain.LastError,
+	).Scan(&trackingDomain.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to update tracking domain verification: %w", err)
+	}
+	return nil
+}
+
+// ListDomainsToRecheck lists the domains due for another DNS check, least recently checked first.
+// Pending domains are only checked on request.
+func (r *pgxTrackingDomainRepository) ListDomainsToRecheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM tracking_domains td
+		WHERE td.status IN ('verified', 'failed') AND (td.last_checked_at IS NULL OR td.last_checked_at < $1)
+		ORDER BY td.last_checked_at NULLS FIRST
+		LIMIT $2`
+	return r.queryDomains(ctx, query, checkedBefore, limit)
+}
+
+// DeleteDomain deletes a tracking domain; campaign overrides using it are removed with it
+func (r *pgxTrackingDomainRepository) DeleteDomain(ctx context.Context, trackingDomainID int64) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM tracking_domains WHERE tracking_domain_id = $1`, trackingDomainID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tracking domain: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetCampaignDomain returns the domain override of a campaign
+func (r *pgxTrackingDomainRepository) GetCampaignDomain(ctx context.Context, campaignID int64) (*domain.TrackingDomain, error) {
+	query := `SELECT ` + trackingDomainColumns + ` FROM campaign_tracking_domains ctd
+		JOIN tracking_domains td ON td.tracking_domain_id = ctd.tracking_domain_id
+		WHERE ctd.campaign_id = $1`
+	return scanTrackingDomain(r.db.QueryRow(ctx, query, campaignID))
+}
+
+// SetCampaignDomain sets or replaces the domain override of a campaign
+func (r *pgxTrackingDomainRepository) SetCampaignDomain(ctx context.Context, campaignID, trackingDomainID int64) error {
+	query := `
+		INSERT INTO campaign_tracking_domains (campaign_id, tracking_domain_id)
+		VALUES ($1, $2)
+		ON CONFLICT (campaign_id) DO UPDATE SET tracking_domain_id = EXCLUDED.tracking_domain_id`
+	if _, err := r.db.Exec(ctx, query, campaignID, trackingDomainID); err != nil {
+		return fmt.Errorf("failed to set campaign tracking domain: %w", err)
+	}
+	return nil
+}
+
+// ClearCampaignDomain removes the domain override of a campaign
+func (r *pgxTrackingDomainRepository) ClearCampaignDomain(ctx context.Context, campaignID int64) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM campaign_tracking_domains WHERE campaign_id = $1`, campaignID); err != nil {
+		return fmt.Errorf("failed to clear campaign tracking domain: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/affiliate-backend/internal/domain"
 	"github.com/jackc/pgx/v5"
@@ -22,6 +23,13 @@ type TrackingLinkRepository interface {
 	GetTrackingLinkByCampaignAndAffiliate(ctx context.Context, campaignID, affiliateID int64, sourceID, sub1, sub2, sub3, sub4, sub5 *string) (*domain.TrackingLink, error)
 	ListTrackingLinksByCampaignAndAffiliate(ctx context.Context, campaignID, affiliateID int64, limit, offset int) ([]*domain.TrackingLink, error)
 	ListTrackingLinksWithFilters(ctx context.Context, affiliateIDs, campaignIDs []int64, limit, offset int) ([]*domain.TrackingLink, int, error)
+
+	// ApplyLifecycleTransitions archives links whose window or campaign ended, and moves active
+	// and scheduled links to the status their window gives them at now. It returns the number
+	// of links changed.
+	ApplyLifecycleTransitions(ctx context.Context, now time.Time) (int64, error)
+	// UpdateTrackingURL replaces the stored tracking URL of a link
+	UpdateTrackingURL(ctx context.Context, trackingLinkID int64, trackingURL string) error
 }
 
 // trackingLinkRepository implements TrackingLinkRepository
@@ -42,8 +50,9 @@ func (r *trackingLinkRepository) CreateTrackingLink(ctx context.Context, trackin
 			tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
 			is_encrypt_parameters, is_redirect_link,
 			internal_notes, tags,
+			active_from, active_until, status_reason,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 		RETURNING tracking_link_id, created_at, updated_at`
 
 	err := r.db.QueryRow(ctx, query,
@@ -64,6 +73,9 @@ func (r *trackingLinkRepository) CreateTrackingLink(ctx context.Context, trackin
 		trackingLink.IsRedirectLink,
 		trackingLink.InternalNotes,
 		trackingLink.Tags,
+		trackingLink.ActiveFrom,
+		trackingLink.ActiveUntil,
+		trackingLink.StatusReason,
 		trackingLink.CreatedAt,
 		trackingLink.UpdatedAt,
 	).Scan(&trackingLink.TrackingLinkID, &trackingLink.CreatedAt, &trackingLink.UpdatedAt)
@@ -81,7 +93,7 @@ func (r *trackingLinkRepository) GetTrackingLinkByID(ctx context.Context, tracki
 		SELECT tracking_link_id, organization_id, campaign_id, affiliate_id, name, description, status,
 			   tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
 			   is_encrypt_parameters, is_redirect_link,
-			   internal_notes, tags,
+			   internal_notes, tags, active_from, active_until, status_reason,
 			   created_at, updated_at
 		FROM public.tracking_links
 		WHERE tracking_link_id = $1`
@@ -106,13 +118,16 @@ func (r *trackingLinkRepository) GetTrackingLinkByID(ctx context.Context, tracki
 		&trackingLink.IsRedirectLink,
 		&trackingLink.InternalNotes,
 		&trackingLink.Tags,
+		&trackingLink.ActiveFrom,
+		&trackingLink.ActiveUntil,
+		&trackingLink.StatusReason,
 		&trackingLink.CreatedAt,
 		&trackingLink.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("tracking link not found")
+			return nil, fmt.Errorf("tracking link not found: %w", domain.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get tracking link: %w", err)
 	}
@@ -128,6 +143,7 @@ func (r *trackingLinkRepository) UpdateTrackingLink(ctx context.Context, trackin
 			tracking_url = $5, source_id = $6, sub1 = $7, sub2 = $8, sub3 = $9, sub4 = $10, sub5 = $11,
 			is_encrypt_parameters = $12, is_redirect_link = $13,
 			internal_notes = $14, tags = $15,
+			active_from = $16, active_until = $17, status_reason = $18,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE tracking_link_id = $1
 		RETURNING updated_at`
@@ -148,6 +164,9 @@ func (r *trackingLinkRepository) UpdateTrackingLink(ctx context.Context, trackin
 		trackingLink.IsRedirectLink,
 		trackingLink.InternalNotes,
 		trackingLink.Tags,
+		trackingLink.ActiveFrom,
+		trackingLink.ActiveUntil,
+		trackingLink.StatusReason,
 	).Scan(&trackingLink.UpdatedAt)
 
 	if err != nil {
@@ -182,7 +201,7 @@ func (r *trackingLinkRepository) ListTrackingLinksByCampaign(ctx context.Context
 		SELECT tracking_link_id, organization_id, campaign_id, affiliate_id, name, description, status,
 			   tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
 			   is_encrypt_parameters, is_redirect_link,
-			   internal_notes, tags,
+			   internal_notes, tags, active_from, active_until, status_reason,
 			   created_at, updated_at
 		FROM public.tracking_links
 		WHERE campaign_id = $1
@@ -217,6 +236,9 @@ func (r *trackingLinkRepository) ListTrackingLinksByCampaign(ctx context.Context
 			&trackingLink.IsRedirectLink,
 			&trackingLink.InternalNotes,
 			&trackingLink.Tags,
+			&trackingLink.ActiveFrom,
+			&trackingLink.ActiveUntil,
+			&trackingLink.StatusReason,
 			&trackingLink.CreatedAt,
 			&trackingLink.UpdatedAt,
 		)
@@ -236,7 +258,7 @@ func (r *trackingLinkRepository) ListTrackingLinksByAffiliate(ctx context.Contex
 			   tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
 			   is_encrypt_parameters, is_redirect_link,
 			   
-			   internal_notes, tags,
+			   internal_notes, tags, active_from, active_until, status_reason,
 			   created_at, updated_at
 		FROM public.tracking_links
 		WHERE affiliate_id = $1
@@ -271,6 +293,9 @@ func (r *trackingLinkRepository) ListTrackingLinksByAffiliate(ctx context.Contex
 			&trackingLink.IsRedirectLink,
 			&trackingLink.InternalNotes,
 			&trackingLink.Tags,
+			&trackingLink.ActiveFrom,
+			&trackingLink.ActiveUntil,
+			&trackingLink.StatusReason,
 			&trackingLink.CreatedAt,
 			&trackingLink.UpdatedAt,
 		)
@@ -290,7 +315,7 @@ func (r *trackingLinkRepository) ListTrackingLinksByOrganization(ctx context.Con
 			   tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
 			   is_encrypt_parameters, is_redirect_link,
 			   
-			   internal_notes, tags,
+			   internal_notes, tags, active_from, active_until, status_reason,
 			   created_at, updated_at
 		FROM public.tracking_links
 		WHERE organization_id = $1
@@ -325,6 +350,9 @@ func (r *trackingLinkRepository) ListTrackingLinksByOrganization(ctx context.Con
 			&trackingLink.IsRedirectLink,
 			&trackingLink.InternalNotes,
 			&trackingLink.Tags,
+			&trackingLink.ActiveFrom,
+			&trackingLink.ActiveUntil,
+			&trackingLink.StatusReason,
 			&trackingLink.CreatedAt,
 			&trackingLink.UpdatedAt,
 		)
@@ -344,7 +372,7 @@ func (r *trackingLinkRepository) GetTrackingLinkByCampaignAndAffiliate(ctx conte
 			   tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
 			   is_encrypt_parameters, is_redirect_link,
 			   
-			   internal_notes, tags,
+			   internal_notes, tags, active_from, active_until, status_reason,
 			   created_at, updated_at
 		FROM public.tracking_links
 		WHERE campaign_id = $1 AND affiliate_id = $2 
@@ -375,6 +403,9 @@ func (r *trackingLinkRepository) GetTrackingLinkByCampaignAndAffiliate(ctx conte
 		&trackingLink.IsRedirectLink,
 		&trackingLink.InternalNotes,
 		&trackingLink.Tags,
+		&trackingLink.ActiveFrom,
+		&trackingLink.ActiveUntil,
+		&trackingLink.StatusReason,
 		&trackingLink.CreatedAt,
 		&trackingLink.UpdatedAt,
 	)
@@ -393,7 +424,7 @@ func (r *trackingLinkRepository) ListTrackingLinksByCampaignAndAffiliate(ctx con
 	query := `
 		SELECT tracking_link_id, organization_id, campaign_id, affiliate_id, name, description, 
 		       status, tracking_url, source_id, sub1, sub2, sub3, sub4, sub5, 
-		       is_encrypt_parameters, is_redirect_link, internal_notes, tags, 
+		       is_encrypt_parameters, is_redirect_link, internal_notes, tags, active_from, active_until, status_reason,
 		       created_at, updated_at
 		FROM tracking_links 
 		WHERE campaign_id = $1 AND affiliate_id = $2
@@ -428,6 +459,9 @@ func (r *trackingLinkRepository) ListTrackingLinksByCampaignAndAffiliate(ctx con
 			&trackingLink.IsRedirectLink,
 			&trackingLink.InternalNotes,
 			&trackingLink.Tags,
+			&trackingLink.ActiveFrom,
+			&trackingLink.ActiveUntil,
+			&trackingLink.StatusReason,
 			&trackingLink.CreatedAt,
 			&trackingLink.UpdatedAt,
 		)
@@ -450,7 +484,7 @@ func (r *trackingLinkRepository) ListTrackingLinksWithFilters(ctx context.Contex
 	baseQuery := `
 		SELECT tracking_link_id, organization_id, campaign_id, affiliate_id, name, description, status,
 		       tracking_url, source_id, sub1, sub2, sub3, sub4, sub5,
-		       is_encrypt_parameters, is_redirect_link, internal_notes, tags,
+		       is_encrypt_parameters, is_redirect_link, internal_notes, tags, active_from, active_until, status_reason,
 		       created_at, updated_at
 		FROM public.tracking_links`
 	
@@ -529,6 +563,9 @@ func (r *trackingLinkRepository) ListTrackingLinksWithFilters(ctx context.Contex
 			&trackingLink.IsRedirectLink,
 			&trackingLink.InternalNotes,
 			&trackingLink.Tags,
+			&trackingLink.ActiveFrom,
+			&trackingLink.ActiveUntil,
+			&trackingLink.StatusReason,
 			&trackingLink.CreatedAt,
 			&trackingLink.UpdatedAt,
 		)
@@ -544,3 +581,102 @@ func (r *trackingLinkRepository) ListTrackingLinksWithFilters(ctx context.Contex
 
 	return trackingLinks, total, nil
 }
+
+// lifecycleLink is a tracking link with the campaign dates its lifecycle status depends on
+type lifecycleLink struct {
+	link     domain.TrackingLink
+	campaign domain.Campaign
+}
+
+// lockLifecycleLinks locks the tracking links matched by condition, which refers to the link as
+// tl and to its campaign as c, and loads their window and campaign dates. The status they move
+// to is computed by the domain, not in SQL.
+func lockLifecycleLinks(ctx context.Context, tx pgx.Tx, condition string, args ...interface{}) ([]*lifecycleLink, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT tl.tracking_link_id, tl.status, tl.status_reason, tl.active_from, tl.active_until,
+		       c.start_date, c.end_date
+		FROM public.tracking_links tl
+		JOIN public.campaigns c ON c.campaign_id = tl.campaign_id
+		WHERE `+condition+`
+		FOR UPDATE OF tl`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock tracking links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]*lifecycleLink, 0)
+	for rows.Next() {
+		l := &lifecycleLink{}
+		if err := rows.Scan(&l.link.TrackingLinkID, &l.link.Status, &l.link.StatusReason, &l.link.ActiveFrom,
+			&l.link.ActiveUntil, &l.campaign.StartDate, &l.campaign.EndDate); err != nil {
+			return nil, fmt.Errorf("failed to scan tracking link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tracking links: %w", err)
+	}
+	return links, nil
+}
+
+// saveLifecycleStatus stores the status and status reason of a link locked by lockLifecycleLinks
+func saveLifecycleStatus(ctx context.Context, tx pgx.Tx, link *domain.TrackingLink) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE public.tracking_links SET status = $2, status_reason = $3
+		WHERE tracking_link_id = $1`, link.TrackingLinkID, link.Status, link.StatusReason)
+	if err != nil {
+		return fmt.Errorf("failed to update tracking link status: %w", err)
+	}
+	return nil
+}
+
+// ApplyLifecycleTransitions updates the links whose lifecycle status changed
+func (r *trackingLinkRepository) ApplyLifecycleTransitions(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A superset of the links whose status can change at now: scheduled links, and links with a
+	// window bound or campaign date on the wrong side of now, campaign dates with a day's margin
+	today := time.Date(now.UTC().Year(), now.UTC().Month(), now.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	links, err := lockLifecycleLinks(ctx, tx, `
+		tl.status = 'scheduled'
+		OR (tl.status IN ('active', 'paused')
+		    AND (tl.active_from > $1 OR tl.active_until <= $1 OR c.start_date >= $2::date OR c.end_date <= $2::date))`,
+		now, today)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, l := range links {
+		if !l.link.ApplyLifecycleTransition(&l.campaign, now) {
+			continue
+		}
+		if err := saveLifecycleStatus(ctx, tx, &l.link); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return changed, nil
+}
+
+// UpdateTrackingURL replaces the tracking URL of a link, e.g. when it is signed again
+func (r *trackingLinkRepository) UpdateTrackingURL(ctx context.Context, trackingLinkID int64, trackingURL string) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE public.tracking_links SET tracking_url = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE tracking_link_id = $1`, trackingLinkID, trackingURL)
+	if err != nil {
+		return fmt.Errorf("failed to update tracking URL: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TrackingLinkSigningRepository defines the interface for tracking link signing data access
+type TrackingLinkSigningRepository interface {
+	GetSettings(ctx context.Context, organizationID int64) (*domain.TrackingLinkSigningSettings, error)
+	// UpsertSettings creates or replaces the signing settings of an organization
+	UpsertSettings(ctx context.Context, settings *domain.TrackingLinkSigningSettings) error
+	DeleteSettings(ctx context.Context, organizationID int64) error
+
+	ListKeys(ctx context.Context, organizationID int64) ([]*domain.TrackingLinkSigningKey, error)
+	GetKey(ctx context.Context, keyID int64) (*domain.TrackingLinkSigningKey, error)
+	GetActiveKey(ctx context.Context, organizationID int64) (*domain.TrackingLinkSigningKey, error)
+	// RotateKey retires the active key of an organization and makes a new key with the given
+	// secret active
+	RotateKey(ctx context.Context, organizationID int64, encryptedSecret string) (*domain.TrackingLinkSigningKey, error)
+	// RevokeKey revokes a retired key so that the links it signed no longer verify
+	RevokeKey(ctx context.Context, organizationID, keyID int64) error
+
+	CreateTamperEvent(ctx context.Context, event *domain.TrackingLinkTamperEvent) error
+	ListTamperEvents(ctx context.Context, organizationID int64, limit, offset int) ([]*domain.TrackingLinkTamperEvent, int, error)
+}
+
+// pgxTrackingLinkSigningRepository implements TrackingLinkSigningRepository using pgx
+type pgxTrackingLinkSigningRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPgxTrackingLinkSigningRepository creates a new tracking link signing repository
+func NewPgxTrackingLinkSigningRepository(db *pgxpool.Pool) TrackingLinkSigningRepository {
+	return &pgxTrackingLinkSigningRepository{db: db}
+}
+
+// GetSettings retrieves the signing settings of an organization
+func (r *pgxTrackingLinkSigningRepository) GetSettings(ctx context.Context, organizationID int64) (*domain.TrackingLinkSigningSettings, error) {
+	query := `
+		SELECT organization_id, enforcement, created_at, updated_at
+		FROM tracking_link_signing_settings
+		WHERE organization_id = $1`
+
+	settings := &domain.TrackingLinkSigningSettings{}
+	err := r.db.QueryRow(ctx, query, organizationID).Scan(
+		&settings.OrganizationID, &settings.Enforcement, &settings.CreatedAt, &settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get tracking link signing settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpsertSettings creates or replaces the signing settings of an organization
+func (r *pgxTrackingLinkSigningRepository) UpsertSettings(ctx context.Context, settings *domain.TrackingLinkSigningSettings) error {
+	query := `
+		INSERT INTO tracking_link_signing_settings (organization_id, enforcement)
+		VALUES ($1, $2)
+		ON CONFLICT (organization_id) DO UPDATE SET enforcement = EXCLUDED.enforcement
+		RETURNING created_at, updated_at`
+	err := r.db.QueryRow(ctx, query, settings.OrganizationID, settings.Enforcement).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save tracking link signing settings: %w", err)
+	}
+	return nil
+}
+
+// DeleteSettings disables signing for an organization
+func (r *pgxTrackingLinkSigningRepository) DeleteSettings(ctx context.Context, organizationID int64) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM tracking_link_signing_settings WHERE organization_id = $1", organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tracking link signing settings: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+const trackingLinkSigningKeyColumns = `key_id, organization_id, encrypted_secret, status, created_at, retired_at, revoked_at`
+
+func scanTrackingLinkSigningKey(row pgx.Row) (*domain.TrackingLinkSigningKey, error) {
+	key := &domain.TrackingLinkSigningKey{}
+	err := row.Scan(&key.KeyID, &key.OrganizationID, &key.EncryptedSecret, &key.Status, &key.CreatedAt, &key.RetiredAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListKeys lists the signing keys of an organization, newest first
+func (r *pgxTrackingLinkSigningRepository) ListKeys(ctx context.Context, organizationID int64) ([]*domain.TrackingLinkSigningKey, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+trackingLinkSigningKeyColumns+`
+		FROM tracking_link_signing_keys
+		WHERE organization_id = $1
+		ORDER BY key_id DESC`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracking link signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.TrackingLinkSigningKey, 0)
+	for rows.Next() {
+		key, err := scanTrackingLinkSigningKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tracking link signing key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tracking link signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// GetKey retrieves a signing key by its ID
+func (r *pgxTrackingLinkSigningRepository) GetKey(ctx context.Context, keyID int64) (*domain.TrackingLinkSigningKey, error) {
+	key, err := scanTrackingLinkSigningKey(r.db.QueryRow(ctx,
+		`SELECT `+trackingLinkSigningKeyColumns+` FROM tracking_link_signing_keys WHERE key_id = $1`, keyID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get tracking link signing key: %w", err)
+	}
+	return key, nil
+}
+
+// GetActiveKey retrieves the key new links of an organization are signed with
+func (r *pgxTrackingLinkSigningRepository) GetActiveKey(ctx context.Context, organizationID int64) (*domain.TrackingLinkSigningKey, error) {
+	key, err := scanTrackingLinkSigningKey(r.db.QueryRow(ctx,
+		`SELECT `+trackingLinkSigningKeyColumns+` FROM tracking_link_signing_keys WHERE organization_id = $1 AND status = $2`,
+		organizationID, domain.SigningKeyStatusActive))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get active tracking link signing key: %w", err)
+	}
+	return key, nil
+}
+
+// RotateKey retires the active key and inserts a new active key in one transaction
+func (r *pgxTrackingLinkSigningRepository) RotateKey(ctx context.Context, organizationID int64, encryptedSecret string) (*domain.TrackingLinkSigningKey, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE tracking_link_signing_keys
+		SET status = $2, retired_at = NOW()
+		WHERE organization_id = $1 AND status = $3`,
+		organizationID, domain.SigningKeyStatusRetired, domain.SigningKeyStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retire tracking link signing key: %w", err)
+	}
+
+	key, err := scanTrackingLinkSigningKey(tx.QueryRow(ctx, `
+		INSERT INTO tracking_link_signing_keys (organization_id, encrypted_secret, status)
+		VALUES ($1, $2, $3)
+		RETURNING `+trackingLinkSigningKeyColumns,
+		organizationID, encryptedSecret, domain.SigningKeyStatusActive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracking link signing key: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return key, nil
+}
+
+// RevokeKey revokes a retired key of an organization
+func (r *pgxTrackingLinkSigningRepository) RevokeKey(ctx context.Context, organizationID, keyID int64) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE tracking_link_signing_keys
+		SET status = $3, revoked_at = NOW()
+		WHERE key_id = $1 AND organization_id = $2 AND status = $4`,
+		keyID, organizationID, domain.SigningKeyStatusRevoked, domain.SigningKeyStatusRetired)
+	if err != nil {
+		return fmt.Errorf("failed to revoke tracking link signing key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CreateTamperEvent records a click that failed verification
+func (r *pgxTrackingLinkSigningRepository) CreateTamperEvent(ctx context.Context, event *domain.TrackingLinkTamperEvent) error {
+	query := `
+		INSERT INTO tracking_link_tamper_events (organization_id, tracking_link_id, key_id, reason, action, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING event_id, created_at`
+	err := r.db.QueryRow(ctx, query,
+		event.OrganizationID, event.TrackingLinkID, event.KeyID, event.Reason, event.Action, event.IP, event.UserAgent,
+	).Scan(&event.EventID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tracking link tamper event: %w", err)
+	}
+	return nil
+}
+
+// ListTamperEvents lists the tamper events of an organization, newest first
+func (r *pgxTrackingLinkSigningRepository) ListTamperEvents(ctx context.Context, organizationID int64, limit, offset int) ([]*domain.TrackingLinkTamperEvent, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM tracking_link_tamper_events WHERE organization_id = $1`, organizationID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tracking link tamper events: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT event_id, organization_id, tracking_link_id, key_id, reason, action, ip, user_agent, created_at
+		FROM tracking_link_tamper_events
+		WHERE organization_id = $1
+		ORDER BY created_at DESC, event_id DESC
+		LIMIT $2 OFFSET $3`, organizationID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list tracking link tamper events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.TrackingLinkTamperEvent, 0)
+	for rows.Next() {
+		event := &domain.TrackingLinkTamperEvent{}
+		if err := rows.Scan(&event.EventID, &event.OrganizationID, &event.TrackingLinkID, &event.KeyID, &event.Reason,
+			&event.Action, &event.IP, &event.UserAgent, &event.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan tracking link tamper event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate tracking link tamper events: %w", err)
+	}
+	return events, total, nil
+}
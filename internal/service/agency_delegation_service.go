@@ -36,6 +36,7 @@ type agencyDelegationService struct {
 	delegationRepo   repository.AgencyDelegationRepository
 	organizationRepo repository.OrganizationRepository
 	profileRepo      repository.ProfileRepository
+	notifier         NotificationService
 }
 
 // NewAgencyDelegationService creates a new agency delegation service
@@ -43,11 +44,13 @@ func NewAgencyDelegationService(
 	delegationRepo repository.AgencyDelegationRepository,
 	organizationRepo repository.OrganizationRepository,
 	profileRepo repository.ProfileRepository,
+	notifier NotificationService,
 ) AgencyDelegationService {
 	return &agencyDelegationService{
 		delegationRepo:   delegationRepo,
 		organizationRepo: organizationRepo,
 		profileRepo:      profileRepo,
+		notifier:         notifier,
 	}
 }
 
@@ -127,7 +130,27 @@ func (s *agencyDelegationService) CreateDelegation(ctx context.Context, req *dom
 		return nil, fmt.Errorf("invalid delegation: %w", err)
 	}
 
-	return s.delegationRepo.Create(ctx, delegation)
+	created, err := s.delegationRepo.Create(ctx, delegation)
+	if err != nil {
+		return nil, err
+	}
+
+	// The agency has to accept the delegation
+	notification := &domain.NewNotification{
+		OrganizationID: req.AgencyOrgID,
+		Type:           domain.NotificationTypeDelegationInvite,
+		Title:          fmt.Sprintf("%s invited your agency to manage its account", advertiserOrg.Name),
+		Data: map[string]interface{}{
+			"delegation_id":     created.DelegationID,
+			"advertiser_org_id": req.AdvertiserOrgID,
+		},
+	}
+	if req.Message != nil {
+		notification.Body = notificationPreview(*req.Message)
+	}
+	notify(ctx, s.notifier, notification)
+
+	return created, nil
 }
 
 // AcceptDelegation accepts a pending delegation
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// AnalyticsImportService defines the interface for bulk analytics imports
+type AnalyticsImportService interface {
+	// Import reads newline-delimited JSON records of the given kind from r and upserts them by domain
+	// in batches. Row errors are recorded on the import; an error is only returned when the import
+	// could not be started. userID may be empty for imports started outside the API.
+	Import(ctx context.Context, kind, source, userID string, r io.Reader, batchSize int) (*domain.AnalyticsImport, error)
+	GetImport(ctx context.Context, importID int64) (*domain.AnalyticsImport, error)
+	ListImports(ctx context.Context, page, pageSize int) (*domain.AnalyticsImportListResponse, error)
+}
+
+// analyticsImportService implements AnalyticsImportService
+type analyticsImportService struct {
+	importRepo repository.AnalyticsImportRepository
+}
+
+// NewAnalyticsImportService creates a new analytics import service
+func NewAnalyticsImportService(importRepo repository.AnalyticsImportRepository) AnalyticsImportService {
+	return &analyticsImportService{importRepo: importRepo}
+}
+
+// analyticsImportBatch collects the parsed records of one kind until they are upserted
+type analyticsImportBatch interface {
+	// add parses a line and adds it to the batch. A record for a domain already in the batch
+	// replaces the earlier one, which is reported as replaced.
+	add(lineNumber int, line []byte) (replaced bool, err error)
+	size() int
+	// upsert writes the whole batch in one transaction
+	upsert(ctx context.Context, importID int64) (*repository.AnalyticsUpsertResult, error)
+	// upsertEach writes the records one by one, returning the errors of the records that failed
+	upsertEach(ctx context.Context, importID int64) (*repository.AnalyticsUpsertResult, []domain.AnalyticsImportError)
+	reset()
+}
+
+// Import runs a bulk import
+func (s *analyticsImportService) Import(ctx context.Context, kind, source, userID string, r io.Reader, batchSize int) (*domain.AnalyticsImport, error) {
+	if !domain.IsValidAnalyticsImportKind(kind) {
+		return nil, fmt.Errorf("%w: kind must be publishers or advertisers", domain.ErrInvalidInput)
+	}
+	if batchSize < 1 {
+		batchSize = domain.DefaultAnalyticsImportBatchSize
+	}
+	if batchSize > domain.MaxAnalyticsImportBatchSize {
+		batchSize = domain.MaxAnalyticsImportBatchSize
+	}
+	if len(source) > 255 {
+		source = source[:255]
+	}
+
+	analyticsImport := &domain.AnalyticsImport{
+		Kind:   kind,
+		Source: source,
+		Status: domain.AnalyticsImportStatusRunning,
+	}
+	if userID != "" {
+		analyticsImport.CreatedByUserID = &userID
+	}
+	if err := s.importRepo.CreateImport(ctx, analyticsImport); err != nil {
+		return nil, err
+	}
+
+	var batch analyticsImportBatch
+	if kind == domain.AnalyticsImportPublishers {
+		batch = &publisherImportBatch{importRepo: s.importRepo, index: make(map[string]int)}
+	} else {
+		batch = &advertiserImportBatch{importRepo: s.importRepo, index: make(map[string]int)}
+	}
+
+	runErr := s.run(ctx, analyticsImport, batch, r, batchSize)
+
+	analyticsImport.Status = domain.AnalyticsImportStatusCompleted
+	if runErr != nil {
+		message := runErr.Error()
+		analyticsImport.Status = domain.AnalyticsImportStatusFailed
+		analyticsImport.ErrorMessage = &message
+		logger.Error("Analytics import failed", "import_id", analyticsImport.ImportID, "kind", kind, "error", runErr)
+	}
+
+	// Record the outcome even if the request was cancelled
+	if err := s.importRepo.FinishImport(context.WithoutCancel(ctx), analyticsImport); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Analytics import finished",
+		"import_id", analyticsImport.ImportID, "kind", kind, "status", analyticsImport.Status,
+		"inserted", analyticsImport.InsertedCount, "updated", analyticsImport.UpdatedCount,
+		"skipped", analyticsImport.SkippedCount, "failed", analyticsImport.FailedCount)
+
+	return analyticsImport, nil
+}
+
+// run reads the input and upserts it batch by batch, updating the counts of analyticsImport.
+// Row errors are recorded and counted; the returned error aborts the import.
+func (s *analyticsImportService) run(ctx context.Context, analyticsImport *domain.AnalyticsImport, batch analyticsImportBatch, r io.Reader, batchSize int) error {
+	var pendingErrors []domain.AnalyticsImportError
+	recordedErrors := 0
+
+	recordError := func(importError domain.AnalyticsImportError) {
+		analyticsImport.FailedCount++
+		if recordedErrors < domain.MaxAnalyticsImportErrors {
+			pendingErrors = append(pendingErrors, importError)
+			recordedErrors++
+		}
+	}
+
+	flush := func() error {
+		if batch.size() > 0 {
+			result, err := batch.upsert(ctx, analyticsImport.ImportID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Retry record by record so one bad row does not fail the whole batch
+				logger.Warn("Analytics import batch failed, retrying records individually",
+					"import_id", analyticsImport.ImportID, "records", batch.size(), "error", err)
+				var rowErrors []domain.AnalyticsImportError
+				result, rowErrors = batch.upsertEach(ctx, analyticsImport.ImportID)
+				for _, rowError := range rowErrors {
+					recordError(rowError)
+				}
+			}
+			analyticsImport.InsertedCount += result.Inserted
+			analyticsImport.UpdatedCount += result.Updated
+			analyticsImport.SkippedCount += result.Skipped
+			batch.reset()
+		}
+
+		if len(pendingErrors) > 0 {
+			if err := s.importRepo.AddImportErrors(ctx, analyticsImport.ImportID, pendingErrors); err != nil {
+				return err
+			}
+			pendingErrors = pendingErrors[:0]
+		}
+		return ctx.Err()
+	}
+
+	reader := bufio.NewReaderSize(r, 1<<20)
+	for lineNumber := 1; ; lineNumber++ {
+		line, tooLong, readErr := readImportLine(reader, domain.MaxAnalyticsImportLineBytes)
+		if readErr != nil && readErr != io.EOF {
+			if err := flush(); err != nil {
+				return errors.Join(readErr, err)
+			}
+			return fmt.Errorf("failed to read import data: %w", readErr)
+		}
+
+		line = bytes.TrimSpace(line)
+		if lineNumber == 1 {
+			line = bytes.TrimPrefix(line, []byte("\xef\xbb\xbf")) // UTF-8 byte order mark
+		}
+
+		switch {
+		case tooLong:
+			recordError(domain.AnalyticsImportError{
+				Line:    lineNumber,
+				Message: fmt.Sprintf("record exceeds %d bytes", domain.MaxAnalyticsImportLineBytes),
+			})
+		case len(line) > 0:
+			replaced, err := batch.add(lineNumber, line)
+			if err != nil {
+				recordError(domain.AnalyticsImportError{Line: lineNumber, Message: err.Error()})
+			} else if replaced {
+				analyticsImport.SkippedCount++
+			}
+		}
+
+		if batch.size() >= batchSize || len(pendingErrors) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			return flush()
+		}
+	}
+}
+
+// readImportLine reads one line of up to maxBytes. Longer lines are consumed and reported as tooLong.
+func readImportLine(reader *bufio.Reader, maxBytes int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong && len(line)+len(chunk) <= maxBytes {
+			line = append(line, chunk...)
+		} else {
+			tooLong = true
+			line = nil
+		}
+		if err != bufio.ErrBufferFull {
+			return line, tooLong, err
+		}
+	}
+}
+
+// GetImport retrieves an import with its recorded errors
+func (s *analyticsImportService) GetImport(ctx context.Context, importID int64) (*domain.AnalyticsImport, error) {
+	return s.importRepo.GetImport(ctx, importID)
+}
+
+// ListImports lists imports, most recent first
+func (s *analyticsImportService) ListImports(ctx context.Context, page, pageSize int) (*domain.AnalyticsImportListResponse, error) {
+	imports, total, err := s.importRepo.ListImports(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AnalyticsImportListResponse{
+		Imports:  imports,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// publisherImportBatch is an analyticsImportBatch of publishers
+type publisherImportBatch struct {
+	importRepo repository.AnalyticsImportRepository
+	records    []*domain.AnalyticsPublisherImportRecord
+	index      map[string]int // Domain -> position in records
+}
+
+func (b *publisherImportBatch) add(lineNumber int, line []byte) (bool, error) {
+	record, err := domain.ParseAnalyticsPublisherRecord(line)
+	if err != nil {
+		return false, err
+	}
+	record.Line = lineNumber
+
+	if i, ok := b.index[record.Publisher.Domain]; ok {
+		b.records[i] = record
+		return true, nil
+	}
+	b.index[record.Publisher.Domain] = len(b.records)
+	b.records = append(b.records, record)
+	return false, nil
+}
+
+func (b *publisherImportBatch) size() int {
+	return len(b.records)
+}
+
+func (b *publisherImportBatch) upsert(ctx context.Context, importID int64) (*repository.AnalyticsUpsertResult, error) {
+	return b.importRepo.UpsertPublishers(ctx, importID, b.records)
+}
+
+func (b *publisherImportBatch) upsertEach(ctx context.Context, importID int64) (*repository.AnalyticsUpsertResult, []domain.AnalyticsImportError) {
+	total := &repository.AnalyticsUpsertResult{}
+	var rowErrors []domain.AnalyticsImportError
+	for _, record := range b.records {
+		result, err := b.importRepo.UpsertPublishers(ctx, importID, []*domain.AnalyticsPublisherImportRecord{record})
+		if err != nil {
+			rowErrors = append(rowErrors, domain.AnalyticsImportError{
+				Line:    record.Line,
+				Domain:  record.Publisher.Domain,
+				Message: err.Error(),
+			})
+			continue
+		}
+		total.Inserted += result.Inserted
+		total.Updated += result.Updated
+		total.Skipped += result.Skipped
+	}
+	return total, rowErrors
+}
+
+func (b *publisherImportBatch) reset() {
+	b.records = b.records[:0]
+	b.index = make(map[string]int)
+}
+
+// advertiserImportBatch is an analyticsImportBatch of advertisers
+type advertiserImportBatch struct {
+	importRepo repository.AnalyticsImportRepository
+	records    []*domain.AnalyticsAdvertiserImportRecord
+	index      map[string]int // Domain -> position in records
+}
+
+func (b *advertiserImportBatch) add(lineNumber int, line []byte) (bool, error) {
+	record, err := domain.ParseAnalyticsAdvertiserRecord(line)
+	if err != nil {
+		return false, err
+	}
+	record.Line = lineNumber
+
+	if i, ok := b.index[record.Advertiser.Domain]; ok {
+		b.records[i] = record
+		return true, nil
+	}
+	b.index[record.Advertiser.Domain] = len(b.records)
+	b.records = append(b.records, record)
+	return false, nil
+}
+
+func (b *advertiserImportBatch) size() int {
+	return len(b.records)
+}
+
+func (b *advertiserImportBatch) upsert(ctx context.Context, importID int64) (*repository.AnalyticsUpsertResult, error) {
+	return b.importRepo.UpsertAdvertisers(ctx, importID, b.records)
+}
+
+func (b *advertiserImportBatch) upsertEach(ctx context.Context, importID int64) (*repository.AnalyticsUpsertResult, []domain.AnalyticsImportError) {
+	total := &repository.AnalyticsUpsertResult{}
+	var rowErrors []domain.AnalyticsImportError
+	for _, record := range b.records {
+		result, err := b.importRepo.UpsertAdvertisers(ctx, importID, []*domain.AnalyticsAdvertiserImportRecord{record})
+		if err != nil {
+			rowErrors = append(rowErrors, domain.AnalyticsImportError{
+				Line:    record.Line,
+				Domain:  record.Advertiser.Domain,
+				Message: err.Error(),
+			})
+			continue
+		}
+		total.Inserted += result.Inserted
+		total.Updated += result.Updated
+		total.Skipped += result.Skipped
+	}
+	return total, rowErrors
+}
+
+func (b *advertiserImportBatch) reset() {
+	b.records = b.records[:0]
+	b.index = make(map[string]int)
+}
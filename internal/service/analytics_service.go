@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
 	"github.com/affiliate-backend/internal/repository"
 )
 
@@ -30,6 +31,9 @@ type AnalyticsService interface {
 	GetPublisherByID(ctx context.Context, id int64) (*domain.AnalyticsPublisherResponse, error)
 	GetPublisherByDomain(ctx context.Context, domainName string) (*domain.AnalyticsPublisherResponse, error)
 	AffiliatesSearch(ctx context.Context, domainFilter, country string, partnerDomains []string, verticals []string, page, offset int) (*AffiliatesSearchResult, error)
+	DiscoverPublishers(ctx context.Context, req *domain.PublisherSearchRequest) (*domain.PublisherSearchResponse, error)
+	GetSimilarPublishers(ctx context.Context, publisherDomain string, limit int) (*domain.SimilarPublishersResponse, error)
+	GetPublisherHistory(ctx context.Context, publisherDomain string, query *domain.PublisherHistoryQuery) (*domain.PublisherHistoryResponse, error)
 	CreatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error
 	UpdatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error
 	DeletePublisher(ctx context.Context, id int64) error
@@ -72,6 +76,93 @@ func (s *analyticsService) AffiliatesSearch(ctx context.Context, domainFilter, c
 	}, nil
 }
 
+// DiscoverPublishers runs a ranked publisher discovery search with facet counts for the same filters
+func (s *analyticsService) DiscoverPublishers(ctx context.Context, req *domain.PublisherSearchRequest) (*domain.PublisherSearchResponse, error) {
+	query, err := req.ToQuery()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	result, err := s.analyticsRepo.DiscoverPublishers(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := s.analyticsRepo.GetPublisherSearchFacets(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*domain.AnalyticsPublisherResponse, 0, len(result.Data))
+	for _, publisher := range result.Data {
+		response, err := s.buildPublisherResponse(publisher)
+		if err != nil {
+			continue
+		}
+		responses = append(responses, response)
+	}
+
+	return &domain.PublisherSearchResponse{
+		Data:     responses,
+		Total:    result.Total,
+		Page:     query.Offset/query.Limit + 1,
+		PageSize: query.Limit,
+		Facets:   *facets,
+	}, nil
+}
+
+// GetSimilarPublishers recommends publishers resembling the given one by related publishers,
+// shared partners, verticals and countries
+func (s *analyticsService) GetSimilarPublishers(ctx context.Context, publisherDomain string, limit int) (*domain.SimilarPublishersResponse, error) {
+	publisher, err := s.analyticsRepo.GetPublisherByDomain(ctx, publisherDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLookalikeRecommender(s.analyticsRepo).recommend(ctx, []*domain.AnalyticsPublisher{publisher}, nil, limit)
+}
+
+// GetPublisherHistory returns the snapshot time series of a publisher with the changes between
+// consecutive snapshots
+func (s *analyticsService) GetPublisherHistory(ctx context.Context, publisherDomain string, query *domain.PublisherHistoryQuery) (*domain.PublisherHistoryResponse, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	freshness, err := s.analyticsRepo.GetPublisherFreshness(ctx, publisherDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.analyticsRepo.ListPublisherSnapshots(ctx, publisherDomain, query)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*domain.PublisherSnapshotDiff, 0, len(snapshots))
+	for i := 1; i < len(snapshots); i++ {
+		changes = append(changes, domain.DiffPublisherSnapshots(snapshots[i-1], snapshots[i]))
+	}
+
+	return &domain.PublisherHistoryResponse{
+		Domain:    publisherDomain,
+		Freshness: *freshness,
+		Snapshots: snapshots,
+		Changes:   changes,
+	}, nil
+}
+
+// recordPublisherSnapshot adds a snapshot after a publisher was written outside of a bulk import.
+// The write itself has succeeded, so failures are only logged.
+func (s *analyticsService) recordPublisherSnapshot(ctx context.Context, publisher *domain.AnalyticsPublisher) {
+	snapshot := domain.NewAnalyticsPublisherSnapshot(publisher)
+	snapshot.PublisherID = publisher.ID
+	snapshot.ContentHash = snapshot.MetricsHash()
+	if err := s.analyticsRepo.CreatePublisherSnapshot(ctx, snapshot); err != nil {
+		logger.Warn("Failed to record publisher snapshot", "domain", publisher.Domain, "error", err)
+	}
+}
+
 // NewAnalyticsService creates a new analytics service
 func NewAnalyticsService(analyticsRepo repository.AnalyticsRepository) AnalyticsService {
 	return &analyticsService{
@@ -148,12 +239,20 @@ func (s *analyticsService) DeleteAdvertiser(ctx context.Context, id int64) error
 
 // CreatePublisher creates a new publisher
 func (s *analyticsService) CreatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error {
-	return s.analyticsRepo.CreatePublisher(ctx, publisher)
+	if err := s.analyticsRepo.CreatePublisher(ctx, publisher); err != nil {
+		return err
+	}
+	s.recordPublisherSnapshot(ctx, publisher)
+	return nil
 }
 
 // UpdatePublisher updates an existing publisher
 func (s *analyticsService) UpdatePublisher(ctx context.Context, publisher *domain.AnalyticsPublisher) error {
-	return s.analyticsRepo.UpdatePublisher(ctx, publisher)
+	if err := s.analyticsRepo.UpdatePublisher(ctx, publisher); err != nil {
+		return err
+	}
+	s.recordPublisherSnapshot(ctx, publisher)
+	return nil
 }
 
 // DeletePublisher deletes a publisher
@@ -20,15 +20,18 @@ type BillingService struct {
 	transactionRepo    repository.TransactionRepository
 	organizationRepo   repository.OrganizationRepository
 	stripeService      *stripe.Service
+	notifier           NotificationService
 }
 
-// NewBillingService creates a new billing service
+// NewBillingService creates a new billing service. Low balances and failed recharges are
+// reported through notifier, when set.
 func NewBillingService(
 	billingAccountRepo repository.BillingAccountRepository,
 	paymentMethodRepo repository.PaymentMethodRepository,
 	transactionRepo repository.TransactionRepository,
 	organizationRepo repository.OrganizationRepository,
 	stripeService *stripe.Service,
+	notifier NotificationService,
 ) *BillingService {
 	return &BillingService{
 		billingAccountRepo: billingAccountRepo,
@@ -36,6 +39,7 @@ func NewBillingService(
 		transactionRepo:    transactionRepo,
 		organizationRepo:   organizationRepo,
 		stripeService:      stripeService,
+		notifier:           notifier,
 	}
 }
 
@@ -273,6 +277,19 @@ func (s *BillingService) Recharge(ctx context.Context, organizationID int64, req
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
+	if transaction.Status == domain.TransactionStatusFailed {
+		notify(ctx, s.notifier, &domain.NewNotification{
+			OrganizationID: organizationID,
+			Type:           domain.NotificationTypeBillingAlert,
+			Title:          "Recharge failed",
+			Body:           fmt.Sprintf("The recharge of %s %s could not be completed", req.Amount.StringFixed(2), currency),
+			Data: map[string]interface{}{
+				"alert":          "recharge_failed",
+				"transaction_id": transaction.TransactionID,
+			},
+		})
+	}
+
 	// Update balance if payment succeeded
 	if paymentIntent.Status == stripeLib.PaymentIntentStatusSucceeded {
 		err = s.billingAccountRepo.UpdateBalance(ctx, account.BillingAccountID, transaction.BalanceAfter)
@@ -332,6 +349,23 @@ func (s *BillingService) DebitAccount(ctx context.Context, organizationID int64,
 		return nil, fmt.Errorf("failed to update account balance: %w", err)
 	}
 
+	// Alert once, when the balance falls below the threshold
+	threshold := account.AutoRechargeThreshold
+	if threshold.IsPositive() && transaction.BalanceBefore.GreaterThanOrEqual(threshold) && transaction.BalanceAfter.LessThan(threshold) {
+		notify(ctx, s.notifier, &domain.NewNotification{
+			OrganizationID: organizationID,
+			Type:           domain.NotificationTypeBillingAlert,
+			Title:          "Balance is low",
+			Body: fmt.Sprintf("Your balance is %s %s, below the %s %s threshold",
+				transaction.BalanceAfter.StringFixed(2), account.Currency, threshold.StringFixed(2), account.Currency),
+			Data: map[string]interface{}{
+				"alert":     "low_balance",
+				"balance":   transaction.BalanceAfter.String(),
+				"threshold": threshold.String(),
+			},
+		})
+	}
+
 	logger.Info("Created debit transaction", 
 		"transaction_id", transaction.TransactionID, 
 		"organization_id", organizationID, 
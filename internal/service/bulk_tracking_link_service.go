@@ -0,0 +1,475 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/export"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// BulkTrackingLinkService defines the interface for bulk tracking link generation jobs
+type BulkTrackingLinkService interface {
+	// CreateJob resolves the affiliates of a job and starts generating their links in the
+	// background; the job is returned as soon as it is saved
+	CreateJob(ctx context.Context, orgID int64, userID string, req *domain.CreateBulkTrackingLinkJobRequest) (*domain.BulkTrackingLinkJobWithProgress, error)
+	GetJob(ctx context.Context, orgID, jobID int64) (*domain.BulkTrackingLinkJobWithProgress, error)
+	ListJobs(ctx context.Context, orgID int64, page, pageSize int) (*domain.BulkTrackingLinkJobListResponse, error)
+	// ListJobItems lists the items of a job, optionally with a given status
+	ListJobItems(ctx context.Context, orgID, jobID int64, status string) ([]*domain.BulkTrackingLinkJobItem, error)
+	// ExportJob renders the items of a job in the given export format
+	ExportJob(ctx context.Context, orgID, jobID int64, format string) (*export.File, error)
+
+	// ResumeJobs starts the running jobs no instance is processing, e.g. after a restart; called at
+	// startup and periodically to take over the jobs of instances that went away
+	ResumeJobs(ctx context.Context) error
+	// Stop stops handing out items, waits for the links being generated and releases the jobs so
+	// that another instance resumes them
+	Stop()
+}
+
+// bulkTrackingLinkService implements BulkTrackingLinkService
+type bulkTrackingLinkService struct {
+	jobRepo               repository.BulkTrackingLinkJobRepository
+	campaignRepo          repository.CampaignRepository
+	affiliateRepo         repository.AffiliateRepository
+	trackingLinkService   TrackingLinkService
+	orgAssociationService OrganizationAssociationService
+	instanceID            string          // Lease owner of the jobs processed by this instance
+	running               sync.Map        // Job IDs being processed by this instance
+	ctx                   context.Context // Cancelled by Stop
+	cancel                context.CancelFunc
+	mu                    sync.Mutex // Orders starting jobs with Stop
+	wg                    sync.WaitGroup
+}
+
+// NewBulkTrackingLinkService creates a new bulk tracking link service
+func NewBulkTrackingLinkService(
+	jobRepo repository.BulkTrackingLinkJobRepository,
+	campaignRepo repository.CampaignRepository,
+	affiliateRepo repository.AffiliateRepository,
+	trackingLinkService TrackingLinkService,
+	orgAssociationService OrganizationAssociationService,
+) BulkTrackingLinkService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &bulkTrackingLinkService{
+		jobRepo:               jobRepo,
+		campaignRepo:          campaignRepo,
+		affiliateRepo:         affiliateRepo,
+		trackingLinkService:   trackingLinkService,
+		orgAssociationService: orgAssociationService,
+		instanceID:            uuid.NewString(),
+		ctx:                   ctx,
+		cancel:                cancel,
+	}
+}
+
+// CreateJob validates the request, renders the template for each affiliate and starts the job
+func (s *bulkTrackingLinkService) CreateJob(ctx context.Context, orgID int64, userID string, req *domain.CreateBulkTrackingLinkJobRequest) (*domain.BulkTrackingLinkJobWithProgress, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, req.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+
+	job := &domain.BulkTrackingLinkJob{
+		OrganizationID:       orgID,
+		CampaignID:           campaign.CampaignID,
+		Status:               domain.BulkLinkJobStatusRunning,
+		AllVisibleAffiliates: req.AllVisibleAffiliates,
+		Template:             req.Template,
+		IsEncryptParameters:  req.IsEncryptParameters,
+		IsRedirectLink:       req.IsRedirectLink,
+		Concurrency:          req.Concurrency,
+	}
+	if userID != "" {
+		job.CreatedByUserID = &userID
+	}
+
+	now := time.Now().UTC()
+	var items []*domain.BulkTrackingLinkJobItem
+	if req.AllVisibleAffiliates {
+		affiliates, err := s.visibleAffiliates(ctx, campaign)
+		if err != nil {
+			return nil, err
+		}
+		if len(affiliates) == 0 {
+			return nil, fmt.Errorf("%w: no affiliates are visible to the campaign through active associations", domain.ErrInvalidInput)
+		}
+		if len(affiliates) > domain.MaxBulkLinkJobAffiliates {
+			return nil, fmt.Errorf("%w: %d affiliates are visible, at most %d can be included in one job",
+				domain.ErrInvalidInput, len(affiliates), domain.MaxBulkLinkJobAffiliates)
+		}
+		for _, affiliate := range affiliates {
+			items = append(items, newBulkTrackingLinkJobItem(&job.Template, campaign, affiliate, now))
+		}
+	} else {
+		for _, affiliateID := range req.AffiliateIDs {
+			affiliate, err := s.affiliateRepo.GetAffiliateByID(ctx, affiliateID)
+			if err != nil {
+				// Unknown affiliates fail on their own without holding up the rest of the job
+				message := "affiliate not found"
+				if !errors.Is(err, domain.ErrNotFound) {
+					message = err.Error()
+				}
+				items = append(items, &domain.BulkTrackingLinkJobItem{
+					AffiliateID:  affiliateID,
+					Status:       domain.BulkLinkItemStatusFailed,
+					ErrorMessage: &message,
+					ProcessedAt:  &now,
+				})
+				continue
+			}
+			items = append(items, newBulkTrackingLinkJobItem(&job.Template, campaign, affiliate, now))
+		}
+	}
+
+	if err := s.jobRepo.CreateJob(ctx, job, items); err != nil {
+		return nil, err
+	}
+	logger.Info("Bulk tracking link job created", "job_id", job.JobID, "campaign_id", job.CampaignID, "items", job.TotalCount)
+
+	s.start(job)
+	return domain.NewBulkTrackingLinkJobWithProgress(job), nil
+}
+
+func newBulkTrackingLinkJobItem(template *domain.TrackingLinkTemplate, campaign *domain.Campaign, affiliate *domain.Affiliate, date time.Time) *domain.BulkTrackingLinkJobItem {
+	return &domain.BulkTrackingLinkJobItem{
+		AffiliateID:   affiliate.AffiliateID,
+		AffiliateName: affiliate.Name,
+		Status:        domain.BulkLinkItemStatusPending,
+		Parameters:    template.Render(campaign, affiliate, date),
+	}
+}
+
+// visibleAffiliates returns the affiliates of the active associations of the campaign's
+// organization to which both the campaign and the affiliate are visible, as link upserts require
+func (s *bulkTrackingLinkService) visibleAffiliates(ctx context.Context, campaign *domain.Campaign) ([]*domain.Affiliate, error) {
+	activeStatus := domain.AssociationStatusActive
+	associations, err := s.orgAssociationService.ListAssociations(ctx, &domain.AssociationListFilter{
+		AdvertiserOrgID: &campaign.OrganizationID,
+		Status:          &activeStatus,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var affiliates []*domain.Affiliate
+	for _, association := range associations {
+		if !association.AllCampaignsVisible {
+			if association.VisibleCampaignIDs == nil {
+				continue
+			}
+			var campaignIDs []int64
+			if err := json.Unmarshal([]byte(*association.VisibleCampaignIDs), &campaignIDs); err != nil {
+				return nil, fmt.Errorf("failed to parse visible campaign IDs: %w", err)
+			}
+			if !containsInt64(campaignIDs, campaign.CampaignID) {
+				continue
+			}
+		}
+		affiliateOrgID := association.AffiliateOrgID
+		orgAffiliates, err := s.orgAssociationService.GetVisibleAffiliatesForAdvertiser(ctx, campaign.OrganizationID, &affiliateOrgID)
+		if err != nil {
+			return nil, err
+		}
+		affiliates = append(affiliates, orgAffiliates...)
+	}
+	return affiliates, nil
+}
+
+func containsInt64(values []int64, value int64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetJob returns a job of the organization
+func (s *bulkTrackingLinkService) GetJob(ctx context.Context, orgID, jobID int64) (*domain.BulkTrackingLinkJobWithProgress, error) {
+	job, err := s.getJob(ctx, orgID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewBulkTrackingLinkJobWithProgress(job), nil
+}
+
+// ListJobs lists the jobs of the organization
+func (s *bulkTrackingLinkService) ListJobs(ctx context.Context, orgID int64, page, pageSize int) (*domain.BulkTrackingLinkJobListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	jobs, total, err := s.jobRepo.ListJobs(ctx, orgID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.BulkTrackingLinkJobListResponse{
+		Jobs:     make([]*domain.BulkTrackingLinkJobWithProgress, 0, len(jobs)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, job := range jobs {
+		result.Jobs = append(result.Jobs, domain.NewBulkTrackingLinkJobWithProgress(job))
+	}
+	return result, nil
+}
+
+// ListJobItems lists the items of a job of the organization
+func (s *bulkTrackingLinkService) ListJobItems(ctx context.Context, orgID, jobID int64, status string) ([]*domain.BulkTrackingLinkJobItem, error) {
+	switch status {
+	case "", domain.BulkLinkItemStatusPending, domain.BulkLinkItemStatusProcessing, domain.BulkLinkItemStatusSucceeded,
+		domain.BulkLinkItemStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: status must be %s, %s, %s or %s", domain.ErrInvalidInput, domain.BulkLinkItemStatusPending,
+			domain.BulkLinkItemStatusProcessing, domain.BulkLinkItemStatusSucceeded, domain.BulkLinkItemStatusFailed)
+	}
+	if _, err := s.getJob(ctx, orgID, jobID); err != nil {
+		return nil, err
+	}
+	return s.jobRepo.ListJobItems(ctx, jobID, status)
+}
+
+// ExportJob renders one row per affiliate with its link or error
+func (s *bulkTrackingLinkService) ExportJob(ctx context.Context, orgID, jobID int64, format string) (*export.File, error) {
+	switch format {
+	case export.FormatCSV, export.FormatXLSX, export.FormatJSONL:
+	default:
+		return nil, fmt.Errorf("%w: unsupported export format: %s", domain.ErrInvalidInput, format)
+	}
+	items, err := s.ListJobItems(ctx, orgID, jobID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	table := &export.Table{
+		Columns: []string{
+			"affiliate_id", "affiliate_name", "status", "tracking_link_id", "tracking_url", "link_name",
+			"source_id", "sub1", "sub2", "sub3", "sub4", "sub5", "is_new", "error", "processed_at",
+		},
+		Rows: make([][]interface{}, 0, len(items)),
+	}
+	for _, item := range items {
+		var trackingLinkID, isNew interface{}
+		if item.TrackingLinkID != nil {
+			trackingLinkID = *item.TrackingLinkID
+		}
+		if item.IsNew != nil {
+			isNew = fmt.Sprintf("%t", *item.IsNew)
+		}
+		parameters := item.Parameters
+		table.Rows = append(table.Rows, []interface{}{
+			item.AffiliateID, item.AffiliateName, item.Status, trackingLinkID, optionalCell(item.TrackingURL), parameters.Name,
+			optionalCell(parameters.SourceID), optionalCell(parameters.Sub1), optionalCell(parameters.Sub2),
+			optionalCell(parameters.Sub3), optionalCell(parameters.Sub4), optionalCell(parameters.Sub5),
+			isNew, optionalCell(item.ErrorMessage), optionalTimeCell(item.ProcessedAt),
+		})
+	}
+	return export.Render(format, table)
+}
+
+// ResumeJobs starts the running jobs; those leased to another instance are skipped by run
+func (s *bulkTrackingLinkService) ResumeJobs(ctx context.Context) error {
+	jobs, err := s.jobRepo.ListRunningJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		s.start(job)
+	}
+	return nil
+}
+
+// Stop cancels the jobs of this instance and waits for them to hand their remaining items back
+func (s *bulkTrackingLinkService) Stop() {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// start processes a job in the background unless this instance is already processing it or is stopping
+func (s *bulkTrackingLinkService) start(job *domain.BulkTrackingLinkJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return
+	}
+	if _, loaded := s.running.LoadOrStore(job.JobID, true); loaded {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.running.Delete(job.JobID)
+		s.run(s.ctx, job)
+	}()
+}
+
+// run generates the links of the pending items with at most job.Concurrency at once, recording
+// each outcome as it completes so that progress can be followed. The job is only processed while
+// this instance holds its lease; when ctx is cancelled or the lease is lost, the links being
+// generated are finished and the rest is left pending for the next lease holder.
+func (s *bulkTrackingLinkService) run(ctx context.Context, job *domain.BulkTrackingLinkJob) {
+	// Recording outcomes must not be cut short by the cancellation that stops the job
+	work := context.WithoutCancel(ctx)
+
+	acquired, err := s.jobRepo.AcquireJobLease(work, job.JobID, s.instanceID, time.Now().Add(domain.BulkLinkJobLeaseDuration))
+	if err != nil {
+		logger.Error("Failed to lease bulk tracking link job", "job_id", job.JobID, "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	logger.Info("Processing bulk tracking link job", "job_id", job.JobID)
+
+	leaseCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go s.renewLease(leaseCtx, stop, job)
+
+	concurrency := job.Concurrency
+	if concurrency < 1 || concurrency > domain.MaxBulkLinkConcurrency {
+		concurrency = domain.DefaultBulkLinkConcurrency
+	}
+
+	var wg sync.WaitGroup
+	var claimErr error
+	var claimErrOnce sync.Once
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for leaseCtx.Err() == nil {
+				item, err := s.jobRepo.ClaimNextItem(work, job.JobID)
+				if err != nil {
+					claimErrOnce.Do(func() { claimErr = err })
+					stop()
+					return
+				}
+				if item == nil {
+					return
+				}
+				s.processItem(work, job, item)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claimErr == nil && leaseCtx.Err() != nil {
+		// Stopped or taken over; whoever holds the lease next resumes the job
+		if err := s.jobRepo.ReleaseJobLease(work, job.JobID, s.instanceID); err != nil {
+			logger.Error("Failed to release bulk tracking link job", "job_id", job.JobID, "error", err)
+		}
+		logger.Info("Bulk tracking link job interrupted", "job_id", job.JobID)
+		return
+	}
+	s.complete(work, job, claimErr)
+}
+
+// renewLease extends the lease of a job until ctx ends, calling lost when the lease can't be renewed
+func (s *bulkTrackingLinkService) renewLease(ctx context.Context, lost context.CancelFunc, job *domain.BulkTrackingLinkJob) {
+	ticker := time.NewTicker(domain.BulkLinkJobLeaseDuration / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			renewed, err := s.jobRepo.RenewJobLease(context.WithoutCancel(ctx), job.JobID, s.instanceID, time.Now().Add(domain.BulkLinkJobLeaseDuration))
+			if err != nil {
+				// Keep working; the lease only expires if renewals keep failing
+				logger.Warn("Failed to renew bulk tracking link job lease", "job_id", job.JobID, "error", err)
+				continue
+			}
+			if !renewed {
+				logger.Warn("Bulk tracking link job lease lost", "job_id", job.JobID)
+				lost()
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processItem upserts the link of one affiliate and records the outcome
+func (s *bulkTrackingLinkService) processItem(ctx context.Context, job *domain.BulkTrackingLinkJob, item *domain.BulkTrackingLinkJobItem) {
+	parameters := item.Parameters
+	response, err := s.trackingLinkService.UpsertTrackingLink(ctx, &domain.TrackingLinkUpsertRequest{
+		CampaignID:          job.CampaignID,
+		AffiliateID:         item.AffiliateID,
+		Name:                parameters.Name,
+		SourceID:            parameters.SourceID,
+		Sub1:                parameters.Sub1,
+		Sub2:                parameters.Sub2,
+		Sub3:                parameters.Sub3,
+		Sub4:                parameters.Sub4,
+		Sub5:                parameters.Sub5,
+		IsEncryptParameters: job.IsEncryptParameters,
+		IsRedirectLink:      job.IsRedirectLink,
+	})
+
+	now := time.Now()
+	item.ProcessedAt = &now
+	if err != nil {
+		message := err.Error()
+		item.Status = domain.BulkLinkItemStatusFailed
+		item.ErrorMessage = &message
+		logger.Warn("Bulk tracking link item failed", "job_id", job.JobID, "affiliate_id", item.AffiliateID, "error", err)
+	} else {
+		item.Status = domain.BulkLinkItemStatusSucceeded
+		item.TrackingLinkID = &response.TrackingLink.TrackingLinkID
+		item.IsNew = &response.IsNew
+		if response.GeneratedURL != "" {
+			item.TrackingURL = &response.GeneratedURL
+		}
+	}
+
+	if err := s.jobRepo.FinishItem(ctx, item); err != nil {
+		logger.Error("Failed to record bulk tracking link item", "job_id", job.JobID, "item_id", item.ItemID, "error", err)
+	}
+}
+
+// complete records the final status of a job
+func (s *bulkTrackingLinkService) complete(ctx context.Context, job *domain.BulkTrackingLinkJob, runErr error) {
+	job.Status = domain.BulkLinkJobStatusCompleted
+	if runErr != nil {
+		message := runErr.Error()
+		job.Status = domain.BulkLinkJobStatusFailed
+		job.ErrorMessage = &message
+	}
+	if err := s.jobRepo.CompleteJob(ctx, job); err != nil {
+		logger.Error("Failed to complete bulk tracking link job", "job_id", job.JobID, "error", err)
+		return
+	}
+	logger.Info("Bulk tracking link job finished", "job_id", job.JobID, "status", job.Status,
+		"succeeded", job.SucceededCount, "failed", job.FailedCount)
+}
+
+func (s *bulkTrackingLinkService) getJob(ctx context.Context, orgID, jobID int64) (*domain.BulkTrackingLinkJob, error) {
+	job, err := s.jobRepo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	return job, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/affiliate-backend/internal/domain"
@@ -26,14 +27,16 @@ type campaignService struct {
 	campaignRepo               repository.CampaignRepository
 	campaignProviderMappingRepo repository.CampaignProviderMappingRepository
 	integrationService         provider.IntegrationService
+	targetingRepo              repository.CampaignTargetingRepository
 }
 
 // NewCampaignService creates a new campaign service
-func NewCampaignService(campaignRepo repository.CampaignRepository, campaignProviderMappingRepo repository.CampaignProviderMappingRepository, integrationService provider.IntegrationService) CampaignService {
+func NewCampaignService(campaignRepo repository.CampaignRepository, campaignProviderMappingRepo repository.CampaignProviderMappingRepository, integrationService provider.IntegrationService, targetingRepo repository.CampaignTargetingRepository) CampaignService {
 	return &campaignService{
 		campaignRepo:               campaignRepo,
 		campaignProviderMappingRepo: campaignProviderMappingRepo,
 		integrationService:         integrationService,
+		targetingRepo:              targetingRepo,
 	}
 }
 
@@ -102,9 +105,18 @@ func (s *campaignService) UpdateCampaign(ctx context.Context, campaign *domain.C
 	}
 	logger.Info("Successfully updated campaign in repository", "campaign_id", campaign.CampaignID)
 
-	// Step 2: Call IntegrationService to update campaign in provider (Everflow)
+	// Step 2: Call IntegrationService to update campaign in provider (Everflow), with the
+	// targeting ruleset so that the update does not clear it
+	targeting, err := s.targetingRepo.GetTargeting(ctx, campaign.CampaignID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		logger.Warn("Failed to load campaign targeting, skipping provider update", "campaign_id", campaign.CampaignID, "error", err)
+		return nil
+	}
+	if targeting != nil {
+		campaign.Targeting = &targeting.Rules
+	}
 	logger.Debug("Calling integration service to update campaign in provider", "campaign_id", campaign.CampaignID)
-	err := s.integrationService.UpdateCampaign(ctx, *campaign)
+	err = s.integrationService.UpdateCampaign(ctx, *campaign)
 	if err != nil {
 		// Log error but don't fail the operation since local update succeeded
 		logger.Warn("Failed to update campaign in provider", "campaign_id", campaign.CampaignID, "error", err)
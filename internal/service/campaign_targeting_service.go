@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/provider"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// GeoLocator locates IP addresses; *geoip.Reader satisfies it
+type GeoLocator interface {
+	Lookup(ip net.IP) (domain.GeoLocation, error)
+}
+
+// CampaignTargetingService defines the interface for campaign targeting ruleset operations
+type CampaignTargetingService interface {
+	GetTargeting(ctx context.Context, orgID, campaignID int64) (*domain.CampaignTargeting, error)
+	// SetTargeting replaces the ruleset of a campaign and syncs it to the provider
+	SetTargeting(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignTargetingRequest) (*domain.CampaignTargeting, error)
+	// DeleteTargeting removes the ruleset of a campaign and clears it on the provider
+	DeleteTargeting(ctx context.Context, orgID, campaignID int64) error
+
+	// EvaluateClick evaluates a click on a campaign against its ruleset, locating the visitor
+	// first; it returns nil when the campaign has no ruleset
+	EvaluateClick(ctx context.Context, campaignID int64, visitor domain.TargetingVisitor) (*domain.TargetingDecision, error)
+}
+
+// campaignTargetingService implements CampaignTargetingService
+type campaignTargetingService struct {
+	targetingRepo      repository.CampaignTargetingRepository
+	campaignRepo       repository.CampaignRepository
+	integrationService provider.IntegrationService
+	geoLocator         GeoLocator
+}
+
+// NewCampaignTargetingService creates a new campaign targeting service. Without a geoLocator,
+// visitors are only located by the country the handler passes in.
+func NewCampaignTargetingService(
+	targetingRepo repository.CampaignTargetingRepository,
+	campaignRepo repository.CampaignRepository,
+	integrationService provider.IntegrationService,
+	geoLocator GeoLocator,
+) CampaignTargetingService {
+	return &campaignTargetingService{
+		targetingRepo:      targetingRepo,
+		campaignRepo:       campaignRepo,
+		integrationService: integrationService,
+		geoLocator:         geoLocator,
+	}
+}
+
+// GetTargeting returns the ruleset of a campaign of the organization
+func (s *campaignTargetingService) GetTargeting(ctx context.Context, orgID, campaignID int64) (*domain.CampaignTargeting, error) {
+	if _, err := s.getCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+	return s.targetingRepo.GetTargeting(ctx, campaignID)
+}
+
+// SetTargeting replaces the ruleset of a campaign of the organization
+func (s *campaignTargetingService) SetTargeting(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignTargetingRequest) (*domain.CampaignTargeting, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	campaign, err := s.getCampaign(ctx, orgID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	targeting := &domain.CampaignTargeting{
+		CampaignID:  campaignID,
+		Rules:       req.Rules,
+		FallbackURL: req.FallbackURL,
+	}
+	if err := s.targetingRepo.UpsertTargeting(ctx, targeting); err != nil {
+		return nil, err
+	}
+
+	campaign.Targeting = &targeting.Rules
+	s.syncToProvider(ctx, campaign)
+	return targeting, nil
+}
+
+// DeleteTargeting removes the ruleset of a campaign of the organization
+func (s *campaignTargetingService) DeleteTargeting(ctx context.Context, orgID, campaignID int64) error {
+	campaign, err := s.getCampaign(ctx, orgID, campaignID)
+	if err != nil {
+		return err
+	}
+	if err := s.targetingRepo.DeleteTargeting(ctx, campaignID); err != nil {
+		return err
+	}
+
+	campaign.Targeting = nil
+	s.syncToProvider(ctx, campaign)
+	return nil
+}
+
+// EvaluateClick locates the visitor with the GeoIP database, keeping attributes the caller
+// already knows (such as a CDN country header), and evaluates the campaign ruleset
+func (s *campaignTargetingService) EvaluateClick(ctx context.Context, campaignID int64, visitor domain.TargetingVisitor) (*domain.TargetingDecision, error) {
+	targeting, err := s.targetingRepo.GetTargeting(ctx, campaignID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if s.geoLocator != nil && visitor.IP != nil {
+		location, err := s.geoLocator.Lookup(visitor.IP)
+		if err != nil {
+			logger.Warn("Failed to locate visitor", "ip", visitor.IP.String(), "error", err)
+		} else {
+			if visitor.Location.Country == "" {
+				visitor.Location.Country = location.Country
+			}
+			if visitor.Location.Region == "" {
+				visitor.Location.Region = location.Region
+			}
+			if visitor.Location.ISP == "" {
+				visitor.Location.ISP = location.ISP
+			}
+			visitor.Location.IsProxy = visitor.Location.IsProxy || location.IsProxy
+		}
+	}
+
+	failedRule := targeting.Rules.Evaluate(visitor)
+	return &domain.TargetingDecision{
+		Matched:     failedRule == "",
+		FailedRule:  failedRule,
+		FallbackURL: targeting.FallbackURL,
+	}, nil
+}
+
+// syncToProvider pushes the campaign with its ruleset to the provider. Failures are logged
+// only, as for campaign updates, since the local ruleset is saved.
+func (s *campaignTargetingService) syncToProvider(ctx context.Context, campaign *domain.Campaign) {
+	if err := s.integrationService.UpdateCampaign(ctx, *campaign); err != nil {
+		logger.Warn("Failed to sync campaign targeting to provider", "campaign_id", campaign.CampaignID, "error", err)
+	}
+}
+
+func (s *campaignTargetingService) getCampaign(ctx context.Context, orgID, campaignID int64) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	return campaign, nil
+}
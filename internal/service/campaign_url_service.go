@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// CampaignURLService defines the interface for destination URL macros and passthrough parameters
+type CampaignURLService interface {
+	// GetURLSettings returns the passthrough parameter whitelist of a campaign, empty when none
+	// was set
+	GetURLSettings(ctx context.Context, orgID, campaignID int64) (*domain.CampaignURLSettings, error)
+	// SetURLSettings replaces the passthrough parameter whitelist of a campaign
+	SetURLSettings(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignURLSettingsRequest) (*domain.CampaignURLSettings, error)
+	// PreviewURL validates the destination URL of a campaign and expands it for a sample click
+	PreviewURL(ctx context.Context, orgID, campaignID int64, req *domain.URLPreviewRequest) (*domain.URLPreviewResponse, error)
+
+	// BuildDestinationURL expands the destination URL of a campaign for a click on a tracking
+	// link, generating the click ID when the click has none
+	BuildDestinationURL(ctx context.Context, campaign *domain.Campaign, click domain.URLMacroClick) (string, error)
+	// BuildProviderURL forwards the sub ID overrides and passthrough parameters of a click onto
+	// the tracking URL a provider generated for the link
+	BuildProviderURL(ctx context.Context, campaign *domain.Campaign, providerURL string, click domain.URLMacroClick) (string, error)
+}
+
+// campaignURLService implements CampaignURLService
+type campaignURLService struct {
+	urlSettingsRepo  repository.CampaignURLSettingsRepository
+	campaignRepo     repository.CampaignRepository
+	trackingLinkRepo repository.TrackingLinkRepository
+}
+
+// NewCampaignURLService creates a new campaign URL service
+func NewCampaignURLService(
+	urlSettingsRepo repository.CampaignURLSettingsRepository,
+	campaignRepo repository.CampaignRepository,
+	trackingLinkRepo repository.TrackingLinkRepository,
+) CampaignURLService {
+	return &campaignURLService{
+		urlSettingsRepo:  urlSettingsRepo,
+		campaignRepo:     campaignRepo,
+		trackingLinkRepo: trackingLinkRepo,
+	}
+}
+
+// GetURLSettings returns the URL settings of a campaign of the organization
+func (s *campaignURLService) GetURLSettings(ctx context.Context, orgID, campaignID int64) (*domain.CampaignURLSettings, error) {
+	if _, err := s.getCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+	return s.loadSettings(ctx, campaignID)
+}
+
+// SetURLSettings replaces the URL settings of a campaign of the organization
+func (s *campaignURLService) SetURLSettings(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignURLSettingsRequest) (*domain.CampaignURLSettings, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.getCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+
+	settings := &domain.CampaignURLSettings{
+		CampaignID:            campaignID,
+		PassthroughParameters: req.PassthroughParameters,
+	}
+	if err := s.urlSettingsRepo.UpsertURLSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// PreviewURL expands the destination URL for a sample click. Problems with the template are
+// reported in the response rather than as errors, so that drafts can be checked.
+func (s *campaignURLService) PreviewURL(ctx context.Context, orgID, campaignID int64, req *domain.URLPreviewRequest) (*domain.URLPreviewResponse, error) {
+	campaign, err := s.getCampaign(ctx, orgID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := ""
+	if req.DestinationURL != nil {
+		template = *req.DestinationURL
+	} else if campaign.DestinationURL != nil {
+		template = *campaign.DestinationURL
+	}
+	if template == "" {
+		return nil, fmt.Errorf("%w: the campaign has no destination URL", domain.ErrInvalidInput)
+	}
+
+	trackingLink := &domain.TrackingLink{CampaignID: campaignID}
+	if req.TrackingLinkID != nil {
+		trackingLink, err = s.trackingLinkRepo.GetTrackingLinkByID(ctx, *req.TrackingLinkID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: tracking link %d not found", domain.ErrInvalidInput, *req.TrackingLinkID)
+			}
+			return nil, err
+		}
+		if trackingLink.OrganizationID != orgID || trackingLink.CampaignID != campaignID {
+			return nil, fmt.Errorf("%w: tracking link %d does not belong to the campaign", domain.ErrInvalidInput, *req.TrackingLinkID)
+		}
+	}
+
+	settings, err := s.loadSettings(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := make(url.Values, len(req.Query))
+	for name, value := range req.Query {
+		query.Set(name, value)
+	}
+	clickID, err := generateClickID()
+	if err != nil {
+		return nil, err
+	}
+	click := domain.URLMacroClick{
+		ClickID:      clickID,
+		TrackingLink: trackingLink,
+		Query:        query,
+		Country:      req.Country,
+		IP:           req.IP,
+		Time:         time.Now().UTC(),
+	}
+
+	macros, problems := settings.ValidateDestinationTemplate(template)
+	if macros == nil {
+		macros = []string{}
+	}
+	return &domain.URLPreviewResponse{
+		Template:          template,
+		URL:               settings.BuildDestinationURL(template, click),
+		Valid:             len(problems) == 0,
+		Problems:          problems,
+		Macros:            macros,
+		DroppedParameters: settings.DroppedClickParameters(query),
+	}, nil
+}
+
+// BuildDestinationURL expands the destination URL of a campaign for a click
+func (s *campaignURLService) BuildDestinationURL(ctx context.Context, campaign *domain.Campaign, click domain.URLMacroClick) (string, error) {
+	if campaign.DestinationURL == nil || *campaign.DestinationURL == "" {
+		return "", fmt.Errorf("campaign %d has no destination URL: %w", campaign.CampaignID, domain.ErrNotFound)
+	}
+	settings, err := s.loadSettings(ctx, campaign.CampaignID)
+	if err != nil {
+		return "", err
+	}
+	if click.ClickID == "" {
+		if click.ClickID, err = generateClickID(); err != nil {
+			return "", err
+		}
+	}
+	return settings.BuildDestinationURL(*campaign.DestinationURL, click), nil
+}
+
+// BuildProviderURL forwards the click parameters of a campaign onto a provider tracking URL
+func (s *campaignURLService) BuildProviderURL(ctx context.Context, campaign *domain.Campaign, providerURL string, click domain.URLMacroClick) (string, error) {
+	settings, err := s.loadSettings(ctx, campaign.CampaignID)
+	if err != nil {
+		return "", err
+	}
+	return settings.BuildProviderURL(providerURL, click), nil
+}
+
+// loadSettings returns the URL settings of a campaign, empty when none were saved
+func (s *campaignURLService) loadSettings(ctx context.Context, campaignID int64) (*domain.CampaignURLSettings, error) {
+	settings, err := s.urlSettingsRepo.GetURLSettings(ctx, campaignID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return &domain.CampaignURLSettings{CampaignID: campaignID, PassthroughParameters: []domain.PassthroughParameter{}}, nil
+	}
+	return settings, err
+}
+
+func (s *campaignURLService) getCampaign(ctx context.Context, orgID, campaignID int64) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	return campaign, nil
+}
+
+// generateClickID generates a random click ID for the {click_id} macro
+func generateClickID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("error generating random bytes: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
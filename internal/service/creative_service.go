@@ -0,0 +1,419 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/provider"
+	"github.com/affiliate-backend/internal/platform/storage"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// CreativeService defines the interface for campaign creative operations
+type CreativeService interface {
+	// CreateCreative stores a creative of a campaign of the organization; it starts pending review
+	CreateCreative(ctx context.Context, orgID, campaignID int64, req *domain.CreateCreativeRequest) (*domain.Creative, error)
+	// ListCreatives lists the creatives of a campaign, optionally with a given status
+	ListCreatives(ctx context.Context, orgID, campaignID int64, status string) ([]*domain.Creative, error)
+	GetCreative(ctx context.Context, orgID, campaignID, creativeID int64) (*domain.Creative, error)
+	// UpdateCreative updates a creative; content changes send it back to review
+	UpdateCreative(ctx context.Context, orgID, campaignID, creativeID int64, req *domain.UpdateCreativeRequest) (*domain.Creative, error)
+	// ReviewCreative approves or rejects a creative
+	ReviewCreative(ctx context.Context, orgID, campaignID, creativeID int64, req *domain.ReviewCreativeRequest) (*domain.Creative, error)
+	DeleteCreative(ctx context.Context, orgID, campaignID, creativeID int64) error
+
+	// GetCreativeFile returns the image of an approved image creative
+	GetCreativeFile(ctx context.Context, creativeID int64) (*domain.Creative, []byte, error)
+	// ListWrappedCreatives returns the approved creatives of a tracking link's campaign wrapped
+	// with the tracking link
+	ListWrappedCreatives(ctx context.Context, orgID, trackingLinkID int64) ([]*domain.WrappedCreative, error)
+}
+
+// creativeService implements CreativeService
+type creativeService struct {
+	creativeRepo          repository.CreativeRepository
+	campaignRepo          repository.CampaignRepository
+	trackingLinkRepo      repository.TrackingLinkRepository
+	campaignProviderRepo  repository.CampaignProviderMappingRepository
+	affiliateProviderRepo repository.AffiliateProviderMappingRepository
+	integrationService    provider.IntegrationService
+	providerCreatives     provider.CreativeService
+	fileStore             storage.ObjectStore
+	apiBaseURL            string
+}
+
+// NewCreativeService creates a new creative service. Creative images are kept in fileStore and
+// served under apiBaseURL; creatives are synced to the provider with providerCreatives.
+func NewCreativeService(
+	creativeRepo repository.CreativeRepository,
+	campaignRepo repository.CampaignRepository,
+	trackingLinkRepo repository.TrackingLinkRepository,
+	campaignProviderRepo repository.CampaignProviderMappingRepository,
+	affiliateProviderRepo repository.AffiliateProviderMappingRepository,
+	integrationService provider.IntegrationService,
+	providerCreatives provider.CreativeService,
+	fileStore storage.ObjectStore,
+	apiBaseURL string,
+) CreativeService {
+	return &creativeService{
+		creativeRepo:          creativeRepo,
+		campaignRepo:          campaignRepo,
+		trackingLinkRepo:      trackingLinkRepo,
+		campaignProviderRepo:  campaignProviderRepo,
+		affiliateProviderRepo: affiliateProviderRepo,
+		integrationService:    integrationService,
+		providerCreatives:     providerCreatives,
+		fileStore:             fileStore,
+		apiBaseURL:            strings.TrimSuffix(apiBaseURL, "/"),
+	}
+}
+
+// CreateCreative validates and stores a creative, reading the dimensions of images, and syncs it
+// to the provider
+func (s *creativeService) CreateCreative(ctx context.Context, orgID, campaignID int64, req *domain.CreateCreativeRequest) (*domain.Creative, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if err := s.checkCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+
+	creative := &domain.Creative{
+		OrganizationID: orgID,
+		CampaignID:     campaignID,
+		Name:           req.Name,
+		CreativeType:   req.CreativeType,
+		Status:         domain.CreativeStatusPending,
+		IsPrivate:      req.IsPrivate,
+		Width:          req.Width,
+		Height:         req.Height,
+		HTMLCode:       req.HTMLCode,
+		EmailFrom:      req.EmailFrom,
+		EmailSubject:   req.EmailSubject,
+	}
+
+	var file []byte
+	if req.CreativeType == domain.CreativeTypeImage {
+		if s.fileStore == nil {
+			return nil, fmt.Errorf("creative storage is not configured")
+		}
+		file = req.File.Data
+		config, format, err := image.DecodeConfig(bytes.NewReader(file))
+		if err != nil {
+			return nil, fmt.Errorf("%w: the file must be a PNG, JPEG or GIF image", domain.ErrInvalidInput)
+		}
+		if config.Width > domain.MaxCreativeDimension || config.Height > domain.MaxCreativeDimension {
+			return nil, fmt.Errorf("%w: the image must be at most %dx%d pixels", domain.ErrInvalidInput,
+				domain.MaxCreativeDimension, domain.MaxCreativeDimension)
+		}
+
+		format = strings.ToLower(format)
+		fileName := strings.TrimSpace(req.File.FileName)
+		if fileName == "" {
+			fileName = "creative." + format
+		}
+		contentType := "image/" + format
+		storageKey := fmt.Sprintf("creatives/%d/%s.%s", campaignID, randomHex(8), format)
+		size := len(file)
+		creative.Width, creative.Height = &config.Width, &config.Height
+		creative.FileName, creative.ContentType, creative.FileSize = &fileName, &contentType, &size
+		creative.StorageKey = &storageKey
+
+		if err := s.fileStore.Put(ctx, storageKey, contentType, file); err != nil {
+			return nil, fmt.Errorf("failed to store creative file: %w", err)
+		}
+	}
+
+	if err := s.creativeRepo.CreateCreative(ctx, creative); err != nil {
+		return nil, err
+	}
+	logger.Info("Creative created", "creative_id", creative.CreativeID, "campaign_id", campaignID, "creative_type", creative.CreativeType)
+
+	s.syncToProvider(ctx, creative, file)
+	s.setFileURL(creative)
+	return creative, nil
+}
+
+// ListCreatives lists the creatives of a campaign of the organization
+func (s *creativeService) ListCreatives(ctx context.Context, orgID, campaignID int64, status string) ([]*domain.Creative, error) {
+	switch status {
+	case "", domain.CreativeStatusPending, domain.CreativeStatusApproved, domain.CreativeStatusRejected:
+	default:
+		return nil, fmt.Errorf("%w: status must be %s, %s or %s", domain.ErrInvalidInput,
+			domain.CreativeStatusPending, domain.CreativeStatusApproved, domain.CreativeStatusRejected)
+	}
+	if err := s.checkCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+
+	creatives, err := s.creativeRepo.ListCreatives(ctx, campaignID, status)
+	if err != nil {
+		return nil, err
+	}
+	for _, creative := range creatives {
+		s.setFileURL(creative)
+	}
+	return creatives, nil
+}
+
+// GetCreative returns a creative of a campaign of the organization
+func (s *creativeService) GetCreative(ctx context.Context, orgID, campaignID, creativeID int64) (*domain.Creative, error) {
+	creative, err := s.getCreative(ctx, orgID, campaignID, creativeID)
+	if err != nil {
+		return nil, err
+	}
+	s.setFileURL(creative)
+	return creative, nil
+}
+
+// UpdateCreative applies an update and syncs the creative to the provider. Approved creatives
+// whose content changed go back to pending, so affiliates keep only reviewed content.
+func (s *creativeService) UpdateCreative(ctx context.Context, orgID, campaignID, creativeID int64, req *domain.UpdateCreativeRequest) (*domain.Creative, error) {
+	creative, err := s.getCreative(ctx, orgID, campaignID, creativeID)
+	if err != nil {
+		return nil, err
+	}
+	contentChanged, err := req.Apply(creative)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if contentChanged && creative.Status != domain.CreativeStatusPending {
+		creative.Status = domain.CreativeStatusPending
+		creative.RejectionReason = nil
+		creative.ReviewedAt = nil
+	}
+
+	if err := s.creativeRepo.UpdateCreative(ctx, creative); err != nil {
+		return nil, err
+	}
+	s.syncToProvider(ctx, creative, nil)
+	s.setFileURL(creative)
+	return creative, nil
+}
+
+// ReviewCreative approves or rejects a creative and syncs its status to the provider
+func (s *creativeService) ReviewCreative(ctx context.Context, orgID, campaignID, creativeID int64, req *domain.ReviewCreativeRequest) (*domain.Creative, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	creative, err := s.getCreative(ctx, orgID, campaignID, creativeID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	creative.Status = req.Status
+	creative.RejectionReason = req.Reason
+	creative.ReviewedAt = &now
+	if err := s.creativeRepo.UpdateCreative(ctx, creative); err != nil {
+		return nil, err
+	}
+	logger.Info("Creative reviewed", "creative_id", creative.CreativeID, "status", creative.Status)
+
+	s.syncToProvider(ctx, creative, nil)
+	s.setFileURL(creative)
+	return creative, nil
+}
+
+// DeleteCreative deletes a creative. The stored image is left in place, as object storage has
+// no delete; its key is not reused. The provider creative is left for the provider to manage.
+func (s *creativeService) DeleteCreative(ctx context.Context, orgID, campaignID, creativeID int64) error {
+	if _, err := s.getCreative(ctx, orgID, campaignID, creativeID); err != nil {
+		return err
+	}
+	return s.creativeRepo.DeleteCreative(ctx, creativeID)
+}
+
+// GetCreativeFile returns the image of an approved image creative. Other creatives are reported
+// as not found, since the file URL is public.
+func (s *creativeService) GetCreativeFile(ctx context.Context, creativeID int64) (*domain.Creative, []byte, error) {
+	creative, err := s.creativeRepo.GetCreativeByID(ctx, creativeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !creative.IsApproved() || creative.StorageKey == nil || s.fileStore == nil {
+		return nil, nil, domain.ErrNotFound
+	}
+	data, err := s.fileStore.Get(ctx, *creative.StorageKey)
+	if err != nil {
+		if err == storage.ErrObjectNotFound {
+			return nil, nil, domain.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load creative file: %w", err)
+	}
+	return creative, data, nil
+}
+
+// ListWrappedCreatives wraps the approved creatives of a tracking link's campaign. Creatives
+// synced to the provider get a tracking URL generated with their provider creative ID, so the
+// provider attributes clicks to them; otherwise the tracking link URL is used.
+func (s *creativeService) ListWrappedCreatives(ctx context.Context, orgID, trackingLinkID int64) ([]*domain.WrappedCreative, error) {
+	trackingLink, err := s.trackingLinkRepo.GetTrackingLinkByID(ctx, trackingLinkID)
+	if err != nil {
+		return nil, err
+	}
+	if trackingLink.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	if trackingLink.TrackingURL == nil || *trackingLink.TrackingURL == "" {
+		return nil, fmt.Errorf("%w: the tracking link has no tracking URL yet", domain.ErrInvalidInput)
+	}
+
+	creatives, err := s.creativeRepo.ListCreatives(ctx, trackingLink.CampaignID, domain.CreativeStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	var campaignMapping *domain.CampaignProviderMapping
+	var affiliateMapping *domain.AffiliateProviderMapping
+	if s.integrationService != nil {
+		campaignMapping, _ = s.campaignProviderRepo.GetCampaignProviderMapping(ctx, trackingLink.CampaignID, "everflow")
+		affiliateMapping, _ = s.affiliateProviderRepo.GetAffiliateProviderMapping(ctx, trackingLink.AffiliateID, "everflow")
+	}
+
+	wrapped := make([]*domain.WrappedCreative, 0, len(creatives))
+	for _, creative := range creatives {
+		trackingURL := *trackingLink.TrackingURL
+		if campaignMapping != nil && affiliateMapping != nil && creative.ProviderCreativeID != nil {
+			if url, err := s.creativeTrackingURL(ctx, trackingLink, creative, campaignMapping, affiliateMapping); err != nil {
+				logger.Warn("Failed to generate creative tracking link, using the tracking link URL",
+					"tracking_link_id", trackingLinkID, "creative_id", creative.CreativeID, "error", err)
+			} else {
+				trackingURL = url
+			}
+		}
+
+		s.setFileURL(creative)
+		var fileURL string
+		if creative.FileURL != nil {
+			fileURL = *creative.FileURL
+		}
+		wrapped = append(wrapped, &domain.WrappedCreative{
+			CreativeID:     creative.CreativeID,
+			TrackingLinkID: trackingLinkID,
+			Name:           creative.Name,
+			CreativeType:   creative.CreativeType,
+			Width:          creative.Width,
+			Height:         creative.Height,
+			EmailFrom:      creative.EmailFrom,
+			EmailSubject:   creative.EmailSubject,
+			FileURL:        creative.FileURL,
+			TrackingURL:    trackingURL,
+			Code:           creative.Wrap(trackingURL, fileURL),
+		})
+	}
+	return wrapped, nil
+}
+
+// creativeTrackingURL generates the provider tracking URL of a tracking link for a creative
+func (s *creativeService) creativeTrackingURL(ctx context.Context, trackingLink *domain.TrackingLink, creative *domain.Creative,
+	campaignMapping *domain.CampaignProviderMapping, affiliateMapping *domain.AffiliateProviderMapping) (string, error) {
+	creativeID, err := strconv.ParseInt(*creative.ProviderCreativeID, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid provider creative ID %q", *creative.ProviderCreativeID)
+	}
+	providerCreativeID := int32(creativeID)
+
+	response, err := s.integrationService.GenerateTrackingLink(ctx, &domain.TrackingLinkGenerationRequest{
+		CampaignID:          trackingLink.CampaignID,
+		AffiliateID:         trackingLink.AffiliateID,
+		Name:                trackingLink.Name,
+		SourceID:            trackingLink.SourceID,
+		Sub1:                trackingLink.Sub1,
+		Sub2:                trackingLink.Sub2,
+		Sub3:                trackingLink.Sub3,
+		Sub4:                trackingLink.Sub4,
+		Sub5:                trackingLink.Sub5,
+		IsEncryptParameters: trackingLink.IsEncryptParameters,
+		IsRedirectLink:      trackingLink.IsRedirectLink,
+		CreativeID:          &providerCreativeID,
+	}, campaignMapping, affiliateMapping)
+	if err != nil {
+		return "", err
+	}
+	if response.GeneratedURL == "" {
+		return "", fmt.Errorf("the provider returned no tracking URL")
+	}
+	return response.GeneratedURL, nil
+}
+
+// syncToProvider creates or updates the provider creative and records the outcome. Failures
+// are recorded on the creative and logged only, since the local creative is saved; the next
+// update or review retries. Images are loaded from storage when the provider has no copy yet.
+func (s *creativeService) syncToProvider(ctx context.Context, creative *domain.Creative, file []byte) {
+	if s.providerCreatives == nil {
+		return
+	}
+
+	recordError := func(err error) {
+		logger.Warn("Failed to sync creative to provider", "creative_id", creative.CreativeID, "error", err)
+		message := err.Error()
+		creative.ProviderSyncError = &message
+		if err := s.creativeRepo.UpdateProviderSync(ctx, creative.CreativeID, nil, nil, &message); err != nil {
+			logger.Error("Failed to record creative sync error", "creative_id", creative.CreativeID, "error", err)
+		}
+	}
+
+	mapping, err := s.campaignProviderRepo.GetCampaignProviderMapping(ctx, creative.CampaignID, s.providerCreatives.ProviderType())
+	if err != nil {
+		recordError(fmt.Errorf("failed to get campaign provider mapping: %w", err))
+		return
+	}
+	if file == nil && creative.ProviderCreativeID == nil && creative.StorageKey != nil && s.fileStore != nil {
+		if file, err = s.fileStore.Get(ctx, *creative.StorageKey); err != nil {
+			recordError(fmt.Errorf("failed to load creative file: %w", err))
+			return
+		}
+	}
+
+	providerCreativeID, err := s.providerCreatives.SyncCreative(ctx, creative, file, mapping)
+	if err != nil {
+		recordError(err)
+		return
+	}
+	now := time.Now()
+	creative.ProviderCreativeID = &providerCreativeID
+	creative.ProviderSyncedAt = &now
+	creative.ProviderSyncError = nil
+	if err := s.creativeRepo.UpdateProviderSync(ctx, creative.CreativeID, &providerCreativeID, &now, nil); err != nil {
+		logger.Error("Failed to record creative sync", "creative_id", creative.CreativeID, "error", err)
+	}
+}
+
+// setFileURL fills in the public URL of image creatives
+func (s *creativeService) setFileURL(creative *domain.Creative) {
+	if creative.StorageKey == nil {
+		return
+	}
+	url := fmt.Sprintf("%s/api/v1/public/creatives/%d/file", s.apiBaseURL, creative.CreativeID)
+	creative.FileURL = &url
+}
+
+func (s *creativeService) getCreative(ctx context.Context, orgID, campaignID, creativeID int64) (*domain.Creative, error) {
+	creative, err := s.creativeRepo.GetCreativeByID(ctx, creativeID)
+	if err != nil {
+		return nil, err
+	}
+	if creative.OrganizationID != orgID || creative.CampaignID != campaignID {
+		return nil, domain.ErrNotFound
+	}
+	return creative, nil
+}
+
+func (s *creativeService) checkCampaign(ctx context.Context, orgID, campaignID int64) error {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.OrganizationID != orgID {
+		return domain.ErrNotFound
+	}
+	return nil
+}
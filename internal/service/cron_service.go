@@ -4,19 +4,38 @@ import (
 	"context"
 	"time"
 
+	"github.com/affiliate-backend/internal/domain"
 	"github.com/affiliate-backend/internal/platform/logger"
 )
 
 // CronService handles scheduled tasks
 type CronService struct {
 	usageCalculationService *UsageCalculationService
+	providerStatsService    ProviderStatsService
+	scheduledReportService  ScheduledReportService
+	pipelineService         PublisherPipelineService
+	outreachService         OutreachSequenceService
+	trackingDomainService   TrackingDomainService
+	linkHealthService       LinkHealthService
+	trackingLinkService     TrackingLinkService
+	conversionService       PublisherConversionService
+	bulkTrackingLinkService BulkTrackingLinkService
 	stopChan                chan bool
 }
 
 // NewCronService creates a new cron service
-func NewCronService(usageCalculationService *UsageCalculationService) *CronService {
+func NewCronService(usageCalculationService *UsageCalculationService, providerStatsService ProviderStatsService, scheduledReportService ScheduledReportService, pipelineService PublisherPipelineService, outreachService OutreachSequenceService, trackingDomainService TrackingDomainService, linkHealthService LinkHealthService, trackingLinkService TrackingLinkService, conversionService PublisherConversionService, bulkTrackingLinkService BulkTrackingLinkService) *CronService {
 	return &CronService{
 		usageCalculationService: usageCalculationService,
+		providerStatsService:    providerStatsService,
+		scheduledReportService:  scheduledReportService,
+		pipelineService:         pipelineService,
+		outreachService:         outreachService,
+		trackingDomainService:   trackingDomainService,
+		linkHealthService:       linkHealthService,
+		trackingLinkService:     trackingLinkService,
+		conversionService:       conversionService,
+		bulkTrackingLinkService: bulkTrackingLinkService,
 		stopChan:                make(chan bool),
 	}
 }
@@ -28,6 +47,51 @@ func (s *CronService) Start() {
 	// Start daily usage calculation job
 	go s.runDailyUsageCalculation()
 
+	// Start daily provider stats import job
+	if s.providerStatsService != nil {
+		go s.runDailyProviderStatsImport()
+	}
+
+	// Start scheduled report job
+	if s.scheduledReportService != nil {
+		go s.runScheduledReports()
+	}
+
+	// Start pipeline reminder job
+	if s.pipelineService != nil {
+		go s.runPipelineReminders()
+	}
+
+	// Start outreach sequence job
+	if s.outreachService != nil {
+		go s.runOutreachSequences()
+	}
+
+	// Start tracking domain recheck job
+	if s.trackingDomainService != nil {
+		go s.runTrackingDomainRechecks()
+	}
+
+	// Start campaign link health job
+	if s.linkHealthService != nil {
+		go s.runLinkHealthChecks()
+	}
+
+	// Start tracking link lifecycle job
+	if s.trackingLinkService != nil {
+		go s.runTrackingLinkLifecycle()
+	}
+
+	// Start publisher conversion progress job
+	if s.conversionService != nil {
+		go s.runPublisherConversionProgress()
+	}
+
+	// Start bulk tracking link job takeover
+	if s.bulkTrackingLinkService != nil {
+		go s.runBulkTrackingLinkJobs()
+	}
+
 	logger.Info("Cron service started")
 }
 
@@ -77,6 +141,241 @@ func (s *CronService) runDailyUsageCalculation() {
 	}
 }
 
+// runDailyProviderStatsImport imports the provider stats of the last few days into the local
+// warehouse. Imports replace the stored day, so days imported before are refreshed.
+func (s *CronService) runDailyProviderStatsImport() {
+	// Provider reports are keyed by UTC day, so run shortly after UTC midnight
+	// to give the provider time to settle late clicks and conversions
+	now := time.Now().UTC()
+	nextRun := time.Date(now.Year(), now.Month(), now.Day()+1, 1, 0, 0, 0, time.UTC)
+
+	timer := time.NewTimer(time.Until(nextRun))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			today := time.Now().UTC()
+			for daysAgo := domain.ProviderStatsScheduledImportDays; daysAgo >= 1; daysAgo-- {
+				day := today.AddDate(0, 0, -daysAgo)
+
+				logger.Info("Running daily provider stats import", "date", day.Format("2006-01-02"))
+
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+				_, err := s.providerStatsService.ImportDay(ctx, day, domain.ProviderStatsImportTriggerScheduled)
+				cancel()
+
+				if err != nil {
+					logger.Error("Error in daily provider stats import", "date", day.Format("2006-01-02"), "error", err)
+				} else {
+					logger.Info("Daily provider stats import completed successfully", "date", day.Format("2006-01-02"))
+				}
+			}
+
+			timer.Reset(24 * time.Hour)
+
+		case <-s.stopChan:
+			logger.Info("Daily provider stats import job stopped")
+			return
+		}
+	}
+}
+
+// runScheduledReports checks for due scheduled reports every minute
+func (s *CronService) runScheduledReports() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			ran, err := s.scheduledReportService.RunDueReports(ctx, time.Now())
+			cancel()
+
+			if err != nil {
+				logger.Error("Error running scheduled reports", "error", err)
+			} else if ran > 0 {
+				logger.Info("Scheduled reports completed", "count", ran)
+			}
+
+		case <-s.stopChan:
+			logger.Info("Scheduled report job stopped")
+			return
+		}
+	}
+}
+
+// runPipelineReminders sends due favorite list follow-up reminders every minute
+func (s *CronService) runPipelineReminders() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			sent, err := s.pipelineService.RunDueReminders(ctx, time.Now())
+			cancel()
+
+			if err != nil {
+				logger.Error("Error sending pipeline reminders", "error", err)
+			} else if sent > 0 {
+				logger.Info("Pipeline reminders sent", "count", sent)
+			}
+
+		case <-s.stopChan:
+			logger.Info("Pipeline reminder job stopped")
+			return
+		}
+	}
+}
+
+// runOutreachSequences sends due outreach sequence steps every minute
+func (s *CronService) runOutreachSequences() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			sent, err := s.outreachService.RunDueSends(ctx, time.Now())
+			cancel()
+
+			if err != nil {
+				logger.Error("Error sending outreach sequence steps", "error", err)
+			} else if sent > 0 {
+				logger.Info("Outreach sequence steps sent", "count", sent)
+			}
+
+		case <-s.stopChan:
+			logger.Info("Outreach sequence job stopped")
+			return
+		}
+	}
+}
+
+// runTrackingDomainRechecks checks the DNS verification of tracking domains every hour
+func (s *CronService) runTrackingDomainRechecks() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			checked, err := s.trackingDomainService.RecheckDomains(ctx, time.Now())
+			cancel()
+
+			if err != nil {
+				logger.Error("Error rechecking tracking domains", "error", err)
+			} else if checked > 0 {
+				logger.Info("Tracking domains rechecked", "count", checked)
+			}
+
+		case <-s.stopChan:
+			logger.Info("Tracking domain recheck job stopped")
+			return
+		}
+	}
+}
+
+// runLinkHealthChecks checks the URLs of active campaigns every 15 minutes; each run takes the
+// campaigns whose last check is older than the check interval
+func (s *CronService) runLinkHealthChecks() {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			checked, err := s.linkHealthService.RunChecks(ctx, time.Now())
+			cancel()
+
+			if err != nil {
+				logger.Error("Error checking campaign links", "error", err)
+			} else if checked > 0 {
+				logger.Info("Campaign links checked", "count", checked)
+			}
+
+		case <-s.stopChan:
+			logger.Info("Link health job stopped")
+			return
+		}
+	}
+}
+
+// runTrackingLinkLifecycle activates scheduled tracking links and archives ended ones every
+// 5 minutes. Redirects check the windows themselves, so the job only has to keep statuses current.
+func (s *CronService) runTrackingLinkLifecycle() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			changed, err := s.trackingLinkService.RunLifecycleTransitions(ctx, time.Now())
+			cancel()
+
+			if err != nil {
+				logger.Error("Error applying tracking link lifecycle", "error", err)
+			} else if changed > 0 {
+				logger.Info("Tracking link statuses updated", "count", changed)
+			}
+
+		case <-s.stopChan:
+			logger.Info("Tracking link lifecycle job stopped")
+			return
+		}
+	}
+}
+
+// runPublisherConversionProgress records the associations and first tracking links of open
+// publisher conversions every 5 minutes
+func (s *CronService) runPublisherConversionProgress() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			if err := s.conversionService.RefreshProgress(ctx); err != nil {
+				logger.Error("Error refreshing publisher conversion progress", "error", err)
+			}
+			cancel()
+
+		case <-s.stopChan:
+			logger.Info("Publisher conversion progress job stopped")
+			return
+		}
+	}
+}
+
+// runBulkTrackingLinkJobs resumes the bulk tracking link jobs whose instance stopped renewing their lease
+func (s *CronService) runBulkTrackingLinkJobs() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := s.bulkTrackingLinkService.ResumeJobs(ctx); err != nil {
+				logger.Error("Error resuming bulk tracking link jobs", "error", err)
+			}
+			cancel()
+
+		case <-s.stopChan:
+			logger.Info("Bulk tracking link job takeover stopped")
+			return
+		}
+	}
+}
+
 // RunManualUsageCalculation runs usage calculation for a specific date manually
 func (s *CronService) RunManualUsageCalculation(ctx context.Context, date time.Time) error {
 	logger.Info("Running manual usage calculation", "date", date.Format("2006-01-02"))
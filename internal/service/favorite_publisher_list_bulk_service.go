@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/export"
+)
+
+// ImportPublishers adds the rows of a CSV or JSON import to a list. Invalid rows, unknown
+// publishers (unless allowed) and unknown stages are reported per row; the other rows are imported.
+func (s *favoritePublisherListService) ImportPublishers(ctx context.Context, organizationID, listID int64, userID *string, rows []*domain.FavoriteListImportRow, opts domain.FavoriteListImportOptions) (*domain.FavoriteListImportResult, error) {
+	if _, err := s.validateListOwnership(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+	if opts.OnDuplicate == "" {
+		opts.OnDuplicate = domain.FavoriteListDuplicateSkip
+	}
+	if opts.OnDuplicate != domain.FavoriteListDuplicateSkip && opts.OnDuplicate != domain.FavoriteListDuplicateUpdate {
+		return nil, fmt.Errorf("%w: invalid on_duplicate: %s (must be skip or update)", domain.ErrInvalidInput, opts.OnDuplicate)
+	}
+	if len(rows) > domain.MaxFavoriteListImportRows {
+		return nil, fmt.Errorf("%w: at most %d rows can be imported at once", domain.ErrInvalidInput, domain.MaxFavoriteListImportRows)
+	}
+
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.FavoriteListImportResult{Total: len(rows), Errors: make([]*domain.FavoriteListImportError, 0)}
+	valid := make([]*domain.FavoriteListImportRow, 0, len(rows))
+	items := make(map[string]*domain.FavoritePublisherListItem, len(rows))
+	for _, row := range rows {
+		if err := row.Normalize(); err != nil {
+			result.AddError(row.Row, row.PublisherDomain, err.Error())
+			continue
+		}
+		if _, seen := items[row.PublisherDomain]; seen {
+			result.Skipped++
+			continue
+		}
+
+		item, err := importedListItem(stages, listID, row)
+		if err != nil {
+			result.AddError(row.Row, row.PublisherDomain, err.Error())
+			continue
+		}
+		items[row.PublisherDomain] = item
+		valid = append(valid, row)
+	}
+
+	if !opts.AllowUnknown && len(valid) > 0 {
+		domains := make([]string, len(valid))
+		for i, row := range valid {
+			domains[i] = row.PublisherDomain
+		}
+		known, err := s.analyticsRepo.GetExistingPublisherDomains(ctx, domains)
+		if err != nil {
+			return nil, err
+		}
+
+		knownRows := valid[:0]
+		for _, row := range valid {
+			if !known[row.PublisherDomain] {
+				result.AddError(row.Row, row.PublisherDomain, "publisher not found in analytics publishers")
+				continue
+			}
+			knownRows = append(knownRows, row)
+		}
+		valid = knownRows
+	}
+
+	// The stage of existing items is changed by moving them, so that the move is recorded in
+	// their history
+	updateExisting := opts.OnDuplicate == domain.FavoriteListDuplicateUpdate
+	var moves []*domain.FavoriteListImportMove
+	if updateExisting && len(valid) > 0 {
+		inList, err := s.favoriteListRepo.GetListDomains(ctx, listID)
+		if err != nil {
+			return nil, err
+		}
+		var unmoved []*domain.FavoriteListImportRow
+		moves, unmoved = domain.PlanFavoriteListImportMoves(stages, valid, inList)
+		if len(unmoved) > 0 {
+			rejected := make(map[string]bool, len(unmoved))
+			for _, row := range unmoved {
+				rejected[row.PublisherDomain] = true
+				result.AddError(row.Row, row.PublisherDomain, fmt.Sprintf("no pipeline stage matches status %s", *row.Status))
+			}
+			movable := valid[:0]
+			for _, row := range valid {
+				if !rejected[row.PublisherDomain] {
+					movable = append(movable, row)
+				}
+			}
+			valid = movable
+		}
+	}
+
+	toAdd := make([]*domain.FavoritePublisherListItem, len(valid))
+	for i, row := range valid {
+		toAdd[i] = items[row.PublisherDomain]
+	}
+	added, updated, err := s.favoriteListRepo.BulkAddPublishersToList(ctx, listID, toAdd, updateExisting, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, move := range moves {
+		status := domain.LegacyStatusForStage(stages, move.Stage)
+		if _, err := s.pipelineRepo.MoveItems(ctx, listID, move.PublisherDomains, move.Stage, status, userID, domain.PipelineChangeReasonMoved, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Added = added
+	result.Updated = updated
+	result.Skipped += len(toAdd) - added - updated
+	return result, nil
+}
+
+// importedListItem builds the list item for an import row. A stage wins over a status; without
+// either the publisher starts in the entry stage.
+func importedListItem(stages []*domain.PipelineStage, listID int64, row *domain.FavoriteListImportRow) (*domain.FavoritePublisherListItem, error) {
+	item := &domain.FavoritePublisherListItem{
+		ListID:          listID,
+		PublisherDomain: row.PublisherDomain,
+		Notes:           row.Notes,
+		Status:          domain.PublisherStatusAdded,
+	}
+
+	var stage *domain.PipelineStage
+	switch {
+	case row.Stage != nil:
+		if stage = domain.FindPipelineStageByName(stages, *row.Stage); stage == nil {
+			return nil, fmt.Errorf("unknown pipeline stage: %s", *row.Stage)
+		}
+		item.Status = domain.LegacyStatusForStage(stages, stage)
+	default:
+		if row.Status != nil {
+			item.Status = *row.Status
+		}
+		stage = domain.StageForLegacyStatus(stages, item.Status)
+	}
+	if stage != nil {
+		item.StageID = &stage.StageID
+	}
+	return item, nil
+}
+
+// BulkUpdatePublishers changes the status or stage of, or removes, several publishers of a list
+func (s *favoritePublisherListService) BulkUpdatePublishers(ctx context.Context, organizationID, listID int64, userID *string, req *domain.BulkFavoriteListItemsRequest) (*domain.FavoriteListBulkResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.validateListOwnership(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+
+	inList, err := s.favoriteListRepo.GetListDomains(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.FavoriteListBulkResult{NotFound: make([]string, 0)}
+	domains := make([]string, 0, len(req.PublisherDomains))
+	for _, publisherDomain := range uniqueTrimmed(req.PublisherDomains) {
+		if !inList[publisherDomain] {
+			result.NotFound = append(result.NotFound, publisherDomain)
+			continue
+		}
+		domains = append(domains, publisherDomain)
+	}
+	result.Matched = len(domains)
+	if len(domains) == 0 {
+		return result, nil
+	}
+
+	if req.Action == domain.FavoriteListBulkActionRemove {
+		removed, err := s.favoriteListRepo.RemovePublishersFromList(ctx, listID, domains)
+		if err != nil {
+			return nil, err
+		}
+		result.Changed = removed
+		return result, nil
+	}
+
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	var stage *domain.PipelineStage
+	reason := domain.PipelineChangeReasonMoved
+	if req.Action == domain.FavoriteListBulkActionStage {
+		if stage = domain.FindPipelineStage(stages, *req.StageID); stage == nil {
+			return nil, fmt.Errorf("%w: stage %d does not exist", domain.ErrInvalidInput, *req.StageID)
+		}
+	} else {
+		if stage = domain.StageForLegacyStatus(stages, *req.Status); stage == nil {
+			return nil, fmt.Errorf("%w: no pipeline stage matches status %s", domain.ErrInvalidInput, *req.Status)
+		}
+		reason = domain.PipelineChangeReasonStatus
+	}
+
+	moved, err := s.pipelineRepo.MoveItems(ctx, listID, domains, stage, domain.LegacyStatusForStage(stages, stage), userID, reason, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	result.Changed = moved
+	return result, nil
+}
+
+// CopyPublishers copies publishers of a list, with their stage, notes and follow-up, into another
+// list of the organization or into a new list
+func (s *favoritePublisherListService) CopyPublishers(ctx context.Context, organizationID, listID int64, userID *string, req *domain.CopyFavoriteListItemsRequest) (*domain.FavoriteListCopyResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.validateListOwnership(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+
+	inList, err := s.favoriteListRepo.GetListDomains(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	domains := uniqueTrimmed(req.PublisherDomains)
+	requested := len(inList)
+	if len(domains) > 0 {
+		requested = 0
+		for _, publisherDomain := range domains {
+			if inList[publisherDomain] {
+				requested++
+			}
+		}
+	}
+
+	var target *domain.FavoritePublisherList
+	if req.TargetListID != nil {
+		if *req.TargetListID == listID {
+			return nil, fmt.Errorf("%w: cannot copy a list into itself", domain.ErrInvalidInput)
+		}
+		if target, err = s.validateListOwnership(ctx, organizationID, *req.TargetListID); err != nil {
+			return nil, err
+		}
+	} else {
+		target, err = s.CreateList(ctx, organizationID, &domain.CreateFavoritePublisherListRequest{Name: strings.TrimSpace(*req.NewListName)})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	added, updated, err := s.favoriteListRepo.CopyPublishersToList(ctx, listID, target.ListID, domains,
+		req.OnDuplicate == domain.FavoriteListDuplicateUpdate, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.FavoriteListCopyResult{
+		TargetList: target,
+		Added:      added,
+		Updated:    updated,
+		Skipped:    requested - added - updated,
+	}, nil
+}
+
+// MergeList moves every publisher of a list into another list of the organization and deletes it
+func (s *favoritePublisherListService) MergeList(ctx context.Context, organizationID, listID int64, userID *string, req *domain.MergeFavoriteListRequest) (*domain.FavoriteListCopyResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if req.TargetListID == listID {
+		return nil, fmt.Errorf("%w: cannot merge a list into itself", domain.ErrInvalidInput)
+	}
+	if _, err := s.validateListOwnership(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+	target, err := s.validateListOwnership(ctx, organizationID, req.TargetListID)
+	if err != nil {
+		return nil, err
+	}
+
+	inList, err := s.favoriteListRepo.GetListDomains(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	added, updated, err := s.favoriteListRepo.MergeLists(ctx, listID, target.ListID,
+		req.OnDuplicate == domain.FavoriteListDuplicateUpdate, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.FavoriteListCopyResult{
+		TargetList: target,
+		Added:      added,
+		Updated:    updated,
+		Skipped:    len(inList) - added - updated,
+	}, nil
+}
+
+// ExportList renders the publishers of a list with their pipeline state and latest metrics
+func (s *favoritePublisherListService) ExportList(ctx context.Context, organizationID, listID int64, format string) (*export.File, error) {
+	switch format {
+	case export.FormatCSV, export.FormatXLSX, export.FormatJSONL:
+	default:
+		return nil, fmt.Errorf("%w: unsupported export format: %s", domain.ErrInvalidInput, format)
+	}
+	if _, err := s.validateListOwnership(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.favoriteListRepo.GetListItemsWithPublisherDetails(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Publisher != nil {
+			domains = append(domains, item.Publisher.Domain)
+		}
+	}
+	snapshots, err := s.analyticsRepo.GetLatestPublisherSnapshots(ctx, domains, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &export.Table{
+		Columns: []string{
+			"publisher_domain", "status", "stage", "notes", "owner_user_id", "next_action_at", "next_action_note", "added_at",
+			"known", "relevance", "traffic_score", "promotype",
+			"partner_count", "affiliate_networks", "verticals", "countries", "metrics_captured_at",
+		},
+		Rows: make([][]interface{}, 0, len(items)),
+	}
+	for _, item := range items {
+		row := []interface{}{
+			item.PublisherDomain, item.Status, nil, optionalCell(item.Notes), optionalCell(item.OwnerUserID),
+			optionalTimeCell(item.NextActionAt), optionalCell(item.NextActionNote), item.AddedAt.UTC().Format(time.RFC3339),
+			nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		}
+		if item.StageID != nil {
+			if stage := domain.FindPipelineStage(stages, *item.StageID); stage != nil {
+				row[2] = stage.Name
+			}
+		}
+		if publisher := item.Publisher; publisher != nil {
+			row[8], row[9], row[10], row[11] = publisher.Known, publisher.Relevance, publisher.TrafficScore, optionalCell(publisher.Promotype)
+			if latest := snapshots[publisher.Domain]; len(latest) > 0 {
+				snapshot := latest[0]
+				row[12] = snapshot.PartnerCount
+				row[13] = strings.Join(snapshot.AffiliateNetworks, ";")
+				row[14] = strings.Join(snapshot.Verticals, ";")
+				row[15] = strings.Join(snapshot.Countries, ";")
+				row[16] = snapshot.CapturedAt.UTC().Format(time.RFC3339)
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return export.Render(format, table)
+}
+
+// uniqueTrimmed trims the values and drops empty and repeated ones, keeping the first occurrence
+func uniqueTrimmed(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+func optionalCell(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func optionalTimeCell(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.UTC().Format(time.RFC3339)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/export"
 	"github.com/affiliate-backend/internal/repository"
 )
 
@@ -24,21 +25,32 @@ type FavoritePublisherListService interface {
 	UpdatePublisherInList(ctx context.Context, organizationID int64, listID int64, publisherDomain string, req *domain.UpdatePublisherInListRequest) error
 	UpdatePublisherStatus(ctx context.Context, organizationID int64, listID int64, publisherDomain string, req *domain.UpdatePublisherStatusRequest) error
 
+	// Bulk operations; userID is recorded as the author of pipeline stage changes
+	ImportPublishers(ctx context.Context, organizationID, listID int64, userID *string, rows []*domain.FavoriteListImportRow, opts domain.FavoriteListImportOptions) (*domain.FavoriteListImportResult, error)
+	BulkUpdatePublishers(ctx context.Context, organizationID, listID int64, userID *string, req *domain.BulkFavoriteListItemsRequest) (*domain.FavoriteListBulkResult, error)
+	CopyPublishers(ctx context.Context, organizationID, listID int64, userID *string, req *domain.CopyFavoriteListItemsRequest) (*domain.FavoriteListCopyResult, error)
+	MergeList(ctx context.Context, organizationID, listID int64, userID *string, req *domain.MergeFavoriteListRequest) (*domain.FavoriteListCopyResult, error)
+	ExportList(ctx context.Context, organizationID, listID int64, format string) (*export.File, error)
+
 	// Utility methods
 	GetListsContainingPublisher(ctx context.Context, organizationID int64, publisherDomain string) ([]*domain.FavoritePublisherList, error)
+	GetListRecommendations(ctx context.Context, organizationID int64, listID int64, limit int) (*domain.SimilarPublishersResponse, error)
 }
 
 // favoritePublisherListService implements FavoritePublisherListService
 type favoritePublisherListService struct {
 	favoriteListRepo repository.FavoritePublisherListRepository
 	analyticsRepo    repository.AnalyticsRepository
+	pipelineRepo     repository.PublisherPipelineRepository
 }
 
-// NewFavoritePublisherListService creates a new favorite publisher list service
-func NewFavoritePublisherListService(favoriteListRepo repository.FavoritePublisherListRepository, analyticsRepo repository.AnalyticsRepository) FavoritePublisherListService {
+// NewFavoritePublisherListService creates a new favorite publisher list service. List items are
+// placed in the organization's outreach pipeline, whose stages pipelineRepo holds.
+func NewFavoritePublisherListService(favoriteListRepo repository.FavoritePublisherListRepository, analyticsRepo repository.AnalyticsRepository, pipelineRepo repository.PublisherPipelineRepository) FavoritePublisherListService {
 	return &favoritePublisherListService{
 		favoriteListRepo: favoriteListRepo,
 		analyticsRepo:    analyticsRepo,
+		pipelineRepo:     pipelineRepo,
 	}
 }
 
@@ -164,18 +176,49 @@ func (s *favoritePublisherListService) AddPublisherToList(ctx context.Context, o
 		status = *req.Status
 	}
 
+	// Place the publisher in the requested stage, the stage matching the status, or the entry stage
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	var stage *domain.PipelineStage
+	switch {
+	case req.StageID != nil:
+		if stage = domain.FindPipelineStage(stages, *req.StageID); stage == nil {
+			return nil, fmt.Errorf("%w: stage %d does not exist", domain.ErrInvalidInput, *req.StageID)
+		}
+		status = domain.LegacyStatusForStage(stages, stage)
+	default:
+		stage = domain.StageForLegacyStatus(stages, status)
+	}
+
 	item := &domain.FavoritePublisherListItem{
 		ListID:          listID,
 		PublisherDomain: req.PublisherDomain,
 		Notes:           req.Notes,
 		Status:          status,
 	}
+	if stage != nil {
+		item.StageID = &stage.StageID
+	}
 
 	err = s.favoriteListRepo.AddPublisherToList(ctx, item)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add publisher to list: %w", err)
 	}
 
+	if stage != nil {
+		change := &domain.PipelineStageChange{
+			ItemID:      item.ItemID,
+			ToStageID:   &stage.StageID,
+			ToStageName: stage.Name,
+			Reason:      domain.PipelineChangeReasonCreated,
+		}
+		if err := s.pipelineRepo.CreateStageChange(ctx, change); err != nil {
+			return nil, err
+		}
+	}
+
 	return item, nil
 }
 
@@ -199,12 +242,45 @@ func (s *favoritePublisherListService) GetListItems(ctx context.Context, organiz
 	}
 
 	if includeDetails {
-		return s.favoriteListRepo.GetListItemsWithPublisherDetails(ctx, listID)
+		items, err := s.favoriteListRepo.GetListItemsWithPublisherDetails(ctx, listID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.attachRecentChanges(ctx, items); err != nil {
+			return nil, err
+		}
+		return items, nil
 	}
 
 	return s.favoriteListRepo.GetListItems(ctx, listID)
 }
 
+// attachRecentChanges sets what changed between the two latest snapshots of each item's publisher
+func (s *favoritePublisherListService) attachRecentChanges(ctx context.Context, items []*domain.FavoritePublisherListItem) error {
+	domains := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Publisher != nil {
+			domains = append(domains, item.Publisher.Domain)
+		}
+	}
+
+	snapshots, err := s.analyticsRepo.GetLatestPublisherSnapshots(ctx, domains, 2)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Publisher == nil {
+			continue
+		}
+		// Snapshots are newest first
+		if latest := snapshots[item.Publisher.Domain]; len(latest) == 2 {
+			item.RecentChange = domain.DiffPublisherSnapshots(latest[1], latest[0])
+		}
+	}
+	return nil
+}
+
 // UpdatePublisherInList updates the notes for a publisher in a list
 func (s *favoritePublisherListService) UpdatePublisherInList(ctx context.Context, organizationID int64, listID int64, publisherDomain string, req *domain.UpdatePublisherInListRequest) error {
 	// First, ensure the list belongs to the organization
@@ -243,6 +319,51 @@ func (s *favoritePublisherListService) UpdatePublisherStatus(ctx context.Context
 	if err := domain.ValidateStatusTransition(currentItem.Status, req.Status); err != nil {
 		return fmt.Errorf("invalid status transition from %s to %s: %w", currentItem.Status, req.Status, err)
 	}
+	if currentItem.Status == req.Status {
+		return nil
+	}
+
+	// Move the item to the pipeline stage matching the status, so board and status agree
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return err
+	}
+	if stage := domain.StageForLegacyStatus(stages, req.Status); stage != nil {
+		_, err := moveListItem(ctx, s.pipelineRepo, stages, currentItem, stage, domain.PipelineChangeReasonStatus, nil, nil)
+		return err
+	}
 
 	return s.favoriteListRepo.UpdatePublisherStatus(ctx, listID, publisherDomain, req.Status)
 }
+
+// GetListRecommendations recommends publishers resembling the publishers of a list as a whole,
+// excluding publishers already in the list or already associated with the organization
+func (s *favoritePublisherListService) GetListRecommendations(ctx context.Context, organizationID int64, listID int64, limit int) (*domain.SimilarPublishersResponse, error) {
+	if _, err := s.validateListOwnership(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.favoriteListRepo.GetListItems(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list items: %w", err)
+	}
+
+	excluded, err := s.favoriteListRepo.GetAssociatedPublisherDomains(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, 0, len(items))
+	for _, item := range items {
+		excluded = append(excluded, item.PublisherDomain)
+		domains = append(domains, item.PublisherDomain)
+	}
+
+	// Publishers without analytics data have nothing to compare with and are skipped
+	seeds, err := s.analyticsRepo.GetPublishersByDomains(ctx, domains)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list publishers: %w", err)
+	}
+
+	return newLookalikeRecommender(s.analyticsRepo).recommend(ctx, seeds, excluded, limit)
+}
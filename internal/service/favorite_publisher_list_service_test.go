@@ -89,6 +89,14 @@ func (m *MockAnalyticsRepository) GetPublisherByDomain(ctx context.Context, doma
 	return args.Get(0).(*domain.AnalyticsPublisher), args.Error(1)
 }
 
+func (m *MockAnalyticsRepository) GetPublishersByDomains(ctx context.Context, domainNames []string) ([]*domain.AnalyticsPublisher, error) {
+	args := m.Called(ctx, domainNames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AnalyticsPublisher), args.Error(1)
+}
+
 // Implement other required methods (not used in these tests)
 func (m *MockAnalyticsRepository) CreateAdvertiser(ctx context.Context, advertiser *domain.AnalyticsAdvertiser) error {
 	return nil
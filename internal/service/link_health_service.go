@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/linkcheck"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+const (
+	// linkHealthBatchSize bounds the campaigns checked per cron run
+	linkHealthBatchSize = 50
+	// linkHealthTrackingLinksPerCampaign bounds the tracking URLs checked per campaign
+	linkHealthTrackingLinksPerCampaign = 5
+)
+
+// LinkChecker fetches a URL and follows its redirects; *linkcheck.Checker satisfies it
+type LinkChecker interface {
+	Check(ctx context.Context, rawURL string) *linkcheck.Result
+}
+
+// LinkHealthService defines the interface for campaign link health monitoring
+type LinkHealthService interface {
+	// GetHealth returns the monitoring record of a campaign of the organization with the checks
+	// of its latest run
+	GetHealth(ctx context.Context, orgID, campaignID int64) (*domain.CampaignLinkHealthResponse, error)
+	SetSettings(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignLinkHealthRequest) (*domain.CampaignLinkHealth, error)
+	ListChecks(ctx context.Context, orgID, campaignID int64, page, pageSize int) (*domain.LinkHealthCheckListResponse, error)
+
+	// RunChecks checks the URLs of the active campaigns due for a check, alerts their
+	// organizations on failures and pauses campaigns that reached their auto-pause threshold.
+	// It returns the number of campaigns checked.
+	RunChecks(ctx context.Context, now time.Time) (int, error)
+}
+
+// linkHealthService implements LinkHealthService
+type linkHealthService struct {
+	linkHealthRepo      repository.LinkHealthRepository
+	campaignRepo        repository.CampaignRepository
+	trackingLinkRepo    repository.TrackingLinkRepository
+	campaignService     CampaignService
+	campaignURLService  CampaignURLService
+	notificationService NotificationService
+	checker             LinkChecker
+}
+
+// NewLinkHealthService creates a new link health service. A nil checker uses a linkcheck
+// checker with the default HTTP client.
+func NewLinkHealthService(
+	linkHealthRepo repository.LinkHealthRepository,
+	campaignRepo repository.CampaignRepository,
+	trackingLinkRepo repository.TrackingLinkRepository,
+	campaignService CampaignService,
+	campaignURLService CampaignURLService,
+	notificationService NotificationService,
+	checker LinkChecker,
+) LinkHealthService {
+	if checker == nil {
+		checker = linkcheck.NewChecker(nil)
+	}
+	return &linkHealthService{
+		linkHealthRepo:      linkHealthRepo,
+		campaignRepo:        campaignRepo,
+		trackingLinkRepo:    trackingLinkRepo,
+		campaignService:     campaignService,
+		campaignURLService:  campaignURLService,
+		notificationService: notificationService,
+		checker:             checker,
+	}
+}
+
+// GetHealth returns the monitoring record of a campaign; campaigns never checked get the defaults
+func (s *linkHealthService) GetHealth(ctx context.Context, orgID, campaignID int64) (*domain.CampaignLinkHealthResponse, error) {
+	if _, err := s.getCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+
+	health, err := s.linkHealthRepo.GetHealth(ctx, campaignID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		health = &domain.CampaignLinkHealth{CampaignID: campaignID, Enabled: true}
+	}
+	checks, err := s.linkHealthRepo.ListLatestChecks(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CampaignLinkHealthResponse{Health: health, LatestChecks: checks}, nil
+}
+
+// SetSettings enables or disables monitoring of a campaign and sets its auto-pause threshold
+func (s *linkHealthService) SetSettings(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignLinkHealthRequest) (*domain.CampaignLinkHealth, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.getCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+
+	health := &domain.CampaignLinkHealth{
+		CampaignID:             campaignID,
+		Enabled:                req.Enabled,
+		AutoPauseAfterFailures: req.AutoPauseAfterFailures,
+	}
+	if err := s.linkHealthRepo.UpsertSettings(ctx, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// ListChecks lists the checks of a campaign, newest first
+func (s *linkHealthService) ListChecks(ctx context.Context, orgID, campaignID int64, page, pageSize int) (*domain.LinkHealthCheckListResponse, error) {
+	if _, err := s.getCampaign(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+
+	checks, total, err := s.linkHealthRepo.ListChecks(ctx, campaignID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LinkHealthCheckListResponse{
+		Checks:   checks,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// RunChecks claims and checks a batch of the campaigns due for a check and deletes expired results
+func (s *linkHealthService) RunChecks(ctx context.Context, now time.Time) (int, error) {
+	targets, err := s.linkHealthRepo.ClaimTargets(ctx, now.Add(-domain.LinkHealthCheckInterval), now,
+		now.Add(domain.LinkHealthClaimTimeout), linkHealthBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	checked := 0
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.checkCampaign(ctx, target.Campaign, now); err != nil {
+			logger.Error("Failed to check campaign links", "campaign_id", target.Campaign.CampaignID, "error", err)
+			continue
+		}
+		checked++
+	}
+
+	if deleted, err := s.linkHealthRepo.DeleteChecksBefore(ctx, now.Add(-domain.LinkHealthCheckRetention)); err != nil {
+		logger.Warn("Failed to delete expired link health checks", "error", err)
+	} else if deleted > 0 {
+		logger.Debug("Deleted expired link health checks", "count", deleted)
+	}
+	return checked, nil
+}
+
+// linkHealthURL is a URL of a campaign to check
+type linkHealthURL struct {
+	kind           string
+	url            string
+	trackingLinkID *int64
+}
+
+// checkCampaign checks the URLs of a campaign, records the run and acts on its failure streak
+func (s *linkHealthService) checkCampaign(ctx context.Context, campaign *domain.Campaign, now time.Time) error {
+	urls, err := s.campaignURLs(ctx, campaign, now)
+	if err != nil {
+		return err
+	}
+
+	var failures []*domain.LinkHealthCheck
+	for _, target := range urls {
+		check := s.checkURL(ctx, campaign, target, now)
+		if err := s.linkHealthRepo.CreateCheck(ctx, check); err != nil {
+			return err
+		}
+		if !check.Healthy {
+			failures = append(failures, check)
+		}
+	}
+
+	health, err := s.linkHealthRepo.RecordRun(ctx, campaign.CampaignID, len(failures) == 0, now)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	// Alert once per failure streak, and again when the campaign is paused
+	if health.ConsecutiveFailures == 1 {
+		notify(ctx, s.notificationService, &domain.NewNotification{
+			OrganizationID: campaign.OrganizationID,
+			Type:           domain.NotificationTypeLinkHealthAlert,
+			Title:          fmt.Sprintf("Links of campaign %s are failing", campaign.Name),
+			Body:           linkHealthFailureSummary(failures),
+			Data: map[string]interface{}{
+				"campaign_id":   campaign.CampaignID,
+				"failed_checks": len(failures),
+			},
+		})
+	}
+	if health.ShouldAutoPause() {
+		s.autoPause(ctx, campaign.CampaignID, health, failures, now)
+	}
+	return nil
+}
+
+// campaignURLs lists the destination, preview and tracking URLs of a campaign. The destination
+// is expanded for a sample click so that its macros do not reach the advertiser.
+func (s *linkHealthService) campaignURLs(ctx context.Context, campaign *domain.Campaign, now time.Time) ([]linkHealthURL, error) {
+	var urls []linkHealthURL
+	seen := make(map[string]bool)
+	add := func(kind, rawURL string, trackingLinkID *int64) {
+		if rawURL == "" || seen[rawURL] {
+			return
+		}
+		seen[rawURL] = true
+		urls = append(urls, linkHealthURL{kind: kind, url: rawURL, trackingLinkID: trackingLinkID})
+	}
+
+	if campaign.DestinationURL != nil && *campaign.DestinationURL != "" {
+		destination, err := s.campaignURLService.BuildDestinationURL(ctx, campaign, domain.URLMacroClick{
+			TrackingLink: &domain.TrackingLink{CampaignID: campaign.CampaignID, OrganizationID: campaign.OrganizationID},
+			Time:         now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		add(domain.LinkURLKindDestination, destination, nil)
+	}
+	if campaign.PreviewURL != nil {
+		add(domain.LinkURLKindPreview, *campaign.PreviewURL, nil)
+	}
+
+	links, err := s.trackingLinkRepo.ListTrackingLinksByCampaign(ctx, campaign.CampaignID, linkHealthTrackingLinksPerCampaign, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.Status != "active" || link.TrackingURL == nil {
+			continue
+		}
+		trackingLinkID := link.TrackingLinkID
+		add(domain.LinkURLKindTracking, *link.TrackingURL, &trackingLinkID)
+	}
+	return urls, nil
+}
+
+// checkURL fetches a URL and converts the result into a check record
+func (s *linkHealthService) checkURL(ctx context.Context, campaign *domain.Campaign, target linkHealthURL, now time.Time) *domain.LinkHealthCheck {
+	result := s.checker.Check(ctx, target.url)
+
+	check := &domain.LinkHealthCheck{
+		OrganizationID: campaign.OrganizationID,
+		CampaignID:     campaign.CampaignID,
+		TrackingLinkID: target.trackingLinkID,
+		URLKind:        target.kind,
+		URL:            target.url,
+		LatencyMs:      int(result.Latency.Milliseconds()),
+		RedirectChain:  result.RedirectChain,
+		TLSExpiresAt:   result.TLSExpiresAt,
+		Healthy:        result.Healthy(),
+		CheckedAt:      now,
+	}
+	if result.StatusCode != 0 {
+		statusCode := result.StatusCode
+		check.StatusCode = &statusCode
+	}
+	if result.Err != nil {
+		message := result.Err.Error()
+		check.Error = &message
+	} else if !check.Healthy {
+		message := fmt.Sprintf("unexpected status %d", result.StatusCode)
+		check.Error = &message
+	}
+	return check
+}
+
+// autoPause pauses a campaign whose failure streak reached its threshold. Campaigns paused
+// meanwhile are left alone.
+func (s *linkHealthService) autoPause(ctx context.Context, campaignID int64, health *domain.CampaignLinkHealth, failures []*domain.LinkHealthCheck, now time.Time) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		logger.Error("Failed to load campaign to auto-pause", "campaign_id", campaignID, "error", err)
+		return
+	}
+	if campaign.Status != "active" {
+		return
+	}
+
+	campaign.Status = "paused"
+	if err := s.campaignService.UpdateCampaign(ctx, campaign); err != nil {
+		logger.Error("Failed to auto-pause campaign", "campaign_id", campaignID, "error", err)
+		return
+	}
+	if err := s.linkHealthRepo.MarkAutoPaused(ctx, campaignID, now); err != nil {
+		logger.Warn("Failed to record campaign auto-pause", "campaign_id", campaignID, "error", err)
+	}
+	logger.Warn("Campaign auto-paused after failed link checks", "campaign_id", campaignID,
+		"consecutive_failures", health.ConsecutiveFailures)
+
+	notify(ctx, s.notificationService, &domain.NewNotification{
+		OrganizationID: campaign.OrganizationID,
+		Type:           domain.NotificationTypeLinkHealthAlert,
+		Title:          fmt.Sprintf("Campaign %s was paused", campaign.Name),
+		Body: fmt.Sprintf("Its links failed %d checks in a row. %s",
+			health.ConsecutiveFailures, linkHealthFailureSummary(failures)),
+		Data: map[string]interface{}{
+			"campaign_id":          campaign.CampaignID,
+			"consecutive_failures": health.ConsecutiveFailures,
+			"auto_paused":          true,
+		},
+	})
+}
+
+// linkHealthFailureSummary describes failed checks in a notification body
+func linkHealthFailureSummary(failures []*domain.LinkHealthCheck) string {
+	lines := make([]string, 0, len(failures))
+	for _, check := range failures {
+		reason := "failed"
+		if check.Error != nil {
+			reason = *check.Error
+		}
+		lines = append(lines, fmt.Sprintf("%s URL %s: %s", check.URLKind, check.URL, reason))
+	}
+	return notificationPreview(strings.Join(lines, "; "))
+}
+
+func (s *linkHealthService) getCampaign(ctx context.Context, orgID, campaignID int64) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	return campaign, nil
+}
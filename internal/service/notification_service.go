@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+const (
+	// notificationStreamBuffer is the number of notifications queued for a slow stream before
+	// newer ones are dropped; dropped notifications are still listed by the API
+	notificationStreamBuffer = 16
+	// Wait between attempts to restore the LISTEN connection
+	notificationListenMinBackoff = time.Second
+	notificationListenMaxBackoff = time.Minute
+	// notificationPreviewLength is the number of characters of a message quoted in a notification
+	notificationPreviewLength = 200
+)
+
+// NotificationService stores notifications of organization users and pushes them to the
+// users' open streams on every replica
+type NotificationService interface {
+	// Notify stores a notification for the recipients that have the type enabled. Replicas with an
+	// open stream of a recipient are told through Postgres NOTIFY.
+	Notify(ctx context.Context, notification *domain.NewNotification) error
+
+	ListNotifications(ctx context.Context, userID string, filter *domain.NotificationFilter, page, pageSize int) (*domain.NotificationListResponse, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID string, notificationID int64) error
+	MarkUnread(ctx context.Context, userID string, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID string, notificationType *string) (int, error)
+	DeleteNotification(ctx context.Context, userID string, notificationID int64) error
+
+	// GetPreferences returns the settings of every notification type, defaults included
+	GetPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, userID string, req *domain.UpdateNotificationPreferencesRequest) ([]domain.NotificationPreference, error)
+
+	// Subscribe opens a stream of the user's new notifications on this replica. The returned
+	// function closes it; the channel is closed when Run stops.
+	Subscribe(userID string) (<-chan *domain.Notification, func())
+	// Run listens for notifications created by any replica and delivers them to the local
+	// streams until ctx is cancelled, reconnecting when the connection drops. The open streams
+	// are closed when it returns.
+	Run(ctx context.Context)
+}
+
+// notificationService implements NotificationService
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan *domain.Notification]struct{}
+	stopped     bool
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		subscribers:      make(map[string]map[chan *domain.Notification]struct{}),
+	}
+}
+
+// notify sends a notification through notifier, logging failures. Notifications are a side
+// effect, so callers carry on when they cannot be stored; notifier may be nil.
+func notify(ctx context.Context, notifier NotificationService, notification *domain.NewNotification) {
+	if notifier == nil {
+		return
+	}
+	if err := notifier.Notify(ctx, notification); err != nil {
+		logger.Error("Failed to send notification",
+			"organization_id", notification.OrganizationID,
+			"type", notification.Type,
+			"error", err)
+	}
+}
+
+// notificationPreview shortens a message to quote it in a notification
+func notificationPreview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= notificationPreviewLength {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:notificationPreviewLength])) + "…"
+}
+
+// Notify stores a notification for its recipients
+func (s *notificationService) Notify(ctx context.Context, notification *domain.NewNotification) error {
+	if err := notification.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	created, err := s.notificationRepo.CreateNotifications(ctx, notification)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Notification created",
+		"organization_id", notification.OrganizationID,
+		"type", notification.Type,
+		"recipients", len(created))
+	return nil
+}
+
+// ListNotifications returns a page of the user's notifications with the unread count
+func (s *notificationService) ListNotifications(ctx context.Context, userID string, filter *domain.NotificationFilter, page, pageSize int) (*domain.NotificationListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	filter.Limit = pageSize
+	if err := filter.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	notifications, total, err := s.notificationRepo.ListNotifications(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		UnreadCount:   unread,
+		Page:          page,
+		PageSize:      filter.Limit,
+	}, nil
+}
+
+// CountUnread counts the user's unread notifications
+func (s *notificationService) CountUnread(ctx context.Context, userID string) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks a notification as read
+func (s *notificationService) MarkRead(ctx context.Context, userID string, notificationID int64) error {
+	return s.notificationRepo.MarkRead(ctx, userID, notificationID)
+}
+
+// MarkUnread marks a notification as unread
+func (s *notificationService) MarkUnread(ctx context.Context, userID string, notificationID int64) error {
+	return s.notificationRepo.MarkUnread(ctx, userID, notificationID)
+}
+
+// MarkAllRead marks every unread notification, or those of one type, as read
+func (s *notificationService) MarkAllRead(ctx context.Context, userID string, notificationType *string) (int, error) {
+	if notificationType != nil && !domain.IsValidNotificationType(*notificationType) {
+		return 0, fmt.Errorf("%w: invalid notification type: %s", domain.ErrInvalidInput, *notificationType)
+	}
+	return s.notificationRepo.MarkAllRead(ctx, userID, notificationType)
+}
+
+// DeleteNotification deletes a notification
+func (s *notificationService) DeleteNotification(ctx context.Context, userID string, notificationID int64) error {
+	return s.notificationRepo.DeleteNotification(ctx, userID, notificationID)
+}
+
+// GetPreferences merges the stored preferences into the defaults
+func (s *notificationService) GetPreferences(ctx context.Context, userID string) ([]domain.NotificationPreference, error) {
+	stored, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]domain.NotificationPreference, len(stored))
+	for _, p := range stored {
+		byType[p.Type] = p
+	}
+	preferences := domain.DefaultNotificationPreferences()
+	for i, p := range preferences {
+		if saved, ok := byType[p.Type]; ok {
+			preferences[i] = saved
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences changes the settings of the listed types; omitted fields keep their value
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID string, req *domain.UpdateNotificationPreferencesRequest) ([]domain.NotificationPreference, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	current, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]*domain.NotificationPreference, len(current))
+	for i := range current {
+		byType[current[i].Type] = &current[i]
+	}
+
+	changed := make([]domain.NotificationPreference, 0, len(req.Preferences))
+	for _, update := range req.Preferences {
+		preference := byType[update.Type]
+		if update.Enabled != nil {
+			preference.Enabled = *update.Enabled
+		}
+		if update.Realtime != nil {
+			preference.Realtime = *update.Realtime
+		}
+		changed = append(changed, *preference)
+	}
+
+	if err := s.notificationRepo.SavePreferences(ctx, userID, changed); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// Subscribe registers a stream of the user's notifications
+func (s *notificationService) Subscribe(userID string) (<-chan *domain.Notification, func()) {
+	ch := make(chan *domain.Notification, notificationStreamBuffer)
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[chan *domain.Notification]struct{})
+	}
+	s.subscribers[userID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers[userID], ch)
+			if len(s.subscribers[userID]) == 0 {
+				delete(s.subscribers, userID)
+			}
+			s.mu.Unlock()
+		})
+	}
+}
+
+// closeSubscribers ends every open stream, e.g. so that the server can shut down
+func (s *notificationService) closeSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for userID, channels := range s.subscribers {
+		for ch := range channels {
+			close(ch)
+		}
+		delete(s.subscribers, userID)
+	}
+}
+
+// hasSubscribers reports whether the user has a stream open on this replica
+func (s *notificationService) hasSubscribers(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers[userID]) > 0
+}
+
+// deliver sends a notification to the user's streams without blocking on slow readers
+func (s *notificationService) deliver(notification *domain.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers[notification.UserID] {
+		select {
+		case ch <- notification:
+		default:
+			logger.Warn("Notification stream is full, dropping notification",
+				"user_id", notification.UserID,
+				"notification_id", notification.NotificationID)
+		}
+	}
+}
+
+// handleEvent loads an announced notification when one of its user's streams is open here
+func (s *notificationService) handleEvent(ctx context.Context, event domain.NotificationEvent) {
+	if !s.hasSubscribers(event.UserID) {
+		return
+	}
+
+	notification, err := s.notificationRepo.GetNotificationByID(ctx, event.NotificationID)
+	if err != nil {
+		if err != domain.ErrNotFound {
+			logger.Error("Failed to load notification", "notification_id", event.NotificationID, "error", err)
+		}
+		return
+	}
+	s.deliver(notification)
+}
+
+// Run keeps a LISTEN connection open until ctx is cancelled
+func (s *notificationService) Run(ctx context.Context) {
+	logger.Info("Starting notification listener", "channel", domain.NotificationChannel)
+	defer s.closeSubscribers()
+
+	backoff := notificationListenMinBackoff
+	for {
+		started := time.Now()
+		err := s.notificationRepo.Listen(ctx, func(event domain.NotificationEvent) {
+			s.handleEvent(ctx, event)
+		})
+		if ctx.Err() != nil {
+			logger.Info("Notification listener stopped")
+			return
+		}
+
+		// A connection that stayed up for a while was healthy, so retry quickly
+		if time.Since(started) > notificationListenMaxBackoff {
+			backoff = notificationListenMinBackoff
+		}
+		logger.Error("Notification listener disconnected", "error", err, "retry_in", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			logger.Info("Notification listener stopped")
+			return
+		}
+		if backoff *= 2; backoff > notificationListenMaxBackoff {
+			backoff = notificationListenMaxBackoff
+		}
+	}
+}
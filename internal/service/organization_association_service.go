@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
 	"github.com/affiliate-backend/internal/repository"
 	"github.com/google/uuid"
 )
@@ -51,6 +52,7 @@ type organizationAssociationService struct {
 	profileRepo     repository.ProfileRepository
 	affiliateRepo   repository.AffiliateRepository
 	campaignRepo    repository.CampaignRepository
+	notifier        NotificationService
 }
 
 // NewOrganizationAssociationService creates a new organization association service
@@ -60,6 +62,7 @@ func NewOrganizationAssociationService(
 	profileRepo repository.ProfileRepository,
 	affiliateRepo repository.AffiliateRepository,
 	campaignRepo repository.CampaignRepository,
+	notifier NotificationService,
 ) OrganizationAssociationService {
 	return &organizationAssociationService{
 		associationRepo: associationRepo,
@@ -67,6 +70,7 @@ func NewOrganizationAssociationService(
 		profileRepo:     profileRepo,
 		affiliateRepo:   affiliateRepo,
 		campaignRepo:    campaignRepo,
+		notifier:        notifier,
 	}
 }
 
@@ -139,6 +143,8 @@ func (s *organizationAssociationService) CreateInvitation(ctx context.Context, r
 		return nil, fmt.Errorf("error creating invitation: %w", err)
 	}
 
+	s.notifyAssociation(ctx, association, req.AffiliateOrgID, fmt.Sprintf("%s invited you to partner with them", advOrg.Name))
+
 	return association, nil
 }
 
@@ -211,9 +217,30 @@ func (s *organizationAssociationService) CreateRequest(ctx context.Context, req
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
+	s.notifyAssociation(ctx, association, req.AdvertiserOrgID, fmt.Sprintf("%s asked to partner with you", affOrg.Name))
+
 	return association, nil
 }
 
+// notifyAssociation tells the users of the organization that has to answer a new association
+func (s *organizationAssociationService) notifyAssociation(ctx context.Context, association *domain.OrganizationAssociation, recipientOrgID int64, title string) {
+	notification := &domain.NewNotification{
+		OrganizationID: recipientOrgID,
+		Type:           domain.NotificationTypeAssociationRequest,
+		Title:          title,
+		Data: map[string]interface{}{
+			"association_id":    association.AssociationID,
+			"association_type":  association.AssociationType,
+			"advertiser_org_id": association.AdvertiserOrgID,
+			"affiliate_org_id":  association.AffiliateOrgID,
+		},
+	}
+	if association.Message != nil {
+		notification.Body = notificationPreview(*association.Message)
+	}
+	notify(ctx, s.notifier, notification)
+}
+
 // ApproveAssociation approves a pending association
 func (s *organizationAssociationService) ApproveAssociation(ctx context.Context, associationID int64, approvedByUserID string) (*domain.OrganizationAssociation, error) {
 	association, err := s.associationRepo.GetAssociationByID(ctx, associationID)
@@ -260,7 +287,7 @@ func (s *organizationAssociationService) RejectAssociation(ctx context.Context,
 	return association, nil
 }
 
-// SuspendAssociation suspends an active association
+// SuspendAssociation suspends an active association and pauses its tracking links
 func (s *organizationAssociationService) SuspendAssociation(ctx context.Context, associationID int64, suspendedByUserID string) (*domain.OrganizationAssociation, error) {
 	association, err := s.associationRepo.GetAssociationByID(ctx, associationID)
 	if err != nil {
@@ -271,17 +298,21 @@ func (s *organizationAssociationService) SuspendAssociation(ctx context.Context,
 		return nil, fmt.Errorf("association cannot be suspended in current status: %s", association.Status)
 	}
 
-	association.Status = domain.AssociationStatusSuspended
 	association.ApprovedByUserID = &suspendedByUserID
 
-	if err := s.associationRepo.UpdateAssociation(ctx, association); err != nil {
+	// The links the association covered are paused with it; they resume when it is reactivated
+	paused, err := s.associationRepo.SuspendAssociation(ctx, association)
+	if err != nil {
 		return nil, fmt.Errorf("error suspending association: %w", err)
 	}
+	if paused > 0 {
+		logger.Info("Paused tracking links of suspended association", "association_id", associationID, "count", paused)
+	}
 
 	return association, nil
 }
 
-// ReactivateAssociation reactivates a suspended association
+// ReactivateAssociation reactivates a suspended association and resumes its tracking links
 func (s *organizationAssociationService) ReactivateAssociation(ctx context.Context, associationID int64, reactivatedByUserID string) (*domain.OrganizationAssociation, error) {
 	association, err := s.associationRepo.GetAssociationByID(ctx, associationID)
 	if err != nil {
@@ -292,12 +323,16 @@ func (s *organizationAssociationService) ReactivateAssociation(ctx context.Conte
 		return nil, fmt.Errorf("association cannot be reactivated in current status: %s", association.Status)
 	}
 
-	association.Status = domain.AssociationStatusActive
 	association.ApprovedByUserID = &reactivatedByUserID
 
-	if err := s.associationRepo.UpdateAssociation(ctx, association); err != nil {
+	// The links paused by the suspension resume with it; links paused by users stay paused
+	resumed, err := s.associationRepo.ReactivateAssociation(ctx, association, time.Now())
+	if err != nil {
 		return nil, fmt.Errorf("error reactivating association: %w", err)
 	}
+	if resumed > 0 {
+		logger.Info("Resumed tracking links of reactivated association", "association_id", associationID, "count", resumed)
+	}
 
 	return association, nil
 }
@@ -0,0 +1,501 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// OutreachSequenceService defines the interface for automated publisher outreach
+type OutreachSequenceService interface {
+	CreateSequence(ctx context.Context, organizationID int64, userID *string, req *domain.CreateOutreachSequenceRequest) (*domain.OutreachSequence, error)
+	// GetSequence returns a sequence of the organization with its steps and reply statistics
+	GetSequence(ctx context.Context, organizationID, sequenceID int64) (*domain.OutreachSequence, error)
+	ListSequences(ctx context.Context, organizationID int64) ([]*domain.OutreachSequence, error)
+	UpdateSequence(ctx context.Context, organizationID, sequenceID int64, req *domain.UpdateOutreachSequenceRequest) (*domain.OutreachSequence, error)
+	DeleteSequence(ctx context.Context, organizationID, sequenceID int64) error
+
+	// LaunchSequence creates a conversation for each eligible publisher of a favorite list and
+	// schedules the first touch
+	LaunchSequence(ctx context.Context, organizationID, sequenceID int64, req *domain.LaunchOutreachSequenceRequest) (*domain.LaunchOutreachSequenceResult, error)
+	ListEnrollments(ctx context.Context, organizationID, sequenceID int64, filter *domain.OutreachEnrollmentFilter) (*domain.OutreachEnrollmentList, error)
+
+	// RunDueSends stops enrollments whose publisher replied, then sends the steps that are due
+	// and returns how many were sent
+	RunDueSends(ctx context.Context, now time.Time) (int, error)
+}
+
+// outreachSequenceService implements OutreachSequenceService
+type outreachSequenceService struct {
+	sequenceRepo     repository.OutreachSequenceRepository
+	favoriteListRepo repository.FavoritePublisherListRepository
+	pipelineRepo     repository.PublisherPipelineRepository
+	messagingRepo    repository.PublisherMessagingRepository
+	analyticsRepo    repository.AnalyticsRepository
+	orgRepo          repository.OrganizationRepository
+	messagingService PublisherMessagingService
+}
+
+// NewOutreachSequenceService creates a new outreach sequence service. Steps are sent through
+// messagingService, so they are emailed like messages written by hand.
+func NewOutreachSequenceService(
+	sequenceRepo repository.OutreachSequenceRepository,
+	favoriteListRepo repository.FavoritePublisherListRepository,
+	pipelineRepo repository.PublisherPipelineRepository,
+	messagingRepo repository.PublisherMessagingRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	orgRepo repository.OrganizationRepository,
+	messagingService PublisherMessagingService,
+) OutreachSequenceService {
+	return &outreachSequenceService{
+		sequenceRepo:     sequenceRepo,
+		favoriteListRepo: favoriteListRepo,
+		pipelineRepo:     pipelineRepo,
+		messagingRepo:    messagingRepo,
+		analyticsRepo:    analyticsRepo,
+		orgRepo:          orgRepo,
+		messagingService: messagingService,
+	}
+}
+
+// renderOutreachMessage renders a sequence subject and step body with the merge fields
+func renderOutreachMessage(subjectTemplate, bodyTemplate string, fields domain.MessageMergeFields) (string, string, error) {
+	tmpl, err := email.ParseTemplate("outreach", subjectTemplate, bodyTemplate)
+	if err != nil {
+		return "", "", err
+	}
+	return tmpl.Render(fields)
+}
+
+// validateOutreachTemplates renders every step with sample merge fields, so unknown fields and
+// syntax errors are reported when the sequence is saved rather than when it sends
+func validateOutreachTemplates(subjectTemplate string, steps []domain.OutreachSequenceStepInput) error {
+	sample := domain.SampleMessageMergeFields()
+	for i, step := range steps {
+		if _, _, err := renderOutreachMessage(subjectTemplate, step.BodyTemplate, sample); err != nil {
+			return fmt.Errorf("%w: step %d: %v", domain.ErrInvalidInput, i+1, err)
+		}
+	}
+	return nil
+}
+
+func outreachSteps(inputs []domain.OutreachSequenceStepInput) []*domain.OutreachSequenceStep {
+	steps := make([]*domain.OutreachSequenceStep, len(inputs))
+	for i, input := range inputs {
+		steps[i] = &domain.OutreachSequenceStep{
+			DelayDays:    input.DelayDays,
+			BodyTemplate: strings.TrimSpace(input.BodyTemplate),
+		}
+	}
+	return steps
+}
+
+func (s *outreachSequenceService) getOwnedSequence(ctx context.Context, organizationID, sequenceID int64) (*domain.OutreachSequence, error) {
+	sequence, err := s.sequenceRepo.GetSequenceByID(ctx, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+	if sequence.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound // Don't reveal sequences of other organizations
+	}
+	return sequence, nil
+}
+
+// CreateSequence creates an active sequence
+func (s *outreachSequenceService) CreateSequence(ctx context.Context, organizationID int64, userID *string, req *domain.CreateOutreachSequenceRequest) (*domain.OutreachSequence, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	subject := strings.TrimSpace(req.SubjectTemplate)
+	if err := validateOutreachTemplates(subject, req.Steps); err != nil {
+		return nil, err
+	}
+
+	sequence := &domain.OutreachSequence{
+		OrganizationID:  organizationID,
+		Name:            strings.TrimSpace(req.Name),
+		SubjectTemplate: subject,
+		Status:          domain.OutreachSequenceStatusActive,
+		CreatedByUserID: userID,
+		Steps:           outreachSteps(req.Steps),
+	}
+	if err := s.sequenceRepo.CreateSequence(ctx, sequence); err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+// GetSequence returns a sequence with its statistics
+func (s *outreachSequenceService) GetSequence(ctx context.Context, organizationID, sequenceID int64) (*domain.OutreachSequence, error) {
+	sequence, err := s.getOwnedSequence(ctx, organizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.sequenceRepo.GetSequenceStats(ctx, sequenceID, len(sequence.Steps))
+	if err != nil {
+		return nil, err
+	}
+	stats.ComputeRates()
+	sequence.Stats = stats
+
+	return sequence, nil
+}
+
+// ListSequences lists the sequences of the organization
+func (s *outreachSequenceService) ListSequences(ctx context.Context, organizationID int64) ([]*domain.OutreachSequence, error) {
+	return s.sequenceRepo.ListSequences(ctx, organizationID)
+}
+
+// UpdateSequence updates a sequence; new steps apply to the steps enrollments have not been sent yet
+func (s *outreachSequenceService) UpdateSequence(ctx context.Context, organizationID, sequenceID int64, req *domain.UpdateOutreachSequenceRequest) (*domain.OutreachSequence, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	sequence, err := s.getOwnedSequence(ctx, organizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		sequence.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.SubjectTemplate != nil {
+		sequence.SubjectTemplate = strings.TrimSpace(*req.SubjectTemplate)
+	}
+	if req.Status != nil {
+		sequence.Status = *req.Status
+	}
+
+	// The subject and the steps are checked together, as either may change
+	steps := req.Steps
+	if steps == nil {
+		steps = make([]domain.OutreachSequenceStepInput, len(sequence.Steps))
+		for i, step := range sequence.Steps {
+			steps[i] = domain.OutreachSequenceStepInput{DelayDays: step.DelayDays, BodyTemplate: step.BodyTemplate}
+		}
+	}
+	if err := validateOutreachTemplates(sequence.SubjectTemplate, steps); err != nil {
+		return nil, err
+	}
+	if req.Steps != nil {
+		sequence.Steps = outreachSteps(req.Steps)
+	}
+
+	if err := s.sequenceRepo.UpdateSequence(ctx, sequence, req.Steps != nil); err != nil {
+		return nil, err
+	}
+	return sequence, nil
+}
+
+// DeleteSequence deletes a sequence and stops its enrollments; their conversations are kept
+func (s *outreachSequenceService) DeleteSequence(ctx context.Context, organizationID, sequenceID int64) error {
+	if _, err := s.getOwnedSequence(ctx, organizationID, sequenceID); err != nil {
+		return err
+	}
+	return s.sequenceRepo.DeleteSequence(ctx, sequenceID)
+}
+
+// LaunchSequence enrolls the publishers of a list. Publishers already enrolled, accepted or in
+// a won or lost pipeline stage, already in an active conversation or without a contact email are skipped.
+func (s *outreachSequenceService) LaunchSequence(ctx context.Context, organizationID, sequenceID int64, req *domain.LaunchOutreachSequenceRequest) (*domain.LaunchOutreachSequenceResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	sequence, err := s.getOwnedSequence(ctx, organizationID, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.favoriteListRepo.GetListByID(ctx, req.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if list.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+
+	items, err := s.favoriteListRepo.GetListItems(ctx, req.ListID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list items: %w", err)
+	}
+	enrolled, err := s.sequenceRepo.GetEnrolledDomains(ctx, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := s.pipelineRepo.ListStages(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	closedStages := make(map[int64]bool)
+	for _, stage := range stages {
+		if stage.Outcome != domain.PipelineStageOutcomeOpen {
+			closedStages[stage.StageID] = true
+		}
+	}
+
+	result := &domain.LaunchOutreachSequenceResult{
+		Enrollments: make([]*domain.OutreachEnrollment, 0),
+		Skipped:     make([]domain.OutreachLaunchSkip, 0),
+	}
+
+	// Restrict the launch to the requested publishers, reporting those not in the list
+	var selected map[string]bool
+	if len(req.PublisherDomains) > 0 {
+		selected = make(map[string]bool)
+		for _, publisherDomain := range req.PublisherDomains {
+			if normalized := domain.NormalizePublisherDomain(publisherDomain); normalized != "" {
+				selected[normalized] = true
+			}
+		}
+		inList := make(map[string]bool, len(items))
+		for _, item := range items {
+			inList[item.PublisherDomain] = true
+		}
+		for publisherDomain := range selected {
+			if !inList[publisherDomain] {
+				result.Skipped = append(result.Skipped, domain.OutreachLaunchSkip{PublisherDomain: publisherDomain, Reason: domain.OutreachSkipNotInList})
+			}
+		}
+	}
+
+	startAt := time.Now()
+	if req.StartAt != nil && req.StartAt.After(startAt) {
+		startAt = *req.StartAt
+	}
+
+	organizationName := ""
+	if org, err := s.orgRepo.GetOrganizationByID(ctx, organizationID); err == nil {
+		organizationName = org.Name
+	}
+
+	for _, item := range items {
+		if selected != nil && !selected[item.PublisherDomain] {
+			continue
+		}
+
+		reason, err := s.launchSkipReason(ctx, organizationID, item, enrolled, closedStages)
+		if err != nil {
+			return nil, err
+		}
+		var fields domain.MessageMergeFields
+		if reason == "" {
+			fields, err = loadMessageMergeFields(ctx, s.analyticsRepo, item.PublisherDomain, organizationName, list, item)
+			if err != nil {
+				return nil, err
+			}
+			if fields.ContactEmail == "" {
+				reason = domain.OutreachSkipNoContactEmail
+			}
+		}
+		if reason != "" {
+			result.Skipped = append(result.Skipped, domain.OutreachLaunchSkip{PublisherDomain: item.PublisherDomain, Reason: reason})
+			continue
+		}
+
+		subject, _, err := renderOutreachMessage(sequence.SubjectTemplate, sequence.Steps[0].BodyTemplate, fields)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to render subject for %s: %v", domain.ErrInvalidInput, item.PublisherDomain, err)
+		}
+
+		conversation := &domain.PublisherConversation{
+			OrganizationID:  organizationID,
+			PublisherDomain: item.PublisherDomain,
+			ListID:          &list.ListID,
+			Subject:         subject,
+			Status:          domain.ConversationStatusActive,
+		}
+		if err := s.messagingRepo.CreateConversation(ctx, conversation); err != nil {
+			return nil, fmt.Errorf("failed to create conversation: %w", err)
+		}
+
+		enrollment := &domain.OutreachEnrollment{
+			SequenceID:      sequenceID,
+			OrganizationID:  organizationID,
+			ListID:          &list.ListID,
+			PublisherDomain: item.PublisherDomain,
+			ConversationID:  &conversation.ConversationID,
+			Status:          domain.OutreachEnrollmentStatusScheduled,
+			NextSendAt:      &startAt,
+		}
+		if err := s.sequenceRepo.CreateEnrollment(ctx, enrollment); err != nil {
+			return nil, err
+		}
+		enrolled[item.PublisherDomain] = true
+
+		result.Enrollments = append(result.Enrollments, enrollment)
+		result.Enrolled++
+	}
+
+	logger.Info("Outreach sequence launched",
+		"sequence_id", sequenceID,
+		"list_id", list.ListID,
+		"enrolled", result.Enrolled,
+		"skipped", len(result.Skipped))
+
+	return result, nil
+}
+
+// launchSkipReason returns why a list item cannot be enrolled, or "" when it can
+func (s *outreachSequenceService) launchSkipReason(ctx context.Context, organizationID int64, item *domain.FavoritePublisherListItem, enrolled map[string]bool, closedStages map[int64]bool) (string, error) {
+	if enrolled[item.PublisherDomain] {
+		return domain.OutreachSkipAlreadyEnrolled, nil
+	}
+	if item.Status == domain.PublisherStatusAccepted || (item.StageID != nil && closedStages[*item.StageID]) {
+		return domain.OutreachSkipClosedStatus, nil
+	}
+
+	_, err := s.messagingRepo.GetConversationByPublisher(ctx, organizationID, item.PublisherDomain, domain.ConversationStatusActive)
+	if err == nil {
+		return domain.OutreachSkipActiveConversation, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return "", fmt.Errorf("failed to check existing conversation: %w", err)
+	}
+	return "", nil
+}
+
+// ListEnrollments lists the enrollments of a sequence
+func (s *outreachSequenceService) ListEnrollments(ctx context.Context, organizationID, sequenceID int64, filter *domain.OutreachEnrollmentFilter) (*domain.OutreachEnrollmentList, error) {
+	if err := filter.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.getOwnedSequence(ctx, organizationID, sequenceID); err != nil {
+		return nil, err
+	}
+
+	enrollments, total, err := s.sequenceRepo.ListEnrollments(ctx, sequenceID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OutreachEnrollmentList{Enrollments: enrollments, Total: total}, nil
+}
+
+// RunDueSends claims and sends the steps that are due. Each step is claimed before it is sent,
+// so several API instances can run the scheduler safely.
+func (s *outreachSequenceService) RunDueSends(ctx context.Context, now time.Time) (int, error) {
+	if replied, err := s.sequenceRepo.MarkRepliedEnrollments(ctx); err != nil {
+		return 0, err
+	} else if replied > 0 {
+		logger.Info("Outreach enrollments stopped by publisher replies", "count", replied)
+	}
+	if _, err := s.sequenceRepo.StopClosedEnrollments(ctx); err != nil {
+		return 0, err
+	}
+
+	enrollments, err := s.sequenceRepo.ListDueEnrollments(ctx, now, domain.OutreachSendBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sequences := make(map[int64]*domain.OutreachSequence)
+	sent := 0
+	for _, enrollment := range enrollments {
+		sequence, ok := sequences[enrollment.SequenceID]
+		if !ok {
+			sequence, err = s.sequenceRepo.GetSequenceByID(ctx, enrollment.SequenceID)
+			if err != nil {
+				return sent, err
+			}
+			sequences[enrollment.SequenceID] = sequence
+		}
+
+		step := sequence.NextStep(enrollment.StepsSent)
+		if step == nil {
+			// Steps were removed after the enrollment was sent its last remaining one
+			if err := s.sequenceRepo.FinishEnrollment(ctx, enrollment.EnrollmentID, domain.OutreachEnrollmentStatusCompleted, nil); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		var nextSendAt *time.Time
+		if next := sequence.NextStep(enrollment.StepsSent + 1); next != nil {
+			at := now.AddDate(0, 0, next.DelayDays)
+			nextSendAt = &at
+		}
+
+		claimed, err := s.sequenceRepo.ClaimEnrollmentStep(ctx, enrollment.EnrollmentID, enrollment.StepsSent, now, nextSendAt)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.sendStep(ctx, sequence, enrollment, step); err != nil {
+			logger.Error("Failed to send outreach step",
+				"enrollment_id", enrollment.EnrollmentID,
+				"step", step.StepNumber,
+				"error", err)
+			reason := err.Error()
+			if err := s.sequenceRepo.FinishEnrollment(ctx, enrollment.EnrollmentID, domain.OutreachEnrollmentStatusFailed, &reason); err != nil {
+				logger.Error("Failed to record outreach step failure", "enrollment_id", enrollment.EnrollmentID, "error", err)
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// sendStep renders a step for the enrollment's publisher and sends it in its conversation
+func (s *outreachSequenceService) sendStep(ctx context.Context, sequence *domain.OutreachSequence, enrollment *domain.OutreachEnrollment, step *domain.OutreachSequenceStep) error {
+	conversation, err := s.messagingRepo.GetConversationByID(ctx, *enrollment.ConversationID)
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	organizationName := ""
+	if org, err := s.orgRepo.GetOrganizationByID(ctx, enrollment.OrganizationID); err == nil {
+		organizationName = org.Name
+	}
+	var list *domain.FavoritePublisherList
+	var item *domain.FavoritePublisherListItem
+	if enrollment.ListID != nil {
+		if list, err = s.favoriteListRepo.GetListByID(ctx, *enrollment.ListID); err != nil {
+			list = nil
+		} else if item, err = s.favoriteListRepo.GetPublisherFromList(ctx, list.ListID, enrollment.PublisherDomain); err != nil {
+			item = nil
+		}
+	}
+
+	fields, err := loadMessageMergeFields(ctx, s.analyticsRepo, enrollment.PublisherDomain, organizationName, list, item)
+	if err != nil {
+		return err
+	}
+	_, body, err := renderOutreachMessage(sequence.SubjectTemplate, step.BodyTemplate, fields)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]interface{}{
+		domain.MessageMetadataOutreach: map[string]interface{}{
+			"sequence_id":   sequence.SequenceID,
+			"enrollment_id": enrollment.EnrollmentID,
+			"step":          step.StepNumber,
+		},
+	}
+	message, err := s.messagingService.SendAutomatedMessage(ctx, conversation, body, step.StepNumber > 1, metadata)
+	if err != nil {
+		return err
+	}
+
+	// Without an email transport messages are only stored, which counts as sent
+	delivery, _ := message.Metadata[domain.MessageMetadataEmailDelivery].(map[string]interface{})
+	switch delivery["status"] {
+	case domain.EmailDeliveryStatusFailed, domain.EmailDeliveryStatusSkipped:
+		return fmt.Errorf("email delivery %v: %v", delivery["status"], delivery["error"])
+	}
+	return nil
+}
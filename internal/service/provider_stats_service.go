@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/provider"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// ProviderStatsService defines the interface for importing provider reporting data into the local warehouse
+type ProviderStatsService interface {
+	// ImportDay imports (or re-imports) a single day of provider stats
+	ImportDay(ctx context.Context, date time.Time, triggeredBy string) (*domain.ProviderStatsImport, error)
+	// Backfill imports every day in the requested range, continuing past failed days
+	Backfill(ctx context.Context, req *domain.ProviderStatsBackfillRequest) (*domain.ProviderStatsBackfillResponse, error)
+	// ListImports lists previous import runs
+	ListImports(ctx context.Context, page, pageSize int) ([]*domain.ProviderStatsImport, int, error)
+}
+
+// providerStatsService implements ProviderStatsService
+type providerStatsService struct {
+	statsRepo        repository.ProviderStatsRepository
+	reportingService provider.ReportingService
+}
+
+// NewProviderStatsService creates a new provider stats service
+func NewProviderStatsService(
+	statsRepo repository.ProviderStatsRepository,
+	reportingService provider.ReportingService,
+) ProviderStatsService {
+	return &providerStatsService{
+		statsRepo:        statsRepo,
+		reportingService: reportingService,
+	}
+}
+
+// ImportDay fetches one day of stats from the provider and replaces the stored facts for that day
+func (s *providerStatsService) ImportDay(ctx context.Context, date time.Time, triggeredBy string) (*domain.ProviderStatsImport, error) {
+	statDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	providerType := s.reportingService.ProviderType()
+
+	imp := &domain.ProviderStatsImport{
+		ProviderType: providerType,
+		StatDate:     statDate,
+		Status:       domain.ProviderStatsImportStatusRunning,
+		TriggeredBy:  triggeredBy,
+		StartedAt:    time.Now(),
+	}
+	if err := s.statsRepo.CreateImport(ctx, imp); err != nil {
+		return nil, fmt.Errorf("failed to record import start: %w", err)
+	}
+
+	stats, err := s.reportingService.FetchDailyStats(ctx, statDate)
+	if err == nil {
+		imp.RowCount, err = s.statsRepo.ReplaceDailyStats(ctx, providerType, statDate, stats)
+	}
+
+	completedAt := time.Now()
+	imp.CompletedAt = &completedAt
+	if err != nil {
+		errMsg := err.Error()
+		imp.Status = domain.ProviderStatsImportStatusFailed
+		imp.ErrorMessage = &errMsg
+	} else {
+		imp.Status = domain.ProviderStatsImportStatusCompleted
+	}
+
+	if updateErr := s.statsRepo.UpdateImport(ctx, imp); updateErr != nil {
+		logger.Error("Failed to record provider stats import result", "import_id", imp.ImportID, "error", updateErr)
+	}
+
+	if err != nil {
+		return imp, fmt.Errorf("failed to import provider stats for %s: %w", statDate.Format("2006-01-02"), err)
+	}
+
+	logger.Info("Imported provider stats",
+		"provider", providerType,
+		"date", statDate.Format("2006-01-02"),
+		"rows", imp.RowCount)
+
+	return imp, nil
+}
+
+// Backfill imports each day of the requested range in order
+func (s *providerStatsService) Backfill(ctx context.Context, req *domain.ProviderStatsBackfillRequest) (*domain.ProviderStatsBackfillResponse, error) {
+	from, to, err := req.ParseRange(time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, err.Error())
+	}
+
+	response := &domain.ProviderStatsBackfillResponse{
+		Imports: make([]*domain.ProviderStatsImport, 0),
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		imp, err := s.ImportDay(ctx, day, domain.ProviderStatsImportTriggerBackfill)
+		if imp == nil {
+			// The run could not even be recorded; stop instead of silently skipping days
+			return nil, err
+		}
+		if err != nil {
+			logger.Warn("Provider stats backfill day failed", "date", day.Format("2006-01-02"), "error", err)
+			response.Failed++
+		} else {
+			response.Completed++
+		}
+		response.Imports = append(response.Imports, imp)
+	}
+
+	return response, nil
+}
+
+// ListImports lists previous import runs for the configured provider
+func (s *providerStatsService) ListImports(ctx context.Context, page, pageSize int) ([]*domain.ProviderStatsImport, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return s.statsRepo.ListImports(ctx, s.reportingService.ProviderType(), pageSize, (page-1)*pageSize)
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// PublisherConversionService defines the interface for onboarding discovered publishers as affiliates
+type PublisherConversionService interface {
+	// ConvertPublisher creates or links the affiliate organization of an accepted list item and
+	// invites it to associate with the organization
+	ConvertPublisher(ctx context.Context, organizationID, listID int64, publisherDomain, userID string, req *domain.ConvertPublisherRequest) (*domain.PublisherConversion, error)
+	GetConversion(ctx context.Context, organizationID, conversionID int64) (*domain.PublisherConversion, error)
+	ListConversions(ctx context.Context, organizationID int64, filter *domain.PublisherConversionFilter) (*domain.PublisherConversionList, error)
+	// GetFunnel defaults to the last DefaultPublisherConversionFunnelDays days when from or to is nil
+	GetFunnel(ctx context.Context, organizationID int64, from, to *time.Time) (*domain.PublisherConversionFunnel, error)
+	// RefreshProgress records the association and first tracking link of every open conversion
+	RefreshProgress(ctx context.Context) error
+}
+
+// publisherConversionService implements PublisherConversionService
+type publisherConversionService struct {
+	conversionRepo    repository.PublisherConversionRepository
+	favoriteListRepo  repository.FavoritePublisherListRepository
+	analyticsRepo     repository.AnalyticsRepository
+	affiliateRepo     repository.AffiliateRepository
+	associationRepo   repository.OrganizationAssociationRepository
+	orgService        OrganizationService
+	affiliateService  AffiliateService
+	invitationService AdvertiserAssociationInvitationService
+}
+
+// NewPublisherConversionService creates a new publisher conversion service
+func NewPublisherConversionService(
+	conversionRepo repository.PublisherConversionRepository,
+	favoriteListRepo repository.FavoritePublisherListRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	affiliateRepo repository.AffiliateRepository,
+	associationRepo repository.OrganizationAssociationRepository,
+	orgService OrganizationService,
+	affiliateService AffiliateService,
+	invitationService AdvertiserAssociationInvitationService,
+) PublisherConversionService {
+	return &publisherConversionService{
+		conversionRepo:    conversionRepo,
+		favoriteListRepo:  favoriteListRepo,
+		analyticsRepo:     analyticsRepo,
+		affiliateRepo:     affiliateRepo,
+		associationRepo:   associationRepo,
+		orgService:        orgService,
+		affiliateService:  affiliateService,
+		invitationService: invitationService,
+	}
+}
+
+// ConvertPublisher onboards an accepted list item as an affiliate
+func (s *publisherConversionService) ConvertPublisher(ctx context.Context, organizationID, listID int64, publisherDomain, userID string, req *domain.ConvertPublisherRequest) (*domain.PublisherConversion, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	org, err := s.orgService.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if org.Type != domain.OrganizationTypeAdvertiser {
+		return nil, fmt.Errorf("%w: only advertiser organizations can convert publishers", domain.ErrInvalidInput)
+	}
+
+	list, err := s.favoriteListRepo.GetListByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if list.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+
+	item, err := s.favoriteListRepo.GetPublisherFromList(ctx, listID, publisherDomain)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != domain.PublisherStatusAccepted {
+		return nil, fmt.Errorf("%w: publisher %s has status %s, only accepted publishers can be converted", domain.ErrInvalidInput, publisherDomain, item.Status)
+	}
+
+	// The conversion row is claimed before anything is set up, so that concurrent conversions of
+	// the publisher cannot both create an affiliate organization. A claim left by a failed attempt
+	// is resumed with what that attempt already set up.
+	conversion := &domain.PublisherConversion{
+		OrganizationID:  organizationID,
+		PublisherDomain: publisherDomain,
+		ListID:          &listID,
+		ItemID:          &item.ItemID,
+		CreatedByUserID: &userID,
+	}
+	claimed, err := s.conversionRepo.ClaimConversion(ctx, conversion)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("%w: publisher %s has already been converted or is being converted", domain.ErrInvalidInput, publisherDomain)
+	}
+
+	result, err := s.setUpConversion(ctx, conversion, userID, req)
+	if err != nil {
+		if releaseErr := s.conversionRepo.ReleaseConversion(context.Background(), conversion.ConversionID); releaseErr != nil {
+			logger.Warn("Failed to release publisher conversion", "conversion_id", conversion.ConversionID, "error", releaseErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// setUpConversion creates or links the affiliate organization, affiliate and invitation of a
+// claimed conversion, recording each step on the claim, and completes it
+func (s *publisherConversionService) setUpConversion(ctx context.Context, conversion *domain.PublisherConversion, userID string, req *domain.ConvertPublisherRequest) (*domain.PublisherConversion, error) {
+	organizationID, publisherDomain := conversion.OrganizationID, conversion.PublisherDomain
+
+	// Contact details are prefilled from the analytics publisher
+	publisher, err := s.analyticsRepo.GetPublisherByDomain(ctx, publisherDomain)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get publisher: %w", err)
+	}
+	contactEmail := s.contactEmail(req, publisher)
+
+	var affiliateOrg *domain.Organization
+	if conversion.AffiliateOrgID != 0 {
+		if affiliateOrg, err = s.orgService.GetOrganizationByID(ctx, conversion.AffiliateOrgID); err != nil {
+			return nil, err
+		}
+	} else {
+		if affiliateOrg, err = s.resolveAffiliateOrganization(ctx, publisherDomain, req, publisher); err != nil {
+			return nil, err
+		}
+		conversion.AffiliateOrgID = affiliateOrg.OrganizationID
+		if err := s.conversionRepo.RecordClaimProgress(ctx, conversion); err != nil {
+			return nil, err
+		}
+	}
+
+	affiliate, err := s.resolveAffiliate(ctx, affiliateOrg, contactEmail)
+	if err != nil {
+		return nil, err
+	}
+	if affiliate != nil {
+		conversion.AffiliateID = &affiliate.AffiliateID
+	}
+
+	// An organization that is already associated, or has asked to be, does not need an invitation
+	var invitation *domain.AdvertiserAssociationInvitation
+	if conversion.InvitationID != nil {
+		if invitation, err = s.invitationService.GetInvitationByID(ctx, *conversion.InvitationID); err != nil {
+			return nil, err
+		}
+	} else {
+		association, err := s.associationRepo.GetAssociationByOrganizations(ctx, organizationID, affiliateOrg.OrganizationID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		if association == nil || association.Status == domain.AssociationStatusRejected {
+			invitation, err = s.createInvitation(ctx, organizationID, affiliateOrg.OrganizationID, publisherDomain, userID, req)
+			if err != nil {
+				return nil, err
+			}
+			conversion.InvitationID = &invitation.InvitationID
+			if err := s.conversionRepo.RecordClaimProgress(ctx, conversion); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	conversion.ContactEmail = contactEmail
+	conversion.Status = domain.PublisherConversionStatusInvited
+	if err := s.conversionRepo.CompleteConversion(ctx, conversion); err != nil {
+		return nil, err
+	}
+
+	// An existing association may already be active and have tracking links
+	if err := s.conversionRepo.RefreshProgress(ctx, &organizationID); err != nil {
+		logger.Warn("Failed to refresh publisher conversion progress", "conversion_id", conversion.ConversionID, "error", err)
+	} else if refreshed, err := s.conversionRepo.GetConversionByID(ctx, conversion.ConversionID); err == nil {
+		conversion = refreshed
+	}
+
+	conversion.Invitation = invitation
+	conversion.AffiliateOrganization = affiliateOrg
+	conversion.Affiliate = affiliate
+
+	return conversion, nil
+}
+
+func (s *publisherConversionService) contactEmail(req *domain.ConvertPublisherRequest, publisher *domain.AnalyticsPublisher) *string {
+	if req.ContactEmail != nil {
+		email := strings.TrimSpace(*req.ContactEmail)
+		return &email
+	}
+	if publisher == nil {
+		return nil
+	}
+	email, err := publisher.PrimaryContactEmail()
+	if err != nil {
+		logger.Warn("Failed to read publisher contact emails", "domain", publisher.Domain, "error", err)
+		return nil
+	}
+	if email == "" {
+		return nil
+	}
+	return &email
+}
+
+// resolveAffiliateOrganization returns the requested affiliate organization, the one whose website is
+// on the publisher domain, or a new one
+func (s *publisherConversionService) resolveAffiliateOrganization(ctx context.Context, publisherDomain string, req *domain.ConvertPublisherRequest, publisher *domain.AnalyticsPublisher) (*domain.Organization, error) {
+	orgID := int64(0)
+	if req.AffiliateOrgID != nil {
+		orgID = *req.AffiliateOrgID
+	} else if req.OrganizationName == nil {
+		found, err := s.conversionRepo.FindAffiliateOrganizationByDomain(ctx, publisherDomain)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		orgID = found
+	}
+
+	if orgID != 0 {
+		org, err := s.orgService.GetOrganizationByID(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: affiliate organization %d not found", domain.ErrInvalidInput, orgID)
+			}
+			return nil, err
+		}
+		if org.Type != domain.OrganizationTypeAffiliate {
+			return nil, fmt.Errorf("%w: organization %d is not an affiliate organization", domain.ErrInvalidInput, orgID)
+		}
+		return org, nil
+	}
+
+	name := publisherDomain
+	if req.OrganizationName != nil {
+		name = strings.TrimSpace(*req.OrganizationName)
+	}
+	website := "https://" + publisherDomain
+	extraInfo := &domain.AffiliateExtraInfo{Website: &website}
+	if publisher != nil {
+		extraInfo.SelfDescription = publisher.Description
+		extraInfo.LogoURL = publisher.FaviconImageURL
+	}
+
+	org, err := s.orgService.CreateOrganizationWithExtraInfo(ctx, &CreateOrganizationWithExtraInfoRequest{
+		Name:               name,
+		Type:               domain.OrganizationTypeAffiliate,
+		AffiliateExtraInfo: extraInfo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create affiliate organization: %w", err)
+	}
+	return org, nil
+}
+
+// resolveAffiliate returns the first affiliate of the organization, creating one when it has none
+func (s *publisherConversionService) resolveAffiliate(ctx context.Context, org *domain.Organization, contactEmail *string) (*domain.Affiliate, error) {
+	affiliates, err := s.affiliateRepo.GetAffiliatesByOrganization(ctx, org.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affiliates: %w", err)
+	}
+	if len(affiliates) > 0 {
+		return affiliates[0], nil
+	}
+
+	affiliate, err := s.affiliateService.CreateAffiliate(ctx, &domain.Affiliate{
+		OrganizationID: org.OrganizationID,
+		Name:           org.Name,
+		ContactEmail:   contactEmail,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create affiliate: %w", err)
+	}
+	return affiliate, nil
+}
+
+// createInvitation issues a single-use invitation restricted to the affiliate organization, with
+// the default visibility of all affiliates and campaigns
+func (s *publisherConversionService) createInvitation(ctx context.Context, organizationID, affiliateOrgID int64, publisherDomain, userID string, req *domain.ConvertPublisherRequest) (*domain.AdvertiserAssociationInvitation, error) {
+	name := "Partnership with " + publisherDomain
+	if req.InvitationName != nil {
+		name = strings.TrimSpace(*req.InvitationName)
+	}
+	maxUses := 1
+
+	invitation, err := s.invitationService.CreateInvitation(ctx, &domain.CreateInvitationRequest{
+		AdvertiserOrgID:        organizationID,
+		Name:                   name,
+		AllowedAffiliateOrgIDs: []int64{affiliateOrgID},
+		MaxUses:                &maxUses,
+		ExpiresAt:              req.ExpiresAt,
+		Message:                req.InvitationMessage,
+	}, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetConversion retrieves a conversion of the organization with its affiliate organization and affiliate
+func (s *publisherConversionService) GetConversion(ctx context.Context, organizationID, conversionID int64) (*domain.PublisherConversion, error) {
+	conversion, err := s.conversionRepo.GetConversionByID(ctx, conversionID)
+	if err != nil {
+		return nil, err
+	}
+	if conversion.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+
+	if org, err := s.orgService.GetOrganizationByID(ctx, conversion.AffiliateOrgID); err == nil {
+		conversion.AffiliateOrganization = org
+	}
+	if conversion.AffiliateID != nil {
+		if affiliate, err := s.affiliateRepo.GetAffiliateByID(ctx, *conversion.AffiliateID); err == nil {
+			conversion.Affiliate = affiliate
+		}
+	}
+
+	return conversion, nil
+}
+
+// ListConversions lists the conversions of the organization
+func (s *publisherConversionService) ListConversions(ctx context.Context, organizationID int64, filter *domain.PublisherConversionFilter) (*domain.PublisherConversionList, error) {
+	if err := filter.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	conversions, total, err := s.conversionRepo.ListConversions(ctx, organizationID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PublisherConversionList{Conversions: conversions, Total: total}, nil
+}
+
+// GetFunnel computes the conversion funnel of the organization over a period
+func (s *publisherConversionService) GetFunnel(ctx context.Context, organizationID int64, from, to *time.Time) (*domain.PublisherConversionFunnel, error) {
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -domain.DefaultPublisherConversionFunnelDays)
+	if from != nil {
+		start = *from
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidInput)
+	}
+
+	funnel, err := s.conversionRepo.GetFunnel(ctx, organizationID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	funnel.ComputeRates()
+
+	return funnel, nil
+}
+
+// RefreshProgress records the association and first tracking link of every open conversion
+func (s *publisherConversionService) RefreshProgress(ctx context.Context) error {
+	return s.conversionRepo.RefreshProgress(ctx, nil)
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/storage"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+const (
+	// maxInboundAttachments limits how many attachments of one email are stored
+	maxInboundAttachments = 20
+	// maxInboundAttachmentBytes limits the size of a single stored attachment
+	maxInboundAttachmentBytes = 10 * 1024 * 1024
+	// maxExternalMessageIDLength matches the publisher_messages.external_message_id column
+	maxExternalMessageIDLength = 255
+)
+
+// How an inbound email was matched to its conversation, recorded in the message metadata
+const (
+	inboundMatchedByReplyAddress = "reply_address"
+	inboundMatchedByHeaders      = "headers"
+)
+
+// PublisherInboundEmailService threads publisher email replies into their conversations
+type PublisherInboundEmailService interface {
+	// ReceiveEmail parses a raw MIME email and appends it to the matching conversation as a
+	// publisher message. envelopeRecipients are the SMTP RCPT TO addresses, when known.
+	// It returns domain.ErrNotFound when the email cannot be matched to a conversation.
+	ReceiveEmail(ctx context.Context, raw []byte, envelopeRecipients []string) (*domain.PublisherMessage, error)
+
+	// GetMessageAttachment returns an attachment of a message in one of the organization's conversations
+	GetMessageAttachment(ctx context.Context, organizationID, conversationID, messageID int64, index int) (*domain.MessageAttachment, []byte, error)
+}
+
+// publisherInboundEmailService implements PublisherInboundEmailService
+type publisherInboundEmailService struct {
+	messagingRepo   repository.PublisherMessagingRepository
+	favListRepo     repository.FavoritePublisherListRepository
+	pipelineRepo    repository.PublisherPipelineRepository
+	replyAddresses  *email.ReplyAddressCodec
+	attachmentStore storage.ObjectStore
+	notifier        NotificationService
+}
+
+// NewPublisherInboundEmailService creates a new inbound email service. Replies are matched by the
+// conversation Reply-To addresses of replyAddresses (if set) or by their threading headers.
+// Attachments are kept in attachmentStore; they are dropped when it is nil. The users of the
+// organization are notified of each reply through notifier, when set.
+func NewPublisherInboundEmailService(
+	messagingRepo repository.PublisherMessagingRepository,
+	favListRepo repository.FavoritePublisherListRepository,
+	pipelineRepo repository.PublisherPipelineRepository,
+	replyAddresses *email.ReplyAddressCodec,
+	attachmentStore storage.ObjectStore,
+	notifier NotificationService,
+) PublisherInboundEmailService {
+	return &publisherInboundEmailService{
+		messagingRepo:   messagingRepo,
+		favListRepo:     favListRepo,
+		pipelineRepo:    pipelineRepo,
+		replyAddresses:  replyAddresses,
+		attachmentStore: attachmentStore,
+		notifier:        notifier,
+	}
+}
+
+func (s *publisherInboundEmailService) ReceiveEmail(ctx context.Context, raw []byte, envelopeRecipients []string) (*domain.PublisherMessage, error) {
+	inbound, err := email.ParseInbound(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	// Redelivered webhooks and SMTP retries carry the same Message-ID
+	var externalID *string
+	if inbound.MessageID != "" {
+		id := inbound.MessageID
+		if len(id) > maxExternalMessageIDLength {
+			id = id[:maxExternalMessageIDLength]
+		}
+		existing, err := s.messagingRepo.GetMessageByExternalID(ctx, id)
+		if err != nil && err != domain.ErrNotFound {
+			return nil, fmt.Errorf("failed to check existing message: %w", err)
+		}
+		if existing != nil {
+			return existing, nil
+		}
+		externalID = &id
+	}
+
+	conversation, matchedBy, err := s.matchConversation(ctx, inbound, envelopeRecipients)
+	if err != nil {
+		return nil, err
+	}
+
+	content := email.StripQuotedReply(inbound.TextBody)
+	if content == "" {
+		content = "(empty message)"
+	}
+
+	sentAt := time.Now().UTC()
+	if !inbound.Date.IsZero() && inbound.Date.Before(sentAt) {
+		sentAt = inbound.Date.UTC()
+	}
+
+	metadata := map[string]interface{}{
+		domain.MessageMetadataEmail: map[string]interface{}{
+			"from":        inbound.From,
+			"from_name":   inbound.FromName,
+			"subject":     inbound.Subject,
+			"message_id":  inbound.MessageID,
+			"in_reply_to": inbound.InReplyTo,
+			"matched_by":  matchedBy,
+		},
+	}
+	if attachments := s.storeAttachments(ctx, conversation.ConversationID, inbound.Attachments); len(attachments) > 0 {
+		metadata[domain.MessageMetadataAttachments] = attachments
+	}
+
+	var senderID *string
+	if inbound.From != "" {
+		senderID = &inbound.From
+	}
+
+	message := &domain.PublisherMessage{
+		ConversationID:    conversation.ConversationID,
+		SenderType:        domain.SenderTypePublisher,
+		SenderID:          senderID,
+		Content:           content,
+		MessageType:       domain.MessageTypeText,
+		ExternalMessageID: externalID,
+		Metadata:          metadata,
+		SentAt:            sentAt,
+	}
+	if err := s.messagingRepo.CreateMessage(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to create publisher reply: %w", err)
+	}
+
+	// A reply reopens a conversation the organization had closed
+	if conversation.Status != domain.ConversationStatusActive {
+		if err := s.messagingRepo.UpdateConversationStatus(ctx, conversation.ConversationID, domain.ConversationStatusActive); err != nil {
+			logger.Error("Failed to reactivate conversation", "conversation_id", conversation.ConversationID, "error", err)
+		}
+	}
+
+	s.advanceListStatus(ctx, conversation)
+
+	notify(ctx, s.notifier, &domain.NewNotification{
+		OrganizationID: conversation.OrganizationID,
+		Type:           domain.NotificationTypePublisherReply,
+		Title:          fmt.Sprintf("%s replied to %s", conversation.PublisherDomain, conversation.Subject),
+		Body:           notificationPreview(content),
+		Data: map[string]interface{}{
+			"conversation_id":  conversation.ConversationID,
+			"message_id":       message.MessageID,
+			"publisher_domain": conversation.PublisherDomain,
+		},
+	})
+
+	logger.Info("Publisher email reply received",
+		"conversation_id", conversation.ConversationID,
+		"message_id", message.MessageID,
+		"matched_by", matchedBy,
+		"attachments", len(inbound.Attachments))
+
+	return message, nil
+}
+
+// matchConversation finds the conversation an email replies to, first by the conversation
+// Reply-To address it was sent to and then by the Message-IDs it references
+func (s *publisherInboundEmailService) matchConversation(ctx context.Context, inbound *email.InboundMessage, envelopeRecipients []string) (*domain.PublisherConversation, string, error) {
+	if s.replyAddresses != nil {
+		for _, recipient := range append(append([]string{}, envelopeRecipients...), inbound.To...) {
+			conversationID, ok := s.replyAddresses.Parse(recipient)
+			if !ok {
+				continue
+			}
+			conversation, err := s.messagingRepo.GetConversationByID(ctx, conversationID)
+			if err == nil {
+				return conversation, inboundMatchedByReplyAddress, nil
+			}
+			if err != domain.ErrNotFound {
+				return nil, "", err
+			}
+		}
+	}
+
+	references := append(append([]string{}, inbound.InReplyTo...), inbound.References...)
+	if len(references) > 0 {
+		original, err := s.messagingRepo.GetMessageByEmailMessageID(ctx, references)
+		if err != nil && err != domain.ErrNotFound {
+			return nil, "", err
+		}
+		if original != nil {
+			conversation, err := s.messagingRepo.GetConversationByID(ctx, original.ConversationID)
+			if err != nil {
+				return nil, "", err
+			}
+			return conversation, inboundMatchedByHeaders, nil
+		}
+	}
+
+	return nil, "", domain.ErrNotFound
+}
+
+// storeAttachments saves the email attachments to object storage. Attachments that are too
+// large or fail to store are skipped so the reply itself is never lost.
+func (s *publisherInboundEmailService) storeAttachments(ctx context.Context, conversationID int64, attachments []email.Attachment) []domain.MessageAttachment {
+	if s.attachmentStore == nil || len(attachments) == 0 {
+		return nil
+	}
+
+	var stored []domain.MessageAttachment
+	for _, attachment := range attachments {
+		if len(stored) >= maxInboundAttachments {
+			logger.Warn("Dropping attachments beyond limit", "conversation_id", conversationID, "limit", maxInboundAttachments)
+			break
+		}
+		if len(attachment.Data) > maxInboundAttachmentBytes {
+			logger.Warn("Dropping oversized attachment", "conversation_id", conversationID, "filename", attachment.Filename, "size", len(attachment.Data))
+			continue
+		}
+
+		saved, err := putMessageAttachment(ctx, s.attachmentStore, conversationID, attachment.Filename, attachment.ContentType, attachment.Data)
+		if err != nil {
+			logger.Error("Failed to store email attachment", "conversation_id", conversationID, "filename", attachment.Filename, "error", err)
+			continue
+		}
+		stored = append(stored, saved)
+	}
+	return stored
+}
+
+// putMessageAttachment saves a file of a conversation message to object storage under a
+// random key, so stored files are never overwritten
+func putMessageAttachment(ctx context.Context, store storage.ObjectStore, conversationID int64, filename, contentType string, data []byte) (domain.MessageAttachment, error) {
+	filename = sanitizeAttachmentFilename(filename)
+	key := messageAttachmentKeyPrefix(conversationID) + randomHex(8) + "/" + filename
+	if err := store.Put(ctx, key, contentType, data); err != nil {
+		return domain.MessageAttachment{}, err
+	}
+
+	return domain.MessageAttachment{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        len(data),
+		StorageKey:  key,
+	}, nil
+}
+
+// messageAttachmentKeyPrefix is the storage key prefix of the attachments of a conversation
+func messageAttachmentKeyPrefix(conversationID int64) string {
+	return fmt.Sprintf("publisher-messages/%d/", conversationID)
+}
+
+// isMessageAttachmentKey reports whether a storage key belongs to the attachments of a
+// conversation. Keys come from message metadata and must never reach other stored objects.
+func isMessageAttachmentKey(conversationID int64, key string) bool {
+	return strings.HasPrefix(key, messageAttachmentKeyPrefix(conversationID)) && path.Clean(key) == key
+}
+
+// advanceListStatus moves the publisher to the next pipeline stage of its favorite list after a reply
+func (s *publisherInboundEmailService) advanceListStatus(ctx context.Context, conversation *domain.PublisherConversation) {
+	if conversation.ListID == nil {
+		return
+	}
+
+	item, err := s.favListRepo.GetPublisherFromList(ctx, *conversation.ListID, conversation.PublisherDomain)
+	if err != nil {
+		if err != domain.ErrNotFound {
+			logger.Error("Failed to load list item for publisher reply", "list_id", *conversation.ListID, "error", err)
+		}
+		return
+	}
+
+	if item.StageID == nil {
+		// Items outside the pipeline only have the legacy status
+		status, changed := domain.StatusAfterPublisherReply(item.Status)
+		if !changed {
+			return
+		}
+		if err := s.favListRepo.UpdatePublisherStatus(ctx, *conversation.ListID, conversation.PublisherDomain, status); err != nil {
+			logger.Error("Failed to update list item status after publisher reply", "list_id", *conversation.ListID, "error", err)
+		}
+		return
+	}
+
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, conversation.OrganizationID)
+	if err != nil {
+		logger.Error("Failed to load pipeline stages for publisher reply", "organization_id", conversation.OrganizationID, "error", err)
+		return
+	}
+	next, changed := domain.StageAfterPublisherReply(stages, domain.FindPipelineStage(stages, *item.StageID))
+	if !changed {
+		return
+	}
+	if _, err := moveListItem(ctx, s.pipelineRepo, stages, item, next, domain.PipelineChangeReasonPublisherReply, nil, nil); err != nil {
+		logger.Error("Failed to move list item after publisher reply", "list_id", *conversation.ListID, "error", err)
+	}
+}
+
+func (s *publisherInboundEmailService) GetMessageAttachment(ctx context.Context, organizationID, conversationID, messageID int64, index int) (*domain.MessageAttachment, []byte, error) {
+	conversation, err := s.messagingRepo.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conversation.OrganizationID != organizationID {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	message, err := s.messagingRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if message.ConversationID != conversationID {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	attachments := message.GetAttachments()
+	if index < 0 || index >= len(attachments) || s.attachmentStore == nil {
+		return nil, nil, domain.ErrNotFound
+	}
+	attachment := attachments[index]
+	if !isMessageAttachmentKey(conversationID, attachment.StorageKey) {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	data, err := s.attachmentStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if err == storage.ErrObjectNotFound {
+			return nil, nil, domain.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load attachment: %w", err)
+	}
+
+	return &attachment, data, nil
+}
+
+// unsafeFilenameChars matches characters that are replaced in stored attachment names
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeAttachmentFilename reduces a sender-supplied filename to a safe storage key segment
+func sanitizeAttachmentFilename(filename string) string {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	name = strings.Trim(unsafeFilenameChars.ReplaceAllString(name, "_"), "._")
+	if name == "" {
+		name = "attachment"
+	}
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+	return name
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// lookalikeCandidatePool is how many candidate publishers are scored per recommendation request
+const lookalikeCandidatePool = 500
+
+// lookalikeRecommender scores candidate publishers against a seed profile
+type lookalikeRecommender struct {
+	analyticsRepo repository.AnalyticsRepository
+	responses     *analyticsService // Builds the publisher API representation
+}
+
+func newLookalikeRecommender(analyticsRepo repository.AnalyticsRepository) *lookalikeRecommender {
+	return &lookalikeRecommender{
+		analyticsRepo: analyticsRepo,
+		responses:     &analyticsService{analyticsRepo: analyticsRepo},
+	}
+}
+
+// recommend returns up to limit publishers most similar to the seed publishers, best first,
+// leaving out the seeds themselves and excludeDomains
+func (r *lookalikeRecommender) recommend(ctx context.Context, seeds []*domain.AnalyticsPublisher, excludeDomains []string, limit int) (*domain.SimilarPublishersResponse, error) {
+	if limit < 1 {
+		limit = domain.DefaultLookalikeLimit
+	}
+	if limit > domain.MaxLookalikeLimit {
+		limit = domain.MaxLookalikeLimit
+	}
+
+	response := &domain.SimilarPublishersResponse{
+		Seeds: make([]string, 0, len(seeds)),
+		Data:  make([]*domain.SimilarPublisher, 0),
+	}
+	if len(seeds) == 0 {
+		return response, nil
+	}
+
+	profiles := make([]*domain.LookalikeProfile, len(seeds))
+	for i, seed := range seeds {
+		profiles[i] = domain.NewLookalikeProfile(seed)
+		response.Seeds = append(response.Seeds, seed.Domain)
+	}
+	seedProfile := profiles[0]
+	if len(profiles) > 1 {
+		seedProfile = domain.MergeLookalikeProfiles(profiles)
+	}
+
+	excluded := make([]string, 0, len(excludeDomains))
+	for _, excludedDomain := range excludeDomains {
+		excluded = append(excluded, strings.ToLower(excludedDomain))
+	}
+
+	candidates, err := r.analyticsRepo.FindLookalikeCandidates(ctx, seedProfile, excluded, lookalikeCandidatePool)
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		publisher *domain.AnalyticsPublisher
+		match     domain.LookalikeMatch
+	}
+	matches := make([]scored, 0, len(candidates))
+	for _, candidate := range candidates {
+		match := seedProfile.Similarity(domain.NewLookalikeProfile(candidate))
+		if match.Score > 0 {
+			matches = append(matches, scored{publisher: candidate, match: match})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].match.Score != matches[j].match.Score {
+			return matches[i].match.Score > matches[j].match.Score
+		}
+		return matches[i].publisher.TrafficScore > matches[j].publisher.TrafficScore
+	})
+
+	for _, match := range matches {
+		if len(response.Data) >= limit {
+			break
+		}
+		publisher, err := r.responses.buildPublisherResponse(match.publisher)
+		if err != nil {
+			continue
+		}
+		response.Data = append(response.Data, &domain.SimilarPublisher{
+			Publisher: publisher,
+			Match:     match.match,
+		})
+	}
+
+	return response, nil
+}
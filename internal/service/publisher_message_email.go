@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/platform/logger"
+)
+
+// publisherMessageTemplate renders messages sent to publishers by email
+var publisherMessageTemplate = email.MustParseTemplate("publisher_message",
+	`{{if .IsReply}}Re: {{end}}{{.Subject}}`,
+	`{{.Content}}
+
+--
+{{.OrganizationName}}
+
+Reply to this email to respond; your reply is added to the conversation.
+`)
+
+// publisherMessageEmailData is the data passed to publisherMessageTemplate
+type publisherMessageEmailData struct {
+	Subject          string
+	Content          string
+	OrganizationName string
+	IsReply          bool
+}
+
+// deliverMessageByEmail emails an organization message to the publisher's contact address and
+// records the outcome in the message metadata. Delivery problems never fail the message itself;
+// they are visible through the recorded delivery status.
+func (s *publisherMessagingService) deliverMessageByEmail(ctx context.Context, conversation *domain.PublisherConversation, message *domain.PublisherMessage, isReply bool) {
+	if s.emailSender == nil || message.MessageType != domain.MessageTypeText {
+		return
+	}
+
+	delivery := map[string]interface{}{
+		"attempted_at": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	recipient, err := s.publisherContactEmail(ctx, conversation.PublisherDomain)
+	switch {
+	case err != nil:
+		delivery["status"] = domain.EmailDeliveryStatusFailed
+		delivery["error"] = err.Error()
+	case recipient == "":
+		delivery["status"] = domain.EmailDeliveryStatusSkipped
+		delivery["error"] = "no contact email known for publisher"
+	default:
+		delivery["to"] = recipient
+		messageID, replyTo, err := s.sendMessageEmail(ctx, conversation, message, recipient, isReply)
+		if replyTo != "" {
+			delivery["reply_to"] = replyTo
+		}
+		if err != nil {
+			delivery["status"] = domain.EmailDeliveryStatusFailed
+			delivery["error"] = err.Error()
+		} else {
+			delivery["status"] = domain.EmailDeliveryStatusSent
+			delivery["message_id"] = messageID
+		}
+	}
+
+	if message.Metadata == nil {
+		message.Metadata = make(map[string]interface{})
+	}
+	message.Metadata[domain.MessageMetadataEmailDelivery] = delivery
+
+	if err := s.messagingRepo.UpdateMessageMetadata(ctx, message.MessageID, message.Metadata); err != nil {
+		logger.Error("Failed to record publisher message email delivery", "message_id", message.MessageID, "error", err)
+	}
+}
+
+// sendMessageEmail renders and sends the email, returning its Message-ID and Reply-To address
+func (s *publisherMessagingService) sendMessageEmail(ctx context.Context, conversation *domain.PublisherConversation, message *domain.PublisherMessage, recipient string, isReply bool) (string, string, error) {
+	data := publisherMessageEmailData{
+		Subject: conversation.Subject,
+		Content: message.Content,
+		IsReply: isReply,
+	}
+	if org, err := s.orgRepo.GetOrganizationByID(ctx, conversation.OrganizationID); err == nil {
+		data.OrganizationName = org.Name
+	}
+
+	subject, body, err := publisherMessageTemplate.Render(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	var replyTo string
+	if s.replyAddresses != nil {
+		replyTo = s.replyAddresses.Address(conversation.ConversationID)
+	}
+
+	attachments, err := s.loadEmailAttachments(ctx, message)
+	if err != nil {
+		return "", replyTo, err
+	}
+
+	messageID, err := s.emailSender.Send(ctx, &email.Message{
+		To:          []string{recipient},
+		ReplyTo:     replyTo,
+		Subject:     subject,
+		TextBody:    body,
+		Attachments: attachments,
+	})
+	return messageID, replyTo, err
+}
+
+// loadEmailAttachments reads the files attached to a message from object storage
+func (s *publisherMessagingService) loadEmailAttachments(ctx context.Context, message *domain.PublisherMessage) ([]email.Attachment, error) {
+	stored := message.GetAttachments()
+	if len(stored) == 0 || s.attachmentStore == nil {
+		return nil, nil
+	}
+
+	attachments := make([]email.Attachment, 0, len(stored))
+	for _, attachment := range stored {
+		if !isMessageAttachmentKey(message.ConversationID, attachment.StorageKey) {
+			return nil, fmt.Errorf("attachment %s is not stored with the conversation", attachment.Filename)
+		}
+		data, err := s.attachmentStore.Get(ctx, attachment.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load attachment %s: %w", attachment.Filename, err)
+		}
+		attachments = append(attachments, email.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Data:        data,
+		})
+	}
+	return attachments, nil
+}
+
+// publisherContactEmail looks up the outreach address of a publisher from its analytics contact data
+func (s *publisherMessagingService) publisherContactEmail(ctx context.Context, publisherDomain string) (string, error) {
+	publisher, err := s.analyticsRepo.GetPublisherByDomain(ctx, publisherDomain)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return publisher.PrimaryContactEmail()
+}
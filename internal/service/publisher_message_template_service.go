@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// PublisherMessageTemplateService defines the interface for reusable publisher message templates
+type PublisherMessageTemplateService interface {
+	CreateTemplate(ctx context.Context, organizationID int64, userID *string, req *domain.CreateMessageTemplateRequest) (*domain.PublisherMessageTemplate, error)
+	GetTemplate(ctx context.Context, organizationID, templateID int64) (*domain.PublisherMessageTemplate, error)
+	ListTemplates(ctx context.Context, organizationID int64) ([]*domain.PublisherMessageTemplate, error)
+	UpdateTemplate(ctx context.Context, organizationID, templateID int64, req *domain.UpdateMessageTemplateRequest) (*domain.PublisherMessageTemplate, error)
+	DeleteTemplate(ctx context.Context, organizationID, templateID int64) error
+
+	// RenderTemplate previews a template for a conversation or publisher, or with sample data
+	RenderTemplate(ctx context.Context, organizationID, templateID int64, req *domain.RenderMessageTemplateRequest) (*domain.RenderedMessageTemplate, error)
+	// RenderForPublisher renders a template for a publisher, with the list fields when listID is set
+	RenderForPublisher(ctx context.Context, organizationID, templateID int64, publisherDomain string, listID *int64) (*domain.RenderedMessageTemplate, error)
+}
+
+// publisherMessageTemplateService implements PublisherMessageTemplateService
+type publisherMessageTemplateService struct {
+	templateRepo  repository.PublisherMessageTemplateRepository
+	messagingRepo repository.PublisherMessagingRepository
+	favListRepo   repository.FavoritePublisherListRepository
+	analyticsRepo repository.AnalyticsRepository
+	orgRepo       repository.OrganizationRepository
+}
+
+// NewPublisherMessageTemplateService creates a new message template service
+func NewPublisherMessageTemplateService(
+	templateRepo repository.PublisherMessageTemplateRepository,
+	messagingRepo repository.PublisherMessagingRepository,
+	favListRepo repository.FavoritePublisherListRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	orgRepo repository.OrganizationRepository,
+) PublisherMessageTemplateService {
+	return &publisherMessageTemplateService{
+		templateRepo:  templateRepo,
+		messagingRepo: messagingRepo,
+		favListRepo:   favListRepo,
+		analyticsRepo: analyticsRepo,
+		orgRepo:       orgRepo,
+	}
+}
+
+// loadMessageMergeFields collects the template fields of a publisher; list and item may be nil
+func loadMessageMergeFields(ctx context.Context, analyticsRepo repository.AnalyticsRepository, publisherDomain, organizationName string, list *domain.FavoritePublisherList, item *domain.FavoritePublisherListItem) (domain.MessageMergeFields, error) {
+	publisher, err := analyticsRepo.GetPublisherByDomain(ctx, publisherDomain)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.MessageMergeFields{}, fmt.Errorf("failed to get publisher: %w", err)
+	}
+
+	fields := domain.NewMessageMergeFields(publisherDomain, publisher)
+	fields.OrganizationName = organizationName
+	if list != nil {
+		fields.ListName = list.Name
+	}
+	if item != nil && item.Notes != nil {
+		fields.Notes = *item.Notes
+	}
+	return fields, nil
+}
+
+// renderMessageTemplate substitutes the merge fields in a template
+func renderMessageTemplate(template *domain.PublisherMessageTemplate, fields domain.MessageMergeFields) (*domain.RenderedMessageTemplate, error) {
+	subject := ""
+	if template.Subject != nil {
+		subject = *template.Subject
+	}
+	tmpl, err := email.ParseTemplate("message_template", subject, template.Body)
+	if err != nil {
+		return nil, err
+	}
+	renderedSubject, body, err := tmpl.Render(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RenderedMessageTemplate{Subject: renderedSubject, Body: body}, nil
+}
+
+func (s *publisherMessageTemplateService) getOwnedTemplate(ctx context.Context, organizationID, templateID int64) (*domain.PublisherMessageTemplate, error) {
+	template, err := s.templateRepo.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+	return template, nil
+}
+
+// checkTemplate rejects duplicate names and templates that do not render with sample data
+func (s *publisherMessageTemplateService) checkTemplate(ctx context.Context, template *domain.PublisherMessageTemplate) error {
+	existing, err := s.templateRepo.ListTemplates(ctx, template.OrganizationID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.TemplateID != template.TemplateID && strings.EqualFold(other.Name, template.Name) {
+			return fmt.Errorf("%w: a template named %s already exists", domain.ErrInvalidInput, template.Name)
+		}
+	}
+	if template.TemplateID == 0 && len(existing) >= domain.MaxMessageTemplatesPerOrganization {
+		return fmt.Errorf("%w: at most %d templates are allowed", domain.ErrInvalidInput, domain.MaxMessageTemplatesPerOrganization)
+	}
+
+	if _, err := renderMessageTemplate(template, domain.SampleMessageMergeFields()); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// CreateTemplate creates a message template
+func (s *publisherMessageTemplateService) CreateTemplate(ctx context.Context, organizationID int64, userID *string, req *domain.CreateMessageTemplateRequest) (*domain.PublisherMessageTemplate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	template := &domain.PublisherMessageTemplate{
+		OrganizationID:  organizationID,
+		Name:            strings.TrimSpace(req.Name),
+		Body:            strings.TrimSpace(req.Body),
+		CreatedByUserID: userID,
+	}
+	if req.Subject != nil {
+		subject := strings.TrimSpace(*req.Subject)
+		template.Subject = &subject
+	}
+
+	if err := s.checkTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetTemplate returns a template of the organization
+func (s *publisherMessageTemplateService) GetTemplate(ctx context.Context, organizationID, templateID int64) (*domain.PublisherMessageTemplate, error) {
+	return s.getOwnedTemplate(ctx, organizationID, templateID)
+}
+
+// ListTemplates lists the templates of the organization
+func (s *publisherMessageTemplateService) ListTemplates(ctx context.Context, organizationID int64) ([]*domain.PublisherMessageTemplate, error) {
+	return s.templateRepo.ListTemplates(ctx, organizationID)
+}
+
+// UpdateTemplate updates a template
+func (s *publisherMessageTemplateService) UpdateTemplate(ctx context.Context, organizationID, templateID int64, req *domain.UpdateMessageTemplateRequest) (*domain.PublisherMessageTemplate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	template, err := s.getOwnedTemplate(ctx, organizationID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Subject != nil {
+		if subject := strings.TrimSpace(*req.Subject); subject != "" {
+			template.Subject = &subject
+		} else {
+			template.Subject = nil
+		}
+	}
+	if req.Body != nil {
+		template.Body = strings.TrimSpace(*req.Body)
+	}
+
+	if err := s.checkTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.UpdateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate deletes a template
+func (s *publisherMessageTemplateService) DeleteTemplate(ctx context.Context, organizationID, templateID int64) error {
+	if _, err := s.getOwnedTemplate(ctx, organizationID, templateID); err != nil {
+		return err
+	}
+	return s.templateRepo.DeleteTemplate(ctx, templateID)
+}
+
+// RenderTemplate previews a template. A conversation takes precedence over a publisher domain.
+func (s *publisherMessageTemplateService) RenderTemplate(ctx context.Context, organizationID, templateID int64, req *domain.RenderMessageTemplateRequest) (*domain.RenderedMessageTemplate, error) {
+	switch {
+	case req.ConversationID != nil:
+		conversation, err := s.messagingRepo.GetConversationByID(ctx, *req.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		if conversation.OrganizationID != organizationID {
+			return nil, domain.ErrNotFound
+		}
+		return s.RenderForPublisher(ctx, organizationID, templateID, conversation.PublisherDomain, conversation.ListID)
+
+	case req.PublisherDomain != nil:
+		publisherDomain := domain.NormalizePublisherDomain(*req.PublisherDomain)
+		if publisherDomain == "" {
+			return nil, fmt.Errorf("%w: publisher_domain is invalid", domain.ErrInvalidInput)
+		}
+		return s.RenderForPublisher(ctx, organizationID, templateID, publisherDomain, req.ListID)
+
+	default:
+		template, err := s.getOwnedTemplate(ctx, organizationID, templateID)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := renderMessageTemplate(template, domain.SampleMessageMergeFields())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+		return rendered, nil
+	}
+}
+
+// RenderForPublisher renders a template with the publisher's analytics data and list notes
+func (s *publisherMessageTemplateService) RenderForPublisher(ctx context.Context, organizationID, templateID int64, publisherDomain string, listID *int64) (*domain.RenderedMessageTemplate, error) {
+	template, err := s.getOwnedTemplate(ctx, organizationID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationName := ""
+	if org, err := s.orgRepo.GetOrganizationByID(ctx, organizationID); err == nil {
+		organizationName = org.Name
+	}
+
+	var list *domain.FavoritePublisherList
+	var item *domain.FavoritePublisherListItem
+	if listID != nil {
+		list, err = s.favListRepo.GetListByID(ctx, *listID)
+		if err != nil {
+			return nil, err
+		}
+		if list.OrganizationID != organizationID {
+			return nil, domain.ErrNotFound
+		}
+		if item, err = s.favListRepo.GetPublisherFromList(ctx, list.ListID, publisherDomain); err != nil {
+			item = nil // The publisher may have been removed from the list
+		}
+	}
+
+	fields, err := loadMessageMergeFields(ctx, s.analyticsRepo, publisherDomain, organizationName, list, item)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := renderMessageTemplate(template, fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return rendered, nil
+}
@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/platform/storage"
 	"github.com/affiliate-backend/internal/repository"
 )
 
@@ -13,14 +16,29 @@ type PublisherMessagingService interface {
 	// Conversation operations
 	CreateConversation(ctx context.Context, organizationID int64, req *domain.CreateConversationRequest) (*domain.PublisherConversation, error)
 	GetConversation(ctx context.Context, organizationID int64, conversationID int64) (*domain.PublisherConversation, error)
-	GetConversations(ctx context.Context, organizationID int64, status string, page, pageSize int) (*domain.ConversationListResponse, error)
+	// GetConversations lists conversations with the unread counts of filter.UserID
+	GetConversations(ctx context.Context, organizationID int64, filter *domain.ConversationListFilter, page, pageSize int) (*domain.ConversationListResponse, error)
 	UpdateConversationStatus(ctx context.Context, organizationID int64, conversationID int64, req *domain.UpdateConversationStatusRequest) error
 	DeleteConversation(ctx context.Context, organizationID int64, conversationID int64) error
 
 	// Message operations
 	SendMessage(ctx context.Context, organizationID int64, conversationID int64, req *domain.SendMessageRequest) (*domain.PublisherMessage, error)
-	GetConversationMessages(ctx context.Context, organizationID int64, conversationID int64, page, pageSize int) (*domain.ConversationWithMessagesResponse, error)
+	// GetConversationMessages returns messages with the read state of userID
+	GetConversationMessages(ctx context.Context, organizationID int64, conversationID int64, userID string, page, pageSize int) (*domain.ConversationWithMessagesResponse, error)
 	AddExternalMessage(ctx context.Context, req *domain.AddExternalMessageRequest) (*domain.PublisherMessage, error)
+	// SendAutomatedMessage sends an organization message on behalf of automation such as outreach
+	// sequences. The email delivery outcome is recorded in the returned message metadata.
+	SendAutomatedMessage(ctx context.Context, conversation *domain.PublisherConversation, content string, isReply bool, metadata map[string]interface{}) (*domain.PublisherMessage, error)
+
+	// Read state operations
+	// MarkConversationRead marks the publisher messages of a conversation as read by the user and
+	// returns how many were newly marked
+	MarkConversationRead(ctx context.Context, organizationID, conversationID int64, userID string, req *domain.MarkConversationReadRequest) (int, error)
+	MarkMessageRead(ctx context.Context, organizationID, conversationID, messageID int64, userID string) error
+	MarkMessageUnread(ctx context.Context, organizationID, conversationID, messageID int64, userID string) error
+
+	// SearchMessages full-text searches the organization's messages, optionally in one conversation
+	SearchMessages(ctx context.Context, organizationID int64, query string, conversationID *int64, page, pageSize int) (*domain.MessageSearchResponse, error)
 
 	// Utility operations
 	FindOrCreateConversation(ctx context.Context, organizationID int64, publisherDomain string, subject string) (*domain.PublisherConversation, error)
@@ -31,24 +49,58 @@ type publisherMessagingService struct {
 	messagingRepo repository.PublisherMessagingRepository
 	analyticsRepo repository.AnalyticsRepository
 	favListRepo   repository.FavoritePublisherListRepository
+	orgRepo       repository.OrganizationRepository
+
+	templateService PublisherMessageTemplateService
+	// Attached files; attachments are rejected when attachmentStore is nil
+	attachmentStore storage.ObjectStore
+
+	// Email transport; messages are only stored when emailSender is nil
+	emailSender    email.Sender
+	replyAddresses *email.ReplyAddressCodec
 }
 
-// NewPublisherMessagingService creates a new publisher messaging service
+// NewPublisherMessagingService creates a new publisher messaging service. Organization messages
+// are emailed to the publisher through emailSender with a Reply-To address from replyAddresses
+// that identifies the conversation, with their attachments from attachmentStore.
 func NewPublisherMessagingService(
 	messagingRepo repository.PublisherMessagingRepository,
 	analyticsRepo repository.AnalyticsRepository,
 	favListRepo repository.FavoritePublisherListRepository,
+	orgRepo repository.OrganizationRepository,
+	templateService PublisherMessageTemplateService,
+	attachmentStore storage.ObjectStore,
+	emailSender email.Sender,
+	replyAddresses *email.ReplyAddressCodec,
 ) PublisherMessagingService {
 	return &publisherMessagingService{
-		messagingRepo: messagingRepo,
-		analyticsRepo: analyticsRepo,
-		favListRepo:   favListRepo,
+		messagingRepo:   messagingRepo,
+		analyticsRepo:   analyticsRepo,
+		favListRepo:     favListRepo,
+		orgRepo:         orgRepo,
+		templateService: templateService,
+		attachmentStore: attachmentStore,
+		emailSender:     emailSender,
+		replyAddresses:  replyAddresses,
 	}
 }
 
 // Conversation operations
 
 func (s *publisherMessagingService) CreateConversation(ctx context.Context, organizationID int64, req *domain.CreateConversationRequest) (*domain.PublisherConversation, error) {
+	if req.TemplateID != nil {
+		rendered, err := s.templateService.RenderForPublisher(ctx, organizationID, *req.TemplateID, req.PublisherDomain, req.ListID)
+		if err != nil {
+			return nil, err
+		}
+		if req.Subject == "" {
+			req.Subject = rendered.Subject
+		}
+		if req.InitialMessage == "" {
+			req.InitialMessage = rendered.Body
+		}
+	}
+
 	if err := req.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
@@ -109,6 +161,8 @@ func (s *publisherMessagingService) CreateConversation(ctx context.Context, orga
 		return nil, fmt.Errorf("failed to create initial message: %w", err)
 	}
 
+	s.deliverMessageByEmail(ctx, conversation, initialMessage, false)
+
 	return conversation, nil
 }
 
@@ -138,7 +192,7 @@ func (s *publisherMessagingService) GetConversation(ctx context.Context, organiz
 	return conversation, nil
 }
 
-func (s *publisherMessagingService) GetConversations(ctx context.Context, organizationID int64, status string, page, pageSize int) (*domain.ConversationListResponse, error) {
+func (s *publisherMessagingService) GetConversations(ctx context.Context, organizationID int64, filter *domain.ConversationListFilter, page, pageSize int) (*domain.ConversationListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -148,11 +202,19 @@ func (s *publisherMessagingService) GetConversations(ctx context.Context, organi
 
 	offset := (page - 1) * pageSize
 
-	conversations, total, err := s.messagingRepo.GetConversationsByOrganization(ctx, organizationID, status, pageSize, offset)
+	conversations, total, err := s.messagingRepo.GetConversationsByOrganization(ctx, organizationID, filter, pageSize, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversations: %w", err)
 	}
 
+	unreadTotal := 0
+	if filter.UserID != "" {
+		unreadTotal, err = s.messagingRepo.CountUnreadMessages(ctx, organizationID, filter.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Optionally load publisher details for each conversation
 	for i := range conversations {
 		if publisher, err := s.analyticsRepo.GetPublisherByDomain(ctx, conversations[i].PublisherDomain); err == nil {
@@ -165,6 +227,7 @@ func (s *publisherMessagingService) GetConversations(ctx context.Context, organi
 		Total:         total,
 		Page:          page,
 		PageSize:      pageSize,
+		UnreadTotal:   unreadTotal,
 	}, nil
 }
 
@@ -203,10 +266,6 @@ func (s *publisherMessagingService) DeleteConversation(ctx context.Context, orga
 // Message operations
 
 func (s *publisherMessagingService) SendMessage(ctx context.Context, organizationID int64, conversationID int64, req *domain.SendMessageRequest) (*domain.PublisherMessage, error) {
-	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid request: %w", err)
-	}
-
 	// Verify conversation exists and belongs to organization
 	conversation, err := s.messagingRepo.GetConversationByID(ctx, conversationID)
 	if err != nil {
@@ -217,6 +276,21 @@ func (s *publisherMessagingService) SendMessage(ctx context.Context, organizatio
 		return nil, domain.ErrNotFound
 	}
 
+	if req.TemplateID != nil && req.Content == "" {
+		rendered, err := s.templateService.RenderForPublisher(ctx, organizationID, *req.TemplateID, conversation.PublisherDomain, conversation.ListID)
+		if err != nil {
+			return nil, err
+		}
+		req.Content = rendered.Body
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if len(req.Attachments) > 0 && s.attachmentStore == nil {
+		return nil, fmt.Errorf("%w: attachments are not available", domain.ErrInvalidInput)
+	}
+
 	// Check if conversation is active
 	if conversation.Status != domain.ConversationStatusActive {
 		return nil, fmt.Errorf("cannot send message to %s conversation", conversation.Status)
@@ -227,12 +301,30 @@ func (s *publisherMessagingService) SendMessage(ctx context.Context, organizatio
 		messageType = *req.MessageType
 	}
 
+	// Attachments are only recorded for files stored by the service
+	metadata := req.Metadata
+	delete(metadata, domain.MessageMetadataAttachments)
+	if len(req.Attachments) > 0 {
+		attachments := make([]domain.MessageAttachment, 0, len(req.Attachments))
+		for _, upload := range req.Attachments {
+			attachment, err := putMessageAttachment(ctx, s.attachmentStore, conversationID, upload.Filename, upload.ContentType, upload.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to store attachment: %w", err)
+			}
+			attachments = append(attachments, attachment)
+		}
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		metadata[domain.MessageMetadataAttachments] = attachments
+	}
+
 	message := &domain.PublisherMessage{
 		ConversationID: conversationID,
 		SenderType:     domain.SenderTypeOrganization,
 		Content:        req.Content,
 		MessageType:    messageType,
-		Metadata:       req.Metadata,
+		Metadata:       metadata,
 	}
 
 	err = s.messagingRepo.CreateMessage(ctx, message)
@@ -240,10 +332,12 @@ func (s *publisherMessagingService) SendMessage(ctx context.Context, organizatio
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
+	s.deliverMessageByEmail(ctx, conversation, message, true)
+
 	return message, nil
 }
 
-func (s *publisherMessagingService) GetConversationMessages(ctx context.Context, organizationID int64, conversationID int64, page, pageSize int) (*domain.ConversationWithMessagesResponse, error) {
+func (s *publisherMessagingService) GetConversationMessages(ctx context.Context, organizationID int64, conversationID int64, userID string, page, pageSize int) (*domain.ConversationWithMessagesResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -267,6 +361,20 @@ func (s *publisherMessagingService) GetConversationMessages(ctx context.Context,
 		return nil, fmt.Errorf("failed to get messages: %w", err)
 	}
 
+	if userID != "" {
+		reads, err := s.messagingRepo.GetMessageReads(ctx, conversationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range messages {
+			if readAt, ok := reads[messages[i].MessageID]; ok {
+				messages[i].ReadAt = &readAt
+			} else if messages[i].SenderType == domain.SenderTypePublisher {
+				messages[i].Unread = true
+			}
+		}
+	}
+
 	// Load additional conversation details
 	if publisher, err := s.analyticsRepo.GetPublisherByDomain(ctx, conversation.PublisherDomain); err == nil {
 		conversation.Publisher = publisher
@@ -314,6 +422,10 @@ func (s *publisherMessagingService) AddExternalMessage(ctx context.Context, req
 		messageType = *req.MessageType
 	}
 
+	// Attachments are only recorded for files stored by the service
+	metadata := req.Metadata
+	delete(metadata, domain.MessageMetadataAttachments)
+
 	message := &domain.PublisherMessage{
 		ConversationID:    req.ConversationID,
 		SenderType:        req.SenderType,
@@ -321,7 +433,7 @@ func (s *publisherMessagingService) AddExternalMessage(ctx context.Context, req
 		Content:           req.Content,
 		MessageType:       messageType,
 		ExternalMessageID: req.ExternalMessageID,
-		Metadata:          req.Metadata,
+		Metadata:          metadata,
 	}
 
 	if req.SentAt != nil {
@@ -336,6 +448,116 @@ func (s *publisherMessagingService) AddExternalMessage(ctx context.Context, req
 	return message, nil
 }
 
+func (s *publisherMessagingService) SendAutomatedMessage(ctx context.Context, conversation *domain.PublisherConversation, content string, isReply bool, metadata map[string]interface{}) (*domain.PublisherMessage, error) {
+	if err := (&domain.SendMessageRequest{Content: content}).Validate(); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	message := &domain.PublisherMessage{
+		ConversationID: conversation.ConversationID,
+		SenderType:     domain.SenderTypeOrganization,
+		Content:        content,
+		MessageType:    domain.MessageTypeText,
+		Metadata:       metadata,
+	}
+
+	if err := s.messagingRepo.CreateMessage(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+
+	s.deliverMessageByEmail(ctx, conversation, message, isReply)
+
+	return message, nil
+}
+
+// Read state operations
+
+// getOwnedConversation returns a conversation of the organization
+func (s *publisherMessagingService) getOwnedConversation(ctx context.Context, organizationID, conversationID int64) (*domain.PublisherConversation, error) {
+	conversation, err := s.messagingRepo.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+	return conversation, nil
+}
+
+// getConversationMessage returns a message of one of the organization's conversations
+func (s *publisherMessagingService) getConversationMessage(ctx context.Context, organizationID, conversationID, messageID int64) (*domain.PublisherMessage, error) {
+	if _, err := s.getOwnedConversation(ctx, organizationID, conversationID); err != nil {
+		return nil, err
+	}
+	message, err := s.messagingRepo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.ConversationID != conversationID {
+		return nil, domain.ErrNotFound
+	}
+	return message, nil
+}
+
+func (s *publisherMessagingService) MarkConversationRead(ctx context.Context, organizationID, conversationID int64, userID string, req *domain.MarkConversationReadRequest) (int, error) {
+	if _, err := s.getOwnedConversation(ctx, organizationID, conversationID); err != nil {
+		return 0, err
+	}
+	return s.messagingRepo.MarkMessagesRead(ctx, conversationID, userID, req.UpToMessageID)
+}
+
+func (s *publisherMessagingService) MarkMessageRead(ctx context.Context, organizationID, conversationID, messageID int64, userID string) error {
+	message, err := s.getConversationMessage(ctx, organizationID, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+	if message.SenderType != domain.SenderTypePublisher {
+		return nil // Only publisher messages have a read state
+	}
+
+	return s.messagingRepo.MarkMessageRead(ctx, messageID, userID)
+}
+
+func (s *publisherMessagingService) MarkMessageUnread(ctx context.Context, organizationID, conversationID, messageID int64, userID string) error {
+	if _, err := s.getConversationMessage(ctx, organizationID, conversationID, messageID); err != nil {
+		return err
+	}
+	return s.messagingRepo.MarkMessageUnread(ctx, messageID, userID)
+}
+
+// Search operations
+
+func (s *publisherMessagingService) SearchMessages(ctx context.Context, organizationID int64, query string, conversationID *int64, page, pageSize int) (*domain.MessageSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > domain.MaxMessageSearchQueryLength {
+		return nil, fmt.Errorf("%w: q must be 1-%d characters", domain.ErrInvalidInput, domain.MaxMessageSearchQueryLength)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if conversationID != nil {
+		if _, err := s.getOwnedConversation(ctx, organizationID, *conversationID); err != nil {
+			return nil, err
+		}
+	}
+
+	results, total, err := s.messagingRepo.SearchMessages(ctx, organizationID, query, conversationID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MessageSearchResponse{
+		Results:  results,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
 // Utility operations
 
 func (s *publisherMessagingService) FindOrCreateConversation(ctx context.Context, organizationID int64, publisherDomain string, subject string) (*domain.PublisherConversation, error) {
@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// PublisherPipelineService manages the outreach pipeline of favorite publisher lists:
+// configurable stages, a kanban board per list, item owners with next-action reminders
+// and the stage history of each item
+type PublisherPipelineService interface {
+	// Stage management; the default stages are created on first use
+	ListStages(ctx context.Context, organizationID int64) ([]*domain.PipelineStage, error)
+	CreateStage(ctx context.Context, organizationID int64, req *domain.CreatePipelineStageRequest) (*domain.PipelineStage, error)
+	UpdateStage(ctx context.Context, organizationID, stageID int64, req *domain.UpdatePipelineStageRequest) (*domain.PipelineStage, error)
+	DeleteStage(ctx context.Context, organizationID, stageID int64) error
+	ReorderStages(ctx context.Context, organizationID int64, req *domain.ReorderPipelineStagesRequest) ([]*domain.PipelineStage, error)
+
+	// Board operations
+	GetBoard(ctx context.Context, organizationID, listID int64, query *domain.PipelineBoardQuery) (*domain.PipelineBoard, error)
+	// MoveItem moves a list item to another stage and notes the change in the active
+	// conversation with the publisher, if there is one
+	MoveItem(ctx context.Context, organizationID, listID int64, publisherDomain, userID string, req *domain.MovePublisherStageRequest) (*domain.FavoritePublisherListItem, error)
+	UpdateFollowUp(ctx context.Context, organizationID, listID int64, publisherDomain string, req *domain.UpdatePublisherFollowUpRequest) (*domain.FavoritePublisherListItem, error)
+	GetItemHistory(ctx context.Context, organizationID, listID int64, publisherDomain string, limit int) ([]*domain.PipelineStageChange, error)
+
+	// RunDueReminders emails the owners of items whose next action has passed and returns how many were sent
+	RunDueReminders(ctx context.Context, now time.Time) (int, error)
+}
+
+// publisherPipelineService implements PublisherPipelineService
+type publisherPipelineService struct {
+	pipelineRepo  repository.PublisherPipelineRepository
+	favListRepo   repository.FavoritePublisherListRepository
+	messagingRepo repository.PublisherMessagingRepository
+	profileRepo   repository.ProfileRepository
+	emailSender   email.Sender
+}
+
+// NewPublisherPipelineService creates a new publisher pipeline service
+func NewPublisherPipelineService(
+	pipelineRepo repository.PublisherPipelineRepository,
+	favListRepo repository.FavoritePublisherListRepository,
+	messagingRepo repository.PublisherMessagingRepository,
+	profileRepo repository.ProfileRepository,
+	emailSender email.Sender,
+) PublisherPipelineService {
+	return &publisherPipelineService{
+		pipelineRepo:  pipelineRepo,
+		favListRepo:   favListRepo,
+		messagingRepo: messagingRepo,
+		profileRepo:   profileRepo,
+		emailSender:   emailSender,
+	}
+}
+
+// ListStages returns the stages of an organization in board order
+func (s *publisherPipelineService) ListStages(ctx context.Context, organizationID int64) ([]*domain.PipelineStage, error) {
+	return loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+}
+
+// CreateStage adds a stage, at the end of the board unless a position is given
+func (s *publisherPipelineService) CreateStage(ctx context.Context, organizationID int64, req *domain.CreatePipelineStageRequest) (*domain.PipelineStage, error) {
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stages) >= domain.MaxPipelineStages {
+		return nil, fmt.Errorf("%w: at most %d pipeline stages are allowed", domain.ErrInvalidInput, domain.MaxPipelineStages)
+	}
+
+	stage := &domain.PipelineStage{
+		OrganizationID: organizationID,
+		Key:            strings.TrimSpace(req.Key),
+		Name:           strings.TrimSpace(req.Name),
+		Position:       len(stages),
+		Outcome:        req.Outcome,
+		Color:          req.Color,
+	}
+	if err := stage.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	for _, existing := range stages {
+		if existing.Key == stage.Key {
+			return nil, fmt.Errorf("%w: a stage with key %s already exists", domain.ErrInvalidInput, stage.Key)
+		}
+	}
+
+	if err := s.pipelineRepo.CreateStage(ctx, stage); err != nil {
+		return nil, err
+	}
+
+	// Insert at the requested position by reordering the whole board
+	if req.Position != nil && *req.Position >= 0 && *req.Position < len(stages) {
+		order := make([]int64, 0, len(stages)+1)
+		for i, existing := range stages {
+			if i == *req.Position {
+				order = append(order, stage.StageID)
+			}
+			order = append(order, existing.StageID)
+		}
+		if err := s.pipelineRepo.ReorderStages(ctx, organizationID, order); err != nil {
+			return nil, err
+		}
+		stage.Position = *req.Position
+	}
+
+	return stage, nil
+}
+
+// UpdateStage renames, recolors or changes the outcome of a stage
+func (s *publisherPipelineService) UpdateStage(ctx context.Context, organizationID, stageID int64, req *domain.UpdatePipelineStageRequest) (*domain.PipelineStage, error) {
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	stage := domain.FindPipelineStage(stages, stageID)
+	if stage == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if req.Name != nil {
+		stage.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Outcome != nil {
+		stage.Outcome = *req.Outcome
+	}
+	if req.Color != nil {
+		stage.Color = req.Color
+		if *req.Color == "" {
+			stage.Color = nil
+		}
+	}
+	if err := stage.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if domain.EntryPipelineStage(stages) == nil {
+		return nil, fmt.Errorf("%w: the pipeline needs at least one open stage", domain.ErrInvalidInput)
+	}
+
+	if err := s.pipelineRepo.UpdateStage(ctx, stage); err != nil {
+		return nil, err
+	}
+	return stage, nil
+}
+
+// DeleteStage deletes an empty stage
+func (s *publisherPipelineService) DeleteStage(ctx context.Context, organizationID, stageID int64) error {
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return err
+	}
+	stage := domain.FindPipelineStage(stages, stageID)
+	if stage == nil {
+		return domain.ErrNotFound
+	}
+
+	count, err := s.pipelineRepo.CountItemsInStage(ctx, stageID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: stage %s still holds %d publishers; move them to another stage first", domain.ErrInvalidInput, stage.Name, count)
+	}
+
+	remaining := make([]*domain.PipelineStage, 0, len(stages)-1)
+	for _, other := range stages {
+		if other.StageID != stageID {
+			remaining = append(remaining, other)
+		}
+	}
+	if domain.EntryPipelineStage(remaining) == nil {
+		return fmt.Errorf("%w: the pipeline needs at least one open stage", domain.ErrInvalidInput)
+	}
+
+	return s.pipelineRepo.DeleteStage(ctx, stageID)
+}
+
+// ReorderStages changes the board order; every stage of the organization must be listed once
+func (s *publisherPipelineService) ReorderStages(ctx context.Context, organizationID int64, req *domain.ReorderPipelineStagesRequest) ([]*domain.PipelineStage, error) {
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.StageIDs) != len(stages) {
+		return nil, fmt.Errorf("%w: stage_ids must list all %d stages", domain.ErrInvalidInput, len(stages))
+	}
+	seen := make(map[int64]bool, len(req.StageIDs))
+	for _, stageID := range req.StageIDs {
+		if domain.FindPipelineStage(stages, stageID) == nil || seen[stageID] {
+			return nil, fmt.Errorf("%w: unknown or repeated stage %d", domain.ErrInvalidInput, stageID)
+		}
+		seen[stageID] = true
+	}
+
+	if err := s.pipelineRepo.ReorderStages(ctx, organizationID, req.StageIDs); err != nil {
+		return nil, err
+	}
+	return s.pipelineRepo.ListStages(ctx, organizationID)
+}
+
+// GetBoard returns the items of a list grouped by stage; items without a stage are shown in the entry stage
+func (s *publisherPipelineService) GetBoard(ctx context.Context, organizationID, listID int64, query *domain.PipelineBoardQuery) (*domain.PipelineBoard, error) {
+	list, err := s.getOwnedList(ctx, organizationID, listID)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.favListRepo.GetListItemsWithPublisherDetails(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := s.pipelineRepo.GetListConversationIDs(ctx, organizationID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &domain.PipelineBoard{List: list, Columns: make([]*domain.PipelineBoardColumn, len(stages))}
+	columns := make(map[int64]*domain.PipelineBoardColumn, len(stages))
+	for i, stage := range stages {
+		board.Columns[i] = &domain.PipelineBoardColumn{Stage: stage, Items: make([]*domain.FavoritePublisherListItem, 0)}
+		columns[stage.StageID] = board.Columns[i]
+	}
+	entry := domain.EntryPipelineStage(stages)
+
+	for _, item := range items {
+		if !matchesBoardQuery(item, query) {
+			continue
+		}
+		if conversationID, ok := conversations[item.PublisherDomain]; ok {
+			item.ConversationID = &conversationID
+		}
+
+		var column *domain.PipelineBoardColumn
+		if item.StageID != nil {
+			column = columns[*item.StageID]
+		}
+		if column == nil && entry != nil {
+			column = columns[entry.StageID]
+		}
+		if column != nil {
+			column.Items = append(column.Items, item)
+			column.Count++
+		}
+	}
+
+	return board, nil
+}
+
+func matchesBoardQuery(item *domain.FavoritePublisherListItem, query *domain.PipelineBoardQuery) bool {
+	if query == nil {
+		return true
+	}
+	if query.OwnerUserID != nil && (item.OwnerUserID == nil || !strings.EqualFold(*item.OwnerUserID, *query.OwnerUserID)) {
+		return false
+	}
+	if query.DueBefore != nil && (item.NextActionAt == nil || !item.NextActionAt.Before(*query.DueBefore)) {
+		return false
+	}
+	return true
+}
+
+// MoveItem moves a list item to another stage
+func (s *publisherPipelineService) MoveItem(ctx context.Context, organizationID, listID int64, publisherDomain, userID string, req *domain.MovePublisherStageRequest) (*domain.FavoritePublisherListItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.getOwnedList(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+	item, err := s.favListRepo.GetPublisherFromList(ctx, listID, publisherDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	stages, err := loadPipelineStages(ctx, s.pipelineRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	target := domain.FindPipelineStage(stages, req.StageID)
+	if target == nil {
+		return nil, fmt.Errorf("%w: stage %d does not exist", domain.ErrInvalidInput, req.StageID)
+	}
+	if item.StageID != nil && *item.StageID == target.StageID {
+		return item, nil
+	}
+
+	var changedBy *string
+	if userID != "" {
+		changedBy = &userID
+	}
+	change, err := moveListItem(ctx, s.pipelineRepo, stages, item, target, domain.PipelineChangeReasonMoved, changedBy, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	s.noteStageChange(ctx, organizationID, item, change)
+	return item, nil
+}
+
+// noteStageChange adds a system message about a stage change to the active conversation with
+// the publisher. Failures are only logged; the move itself already succeeded.
+func (s *publisherPipelineService) noteStageChange(ctx context.Context, organizationID int64, item *domain.FavoritePublisherListItem, change *domain.PipelineStageChange) {
+	conversation, err := s.messagingRepo.GetConversationByPublisher(ctx, organizationID, item.PublisherDomain, domain.ConversationStatusActive)
+	if err != nil {
+		if err != domain.ErrNotFound {
+			logger.Warn("Failed to find conversation for pipeline stage change", "item_id", item.ItemID, "error", err)
+		}
+		return
+	}
+
+	content := "Pipeline stage set to " + change.ToStageName
+	if change.FromStageName != nil {
+		content = fmt.Sprintf("Pipeline stage changed from %s to %s", *change.FromStageName, change.ToStageName)
+	}
+	if change.Note != nil && strings.TrimSpace(*change.Note) != "" {
+		content += ": " + strings.TrimSpace(*change.Note)
+	}
+
+	message := &domain.PublisherMessage{
+		ConversationID: conversation.ConversationID,
+		SenderType:     domain.SenderTypeSystem,
+		SenderID:       change.ChangedByUserID,
+		Content:        content,
+		MessageType:    domain.MessageTypeSystem,
+		Metadata: map[string]interface{}{
+			"pipeline_change": map[string]interface{}{
+				"list_id":     item.ListID,
+				"item_id":     item.ItemID,
+				"change_id":   change.ChangeID,
+				"to_stage_id": change.ToStageID,
+			},
+		},
+	}
+	if err := s.messagingRepo.CreateMessage(ctx, message); err != nil {
+		logger.Warn("Failed to add pipeline stage change to conversation", "conversation_id", conversation.ConversationID, "error", err)
+	}
+}
+
+// UpdateFollowUp sets the owner and next action of a list item
+func (s *publisherPipelineService) UpdateFollowUp(ctx context.Context, organizationID, listID int64, publisherDomain string, req *domain.UpdatePublisherFollowUpRequest) (*domain.FavoritePublisherListItem, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if _, err := s.getOwnedList(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+	item, err := s.favListRepo.GetPublisherFromList(ctx, listID, publisherDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.OwnerUserID != nil && *req.OwnerUserID != "" {
+		if err := s.validateOwner(ctx, organizationID, *req.OwnerUserID); err != nil {
+			return nil, err
+		}
+		item.OwnerUserID = req.OwnerUserID
+	} else {
+		item.OwnerUserID = nil
+	}
+	item.NextActionAt = req.NextActionAt
+	item.NextActionNote = req.NextActionNote
+
+	if err := s.pipelineRepo.UpdateFollowUp(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// validateOwner checks that the owner is a user of the organization
+func (s *publisherPipelineService) validateOwner(ctx context.Context, organizationID int64, ownerUserID string) error {
+	id, err := uuid.Parse(ownerUserID)
+	if err != nil {
+		return fmt.Errorf("%w: owner_user_id must be a valid UUID", domain.ErrInvalidInput)
+	}
+	profile, err := s.profileRepo.GetProfileByID(ctx, id)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("failed to get owner profile: %w", err)
+	}
+	if profile == nil || profile.OrganizationID == nil || *profile.OrganizationID != organizationID {
+		return fmt.Errorf("%w: owner %s is not a user of the organization", domain.ErrInvalidInput, ownerUserID)
+	}
+	return nil
+}
+
+// GetItemHistory returns the stage history of a list item, most recent first
+func (s *publisherPipelineService) GetItemHistory(ctx context.Context, organizationID, listID int64, publisherDomain string, limit int) ([]*domain.PipelineStageChange, error) {
+	if _, err := s.getOwnedList(ctx, organizationID, listID); err != nil {
+		return nil, err
+	}
+	item, err := s.favListRepo.GetPublisherFromList(ctx, listID, publisherDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 {
+		limit = domain.DefaultPipelineHistoryLimit
+	}
+	if limit > domain.MaxPipelineHistoryLimit {
+		limit = domain.MaxPipelineHistoryLimit
+	}
+	return s.pipelineRepo.ListStageChanges(ctx, item.ItemID, limit)
+}
+
+// RunDueReminders claims and sends the reminders that are due. Each reminder is claimed
+// before it is sent, so several API instances can run the scheduler safely.
+func (s *publisherPipelineService) RunDueReminders(ctx context.Context, now time.Time) (int, error) {
+	reminders, err := s.pipelineRepo.ListDueReminders(ctx, now, domain.PipelineReminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		claimed, err := s.pipelineRepo.ClaimReminder(ctx, reminder.ItemID, reminder.NextActionAt)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if _, err := s.emailSender.Send(ctx, pipelineReminderEmail(reminder)); err != nil {
+			logger.Error("Failed to send pipeline reminder", "item_id", reminder.ItemID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// pipelineReminderEmail renders the reminder email for a due next action
+func pipelineReminderEmail(reminder *domain.PipelineReminder) *email.Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Your next action for %s in the list %q was due %s.\n\n",
+		reminder.PublisherDomain, reminder.ListName, reminder.NextActionAt.UTC().Format("2006-01-02 15:04 MST"))
+	if reminder.StageName != nil {
+		fmt.Fprintf(&body, "Stage: %s\n", *reminder.StageName)
+	}
+	if reminder.NextActionNote != nil && *reminder.NextActionNote != "" {
+		fmt.Fprintf(&body, "Next action: %s\n", *reminder.NextActionNote)
+	}
+
+	return &email.Message{
+		To:       []string{reminder.OwnerEmail},
+		Subject:  "Reminder: follow up with " + reminder.PublisherDomain,
+		TextBody: body.String(),
+	}
+}
+
+// getOwnedList loads a list and hides lists of other organizations
+func (s *publisherPipelineService) getOwnedList(ctx context.Context, organizationID, listID int64) (*domain.FavoritePublisherList, error) {
+	list, err := s.favListRepo.GetListByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if list.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+	return list, nil
+}
+
+// loadPipelineStages returns the stages of an organization in board order, creating the
+// default stages when the organization has none yet
+func loadPipelineStages(ctx context.Context, pipelineRepo repository.PublisherPipelineRepository, organizationID int64) ([]*domain.PipelineStage, error) {
+	stages, err := pipelineRepo.ListStages(ctx, organizationID)
+	if err != nil || len(stages) > 0 {
+		return stages, err
+	}
+
+	if err := pipelineRepo.CreateDefaultStages(ctx, organizationID); err != nil {
+		return nil, err
+	}
+	return pipelineRepo.ListStages(ctx, organizationID)
+}
+
+// moveListItem moves an item to a stage, keeping its legacy status in sync, and records the
+// change in the item's history
+func moveListItem(ctx context.Context, pipelineRepo repository.PublisherPipelineRepository, stages []*domain.PipelineStage,
+	item *domain.FavoritePublisherListItem, target *domain.PipelineStage, reason string, changedBy, note *string) (*domain.PipelineStageChange, error) {
+	change := &domain.PipelineStageChange{
+		ItemID:          item.ItemID,
+		ToStageID:       &target.StageID,
+		ToStageName:     target.Name,
+		ChangedByUserID: changedBy,
+		Reason:          reason,
+		Note:            note,
+	}
+	if item.StageID != nil {
+		if from := domain.FindPipelineStage(stages, *item.StageID); from != nil {
+			change.FromStageID = &from.StageID
+			change.FromStageName = &from.Name
+		}
+	}
+
+	if err := pipelineRepo.MoveItem(ctx, item, domain.LegacyStatusForStage(stages, target), change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
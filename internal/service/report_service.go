@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// ReportService defines the interface for performance reporting
+type ReportService interface {
+	// RunReport runs a performance report restricted to the data the organization may see
+	RunReport(ctx context.Context, organizationID int64, req *domain.ReportRequest) (*domain.ReportResponse, error)
+}
+
+// reportService implements ReportService
+type reportService struct {
+	reportRepo repository.ReportRepository
+	orgRepo    repository.OrganizationRepository
+}
+
+// NewReportService creates a new report service
+func NewReportService(reportRepo repository.ReportRepository, orgRepo repository.OrganizationRepository) ReportService {
+	return &reportService{
+		reportRepo: reportRepo,
+		orgRepo:    orgRepo,
+	}
+}
+
+// RunReport validates the request, scopes it to the organization and computes the report metrics
+func (s *reportService) RunReport(ctx context.Context, organizationID int64, req *domain.ReportRequest) (*domain.ReportResponse, error) {
+	org, err := s.orgRepo.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrForbidden
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	scope := domain.ReportScope{
+		OrganizationID:   org.OrganizationID,
+		OrganizationType: org.Type,
+	}
+
+	query, err := req.ToQuery(scope, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, err.Error())
+	}
+
+	aggregates, total, totals, err := s.reportRepo.QueryReport(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]domain.ReportRow, 0, len(aggregates))
+	for _, agg := range aggregates {
+		rows = append(rows, domain.ReportRow{
+			ReportDimensionValues: agg.ReportDimensionValues,
+			Metrics:               domain.NewReportMetrics(agg.ReportTotals, org.Type),
+		})
+	}
+
+	groupBy := query.GroupBy
+	if groupBy == nil {
+		groupBy = []domain.ReportDimension{}
+	}
+
+	return &domain.ReportResponse{
+		Rows:     rows,
+		Totals:   domain.NewReportMetrics(*totals, org.Type),
+		GroupBy:  groupBy,
+		Timezone: query.Timezone,
+		From:     query.From.Format("2006-01-02"),
+		To:       query.To.Format("2006-01-02"),
+		Total:    total,
+		Page:     query.Offset/query.Limit + 1,
+		PageSize: query.Limit,
+	}, nil
+}
@@ -0,0 +1,729 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/crypto"
+	"github.com/affiliate-backend/internal/platform/email"
+	"github.com/affiliate-backend/internal/platform/export"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/netguard"
+	"github.com/affiliate-backend/internal/platform/storage"
+	"github.com/affiliate-backend/internal/repository"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// scheduledReportPageSize is the report page size used while collecting the rows of a run
+	scheduledReportPageSize = 1000
+	// maxScheduledReportAttachmentBytes is the largest file attached to a delivery email;
+	// larger files are only linked
+	maxScheduledReportAttachmentBytes = 10 * 1024 * 1024
+	// dueScheduledReportBatchSize limits how many due reports are picked up per scheduler tick
+	dueScheduledReportBatchSize = 50
+)
+
+// ScheduledReportService defines the interface for scheduled report operations
+type ScheduledReportService interface {
+	CreateScheduledReport(ctx context.Context, organizationID int64, userID string, req *domain.CreateScheduledReportRequest) (*domain.ScheduledReport, error)
+	GetScheduledReport(ctx context.Context, organizationID, id int64) (*domain.ScheduledReport, error)
+	ListScheduledReports(ctx context.Context, organizationID int64, page, pageSize int) (*domain.ScheduledReportListResponse, error)
+	UpdateScheduledReport(ctx context.Context, organizationID, id int64, req *domain.UpdateScheduledReportRequest) (*domain.ScheduledReport, error)
+	DeleteScheduledReport(ctx context.Context, organizationID, id int64) error
+
+	// RunNow renders and delivers the report immediately; delivery failures are recorded on the returned run
+	RunNow(ctx context.Context, organizationID, id int64) (*domain.ScheduledReportRun, error)
+	ListRuns(ctx context.Context, organizationID, id int64, page, pageSize int) (*domain.ScheduledReportRunListResponse, error)
+	// DownloadRun returns an archived run and its file
+	DownloadRun(ctx context.Context, organizationID, id, runID int64) (*domain.ScheduledReportRun, []byte, error)
+
+	// RunDueReports runs every active report whose next run time has passed and returns how many ran
+	RunDueReports(ctx context.Context, now time.Time) (int, error)
+}
+
+// scheduledReportService implements ScheduledReportService
+type scheduledReportService struct {
+	scheduledReportRepo repository.ScheduledReportRepository
+	reportService       ReportService
+	cryptoService       crypto.Service
+	emailSender         email.Sender
+	archiveStore        storage.ObjectStore
+	apiBaseURL          string
+}
+
+// NewScheduledReportService creates a new scheduled report service. Rendered files are kept in
+// archiveStore for download; apiBaseURL is used to build absolute download links in emails.
+func NewScheduledReportService(
+	scheduledReportRepo repository.ScheduledReportRepository,
+	reportService ReportService,
+	cryptoService crypto.Service,
+	emailSender email.Sender,
+	archiveStore storage.ObjectStore,
+	apiBaseURL string,
+) ScheduledReportService {
+	return &scheduledReportService{
+		scheduledReportRepo: scheduledReportRepo,
+		reportService:       reportService,
+		cryptoService:       cryptoService,
+		emailSender:         emailSender,
+		archiveStore:        archiveStore,
+		apiBaseURL:          strings.TrimSuffix(apiBaseURL, "/"),
+	}
+}
+
+// CreateScheduledReport validates and saves a new scheduled report
+func (s *scheduledReportService) CreateScheduledReport(ctx context.Context, organizationID int64, userID string, req *domain.CreateScheduledReportRequest) (*domain.ScheduledReport, error) {
+	report := &domain.ScheduledReport{
+		OrganizationID: organizationID,
+		Name:           strings.TrimSpace(req.Name),
+		Query:          req.Query,
+		DateRange:      req.DateRange,
+		Timezone:       req.Timezone,
+		Format:         req.Format,
+		Schedule:       strings.TrimSpace(req.Schedule),
+		DeliveryType:   req.DeliveryType,
+		Delivery:       req.Delivery,
+		IsActive:       true,
+	}
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if req.IsActive != nil {
+		report.IsActive = *req.IsActive
+	}
+	if userID != "" {
+		report.CreatedByUserID = &userID
+	}
+
+	if err := s.prepare(report, nil, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduledReportRepo.CreateScheduledReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	report.Redact()
+	return report, nil
+}
+
+// GetScheduledReport retrieves a scheduled report owned by the organization
+func (s *scheduledReportService) GetScheduledReport(ctx context.Context, organizationID, id int64) (*domain.ScheduledReport, error) {
+	report, err := s.getOwnedReport(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	report.Redact()
+	return report, nil
+}
+
+// ListScheduledReports lists the scheduled reports of the organization
+func (s *scheduledReportService) ListScheduledReports(ctx context.Context, organizationID int64, page, pageSize int) (*domain.ScheduledReportListResponse, error) {
+	reports, total, err := s.scheduledReportRepo.ListScheduledReports(ctx, organizationID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, report := range reports {
+		report.Redact()
+	}
+
+	return &domain.ScheduledReportListResponse{
+		ScheduledReports: reports,
+		Total:            total,
+		Page:             page,
+		PageSize:         pageSize,
+	}, nil
+}
+
+// UpdateScheduledReport applies the given changes and recomputes the next run time
+func (s *scheduledReportService) UpdateScheduledReport(ctx context.Context, organizationID, id int64, req *domain.UpdateScheduledReportRequest) (*domain.ScheduledReport, error) {
+	report, err := s.getOwnedReport(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	stored := report.Delivery
+
+	if req.Name != nil {
+		report.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Query != nil {
+		report.Query = *req.Query
+	}
+	if req.DateRange != nil {
+		report.DateRange = *req.DateRange
+	}
+	if req.Timezone != nil {
+		report.Timezone = *req.Timezone
+	}
+	if req.Format != nil {
+		report.Format = *req.Format
+	}
+	if req.Schedule != nil {
+		report.Schedule = strings.TrimSpace(*req.Schedule)
+	}
+	if req.DeliveryType != nil {
+		report.DeliveryType = *req.DeliveryType
+	}
+	if req.Delivery != nil {
+		report.Delivery = *req.Delivery
+	}
+	if req.IsActive != nil {
+		report.IsActive = *req.IsActive
+	}
+
+	if err := s.prepare(report, &stored, req.Delivery != nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduledReportRepo.UpdateScheduledReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	report.Redact()
+	return report, nil
+}
+
+// DeleteScheduledReport deletes a scheduled report owned by the organization
+func (s *scheduledReportService) DeleteScheduledReport(ctx context.Context, organizationID, id int64) error {
+	if _, err := s.getOwnedReport(ctx, organizationID, id); err != nil {
+		return err
+	}
+	return s.scheduledReportRepo.DeleteScheduledReport(ctx, id)
+}
+
+// RunNow runs the report immediately without changing its schedule
+func (s *scheduledReportService) RunNow(ctx context.Context, organizationID, id int64) (*domain.ScheduledReportRun, error) {
+	report, err := s.getOwnedReport(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.executeRun(ctx, report, domain.ScheduledReportRunTriggerManual, time.Now())
+}
+
+// ListRuns lists the run history of a scheduled report owned by the organization
+func (s *scheduledReportService) ListRuns(ctx context.Context, organizationID, id int64, page, pageSize int) (*domain.ScheduledReportRunListResponse, error) {
+	if _, err := s.getOwnedReport(ctx, organizationID, id); err != nil {
+		return nil, err
+	}
+
+	runs, total, err := s.scheduledReportRepo.ListRuns(ctx, id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, run := range runs {
+		s.setDownloadURL(run)
+	}
+
+	return &domain.ScheduledReportRunListResponse{
+		Runs:     runs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// DownloadRun loads the archived file of a run
+func (s *scheduledReportService) DownloadRun(ctx context.Context, organizationID, id, runID int64) (*domain.ScheduledReportRun, []byte, error) {
+	if _, err := s.getOwnedReport(ctx, organizationID, id); err != nil {
+		return nil, nil, err
+	}
+
+	run, err := s.scheduledReportRepo.GetRunByID(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run.ScheduledReportID != id || run.StorageKey == nil {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	data, err := s.archiveStore.Get(ctx, *run.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, domain.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load report file: %w", err)
+	}
+
+	return run, data, nil
+}
+
+// RunDueReports claims and runs the reports that are due. Each occurrence is claimed by
+// advancing next_run_at first, so several API instances can run the scheduler safely.
+func (s *scheduledReportService) RunDueReports(ctx context.Context, now time.Time) (int, error) {
+	reports, err := s.scheduledReportRepo.ListDueScheduledReports(ctx, now, dueScheduledReportBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ran := 0
+	for _, report := range reports {
+		var nextRunAt *time.Time
+		if next, err := nextScheduledRun(report.Schedule, report.Timezone, now); err != nil {
+			// The schedule was valid when saved; stop scheduling rather than retrying every tick
+			logger.Error("Invalid schedule on scheduled report", "scheduled_report_id", report.ScheduledReportID, "error", err)
+		} else {
+			nextRunAt = &next
+		}
+
+		claimed, err := s.scheduledReportRepo.ClaimScheduledRun(ctx, report.ScheduledReportID, *report.NextRunAt, nextRunAt)
+		if err != nil {
+			return ran, err
+		}
+		if !claimed {
+			continue
+		}
+
+		run, err := s.executeRun(ctx, report, domain.ScheduledReportRunTriggerScheduled, now)
+		if err != nil {
+			logger.Error("Failed to run scheduled report", "scheduled_report_id", report.ScheduledReportID, "error", err)
+			continue
+		}
+		if run.Status == domain.ScheduledReportRunStatusFailed {
+			logger.Warn("Scheduled report run failed", "scheduled_report_id", report.ScheduledReportID, "run_id", run.RunID, "error", *run.ErrorMessage)
+		}
+		ran++
+	}
+
+	return ran, nil
+}
+
+// getOwnedReport loads a report and hides reports of other organizations
+func (s *scheduledReportService) getOwnedReport(ctx context.Context, organizationID, id int64) (*domain.ScheduledReport, error) {
+	report, err := s.scheduledReportRepo.GetScheduledReportByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+	return report, nil
+}
+
+// prepare validates the report and computes the next run time. When the delivery was supplied
+// by the caller its secrets are encrypted, falling back to the ones in stored when omitted;
+// otherwise the delivery still holds the already encrypted secrets.
+func (s *scheduledReportService) prepare(report *domain.ScheduledReport, stored *domain.ScheduledReportDelivery, deliveryChanged bool) error {
+	if err := report.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidInput, err.Error())
+	}
+	if host := report.DestinationHost(); host != "" {
+		if err := netguard.CheckHost(host); err != nil {
+			return fmt.Errorf("%w: delivery host must be a public address: %s", domain.ErrInvalidInput, host)
+		}
+	}
+
+	if report.DeliveryType == domain.ScheduledReportDeliveryEmail {
+		report.Delivery = domain.ScheduledReportDelivery{Recipients: report.Delivery.Recipients}
+	} else {
+		report.Delivery.Recipients = nil
+	}
+
+	switch report.DeliveryType {
+	case domain.ScheduledReportDeliverySFTP:
+		report.Delivery.S3 = nil
+		sftp := *report.Delivery.SFTP
+		if deliveryChanged {
+			var storedPassword, storedPrivateKey string
+			if stored != nil && stored.SFTP != nil {
+				storedPassword, storedPrivateKey = stored.SFTP.Password, stored.SFTP.PrivateKey
+			}
+			var err error
+			if sftp.Password, err = s.encryptSecret(sftp.Password, storedPassword); err != nil {
+				return err
+			}
+			if sftp.PrivateKey, err = s.encryptSecret(sftp.PrivateKey, storedPrivateKey); err != nil {
+				return err
+			}
+		}
+		if sftp.Password == "" && sftp.PrivateKey == "" {
+			return fmt.Errorf("%w: sftp delivery requires a password or private key", domain.ErrInvalidInput)
+		}
+		report.Delivery.SFTP = &sftp
+	case domain.ScheduledReportDeliveryS3:
+		report.Delivery.SFTP = nil
+		s3 := *report.Delivery.S3
+		if deliveryChanged {
+			var storedSecretKey string
+			if stored != nil && stored.S3 != nil {
+				storedSecretKey = stored.S3.SecretAccessKey
+			}
+			var err error
+			if s3.SecretAccessKey, err = s.encryptSecret(s3.SecretAccessKey, storedSecretKey); err != nil {
+				return err
+			}
+		}
+		if s3.SecretAccessKey == "" {
+			return fmt.Errorf("%w: s3 delivery requires secret_access_key", domain.ErrInvalidInput)
+		}
+		report.Delivery.S3 = &s3
+	}
+
+	next, err := nextScheduledRun(report.Schedule, report.Timezone, time.Now())
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidInput, err.Error())
+	}
+	report.NextRunAt = nil
+	if report.IsActive {
+		report.NextRunAt = &next
+	}
+
+	return nil
+}
+
+// encryptSecret encrypts a newly supplied secret, or returns the already encrypted stored
+// value when the secret was omitted
+func (s *scheduledReportService) encryptSecret(value, stored string) (string, error) {
+	if value == "" {
+		return stored, nil
+	}
+	encrypted, err := s.cryptoService.Encrypt(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt delivery secret: %w", err)
+	}
+	return encrypted, nil
+}
+
+// executeRun renders the report, archives the file and delivers it, recording the outcome.
+// An error is only returned when the run could not be recorded at all.
+func (s *scheduledReportService) executeRun(ctx context.Context, report *domain.ScheduledReport, trigger string, now time.Time) (*domain.ScheduledReportRun, error) {
+	from, to, err := report.ResolveDateRange(now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, err.Error())
+	}
+
+	run := &domain.ScheduledReportRun{
+		ScheduledReportID: report.ScheduledReportID,
+		Status:            domain.ScheduledReportRunStatusRunning,
+		TriggeredBy:       trigger,
+		RangeFrom:         from,
+		RangeTo:           to,
+		StartedAt:         time.Now(),
+	}
+	if err := s.scheduledReportRepo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	runErr := s.renderAndDeliver(ctx, report, run)
+
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	run.Status = domain.ScheduledReportRunStatusCompleted
+	if runErr != nil {
+		message := runErr.Error()
+		run.Status = domain.ScheduledReportRunStatusFailed
+		run.ErrorMessage = &message
+	}
+
+	if err := s.scheduledReportRepo.UpdateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	s.setDownloadURL(run)
+	return run, nil
+}
+
+// renderAndDeliver fills in the file details of the run as it progresses
+func (s *scheduledReportService) renderAndDeliver(ctx context.Context, report *domain.ScheduledReport, run *domain.ScheduledReportRun) error {
+	table, err := s.buildTable(ctx, report, run.RangeFrom, run.RangeTo)
+	if err != nil {
+		return err
+	}
+	run.RowCount = len(table.Rows)
+
+	file, err := export.Render(report.Format, table)
+	if err != nil {
+		return err
+	}
+
+	fileName := scheduledReportFileName(report, run, file.Extension)
+	fileSize := int64(len(file.Data))
+	run.FileName = &fileName
+	run.ContentType = &file.ContentType
+	run.FileSize = &fileSize
+
+	storageKey := fmt.Sprintf("scheduled-reports/%d/%d/%d/%s", report.OrganizationID, report.ScheduledReportID, run.RunID, fileName)
+	if err := s.archiveStore.Put(ctx, storageKey, file.ContentType, file.Data); err != nil {
+		return fmt.Errorf("failed to archive report file: %w", err)
+	}
+	run.StorageKey = &storageKey
+
+	switch report.DeliveryType {
+	case domain.ScheduledReportDeliveryEmail:
+		return s.deliverByEmail(ctx, report, run, file)
+	case domain.ScheduledReportDeliverySFTP, domain.ScheduledReportDeliveryS3:
+		store, key, err := s.destinationStore(report, fileName)
+		if err != nil {
+			return err
+		}
+		if err := store.Put(ctx, key, file.ContentType, file.Data); err != nil {
+			return fmt.Errorf("failed to deliver report file: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported delivery type: %s", report.DeliveryType)
+	}
+}
+
+// buildTable collects all report rows for the range, page by page
+func (s *scheduledReportService) buildTable(ctx context.Context, report *domain.ScheduledReport, from, to time.Time) (*export.Table, error) {
+	req := &domain.ReportRequest{
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Timezone:  report.Timezone,
+		GroupBy:   report.Query.GroupBy,
+		Filters:   report.Query.Filters,
+		SortBy:    report.Query.SortBy,
+		SortOrder: report.Query.SortOrder,
+		Page:      1,
+		PageSize:  scheduledReportPageSize,
+	}
+
+	var table *export.Table
+	for {
+		resp, err := s.reportService.RunReport(ctx, report.OrganizationID, req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Total > domain.MaxScheduledReportRows {
+			return nil, fmt.Errorf("report has %d rows, more than the maximum of %d; narrow the filters or grouping", resp.Total, domain.MaxScheduledReportRows)
+		}
+
+		if table == nil {
+			table = &export.Table{
+				Columns: scheduledReportColumns(resp.GroupBy, resp.Totals),
+				Rows:    make([][]interface{}, 0, resp.Total),
+			}
+		}
+		for _, row := range resp.Rows {
+			table.Rows = append(table.Rows, scheduledReportRow(resp.GroupBy, row))
+		}
+
+		if len(resp.Rows) < req.PageSize || len(table.Rows) >= resp.Total {
+			return table, nil
+		}
+		req.Page++
+	}
+}
+
+// deliverByEmail sends the file to the recipients, attaching it when it is small enough
+func (s *scheduledReportService) deliverByEmail(ctx context.Context, report *domain.ScheduledReport, run *domain.ScheduledReportRun, file *export.File) error {
+	s.setDownloadURL(run)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Your scheduled report %q for %s to %s (%s) is ready.\n\n",
+		report.Name, run.RangeFrom.Format("2006-01-02"), run.RangeTo.Format("2006-01-02"), report.Timezone)
+	fmt.Fprintf(&body, "Rows: %d\n", run.RowCount)
+
+	msg := &email.Message{
+		To:      report.Delivery.Recipients,
+		Subject: fmt.Sprintf("Scheduled report: %s (%s)", report.Name, run.RangeTo.Format("2006-01-02")),
+	}
+	if len(file.Data) <= maxScheduledReportAttachmentBytes {
+		msg.Attachments = []email.Attachment{{Filename: *run.FileName, ContentType: file.ContentType, Data: file.Data}}
+		body.WriteString("The report is attached.\n")
+	} else {
+		body.WriteString("The report is too large to attach.\n")
+	}
+	if run.DownloadURL != nil {
+		fmt.Fprintf(&body, "Download: %s\n", *run.DownloadURL)
+	}
+	msg.TextBody = body.String()
+
+	if _, err := s.emailSender.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to email report: %w", err)
+	}
+	return nil
+}
+
+// destinationStore builds the store for an SFTP or S3 destination with decrypted credentials
+// and returns the object key the file is written to
+func (s *scheduledReportService) destinationStore(report *domain.ScheduledReport, fileName string) (storage.ObjectStore, string, error) {
+	switch report.DeliveryType {
+	case domain.ScheduledReportDeliverySFTP:
+		dest := report.Delivery.SFTP
+		password, err := s.decryptSecret(dest.Password)
+		if err != nil {
+			return nil, "", err
+		}
+		privateKey, err := s.decryptSecret(dest.PrivateKey)
+		if err != nil {
+			return nil, "", err
+		}
+		return storage.NewSFTPStore(storage.SFTPConfig{
+			Host:               dest.Host,
+			Port:               dest.Port,
+			Username:           dest.Username,
+			Password:           password,
+			PrivateKey:         privateKey,
+			Directory:          dest.Directory,
+			HostKeyFingerprint: dest.HostKeyFingerprint,
+			PublicOnly:         true,
+		}), fileName, nil
+	case domain.ScheduledReportDeliveryS3:
+		dest := report.Delivery.S3
+		secretKey, err := s.decryptSecret(dest.SecretAccessKey)
+		if err != nil {
+			return nil, "", err
+		}
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        dest.Endpoint,
+			Region:          dest.Region,
+			Bucket:          dest.Bucket,
+			AccessKeyID:     dest.AccessKeyID,
+			SecretAccessKey: secretKey,
+			PublicOnly:      true,
+		}), path.Join(dest.Prefix, fileName), nil
+	default:
+		return nil, "", fmt.Errorf("unsupported delivery type: %s", report.DeliveryType)
+	}
+}
+
+// decryptSecret decrypts a stored delivery secret; empty secrets stay empty
+func (s *scheduledReportService) decryptSecret(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	decrypted, err := s.cryptoService.Decrypt(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt delivery secret: %w", err)
+	}
+	return decrypted, nil
+}
+
+// setDownloadURL sets the download link of an archived run
+func (s *scheduledReportService) setDownloadURL(run *domain.ScheduledReportRun) {
+	if run.StorageKey == nil {
+		return
+	}
+	url := fmt.Sprintf("%s/api/v1/scheduled-reports/%d/runs/%d/download", s.apiBaseURL, run.ScheduledReportID, run.RunID)
+	run.DownloadURL = &url
+}
+
+// nextScheduledRun returns the first occurrence of a standard cron schedule after the given time,
+// evaluated in the report timezone
+func nextScheduledRun(schedule, timezone string, after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q", timezone)
+	}
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %v", schedule, err)
+	}
+	next := parsed.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("schedule %q never runs", schedule)
+	}
+	return next.UTC(), nil
+}
+
+// scheduledReportFileName builds a descriptive file name such as "daily-revenue_2024-03-01_2024-03-07.csv"
+func scheduledReportFileName(report *domain.ScheduledReport, run *domain.ScheduledReportRun, extension string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(report.Name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			slug.WriteRune(r)
+		case slug.Len() > 0 && !strings.HasSuffix(slug.String(), "-"):
+			slug.WriteByte('-')
+		}
+	}
+	name := strings.Trim(slug.String(), "-")
+	if name == "" {
+		name = "report"
+	}
+	if len(name) > 60 {
+		name = strings.Trim(name[:60], "-")
+	}
+	return fmt.Sprintf("%s_%s_%s.%s", name, run.RangeFrom.Format("2006-01-02"), run.RangeTo.Format("2006-01-02"), extension)
+}
+
+// scheduledReportColumns returns the file columns: the grouped dimensions followed by the
+// metrics visible to the organization
+func scheduledReportColumns(groupBy []domain.ReportDimension, totals domain.ReportMetrics) []string {
+	columns := make([]string, 0, len(groupBy)+11)
+	for _, dimension := range groupBy {
+		switch dimension {
+		case domain.ReportDimensionCampaign:
+			columns = append(columns, "campaign_id", "campaign_name")
+		case domain.ReportDimensionAffiliate:
+			columns = append(columns, "affiliate_id", "affiliate_name")
+		default:
+			columns = append(columns, string(dimension))
+		}
+	}
+
+	columns = append(columns, "impressions", "clicks", "unique_clicks", "conversions")
+	if totals.Revenue != nil {
+		columns = append(columns, "revenue")
+	}
+	if totals.Payout != nil {
+		columns = append(columns, "payout")
+	}
+	columns = append(columns, "cr", "epc", "cpa")
+	if totals.Margin != nil {
+		columns = append(columns, "margin", "margin_percent")
+	}
+	return columns
+}
+
+// scheduledReportRow returns the cells of a report row in the order of scheduledReportColumns
+func scheduledReportRow(groupBy []domain.ReportDimension, row domain.ReportRow) []interface{} {
+	cells := make([]interface{}, 0, len(groupBy)+11)
+	for _, dimension := range groupBy {
+		switch dimension {
+		case domain.ReportDimensionDate:
+			cells = append(cells, derefCell(row.Date))
+		case domain.ReportDimensionHour:
+			cells = append(cells, derefCell(row.Hour))
+		case domain.ReportDimensionCampaign:
+			cells = append(cells, derefCell(row.CampaignID), derefCell(row.CampaignName))
+		case domain.ReportDimensionAffiliate:
+			cells = append(cells, derefCell(row.AffiliateID), derefCell(row.AffiliateName))
+		case domain.ReportDimensionSub1:
+			cells = append(cells, derefCell(row.Sub1))
+		case domain.ReportDimensionSub2:
+			cells = append(cells, derefCell(row.Sub2))
+		case domain.ReportDimensionSub3:
+			cells = append(cells, derefCell(row.Sub3))
+		case domain.ReportDimensionSub4:
+			cells = append(cells, derefCell(row.Sub4))
+		case domain.ReportDimensionSub5:
+			cells = append(cells, derefCell(row.Sub5))
+		case domain.ReportDimensionCountry:
+			cells = append(cells, derefCell(row.Country))
+		case domain.ReportDimensionDevice:
+			cells = append(cells, derefCell(row.Device))
+		}
+	}
+
+	metrics := row.Metrics
+	cells = append(cells, metrics.Impressions, metrics.Clicks, metrics.UniqueClicks, metrics.Conversions)
+	if metrics.Revenue != nil {
+		cells = append(cells, *metrics.Revenue)
+	}
+	if metrics.Payout != nil {
+		cells = append(cells, *metrics.Payout)
+	}
+	cells = append(cells, metrics.ConversionRate, metrics.EPC, metrics.CPA)
+	if metrics.Margin != nil {
+		cells = append(cells, *metrics.Margin, *metrics.MarginPercent)
+	}
+	return cells
+}
+
+// derefCell turns an optional dimension value into a table cell
+func derefCell[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
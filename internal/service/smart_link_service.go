@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// SmartLinkService defines the interface for smart link operations
+type SmartLinkService interface {
+	CreateSmartLink(ctx context.Context, orgID int64, req *domain.CreateSmartLinkRequest) (*domain.SmartLink, error)
+	GetSmartLink(ctx context.Context, orgID, smartLinkID int64) (*domain.SmartLink, error)
+	ListSmartLinks(ctx context.Context, orgID int64) ([]*domain.SmartLink, error)
+	UpdateSmartLink(ctx context.Context, orgID, smartLinkID int64, req *domain.UpdateSmartLinkRequest) (*domain.SmartLink, error)
+	DeleteSmartLink(ctx context.Context, orgID, smartLinkID int64) error
+
+	// SimulateSmartLink evaluates a click without redirecting or counting it
+	SimulateSmartLink(ctx context.Context, orgID, smartLinkID int64, req *domain.SimulateSmartLinkRequest) (*domain.SmartLinkSimulation, error)
+	// GetSmartLinkStats returns the clicks per destination between two UTC dates, inclusive
+	GetSmartLinkStats(ctx context.Context, orgID, smartLinkID int64, from, to time.Time) (*domain.SmartLinkStats, error)
+
+	// RouteClick picks the destination of a click, counts it and returns the URL to redirect to
+	RouteClick(ctx context.Context, smartLinkID int64, click domain.SmartLinkClick) (string, error)
+}
+
+// smartLinkService implements SmartLinkService
+type smartLinkService struct {
+	smartLinkRepo    repository.SmartLinkRepository
+	trackingLinkRepo repository.TrackingLinkRepository
+	affiliateRepo    repository.AffiliateRepository
+}
+
+// NewSmartLinkService creates a new smart link service
+func NewSmartLinkService(
+	smartLinkRepo repository.SmartLinkRepository,
+	trackingLinkRepo repository.TrackingLinkRepository,
+	affiliateRepo repository.AffiliateRepository,
+) SmartLinkService {
+	return &smartLinkService{
+		smartLinkRepo:    smartLinkRepo,
+		trackingLinkRepo: trackingLinkRepo,
+		affiliateRepo:    affiliateRepo,
+	}
+}
+
+// CreateSmartLink creates a smart link for an affiliate of the organization
+func (s *smartLinkService) CreateSmartLink(ctx context.Context, orgID int64, req *domain.CreateSmartLinkRequest) (*domain.SmartLink, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	affiliate, err := s.affiliateRepo.GetAffiliateByID(ctx, req.AffiliateID)
+	if err != nil || affiliate.OrganizationID != orgID {
+		if err == nil || errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: affiliate %d does not belong to the organization", domain.ErrInvalidInput, req.AffiliateID)
+		}
+		return nil, err
+	}
+
+	smartLink := &domain.SmartLink{
+		OrganizationID: orgID,
+		AffiliateID:    req.AffiliateID,
+		Name:           req.Name,
+		Status:         domain.SmartLinkStatusActive,
+		FallbackURL:    req.FallbackURL,
+	}
+	if smartLink.Destinations, err = s.buildDestinations(ctx, req.AffiliateID, req.Destinations); err != nil {
+		return nil, err
+	}
+
+	if err := s.smartLinkRepo.CreateSmartLink(ctx, smartLink); err != nil {
+		return nil, err
+	}
+	return smartLink, nil
+}
+
+// GetSmartLink returns a smart link of the organization
+func (s *smartLinkService) GetSmartLink(ctx context.Context, orgID, smartLinkID int64) (*domain.SmartLink, error) {
+	return s.getOwnedSmartLink(ctx, orgID, smartLinkID)
+}
+
+// ListSmartLinks lists the smart links of the organization
+func (s *smartLinkService) ListSmartLinks(ctx context.Context, orgID int64) ([]*domain.SmartLink, error) {
+	return s.smartLinkRepo.ListSmartLinks(ctx, orgID)
+}
+
+// UpdateSmartLink updates a smart link of the organization
+func (s *smartLinkService) UpdateSmartLink(ctx context.Context, orgID, smartLinkID int64, req *domain.UpdateSmartLinkRequest) (*domain.SmartLink, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	smartLink, err := s.getOwnedSmartLink(ctx, orgID, smartLinkID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		smartLink.Name = *req.Name
+	}
+	if req.Status != nil {
+		smartLink.Status = *req.Status
+	}
+	if req.FallbackURL != nil {
+		if *req.FallbackURL == "" {
+			smartLink.FallbackURL = nil
+		} else {
+			smartLink.FallbackURL = req.FallbackURL
+		}
+	}
+	replaceDestinations := req.Destinations != nil
+	if replaceDestinations {
+		if smartLink.Destinations, err = s.buildDestinations(ctx, smartLink.AffiliateID, req.Destinations); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.smartLinkRepo.UpdateSmartLink(ctx, smartLink, replaceDestinations); err != nil {
+		return nil, err
+	}
+	return smartLink, nil
+}
+
+// DeleteSmartLink deletes a smart link of the organization
+func (s *smartLinkService) DeleteSmartLink(ctx context.Context, orgID, smartLinkID int64) error {
+	if _, err := s.getOwnedSmartLink(ctx, orgID, smartLinkID); err != nil {
+		return err
+	}
+	return s.smartLinkRepo.DeleteSmartLink(ctx, smartLinkID)
+}
+
+// SimulateSmartLink evaluates a described click, and draws req.Clicks clicks when asked to
+func (s *smartLinkService) SimulateSmartLink(ctx context.Context, orgID, smartLinkID int64, req *domain.SimulateSmartLinkRequest) (*domain.SmartLinkSimulation, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	smartLink, err := s.getOwnedSmartLink(ctx, orgID, smartLinkID)
+	if err != nil {
+		return nil, err
+	}
+
+	click := domain.SmartLinkClick{Country: req.Country, Device: req.Device, Time: time.Now().UTC()}
+	if click.Device == "" {
+		click.Device = domain.ClassifyDevice(req.UserAgent)
+	}
+	if req.Time != nil {
+		click.Time = *req.Time
+	}
+
+	evaluation, err := s.evaluate(ctx, smartLink, click)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &domain.SmartLinkSimulation{Click: click, Destinations: evaluation.Destinations}
+	if selected := evaluation.Pick(rand.Float64()); selected != nil {
+		simulation.Selected = &selected.TrackingLinkID
+		simulation.RedirectURL = &selected.TrackingURL
+	} else {
+		simulation.UsesFallback = true
+		simulation.RedirectURL = smartLink.FallbackURL
+	}
+
+	if req.Clicks > 0 {
+		simulation.Distribution = make(map[int64]int)
+		for i := 0; i < req.Clicks; i++ {
+			if selected := evaluation.Pick(rand.Float64()); selected != nil {
+				simulation.Distribution[selected.TrackingLinkID]++
+			} else {
+				simulation.Distribution[domain.SmartLinkFallbackDestinationID]++
+			}
+		}
+	}
+	return simulation, nil
+}
+
+// GetSmartLinkStats sums the daily clicks per destination
+func (s *smartLinkService) GetSmartLinkStats(ctx context.Context, orgID, smartLinkID int64, from, to time.Time) (*domain.SmartLinkStats, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", domain.ErrInvalidInput)
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return nil, fmt.Errorf("%w: the date range must not exceed 366 days", domain.ErrInvalidInput)
+	}
+	if _, err := s.getOwnedSmartLink(ctx, orgID, smartLinkID); err != nil {
+		return nil, err
+	}
+
+	daily, err := s.smartLinkRepo.GetDailyStats(ctx, smartLinkID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.SmartLinkStats{
+		SmartLinkID:  smartLinkID,
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		Destinations: make([]domain.SmartLinkDestinationStats, 0),
+		Daily:        make([]domain.SmartLinkDailyStats, 0, len(daily)),
+	}
+	totals := make(map[int64]*domain.SmartLinkDestinationStats)
+	for _, row := range daily {
+		stats.Daily = append(stats.Daily, *row)
+		stats.TotalClicks += row.Clicks
+		if row.TrackingLinkID == domain.SmartLinkFallbackDestinationID {
+			stats.FallbackClicks += row.Clicks
+		}
+		total, ok := totals[row.TrackingLinkID]
+		if !ok {
+			total = &domain.SmartLinkDestinationStats{TrackingLinkID: row.TrackingLinkID, CampaignID: row.CampaignID}
+			totals[row.TrackingLinkID] = total
+		}
+		total.Clicks += row.Clicks
+	}
+	for _, total := range totals {
+		if stats.TotalClicks > 0 {
+			total.Share = float64(total.Clicks) / float64(stats.TotalClicks)
+		}
+		stats.Destinations = append(stats.Destinations, *total)
+	}
+	sort.Slice(stats.Destinations, func(i, j int) bool {
+		if stats.Destinations[i].Clicks != stats.Destinations[j].Clicks {
+			return stats.Destinations[i].Clicks > stats.Destinations[j].Clicks
+		}
+		return stats.Destinations[i].TrackingLinkID < stats.Destinations[j].TrackingLinkID
+	})
+	return stats, nil
+}
+
+// RouteClick picks a destination for a click and counts it; paused smart links and clicks no
+// destination accepts go to the fallback URL, and ErrNotFound is returned when there is none
+func (s *smartLinkService) RouteClick(ctx context.Context, smartLinkID int64, click domain.SmartLinkClick) (string, error) {
+	smartLink, err := s.smartLinkRepo.GetSmartLinkByID(ctx, smartLinkID)
+	if err != nil {
+		return "", err
+	}
+
+	var selected *domain.SmartLinkDestinationEvaluation
+	if smartLink.Status == domain.SmartLinkStatusActive {
+		evaluation, err := s.evaluate(ctx, smartLink, click)
+		if err != nil {
+			return "", err
+		}
+		selected = evaluation.Pick(rand.Float64())
+	}
+
+	day := utcDay(click.Time)
+	if selected == nil {
+		if smartLink.FallbackURL == nil {
+			return "", fmt.Errorf("no destination accepts the click and the smart link has no fallback URL: %w", domain.ErrNotFound)
+		}
+		s.recordClick(ctx, smartLinkID, day, domain.SmartLinkFallbackDestinationID, nil)
+		return *smartLink.FallbackURL, nil
+	}
+
+	campaignID := selected.CampaignID
+	s.recordClick(ctx, smartLinkID, day, selected.TrackingLinkID, &campaignID)
+	return selected.TrackingURL, nil
+}
+
+// recordClick counts a click; failures are logged so that the click is still redirected
+func (s *smartLinkService) recordClick(ctx context.Context, smartLinkID int64, day time.Time, trackingLinkID int64, campaignID *int64) {
+	if err := s.smartLinkRepo.RecordClick(ctx, smartLinkID, day, trackingLinkID, campaignID); err != nil {
+		logger.Error("Failed to record smart link click", "smart_link_id", smartLinkID, "tracking_link_id", trackingLinkID, "error", err)
+	}
+}
+
+// evaluate matches a click against the destinations with their current state
+func (s *smartLinkService) evaluate(ctx context.Context, smartLink *domain.SmartLink, click domain.SmartLinkClick) (*domain.SmartLinkEvaluation, error) {
+	states, err := s.smartLinkRepo.GetDestinationStates(ctx, smartLink.SmartLinkID, utcDay(click.Time))
+	if err != nil {
+		return nil, err
+	}
+	return domain.EvaluateSmartLink(smartLink, states, click), nil
+}
+
+// buildDestinations checks that every tracking link belongs to the affiliate and records its campaign
+func (s *smartLinkService) buildDestinations(ctx context.Context, affiliateID int64, inputs []domain.SmartLinkDestinationInput) ([]*domain.SmartLinkDestination, error) {
+	destinations := make([]*domain.SmartLinkDestination, 0, len(inputs))
+	for _, input := range inputs {
+		trackingLink, err := s.trackingLinkRepo.GetTrackingLinkByID(ctx, input.TrackingLinkID)
+		if err != nil || trackingLink.AffiliateID != affiliateID {
+			if err == nil || errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: tracking link %d does not belong to the smart link's affiliate", domain.ErrInvalidInput, input.TrackingLinkID)
+			}
+			return nil, err
+		}
+
+		isActive := true
+		if input.IsActive != nil {
+			isActive = *input.IsActive
+		}
+		destinations = append(destinations, &domain.SmartLinkDestination{
+			TrackingLinkID: input.TrackingLinkID,
+			CampaignID:     trackingLink.CampaignID,
+			Weight:         input.Weight,
+			Rules:          input.Rules,
+			DailyClickCap:  input.DailyClickCap,
+			IsActive:       isActive,
+		})
+	}
+	return destinations, nil
+}
+
+// getOwnedSmartLink returns a smart link, or ErrNotFound when it belongs to another organization
+func (s *smartLinkService) getOwnedSmartLink(ctx context.Context, orgID, smartLinkID int64) (*domain.SmartLink, error) {
+	smartLink, err := s.smartLinkRepo.GetSmartLinkByID(ctx, smartLinkID)
+	if err != nil {
+		return nil, err
+	}
+	if smartLink.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	return smartLink, nil
+}
+
+// utcDay truncates a time to its UTC date
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,499 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// trackingDomainRecheckBatchSize bounds the domains checked per cron run
+const trackingDomainRecheckBatchSize = 100
+
+// TXTResolver looks up DNS TXT records; net.DefaultResolver satisfies it
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// TrackingDomainService defines the interface for custom tracking domain operations
+type TrackingDomainService interface {
+	CreateDomain(ctx context.Context, orgID int64, req *domain.CreateTrackingDomainRequest) (*domain.TrackingDomain, error)
+	GetDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error)
+	ListDomains(ctx context.Context, orgID int64) ([]*domain.TrackingDomain, error)
+	DeleteDomain(ctx context.Context, orgID, trackingDomainID int64) error
+	// VerifyDomain looks up the verification TXT record and updates the domain status
+	VerifyDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error)
+	SetDefaultDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error)
+	ClearDefaultDomain(ctx context.Context, orgID int64) error
+
+	// GetCampaignDomain returns the domain override of a campaign
+	GetCampaignDomain(ctx context.Context, orgID, campaignID int64) (*domain.TrackingDomain, error)
+	// SetCampaignDomain sets the domain override of a campaign, or removes it when no domain is given
+	SetCampaignDomain(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignTrackingDomainRequest) (*domain.TrackingDomain, error)
+
+	// TrackingLinkURL returns the native URL of a tracking link on the campaign's domain, the
+	// organization's default domain or the platform tracking URL, in that order; "" when none is set
+	TrackingLinkURL(ctx context.Context, trackingLink *domain.TrackingLink) (string, error)
+	// ResolveRedirect returns where a click on a native tracking link received on host goes;
+	// query holds the parameters of the click request
+	ResolveRedirect(ctx context.Context, host string, trackingLinkID int64, visitor domain.TargetingVisitor, query url.Values) (string, error)
+
+	// RecheckDomains checks again the domains whose last check is older than the recheck interval
+	RecheckDomains(ctx context.Context, now time.Time) (int, error)
+}
+
+// trackingDomainService implements TrackingDomainService
+type trackingDomainService struct {
+	trackingDomainRepo       repository.TrackingDomainRepository
+	campaignRepo             repository.CampaignRepository
+	trackingLinkRepo         repository.TrackingLinkRepository
+	trackingLinkProviderRepo repository.TrackingLinkProviderMappingRepository
+	resolver                 TXTResolver
+	campaignTargetingService CampaignTargetingService
+	campaignURLService       CampaignURLService
+	linkSigningService       TrackingLinkSigningService
+	platformBaseURL          string
+	platformHosts            map[string]bool
+}
+
+// NewTrackingDomainService creates a new tracking domain service. Native links fall back to
+// platformBaseURL when an organization has no verified domain; the redirect is served on the
+// hosts of platformBaseURL and apiBaseURL besides the verified tracking domains. Clicks are
+// checked against the campaign targeting ruleset when campaignTargetingService is set, and
+// destination URL macros are expanded when campaignURLService is set. With linkSigningService,
+// native links of organizations that sign links carry signed parameters that clicks are
+// verified against.
+func NewTrackingDomainService(
+	trackingDomainRepo repository.TrackingDomainRepository,
+	campaignRepo repository.CampaignRepository,
+	trackingLinkRepo repository.TrackingLinkRepository,
+	trackingLinkProviderRepo repository.TrackingLinkProviderMappingRepository,
+	resolver TXTResolver,
+	platformBaseURL string,
+	apiBaseURL string,
+	campaignTargetingService CampaignTargetingService,
+	campaignURLService CampaignURLService,
+	linkSigningService TrackingLinkSigningService,
+) TrackingDomainService {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	platformHosts := make(map[string]bool)
+	for _, baseURL := range []string{platformBaseURL, apiBaseURL} {
+		if parsed, err := url.Parse(baseURL); err == nil && parsed.Host != "" {
+			platformHosts[domain.RequestHostname(parsed.Host)] = true
+		}
+	}
+	return &trackingDomainService{
+		trackingDomainRepo:       trackingDomainRepo,
+		campaignRepo:             campaignRepo,
+		trackingLinkRepo:         trackingLinkRepo,
+		trackingLinkProviderRepo: trackingLinkProviderRepo,
+		resolver:                 resolver,
+		campaignTargetingService: campaignTargetingService,
+		campaignURLService:       campaignURLService,
+		linkSigningService:       linkSigningService,
+		platformBaseURL:          strings.TrimSuffix(platformBaseURL, "/"),
+		platformHosts:            platformHosts,
+	}
+}
+
+// CreateDomain registers a hostname for the organization; it must be verified before use
+func (s *trackingDomainService) CreateDomain(ctx context.Context, orgID int64, req *domain.CreateTrackingDomainRequest) (*domain.TrackingDomain, error) {
+	hostname, err := domain.NormalizeHostname(req.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if s.platformHosts[hostname] {
+		return nil, fmt.Errorf("%w: %s is the platform tracking host", domain.ErrInvalidInput, hostname)
+	}
+
+	// Unverified claims don't block other organizations; whoever proves DNS control first owns the hostname
+	if _, err := s.trackingDomainRepo.GetOrganizationDomainByHostname(ctx, orgID, hostname); err == nil {
+		return nil, fmt.Errorf("%w: %s is already registered", domain.ErrInvalidInput, hostname)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if _, err := s.trackingDomainRepo.GetDomainByHostname(ctx, hostname); err == nil {
+		return nil, fmt.Errorf("%w: %s is already verified by another organization", domain.ErrInvalidInput, hostname)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	count, err := s.trackingDomainRepo.CountDomainsByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxTrackingDomainsPerOrganization {
+		return nil, fmt.Errorf("%w: an organization can register at most %d tracking domains",
+			domain.ErrInvalidInput, domain.MaxTrackingDomainsPerOrganization)
+	}
+
+	token, err := generateTrackingDomainToken()
+	if err != nil {
+		return nil, err
+	}
+	trackingDomain := &domain.TrackingDomain{
+		OrganizationID:    orgID,
+		Hostname:          hostname,
+		Status:            domain.TrackingDomainStatusPending,
+		VerificationToken: token,
+	}
+	if err := s.trackingDomainRepo.CreateDomain(ctx, trackingDomain); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Tracking domain registered", "organization_id", orgID, "hostname", hostname)
+	return withVerificationRecord(trackingDomain), nil
+}
+
+// GetDomain returns a tracking domain of the organization
+func (s *trackingDomainService) GetDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error) {
+	trackingDomain, err := s.getOwnedDomain(ctx, orgID, trackingDomainID)
+	if err != nil {
+		return nil, err
+	}
+	return withVerificationRecord(trackingDomain), nil
+}
+
+// ListDomains lists the tracking domains of the organization
+func (s *trackingDomainService) ListDomains(ctx context.Context, orgID int64) ([]*domain.TrackingDomain, error) {
+	domains, err := s.trackingDomainRepo.ListDomainsByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, trackingDomain := range domains {
+		withVerificationRecord(trackingDomain)
+	}
+	return domains, nil
+}
+
+// DeleteDomain removes a tracking domain; links already generated on it stop redirecting
+func (s *trackingDomainService) DeleteDomain(ctx context.Context, orgID, trackingDomainID int64) error {
+	if _, err := s.getOwnedDomain(ctx, orgID, trackingDomainID); err != nil {
+		return err
+	}
+	return s.trackingDomainRepo.DeleteDomain(ctx, trackingDomainID)
+}
+
+// VerifyDomain checks the verification TXT record of a domain
+func (s *trackingDomainService) VerifyDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error) {
+	trackingDomain, err := s.getOwnedDomain(ctx, orgID, trackingDomainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDomain(ctx, trackingDomain, time.Now()); err != nil {
+		return nil, err
+	}
+	return withVerificationRecord(trackingDomain), nil
+}
+
+// SetDefaultDomain makes a verified domain the default of the organization's links
+func (s *trackingDomainService) SetDefaultDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error) {
+	trackingDomain, err := s.getOwnedDomain(ctx, orgID, trackingDomainID)
+	if err != nil {
+		return nil, err
+	}
+	if !trackingDomain.IsVerified() {
+		return nil, fmt.Errorf("%w: %s must be verified before it can be the default domain", domain.ErrInvalidInput, trackingDomain.Hostname)
+	}
+	if err := s.trackingDomainRepo.SetDefaultDomain(ctx, orgID, trackingDomainID); err != nil {
+		return nil, err
+	}
+	trackingDomain.IsDefault = true
+	return withVerificationRecord(trackingDomain), nil
+}
+
+// ClearDefaultDomain makes the organization's links use the platform tracking URL again
+func (s *trackingDomainService) ClearDefaultDomain(ctx context.Context, orgID int64) error {
+	return s.trackingDomainRepo.ClearDefaultDomain(ctx, orgID)
+}
+
+// GetCampaignDomain returns the domain override of a campaign of the organization
+func (s *trackingDomainService) GetCampaignDomain(ctx context.Context, orgID, campaignID int64) (*domain.TrackingDomain, error) {
+	if err := s.checkCampaignOwnership(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+	trackingDomain, err := s.trackingDomainRepo.GetCampaignDomain(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return withVerificationRecord(trackingDomain), nil
+}
+
+// SetCampaignDomain sets or removes the domain override of a campaign of the organization
+func (s *trackingDomainService) SetCampaignDomain(ctx context.Context, orgID, campaignID int64, req *domain.SetCampaignTrackingDomainRequest) (*domain.TrackingDomain, error) {
+	if err := s.checkCampaignOwnership(ctx, orgID, campaignID); err != nil {
+		return nil, err
+	}
+	if req.TrackingDomainID == nil {
+		return nil, s.trackingDomainRepo.ClearCampaignDomain(ctx, campaignID)
+	}
+
+	trackingDomain, err := s.trackingDomainRepo.GetDomainByID(ctx, *req.TrackingDomainID)
+	if err != nil || trackingDomain.OrganizationID != orgID {
+		if err == nil || errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: tracking domain %d does not belong to the organization", domain.ErrInvalidInput, *req.TrackingDomainID)
+		}
+		return nil, err
+	}
+	if !trackingDomain.IsVerified() {
+		return nil, fmt.Errorf("%w: %s must be verified before campaigns can use it", domain.ErrInvalidInput, trackingDomain.Hostname)
+	}
+	if err := s.trackingDomainRepo.SetCampaignDomain(ctx, campaignID, trackingDomain.TrackingDomainID); err != nil {
+		return nil, err
+	}
+	return withVerificationRecord(trackingDomain), nil
+}
+
+// TrackingLinkURL returns the native URL of a tracking link
+func (s *trackingDomainService) TrackingLinkURL(ctx context.Context, trackingLink *domain.TrackingLink) (string, error) {
+	baseURL := s.platformBaseURL
+
+	trackingDomain, err := s.trackingDomainRepo.GetCampaignDomain(ctx, trackingLink.CampaignID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return "", err
+	}
+	// An override whose domain lost its verification falls back to the default domain
+	if trackingDomain == nil || !trackingDomain.IsVerified() {
+		trackingDomain, err = s.trackingDomainRepo.GetDefaultDomain(ctx, trackingLink.OrganizationID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return "", err
+		}
+	}
+	if trackingDomain != nil && trackingDomain.IsVerified() {
+		baseURL = "https://" + trackingDomain.Hostname
+	}
+
+	if baseURL == "" {
+		return "", nil
+	}
+	nativeURL := baseURL + domain.TrackingLinkRedirectPath + strconv.FormatInt(trackingLink.TrackingLinkID, 10)
+	if s.linkSigningService == nil {
+		return nativeURL, nil
+	}
+	return s.linkSigningService.SignLinkURL(ctx, trackingLink, nativeURL)
+}
+
+// ResolveRedirect checks that the link and its campaign are active and that the link is served
+// on the platform host or a verified domain of its organization, and returns the provider
+// tracking URL with the click's sub ID overrides and passthrough parameters forwarded, or the
+// campaign destination with its macros expanded for the click when the link was never generated
+// through the provider. Links outside their active window or their campaign dates are not
+// served. Clicks on signed links are verified first. Visitors the campaign targeting ruleset
+// rejects go to its fallback URL, or are refused when it has none.
+func (s *trackingDomainService) ResolveRedirect(ctx context.Context, host string, trackingLinkID int64, visitor domain.TargetingVisitor, query url.Values) (string, error) {
+	trackingLink, err := s.trackingLinkRepo.GetTrackingLinkByID(ctx, trackingLinkID)
+	if err != nil {
+		return "", err
+	}
+	if trackingLink.Status != domain.TrackingLinkStatusActive {
+		return "", fmt.Errorf("tracking link is %s: %w", trackingLink.Status, domain.ErrNotFound)
+	}
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, trackingLink.CampaignID)
+	if err != nil {
+		return "", err
+	}
+	if err := trackingLink.CheckAcceptsClick(campaign, visitor.Time); err != nil {
+		return "", err
+	}
+
+	hostname := domain.RequestHostname(host)
+	if !s.platformHosts[hostname] {
+		trackingDomain, err := s.trackingDomainRepo.GetDomainByHostname(ctx, hostname)
+		if err != nil {
+			return "", err
+		}
+		if !trackingDomain.IsVerified() || trackingDomain.OrganizationID != trackingLink.OrganizationID {
+			return "", fmt.Errorf("tracking link is not served on %s: %w", hostname, domain.ErrNotFound)
+		}
+	}
+
+	if s.linkSigningService != nil {
+		if query, err = s.linkSigningService.VerifyClick(ctx, trackingLink, query, visitor); err != nil {
+			return "", err
+		}
+	}
+
+	if s.campaignTargetingService != nil {
+		decision, err := s.campaignTargetingService.EvaluateClick(ctx, trackingLink.CampaignID, visitor)
+		if err != nil {
+			return "", err
+		}
+		if decision != nil && !decision.Matched {
+			if decision.FallbackURL == nil {
+				return "", fmt.Errorf("click does not match the %s targeting rule: %w", decision.FailedRule, domain.ErrNotFound)
+			}
+			return *decision.FallbackURL, nil
+		}
+	}
+
+	click := domain.URLMacroClick{
+		TrackingLink: trackingLink,
+		Query:        query,
+		Country:      visitor.Location.Country,
+		Time:         visitor.Time,
+	}
+	if visitor.IP != nil {
+		click.IP = visitor.IP.String()
+	}
+
+	// The provider expands the macros of links it generated; the click's sub IDs and passthrough
+	// parameters are forwarded to it
+	mapping, err := s.trackingLinkProviderRepo.GetTrackingLinkProviderMapping(ctx, trackingLinkID, "everflow")
+	if err == nil && mapping.ProviderData != nil {
+		var providerData domain.EverflowTrackingLinkProviderData
+		if err := providerData.FromJSON(*mapping.ProviderData); err == nil && providerData.GeneratedURL != nil && *providerData.GeneratedURL != "" {
+			if s.campaignURLService == nil {
+				return *providerData.GeneratedURL, nil
+			}
+			return s.campaignURLService.BuildProviderURL(ctx, campaign, *providerData.GeneratedURL, click)
+		}
+	}
+
+	if campaign.DestinationURL == nil || *campaign.DestinationURL == "" {
+		return "", fmt.Errorf("campaign %d has no destination URL: %w", campaign.CampaignID, domain.ErrNotFound)
+	}
+	if s.campaignURLService == nil {
+		return *campaign.DestinationURL, nil
+	}
+	return s.campaignURLService.BuildDestinationURL(ctx, campaign, click)
+}
+
+// RecheckDomains checks the verified and failed domains again so that domains whose TXT record
+// was removed stop serving links, and repaired ones serve them again
+func (s *trackingDomainService) RecheckDomains(ctx context.Context, now time.Time) (int, error) {
+	domains, err := s.trackingDomainRepo.ListDomainsToRecheck(ctx, now.Add(-domain.TrackingDomainRecheckInterval), trackingDomainRecheckBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	checked := 0
+	for _, trackingDomain := range domains {
+		wasVerified := trackingDomain.IsVerified()
+		if err := s.checkDomain(ctx, trackingDomain, now); err != nil {
+			logger.Error("Failed to recheck tracking domain", "tracking_domain_id", trackingDomain.TrackingDomainID, "error", err)
+			continue
+		}
+		if wasVerified && !trackingDomain.IsVerified() {
+			logger.Warn("Tracking domain lost its verification", "tracking_domain_id", trackingDomain.TrackingDomainID,
+				"hostname", trackingDomain.Hostname, "organization_id", trackingDomain.OrganizationID)
+		}
+		checked++
+	}
+	return checked, nil
+}
+
+// checkDomain looks up the verification record and stores the result. Only a definitive answer
+// changes the status; lookup errors such as timeouts are recorded without failing the domain.
+func (s *trackingDomainService) checkDomain(ctx context.Context, trackingDomain *domain.TrackingDomain, now time.Time) error {
+	found, lookupErr := s.lookupVerificationRecord(ctx, trackingDomain)
+
+	ownedElsewhere := false
+	if found {
+		verified, err := s.trackingDomainRepo.GetDomainByHostname(ctx, trackingDomain.Hostname)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		ownedElsewhere = verified != nil && verified.TrackingDomainID != trackingDomain.TrackingDomainID
+	}
+
+	trackingDomain.LastCheckedAt = &now
+	switch {
+	case lookupErr != nil:
+		message := fmt.Sprintf("DNS lookup of %s failed: %v", trackingDomain.VerificationRecordName(), lookupErr)
+		trackingDomain.LastError = &message
+	case found && ownedElsewhere:
+		message := fmt.Sprintf("%s is already verified by another organization", trackingDomain.Hostname)
+		trackingDomain.Status = domain.TrackingDomainStatusFailed
+		trackingDomain.LastError = &message
+	case found:
+		if !trackingDomain.IsVerified() {
+			trackingDomain.VerifiedAt = &now
+		}
+		trackingDomain.Status = domain.TrackingDomainStatusVerified
+		trackingDomain.LastError = nil
+	default:
+		message := fmt.Sprintf("TXT record %s does not contain %q", trackingDomain.VerificationRecordName(), trackingDomain.VerificationRecordValue())
+		trackingDomain.Status = domain.TrackingDomainStatusFailed
+		trackingDomain.LastError = &message
+	}
+
+	return s.trackingDomainRepo.UpdateVerification(ctx, trackingDomain)
+}
+
+// lookupVerificationRecord reports whether the TXT record holds the domain's token
+func (s *trackingDomainService) lookupVerificationRecord(ctx context.Context, trackingDomain *domain.TrackingDomain) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	records, err := s.resolver.LookupTXT(ctx, trackingDomain.VerificationRecordName())
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	want := trackingDomain.VerificationRecordValue()
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getOwnedDomain returns a domain, or ErrNotFound when it belongs to another organization
+func (s *trackingDomainService) getOwnedDomain(ctx context.Context, orgID, trackingDomainID int64) (*domain.TrackingDomain, error) {
+	trackingDomain, err := s.trackingDomainRepo.GetDomainByID(ctx, trackingDomainID)
+	if err != nil {
+		return nil, err
+	}
+	if trackingDomain.OrganizationID != orgID {
+		return nil, domain.ErrNotFound
+	}
+	return trackingDomain, nil
+}
+
+// checkCampaignOwnership returns ErrNotFound when the campaign belongs to another organization
+func (s *trackingDomainService) checkCampaignOwnership(ctx context.Context, orgID, campaignID int64) error {
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.OrganizationID != orgID {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// withVerificationRecord fills in the DNS record the organization has to create
+func withVerificationRecord(trackingDomain *domain.TrackingDomain) *domain.TrackingDomain {
+	trackingDomain.Verification = &domain.TrackingDomainVerificationRecord{
+		Type:  "TXT",
+		Name:  trackingDomain.VerificationRecordName(),
+		Value: trackingDomain.VerificationRecordValue(),
+	}
+	return trackingDomain
+}
+
+// generateTrackingDomainToken generates a random verification token
+func generateTrackingDomainToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("error generating random bytes: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Register the GIF decoder for logos
+	_ "image/jpeg" // Register the JPEG decoder for logos
+	_ "image/png"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/platform/qrcode"
+	"github.com/affiliate-backend/internal/platform/storage"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+const (
+	// qrCacheMaxEntries and qrCacheMaxBytes bound the rendered QR codes kept in memory
+	qrCacheMaxEntries = 512
+	qrCacheMaxBytes   = 64 << 20
+)
+
+// TrackingLinkQRService renders the QR codes of tracking links and manages the organization
+// logos drawn on them
+type TrackingLinkQRService interface {
+	// GetTrackingLinkQR renders the QR code of a tracking link of the organization. Images are
+	// cached per link and options until the tracking URL or the logo changes.
+	GetTrackingLinkQR(ctx context.Context, organizationID, trackingLinkID int64, options *domain.QRCodeOptions) (*domain.QRCodeImage, error)
+	// InvalidateTrackingLink drops the cached QR codes of a tracking link
+	InvalidateTrackingLink(trackingLinkID int64)
+
+	GetOrganizationLogo(ctx context.Context, organizationID int64) (*domain.OrganizationLogo, []byte, error)
+	// UploadOrganizationLogo stores a PNG, JPEG or GIF image as the logo of an organization
+	UploadOrganizationLogo(ctx context.Context, organizationID int64, data []byte) (*domain.OrganizationLogo, error)
+	DeleteOrganizationLogo(ctx context.Context, organizationID int64) error
+}
+
+// trackingLinkQRService implements TrackingLinkQRService
+type trackingLinkQRService struct {
+	trackingLinkRepo repository.TrackingLinkRepository
+	logoRepo         repository.OrganizationLogoRepository
+	logoStore        storage.ObjectStore
+	cache            *qrCache
+}
+
+// NewTrackingLinkQRService creates a new tracking link QR code service. Logos are kept in
+// logoStore; they cannot be uploaded when it is nil.
+func NewTrackingLinkQRService(
+	trackingLinkRepo repository.TrackingLinkRepository,
+	logoRepo repository.OrganizationLogoRepository,
+	logoStore storage.ObjectStore,
+) TrackingLinkQRService {
+	return &trackingLinkQRService{
+		trackingLinkRepo: trackingLinkRepo,
+		logoRepo:         logoRepo,
+		logoStore:        logoStore,
+		cache:            newQRCache(qrCacheMaxEntries, qrCacheMaxBytes),
+	}
+}
+
+// GetTrackingLinkQR returns the cached QR code of a tracking link or renders it
+func (s *trackingLinkQRService) GetTrackingLinkQR(ctx context.Context, organizationID, trackingLinkID int64, options *domain.QRCodeOptions) (*domain.QRCodeImage, error) {
+	if err := options.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	trackingLink, err := s.trackingLinkRepo.GetTrackingLinkByID(ctx, trackingLinkID)
+	if err != nil {
+		return nil, err
+	}
+	if trackingLink.OrganizationID != organizationID {
+		return nil, domain.ErrNotFound
+	}
+	if trackingLink.TrackingURL == nil || *trackingLink.TrackingURL == "" {
+		return nil, fmt.Errorf("%w: the tracking link has no tracking URL yet", domain.ErrInvalidInput)
+	}
+	trackingURL := *trackingLink.TrackingURL
+
+	var logo *domain.OrganizationLogo
+	var logoVersion time.Time
+	if options.Logo {
+		logo, err = s.logoRepo.GetLogo(ctx, organizationID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: the organization has no logo", domain.ErrInvalidInput)
+			}
+			return nil, err
+		}
+		logoVersion = logo.UpdatedAt
+	}
+
+	key := fmt.Sprintf("%d|%s", trackingLinkID, options.CacheKey())
+	if cached := s.cache.get(key, trackingURL, logoVersion); cached != nil {
+		return cached, nil
+	}
+
+	rendered, err := s.render(ctx, trackingURL, options, logo)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(&qrCacheEntry{
+		key:            key,
+		trackingLinkID: trackingLinkID,
+		organizationID: organizationID,
+		trackingURL:    trackingURL,
+		logoVersion:    logoVersion,
+		image:          rendered,
+	})
+	return rendered, nil
+}
+
+// render encodes the tracking URL and draws it with the options
+func (s *trackingLinkQRService) render(ctx context.Context, trackingURL string, options *domain.QRCodeOptions, logo *domain.OrganizationLogo) (*domain.QRCodeImage, error) {
+	level, err := qrcode.ParseErrorCorrectionLevel(options.ErrorCorrection)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	code, err := qrcode.Encode(trackingURL, level)
+	if err != nil {
+		if errors.Is(err, qrcode.ErrTooLong) {
+			return nil, fmt.Errorf("%w: the tracking URL is too long for a QR code at level %s", domain.ErrInvalidInput, options.ErrorCorrection)
+		}
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	renderOptions := qrcode.RenderOptions{Size: options.Size, Margin: *options.Margin}
+	if renderOptions.Foreground, err = qrcode.ParseHexColor(options.Foreground); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	if options.Background != domain.QRCodeTransparent {
+		if renderOptions.Background, err = qrcode.ParseHexColor(options.Background); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+	} else {
+		renderOptions.Background = color.Transparent
+	}
+	if logo != nil {
+		if renderOptions.Logo, err = s.loadLogoImage(ctx, logo); err != nil {
+			return nil, err
+		}
+	}
+
+	var data []byte
+	if options.Format == domain.QRCodeFormatSVG {
+		data, err = code.RenderSVG(renderOptions)
+	} else {
+		data, err = code.RenderPNG(renderOptions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return &domain.QRCodeImage{
+		Data:        data,
+		ContentType: options.ContentType(),
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
+
+func (s *trackingLinkQRService) loadLogoImage(ctx context.Context, logo *domain.OrganizationLogo) (image.Image, error) {
+	if s.logoStore == nil {
+		return nil, fmt.Errorf("logo storage is not configured")
+	}
+	data, err := s.logoStore.Get(ctx, logo.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization logo: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode organization logo: %w", err)
+	}
+	return img, nil
+}
+
+// InvalidateTrackingLink drops the cached QR codes of a tracking link
+func (s *trackingLinkQRService) InvalidateTrackingLink(trackingLinkID int64) {
+	s.cache.removeWhere(func(e *qrCacheEntry) bool { return e.trackingLinkID == trackingLinkID })
+}
+
+// GetOrganizationLogo returns the logo of an organization with its image
+func (s *trackingLinkQRService) GetOrganizationLogo(ctx context.Context, organizationID int64) (*domain.OrganizationLogo, []byte, error) {
+	logo, err := s.logoRepo.GetLogo(ctx, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.logoStore == nil {
+		return nil, nil, fmt.Errorf("logo storage is not configured")
+	}
+	data, err := s.logoStore.Get(ctx, logo.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, domain.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to load organization logo: %w", err)
+	}
+	return logo, data, nil
+}
+
+// UploadOrganizationLogo validates and stores a logo, replacing the previous one
+func (s *trackingLinkQRService) UploadOrganizationLogo(ctx context.Context, organizationID int64, data []byte) (*domain.OrganizationLogo, error) {
+	if s.logoStore == nil {
+		return nil, fmt.Errorf("logo storage is not configured")
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: the logo is empty", domain.ErrInvalidInput)
+	}
+	if len(data) > domain.MaxOrganizationLogoSize {
+		return nil, fmt.Errorf("%w: the logo must be at most %d bytes", domain.ErrInvalidInput, domain.MaxOrganizationLogoSize)
+	}
+
+	// Check the dimensions before decoding so that small files cannot expand into huge images
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: the logo must be a PNG, JPEG or GIF image", domain.ErrInvalidInput)
+	}
+	if config.Width > domain.MaxOrganizationLogoDimension || config.Height > domain.MaxOrganizationLogoDimension {
+		return nil, fmt.Errorf("%w: the logo must be at most %dx%d pixels", domain.ErrInvalidInput,
+			domain.MaxOrganizationLogoDimension, domain.MaxOrganizationLogoDimension)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%w: the logo could not be decoded: %v", domain.ErrInvalidInput, err)
+	}
+
+	logo := &domain.OrganizationLogo{
+		OrganizationID: organizationID,
+		StorageKey:     fmt.Sprintf("organization-logos/%d/%s.%s", organizationID, randomHex(8), format),
+		ContentType:    "image/" + strings.ToLower(format),
+		SizeBytes:      len(data),
+		Width:          config.Width,
+		Height:         config.Height,
+	}
+	if err := s.logoStore.Put(ctx, logo.StorageKey, logo.ContentType, data); err != nil {
+		return nil, fmt.Errorf("failed to store organization logo: %w", err)
+	}
+	if err := s.logoRepo.SaveLogo(ctx, logo); err != nil {
+		return nil, err
+	}
+
+	s.invalidateOrganizationLogo(organizationID)
+	logger.Info("Organization logo uploaded", "organization_id", organizationID, "size_bytes", logo.SizeBytes)
+	return logo, nil
+}
+
+// DeleteOrganizationLogo removes the logo of an organization. The stored image is left in
+// place, as object storage has no delete; its key is not reused.
+func (s *trackingLinkQRService) DeleteOrganizationLogo(ctx context.Context, organizationID int64) error {
+	if err := s.logoRepo.DeleteLogo(ctx, organizationID); err != nil {
+		return err
+	}
+	s.invalidateOrganizationLogo(organizationID)
+	return nil
+}
+
+// invalidateOrganizationLogo drops the cached QR codes that show the organization logo
+func (s *trackingLinkQRService) invalidateOrganizationLogo(organizationID int64) {
+	s.cache.removeWhere(func(e *qrCacheEntry) bool {
+		return e.organizationID == organizationID && !e.logoVersion.IsZero()
+	})
+}
+
+// qrCacheEntry is a rendered QR code with the tracking URL and logo it was rendered from
+type qrCacheEntry struct {
+	key            string
+	trackingLinkID int64
+	organizationID int64
+	trackingURL    string
+	logoVersion    time.Time
+	image          *domain.QRCodeImage
+}
+
+// qrCache is a least recently used cache of rendered QR codes. Entries are checked against the
+// current tracking URL and logo on every read, so a change made through another replica is never
+// served stale; explicit invalidation only frees the memory early.
+type qrCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	order      *list.List // Front is the most recently used
+	entries    map[string]*list.Element
+}
+
+func newQRCache(maxEntries, maxBytes int) *qrCache {
+	return &qrCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the image cached under key when it was rendered from the same URL and logo
+func (c *qrCache) get(key, trackingURL string, logoVersion time.Time) *domain.QRCodeImage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*qrCacheEntry)
+	if entry.trackingURL != trackingURL || !entry.logoVersion.Equal(logoVersion) {
+		c.remove(element)
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return entry.image
+}
+
+func (c *qrCache) put(entry *qrCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[entry.key]; ok {
+		c.remove(element)
+	}
+	if len(entry.image.Data) > c.maxBytes {
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.bytes += len(entry.image.Data)
+
+	for len(c.entries) > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *qrCache) removeWhere(match func(*qrCacheEntry) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if match(element.Value.(*qrCacheEntry)) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// remove must be called with mu held
+func (c *qrCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*qrCacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= len(entry.image.Data)
+}
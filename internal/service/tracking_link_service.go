@@ -34,6 +34,10 @@ type TrackingLinkService interface {
 	// Tracking link upsert
 	UpsertTrackingLink(ctx context.Context, req *domain.TrackingLinkUpsertRequest) (*domain.TrackingLinkUpsertResponse, error)
 
+	// RunLifecycleTransitions activates scheduled links whose window opened and archives links
+	// whose window or campaign ended. It returns the number of links changed.
+	RunLifecycleTransitions(ctx context.Context, now time.Time) (int, error)
+
 	// Provider sync operations
 	SyncTrackingLinkToProvider(ctx context.Context, trackingLinkID int64) error
 	SyncTrackingLinkFromProvider(ctx context.Context, trackingLinkID int64) error
@@ -55,6 +59,8 @@ type trackingLinkService struct {
 	affiliateProviderRepo    repository.AffiliateProviderMappingRepository
 	integrationService       provider.IntegrationService
 	orgAssociationService    OrganizationAssociationService
+	qrService                TrackingLinkQRService
+	trackingDomainService    TrackingDomainService
 }
 
 // NewTrackingLinkService creates a new tracking link service
@@ -67,6 +73,8 @@ func NewTrackingLinkService(
 	affiliateProviderRepo repository.AffiliateProviderMappingRepository,
 	integrationService provider.IntegrationService,
 	orgAssociationService OrganizationAssociationService,
+	qrService TrackingLinkQRService,
+	trackingDomainService TrackingDomainService,
 ) TrackingLinkService {
 	return &trackingLinkService{
 		trackingLinkRepo:         trackingLinkRepo,
@@ -77,6 +85,8 @@ func NewTrackingLinkService(
 		affiliateProviderRepo:    affiliateProviderRepo,
 		integrationService:       integrationService,
 		orgAssociationService:    orgAssociationService,
+		qrService:                qrService,
+		trackingDomainService:    trackingDomainService,
 	}
 }
 
@@ -97,6 +107,13 @@ func (s *trackingLinkService) CreateTrackingLink(ctx context.Context, trackingLi
 	trackingLink.CreatedAt = now
 	trackingLink.UpdatedAt = now
 
+	// Links created outside their window start scheduled or archived
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, trackingLink.CampaignID)
+	if err != nil {
+		return fmt.Errorf("failed to get campaign: %w", err)
+	}
+	trackingLink.ApplyLifecycle(campaign, now)
+
 	// Create tracking link in repository
 	if err := s.trackingLinkRepo.CreateTrackingLink(ctx, trackingLink); err != nil {
 		return fmt.Errorf("failed to create tracking link: %w", err)
@@ -137,6 +154,10 @@ func (s *trackingLinkService) UpdateTrackingLink(ctx context.Context, trackingLi
 		return fmt.Errorf("failed to get existing tracking link: %w", err)
 	}
 
+	if err := s.applyStatusChange(ctx, existingLink, trackingLink); err != nil {
+		return err
+	}
+
 	// Check if tracking parameters have changed
 	parametersChanged := s.hasTrackingParametersChanged(existingLink, trackingLink)
 	
@@ -144,6 +165,7 @@ func (s *trackingLinkService) UpdateTrackingLink(ctx context.Context, trackingLi
 	if err := s.trackingLinkRepo.UpdateTrackingLink(ctx, trackingLink); err != nil {
 		return fmt.Errorf("failed to update tracking link: %w", err)
 	}
+	s.invalidateQRCodes(trackingLink.TrackingLinkID, existingLink.TrackingURL, trackingLink.TrackingURL)
 
 	// If tracking parameters changed, regenerate the tracking link
 	if parametersChanged {
@@ -174,6 +196,9 @@ func (s *trackingLinkService) DeleteTrackingLink(ctx context.Context, id int64)
 	if err := s.trackingLinkRepo.DeleteTrackingLink(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete tracking link: %w", err)
 	}
+	if s.qrService != nil {
+		s.qrService.InvalidateTrackingLink(id)
+	}
 
 	return nil
 }
@@ -290,12 +315,15 @@ func (s *trackingLinkService) GenerateTrackingLink(ctx context.Context, req *dom
 		Sub5:                req.Sub5,
 		IsEncryptParameters: req.IsEncryptParameters,
 		IsRedirectLink:      req.IsRedirectLink,
+		ActiveFrom:          req.ActiveFrom,
+		ActiveUntil:         req.ActiveUntil,
 	}
 
 	// Set timestamps
 	now := time.Now()
 	trackingLink.CreatedAt = now
 	trackingLink.UpdatedAt = now
+	trackingLink.ApplyLifecycle(campaign, now)
 
 	// Create tracking link in database
 	if err := s.trackingLinkRepo.CreateTrackingLink(ctx, trackingLink); err != nil {
@@ -352,10 +380,12 @@ func (s *trackingLinkService) RegenerateTrackingLink(ctx context.Context, tracki
 	}
 
 	// Update tracking link with new generated URL
+	previousURL := trackingLink.TrackingURL
 	trackingLink.TrackingURL = &generatedURL
 	if err := s.trackingLinkRepo.UpdateTrackingLink(ctx, trackingLink); err != nil {
 		return nil, fmt.Errorf("failed to update tracking link with regenerated URL: %w", err)
 	}
+	s.invalidateQRCodes(trackingLinkID, previousURL, trackingLink.TrackingURL)
 
 	return &domain.TrackingLinkGenerationResponse{
 		TrackingLink: trackingLink,
@@ -419,12 +449,15 @@ func (s *trackingLinkService) UpsertTrackingLink(ctx context.Context, req *domai
 			IsRedirectLink:      req.IsRedirectLink,
 			InternalNotes:       req.InternalNotes,
 			Tags:                req.Tags,
+			ActiveFrom:          req.ActiveFrom,
+			ActiveUntil:         req.ActiveUntil,
 		}
 
 		// Set timestamps
 		now := time.Now()
 		trackingLink.CreatedAt = now
 		trackingLink.UpdatedAt = now
+		trackingLink.ApplyLifecycle(campaign, now)
 
 		// Create tracking link in database
 		if err := s.trackingLinkRepo.CreateTrackingLink(ctx, trackingLink); err != nil {
@@ -471,9 +504,19 @@ func (s *trackingLinkService) UpsertTrackingLink(ctx context.Context, req *domai
 		if req.Tags != nil {
 			trackingLink.Tags = req.Tags
 		}
+		if req.ActiveFrom != nil {
+			trackingLink.ActiveFrom = req.ActiveFrom
+		}
+		if req.ActiveUntil != nil {
+			trackingLink.ActiveUntil = req.ActiveUntil
+		}
+		if err := trackingLink.ValidateActiveWindow(); err != nil {
+			return nil, fmt.Errorf("tracking link upsert request validation failed: %w", err)
+		}
 		
 		// Update timestamp
 		trackingLink.UpdatedAt = time.Now()
+		trackingLink.ApplyLifecycle(campaign, trackingLink.UpdatedAt)
 		
 		// Update tracking link in database
 		if err := s.trackingLinkRepo.UpdateTrackingLink(ctx, trackingLink); err != nil {
@@ -520,10 +563,12 @@ func (s *trackingLinkService) UpsertTrackingLink(ctx context.Context, req *domai
 		}
 
 		// Update tracking link with generated URL
+		previousURL := trackingLink.TrackingURL
 		trackingLink.TrackingURL = &generatedURL
 		if err := s.trackingLinkRepo.UpdateTrackingLink(ctx, trackingLink); err != nil {
 			return nil, fmt.Errorf("failed to update tracking link with generated URL: %w", err)
 		}
+		s.invalidateQRCodes(trackingLink.TrackingLinkID, previousURL, trackingLink.TrackingURL)
 	} else {
 		// Use existing tracking URL and provider data
 		if trackingLink.TrackingURL != nil {
@@ -618,7 +663,7 @@ func (s *trackingLinkService) generateTrackingLinkViaProvider(ctx context.Contex
 		}
 	}
 
-	return response.GeneratedURL, response.ProviderData, nil
+	return s.nativeTrackingURL(ctx, trackingLink, response.GeneratedURL), response.ProviderData, nil
 }
 
 // SyncTrackingLinkToProvider syncs a tracking link to the provider
@@ -677,6 +722,53 @@ func (s *trackingLinkService) DeleteTrackingLinkProviderMapping(ctx context.Cont
 	return nil
 }
 
+// applyStatusChange clears the reason of automatic status changes when a user changes the
+// status, refuses to resume links of a suspended association, and moves active and scheduled
+// links to the status their window gives them
+func (s *trackingLinkService) applyStatusChange(ctx context.Context, existingLink, trackingLink *domain.TrackingLink) error {
+	if trackingLink.Status != existingLink.Status {
+		trackingLink.StatusReason = nil
+		resumed := trackingLink.Status == domain.TrackingLinkStatusActive || trackingLink.Status == domain.TrackingLinkStatusScheduled
+		if resumed && existingLink.StatusReason != nil && *existingLink.StatusReason == domain.TrackingLinkStatusReasonAssociationSuspended {
+			if err := s.verifyLinkAssociationActive(ctx, trackingLink); err != nil {
+				return err
+			}
+		}
+	}
+
+	campaign, err := s.campaignRepo.GetCampaignByID(ctx, trackingLink.CampaignID)
+	if err != nil {
+		return fmt.Errorf("failed to get campaign: %w", err)
+	}
+	trackingLink.ApplyLifecycle(campaign, time.Now())
+	return nil
+}
+
+// verifyLinkAssociationActive checks that the association the link depends on is active
+func (s *trackingLinkService) verifyLinkAssociationActive(ctx context.Context, trackingLink *domain.TrackingLink) error {
+	affiliate, err := s.affiliateRepo.GetAffiliateByID(ctx, trackingLink.AffiliateID)
+	if err != nil {
+		return fmt.Errorf("failed to get affiliate: %w", err)
+	}
+	association, err := s.orgAssociationService.GetAssociationByOrganizations(ctx, trackingLink.OrganizationID, affiliate.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to get association: %w", err)
+	}
+	if association.Status != domain.AssociationStatusActive {
+		return fmt.Errorf("%w: the link was paused because its association is %s", domain.ErrInvalidInput, association.Status)
+	}
+	return nil
+}
+
+// RunLifecycleTransitions applies the lifecycle of tracking links at now
+func (s *trackingLinkService) RunLifecycleTransitions(ctx context.Context, now time.Time) (int, error) {
+	changed, err := s.trackingLinkRepo.ApplyLifecycleTransitions(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(changed), nil
+}
+
 // validateTrackingLink validates tracking link business rules
 func (s *trackingLinkService) validateTrackingLink(trackingLink *domain.TrackingLink) error {
 	if trackingLink.Name == "" {
@@ -696,16 +788,11 @@ func (s *trackingLinkService) validateTrackingLink(trackingLink *domain.Tracking
 	}
 
 	// Validate status
-	validStatuses := map[string]bool{
-		"active":   true,
-		"paused":   true,
-		"archived": true,
-	}
-	if !validStatuses[trackingLink.Status] {
+	if !domain.IsValidTrackingLinkStatus(trackingLink.Status) {
 		return fmt.Errorf("invalid tracking link status: %s", trackingLink.Status)
 	}
 
-	return nil
+	return trackingLink.ValidateActiveWindow()
 }
 
 // validateTrackingLinkGenerationRequest validates tracking link generation request
@@ -722,6 +809,10 @@ func (s *trackingLinkService) validateTrackingLinkGenerationRequest(req *domain.
 		return fmt.Errorf("valid affiliate ID is required")
 	}
 
+	if req.ActiveFrom != nil && req.ActiveUntil != nil && !req.ActiveUntil.After(*req.ActiveFrom) {
+		return fmt.Errorf("active_until must be after active_from")
+	}
+
 	return nil
 }
 
@@ -826,6 +917,35 @@ func (s *trackingLinkService) synchronizeTrackingLinkWithProvider(ctx context.Co
 	return nil
 }
 
+// nativeTrackingURL returns the link's URL on its tracking domain, which redirects to the provider
+// URL kept in the provider mapping, or the provider URL when no tracking domain applies
+func (s *trackingLinkService) nativeTrackingURL(ctx context.Context, trackingLink *domain.TrackingLink, providerURL string) string {
+	if s.trackingDomainService == nil || providerURL == "" {
+		return providerURL
+	}
+	nativeURL, err := s.trackingDomainService.TrackingLinkURL(ctx, trackingLink)
+	if err != nil {
+		logger.Warn("Failed to resolve tracking domain, using provider URL",
+			"tracking_link_id", trackingLink.TrackingLinkID,
+			"error", err)
+		return providerURL
+	}
+	if nativeURL == "" {
+		return providerURL
+	}
+	return nativeURL
+}
+
+// invalidateQRCodes drops the cached QR codes of a tracking link when its URL changed
+func (s *trackingLinkService) invalidateQRCodes(trackingLinkID int64, before, after *string) {
+	if s.qrService == nil {
+		return
+	}
+	if before == nil || after == nil || *before != *after {
+		s.qrService.InvalidateTrackingLink(trackingLinkID)
+	}
+}
+
 // hasTrackingParametersChanged checks if any tracking parameters have changed
 func (s *trackingLinkService) hasTrackingParametersChanged(existing, updated *domain.TrackingLink) bool {
 	// Compare source_id
@@ -901,6 +1021,10 @@ func (s *trackingLinkService) validateTrackingLinkUpsertRequest(req *domain.Trac
 		return fmt.Errorf("valid affiliate ID is required")
 	}
 
+	if req.ActiveFrom != nil && req.ActiveUntil != nil && !req.ActiveUntil.After(*req.ActiveFrom) {
+		return fmt.Errorf("active_until must be after active_from")
+	}
+
 	return nil
 }
 
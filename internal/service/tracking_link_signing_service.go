@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/affiliate-backend/internal/domain"
+	"github.com/affiliate-backend/internal/platform/crypto"
+	"github.com/affiliate-backend/internal/platform/logger"
+	"github.com/affiliate-backend/internal/repository"
+)
+
+// Purposes the subkeys of a signing key are derived for
+const (
+	linkSigningKeyPurpose    = "tracking-link-signing"
+	linkEncryptionKeyPurpose = "tracking-link-encryption"
+)
+
+// linkResignBatchSize is the number of links loaded at once when stored URLs are signed again
+const linkResignBatchSize = 500
+
+// TrackingLinkSigningService defines the interface for signing and verifying native tracking link parameters
+type TrackingLinkSigningService interface {
+	GetSigning(ctx context.Context, orgID int64) (*domain.TrackingLinkSigning, error)
+	// SetSigning enables signing with the given enforcement, creating the first key when the
+	// organization has none, and signs the stored native URLs of its links
+	SetSigning(ctx context.Context, orgID int64, req *domain.SetTrackingLinkSigningRequest) (*domain.TrackingLinkSigning, error)
+	// DisableSigning stops signing new links and verifying clicks; keys are kept
+	DisableSigning(ctx context.Context, orgID int64) error
+	// RotateKey makes a new key active; links signed with the previous key keep verifying until
+	// it is revoked
+	RotateKey(ctx context.Context, orgID int64) (*domain.TrackingLinkSigningKey, error)
+	// RevokeKey signs the stored native URLs still using a retired key with the active key, then
+	// revokes it; copies of the old URLs stop verifying
+	RevokeKey(ctx context.Context, orgID, keyID int64) error
+	ListTamperEvents(ctx context.Context, orgID int64, page, pageSize int) (*domain.TrackingLinkTamperEventListResponse, error)
+
+	// SignLinkURL appends the signed parameters of a link, or their encryption for links with
+	// IsEncryptParameters, to its native URL when the organization signs links
+	SignLinkURL(ctx context.Context, trackingLink *domain.TrackingLink, nativeURL string) (string, error)
+	// VerifyClick verifies the signed parameters of a click and returns the query the
+	// destination URL is expanded with. Tampered clicks are recorded; they fail with
+	// domain.ErrLinkTampered under the reject enforcement, and lose their click parameters
+	// under the flag enforcement.
+	VerifyClick(ctx context.Context, trackingLink *domain.TrackingLink, query url.Values, visitor domain.TargetingVisitor) (url.Values, error)
+}
+
+// trackingLinkSigningService implements TrackingLinkSigningService
+type trackingLinkSigningService struct {
+	signingRepo      repository.TrackingLinkSigningRepository
+	trackingLinkRepo repository.TrackingLinkRepository
+	cryptoService    crypto.Service
+}
+
+// NewTrackingLinkSigningService creates a new tracking link signing service. Key secrets are
+// stored encrypted with cryptoService.
+func NewTrackingLinkSigningService(signingRepo repository.TrackingLinkSigningRepository, trackingLinkRepo repository.TrackingLinkRepository, cryptoService crypto.Service) TrackingLinkSigningService {
+	return &trackingLinkSigningService{
+		signingRepo:      signingRepo,
+		trackingLinkRepo: trackingLinkRepo,
+		cryptoService:    cryptoService,
+	}
+}
+
+// GetSigning returns the signing settings and keys of an organization
+func (s *trackingLinkSigningService) GetSigning(ctx context.Context, orgID int64) (*domain.TrackingLinkSigning, error) {
+	settings, err := s.signingRepo.GetSettings(ctx, orgID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	keys, err := s.signingRepo.ListKeys(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TrackingLinkSigning{Enabled: settings != nil, Settings: settings, Keys: keys}, nil
+}
+
+// SetSigning enables signing for an organization or changes its enforcement
+func (s *trackingLinkSigningService) SetSigning(ctx context.Context, orgID int64, req *domain.SetTrackingLinkSigningRequest) (*domain.TrackingLinkSigning, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	if _, err := s.signingRepo.GetActiveKey(ctx, orgID); err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		if _, err := s.RotateKey(ctx, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	settings := &domain.TrackingLinkSigningSettings{OrganizationID: orgID, Enforcement: req.Enforcement}
+	if err := s.signingRepo.UpsertSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	// Links created before signing was enabled carry no signature and would be refused
+	if err := s.resignLinks(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.GetSigning(ctx, orgID)
+}
+
+// DisableSigning disables signing for an organization
+func (s *trackingLinkSigningService) DisableSigning(ctx context.Context, orgID int64) error {
+	return s.signingRepo.DeleteSettings(ctx, orgID)
+}
+
+// RotateKey generates a new secret and makes it the active key of the organization
+func (s *trackingLinkSigningService) RotateKey(ctx context.Context, orgID int64) (*domain.TrackingLinkSigningKey, error) {
+	secret, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	encryptedSecret, err := s.cryptoService.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+	key, err := s.signingRepo.RotateKey(ctx, orgID, encryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Rotated tracking link signing key", "organization_id", orgID, "key_id", key.KeyID)
+	return key, nil
+}
+
+// RevokeKey revokes a retired key of an organization
+func (s *trackingLinkSigningService) RevokeKey(ctx context.Context, orgID, keyID int64) error {
+	key, err := s.signingRepo.GetKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.OrganizationID != orgID {
+		return domain.ErrNotFound
+	}
+	switch key.Status {
+	case domain.SigningKeyStatusActive:
+		return fmt.Errorf("%w: the active key cannot be revoked; rotate it first", domain.ErrInvalidInput)
+	case domain.SigningKeyStatusRevoked:
+		return fmt.Errorf("%w: the key is already revoked", domain.ErrInvalidInput)
+	}
+	if err := s.resignLinks(ctx, orgID); err != nil {
+		return err
+	}
+	return s.signingRepo.RevokeKey(ctx, orgID, keyID)
+}
+
+// resignLinks signs the stored native URLs of an organization's links with its active key. URLs
+// already signed with it, and provider URLs, are left alone.
+func (s *trackingLinkSigningService) resignLinks(ctx context.Context, orgID int64) error {
+	key, err := s.signingRepo.GetActiveKey(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	activeKeyID := strconv.FormatInt(key.KeyID, 10)
+
+	resigned := 0
+	for offset := 0; ; offset += linkResignBatchSize {
+		links, err := s.trackingLinkRepo.ListTrackingLinksByOrganization(ctx, orgID, linkResignBatchSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, trackingLink := range links {
+			if trackingLink.TrackingURL == nil {
+				continue
+			}
+			stored, err := url.Parse(*trackingLink.TrackingURL)
+			if err != nil || stored.Path != domain.TrackingLinkRedirectPath+strconv.FormatInt(trackingLink.TrackingLinkID, 10) {
+				continue
+			}
+			if stored.Query().Get(domain.LinkParamKeyID) == activeKeyID {
+				continue
+			}
+			nativeURL := stored.Scheme + "://" + stored.Host + stored.Path
+			signedURL, err := s.SignLinkURL(ctx, trackingLink, nativeURL)
+			if err != nil {
+				return err
+			}
+			if err := s.trackingLinkRepo.UpdateTrackingURL(ctx, trackingLink.TrackingLinkID, signedURL); err != nil {
+				return err
+			}
+			resigned++
+		}
+		if len(links) < linkResignBatchSize {
+			break
+		}
+	}
+	if resigned > 0 {
+		logger.Info("Signed stored tracking link URLs", "organization_id", orgID, "key_id", key.KeyID, "links", resigned)
+	}
+	return nil
+}
+
+// ListTamperEvents lists the clicks of an organization that failed verification
+func (s *trackingLinkSigningService) ListTamperEvents(ctx context.Context, orgID int64, page, pageSize int) (*domain.TrackingLinkTamperEventListResponse, error) {
+	events, total, err := s.signingRepo.ListTamperEvents(ctx, orgID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TrackingLinkTamperEventListResponse{Events: events, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// SignLinkURL signs a native link URL with the active key of the link's organization
+func (s *trackingLinkSigningService) SignLinkURL(ctx context.Context, trackingLink *domain.TrackingLink, nativeURL string) (string, error) {
+	if _, err := s.signingRepo.GetSettings(ctx, trackingLink.OrganizationID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nativeURL, nil
+		}
+		return "", err
+	}
+	key, err := s.signingRepo.GetActiveKey(ctx, trackingLink.OrganizationID)
+	if err != nil {
+		return "", err
+	}
+	secret, err := s.keySecret(key)
+	if err != nil {
+		return "", err
+	}
+
+	params := domain.SignedLinkParameters(trackingLink)
+	signed := url.Values{}
+	signed.Set(domain.LinkParamKeyID, strconv.FormatInt(key.KeyID, 10))
+	if trackingLink.IsEncryptParameters != nil && *trackingLink.IsEncryptParameters {
+		sealed, err := crypto.Seal(crypto.DeriveKey(secret, linkEncryptionKeyPurpose), []byte(params.Encode()),
+			domain.LinkEncryptionAdditionalData(trackingLink.TrackingLinkID, key.KeyID))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt tracking link parameters: %w", err)
+		}
+		signed.Set(domain.LinkParamData, sealed)
+	} else {
+		for name, values := range params {
+			signed[name] = values
+		}
+		message := domain.LinkSignatureMessage(trackingLink.TrackingLinkID, key.KeyID, params)
+		signed.Set(domain.LinkParamSignature, crypto.Sign(crypto.DeriveKey(secret, linkSigningKeyPurpose), message))
+	}
+
+	separator := "?"
+	if strings.Contains(nativeURL, "?") {
+		separator = "&"
+	}
+	return nativeURL + separator + signed.Encode(), nil
+}
+
+// VerifyClick verifies a click on a link of an organization that signs links
+func (s *trackingLinkSigningService) VerifyClick(ctx context.Context, trackingLink *domain.TrackingLink, query url.Values, visitor domain.TargetingVisitor) (url.Values, error) {
+	settings, err := s.signingRepo.GetSettings(ctx, trackingLink.OrganizationID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return query, nil
+		}
+		return nil, err
+	}
+
+	verified, keyID, reason, err := s.verifyParameters(ctx, trackingLink, query)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return verified, nil
+	}
+
+	action := domain.TamperActionFlagged
+	if settings.Enforcement == domain.LinkSigningEnforcementReject {
+		action = domain.TamperActionRejected
+	}
+	event := &domain.TrackingLinkTamperEvent{
+		OrganizationID: trackingLink.OrganizationID,
+		TrackingLinkID: trackingLink.TrackingLinkID,
+		KeyID:          keyID,
+		Reason:         reason,
+		Action:         action,
+	}
+	if visitor.IP != nil {
+		ip := visitor.IP.String()
+		event.IP = &ip
+	}
+	if visitor.UserAgent != "" {
+		event.UserAgent = &visitor.UserAgent
+	}
+	logger.Warn("Tracking link click failed verification", "tracking_link_id", trackingLink.TrackingLinkID,
+		"reason", reason, "action", action)
+	if err := s.signingRepo.CreateTamperEvent(ctx, event); err != nil {
+		logger.Error("Failed to record tracking link tamper event", "tracking_link_id", trackingLink.TrackingLinkID, "error", err)
+	}
+
+	if action == domain.TamperActionRejected {
+		return nil, fmt.Errorf("%s: %w", reason, domain.ErrLinkTampered)
+	}
+	// Flagged clicks are redirected with the link's stored parameters
+	return withoutSignedParameters(query), nil
+}
+
+// verifyParameters returns the click query with the verified parameters, or the reason the
+// click failed verification
+func (s *trackingLinkSigningService) verifyParameters(ctx context.Context, trackingLink *domain.TrackingLink, query url.Values) (url.Values, *int64, string, error) {
+	keyID, err := strconv.ParseInt(query.Get(domain.LinkParamKeyID), 10, 64)
+	if err != nil {
+		if query.Get(domain.LinkParamKeyID) == "" {
+			return nil, nil, domain.TamperReasonMissingSignature, nil
+		}
+		return nil, nil, domain.TamperReasonUnknownKey, nil
+	}
+	key, err := s.signingRepo.GetKey(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, domain.TamperReasonUnknownKey, nil
+		}
+		return nil, nil, "", err
+	}
+	if key.OrganizationID != trackingLink.OrganizationID {
+		return nil, nil, domain.TamperReasonUnknownKey, nil
+	}
+	if key.Status == domain.SigningKeyStatusRevoked {
+		return nil, &keyID, domain.TamperReasonRevokedKey, nil
+	}
+	secret, err := s.keySecret(key)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var params url.Values
+	if data := query.Get(domain.LinkParamData); data != "" {
+		plaintext, err := crypto.Open(crypto.DeriveKey(secret, linkEncryptionKeyPurpose), data,
+			domain.LinkEncryptionAdditionalData(trackingLink.TrackingLinkID, keyID))
+		if err != nil {
+			return nil, &keyID, domain.TamperReasonUndecryptable, nil
+		}
+		if params, err = url.ParseQuery(string(plaintext)); err != nil {
+			return nil, &keyID, domain.TamperReasonUndecryptable, nil
+		}
+	} else {
+		signature := query.Get(domain.LinkParamSignature)
+		if signature == "" {
+			return nil, &keyID, domain.TamperReasonMissingSignature, nil
+		}
+		var ok bool
+		if params, ok = domain.ExtractSignedLinkParameters(query); !ok {
+			return nil, &keyID, domain.TamperReasonInvalidSignature, nil
+		}
+		message := domain.LinkSignatureMessage(trackingLink.TrackingLinkID, keyID, params)
+		if !crypto.Verify(crypto.DeriveKey(secret, linkSigningKeyPurpose), message, signature) {
+			return nil, &keyID, domain.TamperReasonInvalidSignature, nil
+		}
+	}
+	if params.Get(domain.LinkParamAffiliateID) != strconv.FormatInt(trackingLink.AffiliateID, 10) {
+		return nil, &keyID, domain.TamperReasonAffiliateMismatch, nil
+	}
+
+	verified := withoutSignedParameters(query)
+	for _, name := range domain.SignedLinkParameterNames {
+		if value := params.Get(name); value != "" {
+			verified.Set(name, value)
+		}
+	}
+	return verified, &keyID, "", nil
+}
+
+// keySecret decrypts the secret of a signing key
+func (s *trackingLinkSigningService) keySecret(key *domain.TrackingLinkSigningKey) ([]byte, error) {
+	encoded, err := s.cryptoService.Decrypt(key.EncryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt signing key %d: %w", key.KeyID, err)
+	}
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing key %d: %w", key.KeyID, err)
+	}
+	return secret, nil
+}
+
+// withoutSignedParameters copies a click query without the signed parameters and signature
+func withoutSignedParameters(query url.Values) url.Values {
+	clean := make(url.Values, len(query))
+	for name, values := range query {
+		clean[name] = values
+	}
+	for _, name := range domain.SignedLinkParameterNames {
+		delete(clean, name)
+	}
+	delete(clean, domain.LinkParamKeyID)
+	delete(clean, domain.LinkParamSignature)
+	delete(clean, domain.LinkParamData)
+	return clean
+}
@@ -0,0 +1,11 @@
+-- #############################################################################
+-- ## Provider Stats Warehouse Migration Rollback
+-- ## This migration removes the provider stats fact tables
+-- #############################################################################
+
+-- Drop the timestamp trigger
+DROP TRIGGER IF EXISTS set_provider_stats_imports_timestamp ON public.provider_stats_imports;
+
+-- Drop the tables (this will also drop all indexes and constraints)
+DROP TABLE IF EXISTS public.provider_stats_imports;
+DROP TABLE IF EXISTS public.provider_daily_stats;
@@ -0,0 +1,84 @@
+-- #############################################################################
+-- ## Provider Stats Warehouse Migration
+-- ## This migration adds local fact tables for daily reporting data pulled from
+-- ## external providers (Everflow entity reports).
+-- ##
+-- ## Features:
+-- ## - Daily stats per provider offer, affiliate and sub IDs
+-- ## - Resolution of provider IDs to local campaigns and affiliates
+-- ## - Import run history for scheduled imports and on-demand backfills
+-- #############################################################################
+
+-- provider_daily_stats: Daily performance facts imported from a provider
+CREATE TABLE public.provider_daily_stats (
+    stat_id BIGSERIAL PRIMARY KEY,
+    provider_type VARCHAR(50) NOT NULL DEFAULT 'everflow' CHECK (provider_type IN ('everflow')),
+    stat_date DATE NOT NULL,
+
+    -- Provider-side dimensions
+    provider_offer_id VARCHAR(255) NOT NULL,
+    provider_affiliate_id VARCHAR(255) NOT NULL,
+    sub1 VARCHAR(255) NOT NULL DEFAULT '',
+    sub2 VARCHAR(255) NOT NULL DEFAULT '',
+    sub3 VARCHAR(255) NOT NULL DEFAULT '',
+    sub4 VARCHAR(255) NOT NULL DEFAULT '',
+    sub5 VARCHAR(255) NOT NULL DEFAULT '',
+
+    -- Local entities resolved through provider mappings (NULL when unmapped)
+    campaign_id BIGINT REFERENCES public.campaigns(campaign_id) ON DELETE SET NULL,
+    affiliate_id BIGINT REFERENCES public.affiliates(affiliate_id) ON DELETE SET NULL,
+
+    -- Metrics
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    unique_clicks BIGINT NOT NULL DEFAULT 0,
+    conversions BIGINT NOT NULL DEFAULT 0,
+    revenue DECIMAL(15,4) NOT NULL DEFAULT 0,
+    payout DECIMAL(15,4) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+
+    imported_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    -- One row per provider day and dimension combination
+    CONSTRAINT unique_provider_daily_stat UNIQUE (provider_type, stat_date, provider_offer_id, provider_affiliate_id, sub1, sub2, sub3, sub4, sub5)
+);
+
+-- provider_stats_imports: History of import runs per provider day
+CREATE TABLE public.provider_stats_imports (
+    import_id BIGSERIAL PRIMARY KEY,
+    provider_type VARCHAR(50) NOT NULL DEFAULT 'everflow' CHECK (provider_type IN ('everflow')),
+    stat_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    triggered_by VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (triggered_by IN ('scheduled', 'backfill')),
+    row_count INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Add trigger for automatic timestamp updates
+CREATE TRIGGER set_provider_stats_imports_timestamp
+BEFORE UPDATE ON public.provider_stats_imports
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Indexes for performance
+CREATE INDEX idx_provider_daily_stats_stat_date ON public.provider_daily_stats(stat_date);
+CREATE INDEX idx_provider_daily_stats_campaign_date ON public.provider_daily_stats(campaign_id, stat_date) WHERE campaign_id IS NOT NULL;
+CREATE INDEX idx_provider_daily_stats_affiliate_date ON public.provider_daily_stats(affiliate_id, stat_date) WHERE affiliate_id IS NOT NULL;
+CREATE INDEX idx_provider_stats_imports_provider_date ON public.provider_stats_imports(provider_type, stat_date DESC);
+CREATE INDEX idx_provider_stats_imports_status ON public.provider_stats_imports(status);
+
+-- Add comments for documentation
+COMMENT ON TABLE public.provider_daily_stats IS 'Daily performance facts imported from provider reporting endpoints';
+COMMENT ON TABLE public.provider_stats_imports IS 'Run history of provider stats imports, one row per imported day and run';
+
+COMMENT ON COLUMN public.provider_daily_stats.provider_offer_id IS 'Provider offer ID (Everflow network_offer_id)';
+COMMENT ON COLUMN public.provider_daily_stats.provider_affiliate_id IS 'Provider affiliate ID (Everflow network_affiliate_id)';
+COMMENT ON COLUMN public.provider_daily_stats.campaign_id IS 'Local campaign resolved from campaign_provider_mappings';
+COMMENT ON COLUMN public.provider_daily_stats.affiliate_id IS 'Local affiliate resolved from affiliate_provider_mappings';
+COMMENT ON COLUMN public.provider_daily_stats.revenue IS 'Revenue earned from the advertiser for the day';
+COMMENT ON COLUMN public.provider_daily_stats.payout IS 'Payout owed to the affiliate for the day';
+COMMENT ON COLUMN public.provider_stats_imports.triggered_by IS 'What started the import: scheduled (cron) or backfill (on demand)';
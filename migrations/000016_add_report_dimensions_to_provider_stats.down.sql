@@ -0,0 +1,16 @@
+-- #############################################################################
+-- ## Report Dimensions Migration Rollback
+-- ## This migration removes the hour, country and device dimensions
+-- #############################################################################
+
+-- Hour, country and device rows collapse onto the same key, so they cannot be kept
+DELETE FROM public.provider_daily_stats;
+
+ALTER TABLE public.provider_daily_stats DROP CONSTRAINT unique_provider_daily_stat;
+ALTER TABLE public.provider_daily_stats
+    ADD CONSTRAINT unique_provider_daily_stat UNIQUE (provider_type, stat_date, provider_offer_id, provider_affiliate_id, sub1, sub2, sub3, sub4, sub5);
+
+ALTER TABLE public.provider_daily_stats
+    DROP COLUMN IF EXISTS device_type,
+    DROP COLUMN IF EXISTS country_code,
+    DROP COLUMN IF EXISTS stat_hour;
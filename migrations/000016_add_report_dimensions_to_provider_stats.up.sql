@@ -0,0 +1,26 @@
+-- #############################################################################
+-- ## Report Dimensions Migration
+-- ## This migration extends the provider stats warehouse with the dimensions
+-- ## needed by the performance reporting API.
+-- ##
+-- ## Features:
+-- ## - Hour of day (UTC) so reports can be bucketed by hour and shifted into
+-- ##   the requested timezone
+-- ## - Country and device type breakdowns
+-- #############################################################################
+
+-- Existing rows are day-level; they are kept and attributed to hour 0
+ALTER TABLE public.provider_daily_stats
+    ADD COLUMN stat_hour SMALLINT NOT NULL DEFAULT 0 CHECK (stat_hour BETWEEN 0 AND 23),
+    ADD COLUMN country_code VARCHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN device_type VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Widen the natural key to include the new dimensions
+ALTER TABLE public.provider_daily_stats DROP CONSTRAINT unique_provider_daily_stat;
+ALTER TABLE public.provider_daily_stats
+    ADD CONSTRAINT unique_provider_daily_stat UNIQUE (provider_type, stat_date, stat_hour, provider_offer_id, provider_affiliate_id, sub1, sub2, sub3, sub4, sub5, country_code, device_type);
+
+-- Add comments for documentation
+COMMENT ON COLUMN public.provider_daily_stats.stat_hour IS 'Hour of day (0-23) in UTC that the facts belong to';
+COMMENT ON COLUMN public.provider_daily_stats.country_code IS 'ISO 3166-1 alpha-2 country code, empty when unknown';
+COMMENT ON COLUMN public.provider_daily_stats.device_type IS 'Device type reported by the provider (e.g. mobile, desktop), empty when unknown';
@@ -0,0 +1,11 @@
+-- #############################################################################
+-- ## Scheduled Reports Migration Rollback
+-- ## This migration removes the scheduled report tables
+-- #############################################################################
+
+-- Drop the timestamp trigger
+DROP TRIGGER IF EXISTS set_scheduled_reports_timestamp ON public.scheduled_reports;
+
+-- Drop the tables (this will also drop all indexes and constraints)
+DROP TABLE IF EXISTS public.scheduled_report_runs;
+DROP TABLE IF EXISTS public.scheduled_reports;
@@ -0,0 +1,71 @@
+-- #############################################################################
+-- ## Scheduled Reports Migration
+-- ## This migration adds saved report definitions that are rendered on a cron
+-- ## schedule and delivered by email, SFTP or to an S3-compatible bucket.
+-- ##
+-- ## Features:
+-- ## - Saved report query, relative date range, file format and cron schedule
+-- ## - Per-report delivery configuration (secrets stored encrypted)
+-- ## - Run history with archived files for later download
+-- #############################################################################
+
+-- scheduled_reports: Saved report definitions
+CREATE TABLE public.scheduled_reports (
+    scheduled_report_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    query JSONB NOT NULL DEFAULT '{}'::jsonb,
+    date_range VARCHAR(30) NOT NULL
+        CHECK (date_range IN ('yesterday', 'last_7_days', 'last_30_days', 'month_to_date', 'previous_month')),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'xlsx', 'jsonl')),
+    schedule VARCHAR(100) NOT NULL,
+    delivery_type VARCHAR(10) NOT NULL CHECK (delivery_type IN ('email', 'sftp', 's3')),
+    delivery_config JSONB NOT NULL DEFAULT '{}'::jsonb,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    created_by_user_id UUID, -- References profiles.id (auth.uid())
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- scheduled_report_runs: Execution history of scheduled reports
+CREATE TABLE public.scheduled_report_runs (
+    run_id BIGSERIAL PRIMARY KEY,
+    scheduled_report_id BIGINT NOT NULL REFERENCES public.scheduled_reports(scheduled_report_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    triggered_by VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (triggered_by IN ('scheduled', 'manual')),
+    range_from DATE NOT NULL,
+    range_to DATE NOT NULL,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    file_size BIGINT,
+    storage_key TEXT,
+    error_message TEXT,
+    started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Add trigger for automatic timestamp updates
+CREATE TRIGGER set_scheduled_reports_timestamp
+BEFORE UPDATE ON public.scheduled_reports
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Indexes for performance
+CREATE INDEX idx_scheduled_reports_organization_id ON public.scheduled_reports(organization_id);
+CREATE INDEX idx_scheduled_reports_due ON public.scheduled_reports(next_run_at) WHERE is_active = TRUE;
+CREATE INDEX idx_scheduled_report_runs_report_id ON public.scheduled_report_runs(scheduled_report_id, started_at DESC);
+
+-- Add comments for documentation
+COMMENT ON TABLE public.scheduled_reports IS 'Saved performance report definitions delivered on a cron schedule';
+COMMENT ON TABLE public.scheduled_report_runs IS 'Execution history of scheduled reports, including archived output files';
+
+COMMENT ON COLUMN public.scheduled_reports.query IS 'Saved report query (group_by, filters, sort) without a date range';
+COMMENT ON COLUMN public.scheduled_reports.date_range IS 'Relative date range resolved in the report timezone at run time';
+COMMENT ON COLUMN public.scheduled_reports.schedule IS 'Standard 5-field cron expression evaluated in the report timezone';
+COMMENT ON COLUMN public.scheduled_reports.delivery_config IS 'Delivery settings (recipients, SFTP or S3 destination); passwords and secret keys are encrypted';
+COMMENT ON COLUMN public.scheduled_report_runs.storage_key IS 'Key of the archived output file in report storage';
@@ -0,0 +1,5 @@
+-- #############################################################################
+-- ## Publisher Message Email Threading Migration Rollback
+-- #############################################################################
+
+DROP INDEX IF EXISTS public.idx_publisher_messages_email_message_id;
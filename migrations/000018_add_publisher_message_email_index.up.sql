@@ -0,0 +1,10 @@
+-- #############################################################################
+-- ## Publisher Message Email Threading Migration
+-- ## Indexes the Message-ID of emailed organization messages so inbound
+-- ## publisher replies can be matched through In-Reply-To/References headers
+-- #############################################################################
+
+CREATE INDEX idx_publisher_messages_email_message_id
+    ON public.publisher_messages ((metadata->'email_delivery'->>'message_id'));
+
+COMMENT ON INDEX public.idx_publisher_messages_email_message_id IS 'Message-ID of the email sent for an organization message, used to thread inbound replies';
@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Publisher Discovery Search Migration Rollback
+-- #############################################################################
+
+DROP INDEX IF EXISTS public.idx_analytics_publishers_keywords_text;
+DROP INDEX IF EXISTS public.idx_analytics_publishers_description_text;
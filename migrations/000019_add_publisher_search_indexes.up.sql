@@ -0,0 +1,11 @@
+-- #############################################################################
+-- ## Publisher Discovery Search Migration
+-- ## Full text indexes for publisher discovery search over descriptions and
+-- ## keywords, complementing the existing idx_analytics_publishers_domain_text
+-- #############################################################################
+
+CREATE INDEX idx_analytics_publishers_description_text
+    ON public.analytics_publishers USING gin(to_tsvector('english', COALESCE(description, '')));
+
+CREATE INDEX idx_analytics_publishers_keywords_text
+    ON public.analytics_publishers USING gin(jsonb_to_tsvector('english', COALESCE(keywords->'value', '[]'::jsonb), '["string"]'));
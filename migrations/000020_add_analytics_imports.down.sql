@@ -0,0 +1,12 @@
+-- #############################################################################
+-- ## Analytics Bulk Import Migration Rollback
+-- #############################################################################
+
+DROP TABLE IF EXISTS public.analytics_advertiser_snapshots;
+DROP TABLE IF EXISTS public.analytics_publisher_snapshots;
+
+ALTER TABLE public.analytics_advertisers DROP COLUMN IF EXISTS content_hash;
+ALTER TABLE public.analytics_publishers DROP COLUMN IF EXISTS content_hash;
+
+DROP TABLE IF EXISTS public.analytics_import_errors;
+DROP TABLE IF EXISTS public.analytics_imports;
@@ -0,0 +1,80 @@
+-- #############################################################################
+-- ## Analytics Bulk Import Migration
+-- ## This migration adds bulk NDJSON imports of analytics publishers and
+-- ## advertisers, upserted by domain.
+-- ##
+-- ## Features:
+-- ## - Import runs with inserted/updated/skipped/failed counts
+-- ## - Per-row import errors
+-- ## - Content hash on each record so unchanged rows are skipped
+-- ## - Snapshot history of key metrics per domain
+-- #############################################################################
+
+-- analytics_imports: Bulk import runs
+CREATE TABLE public.analytics_imports (
+    import_id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('publishers', 'advertisers')),
+    source VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    inserted_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    skipped_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by_user_id UUID, -- References profiles.id (auth.uid()); NULL for the command line tool
+    started_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+-- analytics_import_errors: Records that could not be imported
+CREATE TABLE public.analytics_import_errors (
+    import_error_id BIGSERIAL PRIMARY KEY,
+    import_id BIGINT NOT NULL REFERENCES public.analytics_imports(import_id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    domain VARCHAR(255),
+    message TEXT NOT NULL
+);
+
+-- Hash of the last imported source record
+ALTER TABLE public.analytics_publishers ADD COLUMN content_hash VARCHAR(64);
+ALTER TABLE public.analytics_advertisers ADD COLUMN content_hash VARCHAR(64);
+
+-- analytics_publisher_snapshots: Key publisher metrics per import
+CREATE TABLE public.analytics_publisher_snapshots (
+    snapshot_id BIGSERIAL PRIMARY KEY,
+    publisher_id BIGINT NOT NULL REFERENCES public.analytics_publishers(id) ON DELETE CASCADE,
+    domain VARCHAR(255) NOT NULL,
+    import_id BIGINT REFERENCES public.analytics_imports(import_id) ON DELETE SET NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    traffic_score DECIMAL(10,2) NOT NULL DEFAULT 0,
+    relevance DECIMAL(5,2) NOT NULL DEFAULT 0,
+    known BOOLEAN NOT NULL DEFAULT FALSE,
+    promotype VARCHAR(50),
+    partner_count INTEGER NOT NULL DEFAULT 0,
+    affiliate_networks JSONB NOT NULL DEFAULT '[]'::jsonb, -- Sorted network names
+    verticals JSONB NOT NULL DEFAULT '[]'::jsonb, -- Sorted verticalsV2 names
+    countries JSONB NOT NULL DEFAULT '[]'::jsonb, -- Sorted upper case country codes
+    captured_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- analytics_advertiser_snapshots: Key advertiser metrics per import
+CREATE TABLE public.analytics_advertiser_snapshots (
+    snapshot_id BIGSERIAL PRIMARY KEY,
+    advertiser_id BIGINT NOT NULL REFERENCES public.analytics_advertisers(id) ON DELETE CASCADE,
+    domain VARCHAR(255) NOT NULL,
+    import_id BIGINT REFERENCES public.analytics_imports(import_id) ON DELETE SET NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    contact_email_count INTEGER NOT NULL DEFAULT 0,
+    related_advertiser_count INTEGER NOT NULL DEFAULT 0,
+    affiliate_networks JSONB NOT NULL DEFAULT '[]'::jsonb, -- Sorted network names
+    verticals JSONB NOT NULL DEFAULT '[]'::jsonb, -- Sorted vertical names
+    captured_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Indexes for performance
+CREATE INDEX idx_analytics_imports_started_at ON public.analytics_imports(started_at DESC);
+CREATE INDEX idx_analytics_import_errors_import_id ON public.analytics_import_errors(import_id, line_number);
+CREATE INDEX idx_analytics_publisher_snapshots_domain ON public.analytics_publisher_snapshots(domain, captured_at DESC);
+CREATE INDEX idx_analytics_publisher_snapshots_publisher_id ON public.analytics_publisher_snapshots(publisher_id, captured_at DESC);
+CREATE INDEX idx_analytics_advertiser_snapshots_domain ON public.analytics_advertiser_snapshots(domain, captured_at DESC);
+CREATE INDEX idx_analytics_advertiser_snapshots_advertiser_id ON public.analytics_advertiser_snapshots(advertiser_id, captured_at DESC);
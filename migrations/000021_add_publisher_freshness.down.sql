@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Publisher Freshness Migration Rollback
+-- ## Seeded baseline snapshots are kept with the rest of the snapshot history.
+-- #############################################################################
+
+ALTER TABLE public.analytics_publishers DROP COLUMN IF EXISTS last_imported_at;
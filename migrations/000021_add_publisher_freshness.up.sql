@@ -0,0 +1,46 @@
+-- #############################################################################
+-- ## Publisher Freshness Migration
+-- ## Tracks when a publisher was last seen by a bulk import and seeds the
+-- ## snapshot history with the current metrics of every publisher that has
+-- ## no snapshot yet, so the first import afterwards already produces a diff.
+-- #############################################################################
+
+ALTER TABLE public.analytics_publishers ADD COLUMN last_imported_at TIMESTAMPTZ;
+
+INSERT INTO public.analytics_publisher_snapshots (
+    publisher_id, domain, content_hash, traffic_score, relevance, known, promotype,
+    partner_count, affiliate_networks, verticals, countries, captured_at
+)
+SELECT
+    p.id,
+    p.domain,
+    COALESCE(p.content_hash, ''),
+    COALESCE(p.traffic_score, 0),
+    COALESCE(p.relevance, 0),
+    COALESCE(p.known, FALSE),
+    p.promotype,
+    CASE
+        WHEN jsonb_typeof(p.partners->'count') = 'number' THEN (p.partners->>'count')::numeric::integer
+        WHEN jsonb_typeof(p.partners->'value') = 'array' THEN jsonb_array_length(p.partners->'value')
+        ELSE 0
+    END,
+    (SELECT COALESCE(jsonb_agg(DISTINCT name ORDER BY name), '[]'::jsonb)
+     FROM jsonb_array_elements_text(
+         CASE WHEN jsonb_typeof(p.affiliate_networks->'value') = 'array' THEN p.affiliate_networks->'value' ELSE '[]'::jsonb END
+     ) AS name
+     WHERE name <> ''),
+    (SELECT COALESCE(jsonb_agg(DISTINCT vertical->>'name' ORDER BY vertical->>'name'), '[]'::jsonb)
+     FROM jsonb_array_elements(
+         CASE WHEN jsonb_typeof(p.verticals_v2->'value') = 'array' THEN p.verticals_v2->'value' ELSE '[]'::jsonb END
+     ) AS vertical
+     WHERE COALESCE(vertical->>'name', '') <> ''),
+    (SELECT COALESCE(jsonb_agg(DISTINCT upper(country->>'countryCode') ORDER BY upper(country->>'countryCode')), '[]'::jsonb)
+     FROM jsonb_array_elements(
+         CASE WHEN jsonb_typeof(p.country_rankings->'value') = 'array' THEN p.country_rankings->'value' ELSE '[]'::jsonb END
+     ) AS country
+     WHERE COALESCE(country->>'countryCode', '') <> ''),
+    p.updated_at
+FROM public.analytics_publishers p
+WHERE NOT EXISTS (
+    SELECT 1 FROM public.analytics_publisher_snapshots s WHERE s.publisher_id = p.id
+);
@@ -0,0 +1,21 @@
+-- #############################################################################
+-- ## Publisher Pipeline Migration Rollback
+-- ## Removes pipeline stages, stage history and the pipeline fields of list items
+-- #############################################################################
+
+DROP TABLE IF EXISTS public.publisher_pipeline_stage_changes;
+
+DROP INDEX IF EXISTS public.idx_favorite_publisher_list_items_due_reminders;
+DROP INDEX IF EXISTS public.idx_favorite_publisher_list_items_owner;
+DROP INDEX IF EXISTS public.idx_favorite_publisher_list_items_stage_id;
+
+ALTER TABLE public.favorite_publisher_list_items
+    DROP COLUMN IF EXISTS reminder_sent_at,
+    DROP COLUMN IF EXISTS next_action_note,
+    DROP COLUMN IF EXISTS next_action_at,
+    DROP COLUMN IF EXISTS owner_user_id,
+    DROP COLUMN IF EXISTS stage_changed_at,
+    DROP COLUMN IF EXISTS stage_id;
+
+DROP TRIGGER IF EXISTS set_publisher_pipeline_stages_timestamp ON public.publisher_pipeline_stages;
+DROP TABLE IF EXISTS public.publisher_pipeline_stages;
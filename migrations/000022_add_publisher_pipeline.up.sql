@@ -0,0 +1,92 @@
+-- #############################################################################
+-- ## Publisher Pipeline Migration
+-- ## Turns favorite lists into an outreach pipeline.
+-- ##
+-- ## Features:
+-- ## - Configurable pipeline stages per organization (open, won or lost)
+-- ## - Stage, owner and next action with reminder tracking on list items
+-- ## - Stage change history per list item
+-- ## - Existing items are moved to the default stage matching their status
+-- #############################################################################
+
+-- publisher_pipeline_stages: Outreach stages of an organization, in board order
+CREATE TABLE public.publisher_pipeline_stages (
+    stage_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    outcome VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (outcome IN ('open', 'won', 'lost')),
+    color VARCHAR(7),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    CONSTRAINT unique_pipeline_stage_key_per_org UNIQUE (organization_id, key)
+);
+
+CREATE TRIGGER set_publisher_pipeline_stages_timestamp
+BEFORE UPDATE ON public.publisher_pipeline_stages
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Pipeline fields on list items. Stages holding items cannot be deleted.
+ALTER TABLE public.favorite_publisher_list_items
+    ADD COLUMN stage_id BIGINT REFERENCES public.publisher_pipeline_stages(stage_id) ON DELETE RESTRICT,
+    ADD COLUMN stage_changed_at TIMESTAMPTZ,
+    ADD COLUMN owner_user_id UUID, -- References profiles.id (auth.uid())
+    ADD COLUMN next_action_at TIMESTAMPTZ,
+    ADD COLUMN next_action_note TEXT,
+    ADD COLUMN reminder_sent_at TIMESTAMPTZ;
+
+-- publisher_pipeline_stage_changes: Stage history of list items. Stage names are copied so
+-- the history stays readable after a stage is renamed or deleted.
+CREATE TABLE public.publisher_pipeline_stage_changes (
+    change_id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES public.favorite_publisher_list_items(item_id) ON DELETE CASCADE,
+    from_stage_id BIGINT REFERENCES public.publisher_pipeline_stages(stage_id) ON DELETE SET NULL,
+    from_stage_name VARCHAR(100),
+    to_stage_id BIGINT REFERENCES public.publisher_pipeline_stages(stage_id) ON DELETE SET NULL,
+    to_stage_name VARCHAR(100) NOT NULL,
+    changed_by_user_id UUID, -- NULL for automatic changes
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('created', 'moved', 'status', 'publisher_reply')),
+    note TEXT,
+    changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Indexes for performance
+CREATE INDEX idx_publisher_pipeline_stages_organization_id ON public.publisher_pipeline_stages(organization_id, position);
+CREATE INDEX idx_favorite_publisher_list_items_stage_id ON public.favorite_publisher_list_items(stage_id);
+CREATE INDEX idx_favorite_publisher_list_items_owner ON public.favorite_publisher_list_items(owner_user_id) WHERE owner_user_id IS NOT NULL;
+CREATE INDEX idx_favorite_publisher_list_items_due_reminders ON public.favorite_publisher_list_items(next_action_at)
+    WHERE next_action_at IS NOT NULL AND reminder_sent_at IS NULL AND owner_user_id IS NOT NULL;
+CREATE INDEX idx_publisher_pipeline_stage_changes_item ON public.publisher_pipeline_stage_changes(item_id, changed_at DESC);
+
+-- Default stages for every organization that already has lists; organizations without stages
+-- get the same defaults when they first use the pipeline
+INSERT INTO public.publisher_pipeline_stages (organization_id, key, name, position, outcome)
+SELECT o.organization_id, d.key, d.name, d.position, d.outcome
+FROM (SELECT DISTINCT organization_id FROM public.favorite_publisher_lists) o
+CROSS JOIN (VALUES
+    ('researching', 'Researching', 0, 'open'),
+    ('contacted', 'Contacted', 1, 'open'),
+    ('negotiating', 'Negotiating', 2, 'open'),
+    ('signed', 'Signed', 3, 'won'),
+    ('rejected', 'Rejected', 4, 'lost')
+) AS d(key, name, position, outcome);
+
+UPDATE public.favorite_publisher_list_items i
+SET stage_id = s.stage_id, stage_changed_at = i.added_at
+FROM public.favorite_publisher_lists l, public.publisher_pipeline_stages s
+WHERE l.list_id = i.list_id
+  AND s.organization_id = l.organization_id
+  AND s.key = CASE i.status WHEN 'added' THEN 'researching' WHEN 'contacted' THEN 'contacted' ELSE 'signed' END;
+
+-- Add comments for documentation
+COMMENT ON TABLE public.publisher_pipeline_stages IS 'Configurable outreach pipeline stages of an organization';
+COMMENT ON TABLE public.publisher_pipeline_stage_changes IS 'Stage change history of favorite list items';
+
+COMMENT ON COLUMN public.publisher_pipeline_stages.outcome IS 'open while outreach is in progress, won or lost when it ended';
+COMMENT ON COLUMN public.favorite_publisher_list_items.stage_id IS 'Current pipeline stage; status mirrors it for older clients';
+COMMENT ON COLUMN public.favorite_publisher_list_items.owner_user_id IS 'User responsible for the outreach to this publisher';
+COMMENT ON COLUMN public.favorite_publisher_list_items.next_action_at IS 'When the owner should follow up; a reminder email is sent once it passes';
+COMMENT ON COLUMN public.favorite_publisher_list_items.reminder_sent_at IS 'When the reminder for the current next action was sent';
@@ -0,0 +1,7 @@
+-- #############################################################################
+-- ## Publisher Conversions Migration Rollback
+-- ## Removes the publisher conversion tracking
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS set_publisher_conversions_timestamp ON public.publisher_conversions;
+DROP TABLE IF EXISTS public.publisher_conversions;
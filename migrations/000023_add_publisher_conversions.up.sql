@@ -0,0 +1,58 @@
+-- #############################################################################
+-- ## Publisher Conversions Migration
+-- ## Bridges publisher discovery and affiliate onboarding.
+-- ##
+-- ## Features:
+-- ## - Records which affiliate organization and affiliate an accepted list item became
+-- ## - Links the conversation, the association invitation and the resulting association
+-- ## - Tracks the funnel milestones up to the first tracking link
+-- #############################################################################
+
+-- publisher_conversions: One onboarding per advertiser organization and publisher domain
+CREATE TABLE public.publisher_conversions (
+    conversion_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    publisher_domain VARCHAR(255) NOT NULL,
+    list_id BIGINT REFERENCES public.favorite_publisher_lists(list_id) ON DELETE SET NULL,
+    item_id BIGINT REFERENCES public.favorite_publisher_list_items(item_id) ON DELETE SET NULL,
+    conversation_id BIGINT REFERENCES public.publisher_conversations(conversation_id) ON DELETE SET NULL,
+
+    -- Onboarded affiliate
+    affiliate_org_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    affiliate_id BIGINT REFERENCES public.affiliates(affiliate_id) ON DELETE SET NULL,
+    contact_email VARCHAR(255),
+
+    -- Association
+    invitation_id BIGINT REFERENCES public.advertiser_association_invitations(invitation_id) ON DELETE SET NULL,
+    association_id BIGINT REFERENCES public.organization_associations(association_id) ON DELETE SET NULL,
+    first_tracking_link_id BIGINT REFERENCES public.tracking_links(tracking_link_id) ON DELETE SET NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'associated', 'linked')),
+    created_by_user_id UUID, -- References profiles.id (auth.uid())
+
+    -- Funnel milestones
+    conversation_started_at TIMESTAMPTZ,
+    invited_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    associated_at TIMESTAMPTZ,
+    first_link_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    CONSTRAINT unique_publisher_conversion_per_org UNIQUE (organization_id, publisher_domain)
+);
+
+CREATE TRIGGER set_publisher_conversions_timestamp
+BEFORE UPDATE ON public.publisher_conversions
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Indexes for performance
+CREATE INDEX idx_publisher_conversions_org_invited ON public.publisher_conversions(organization_id, invited_at DESC);
+CREATE INDEX idx_publisher_conversions_open ON public.publisher_conversions(organization_id) WHERE status <> 'linked';
+CREATE INDEX idx_publisher_conversions_affiliate_org ON public.publisher_conversions(affiliate_org_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE public.publisher_conversions IS 'Onboarding of discovered publishers as affiliates, from conversation to first tracking link';
+COMMENT ON COLUMN public.publisher_conversions.status IS 'invited until the association is active, associated until the first tracking link exists, then linked';
+COMMENT ON COLUMN public.publisher_conversions.invitation_id IS 'Invitation issued to the affiliate organization; NULL when an association already existed';
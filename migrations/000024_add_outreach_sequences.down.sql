@@ -0,0 +1,10 @@
+-- #############################################################################
+-- ## Outreach Sequences Migration Rollback
+-- ## Removes outreach sequences, their steps and enrollments
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS set_outreach_sequence_enrollments_timestamp ON public.outreach_sequence_enrollments;
+DROP TABLE IF EXISTS public.outreach_sequence_enrollments;
+DROP TABLE IF EXISTS public.outreach_sequence_steps;
+DROP TRIGGER IF EXISTS set_outreach_sequences_timestamp ON public.outreach_sequences;
+DROP TABLE IF EXISTS public.outreach_sequences;
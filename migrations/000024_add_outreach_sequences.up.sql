@@ -0,0 +1,79 @@
+-- #############################################################################
+-- ## Outreach Sequences Migration
+-- ## Automated first-touch and follow-up messages for publisher messaging.
+-- ##
+-- ## Features:
+-- ## - Sequences with a subject and timed message steps using publisher merge fields
+-- ## - Enrollments of favorite list publishers, each with its own conversation
+-- ## - Enrollments stop automatically when the publisher replies
+-- #############################################################################
+
+-- outreach_sequences: Message sequences of an organization
+CREATE TABLE public.outreach_sequences (
+    sequence_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    subject_template VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused')),
+    created_by_user_id UUID, -- References profiles.id (auth.uid())
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER set_outreach_sequences_timestamp
+BEFORE UPDATE ON public.outreach_sequences
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- outreach_sequence_steps: Messages of a sequence. Step 1 is the first touch; later steps are
+-- follow-ups sent delay_days after the previous step when the publisher has not replied.
+CREATE TABLE public.outreach_sequence_steps (
+    step_id BIGSERIAL PRIMARY KEY,
+    sequence_id BIGINT NOT NULL REFERENCES public.outreach_sequences(sequence_id) ON DELETE CASCADE,
+    step_number INTEGER NOT NULL CHECK (step_number >= 1),
+    delay_days INTEGER NOT NULL DEFAULT 0 CHECK (delay_days >= 0),
+    body_template TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    CONSTRAINT unique_outreach_sequence_step UNIQUE (sequence_id, step_number)
+);
+
+-- outreach_sequence_enrollments: Publishers a sequence was launched against
+CREATE TABLE public.outreach_sequence_enrollments (
+    enrollment_id BIGSERIAL PRIMARY KEY,
+    sequence_id BIGINT NOT NULL REFERENCES public.outreach_sequences(sequence_id) ON DELETE CASCADE,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    list_id BIGINT REFERENCES public.favorite_publisher_lists(list_id) ON DELETE SET NULL,
+    publisher_domain VARCHAR(255) NOT NULL,
+    conversation_id BIGINT REFERENCES public.publisher_conversations(conversation_id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'active', 'completed', 'replied', 'stopped', 'failed')),
+    steps_sent INTEGER NOT NULL DEFAULT 0,
+    next_send_at TIMESTAMPTZ,
+    last_sent_at TIMESTAMPTZ,
+    replied_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    CONSTRAINT unique_outreach_enrollment_per_sequence UNIQUE (sequence_id, publisher_domain)
+);
+
+CREATE TRIGGER set_outreach_sequence_enrollments_timestamp
+BEFORE UPDATE ON public.outreach_sequence_enrollments
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Indexes for performance
+CREATE INDEX idx_outreach_sequences_organization_id ON public.outreach_sequences(organization_id);
+CREATE INDEX idx_outreach_sequence_enrollments_sequence ON public.outreach_sequence_enrollments(sequence_id, status);
+CREATE INDEX idx_outreach_sequence_enrollments_due ON public.outreach_sequence_enrollments(next_send_at)
+    WHERE status IN ('scheduled', 'active');
+CREATE INDEX idx_outreach_sequence_enrollments_conversation ON public.outreach_sequence_enrollments(conversation_id)
+    WHERE conversation_id IS NOT NULL;
+
+-- Add comments for documentation
+COMMENT ON TABLE public.outreach_sequences IS 'Automated publisher outreach: a first-touch message and timed follow-ups';
+COMMENT ON COLUMN public.outreach_sequences.subject_template IS 'Conversation subject, rendered with the publisher merge fields at launch';
+COMMENT ON COLUMN public.outreach_sequence_steps.body_template IS 'Message body with publisher merge fields such as {{.Domain}}';
+COMMENT ON COLUMN public.outreach_sequence_enrollments.status IS 'scheduled before the first touch, active while follow-ups remain, then completed, replied, stopped or failed';
+COMMENT ON COLUMN public.outreach_sequence_enrollments.steps_sent IS 'Number of steps sent; the step a reply followed';
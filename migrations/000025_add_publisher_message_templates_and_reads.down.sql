@@ -0,0 +1,9 @@
+-- #############################################################################
+-- ## Publisher Message Templates, Read State and Search Migration Rollback
+-- #############################################################################
+
+DROP INDEX IF EXISTS public.idx_publisher_messages_search_vector;
+ALTER TABLE public.publisher_messages DROP COLUMN IF EXISTS search_vector;
+DROP TABLE IF EXISTS public.publisher_message_reads;
+DROP TRIGGER IF EXISTS set_publisher_message_templates_timestamp ON public.publisher_message_templates;
+DROP TABLE IF EXISTS public.publisher_message_templates;
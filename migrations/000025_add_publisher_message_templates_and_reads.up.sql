@@ -0,0 +1,48 @@
+-- #############################################################################
+-- ## Publisher Message Templates, Read State and Search Migration
+-- ##
+-- ## Features:
+-- ## - Reusable organization message templates with publisher merge fields
+-- ## - Per-user read state of publisher messages for unread counts
+-- ## - Full-text search across conversation messages
+-- #############################################################################
+
+-- publisher_message_templates: Reusable messages of an organization
+CREATE TABLE public.publisher_message_templates (
+    template_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    subject VARCHAR(500), -- Used when the template starts a conversation
+    body TEXT NOT NULL,
+    created_by_user_id UUID, -- References profiles.id (auth.uid())
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    CONSTRAINT unique_publisher_message_template_name UNIQUE (organization_id, name)
+);
+
+CREATE TRIGGER set_publisher_message_templates_timestamp
+BEFORE UPDATE ON public.publisher_message_templates
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- publisher_message_reads: Publisher messages read by organization users
+CREATE TABLE public.publisher_message_reads (
+    message_id BIGINT NOT NULL REFERENCES public.publisher_messages(message_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL, -- References profiles.id (auth.uid())
+    read_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX idx_publisher_message_reads_user_id ON public.publisher_message_reads(user_id);
+
+-- Full-text search vector of the message content
+ALTER TABLE public.publisher_messages
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+CREATE INDEX idx_publisher_messages_search_vector ON public.publisher_messages USING GIN (search_vector);
+
+COMMENT ON TABLE public.publisher_message_templates IS 'Reusable publisher messages of an organization, rendered with publisher merge fields';
+COMMENT ON TABLE public.publisher_message_reads IS 'Per-user read state of publisher messages; messages without a row are unread';
+COMMENT ON COLUMN public.publisher_messages.search_vector IS 'English full-text search vector of the message content';
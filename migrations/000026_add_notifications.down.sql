@@ -0,0 +1,9 @@
+-- #############################################################################
+-- ## Notifications Migration Rollback
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS notify_notifications_insert ON public.notifications;
+DROP FUNCTION IF EXISTS notify_notification_created();
+DROP TRIGGER IF EXISTS set_notification_preferences_timestamp ON public.notification_preferences;
+DROP TABLE IF EXISTS public.notification_preferences;
+DROP TABLE IF EXISTS public.notifications;
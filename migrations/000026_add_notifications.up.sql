@@ -0,0 +1,65 @@
+-- #############################################################################
+-- ## Notifications Migration
+-- ##
+-- ## Features:
+-- ## - Per-user notifications of an organization with read state
+-- ## - Per-user, per-type notification preferences
+-- ## - NOTIFY on the 'notifications' channel so every API replica can push
+-- ##   new notifications to its connected clients
+-- #############################################################################
+
+-- notifications: One row per recipient user
+CREATE TABLE public.notifications (
+    notification_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES public.profiles(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL CHECK (type IN ('publisher_reply', 'association_request', 'delegation_invite', 'billing_alert')),
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    data JSONB DEFAULT '{}'::jsonb NOT NULL, -- IDs of the related resource, e.g. conversation_id
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_notifications_user_created ON public.notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_user_unread ON public.notifications(user_id) WHERE read_at IS NULL;
+
+-- notification_preferences: Types without a row are enabled and pushed live
+CREATE TABLE public.notification_preferences (
+    user_id UUID NOT NULL REFERENCES public.profiles(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL CHECK (type IN ('publisher_reply', 'association_request', 'delegation_invite', 'billing_alert')),
+    enabled BOOLEAN DEFAULT TRUE NOT NULL,  -- Disabled types are not stored
+    realtime BOOLEAN DEFAULT TRUE NOT NULL, -- Pushed to connected clients as they are created
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id, type)
+);
+
+CREATE TRIGGER set_notification_preferences_timestamp
+BEFORE UPDATE ON public.notification_preferences
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Announce new notifications to the API replicas listening on the channel
+CREATE OR REPLACE FUNCTION notify_notification_created()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF COALESCE((SELECT realtime FROM public.notification_preferences
+                 WHERE user_id = NEW.user_id AND type = NEW.type), TRUE) THEN
+        PERFORM pg_notify('notifications', json_build_object(
+            'notification_id', NEW.notification_id,
+            'user_id', NEW.user_id
+        )::text);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notify_notifications_insert
+AFTER INSERT ON public.notifications
+FOR EACH ROW
+EXECUTE FUNCTION notify_notification_created();
+
+COMMENT ON TABLE public.notifications IS 'In-app notifications of organization users';
+COMMENT ON TABLE public.notification_preferences IS 'Per-user notification settings by type; missing rows use the defaults';
+COMMENT ON FUNCTION notify_notification_created() IS 'Sends the notification and user IDs on the notifications channel';
@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Organization Logos Migration Rollback
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS set_organization_logos_timestamp ON public.organization_logos;
+DROP TABLE IF EXISTS public.organization_logos;
@@ -0,0 +1,24 @@
+-- #############################################################################
+-- ## Organization Logos Migration
+-- ##
+-- ## Features:
+-- ## - One logo per organization, drawn at the centre of tracking link QR codes
+-- ## - The image is kept in object storage; the row records where and when it
+-- ##   changed so that cached QR codes can be rebuilt
+-- #############################################################################
+
+CREATE TABLE public.organization_logos (
+    organization_id BIGINT PRIMARY KEY REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    storage_key VARCHAR(500) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes INTEGER NOT NULL CHECK (size_bytes > 0),
+    width INTEGER NOT NULL CHECK (width > 0),
+    height INTEGER NOT NULL CHECK (height > 0),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER set_organization_logos_timestamp
+BEFORE UPDATE ON public.organization_logos
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1,7 @@
+-- #############################################################################
+-- ## Tracking Domains Migration Rollback
+-- #############################################################################
+
+DROP TABLE IF EXISTS public.campaign_tracking_domains;
+DROP TRIGGER IF EXISTS set_tracking_domains_timestamp ON public.tracking_domains;
+DROP TABLE IF EXISTS public.tracking_domains;
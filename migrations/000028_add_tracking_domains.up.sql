@@ -0,0 +1,42 @@
+-- #############################################################################
+-- ## Tracking Domains Migration
+-- ##
+-- ## Features:
+-- ## - Custom hostnames organizations serve their tracking links on, verified
+-- ##   through a DNS TXT record
+-- ## - One default domain per organization
+-- ## - Per-campaign domain overrides
+-- #############################################################################
+
+CREATE TABLE public.tracking_domains (
+    tracking_domain_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    hostname VARCHAR(253) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'failed')),
+    verification_token VARCHAR(64) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    verified_at TIMESTAMPTZ,
+    last_checked_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- A hostname can only serve the links of one organization
+CREATE UNIQUE INDEX idx_tracking_domains_hostname ON public.tracking_domains(hostname);
+CREATE INDEX idx_tracking_domains_organization ON public.tracking_domains(organization_id);
+CREATE UNIQUE INDEX idx_tracking_domains_default ON public.tracking_domains(organization_id) WHERE is_default;
+
+CREATE TRIGGER set_tracking_domains_timestamp
+BEFORE UPDATE ON public.tracking_domains
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- campaign_tracking_domains: Campaigns whose links use another domain than the organization default
+CREATE TABLE public.campaign_tracking_domains (
+    campaign_id BIGINT PRIMARY KEY REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    tracking_domain_id BIGINT NOT NULL REFERENCES public.tracking_domains(tracking_domain_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_campaign_tracking_domains_domain ON public.campaign_tracking_domains(tracking_domain_id);
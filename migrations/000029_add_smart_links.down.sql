@@ -0,0 +1,8 @@
+-- #############################################################################
+-- ## Smart Links Migration Rollback
+-- #############################################################################
+
+DROP TABLE IF EXISTS public.smart_link_daily_stats;
+DROP TABLE IF EXISTS public.smart_link_destinations;
+DROP TRIGGER IF EXISTS set_smart_links_timestamp ON public.smart_links;
+DROP TABLE IF EXISTS public.smart_links;
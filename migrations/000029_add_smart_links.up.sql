@@ -0,0 +1,54 @@
+-- #############################################################################
+-- ## Smart Links Migration
+-- ##
+-- ## Features:
+-- ## - Smart links routing each click to one of several tracking links of an
+-- ##   affiliate by country, device and day-part rules, weights and daily caps
+-- ## - Fallback URL for clicks no destination accepts
+-- ## - Daily click counts per destination
+-- #############################################################################
+
+CREATE TABLE public.smart_links (
+    smart_link_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    affiliate_id BIGINT NOT NULL REFERENCES public.affiliates(affiliate_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused')),
+    fallback_url TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_smart_links_organization ON public.smart_links(organization_id);
+
+CREATE TRIGGER set_smart_links_timestamp
+BEFORE UPDATE ON public.smart_links
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- smart_link_destinations: Tracking links a smart link routes to
+CREATE TABLE public.smart_link_destinations (
+    destination_id BIGSERIAL PRIMARY KEY,
+    smart_link_id BIGINT NOT NULL REFERENCES public.smart_links(smart_link_id) ON DELETE CASCADE,
+    tracking_link_id BIGINT NOT NULL REFERENCES public.tracking_links(tracking_link_id) ON DELETE CASCADE,
+    campaign_id BIGINT NOT NULL REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    weight INTEGER NOT NULL DEFAULT 1 CHECK (weight > 0),
+    rules JSONB NOT NULL DEFAULT '{}'::jsonb,
+    daily_click_cap INTEGER CHECK (daily_click_cap > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (smart_link_id, tracking_link_id)
+);
+
+CREATE INDEX idx_smart_link_destinations_tracking_link ON public.smart_link_destinations(tracking_link_id);
+
+-- smart_link_daily_stats: Clicks per UTC day and destination; tracking_link_id 0 counts the
+-- clicks sent to the fallback URL. Rows are kept when a destination is removed.
+CREATE TABLE public.smart_link_daily_stats (
+    smart_link_id BIGINT NOT NULL REFERENCES public.smart_links(smart_link_id) ON DELETE CASCADE,
+    stat_date DATE NOT NULL,
+    tracking_link_id BIGINT NOT NULL,
+    campaign_id BIGINT,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (smart_link_id, stat_date, tracking_link_id)
+);
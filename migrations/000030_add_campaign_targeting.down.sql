@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Campaign Targeting Migration Rollback
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS set_campaign_targeting_rulesets_timestamp ON public.campaign_targeting_rulesets;
+DROP TABLE IF EXISTS public.campaign_targeting_rulesets;
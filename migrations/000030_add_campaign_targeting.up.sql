@@ -0,0 +1,22 @@
+-- #############################################################################
+-- ## Campaign Targeting Migration
+-- ##
+-- ## Features:
+-- ## - Provider-agnostic traffic targeting ruleset per campaign (countries,
+-- ##   regions, devices, browsers, operating systems, ISPs, IP lists, proxy
+-- ##   blocking and day parting)
+-- ## - Fallback URL for traffic that does not match the ruleset
+-- #############################################################################
+
+CREATE TABLE public.campaign_targeting_rulesets (
+    campaign_id BIGINT PRIMARY KEY REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    rules JSONB NOT NULL DEFAULT '{}'::jsonb,
+    fallback_url TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER set_campaign_targeting_rulesets_timestamp
+BEFORE UPDATE ON public.campaign_targeting_rulesets
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
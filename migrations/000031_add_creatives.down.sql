@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Creatives Migration Rollback
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS set_creatives_timestamp ON public.creatives;
+DROP TABLE IF EXISTS public.creatives;
@@ -0,0 +1,42 @@
+-- #############################################################################
+-- ## Creatives Migration
+-- ##
+-- ## Features:
+-- ## - Banner, HTML and email creatives of campaigns
+-- ## - Banner images are kept in object storage; the row records where
+-- ## - Approval status; only approved creatives are handed to affiliates
+-- ## - Provider sync state of the matching provider creative
+-- #############################################################################
+
+CREATE TABLE public.creatives (
+    creative_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    campaign_id BIGINT NOT NULL REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    creative_type VARCHAR(20) NOT NULL CHECK (creative_type IN ('image', 'html', 'email')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    rejection_reason TEXT,
+    reviewed_at TIMESTAMPTZ,
+    is_private BOOLEAN NOT NULL DEFAULT FALSE,
+    width INTEGER CHECK (width > 0),
+    height INTEGER CHECK (height > 0),
+    html_code TEXT,
+    email_from VARCHAR(255),
+    email_subject VARCHAR(255),
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    file_size INTEGER CHECK (file_size > 0),
+    storage_key VARCHAR(500),
+    provider_creative_id VARCHAR(100),
+    provider_synced_at TIMESTAMPTZ,
+    provider_sync_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_creatives_campaign ON public.creatives(campaign_id, status);
+
+CREATE TRIGGER set_creatives_timestamp
+BEFORE UPDATE ON public.creatives
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
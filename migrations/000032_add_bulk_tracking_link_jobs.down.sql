@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Bulk Tracking Link Jobs Migration Rollback
+-- #############################################################################
+
+DROP TABLE IF EXISTS public.bulk_tracking_link_job_items;
+DROP TABLE IF EXISTS public.bulk_tracking_link_jobs;
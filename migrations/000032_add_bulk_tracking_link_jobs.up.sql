@@ -0,0 +1,48 @@
+-- #############################################################################
+-- ## Bulk Tracking Link Jobs Migration
+-- ##
+-- ## Features:
+-- ## - Jobs generating or upserting the tracking links of a campaign for many
+-- ##   affiliates from a name and sub ID template
+-- ## - Progress counts on the job and the outcome of each affiliate's link
+-- #############################################################################
+
+CREATE TABLE public.bulk_tracking_link_jobs (
+    job_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    campaign_id BIGINT NOT NULL REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    all_visible_affiliates BOOLEAN NOT NULL DEFAULT FALSE,
+    template JSONB NOT NULL DEFAULT '{}'::jsonb,
+    is_encrypt_parameters BOOLEAN,
+    is_redirect_link BOOLEAN,
+    concurrency INTEGER NOT NULL DEFAULT 4 CHECK (concurrency > 0),
+    total_count INTEGER NOT NULL DEFAULT 0,
+    succeeded_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by_user_id UUID, -- References profiles.id (auth.uid())
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_bulk_tracking_link_jobs_organization ON public.bulk_tracking_link_jobs(organization_id, created_at DESC);
+CREATE INDEX idx_bulk_tracking_link_jobs_running ON public.bulk_tracking_link_jobs(status) WHERE status = 'running';
+
+-- bulk_tracking_link_job_items: One affiliate's link in a job
+CREATE TABLE public.bulk_tracking_link_job_items (
+    item_id BIGSERIAL PRIMARY KEY,
+    job_id BIGINT NOT NULL REFERENCES public.bulk_tracking_link_jobs(job_id) ON DELETE CASCADE,
+    affiliate_id BIGINT NOT NULL,
+    affiliate_name VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    parameters JSONB NOT NULL DEFAULT '{}'::jsonb, -- Rendered link name and tracking parameters
+    tracking_link_id BIGINT REFERENCES public.tracking_links(tracking_link_id) ON DELETE SET NULL,
+    tracking_url TEXT,
+    is_new BOOLEAN,
+    error_message TEXT,
+    processed_at TIMESTAMPTZ,
+    UNIQUE (job_id, affiliate_id)
+);
+
+CREATE INDEX idx_bulk_tracking_link_job_items_status ON public.bulk_tracking_link_job_items(job_id, status);
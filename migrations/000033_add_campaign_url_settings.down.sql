@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Campaign URL Settings Migration Rollback
+-- #############################################################################
+
+DROP TRIGGER IF EXISTS set_campaign_url_settings_timestamp ON public.campaign_url_settings;
+DROP TABLE IF EXISTS public.campaign_url_settings;
@@ -0,0 +1,19 @@
+-- #############################################################################
+-- ## Campaign URL Settings Migration
+-- ##
+-- ## Features:
+-- ## - Whitelist of click parameters passed through to the campaign destination
+-- ##   URL on native redirects, with their URL encoding and default value
+-- #############################################################################
+
+CREATE TABLE public.campaign_url_settings (
+    campaign_id BIGINT PRIMARY KEY REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    passthrough_parameters JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER set_campaign_url_settings_timestamp
+BEFORE UPDATE ON public.campaign_url_settings
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
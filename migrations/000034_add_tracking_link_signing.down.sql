@@ -0,0 +1,8 @@
+-- #############################################################################
+-- ## Tracking Link Signing Migration Rollback
+-- #############################################################################
+
+DROP TABLE IF EXISTS public.tracking_link_tamper_events;
+DROP TABLE IF EXISTS public.tracking_link_signing_keys;
+DROP TRIGGER IF EXISTS set_tracking_link_signing_settings_timestamp ON public.tracking_link_signing_settings;
+DROP TABLE IF EXISTS public.tracking_link_signing_settings;
@@ -0,0 +1,51 @@
+-- #############################################################################
+-- ## Tracking Link Signing Migration
+-- ##
+-- ## Features:
+-- ## - Per-organization signing of native tracking link parameters (HMAC), or
+-- ##   encryption for links with is_encrypt_parameters
+-- ## - Versioned signing keys whose IDs are embedded in links, rotated without
+-- ##   breaking links signed with retired keys until they are revoked
+-- ## - Log of clicks that failed verification
+-- #############################################################################
+
+CREATE TABLE public.tracking_link_signing_settings (
+    organization_id BIGINT PRIMARY KEY REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    enforcement VARCHAR(20) NOT NULL DEFAULT 'reject' CHECK (enforcement IN ('reject', 'flag')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER set_tracking_link_signing_settings_timestamp
+BEFORE UPDATE ON public.tracking_link_signing_settings
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- tracking_link_signing_keys: Secrets are encrypted with the application encryption key
+CREATE TABLE public.tracking_link_signing_keys (
+    key_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    encrypted_secret TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'retired', 'revoked')),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    retired_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_tracking_link_signing_keys_organization ON public.tracking_link_signing_keys(organization_id);
+CREATE UNIQUE INDEX idx_tracking_link_signing_keys_active ON public.tracking_link_signing_keys(organization_id) WHERE status = 'active';
+
+-- tracking_link_tamper_events: Clicks whose signed parameters did not verify
+CREATE TABLE public.tracking_link_tamper_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    tracking_link_id BIGINT NOT NULL REFERENCES public.tracking_links(tracking_link_id) ON DELETE CASCADE,
+    key_id BIGINT,
+    reason VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('rejected', 'flagged')),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_tracking_link_tamper_events_organization ON public.tracking_link_tamper_events(organization_id, created_at DESC);
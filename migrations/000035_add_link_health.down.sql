@@ -0,0 +1,24 @@
+-- #############################################################################
+-- ## Link Health Migration Rollback
+-- #############################################################################
+
+DELETE FROM public.notifications WHERE type = 'link_health_alert';
+DELETE FROM public.notification_preferences WHERE type = 'link_health_alert';
+
+ALTER TABLE public.notifications
+DROP CONSTRAINT IF EXISTS notifications_type_check;
+
+ALTER TABLE public.notifications
+ADD CONSTRAINT notifications_type_check
+CHECK (type IN ('publisher_reply', 'association_request', 'delegation_invite', 'billing_alert'));
+
+ALTER TABLE public.notification_preferences
+DROP CONSTRAINT IF EXISTS notification_preferences_type_check;
+
+ALTER TABLE public.notification_preferences
+ADD CONSTRAINT notification_preferences_type_check
+CHECK (type IN ('publisher_reply', 'association_request', 'delegation_invite', 'billing_alert'));
+
+DROP TABLE IF EXISTS public.link_health_checks;
+DROP TRIGGER IF EXISTS set_campaign_link_health_timestamp ON public.campaign_link_health;
+DROP TABLE IF EXISTS public.campaign_link_health;
@@ -0,0 +1,63 @@
+-- #############################################################################
+-- ## Link Health Migration
+-- ##
+-- ## Features:
+-- ## - Scheduled checks of the destination, preview and tracking URLs of active
+-- ##   campaigns, recording status code, latency, redirect chain and TLS expiry
+-- ## - Per-campaign monitoring settings and failure streak, with optional
+-- ##   auto-pause after a number of consecutive failed runs
+-- ## - 'link_health_alert' notification type
+-- #############################################################################
+
+-- campaign_link_health: Campaigns without a row are monitored with the defaults
+CREATE TABLE public.campaign_link_health (
+    campaign_id BIGINT PRIMARY KEY REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    auto_pause_after_failures INTEGER CHECK (auto_pause_after_failures > 0),
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_checked_at TIMESTAMPTZ,
+    last_healthy BOOLEAN,
+    auto_paused_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER set_campaign_link_health_timestamp
+BEFORE UPDATE ON public.campaign_link_health
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
+
+-- link_health_checks: One row per URL checked in a run
+CREATE TABLE public.link_health_checks (
+    check_id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES public.organizations(organization_id) ON DELETE CASCADE,
+    campaign_id BIGINT NOT NULL REFERENCES public.campaigns(campaign_id) ON DELETE CASCADE,
+    tracking_link_id BIGINT REFERENCES public.tracking_links(tracking_link_id) ON DELETE CASCADE,
+    url_kind VARCHAR(20) NOT NULL CHECK (url_kind IN ('destination', 'preview', 'tracking')),
+    url TEXT NOT NULL,
+    status_code INTEGER,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    redirect_chain JSONB NOT NULL DEFAULT '[]',
+    tls_expires_at TIMESTAMPTZ,
+    error TEXT,
+    healthy BOOLEAN NOT NULL,
+    checked_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_link_health_checks_campaign ON public.link_health_checks(campaign_id, checked_at DESC);
+CREATE INDEX idx_link_health_checks_checked_at ON public.link_health_checks(checked_at);
+
+-- Allow link health alerts as a notification type
+ALTER TABLE public.notifications
+DROP CONSTRAINT IF EXISTS notifications_type_check;
+
+ALTER TABLE public.notifications
+ADD CONSTRAINT notifications_type_check
+CHECK (type IN ('publisher_reply', 'association_request', 'delegation_invite', 'billing_alert', 'link_health_alert'));
+
+ALTER TABLE public.notification_preferences
+DROP CONSTRAINT IF EXISTS notification_preferences_type_check;
+
+ALTER TABLE public.notification_preferences
+ADD CONSTRAINT notification_preferences_type_check
+CHECK (type IN ('publisher_reply', 'association_request', 'delegation_invite', 'billing_alert', 'link_health_alert'));
@@ -0,0 +1,21 @@
+-- #############################################################################
+-- ## Tracking Link Lifecycle Migration Rollback
+-- #############################################################################
+
+DROP INDEX IF EXISTS public.idx_tracking_links_status_reason;
+DROP INDEX IF EXISTS public.idx_tracking_links_active_until;
+
+UPDATE public.tracking_links SET status = 'paused' WHERE status = 'scheduled';
+
+ALTER TABLE public.tracking_links
+DROP CONSTRAINT IF EXISTS tracking_links_status_check;
+
+ALTER TABLE public.tracking_links
+ADD CONSTRAINT tracking_links_status_check
+CHECK (status IN ('active', 'paused', 'archived'));
+
+ALTER TABLE public.tracking_links
+DROP CONSTRAINT IF EXISTS tracking_links_active_window_check,
+DROP COLUMN IF EXISTS status_reason,
+DROP COLUMN IF EXISTS active_until,
+DROP COLUMN IF EXISTS active_from;
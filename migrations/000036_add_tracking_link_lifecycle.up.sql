@@ -0,0 +1,26 @@
+-- #############################################################################
+-- ## Tracking Link Lifecycle Migration
+-- ##
+-- ## Features:
+-- ## - active_from/active_until windows on tracking links
+-- ## - 'scheduled' status for links waiting for their window or campaign to start
+-- ## - status_reason recording automatic transitions: expiry, campaign end and
+-- ##   pauses caused by a suspended organization association
+-- #############################################################################
+
+ALTER TABLE public.tracking_links
+ADD COLUMN active_from TIMESTAMPTZ,
+ADD COLUMN active_until TIMESTAMPTZ,
+ADD COLUMN status_reason VARCHAR(30) CHECK (status_reason IN ('association_suspended', 'expired', 'campaign_ended')),
+ADD CONSTRAINT tracking_links_active_window_check CHECK (active_until IS NULL OR active_from IS NULL OR active_until > active_from);
+
+ALTER TABLE public.tracking_links
+DROP CONSTRAINT IF EXISTS tracking_links_status_check;
+
+ALTER TABLE public.tracking_links
+ADD CONSTRAINT tracking_links_status_check
+CHECK (status IN ('scheduled', 'active', 'paused', 'archived'));
+
+-- The lifecycle job looks for scheduled links and windows that closed
+CREATE INDEX idx_tracking_links_active_until ON public.tracking_links(active_until) WHERE active_until IS NOT NULL;
+CREATE INDEX idx_tracking_links_status_reason ON public.tracking_links(status_reason) WHERE status_reason IS NOT NULL;
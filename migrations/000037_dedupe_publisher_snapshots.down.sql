@@ -0,0 +1,7 @@
+-- #############################################################################
+-- ## Deduplicate Publisher Snapshots Migration (Down)
+-- ##
+-- ## Removed duplicate snapshots carry no information and are not restored.
+-- #############################################################################
+
+SELECT 1;
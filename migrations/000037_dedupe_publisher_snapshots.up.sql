@@ -0,0 +1,35 @@
+-- #############################################################################
+-- ## Deduplicate Publisher Snapshots Migration
+-- ##
+-- ## Snapshots used to be recorded for every publisher write, including edits
+-- ## that left the tracked metrics unchanged. Remove snapshots whose metrics
+-- ## equal the previous snapshot of the same publisher so that recent changes
+-- ## compare the last two distinct states.
+-- #############################################################################
+
+DELETE FROM public.analytics_publisher_snapshots s
+USING (
+    SELECT snapshot_id,
+           traffic_score, relevance, known, promotype, partner_count, affiliate_networks, verticals, countries,
+           LAG(traffic_score) OVER w AS prev_traffic_score,
+           LAG(relevance) OVER w AS prev_relevance,
+           LAG(known) OVER w AS prev_known,
+           LAG(promotype) OVER w AS prev_promotype,
+           LAG(partner_count) OVER w AS prev_partner_count,
+           LAG(affiliate_networks) OVER w AS prev_affiliate_networks,
+           LAG(verticals) OVER w AS prev_verticals,
+           LAG(countries) OVER w AS prev_countries,
+           ROW_NUMBER() OVER w AS position
+    FROM public.analytics_publisher_snapshots
+    WINDOW w AS (PARTITION BY publisher_id ORDER BY captured_at, snapshot_id)
+) d
+WHERE s.snapshot_id = d.snapshot_id
+  AND d.position > 1
+  AND d.traffic_score = d.prev_traffic_score
+  AND d.relevance = d.prev_relevance
+  AND d.known = d.prev_known
+  AND d.promotype IS NOT DISTINCT FROM d.prev_promotype
+  AND d.partner_count = d.prev_partner_count
+  AND d.affiliate_networks = d.prev_affiliate_networks
+  AND d.verticals = d.prev_verticals
+  AND d.countries = d.prev_countries;
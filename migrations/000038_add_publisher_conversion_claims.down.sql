@@ -0,0 +1,20 @@
+-- #############################################################################
+-- ## Publisher Conversion Claims Migration (Down)
+-- #############################################################################
+
+DELETE FROM public.publisher_conversions WHERE status = 'pending';
+
+ALTER TABLE public.publisher_conversions
+DROP CONSTRAINT IF EXISTS publisher_conversions_affiliate_org_check;
+
+ALTER TABLE public.publisher_conversions
+DROP CONSTRAINT IF EXISTS publisher_conversions_status_check;
+
+ALTER TABLE public.publisher_conversions
+ADD CONSTRAINT publisher_conversions_status_check CHECK (status IN ('invited', 'associated', 'linked'));
+
+ALTER TABLE public.publisher_conversions
+ALTER COLUMN affiliate_org_id SET NOT NULL,
+DROP COLUMN IF EXISTS claimed_until;
+
+COMMENT ON COLUMN public.publisher_conversions.status IS 'invited until the association is active, associated until the first tracking link exists, then linked';
@@ -0,0 +1,25 @@
+-- #############################################################################
+-- ## Publisher Conversion Claims Migration
+-- ##
+-- ## A conversion row is inserted as a 'pending' claim before the affiliate
+-- ## organization, affiliate and invitation are set up, so that concurrent
+-- ## conversions of the same publisher cannot both create them. The claim
+-- ## records what has been set up, so a retry after a failure resumes instead
+-- ## of creating them again.
+-- #############################################################################
+
+ALTER TABLE public.publisher_conversions
+ALTER COLUMN affiliate_org_id DROP NOT NULL,
+ADD COLUMN claimed_until TIMESTAMPTZ;
+
+ALTER TABLE public.publisher_conversions
+DROP CONSTRAINT IF EXISTS publisher_conversions_status_check;
+
+ALTER TABLE public.publisher_conversions
+ADD CONSTRAINT publisher_conversions_status_check CHECK (status IN ('pending', 'invited', 'associated', 'linked'));
+
+ALTER TABLE public.publisher_conversions
+ADD CONSTRAINT publisher_conversions_affiliate_org_check CHECK (status = 'pending' OR affiliate_org_id IS NOT NULL);
+
+COMMENT ON COLUMN public.publisher_conversions.claimed_until IS 'Until when a pending conversion is being set up by a request; NULL when it can be resumed';
+COMMENT ON COLUMN public.publisher_conversions.status IS 'pending while the conversion is being set up, invited until the association is active, associated until the first tracking link exists, then linked';
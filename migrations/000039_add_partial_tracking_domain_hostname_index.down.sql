@@ -0,0 +1,15 @@
+-- #############################################################################
+-- ## Partial Tracking Domain Hostname Index Migration (Down)
+-- #############################################################################
+
+DROP INDEX IF EXISTS public.idx_tracking_domains_organization_hostname;
+DROP INDEX IF EXISTS public.idx_tracking_domains_hostname;
+
+-- Keep the verified domain, or the oldest claim, of each hostname
+DELETE FROM public.tracking_domains td
+USING public.tracking_domains other
+WHERE td.hostname = other.hostname
+  AND td.tracking_domain_id <> other.tracking_domain_id
+  AND (other.status = 'verified', other.tracking_domain_id * -1) > (td.status = 'verified', td.tracking_domain_id * -1);
+
+CREATE UNIQUE INDEX idx_tracking_domains_hostname ON public.tracking_domains(hostname);
@@ -0,0 +1,13 @@
+-- #############################################################################
+-- ## Partial Tracking Domain Hostname Index Migration
+-- ##
+-- ## Features:
+-- ## - Only verified domains reserve a hostname, so an unverified claim can't
+-- ##   block the organization that actually controls its DNS
+-- ## - An organization still registers a hostname at most once
+-- #############################################################################
+
+DROP INDEX IF EXISTS public.idx_tracking_domains_hostname;
+
+CREATE UNIQUE INDEX idx_tracking_domains_hostname ON public.tracking_domains(hostname) WHERE status = 'verified';
+CREATE UNIQUE INDEX idx_tracking_domains_organization_hostname ON public.tracking_domains(organization_id, hostname);
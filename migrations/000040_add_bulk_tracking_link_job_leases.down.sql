@@ -0,0 +1,15 @@
+-- #############################################################################
+-- ## Bulk Tracking Link Job Leases Migration (Down)
+-- #############################################################################
+
+UPDATE public.bulk_tracking_link_job_items SET status = 'pending' WHERE status = 'processing';
+
+ALTER TABLE public.bulk_tracking_link_job_items
+DROP CONSTRAINT IF EXISTS bulk_tracking_link_job_items_status_check;
+
+ALTER TABLE public.bulk_tracking_link_job_items
+ADD CONSTRAINT bulk_tracking_link_job_items_status_check CHECK (status IN ('pending', 'succeeded', 'failed'));
+
+ALTER TABLE public.bulk_tracking_link_jobs
+DROP COLUMN IF EXISTS lease_until,
+DROP COLUMN IF EXISTS lease_owner;
@@ -0,0 +1,21 @@
+-- #############################################################################
+-- ## Bulk Tracking Link Job Leases Migration
+-- ##
+-- ## Features:
+-- ## - A running job is processed by the instance holding its lease, renewed
+-- ##   while it works; another instance takes over once the lease expires
+-- ## - Items are claimed (processing) before their link is generated, so that
+-- ##   no item is generated twice
+-- #############################################################################
+
+ALTER TABLE public.bulk_tracking_link_jobs
+ADD COLUMN lease_owner VARCHAR(64),
+ADD COLUMN lease_until TIMESTAMPTZ;
+
+ALTER TABLE public.bulk_tracking_link_job_items
+DROP CONSTRAINT IF EXISTS bulk_tracking_link_job_items_status_check;
+
+ALTER TABLE public.bulk_tracking_link_job_items
+ADD CONSTRAINT bulk_tracking_link_job_items_status_check CHECK (status IN ('pending', 'processing', 'succeeded', 'failed'));
+
+COMMENT ON COLUMN public.bulk_tracking_link_jobs.lease_owner IS 'Instance processing the running job until lease_until';
@@ -0,0 +1,6 @@
+-- #############################################################################
+-- ## Link Health Claims Migration (Down)
+-- #############################################################################
+
+ALTER TABLE public.campaign_link_health
+DROP COLUMN IF EXISTS claimed_until;
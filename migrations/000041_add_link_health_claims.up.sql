@@ -0,0 +1,12 @@
+-- #############################################################################
+-- ## Link Health Claims Migration
+-- ##
+-- ## Features:
+-- ## - Campaigns due for a link check are claimed until their run is recorded,
+-- ##   so that instances running the cron job don't check them twice
+-- #############################################################################
+
+ALTER TABLE public.campaign_link_health
+ADD COLUMN claimed_until TIMESTAMPTZ;
+
+COMMENT ON COLUMN public.campaign_link_health.claimed_until IS 'Set while an instance checks the campaign; expired claims are taken over';